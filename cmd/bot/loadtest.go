@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/loadtest"
+	"go-telegram-forwarder-bot/internal/logger"
+)
+
+// runLoadTest runs the loadtest subcommand and returns the exit code. It uses
+// the regular configuration, so rate limits and features match production,
+// but talks to a fake Bot API server and writes to a scratch database.
+func runLoadTest(args []string) int {
+	flags := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	opts := loadtest.Options{}
+	flags.IntVar(&opts.Guests, "guests", 100, "synthetic guests sending messages at the same time")
+	flags.IntVar(&opts.MessagesPerGuest, "messages", 10, "messages sent by each guest")
+	flags.DurationVar(&opts.Interval, "interval", time.Second, "time between two messages of a guest")
+	flags.IntVar(&opts.Bots, "bots", 1, "ForwarderBots the guests are spread over")
+	flags.IntVar(&opts.Recipients, "recipients", 1, "recipients of each bot, including its manager")
+	flags.DurationVar(&opts.Timeout, "timeout", 10*time.Minute, "give up waiting for deliveries after this long")
+	flags.DurationVar(&opts.IdleTimeout, "idle-timeout", 10*time.Second, "stop once all messages were sent and nothing was delivered for this long")
+	flags.StringVar(&opts.DatabaseType, "db-type", "", "scratch database type (sqlite, mysql, postgres); empty for a temporary SQLite file")
+	flags.StringVar(&opts.DatabaseDSN, "db-dsn", "", "scratch database DSN; never point this at the production database")
+	verbose := flags.Bool("verbose", false, "log at the configured level instead of warnings only")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if opts.DatabaseType != "" && opts.DatabaseDSN == "" {
+		fmt.Fprintln(os.Stderr, "-db-dsn is required with -db-type")
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+	// Keep the load test out of the production log file
+	cfg.Log.Output = "stdout"
+	if !*verbose {
+		cfg.Log.Level = "warn"
+	}
+	log, err := logger.New(cfg.Log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return 1
+	}
+	defer log.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Running load test: %d guests x %d messages...\n", opts.Guests, opts.MessagesPerGuest)
+	report, err := loadtest.Run(ctx, cfg, log, opts)
+	if report != nil {
+		fmt.Println()
+		report.Print(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Load test failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,695 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service"
+	blacklistservice "go-telegram-forwarder-bot/internal/service/blacklist"
+	"go-telegram-forwarder-bot/internal/service/manager_bot"
+	"go-telegram-forwarder-bot/internal/service/statistics"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type botView struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	TelegramBotID int64     `json:"telegram_bot_id"`
+	ManagerUserID int64     `json:"manager_user_id"`
+	Running       bool      `json:"running"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (s *Server) botView(bot *models.ForwarderBot) botView {
+	_, running := s.botManager.GetBot(bot.ID)
+	return botView{
+		ID:            bot.ID.String(),
+		Name:          bot.Name,
+		TelegramBotID: bot.TelegramBotID,
+		ManagerUserID: bot.Manager.TelegramUserID,
+		Running:       running,
+		CreatedAt:     bot.CreatedAt,
+	}
+}
+
+type recipientView struct {
+	ID         string    `json:"id"`
+	ChatID     int64     `json:"chat_id"`
+	Type       string    `json:"type"`
+	Title      *string   `json:"title"`
+	Username   *string   `json:"username"`
+	Alias      *string   `json:"alias"`
+	IsFallback bool      `json:"is_fallback"`
+	IsForum    bool      `json:"is_forum"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newRecipientView(recipient *models.Recipient) recipientView {
+	return recipientView{
+		ID:         recipient.ID.String(),
+		ChatID:     recipient.ChatID,
+		Type:       string(recipient.RecipientType),
+		Title:      recipient.Title,
+		Username:   recipient.Username,
+		Alias:      recipient.Alias,
+		IsFallback: recipient.IsFallback,
+		IsForum:    recipient.IsForum,
+		CreatedAt:  recipient.CreatedAt,
+	}
+}
+
+type adminView struct {
+	ID        string    `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Username  *string   `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type blacklistView struct {
+	ID          string     `json:"id"`
+	GuestUserID int64      `json:"guest_user_id"`
+	RequestType string     `json:"request_type"`
+	Status      string     `json:"status"`
+	ApprovedAt  *time.Time `json:"approved_at"`
+	ExpiresAt   *time.Time `json:"expires_at"` // End of a temporary ban, null for a permanent one
+	Reason      string     `json:"reason"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type botStatsView struct {
+	BotID         string `json:"bot_id"`
+	BotName       string `json:"bot_name"`
+	InboundCount  int64  `json:"inbound_count"`
+	OutboundCount int64  `json:"outbound_count"`
+	GuestCount    int64  `json:"guest_count"`
+}
+
+func newBotStatsView(stats *statistics.BotStatistics) botStatsView {
+	return botStatsView{
+		BotID:         stats.BotID.String(),
+		BotName:       stats.BotName,
+		InboundCount:  stats.InboundCount,
+		OutboundCount: stats.OutboundCount,
+		GuestCount:    stats.GuestCount,
+	}
+}
+
+// handleGetStats returns instance-wide totals to superusers and per-bot
+// counts of the caller's bots to everyone else
+func (s *Server) handleGetStats(w http.ResponseWriter, r *http.Request, c *caller) error {
+	if c.isSuperuser {
+		stats, err := s.statsService.GetGlobalStatistics()
+		if err != nil {
+			return err
+		}
+		writeJSON(w, http.StatusOK, map[string]int64{
+			"manager_count":  stats.ManagerCount,
+			"bot_count":      stats.BotCount,
+			"inbound_count":  stats.TotalInbound,
+			"outbound_count": stats.TotalOutbound,
+			"guest_count":    stats.TotalGuestCount,
+		})
+		return nil
+	}
+
+	stats, err := s.statsService.GetManagerStatistics(c.user.ID)
+	if err != nil {
+		return err
+	}
+	bots := make([]botStatsView, 0, len(stats.Bots))
+	for i := range stats.Bots {
+		bots = append(bots, newBotStatsView(&stats.Bots[i]))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"bots": bots})
+	return nil
+}
+
+func (s *Server) handleListBots(w http.ResponseWriter, r *http.Request, c *caller) error {
+	var bots []*models.ForwarderBot
+	var err error
+	if c.isSuperuser {
+		bots, err = s.botRepo.GetAll()
+	} else {
+		bots, err = s.botRepo.GetByManagerID(c.user.ID)
+		for _, bot := range bots {
+			bot.Manager = *c.user
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	views := make([]botView, 0, len(bots))
+	for _, bot := range bots {
+		views = append(views, s.botView(bot))
+	}
+	writeJSON(w, http.StatusOK, views)
+	return nil
+}
+
+type createBotRequest struct {
+	Token string `json:"token"`
+	// ManagerUserID registers the bot for another manager; superusers only
+	ManagerUserID int64 `json:"manager_user_id"`
+}
+
+func (s *Server) handleCreateBot(w http.ResponseWriter, r *http.Request, c *caller) error {
+	var req createBotRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	if req.Token == "" {
+		return badRequest("token is required")
+	}
+	managerUserID := c.user.TelegramUserID
+	if req.ManagerUserID != 0 && req.ManagerUserID != managerUserID {
+		if !c.isSuperuser {
+			return &apiError{Status: http.StatusForbidden, Message: "only superusers can register bots for other managers"}
+		}
+		managerUserID = req.ManagerUserID
+	}
+
+	// Superusers may exceed tier limits, as they may raise them anyway
+	register := s.managerService.RegisterBotWithinLimit
+	if c.isSuperuser {
+		register = s.managerService.RegisterBot
+	}
+	bot, err := register(r.Context(), req.Token, managerUserID)
+	switch {
+	case errors.Is(err, manager_bot.ErrBotAlreadyRegistered):
+		return conflict("bot is already registered")
+	case errors.Is(err, manager_bot.ErrManagerBotToken):
+		return badRequest("token belongs to the ManagerBot")
+	case errors.Is(err, manager_bot.ErrManagerSuspended):
+		return &apiError{Status: http.StatusForbidden, Message: "manager is suspended"}
+	case errors.Is(err, manager_bot.ErrBotLimitReached):
+		return &apiError{Status: http.StatusForbidden, Message: err.Error()}
+	case errors.Is(err, manager_bot.ErrInvalidToken), errors.Is(err, manager_bot.ErrTokenVerification):
+		// Telegram's answer for bad tokens is the client's fault
+		return badRequest("%v", err)
+	case bot == nil && err != nil:
+		return err
+	case err != nil:
+		// Registered but not started; the bot is retried on the next restart
+		s.logger.Warn("Bot registered through API failed to start",
+			zap.String("bot_id", bot.ID.String()),
+			zap.Error(err))
+	}
+
+	s.audit(c, models.AuditLogActionAddBot, "bot", bot.ID, map[string]interface{}{
+		"bot_name":        bot.Name,
+		"manager_user_id": managerUserID,
+	})
+
+	bot, err = s.botRepo.GetByID(bot.ID)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusCreated, s.botView(bot))
+	return nil
+}
+
+func (s *Server) handleGetBot(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, s.botView(bot))
+	return nil
+}
+
+func (s *Server) handleDeleteBot(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	if _, err := s.managerService.DeleteBot(bot.ID); err != nil {
+		return err
+	}
+
+	s.audit(c, models.AuditLogActionDeleteBot, "bot", bot.ID, map[string]interface{}{
+		"bot_name": bot.Name,
+	})
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) handleGetBotStats(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	stats, err := s.statsService.GetBotStatistics(bot.ID)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, newBotStatsView(stats))
+	return nil
+}
+
+func (s *Server) handleListRecipients(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	recipients, err := s.recipientRepo.GetByBotID(bot.ID)
+	if err != nil {
+		return err
+	}
+
+	views := make([]recipientView, 0, len(recipients))
+	for _, recipient := range recipients {
+		views = append(views, newRecipientView(recipient))
+	}
+	writeJSON(w, http.StatusOK, views)
+	return nil
+}
+
+type recipientRequest struct {
+	ChatID     int64   `json:"chat_id"`
+	Alias      *string `json:"alias"`
+	IsFallback *bool   `json:"is_fallback"`
+}
+
+func (s *Server) handleCreateRecipient(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	var req recipientRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	if req.ChatID == 0 {
+		return badRequest("chat_id is required")
+	}
+	if _, err := s.recipientRepo.GetByBotIDAndChatID(bot.ID, req.ChatID); err == nil {
+		return conflict("recipient already exists")
+	}
+
+	// Same rule as /addrecipient: negative chat IDs are groups, unless the
+	// validator can look the chat up
+	recipientType := models.RecipientTypeUser
+	if req.ChatID < 0 {
+		recipientType = models.RecipientTypeGroup
+	}
+	if s.recipientValidator != nil {
+		var b *gotgbot.Bot
+		if fb, running := s.botManager.GetBot(bot.ID); running {
+			b = fb.GetBot()
+		}
+		recipientType, err = s.recipientValidator.Validate(b, bot, req.ChatID)
+		if err != nil {
+			var invalid *service.InvalidRecipientError
+			if errors.As(err, &invalid) {
+				return badRequest("invalid recipient: %s", invalid.Reason)
+			}
+			return err
+		}
+	}
+	recipient := &models.Recipient{
+		BotID:         bot.ID,
+		RecipientType: recipientType,
+		ChatID:        req.ChatID,
+	}
+	if err := applyRecipientRequest(recipient, &req); err != nil {
+		return err
+	}
+	if err := s.recipientRepo.Create(recipient); err != nil {
+		return err
+	}
+	if recipient.IsFallback {
+		if err := s.recipientRepo.SetFallback(bot.ID, &recipient.ID); err != nil {
+			return err
+		}
+	}
+	if fb, running := s.botManager.GetBot(bot.ID); running {
+		if err := s.recipientInfoRefresher.Refresh(r.Context(), fb.GetBot(), recipient); err != nil {
+			s.logger.Debug("Failed to refresh recipient info",
+				zap.String("bot_id", bot.ID.String()),
+				zap.Int64("chat_id", recipient.ChatID),
+				zap.Error(err))
+		}
+	}
+
+	s.audit(c, models.AuditLogActionAddRecipient, "recipient", recipient.ID, map[string]interface{}{
+		"chat_id": recipient.ChatID,
+		"type":    recipientType,
+	})
+	writeJSON(w, http.StatusCreated, newRecipientView(recipient))
+	return nil
+}
+
+// handleUpdateRecipient sets a recipient's alias and whether it is the
+// fallback. The chat ID cannot be changed; add a new recipient instead.
+func (s *Server) handleUpdateRecipient(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, recipient, err := s.loadRecipient(r, c)
+	if err != nil {
+		return err
+	}
+	var req recipientRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	if req.ChatID != 0 && req.ChatID != recipient.ChatID {
+		return badRequest("chat_id cannot be changed")
+	}
+	before := *recipient
+	wasFallback := recipient.IsFallback
+	if err := applyRecipientRequest(recipient, &req); err != nil {
+		return err
+	}
+	if err := s.recipientRepo.Update(recipient); err != nil {
+		return err
+	}
+	if recipient.IsFallback != wasFallback {
+		fallbackID := &recipient.ID
+		if !recipient.IsFallback {
+			fallbackID = nil
+		}
+		if err := s.recipientRepo.SetFallback(bot.ID, fallbackID); err != nil {
+			return err
+		}
+	}
+
+	if changes := recipientChanges(&before, recipient); len(changes) > 0 {
+		s.audit(c, models.AuditLogActionUpdateRecipient, "recipient", recipient.ID, map[string]interface{}{
+			"chat_id": recipient.ChatID,
+			"changes": changes,
+		})
+	}
+	writeJSON(w, http.StatusOK, newRecipientView(recipient))
+	return nil
+}
+
+// recipientChanges returns the fields an update changed, each with its old
+// and new value. An unset alias is shown as empty.
+func recipientChanges(before, after *models.Recipient) map[string]interface{} {
+	changes := make(map[string]interface{})
+	oldAlias, newAlias := "", ""
+	if before.Alias != nil {
+		oldAlias = *before.Alias
+	}
+	if after.Alias != nil {
+		newAlias = *after.Alias
+	}
+	if oldAlias != newAlias {
+		changes["alias"] = map[string]string{"from": oldAlias, "to": newAlias}
+	}
+	if before.IsFallback != after.IsFallback {
+		changes["is_fallback"] = map[string]bool{"from": before.IsFallback, "to": after.IsFallback}
+	}
+	return changes
+}
+
+func (s *Server) handleDeleteRecipient(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, recipient, err := s.loadRecipient(r, c)
+	if err != nil {
+		return err
+	}
+	if err := s.recipientRepo.Delete(bot.ID, recipient.ID); err != nil {
+		return err
+	}
+
+	s.audit(c, models.AuditLogActionDelRecipient, "recipient", recipient.ID, map[string]interface{}{
+		"chat_id": recipient.ChatID,
+	})
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// loadRecipient returns the bot and the recipient named in the path
+func (s *Server) loadRecipient(r *http.Request, c *caller) (*models.ForwarderBot, *models.Recipient, error) {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipientID, err := pathUUID(r, "recipient_id")
+	if err != nil {
+		return nil, nil, err
+	}
+	recipient, err := s.recipientRepo.GetByID(recipientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if recipient.BotID != bot.ID {
+		return nil, nil, errNotFound
+	}
+	return bot, recipient, nil
+}
+
+// applyRecipientRequest copies the optional fields of req onto recipient. An
+// empty alias clears it.
+func applyRecipientRequest(recipient *models.Recipient, req *recipientRequest) error {
+	if req.Alias != nil {
+		alias := strings.TrimSpace(*req.Alias)
+		if len([]rune(alias)) > 64 {
+			return badRequest("alias must be at most 64 characters")
+		}
+		recipient.Alias = nil
+		if alias != "" {
+			recipient.Alias = &alias
+		}
+	}
+	if req.IsFallback != nil {
+		recipient.IsFallback = *req.IsFallback
+	}
+	return nil
+}
+
+func (s *Server) handleListAdmins(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	admins, err := s.botAdminRepo.GetByBotID(bot.ID)
+	if err != nil {
+		return err
+	}
+
+	views := make([]adminView, 0, len(admins))
+	for _, admin := range admins {
+		views = append(views, adminView{
+			ID:        admin.ID.String(),
+			UserID:    admin.AdminUser.TelegramUserID,
+			Username:  admin.AdminUser.Username,
+			CreatedAt: admin.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, views)
+	return nil
+}
+
+type createAdminRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+func (s *Server) handleCreateAdmin(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	var req createAdminRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	if req.UserID == 0 {
+		return badRequest("user_id is required")
+	}
+
+	adminUser, err := s.userRepo.GetOrCreateByTelegramUserID(req.UserID, nil)
+	if err != nil {
+		return err
+	}
+	isAdmin, err := s.botAdminRepo.IsAdmin(bot.ID, adminUser.ID)
+	if err != nil {
+		return err
+	}
+	if isAdmin {
+		return conflict("user is already an admin")
+	}
+
+	botAdmin := &models.BotAdmin{
+		BotID:       bot.ID,
+		AdminUserID: adminUser.ID,
+	}
+	if err := s.botAdminRepo.Create(botAdmin); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return conflict("user is already an admin")
+		}
+		return err
+	}
+
+	s.audit(c, models.AuditLogActionAddAdmin, "admin", botAdmin.ID, map[string]interface{}{
+		"admin_user_id": req.UserID,
+	})
+	writeJSON(w, http.StatusCreated, adminView{
+		ID:        botAdmin.ID.String(),
+		UserID:    adminUser.TelegramUserID,
+		Username:  adminUser.Username,
+		CreatedAt: botAdmin.CreatedAt,
+	})
+	return nil
+}
+
+func (s *Server) handleDeleteAdmin(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	adminID, err := pathUUID(r, "admin_id")
+	if err != nil {
+		return err
+	}
+	admin, err := s.botAdminRepo.GetByID(adminID)
+	if err != nil {
+		return err
+	}
+	if admin.BotID != bot.ID {
+		return errNotFound
+	}
+	if err := s.botAdminRepo.Delete(bot.ID, admin.ID); err != nil {
+		return err
+	}
+
+	s.audit(c, models.AuditLogActionDelAdmin, "admin", admin.ID, map[string]interface{}{
+		"admin_user_id": admin.AdminUser.TelegramUserID,
+	})
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// handleListBlacklist lists the guests currently banned from the bot
+func (s *Server) handleListBlacklist(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	entries, err := s.blacklistService.GetBlacklisted(bot.ID)
+	if err != nil {
+		return err
+	}
+
+	views := make([]blacklistView, 0, len(entries))
+	for _, entry := range entries {
+		views = append(views, newBlacklistView(entry, entry.Guest.GuestUserID))
+	}
+	writeJSON(w, http.StatusOK, views)
+	return nil
+}
+
+type banRequest struct {
+	GuestUserID int64  `json:"guest_user_id"`
+	Reason      string `json:"reason"`
+}
+
+// handleBan bans a guest. The request is approved at once, as the caller is
+// the bot's manager or a superuser.
+func (s *Server) handleBan(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	var req banRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	if req.GuestUserID == 0 {
+		return badRequest("guest_user_id is required")
+	}
+
+	banned, err := s.blacklistService.IsBlacklisted(bot.ID, req.GuestUserID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return conflict("guest is already banned")
+	}
+	entry, err := s.decideBlacklist(bot, c, req.GuestUserID, models.BlacklistRequestTypeBan, req.Reason)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusCreated, newBlacklistView(entry, req.GuestUserID))
+	return nil
+}
+
+func (s *Server) handleUnban(w http.ResponseWriter, r *http.Request, c *caller) error {
+	bot, err := s.loadBot(r, c)
+	if err != nil {
+		return err
+	}
+	guestUserID, err := strconv.ParseInt(r.PathValue("guest_user_id"), 10, 64)
+	if err != nil {
+		return badRequest("invalid guest_user_id")
+	}
+
+	banned, err := s.blacklistService.IsBlacklisted(bot.ID, guestUserID)
+	if err != nil {
+		return err
+	}
+	if !banned {
+		return errNotFound
+	}
+	if _, err := s.decideBlacklist(bot, c, guestUserID, models.BlacklistRequestTypeUnban, ""); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// decideBlacklist creates a ban or unban request and approves it on behalf of
+// the caller. reason is only kept for bans.
+func (s *Server) decideBlacklist(bot *models.ForwarderBot, c *caller, guestUserID int64, requestType models.BlacklistRequestType, reason string) (*models.Blacklist, error) {
+	create := func(botID uuid.UUID, guestUserID int64, requestUserID uuid.UUID) (*models.Blacklist, error) {
+		return s.blacklistService.CreateBanRequest(botID, guestUserID, requestUserID, reason)
+	}
+	action := models.AuditLogActionBan
+	if requestType == models.BlacklistRequestTypeUnban {
+		create = s.blacklistService.CreateUnbanRequest
+		action = models.AuditLogActionUnban
+	}
+
+	entry, err := create(bot.ID, guestUserID, c.user.ID)
+	if err != nil {
+		// The latest request does not allow this one, e.g. a ban is pending approval
+		return nil, conflict("%v", err)
+	}
+	if err := s.blacklistService.ApproveRequest(entry.ID, c.user.ID); err != nil {
+		if errors.Is(err, blacklistservice.ErrAlreadyDecided) {
+			return nil, conflict("request was decided by someone else")
+		}
+		return nil, err
+	}
+	entry.Status = models.BlacklistStatusApproved
+	now := time.Now()
+	entry.ApprovedAt = &now
+
+	s.audit(c, action, "blacklist", entry.ID, map[string]interface{}{
+		"blacklist_id":  entry.ID.String(),
+		"request_type":  requestType,
+		"guest_user_id": guestUserID,
+		"reason":        entry.Reason,
+	})
+	return entry, nil
+}
+
+func newBlacklistView(entry *models.Blacklist, guestUserID int64) blacklistView {
+	return blacklistView{
+		ID:          entry.ID.String(),
+		GuestUserID: guestUserID,
+		RequestType: string(entry.RequestType),
+		Status:      string(entry.Status),
+		ApprovedAt:  entry.ApprovedAt,
+		ExpiresAt:   entry.ExpiresAt,
+		Reason:      entry.Reason,
+		CreatedAt:   entry.CreatedAt,
+	}
+}
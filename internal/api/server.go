@@ -0,0 +1,309 @@
+// Package api serves an HTTP API for managing ForwarderBots, their recipients,
+// admins and blacklists without the Telegram UI.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/bot"
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/service"
+	"go-telegram-forwarder-bot/internal/service/blacklist"
+	"go-telegram-forwarder-bot/internal/service/manager_bot"
+	"go-telegram-forwarder-bot/internal/service/statistics"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxBodyBytes limits request bodies; every request fits in a few hundred bytes
+const maxBodyBytes = 64 << 10
+
+// ServerParams contains all dependencies of the API server
+type ServerParams struct {
+	Config                 *config.Config
+	BotRepo                repository.BotRepository
+	RecipientRepo          repository.RecipientRepository
+	BotAdminRepo           repository.BotAdminRepository
+	UserRepo               repository.UserRepository
+	AuditLogRepo           repository.AuditLogRepository
+	BlacklistService       *blacklist.Service
+	StatsService           *statistics.Service
+	ManagerService         *manager_bot.Service
+	BotManager             *bot.BotManager
+	RecipientInfoRefresher *service.RecipientInfoRefresher
+	RecipientValidator     *service.RecipientValidator
+	Lockdown               *service.Lockdown
+	Logger                 *zap.Logger
+}
+
+// Server is the HTTP API. Every request is authenticated with one of the keys
+// in api.keys and acts as the Telegram user the key belongs to.
+type Server struct {
+	cfg                    *config.Config
+	botRepo                repository.BotRepository
+	recipientRepo          repository.RecipientRepository
+	botAdminRepo           repository.BotAdminRepository
+	userRepo               repository.UserRepository
+	auditLogRepo           repository.AuditLogRepository
+	blacklistService       *blacklist.Service
+	statsService           *statistics.Service
+	managerService         *manager_bot.Service
+	botManager             *bot.BotManager
+	recipientInfoRefresher *service.RecipientInfoRefresher
+	recipientValidator     *service.RecipientValidator
+	lockdown               *service.Lockdown
+	logger                 *zap.Logger
+
+	mux    *http.ServeMux
+	server *http.Server
+}
+
+func NewServer(params ServerParams) *Server {
+	s := &Server{
+		cfg:                    params.Config,
+		botRepo:                params.BotRepo,
+		recipientRepo:          params.RecipientRepo,
+		botAdminRepo:           params.BotAdminRepo,
+		userRepo:               params.UserRepo,
+		auditLogRepo:           params.AuditLogRepo,
+		blacklistService:       params.BlacklistService,
+		statsService:           params.StatsService,
+		managerService:         params.ManagerService,
+		botManager:             params.BotManager,
+		recipientInfoRefresher: params.RecipientInfoRefresher,
+		recipientValidator:     params.RecipientValidator,
+		lockdown:               params.Lockdown,
+		logger:                 params.Logger,
+		mux:                    http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.handle("GET /api/v1/stats", s.handleGetStats)
+	s.handle("GET /api/v1/bots", s.handleListBots)
+	s.handle("POST /api/v1/bots", s.handleCreateBot)
+	s.handle("GET /api/v1/bots/{bot_id}", s.handleGetBot)
+	s.handle("DELETE /api/v1/bots/{bot_id}", s.handleDeleteBot)
+	s.handle("GET /api/v1/bots/{bot_id}/stats", s.handleGetBotStats)
+	s.handle("GET /api/v1/bots/{bot_id}/recipients", s.handleListRecipients)
+	s.handle("POST /api/v1/bots/{bot_id}/recipients", s.handleCreateRecipient)
+	s.handle("PATCH /api/v1/bots/{bot_id}/recipients/{recipient_id}", s.handleUpdateRecipient)
+	s.handle("DELETE /api/v1/bots/{bot_id}/recipients/{recipient_id}", s.handleDeleteRecipient)
+	s.handle("GET /api/v1/bots/{bot_id}/admins", s.handleListAdmins)
+	s.handle("POST /api/v1/bots/{bot_id}/admins", s.handleCreateAdmin)
+	s.handle("DELETE /api/v1/bots/{bot_id}/admins/{admin_id}", s.handleDeleteAdmin)
+	s.handle("GET /api/v1/bots/{bot_id}/blacklist", s.handleListBlacklist)
+	s.handle("POST /api/v1/bots/{bot_id}/blacklist", s.handleBan)
+	s.handle("DELETE /api/v1/bots/{bot_id}/blacklist/{guest_user_id}", s.handleUnban)
+}
+
+// Start listens on api.listen_addr and serves requests until ctx is done
+func (s *Server) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.cfg.API.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.cfg.API.ListenAddr, err)
+	}
+
+	s.server = &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("API server stopped", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			s.logger.Warn("Failed to shut down API server", zap.Error(err))
+		}
+	}()
+
+	s.logger.Info("API server listening", zap.String("listen_addr", s.cfg.API.ListenAddr))
+	return nil
+}
+
+// caller is the user an API key acts as
+type caller struct {
+	keyName     string
+	user        *models.User
+	isSuperuser bool
+}
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request, c *caller) error
+
+// handle registers an authenticated handler. Errors returned by handlers are
+// written as JSON; an apiError sets the status code, anything else is a 500.
+func (s *Server) handle(pattern string, h handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		key, ok := s.authenticate(r)
+		if !ok {
+			writeError(w, errUnauthorized)
+			return
+		}
+		user, err := s.userRepo.GetOrCreateByTelegramUserID(key.UserID, nil)
+		if err != nil {
+			s.logger.Error("Failed to get API key user", zap.String("key", key.Name), zap.Error(err))
+			writeError(w, err)
+			return
+		}
+		if user.IsSuspended() {
+			writeError(w, &apiError{Status: http.StatusForbidden, Message: "user is suspended"})
+			return
+		}
+		if notice, blocked := s.lockdownNotice(key.UserID); blocked {
+			writeError(w, &apiError{Status: http.StatusServiceUnavailable, Message: notice})
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		c := &caller{keyName: key.Name, user: user, isSuperuser: s.managerService.IsSuperuser(key.UserID)}
+		if err := h(w, r, c); err != nil {
+			var apiErr *apiError
+			if !errors.As(err, &apiErr) {
+				s.logger.Error("API request failed",
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.String("key", key.Name),
+					zap.Error(err))
+			}
+			writeError(w, err)
+		}
+	})
+}
+
+// lockdownNotice returns the notice for callers locked out by /lockdown
+func (s *Server) lockdownNotice(telegramUserID int64) (string, bool) {
+	if s.lockdown == nil {
+		return "", false
+	}
+	return s.lockdown.Blocks(telegramUserID)
+}
+
+// authenticate returns the key sent with the request as a bearer token
+func (s *Server) authenticate(r *http.Request) (config.APIKey, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return config.APIKey{}, false
+	}
+	for _, key := range s.cfg.API.Keys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key.Key)) == 1 {
+			return key, true
+		}
+	}
+	return config.APIKey{}, false
+}
+
+// apiError is an error shown to the API client as is
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+var (
+	errUnauthorized = &apiError{Status: http.StatusUnauthorized, Message: "missing or invalid API key"}
+	errNotFound     = &apiError{Status: http.StatusNotFound, Message: "not found"}
+)
+
+func badRequest(format string, args ...interface{}) error {
+	return &apiError{Status: http.StatusBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...interface{}) error {
+	return &apiError{Status: http.StatusConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		writeJSON(w, apiErr.Status, map[string]string{"error": apiErr.Message})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": errNotFound.Message})
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+}
+
+// decodeJSON reads the request body into v, rejecting unknown fields
+func decodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// pathUUID parses the named path parameter as a UUID
+func pathUUID(r *http.Request, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		return uuid.Nil, badRequest("invalid %s", name)
+	}
+	return id, nil
+}
+
+// loadBot returns the bot named in the path if the caller may manage it. Bots
+// of other managers are reported as not found, so their IDs are not revealed.
+func (s *Server) loadBot(r *http.Request, c *caller) (*models.ForwarderBot, error) {
+	botID, err := pathUUID(r, "bot_id")
+	if err != nil {
+		return nil, err
+	}
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.isSuperuser && bot.ManagerID != c.user.ID {
+		return nil, errNotFound
+	}
+	return bot, nil
+}
+
+// audit records an action taken through the API in the audit log
+func (s *Server) audit(c *caller, action models.AuditLogAction, resourceType string, resourceID uuid.UUID, details map[string]interface{}) {
+	details["via"] = "api"
+	details["api_key"] = c.keyName
+	encoded, _ := json.Marshal(details)
+	auditLog := &models.AuditLog{
+		UserID:       &c.user.ID,
+		ActionType:   action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Details:      string(encoded),
+	}
+	if err := s.auditLogRepo.Create(auditLog); err != nil {
+		s.logger.Warn("Failed to record audit log", zap.String("action", string(action)), zap.Error(err))
+	}
+}
@@ -0,0 +1,345 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/bot"
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/database"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/service"
+	"go-telegram-forwarder-bot/internal/service/blacklist"
+	"go-telegram-forwarder-bot/internal/service/manager_bot"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	superuserID = 1000
+	managerAID  = 2000
+	managerBID  = 3000
+
+	superuserKey = "superuser-key-0123456789abcdefghijkl"
+	managerAKey  = "manager-a-key-0123456789abcdefghijkl"
+	managerBKey  = "manager-b-key-0123456789abcdefghijkl"
+)
+
+type testServer struct {
+	*Server
+	db        *gorm.DB
+	users     repository.UserRepository
+	bots      repository.BotRepository
+	blacklist *blacklist.Service
+	lockdown  *service.Lockdown
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	// Every connection to :memory: is a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// newFakeBotAPI answers getMe for any token with the bot ID the token starts with
+func newFakeBotAPI(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/bot"), "/")
+		id, _, _ := strings.Cut(token, ":")
+		botID, _ := strconv.ParseInt(id, 10, 64)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"ok":true,"result":{"id":%d,"is_bot":true,"first_name":"Test","username":"test_%d_bot"}}`, botID, botID)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	db := newTestDB(t)
+	log := zap.NewNop()
+
+	cfg := &config.Config{
+		Environment: "development",
+		ManagerBot: config.ManagerBotConfig{
+			Token:      "999:" + strings.Repeat("M", 35),
+			Superusers: []int64{superuserID},
+		},
+		BotAPI: config.BotAPIConfig{URL: newFakeBotAPI(t)},
+		Tiers: config.TiersConfig{
+			Free: config.TierConfig{MaxBots: 1},
+		},
+		API: config.APIConfig{Keys: []config.APIKey{
+			{Name: "superuser", Key: superuserKey, UserID: superuserID},
+			{Name: "manager-a", Key: managerAKey, UserID: managerAID},
+			{Name: "manager-b", Key: managerBKey, UserID: managerBID},
+		}},
+	}
+
+	users := repository.NewUserRepository(db)
+	bots := repository.NewBotRepository(db)
+	recipients := repository.NewRecipientRepository(db)
+	auditLogs := repository.NewAuditLogRepository(db)
+	superusers, err := service.NewSuperusers(repository.NewSuperuserRepository(db), cfg, log)
+	if err != nil {
+		t.Fatalf("NewSuperusers: %v", err)
+	}
+
+	managerService, err := manager_bot.NewService(db, bots, users, auditLogs, recipients,
+		repository.NewReportRepository(db), nil, cfg, log)
+	if err != nil {
+		t.Fatalf("manager_bot.NewService: %v", err)
+	}
+	managerService.SetSuperusers(superusers)
+	managerService.SetFeatureFlags(service.NewFeatureFlags(cfg, superusers, users, bots))
+
+	botManager, err := bot.NewBotManager(bot.BotManagerParams{Ctx: context.Background(), Config: cfg, Logger: log})
+	if err != nil {
+		t.Fatalf("NewBotManager: %v", err)
+	}
+
+	blacklistService := blacklist.NewService(repository.NewBlacklistRepository(db), repository.NewGuestRepository(db), log)
+	lockdown := service.NewLockdown(superusers)
+	server := NewServer(ServerParams{
+		Config:           cfg,
+		BotRepo:          bots,
+		RecipientRepo:    recipients,
+		BotAdminRepo:     repository.NewBotAdminRepository(db),
+		UserRepo:         users,
+		AuditLogRepo:     auditLogs,
+		BlacklistService: blacklistService,
+		ManagerService:   managerService,
+		BotManager:       botManager,
+		Lockdown:         lockdown,
+		Logger:           log,
+	})
+	return &testServer{
+		Server:    server,
+		db:        db,
+		users:     users,
+		bots:      bots,
+		blacklist: blacklistService,
+		lockdown:  lockdown,
+	}
+}
+
+// createBot stores a bot of the manager with the given Telegram user ID
+func (s *testServer) createBot(t *testing.T, managerTelegramID int64) *models.ForwarderBot {
+	t.Helper()
+	manager, err := s.users.GetOrCreateByTelegramUserID(managerTelegramID, nil)
+	if err != nil {
+		t.Fatalf("GetOrCreateByTelegramUserID: %v", err)
+	}
+	forwarderBot := &models.ForwarderBot{Token: "encrypted", Name: "test_bot", ManagerID: manager.ID}
+	if err := s.bots.Create(forwarderBot); err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+	return forwarderBot
+}
+
+// request sends a request with the key and returns the recorded response
+func (s *testServer) request(key, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, want, rec.Body.String())
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestServer(t)
+
+	expectStatus(t, s.request("", http.MethodGet, "/api/v1/bots", ""), http.StatusUnauthorized)
+	expectStatus(t, s.request("wrong-key-0123456789abcdefghijklmnop", http.MethodGet, "/api/v1/bots", ""), http.StatusUnauthorized)
+
+	// The key must be sent as a bearer token
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/bots", nil)
+	req.Header.Set("Authorization", managerAKey)
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	expectStatus(t, rec, http.StatusUnauthorized)
+
+	expectStatus(t, s.request(managerAKey, http.MethodGet, "/api/v1/bots", ""), http.StatusOK)
+}
+
+func TestOtherManagersBotsAreNotFound(t *testing.T) {
+	s := newTestServer(t)
+	botA := s.createBot(t, managerAID)
+	botB := s.createBot(t, managerBID)
+
+	expectStatus(t, s.request(managerAKey, http.MethodGet, "/api/v1/bots/"+botA.ID.String(), ""), http.StatusOK)
+	expectStatus(t, s.request(managerAKey, http.MethodGet, "/api/v1/bots/"+botB.ID.String(), ""), http.StatusNotFound)
+	expectStatus(t, s.request(managerAKey, http.MethodDelete, "/api/v1/bots/"+botB.ID.String(), ""), http.StatusNotFound)
+	expectStatus(t, s.request(managerAKey, http.MethodPost, "/api/v1/bots/"+botB.ID.String()+"/blacklist",
+		`{"guest_user_id": 42}`), http.StatusNotFound)
+	if banned, _ := s.blacklist.IsBlacklisted(botB.ID, 42); banned {
+		t.Fatal("guest was banned from another manager's bot")
+	}
+
+	// A bot that does not exist looks the same as another manager's
+	expectStatus(t, s.request(managerAKey, http.MethodGet, "/api/v1/bots/"+uuid.New().String(), ""), http.StatusNotFound)
+
+	// Superusers manage every bot
+	expectStatus(t, s.request(superuserKey, http.MethodGet, "/api/v1/bots/"+botB.ID.String(), ""), http.StatusOK)
+
+	var listed []botView
+	rec := s.request(managerAKey, http.MethodGet, "/api/v1/bots", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil || len(listed) != 1 || listed[0].ID != botA.ID.String() {
+		t.Fatalf("GET /bots = %s, %v; want only the caller's bot", rec.Body.String(), err)
+	}
+}
+
+func TestSuspendedUserIsForbidden(t *testing.T) {
+	s := newTestServer(t)
+	manager, err := s.users.GetOrCreateByTelegramUserID(managerAID, nil)
+	if err != nil {
+		t.Fatalf("GetOrCreateByTelegramUserID: %v", err)
+	}
+	now := time.Now()
+	manager.SuspendedAt = &now
+	if err := s.users.Update(manager); err != nil {
+		t.Fatalf("failed to suspend manager: %v", err)
+	}
+
+	expectStatus(t, s.request(managerAKey, http.MethodGet, "/api/v1/bots", ""), http.StatusForbidden)
+	expectStatus(t, s.request(managerBKey, http.MethodGet, "/api/v1/bots", ""), http.StatusOK)
+}
+
+func TestLockdownKeepsOutAllButSuperusers(t *testing.T) {
+	s := newTestServer(t)
+	s.lockdown.Start(superuserID, "maintenance", 0)
+
+	rec := s.request(managerAKey, http.MethodGet, "/api/v1/bots", "")
+	expectStatus(t, rec, http.StatusServiceUnavailable)
+	if !strings.Contains(rec.Body.String(), "maintenance") {
+		t.Fatalf("lockdown response %s does not include the note", rec.Body.String())
+	}
+	expectStatus(t, s.request(superuserKey, http.MethodGet, "/api/v1/bots", ""), http.StatusOK)
+
+	s.lockdown.Lift()
+	expectStatus(t, s.request(managerAKey, http.MethodGet, "/api/v1/bots", ""), http.StatusOK)
+}
+
+func TestCreateBotWithinTierLimit(t *testing.T) {
+	s := newTestServer(t)
+	token := func(botID int64) string {
+		return fmt.Sprintf(`{"token": "%d:%s"}`, botID, strings.Repeat("T", 35))
+	}
+
+	expectStatus(t, s.request(managerAKey, http.MethodPost, "/api/v1/bots", token(101)), http.StatusCreated)
+	// The free tier allows one bot
+	expectStatus(t, s.request(managerAKey, http.MethodPost, "/api/v1/bots", token(102)), http.StatusForbidden)
+	manager, _ := s.users.GetOrCreateByTelegramUserID(managerAID, nil)
+	if bots, _ := s.bots.GetByManagerID(manager.ID); len(bots) != 1 {
+		t.Fatalf("manager has %d bots, want 1", len(bots))
+	}
+
+	// Superusers may register past the limit for a manager
+	expectStatus(t, s.request(superuserKey, http.MethodPost, "/api/v1/bots",
+		fmt.Sprintf(`{"token": "102:%s", "manager_user_id": %d}`, strings.Repeat("T", 35), managerAID)), http.StatusCreated)
+	// Only superusers register bots for others
+	expectStatus(t, s.request(managerBKey, http.MethodPost, "/api/v1/bots",
+		fmt.Sprintf(`{"token": "103:%s", "manager_user_id": %d}`, strings.Repeat("T", 35), managerAID)), http.StatusForbidden)
+
+	expectStatus(t, s.request(managerBKey, http.MethodPost, "/api/v1/bots", `{"token": "not-a-token"}`), http.StatusBadRequest)
+	expectStatus(t, s.request(managerBKey, http.MethodPost, "/api/v1/bots", token(101)), http.StatusConflict)
+}
+
+func TestBanAndUnbanConflicts(t *testing.T) {
+	s := newTestServer(t)
+	forwarderBot := s.createBot(t, managerAID)
+	blacklistPath := "/api/v1/bots/" + forwarderBot.ID.String() + "/blacklist"
+
+	expectStatus(t, s.request(managerAKey, http.MethodPost, blacklistPath, `{"guest_user_id": 42, "reason": "spam"}`), http.StatusCreated)
+	expectStatus(t, s.request(managerAKey, http.MethodPost, blacklistPath, `{"guest_user_id": 42}`), http.StatusConflict)
+	expectStatus(t, s.request(managerAKey, http.MethodDelete, blacklistPath+"/42", ""), http.StatusNoContent)
+	// An unbanned guest cannot be unbanned again
+	expectStatus(t, s.request(managerAKey, http.MethodDelete, blacklistPath+"/42", ""), http.StatusNotFound)
+
+	// A ban waiting for approval in the bot counts as a ban, and cannot be
+	// lifted before it was decided
+	requester, _ := s.users.GetOrCreateByTelegramUserID(managerAID, nil)
+	if _, err := s.blacklist.CreateBanRequest(forwarderBot.ID, 43, requester.ID, ""); err != nil {
+		t.Fatalf("CreateBanRequest: %v", err)
+	}
+	expectStatus(t, s.request(managerAKey, http.MethodPost, blacklistPath, `{"guest_user_id": 43}`), http.StatusConflict)
+	expectStatus(t, s.request(managerAKey, http.MethodDelete, blacklistPath+"/43", ""), http.StatusConflict)
+
+	expectStatus(t, s.request(managerAKey, http.MethodPost, blacklistPath, `{"guest_user_id": 0}`), http.StatusBadRequest)
+	expectStatus(t, s.request(managerAKey, http.MethodDelete, blacklistPath+"/abc", ""), http.StatusBadRequest)
+}
+
+func TestUpdateRecipientIsAudited(t *testing.T) {
+	s := newTestServer(t)
+	forwarderBot := s.createBot(t, managerAID)
+	recipient := &models.Recipient{BotID: forwarderBot.ID, ChatID: -100123, RecipientType: models.RecipientTypeGroup}
+	if err := repository.NewRecipientRepository(s.db).Create(recipient); err != nil {
+		t.Fatalf("failed to create recipient: %v", err)
+	}
+	path := "/api/v1/bots/" + forwarderBot.ID.String() + "/recipients/" + recipient.ID.String()
+
+	expectStatus(t, s.request(managerAKey, http.MethodPatch, path, `{"alias": "Support"}`), http.StatusOK)
+	// Nothing changes, so nothing is recorded
+	expectStatus(t, s.request(managerAKey, http.MethodPatch, path, `{"alias": "Support"}`), http.StatusOK)
+
+	var logs []models.AuditLog
+	if err := s.db.Where("action_type = ?", models.AuditLogActionUpdateRecipient).Find(&logs).Error; err != nil {
+		t.Fatalf("failed to read audit logs: %v", err)
+	}
+	if len(logs) != 1 || logs[0].ResourceID != recipient.ID {
+		t.Fatalf("audit logs = %+v, want one for the recipient", logs)
+	}
+	var details struct {
+		APIKey  string `json:"api_key"`
+		Changes struct {
+			Alias map[string]string `json:"alias"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(logs[0].Details), &details); err != nil {
+		t.Fatalf("failed to decode audit details: %v", err)
+	}
+	if details.APIKey != "manager-a" || details.Changes.Alias["from"] != "" || details.Changes.Alias["to"] != "Support" {
+		t.Fatalf("audit details = %s, want the alias change by manager-a", logs[0].Details)
+	}
+
+	// Another manager cannot change the recipient
+	expectStatus(t, s.request(managerBKey, http.MethodPatch, path, `{"alias": "Mine"}`), http.StatusNotFound)
+}
@@ -0,0 +1,309 @@
+// Package app wires all components of the bot together and runs them.
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/database"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service"
+	"go-telegram-forwarder-bot/internal/service/llm"
+	"go-telegram-forwarder-bot/internal/service/message"
+	"go-telegram-forwarder-bot/internal/service/scheduler"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// stateSaveTimeout bounds saving the in-memory state to Redis on shutdown
+const stateSaveTimeout = 10 * time.Second
+
+// Option customizes how New builds the application
+type Option func(*options)
+
+type options struct {
+	db          *gorm.DB
+	redisClient redis.UniversalClient
+	skipMigrate bool
+	hooks       message.HooksInterface
+	llmProvider llm.Provider
+}
+
+// WithDB uses an existing database connection instead of connecting with the
+// database configuration
+func WithDB(db *gorm.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// WithRedisClient uses an existing Redis client instead of connecting with
+// the Redis configuration. It has no effect when Redis is disabled.
+func WithRedisClient(client redis.UniversalClient) Option {
+	return func(o *options) {
+		o.redisClient = client
+	}
+}
+
+// WithoutMigrations skips the database migrations run on startup, e.g. when
+// the schema is managed separately
+func WithoutMigrations() Option {
+	return func(o *options) {
+		o.skipMigrate = true
+	}
+}
+
+// WithHooks is told about every message forwarded by any ForwarderBot
+func WithHooks(hooks message.HooksInterface) Option {
+	return func(o *options) {
+		o.hooks = hooks
+	}
+}
+
+// WithLLMProvider drafts /suggest replies with the given provider instead of
+// the one configured in llm. Draft replies still need llm.enabled.
+func WithLLMProvider(provider llm.Provider) Option {
+	return func(o *options) {
+		o.llmProvider = provider
+	}
+}
+
+// App is the fully wired application
+type App struct {
+	ctx         context.Context
+	logger      *zap.Logger
+	manager     *managerStage
+	runtime     *runtimeStage
+	scheduler   *scheduler.Scheduler
+	audit       *service.AuditWriter
+	rateLimiter *message.RateLimiter
+	failover    *service.Failover // nil unless failover is enabled
+}
+
+// New connects to the database and Redis and builds every component. Bots are
+// not started until Run is called; ctx bounds the lifetime of the application.
+func New(ctx context.Context, cfg *config.Config, log *zap.Logger, opts ...Option) (*App, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Set date and number formatting
+	locale, err := utils.ParseLocale(cfg.Locale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locale: %w", err)
+	}
+	utils.SetLocale(locale)
+
+	db := o.db
+	if db == nil {
+		db, err = database.Connect(cfg.Database)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+	}
+	if !o.skipMigrate {
+		if err := database.Migrate(db); err != nil {
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
+	log.Info("Database connected and migrated successfully")
+
+	// According to requirements: if Redis is enabled and the connection fails
+	// at startup, terminate directly
+	var redisClient redis.UniversalClient
+	if cfg.Redis.Enabled {
+		redisClient = o.redisClient
+		if redisClient == nil {
+			redisClient, err = database.ConnectRedis(cfg.Redis)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to Redis at startup: %w", err)
+			}
+		}
+		log.Info("Redis connected successfully")
+	}
+
+	repos, err := newRepositories(db, cfg)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newCore(repos, redisClient, o.llmProvider, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	if o.hooks != nil {
+		c.forwarder.SetHooks(o.hooks)
+	}
+	m, err := newManagerStage(db, repos, c, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newRuntimeStage(ctx, db, redisClient, repos, c, m, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Chaos.Enabled {
+		log.Warn("Chaos mode enabled: Bot API requests fail on purpose",
+			zap.Float64("rate_limit_rate", cfg.Chaos.RateLimitRate),
+			zap.Float64("server_error_rate", cfg.Chaos.ServerErrorRate),
+			zap.Float64("timeout_rate", cfg.Chaos.TimeoutRate),
+			zap.Strings("methods", cfg.Chaos.Methods))
+	}
+
+	var failover *service.Failover
+	if cfg.Failover.Enabled {
+		failover = service.NewFailover(repos.workerLock, &cfg.Failover, log)
+	}
+
+	return &App{
+		ctx:         ctx,
+		logger:      log,
+		manager:     m,
+		runtime:     r,
+		scheduler:   newScheduler(c, r, cfg, log),
+		audit:       c.auditWriter,
+		rateLimiter: c.rateLimiter,
+		failover:    failover,
+	}, nil
+}
+
+// saveState writes the in-memory state to Redis on shutdown. The application
+// context is done by then, so saving gets its own deadline.
+func (a *App) saveState() {
+	ctx, cancel := context.WithTimeout(context.Background(), stateSaveTimeout)
+	defer cancel()
+
+	if err := a.runtime.stateStore.Save(ctx); err != nil {
+		a.logger.Warn("Failed to save state to Redis", zap.Error(err))
+		return
+	}
+	saved, err := a.rateLimiter.SaveToRedis(ctx)
+	if err != nil {
+		a.logger.Warn("Failed to save rate limits to Redis", zap.Error(err))
+		return
+	}
+	a.logger.Info("State saved to Redis", zap.Int("rate_limit_buckets", saved))
+}
+
+// RegisterBot registers a ForwarderBot for the manager with the given Telegram
+// user ID, like /addbot, and starts it
+func (a *App) RegisterBot(ctx context.Context, token string, managerTelegramUserID int64) (*models.ForwarderBot, error) {
+	return a.manager.service.RegisterBot(ctx, token, managerTelegramUserID)
+}
+
+// Run starts the monitors, workers and all bots, and blocks until the context
+// passed to New is done. It then stops everything and waits for it to finish.
+// With failover enabled, everything but the monitors waits until this instance
+// holds the lease, and Run returns ErrLeaseLost if the lease is lost.
+func (a *App) Run() error {
+	log := a.logger
+
+	if a.runtime.redisConnection != nil {
+		go a.runtime.redisConnection.Monitor(a.ctx)
+	}
+	go a.runtime.dbHealth.Monitor(a.ctx)
+
+	// A standby instance must not handle any update, including bots started
+	// through the API, until the active instance is gone
+	if a.failover != nil {
+		if err := a.failover.Acquire(a.ctx); err != nil {
+			if a.ctx.Err() != nil {
+				log.Info("Shutdown complete")
+				return nil
+			}
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	defer cancel()
+
+	// The listener must be up before the bots register their webhooks
+	if a.runtime.webhook != nil {
+		if err := a.runtime.webhook.Start(ctx); err != nil {
+			return err
+		}
+	}
+
+	if a.runtime.api != nil {
+		if err := a.runtime.api.Start(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Pick up the state saved by the previous shutdown before any update is handled
+	if a.runtime.stateStore != nil {
+		a.runtime.stateStore.Restore(ctx)
+	}
+
+	// Load all ForwarderBots from database and start them
+	if err := a.runtime.botManager.LoadAllBots(); err != nil {
+		log.Warn("Failed to load some ForwarderBots", zap.Error(err))
+	}
+
+	a.scheduler.Start(ctx)
+
+	// Start ManagerBot
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := a.manager.bot.Start(ctx); err != nil && ctx.Err() == nil {
+			log.Error("ManagerBot error", zap.Error(err))
+		}
+	}()
+
+	// Stop polling as soon as the lease may have passed to a standby
+	var leaseErr error
+	if a.failover != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := a.failover.Hold(ctx); err != nil {
+				log.Error("Stopping: this instance is no longer active", zap.Error(err))
+				leaseErr = err
+				cancel()
+			}
+		}()
+	}
+
+	log.Info("All bots are running. Press Ctrl+C to stop.")
+	<-ctx.Done()
+
+	log.Info("Shutting down...")
+
+	// Stop all bots
+	a.manager.bot.Stop()
+	a.runtime.botManager.StopAll()
+
+	// Wait for all goroutines to finish
+	wg.Wait()
+	a.runtime.botManager.Wait()
+	a.scheduler.Wait()
+
+	// Deliver any buffered manager notification digests
+	a.manager.managerNotifier.Flush()
+
+	// Keep rate limits, cooldowns and pauses for the next start
+	if a.runtime.stateStore != nil {
+		a.saveState()
+	}
+
+	// Write the audit logs still queued
+	a.audit.Close()
+
+	// Hand over to the standby right away instead of after the lease expires
+	if a.failover != nil && leaseErr == nil {
+		a.failover.Release()
+	}
+
+	log.Info("Shutdown complete")
+	return leaseErr
+}
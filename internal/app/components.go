@@ -0,0 +1,449 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"go-telegram-forwarder-bot/internal/api"
+	"go-telegram-forwarder-bot/internal/bot"
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/service"
+	"go-telegram-forwarder-bot/internal/service/adfilter"
+	"go-telegram-forwarder-bot/internal/service/blacklist"
+	"go-telegram-forwarder-bot/internal/service/filescan"
+	"go-telegram-forwarder-bot/internal/service/llm"
+	"go-telegram-forwarder-bot/internal/service/lock"
+	"go-telegram-forwarder-bot/internal/service/manager_bot"
+	"go-telegram-forwarder-bot/internal/service/message"
+	"go-telegram-forwarder-bot/internal/service/ocr"
+	"go-telegram-forwarder-bot/internal/service/scheduler"
+	"go-telegram-forwarder-bot/internal/service/settings"
+	"go-telegram-forwarder-bot/internal/service/statistics"
+	"go-telegram-forwarder-bot/internal/service/transcription"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// The application is built in stages. Each stage only receives the stages
+// it depends on, so a component cannot be used before it has been created and
+// a missing dependency is a compile error rather than a nil pointer at runtime.
+//
+//	repositories → core → managerStage → runtimeStage → scheduler
+
+// repositories holds every repository, all backed by the same database
+type repositories struct {
+	user                     repository.UserRepository
+	bot                      repository.BotRepository
+	recipient                repository.RecipientRepository
+	guest                    repository.GuestRepository
+	blacklist                repository.BlacklistRepository
+	blacklistApprovalMessage repository.BlacklistApprovalMessageRepository
+	botAdmin                 repository.BotAdminRepository
+	messageMapping           repository.MessageMappingRepository
+	inboundMessage           repository.InboundMessageRepository
+	statsDaily               repository.StatsDailyRepository
+	botUsage                 repository.BotUsageRepository
+	welcomeAssignment        repository.WelcomeAssignmentRepository
+	autoReply                repository.AutoReplyRepository
+	filterRule               repository.FilterRuleRepository
+	quarantinedMessage       repository.QuarantinedMessageRepository
+	guestTopic               repository.GuestTopicRepository
+	auditLog                 repository.AuditLogRepository
+	report                   repository.ReportRepository
+	adminInvite              repository.AdminInviteRepository
+	payment                  repository.PaymentRepository
+	workerLock               repository.WorkerLockRepository
+	botSetting               repository.BotSettingRepository
+	superuser                repository.SuperuserRepository
+	pendingDelivery          repository.PendingDeliveryRepository
+	deadLetter               repository.DeadLetterRepository
+	routingRule              repository.RoutingRuleRepository
+	scheduledMessage         repository.ScheduledMessageRepository
+}
+
+func newRepositories(db *gorm.DB, cfg *config.Config) (*repositories, error) {
+	// Guest messages kept for later delivery are encrypted at rest
+	encryptionKey, err := utils.GetEncryptionKeyFromConfig(cfg.EncryptionKey, cfg.Environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+	cipher, err := repository.NewContentCipher(db, encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create content cipher: %w", err)
+	}
+
+	return &repositories{
+		user:                     repository.NewUserRepository(db),
+		bot:                      repository.NewBotRepository(db),
+		recipient:                repository.NewRecipientRepository(db),
+		guest:                    repository.NewGuestRepository(db),
+		blacklist:                repository.NewBlacklistRepository(db),
+		blacklistApprovalMessage: repository.NewBlacklistApprovalMessageRepository(db),
+		botAdmin:                 repository.NewBotAdminRepository(db),
+		messageMapping:           repository.NewMessageMappingRepository(db),
+		inboundMessage:           repository.NewInboundMessageRepository(db),
+		statsDaily:               repository.NewStatsDailyRepository(db),
+		botUsage:                 repository.NewBotUsageRepository(db),
+		welcomeAssignment:        repository.NewWelcomeAssignmentRepository(db),
+		autoReply:                repository.NewAutoReplyRepository(db),
+		filterRule:               repository.NewFilterRuleRepository(db),
+		quarantinedMessage:       repository.NewQuarantinedMessageRepository(db, cipher),
+		guestTopic:               repository.NewGuestTopicRepository(db),
+		auditLog:                 repository.NewAuditLogRepository(db),
+		report:                   repository.NewReportRepository(db),
+		adminInvite:              repository.NewAdminInviteRepository(db),
+		payment:                  repository.NewPaymentRepository(db),
+		workerLock:               repository.NewWorkerLockRepository(db),
+		botSetting:               repository.NewBotSettingRepository(db),
+		superuser:                repository.NewSuperuserRepository(db),
+		pendingDelivery:          repository.NewPendingDeliveryRepository(db, cipher),
+		deadLetter:               repository.NewDeadLetterRepository(db, cipher),
+		routingRule:              repository.NewRoutingRuleRepository(db),
+		scheduledMessage:         repository.NewScheduledMessageRepository(db, cipher),
+	}, nil
+}
+
+// core holds the services that need neither a running bot nor the ErrorNotifier
+type core struct {
+	stats                  *statistics.Service
+	settings               *settings.Service
+	blacklist              *blacklist.Service
+	rateLimiter            *message.RateLimiter
+	loopDetector           *message.LoopDetector
+	floodDetector          *message.FloodDetector
+	retryHandler           *message.RetryHandler
+	workerLocker           lock.Locker
+	redisLocker            *lock.RedisLocker // nil when Redis is disabled
+	groupMonitor           *service.GroupMonitor
+	recipientInfoRefresher *service.RecipientInfoRefresher
+	recipientValidator     *service.RecipientValidator
+	botInfoRefresher       *service.BotInfoRefresher
+	forwarder              *message.Forwarder
+	suggester              *llm.Suggester         // nil unless llm is enabled
+	transcriber            *transcription.Service // nil unless transcription is enabled
+	ocr                    *ocr.Service           // nil unless OCR is enabled
+	fileScanner            *filescan.Service      // nil unless file scanning is enabled
+	adFilter               *adfilter.Service      // nil unless the ad filter is enabled
+	auditWriter            *service.AuditWriter
+	superusers             *service.Superusers
+	featureFlags           *service.FeatureFlags
+	lockdown               *service.Lockdown
+	accessMonitor          *service.AccessMonitor
+	mappingPruner          *service.MappingPruner
+}
+
+func newCore(repos *repositories, redisClient redis.UniversalClient, llmProvider llm.Provider, cfg *config.Config, log *zap.Logger) (*core, error) {
+	// Every component writes audit logs through the background writer
+	auditWriter := service.NewAuditWriter(repos.auditLog, log)
+	repos.auditLog = auditWriter
+
+	c := &core{
+		auditWriter: auditWriter,
+		stats: statistics.NewService(repos.bot, repos.guest, repos.messageMapping, repos.inboundMessage,
+			repos.statsDaily, repos.welcomeAssignment, log),
+		settings:  settings.NewService(repos.bot, repos.botSetting, log),
+		blacklist: blacklist.NewService(repos.blacklist, repos.guest, log),
+		// Rate limiter will handle nil redisClient gracefully
+		rateLimiter:            message.NewRateLimiter(redisClient, cfg, log),
+		loopDetector:           message.NewLoopDetector(cfg),
+		floodDetector:          message.NewFloodDetector(cfg),
+		retryHandler:           message.NewRetryHandler(cfg, log),
+		groupMonitor:           service.NewGroupMonitor(repos.bot, repos.recipient, repos.auditLog, log),
+		recipientInfoRefresher: service.NewRecipientInfoRefresher(repos.recipient, log),
+		recipientValidator:     service.NewRecipientValidator(repos.guest, repos.user, repos.botAdmin, log),
+		botInfoRefresher:       service.NewBotInfoRefresher(repos.bot, log),
+		accessMonitor:          service.NewAccessMonitor(cfg, repos.auditLog, repos.user, log),
+	}
+
+	// Superusers from the config are added to the table on every start
+	superusers, err := service.NewSuperusers(repos.superuser, cfg, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load superusers: %w", err)
+	}
+	c.superusers = superusers
+	// Bots may have their own guest and Telegram API limits
+	c.rateLimiter.SetLimitSource(message.NewBotLimitSource(repos.bot))
+	c.mappingPruner = service.NewMappingPruner(repos.bot, repos.messageMapping, c.settings, cfg, log)
+	c.lockdown = service.NewLockdown(superusers)
+	// Feature flags for manager subscription tiers
+	c.featureFlags = service.NewFeatureFlags(cfg, superusers, repos.user, repos.bot)
+
+	// Worker lock so periodic workers run on only one instance
+	// Use Redis when enabled, otherwise fall back to database leases
+	if redisClient != nil {
+		c.redisLocker = lock.NewRedisLocker(redisClient)
+		c.workerLocker = c.redisLocker
+	} else {
+		c.workerLocker = lock.NewDBLocker(repos.workerLock)
+	}
+
+	c.forwarder = message.NewForwarder(
+		repos.bot,
+		repos.recipient,
+		repos.guest,
+		repos.messageMapping,
+		repos.inboundMessage,
+		repos.guestTopic,
+		c.rateLimiter,
+		c.retryHandler,
+		cfg,
+		log,
+	)
+	c.forwarder.SetGroupMonitor(c.groupMonitor)
+	c.forwarder.SetDeliveryQueue(repos.pendingDelivery)
+	c.forwarder.SetDeadLetters(repos.deadLetter)
+	c.forwarder.SetLoopDetector(c.loopDetector)
+	c.forwarder.SetRoutingRules(repos.routingRule)
+
+	// Draft replies are strictly opt-in: conversation text leaves the instance
+	if cfg.LLM.Enabled {
+		if llmProvider == nil {
+			var err error
+			llmProvider, err = llm.NewProvider(&cfg.LLM, &cfg.Proxy)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create LLM provider: %w", err)
+			}
+		}
+		c.suggester = llm.NewSuggester(llmProvider, &cfg.LLM, log)
+		log.Info("Draft replies enabled", zap.String("model", cfg.LLM.Model))
+	}
+
+	// Voice transcription is opt-in too: audio is sent to the provider
+	if cfg.Transcription.Enabled {
+		var err error
+		c.transcriber, err = transcription.NewService(&cfg.Transcription, &cfg.Proxy, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transcription service: %w", err)
+		}
+		log.Info("Voice transcription enabled", zap.String("model", cfg.Transcription.Model))
+	}
+
+	if cfg.OCR.Enabled {
+		var err error
+		c.ocr, err = ocr.NewService(&cfg.OCR, &cfg.Proxy, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OCR service: %w", err)
+		}
+		log.Info("Image text recognition enabled", zap.String("provider", cfg.OCR.Provider))
+	}
+
+	if cfg.AdFilter.Enabled {
+		c.adFilter = adfilter.NewService(repos.filterRule, repos.quarantinedMessage, log)
+	}
+
+	if cfg.FileScan.Enabled {
+		var err error
+		c.fileScanner, err = filescan.NewService(&cfg.FileScan, &cfg.Proxy, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create file scan service: %w", err)
+		}
+		log.Info("File scanning enabled", zap.String("provider", cfg.FileScan.Provider))
+	}
+
+	return c, nil
+}
+
+// managerStage holds the ManagerBot and the notifiers that send through it
+type managerStage struct {
+	service         *manager_bot.Service
+	bot             *bot.ManagerBot
+	errorNotifier   *service.ErrorNotifier
+	managerNotifier *service.ManagerNotifier
+	quotaEnforcer   *service.QuotaEnforcer
+}
+
+func newManagerStage(db *gorm.DB, repos *repositories, c *core, cfg *config.Config, log *zap.Logger) (*managerStage, error) {
+	managerBotService, err := manager_bot.NewService(
+		db,
+		repos.bot,
+		repos.user,
+		repos.auditLog,
+		repos.recipient,
+		repos.report,
+		c.stats,
+		cfg,
+		log,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ManagerBot service: %w", err)
+	}
+
+	managerBot, err := bot.NewManagerBot(cfg.ManagerBot.Token, managerBotService, log, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ManagerBot: %w", err)
+	}
+
+	m := &managerStage{
+		service:       managerBotService,
+		bot:           managerBot,
+		errorNotifier: service.NewErrorNotifier(managerBot.GetBot(), c.superusers, log),
+	}
+	m.managerNotifier = service.NewManagerNotifier(managerBot.GetBot(), repos.bot, repos.user, log)
+	m.managerNotifier.SetSettings(c.settings)
+	// Per-bot monthly message quotas
+	m.quotaEnforcer = service.NewQuotaEnforcer(repos.bot, repos.botUsage, m.managerNotifier, log)
+
+	m.errorNotifier.SetNotifications(cfg.Notifications)
+	m.errorNotifier.SetUsers(repos.user)
+	c.auditWriter.SetErrorNotifier(m.errorNotifier)
+	c.accessMonitor.SetErrorNotifier(m.errorNotifier)
+	c.forwarder.SetErrorNotifier(m.errorNotifier)
+	c.forwarder.SetManagerNotifier(m.managerNotifier)
+	c.forwarder.SetQuotaEnforcer(m.quotaEnforcer)
+
+	managerBotService.SetQuotaEnforcer(m.quotaEnforcer)
+	managerBotService.SetFeatureFlags(c.featureFlags)
+	managerBotService.SetSettings(c.settings)
+	managerBotService.SetBotInfoRefresher(c.botInfoRefresher)
+	managerBotService.SetAuditLogMonitor(c.auditWriter)
+	managerBotService.SetSuperusers(c.superusers)
+	managerBotService.SetLockdown(c.lockdown)
+	managerBotService.SetDeliveryQueue(repos.pendingDelivery)
+	managerBotService.SetDeadLetters(repos.deadLetter)
+	managerBotService.SetAccessMonitor(c.accessMonitor)
+	managerBotService.SetMappingPruner(c.mappingPruner)
+
+	return m, nil
+}
+
+// runtimeStage holds the connection monitors, the webhook and API servers and
+// the BotManager
+type runtimeStage struct {
+	dbHealth        *service.DBHealth
+	redisConnection *service.RedisConnection // nil when Redis is disabled
+	stateStore      *service.StateStore      // nil when Redis is disabled
+	webhook         *bot.WebhookServer       // nil when long polling
+	botManager      *bot.BotManager
+	api             *api.Server // nil unless the HTTP API is enabled
+}
+
+func newRuntimeStage(ctx context.Context, db *gorm.DB, redisClient redis.UniversalClient, repos *repositories, c *core, m *managerStage, cfg *config.Config, log *zap.Logger) (*runtimeStage, error) {
+	r := &runtimeStage{
+		// Bots hold updates while the database is unreachable
+		dbHealth: service.NewDBHealth(db, m.errorNotifier, log),
+	}
+	m.bot.SetDBHealth(r.dbHealth)
+
+	// Reconnected clients are handed to the rate limiter and worker lock
+	if redisClient != nil {
+		r.redisConnection = service.NewRedisConnection(redisClient, cfg.Redis, log)
+		r.redisConnection.SetErrorNotifier(m.errorNotifier)
+		r.redisConnection.OnChange(c.rateLimiter.SetRedisClient)
+		r.redisConnection.OnChange(c.redisLocker.SetClient)
+
+		// In-memory cooldowns and pauses are kept across restarts
+		r.stateStore = service.NewStateStore(r.redisConnection, log)
+		r.stateStore.Register("loop_detector", c.loopDetector)
+		r.stateStore.Register("manager_notifier", m.managerNotifier)
+		r.stateStore.Register("error_notifier", m.errorNotifier)
+		r.stateStore.Register("lockdown", c.lockdown)
+	}
+
+	if cfg.Webhook.Enabled {
+		webhook, err := bot.NewWebhookServer(cfg.Webhook, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webhook server: %w", err)
+		}
+		r.webhook = webhook
+		m.bot.SetWebhook(webhook)
+	}
+
+	botManager, err := bot.NewBotManager(bot.BotManagerParams{
+		Ctx:                          ctx,
+		BotRepo:                      repos.bot,
+		RecipientRepo:                repos.recipient,
+		GuestRepo:                    repos.guest,
+		BlacklistRepo:                repos.blacklist,
+		BlacklistApprovalMessageRepo: repos.blacklistApprovalMessage,
+		BotAdminRepo:                 repos.botAdmin,
+		MessageMappingRepo:           repos.messageMapping,
+		InboundMessageRepo:           repos.inboundMessage,
+		UserRepo:                     repos.user,
+		AuditLogRepo:                 repos.auditLog,
+		ReportRepo:                   repos.report,
+		AdminInviteRepo:              repos.adminInvite,
+		PaymentRepo:                  repos.payment,
+		WelcomeAssignmentRepo:        repos.welcomeAssignment,
+		AutoReplyRepo:                repos.autoReply,
+		GuestTopicRepo:               repos.guestTopic,
+		PendingDeliveryRepo:          repos.pendingDelivery,
+		DeadLetterRepo:               repos.deadLetter,
+		RoutingRuleRepo:              repos.routingRule,
+		ScheduledMessageRepo:         repos.scheduledMessage,
+		BlacklistService:             c.blacklist,
+		StatsService:                 c.stats,
+		SettingsService:              c.settings,
+		GroupMonitor:                 c.groupMonitor,
+		RecipientInfoRefresher:       c.recipientInfoRefresher,
+		RecipientValidator:           c.recipientValidator,
+		BotInfoRefresher:             c.botInfoRefresher,
+		RateLimiter:                  c.rateLimiter,
+		LoopDetector:                 c.loopDetector,
+		FloodDetector:                c.floodDetector,
+		RetryHandler:                 c.retryHandler,
+		ErrorNotifier:                m.errorNotifier,
+		DBHealth:                     r.dbHealth,
+		ManagerNotifier:              m.managerNotifier,
+		QuotaEnforcer:                m.quotaEnforcer,
+		Suggester:                    c.suggester,
+		Transcriber:                  c.transcriber,
+		OCR:                          c.ocr,
+		FileScanner:                  c.fileScanner,
+		AdFilter:                     c.adFilter,
+		Lockdown:                     c.lockdown,
+		AccessMonitor:                c.accessMonitor,
+		FeatureFlags:                 c.featureFlags,
+		Webhook:                      r.webhook,
+		Config:                       cfg,
+		Logger:                       log,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BotManager: %w", err)
+	}
+	r.botManager = botManager
+	// Features that need other update types take effect by restarting the bot
+	c.settings.SetChangeListener(botManager.SettingChanged)
+
+	// Enable dynamic bot management from the ManagerBot
+	m.service.SetBotManager(botManager)
+
+	if cfg.API.Enabled {
+		r.api = api.NewServer(api.ServerParams{
+			Config:                 cfg,
+			BotRepo:                repos.bot,
+			RecipientRepo:          repos.recipient,
+			BotAdminRepo:           repos.botAdmin,
+			UserRepo:               repos.user,
+			AuditLogRepo:           repos.auditLog,
+			BlacklistService:       c.blacklist,
+			StatsService:           c.stats,
+			ManagerService:         m.service,
+			Lockdown:               c.lockdown,
+			BotManager:             botManager,
+			RecipientInfoRefresher: c.recipientInfoRefresher,
+			RecipientValidator:     c.recipientValidator,
+			Logger:                 log,
+		})
+	}
+
+	return r, nil
+}
+
+// newScheduler registers the periodic workers
+func newScheduler(c *core, r *runtimeStage, cfg *config.Config, log *zap.Logger) *scheduler.Scheduler {
+	s := scheduler.New(c.workerLocker, log)
+	s.Register("blacklist_auto_approve", cfg.Workers.AutoApprove, c.blacklist.AutoApproveExpired)
+	s.Register("group_check", cfg.Workers.GroupCheck, r.botManager.CheckAllGroups)
+	s.Register("recipient_info", cfg.Workers.RecipientInfo, r.botManager.RefreshRecipientInfo)
+	s.Register("bot_info", cfg.Workers.BotInfo, r.botManager.RefreshBotInfo)
+	s.Register("stats_daily", cfg.Workers.StatsDaily, c.stats.RollupDaily)
+	s.Register("retry_queue", cfg.Workers.RetryQueue, r.botManager.RetryQueuedDeliveries)
+	s.Register("mapping_prune", cfg.Workers.MappingPrune, c.mappingPruner.Prune)
+	s.Register("mute_expiry", cfg.Workers.MuteExpiry, c.blacklist.LiftExpiredMutes)
+	s.Register("later", cfg.Workers.Later, r.botManager.DeliverScheduledMessages)
+	return s
+}
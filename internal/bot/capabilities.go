@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"slices"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+)
+
+// baseForwarderUpdates are the update types every ForwarderBot handles:
+// guest messages, commands and button presses
+var baseForwarderUpdates = []string{"message", "callback_query"}
+
+// capability is an optional ForwarderBot feature and the update types it needs
+// from Telegram. A bot asks only for the update types of its enabled
+// capabilities, so a feature that handles a new update type adds an entry here.
+type capability struct {
+	name    string
+	updates []string
+	// keys are the settings enabled depends on; changing one of them makes
+	// the bot recompute its update types
+	keys []string
+	// enabled reports whether the bot uses the feature. nil means always.
+	enabled func(bot *models.ForwarderBot, settings *settings.Service) bool
+}
+
+var forwarderCapabilities = []capability{
+	{
+		name:    "paywall",
+		updates: []string{"pre_checkout_query"},
+		keys:    []string{settings.KeyPaywallMode},
+		enabled: func(bot *models.ForwarderBot, _ *settings.Service) bool {
+			return bot.PaywallMode != models.PaywallModeOff
+		},
+	},
+	{
+		name:    "reaction_sync",
+		updates: []string{"message_reaction"},
+		keys:    []string{settings.KeyReactionSync},
+		enabled: func(bot *models.ForwarderBot, settingsService *settings.Service) bool {
+			return settingsService != nil && settingsService.GetBool(bot.ID, settings.KeyReactionSync)
+		},
+	},
+}
+
+// forwarderUpdates returns the update types the bot needs for its enabled
+// capabilities, in a stable order. settingsService may be nil, in which case
+// features are judged by the bot alone.
+func forwarderUpdates(bot *models.ForwarderBot, settingsService *settings.Service) []string {
+	updates := slices.Clone(baseForwarderUpdates)
+	for _, c := range forwarderCapabilities {
+		if c.enabled != nil && !c.enabled(bot, settingsService) {
+			continue
+		}
+		for _, update := range c.updates {
+			if !slices.Contains(updates, update) {
+				updates = append(updates, update)
+			}
+		}
+	}
+	return updates
+}
+
+// affectsUpdates reports whether the setting decides which update types a
+// ForwarderBot needs
+func affectsUpdates(key string) bool {
+	for _, c := range forwarderCapabilities {
+		if slices.Contains(c.keys, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"go-telegram-forwarder-bot/internal/config"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+// chaosClient fails a share of Bot API requests the way Telegram and the
+// network do, so retries, the retry queue, dead letters and failure
+// notifications can be exercised without a real outage. Requests that are
+// not failed go to the wrapped client unchanged.
+type chaosClient struct {
+	gotgbot.BotClient
+	cfg    config.ChaosConfig
+	logger *zap.Logger
+	// random returns a random number in [0, 1)
+	random func() float64
+}
+
+// withChaos wraps the client of opts in a chaosClient when chaos mode is enabled
+func withChaos(opts *gotgbot.BotOpts, cfg config.ChaosConfig, logger *zap.Logger) *gotgbot.BotOpts {
+	if !cfg.Enabled {
+		return opts
+	}
+	if opts == nil {
+		opts = &gotgbot.BotOpts{}
+	}
+	client := opts.BotClient
+	if client == nil {
+		client = &gotgbot.BaseBotClient{}
+	}
+	opts.BotClient = &chaosClient{
+		BotClient: client,
+		cfg:       cfg,
+		logger:    logger,
+		random:    rand.Float64,
+	}
+	return opts
+}
+
+func (c *chaosClient) RequestWithContext(ctx context.Context, token string, method string, params map[string]string, data map[string]gotgbot.FileReader, opts *gotgbot.RequestOpts) (json.RawMessage, error) {
+	if err := c.inject(method, params); err != nil {
+		c.logger.Debug("Injected Telegram API failure",
+			zap.String("method", method),
+			zap.Error(err))
+		return nil, err
+	}
+	return c.BotClient.RequestWithContext(ctx, token, method, params, data, opts)
+}
+
+// inject picks the failure of one request, or nil to let it through. The
+// rates are shares of all affected requests, so they add up.
+func (c *chaosClient) inject(method string, params map[string]string) error {
+	if !c.affects(method) {
+		return nil
+	}
+	roll := c.random()
+	if roll < c.cfg.RateLimitRate {
+		return &gotgbot.TelegramError{
+			Method:         method,
+			Params:         params,
+			Code:           429,
+			Description:    fmt.Sprintf("Too Many Requests: retry after %d", c.cfg.RetryAfterSeconds),
+			ResponseParams: &gotgbot.ResponseParameters{RetryAfter: int64(c.cfg.RetryAfterSeconds)},
+		}
+	}
+	roll -= c.cfg.RateLimitRate
+	if roll < c.cfg.ServerErrorRate {
+		return &gotgbot.TelegramError{
+			Method:      method,
+			Params:      params,
+			Code:        500,
+			Description: "Internal Server Error",
+		}
+	}
+	roll -= c.cfg.ServerErrorRate
+	if roll < c.cfg.TimeoutRate {
+		// Worded and wrapped like a timeout of the real client
+		return fmt.Errorf("failed to execute POST request to %s: %w", method, chaosTimeoutError{})
+	}
+	return nil
+}
+
+// affects reports whether failures are injected into method. getMe is left
+// alone unless listed, since a failed getMe keeps the bot from starting.
+func (c *chaosClient) affects(method string) bool {
+	if len(c.cfg.Methods) == 0 {
+		return method != "getMe"
+	}
+	return slices.Contains(c.cfg.Methods, method)
+}
+
+// chaosTimeoutError is an injected request timeout. It is a net.Error, like
+// the error of a real timeout.
+type chaosTimeoutError struct{}
+
+func (chaosTimeoutError) Error() string   { return "injected timeout: context deadline exceeded" }
+func (chaosTimeoutError) Timeout() bool   { return true }
+func (chaosTimeoutError) Temporary() bool { return true }
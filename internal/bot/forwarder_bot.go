@@ -7,7 +7,9 @@ import (
 	"sync"
 
 	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/service"
 	"go-telegram-forwarder-bot/internal/service/forwarder_bot"
+	"go-telegram-forwarder-bot/internal/service/message"
 	"go-telegram-forwarder-bot/internal/utils"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
@@ -17,13 +19,18 @@ import (
 )
 
 type ForwarderBot struct {
-	botID    uuid.UUID
-	bot      *gotgbot.Bot
-	updater  *ext.Updater
-	service  *forwarder_bot.Service
-	logger   *zap.Logger
-	stop     chan struct{}
-	stopOnce sync.Once
+	botID     uuid.UUID
+	bot       *gotgbot.Bot
+	updater   *ext.Updater
+	service   *forwarder_bot.Service
+	forwarder *message.Forwarder
+	dbHealth  *service.DBHealth
+	webhook   *WebhookServer
+	polling   config.PollingConfig
+	updates   updateOptions
+	logger    *zap.Logger
+	stop      chan struct{}
+	stopOnce  sync.Once
 }
 
 func NewForwarderBot(token string, botID uuid.UUID, service *forwarder_bot.Service, logger *zap.Logger, cfg *config.Config) (*ForwarderBot, error) {
@@ -52,6 +59,11 @@ func NewForwarderBot(token string, botID uuid.UUID, service *forwarder_bot.Servi
 			zap.String("proxy_url", cfg.Proxy.URL))
 	}
 
+	botOpts = utils.WithBotAPIURL(botOpts, cfg.BotAPI.URL)
+
+	// Test deployments may fail requests on purpose
+	botOpts = withChaos(botOpts, cfg.Chaos, logger)
+
 	b, err := gotgbot.NewBot(token, botOpts)
 	if err != nil {
 		return nil, err
@@ -67,6 +79,8 @@ func NewForwarderBot(token string, botID uuid.UUID, service *forwarder_bot.Servi
 		bot:     b,
 		updater: updater,
 		service: service,
+		polling: cfg.Polling,
+		updates: forwarderUpdateOptions(cfg.Polling, botID, b.Username, baseForwarderUpdates),
 		logger:  logger,
 		stop:    make(chan struct{}),
 	}, nil
@@ -92,18 +106,15 @@ func (fb *ForwarderBot) Start(ctx context.Context) error {
 
 	// Create a handler that processes all updates
 	handler := &forwarderUpdateHandler{
-		bot:     fb.bot,
-		service: fb.service,
-		logger:  fb.logger,
-		ctx:     ctx,
+		bot:      fb.bot,
+		service:  fb.service,
+		dbHealth: fb.dbHealth,
+		logger:   fb.logger,
+		ctx:      ctx,
 	}
 	dp.AddHandlerToGroup(handler, 0)
 
-	// Start polling
-	err := fb.updater.StartPolling(fb.bot, &ext.PollingOpts{
-		DropPendingUpdates: true,
-	})
-	if err != nil {
+	if err := startUpdates(fb.bot, fb.updater, fb.webhook, fb.updates); err != nil {
 		return err
 	}
 
@@ -122,6 +133,9 @@ func (fb *ForwarderBot) Start(ctx context.Context) error {
 func (fb *ForwarderBot) Stop() {
 	fb.stopOnce.Do(func() {
 		close(fb.stop)
+		if fb.webhook != nil {
+			fb.webhook.unroute(botPath(fb.bot.Token))
+		}
 		fb.updater.Stop()
 		fb.logger.Info("ForwarderBot stopped",
 			zap.String("bot_id", fb.botID.String()))
@@ -136,11 +150,45 @@ func (fb *ForwarderBot) GetBot() *gotgbot.Bot {
 	return fb.bot
 }
 
+// SetDBHealth makes the bot hold updates while the database is unreachable.
+// It must be called before Start.
+func (fb *ForwarderBot) SetDBHealth(dbHealth *service.DBHealth) {
+	fb.dbHealth = dbHealth
+}
+
+// SetWebhook makes the bot receive updates through the webhook server instead
+// of long polling. It must be called before Start.
+func (fb *ForwarderBot) SetWebhook(webhook *WebhookServer) {
+	fb.webhook = webhook
+}
+
+// SetHandledUpdates sets the update types the bot asks Telegram for, see
+// forwarderUpdates. It must be called before Start.
+func (fb *ForwarderBot) SetHandledUpdates(handled []string) {
+	fb.updates = forwarderUpdateOptions(fb.polling, fb.botID, fb.bot.Username, handled)
+}
+
+// HandledUpdates returns the update types the bot asks Telegram for
+func (fb *ForwarderBot) HandledUpdates() []string {
+	return fb.updates.allowedUpdates
+}
+
+// SetForwarder sets the message forwarder used to retry the bot's queued deliveries
+func (fb *ForwarderBot) SetForwarder(forwarder *message.Forwarder) {
+	fb.forwarder = forwarder
+}
+
+// Forwarder returns the bot's message forwarder, or nil if none was set
+func (fb *ForwarderBot) Forwarder() *message.Forwarder {
+	return fb.forwarder
+}
+
 type forwarderUpdateHandler struct {
-	bot     *gotgbot.Bot
-	service *forwarder_bot.Service
-	logger  *zap.Logger
-	ctx     context.Context
+	bot      *gotgbot.Bot
+	service  *forwarder_bot.Service
+	dbHealth *service.DBHealth
+	logger   *zap.Logger
+	ctx      context.Context
 }
 
 func (h *forwarderUpdateHandler) CheckUpdate(b *gotgbot.Bot, ctx *ext.Context) bool {
@@ -155,6 +203,10 @@ func (h *forwarderUpdateHandler) HandleUpdate(b *gotgbot.Bot, ctx *ext.Context)
 		zap.Bool("has_message", update.Message != nil),
 		zap.Bool("has_callback_query", update.CallbackQuery != nil))
 
+	if !waitForDB(h.ctx, h.dbHealth, h.logger, update) {
+		return nil
+	}
+
 	// Handle callback queries
 	if update.CallbackQuery != nil {
 		h.logger.Debug("Processing callback query",
@@ -174,11 +226,30 @@ func (h *forwarderUpdateHandler) HandleUpdate(b *gotgbot.Bot, ctx *ext.Context)
 		return err
 	}
 
+	// Handle paywall pre-checkout queries
+	if update.PreCheckoutQuery != nil {
+		h.logger.Debug("Processing pre-checkout query",
+			zap.String("query_id", update.PreCheckoutQuery.Id),
+			zap.Int64("user_id", update.PreCheckoutQuery.From.Id))
+		return h.service.HandlePreCheckoutQuery(h.ctx, b, ctx)
+	}
+
+	// Handle reactions, only sent to bots that sync them
+	if update.MessageReaction != nil {
+		h.logger.Debug("Processing message reaction",
+			zap.Int64("chat_id", update.MessageReaction.Chat.Id),
+			zap.Int64("message_id", update.MessageReaction.MessageId))
+		return h.service.HandleMessageReaction(h.ctx, b, ctx)
+	}
+
 	// Handle messages
 	if update.Message != nil {
 		message := update.Message
 		text := message.Text
-		userID := message.From.Id
+		var userID int64
+		if message.From != nil {
+			userID = message.From.Id
+		}
 		chatID := message.Chat.Id
 		messageID := message.MessageId
 
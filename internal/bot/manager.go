@@ -2,22 +2,35 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
+	"time"
 
 	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
 	"go-telegram-forwarder-bot/internal/repository"
 	"go-telegram-forwarder-bot/internal/service"
+	"go-telegram-forwarder-bot/internal/service/adfilter"
 	"go-telegram-forwarder-bot/internal/service/blacklist"
+	"go-telegram-forwarder-bot/internal/service/filescan"
 	"go-telegram-forwarder-bot/internal/service/forwarder_bot"
+	"go-telegram-forwarder-bot/internal/service/llm"
 	"go-telegram-forwarder-bot/internal/service/message"
+	"go-telegram-forwarder-bot/internal/service/ocr"
+	"go-telegram-forwarder-bot/internal/service/settings"
 	"go-telegram-forwarder-bot/internal/service/statistics"
+	"go-telegram-forwarder-bot/internal/service/transcription"
 	"go-telegram-forwarder-bot/internal/utils"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// ErrBotNotRunning is returned for operations that need a running ForwarderBot
+var ErrBotNotRunning = errors.New("bot is not running")
+
 // BotManagerParams contains all dependencies for creating a BotManager
 type BotManagerParams struct {
 	Ctx                          context.Context
@@ -28,15 +41,43 @@ type BotManagerParams struct {
 	BlacklistApprovalMessageRepo repository.BlacklistApprovalMessageRepository
 	BotAdminRepo                 repository.BotAdminRepository
 	MessageMappingRepo           repository.MessageMappingRepository
+	InboundMessageRepo           repository.InboundMessageRepository
 	UserRepo                     repository.UserRepository
 	AuditLogRepo                 repository.AuditLogRepository
+	ReportRepo                   repository.ReportRepository
+	AdminInviteRepo              repository.AdminInviteRepository
+	PaymentRepo                  repository.PaymentRepository
+	WelcomeAssignmentRepo        repository.WelcomeAssignmentRepository
+	AutoReplyRepo                repository.AutoReplyRepository
+	GuestTopicRepo               repository.GuestTopicRepository
+	PendingDeliveryRepo          repository.PendingDeliveryRepository
+	DeadLetterRepo               repository.DeadLetterRepository
+	RoutingRuleRepo              repository.RoutingRuleRepository
+	ScheduledMessageRepo         repository.ScheduledMessageRepository
 	BlacklistService             *blacklist.Service
 	StatsService                 *statistics.Service
+	SettingsService              *settings.Service
 	GroupMonitor                 *service.GroupMonitor
+	RecipientInfoRefresher       *service.RecipientInfoRefresher
+	RecipientValidator           *service.RecipientValidator
+	BotInfoRefresher             *service.BotInfoRefresher
 	RateLimiter                  *message.RateLimiter
+	LoopDetector                 *message.LoopDetector
+	FloodDetector                *message.FloodDetector
 	RetryHandler                 *message.RetryHandler
 	ErrorNotifier                *service.ErrorNotifier
+	DBHealth                     *service.DBHealth
+	Webhook                      *WebhookServer // nil when long polling
 	ManagerNotifier              *service.ManagerNotifier
+	QuotaEnforcer                *service.QuotaEnforcer
+	Suggester                    *llm.Suggester         // nil unless llm is enabled
+	Transcriber                  *transcription.Service // nil unless transcription is enabled
+	OCR                          *ocr.Service           // nil unless OCR is enabled
+	FileScanner                  *filescan.Service      // nil unless file scanning is enabled
+	AdFilter                     *adfilter.Service      // nil unless the ad filter is enabled
+	Lockdown                     *service.Lockdown
+	AccessMonitor                *service.AccessMonitor
+	FeatureFlags                 *service.FeatureFlags
 	Config                       *config.Config
 	Logger                       *zap.Logger
 }
@@ -53,15 +94,43 @@ type BotManager struct {
 	blacklistApprovalMessageRepo repository.BlacklistApprovalMessageRepository
 	botAdminRepo                 repository.BotAdminRepository
 	messageMappingRepo           repository.MessageMappingRepository
+	inboundMessageRepo           repository.InboundMessageRepository
 	userRepo                     repository.UserRepository
 	auditLogRepo                 repository.AuditLogRepository
+	reportRepo                   repository.ReportRepository
+	adminInviteRepo              repository.AdminInviteRepository
+	paymentRepo                  repository.PaymentRepository
+	welcomeAssignmentRepo        repository.WelcomeAssignmentRepository
+	autoReplyRepo                repository.AutoReplyRepository
+	guestTopicRepo               repository.GuestTopicRepository
+	pendingDeliveryRepo          repository.PendingDeliveryRepository
+	deadLetterRepo               repository.DeadLetterRepository
+	routingRuleRepo              repository.RoutingRuleRepository
+	scheduledMessageRepo         repository.ScheduledMessageRepository
 	blacklistService             *blacklist.Service
 	statsService                 *statistics.Service
+	settingsService              *settings.Service
 	groupMonitor                 *service.GroupMonitor
+	recipientInfoRefresher       *service.RecipientInfoRefresher
+	recipientValidator           *service.RecipientValidator
+	botInfoRefresher             *service.BotInfoRefresher
 	rateLimiter                  *message.RateLimiter
+	loopDetector                 *message.LoopDetector
+	floodDetector                *message.FloodDetector
 	retryHandler                 *message.RetryHandler
 	errorNotifier                *service.ErrorNotifier
+	dbHealth                     *service.DBHealth
+	webhook                      *WebhookServer
 	managerNotifier              *service.ManagerNotifier
+	quotaEnforcer                *service.QuotaEnforcer
+	suggester                    *llm.Suggester
+	transcriber                  *transcription.Service
+	ocr                          *ocr.Service
+	fileScanner                  *filescan.Service
+	adFilter                     *adfilter.Service
+	lockdown                     *service.Lockdown
+	accessMonitor                *service.AccessMonitor
+	featureFlags                 *service.FeatureFlags
 	config                       *config.Config
 	logger                       *zap.Logger
 	encryptionKey                []byte
@@ -85,15 +154,43 @@ func NewBotManager(params BotManagerParams) (*BotManager, error) {
 		blacklistApprovalMessageRepo: params.BlacklistApprovalMessageRepo,
 		botAdminRepo:                 params.BotAdminRepo,
 		messageMappingRepo:           params.MessageMappingRepo,
+		inboundMessageRepo:           params.InboundMessageRepo,
 		userRepo:                     params.UserRepo,
 		auditLogRepo:                 params.AuditLogRepo,
+		reportRepo:                   params.ReportRepo,
+		adminInviteRepo:              params.AdminInviteRepo,
+		paymentRepo:                  params.PaymentRepo,
+		welcomeAssignmentRepo:        params.WelcomeAssignmentRepo,
+		autoReplyRepo:                params.AutoReplyRepo,
+		guestTopicRepo:               params.GuestTopicRepo,
+		pendingDeliveryRepo:          params.PendingDeliveryRepo,
+		deadLetterRepo:               params.DeadLetterRepo,
+		routingRuleRepo:              params.RoutingRuleRepo,
+		scheduledMessageRepo:         params.ScheduledMessageRepo,
 		blacklistService:             params.BlacklistService,
 		statsService:                 params.StatsService,
+		settingsService:              params.SettingsService,
 		groupMonitor:                 params.GroupMonitor,
+		recipientInfoRefresher:       params.RecipientInfoRefresher,
+		recipientValidator:           params.RecipientValidator,
+		botInfoRefresher:             params.BotInfoRefresher,
 		rateLimiter:                  params.RateLimiter,
+		loopDetector:                 params.LoopDetector,
+		floodDetector:                params.FloodDetector,
 		retryHandler:                 params.RetryHandler,
 		errorNotifier:                params.ErrorNotifier,
+		dbHealth:                     params.DBHealth,
+		webhook:                      params.Webhook,
 		managerNotifier:              params.ManagerNotifier,
+		quotaEnforcer:                params.QuotaEnforcer,
+		suggester:                    params.Suggester,
+		transcriber:                  params.Transcriber,
+		ocr:                          params.OCR,
+		fileScanner:                  params.FileScanner,
+		adFilter:                     params.AdFilter,
+		lockdown:                     params.Lockdown,
+		accessMonitor:                params.AccessMonitor,
+		featureFlags:                 params.FeatureFlags,
 		config:                       params.Config,
 		logger:                       params.Logger,
 		encryptionKey:                encryptionKey,
@@ -111,6 +208,12 @@ func (bm *BotManager) LoadAllBots() error {
 		zap.Int("bot_count", len(bots)))
 
 	for _, botModel := range bots {
+		if botModel.Manager.IsSuspended() {
+			bm.logger.Debug("Skipping bot of suspended manager",
+				zap.String("bot_id", botModel.ID.String()),
+				zap.String("manager_id", botModel.ManagerID.String()))
+			continue
+		}
 		if err := bm.StartBot(botModel.ID); err != nil {
 			bm.logger.Warn("Failed to start bot",
 				zap.String("bot_id", botModel.ID.String()),
@@ -127,18 +230,9 @@ func (bm *BotManager) LoadAllBots() error {
 // StartBot starts a ForwarderBot by its ID
 // botID can be uuid.UUID or any type that can be converted to uuid.UUID
 func (bm *BotManager) StartBot(botID interface{}) error {
-	var id uuid.UUID
-	switch v := botID.(type) {
-	case uuid.UUID:
-		id = v
-	case string:
-		parsed, err := uuid.Parse(v)
-		if err != nil {
-			return fmt.Errorf("invalid bot ID format: %w", err)
-		}
-		id = parsed
-	default:
-		return fmt.Errorf("unsupported bot ID type: %T", botID)
+	id, err := parseBotID(botID)
+	if err != nil {
+		return err
 	}
 	return bm.startBot(id)
 }
@@ -160,6 +254,10 @@ func (bm *BotManager) startBot(botID uuid.UUID) error {
 		return fmt.Errorf("failed to get bot from database: %w", err)
 	}
 
+	if botModel.Manager.IsSuspended() {
+		return fmt.Errorf("manager of bot %s is suspended", botID.String())
+	}
+
 	bm.logger.Debug("Starting ForwarderBot",
 		zap.String("bot_id", botID.String()),
 		zap.String("bot_name", botModel.Name))
@@ -170,6 +268,8 @@ func (bm *BotManager) startBot(botID uuid.UUID) error {
 		bm.recipientRepo,
 		bm.guestRepo,
 		bm.messageMappingRepo,
+		bm.inboundMessageRepo,
+		bm.guestTopicRepo,
 		bm.rateLimiter,
 		bm.retryHandler,
 		bm.config,
@@ -178,6 +278,21 @@ func (bm *BotManager) startBot(botID uuid.UUID) error {
 	botMessageForwarder.SetGroupMonitor(bm.groupMonitor)
 	botMessageForwarder.SetErrorNotifier(bm.errorNotifier)
 	botMessageForwarder.SetManagerNotifier(bm.managerNotifier)
+	if bm.quotaEnforcer != nil {
+		botMessageForwarder.SetQuotaEnforcer(bm.quotaEnforcer)
+	}
+	if bm.pendingDeliveryRepo != nil {
+		botMessageForwarder.SetDeliveryQueue(bm.pendingDeliveryRepo)
+	}
+	if bm.deadLetterRepo != nil {
+		botMessageForwarder.SetDeadLetters(bm.deadLetterRepo)
+	}
+	if bm.loopDetector != nil {
+		botMessageForwarder.SetLoopDetector(bm.loopDetector)
+	}
+	if bm.routingRuleRepo != nil {
+		botMessageForwarder.SetRoutingRules(bm.routingRuleRepo)
+	}
 
 	// Create ForwarderBot service
 	forwarderBotService, err := forwarder_bot.NewService(
@@ -191,6 +306,11 @@ func (bm *BotManager) startBot(botID uuid.UUID) error {
 		bm.messageMappingRepo,
 		bm.userRepo,
 		bm.auditLogRepo,
+		bm.reportRepo,
+		bm.adminInviteRepo,
+		bm.paymentRepo,
+		bm.welcomeAssignmentRepo,
+		bm.autoReplyRepo,
 		botMessageForwarder,
 		bm.blacklistService,
 		bm.statsService,
@@ -200,6 +320,50 @@ func (bm *BotManager) startBot(botID uuid.UUID) error {
 	if err != nil {
 		return fmt.Errorf("failed to create ForwarderBot service: %w", err)
 	}
+	forwarderBotService.SetSuperuserNotifier(bm.errorNotifier)
+	forwarderBotService.SetRecipientInfoRefresher(bm.recipientInfoRefresher)
+	if bm.recipientValidator != nil {
+		forwarderBotService.SetRecipientValidator(bm.recipientValidator)
+	}
+	if bm.settingsService != nil {
+		forwarderBotService.SetSettings(bm.settingsService)
+	}
+	// The service reads the bot's settings and history to describe guests
+	botMessageForwarder.SetProfileCards(forwarderBotService)
+	if bm.suggester != nil {
+		forwarderBotService.SetSuggester(bm.suggester)
+	}
+	if bm.transcriber != nil {
+		forwarderBotService.SetTranscriber(bm.transcriber)
+	}
+	if bm.ocr != nil {
+		forwarderBotService.SetOCR(bm.ocr)
+	}
+	if bm.adFilter != nil {
+		forwarderBotService.SetAdFilter(bm.adFilter)
+	}
+	if bm.routingRuleRepo != nil {
+		forwarderBotService.SetRoutingRules(bm.routingRuleRepo)
+	}
+	if bm.fileScanner != nil {
+		forwarderBotService.SetFileScanner(bm.fileScanner)
+	}
+	forwarderBotService.SetManagerNotifier(bm.managerNotifier)
+	if bm.lockdown != nil {
+		forwarderBotService.SetLockdown(bm.lockdown)
+	}
+	if bm.accessMonitor != nil {
+		forwarderBotService.SetAccessMonitor(bm.accessMonitor)
+	}
+	if bm.floodDetector != nil {
+		forwarderBotService.SetFloodDetector(bm.floodDetector)
+	}
+	if bm.featureFlags != nil {
+		forwarderBotService.SetFeatureFlags(bm.featureFlags)
+	}
+	if bm.scheduledMessageRepo != nil {
+		forwarderBotService.SetScheduledMessages(bm.scheduledMessageRepo)
+	}
 
 	// Create ForwarderBot instance
 	forwarderBot, err := NewForwarderBotFromEncrypted(
@@ -213,12 +377,19 @@ func (bm *BotManager) startBot(botID uuid.UUID) error {
 	if err != nil {
 		return fmt.Errorf("failed to create ForwarderBot instance: %w", err)
 	}
-
-	// Start group monitoring for this bot
-	botInstance := forwarderBot.GetBot()
-	if botInstance != nil {
-		monitorCtx := context.Background()
-		go bm.groupMonitor.StartPeriodicCheck(monitorCtx, botInstance, botID)
+	forwarderBot.SetDBHealth(bm.dbHealth)
+	forwarderBot.SetWebhook(bm.webhook)
+	forwarderBot.SetForwarder(botMessageForwarder)
+	forwarderBot.SetHandledUpdates(forwarderUpdates(botModel, bm.settingsService))
+
+	// Bots registered before Telegram IDs were stored get theirs on first start
+	if botModel.TelegramBotID == 0 {
+		botModel.TelegramBotID = forwarderBot.GetBot().Id
+		if err := bm.botRepo.Update(botModel); err != nil {
+			bm.logger.Warn("Failed to store Telegram bot ID",
+				zap.String("bot_id", botID.String()),
+				zap.Error(err))
+		}
 	}
 
 	// Store bot instance
@@ -237,7 +408,8 @@ func (bm *BotManager) startBot(botID uuid.UUID) error {
 
 	bm.logger.Info("ForwarderBot started successfully",
 		zap.String("bot_id", botID.String()),
-		zap.String("bot_name", botModel.Name))
+		zap.String("bot_name", botModel.Name),
+		zap.Strings("allowed_updates", forwarderBot.HandledUpdates()))
 
 	return nil
 }
@@ -245,20 +417,86 @@ func (bm *BotManager) startBot(botID uuid.UUID) error {
 // StopBot stops a ForwarderBot by its ID
 // botID can be uuid.UUID or any type that can be converted to uuid.UUID
 func (bm *BotManager) StopBot(botID interface{}) error {
-	var id uuid.UUID
+	id, err := parseBotID(botID)
+	if err != nil {
+		return err
+	}
+	return bm.stopBot(id)
+}
+
+// RestartBot stops the running instance of a ForwarderBot, if any, and starts
+// it again from the database, e.g. to pick up a new token
+// botID can be uuid.UUID or any type that can be converted to uuid.UUID
+func (bm *BotManager) RestartBot(botID interface{}) error {
+	id, err := parseBotID(botID)
+	if err != nil {
+		return err
+	}
+	if err := bm.stopBot(id); err != nil {
+		return err
+	}
+	return bm.startBot(id)
+}
+
+// SettingChanged applies a changed setting to the running bots: changed rate
+// limits take effect at once, and a ForwarderBot is restarted when a setting
+// enables or disables a capability that needs other update types, as the
+// update types are only sent to Telegram when the bot starts
+func (bm *BotManager) SettingChanged(botID uuid.UUID, key string) {
+	switch key {
+	case settings.KeyRateLimit, settings.KeyRateBurst, settings.KeyAPIRateLimit:
+		bm.rateLimiter.InvalidateLimits(botID)
+		return
+	}
+	if !affectsUpdates(key) {
+		return
+	}
+	fb, running := bm.GetBot(botID)
+	if !running {
+		return
+	}
+	botModel, err := bm.botRepo.GetByID(botID)
+	if err != nil {
+		bm.logger.Warn("Failed to get bot to update its allowed updates",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return
+	}
+	updates := forwarderUpdateOptions(bm.config.Polling, botID, fb.GetBot().Username,
+		forwarderUpdates(botModel, bm.settingsService))
+	if slices.Equal(updates.allowedUpdates, fb.HandledUpdates()) {
+		return
+	}
+
+	bm.logger.Info("Restarting ForwarderBot to change its allowed updates",
+		zap.String("bot_id", botID.String()),
+		zap.String("setting", key),
+		zap.Strings("allowed_updates", updates.allowedUpdates))
+	// The change is usually made from one of the bot's own updates, which
+	// stopping the bot waits for
+	go func() {
+		if err := bm.RestartBot(botID); err != nil {
+			bm.logger.Error("Failed to restart ForwarderBot",
+				zap.String("bot_id", botID.String()),
+				zap.Error(err))
+		}
+	}()
+}
+
+// parseBotID converts the bot IDs accepted by StartBot, StopBot and RestartBot
+func parseBotID(botID interface{}) (uuid.UUID, error) {
 	switch v := botID.(type) {
 	case uuid.UUID:
-		id = v
+		return v, nil
 	case string:
-		parsed, err := uuid.Parse(v)
+		id, err := uuid.Parse(v)
 		if err != nil {
-			return fmt.Errorf("invalid bot ID format: %w", err)
+			return uuid.Nil, fmt.Errorf("invalid bot ID format: %w", err)
 		}
-		id = parsed
+		return id, nil
 	default:
-		return fmt.Errorf("unsupported bot ID type: %T", botID)
+		return uuid.Nil, fmt.Errorf("unsupported bot ID type: %T", botID)
 	}
-	return bm.stopBot(id)
 }
 
 func (bm *BotManager) stopBot(botID uuid.UUID) error {
@@ -275,6 +513,12 @@ func (bm *BotManager) stopBot(botID uuid.UUID) error {
 	bm.logger.Debug("Stopping ForwarderBot",
 		zap.String("bot_id", botID.String()))
 
+	// The bot is going away for good, so Telegram should stop sending its
+	// updates. On shutdown the webhooks are kept so updates queue until restart.
+	if bm.webhook != nil {
+		bm.webhook.unregister(bot.GetBot())
+	}
+
 	// Stop the bot
 	bot.Stop()
 
@@ -307,6 +551,132 @@ func (bm *BotManager) GetAllBots() []*ForwarderBot {
 	return bots
 }
 
+// CheckAllGroups verifies the group recipients of every running bot.
+// Run periodically by the worker scheduler.
+func (bm *BotManager) CheckAllGroups(ctx context.Context) error {
+	for _, fb := range bm.GetAllBots() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		botInstance := fb.GetBot()
+		if botInstance == nil {
+			continue
+		}
+		bm.groupMonitor.CheckAllRecipients(ctx, botInstance, fb.GetBotID())
+	}
+	return nil
+}
+
+// RefreshRecipientInfo updates the stored chat titles and usernames of the
+// recipients of every running bot. Run periodically by the worker scheduler.
+func (bm *BotManager) RefreshRecipientInfo(ctx context.Context) error {
+	for _, fb := range bm.GetAllBots() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		botInstance := fb.GetBot()
+		if botInstance == nil {
+			continue
+		}
+		bm.recipientInfoRefresher.RefreshAll(ctx, botInstance, fb.GetBotID())
+	}
+	return nil
+}
+
+// RefreshBotInfo updates the stored username and Telegram ID of every running
+// bot. Run periodically by the worker scheduler.
+func (bm *BotManager) RefreshBotInfo(ctx context.Context) error {
+	for _, fb := range bm.GetAllBots() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		botInstance := fb.GetBot()
+		if botInstance == nil {
+			continue
+		}
+		if _, err := bm.botInfoRefresher.Refresh(ctx, botInstance, fb.GetBotID()); err != nil {
+			bm.logger.Debug("Failed to refresh bot info",
+				zap.String("bot_id", fb.GetBotID().String()),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// RetryQueuedDeliveries tries the due deliveries in the retry queue of every
+// running bot. Run periodically by the worker scheduler.
+func (bm *BotManager) RetryQueuedDeliveries(ctx context.Context) error {
+	for _, fb := range bm.GetAllBots() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		botInstance := fb.GetBot()
+		if botInstance == nil || fb.Forwarder() == nil {
+			continue
+		}
+		if _, err := fb.Forwarder().DeliverQueued(ctx, botInstance, fb.GetBotID(), false); err != nil {
+			bm.logger.Warn("Failed to retry queued deliveries",
+				zap.String("bot_id", fb.GetBotID().String()),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// DeliverScheduledMessages delivers the due messages guests held with /later
+// on every running bot. Run periodically by the worker scheduler.
+func (bm *BotManager) DeliverScheduledMessages(ctx context.Context) error {
+	for _, fb := range bm.GetAllBots() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		botInstance := fb.GetBot()
+		if botInstance == nil || fb.service == nil {
+			continue
+		}
+		if err := fb.service.DeliverScheduled(ctx, botInstance); err != nil {
+			bm.logger.Warn("Failed to deliver held messages",
+				zap.String("bot_id", fb.GetBotID().String()),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// FlushDeliveryQueue tries the queued deliveries of a running bot now, whether
+// they are due or not, and returns how many were delivered
+func (bm *BotManager) FlushDeliveryQueue(ctx context.Context, botID uuid.UUID) (int, error) {
+	fb, running := bm.GetBot(botID)
+	if !running || fb.GetBot() == nil || fb.Forwarder() == nil {
+		return 0, ErrBotNotRunning
+	}
+	result, err := fb.Forwarder().DeliverQueued(ctx, fb.GetBot(), botID, true)
+	if err != nil {
+		return 0, err
+	}
+	return result.Delivered, nil
+}
+
+// ReplayQueued delivers the given queued deliveries of a running bot now; see
+// message.Forwarder.ReplayQueued
+func (bm *BotManager) ReplayQueued(ctx context.Context, botID uuid.UUID, deliveries []*models.PendingDelivery, progress func(message.ReplayReport)) (message.ReplayReport, error) {
+	fb, running := bm.GetBot(botID)
+	if !running || fb.GetBot() == nil || fb.Forwarder() == nil {
+		return message.ReplayReport{}, ErrBotNotRunning
+	}
+	return fb.Forwarder().ReplayQueued(ctx, fb.GetBot(), botID, deliveries, progress), nil
+}
+
+// ReplayArchived re-delivers the messages a running bot received in
+// [start, end) to one recipient; see message.Forwarder.ReplayArchived
+func (bm *BotManager) ReplayArchived(ctx context.Context, botID uuid.UUID, recipient *models.Recipient, start time.Time, end time.Time, progress func(message.ReplayReport)) (message.ReplayReport, error) {
+	fb, running := bm.GetBot(botID)
+	if !running || fb.GetBot() == nil || fb.Forwarder() == nil {
+		return message.ReplayReport{}, ErrBotNotRunning
+	}
+	return fb.Forwarder().ReplayArchived(ctx, fb.GetBot(), botID, recipient, start, end, progress)
+}
+
 // StopAll stops all running bots
 func (bm *BotManager) StopAll() {
 	bm.mu.Lock()
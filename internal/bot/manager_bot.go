@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/service"
 	"go-telegram-forwarder-bot/internal/service/manager_bot"
 	"go-telegram-forwarder-bot/internal/utils"
 
@@ -14,12 +16,19 @@ import (
 	"go.uber.org/zap"
 )
 
+// dbOutageMaxWait is how long an update is held while the database is
+// unreachable before it is dropped
+const dbOutageMaxWait = 30 * time.Second
+
 type ManagerBot struct {
-	bot     *gotgbot.Bot
-	updater *ext.Updater
-	service *manager_bot.Service
-	logger  *zap.Logger
-	stop    chan struct{}
+	bot      *gotgbot.Bot
+	updater  *ext.Updater
+	service  *manager_bot.Service
+	dbHealth *service.DBHealth
+	webhook  *WebhookServer
+	updates  updateOptions
+	logger   *zap.Logger
+	stop     chan struct{}
 }
 
 func NewManagerBot(token string, service *manager_bot.Service, logger *zap.Logger, cfg *config.Config) (*ManagerBot, error) {
@@ -46,6 +55,11 @@ func NewManagerBot(token string, service *manager_bot.Service, logger *zap.Logge
 		logger.Info("Proxy enabled for ManagerBot", zap.String("proxy_url", cfg.Proxy.URL))
 	}
 
+	botOpts = utils.WithBotAPIURL(botOpts, cfg.BotAPI.URL)
+
+	// Test deployments may fail requests on purpose
+	botOpts = withChaos(botOpts, cfg.Chaos, logger)
+
 	b, err := gotgbot.NewBot(token, botOpts)
 	if err != nil {
 		return nil, err
@@ -60,6 +74,7 @@ func NewManagerBot(token string, service *manager_bot.Service, logger *zap.Logge
 		bot:     b,
 		updater: updater,
 		service: service,
+		updates: newUpdateOptions(cfg.Polling, managerBotUpdates),
 		logger:  logger,
 		stop:    make(chan struct{}),
 	}, nil
@@ -76,18 +91,15 @@ func (mb *ManagerBot) Start(ctx context.Context) error {
 
 	// Create a handler that processes all updates
 	handler := &updateHandler{
-		bot:     mb.bot,
-		service: mb.service,
-		logger:  mb.logger,
-		ctx:     ctx,
+		bot:      mb.bot,
+		service:  mb.service,
+		dbHealth: mb.dbHealth,
+		logger:   mb.logger,
+		ctx:      ctx,
 	}
 	dp.AddHandlerToGroup(handler, 0)
 
-	// Start polling
-	err := mb.updater.StartPolling(mb.bot, &ext.PollingOpts{
-		DropPendingUpdates: true,
-	})
-	if err != nil {
+	if err := startUpdates(mb.bot, mb.updater, mb.webhook, mb.updates); err != nil {
 		return err
 	}
 
@@ -104,6 +116,9 @@ func (mb *ManagerBot) Start(ctx context.Context) error {
 
 func (mb *ManagerBot) Stop() {
 	close(mb.stop)
+	if mb.webhook != nil {
+		mb.webhook.unroute(botPath(mb.bot.Token))
+	}
 	mb.updater.Stop()
 	mb.logger.Info("ManagerBot stopped")
 }
@@ -112,11 +127,40 @@ func (mb *ManagerBot) GetBot() *gotgbot.Bot {
 	return mb.bot
 }
 
+// SetDBHealth makes the bot hold updates while the database is unreachable.
+// It must be called before Start.
+func (mb *ManagerBot) SetDBHealth(dbHealth *service.DBHealth) {
+	mb.dbHealth = dbHealth
+}
+
+// SetWebhook makes the bot receive updates through the webhook server instead
+// of long polling. It must be called before Start.
+func (mb *ManagerBot) SetWebhook(webhook *WebhookServer) {
+	mb.webhook = webhook
+}
+
+// waitForDB holds an update during a database outage. It reports false if the
+// database did not recover within dbOutageMaxWait and the update should be dropped.
+func waitForDB(ctx context.Context, dbHealth *service.DBHealth, logger *zap.Logger, update *gotgbot.Update) bool {
+	if dbHealth == nil || dbHealth.Healthy() {
+		return true
+	}
+	logger.Debug("Holding update until the database recovers",
+		zap.Int64("update_id", update.UpdateId))
+	if dbHealth.WaitHealthy(ctx, dbOutageMaxWait) {
+		return true
+	}
+	logger.Warn("Dropping update, database is unreachable",
+		zap.Int64("update_id", update.UpdateId))
+	return false
+}
+
 type updateHandler struct {
-	bot     *gotgbot.Bot
-	service *manager_bot.Service
-	logger  *zap.Logger
-	ctx     context.Context
+	bot      *gotgbot.Bot
+	service  *manager_bot.Service
+	dbHealth *service.DBHealth
+	logger   *zap.Logger
+	ctx      context.Context
 }
 
 func (h *updateHandler) CheckUpdate(b *gotgbot.Bot, ctx *ext.Context) bool {
@@ -131,6 +175,10 @@ func (h *updateHandler) HandleUpdate(b *gotgbot.Bot, ctx *ext.Context) error {
 		zap.Bool("has_message", update.Message != nil),
 		zap.Bool("has_callback_query", update.CallbackQuery != nil))
 
+	if !waitForDB(h.ctx, h.dbHealth, h.logger, update) {
+		return nil
+	}
+
 	// Handle callback queries
 	if update.CallbackQuery != nil {
 		h.logger.Debug("Processing callback query",
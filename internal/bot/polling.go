@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+)
+
+// managerBotUpdates are the update types the ManagerBot handles. Asking
+// Telegram for only these saves bandwidth and spares the bot updates it would
+// ignore. ForwarderBots ask for those of their enabled capabilities.
+var managerBotUpdates = []string{"message", "callback_query"}
+
+// updateOptions is how a bot asks Telegram for updates
+type updateOptions struct {
+	timeout        int64 // Seconds a getUpdates request is held open
+	limit          int64
+	allowedUpdates []string
+}
+
+// newUpdateOptions applies the polling config on top of the update types the
+// bot handles
+func newUpdateOptions(cfg config.PollingConfig, handled []string) updateOptions {
+	opts := updateOptions{
+		timeout:        int64(cfg.TimeoutSeconds),
+		limit:          int64(cfg.Limit),
+		allowedUpdates: handled,
+	}
+	if len(cfg.AllowedUpdates) > 0 {
+		opts.allowedUpdates = cfg.AllowedUpdates
+	}
+	return opts
+}
+
+// forwarderUpdateOptions returns the update options of a ForwarderBot handling
+// the given update types, applying the first override matching its ID or username
+func forwarderUpdateOptions(cfg config.PollingConfig, botID uuid.UUID, username string, handled []string) updateOptions {
+	opts := newUpdateOptions(cfg, handled)
+	for _, override := range cfg.Bots {
+		ref := override.Bot
+		if ref != botID.String() && !(strings.HasPrefix(ref, "@") && strings.EqualFold(ref[1:], username)) {
+			continue
+		}
+		if override.TimeoutSeconds > 0 {
+			opts.timeout = int64(override.TimeoutSeconds)
+		}
+		if override.Limit > 0 {
+			opts.limit = int64(override.Limit)
+		}
+		if len(override.AllowedUpdates) > 0 {
+			opts.allowedUpdates = override.AllowedUpdates
+		}
+		break
+	}
+	return opts
+}
+
+// pollingOpts returns the long polling options. The HTTP request must outlast
+// the time Telegram holds it open.
+func (o updateOptions) pollingOpts() *ext.PollingOpts {
+	return &ext.PollingOpts{
+		DropPendingUpdates: true,
+		GetUpdatesOpts: &gotgbot.GetUpdatesOpts{
+			Timeout:        o.timeout,
+			Limit:          o.limit,
+			AllowedUpdates: o.allowedUpdates,
+			RequestOpts: &gotgbot.RequestOpts{
+				Timeout: time.Duration(o.timeout)*time.Second + gotgbot.DefaultTimeout,
+			},
+		},
+	}
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// WebhookServer receives updates for all bots on one HTTP listener. Each bot
+// is served on its own path, derived from its token, so any number of bots
+// can share the listener.
+type WebhookServer struct {
+	cfg       config.WebhookConfig
+	publicURL string // Public URL without the trailing slash
+	prefix    string // Path of the public URL; requests are matched below it
+	logger    *zap.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]http.Handler // Bot path -> updater handler
+	server   *http.Server
+}
+
+func NewWebhookServer(cfg config.WebhookConfig, logger *zap.Logger) (*WebhookServer, error) {
+	u, err := url.Parse(cfg.PublicURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook public URL: %w", err)
+	}
+	return &WebhookServer{
+		cfg:       cfg,
+		publicURL: strings.TrimSuffix(cfg.PublicURL, "/"),
+		prefix:    strings.TrimSuffix(u.Path, "/") + "/",
+		logger:    logger,
+		handlers:  make(map[string]http.Handler),
+	}, nil
+}
+
+// Start listens on the configured address and serves updates until ctx is done
+func (ws *WebhookServer) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", ws.cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", ws.cfg.ListenAddr, err)
+	}
+
+	ws.server = &http.Server{
+		Handler:           ws,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		var err error
+		if ws.cfg.CertFile != "" {
+			err = ws.server.ServeTLS(ln, ws.cfg.CertFile, ws.cfg.KeyFile)
+		} else {
+			err = ws.server.Serve(ln)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ws.logger.Error("Webhook server stopped", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := ws.server.Shutdown(shutdownCtx); err != nil {
+			ws.logger.Warn("Failed to shut down webhook server", zap.Error(err))
+		}
+	}()
+
+	ws.logger.Info("Webhook server listening",
+		zap.String("listen_addr", ws.cfg.ListenAddr),
+		zap.String("public_url", ws.publicURL),
+		zap.Bool("tls", ws.cfg.CertFile != ""))
+	return nil
+}
+
+func (ws *WebhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, ok := strings.CutPrefix(r.URL.Path, ws.prefix)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	ws.mu.RLock()
+	handler := ws.handlers[path]
+	ws.mu.RUnlock()
+	if handler == nil {
+		http.NotFound(w, r)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// botPath returns the path a bot receives updates on. A hash is used instead
+// of the token itself so the token does not end up in access logs.
+func botPath(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:16])
+}
+
+// register routes the bot's updates to its updater and points the bot's
+// webhook at this server
+func (ws *WebhookServer) register(b *gotgbot.Bot, updater *ext.Updater, allowedUpdates []string) error {
+	path := botPath(b.Token)
+	if err := updater.AddWebhook(b, path, &ext.AddWebhookOpts{SecretToken: ws.cfg.SecretToken}); err != nil {
+		return fmt.Errorf("failed to add webhook: %w", err)
+	}
+
+	ws.mu.Lock()
+	ws.handlers[path] = updater.GetHandlerFunc(ws.prefix)
+	ws.mu.Unlock()
+
+	// Pending updates are kept: they are what Telegram queued while the
+	// application was restarting
+	opts := &gotgbot.SetWebhookOpts{
+		SecretToken:    ws.cfg.SecretToken,
+		AllowedUpdates: allowedUpdates,
+	}
+	if ws.cfg.SelfSigned {
+		cert, err := os.Open(ws.cfg.CertFile)
+		if err != nil {
+			ws.unroute(path)
+			return fmt.Errorf("failed to open webhook certificate: %w", err)
+		}
+		defer cert.Close()
+		opts.Certificate = gotgbot.InputFileByReader("cert.pem", cert)
+	}
+	if _, err := b.SetWebhook(ws.publicURL+"/"+path, opts); err != nil {
+		ws.unroute(path)
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	return nil
+}
+
+// unregister removes the bot's webhook so Telegram stops sending its updates
+func (ws *WebhookServer) unregister(b *gotgbot.Bot) {
+	ws.unroute(botPath(b.Token))
+	if _, err := b.DeleteWebhook(nil); err != nil {
+		ws.logger.Warn("Failed to delete webhook",
+			zap.String("bot_username", b.Username),
+			zap.Error(err))
+	}
+}
+
+func (ws *WebhookServer) unroute(path string) {
+	ws.mu.Lock()
+	delete(ws.handlers, path)
+	ws.mu.Unlock()
+}
+
+// startUpdates starts receiving updates for the bot, through the webhook server
+// if there is one and by long polling otherwise
+func startUpdates(b *gotgbot.Bot, updater *ext.Updater, webhook *WebhookServer, opts updateOptions) error {
+	if webhook != nil {
+		return webhook.register(b, updater, opts.allowedUpdates)
+	}
+	return updater.StartPolling(b, opts.pollingOpts())
+}
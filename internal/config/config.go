@@ -1,16 +1,36 @@
 package config
 
 type Config struct {
-	ManagerBot    ManagerBotConfig `mapstructure:"manager_bot"`
-	Database      DatabaseConfig   `mapstructure:"database"`
-	Redis         RedisConfig      `mapstructure:"redis"`
-	RateLimit     RateLimitConfig  `mapstructure:"rate_limit"`
-	Retry         RetryConfig      `mapstructure:"retry"`
-	Log           LogConfig        `mapstructure:"log"`
-	Environment   string           `mapstructure:"environment"`
-	EncryptionKey string           `mapstructure:"encryption_key"` // Base64 encoded 32-byte key
-	Proxy         ProxyConfig      `mapstructure:"proxy"`
-	AdFilter      AdFilterConfig   `mapstructure:"ad_filter"`
+	ManagerBot    ManagerBotConfig    `mapstructure:"manager_bot"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	Redis         RedisConfig         `mapstructure:"redis"`
+	RateLimit     RateLimitConfig     `mapstructure:"rate_limit"`
+	Retry         RetryConfig         `mapstructure:"retry"`
+	Log           LogConfig           `mapstructure:"log"`
+	Environment   string              `mapstructure:"environment"`
+	EncryptionKey string              `mapstructure:"encryption_key"` // Base64 encoded 32-byte key
+	Locale        string              `mapstructure:"locale"`         // Date and number formatting, e.g. en, de, fr
+	Proxy         ProxyConfig         `mapstructure:"proxy"`
+	BotAPI        BotAPIConfig        `mapstructure:"bot_api"`
+	AdFilter      AdFilterConfig      `mapstructure:"ad_filter"`
+	Workers       WorkersConfig       `mapstructure:"workers"`
+	Retention     RetentionConfig     `mapstructure:"retention"`
+	FailureNotice FailureNoticeConfig `mapstructure:"failure_notice"`
+	AccessDenied  AccessDeniedConfig  `mapstructure:"access_denied"`
+	LoopDetection LoopDetectionConfig `mapstructure:"loop_detection"`
+	AntiFlood     AntiFloodConfig     `mapstructure:"anti_flood"`
+	Later         LaterConfig         `mapstructure:"later"`
+	Tiers         TiersConfig         `mapstructure:"tiers"`
+	LLM           LLMConfig           `mapstructure:"llm"`
+	Transcription TranscriptionConfig `mapstructure:"transcription"`
+	OCR           OCRConfig           `mapstructure:"ocr"`
+	FileScan      FileScanConfig      `mapstructure:"file_scan"`
+	API           APIConfig           `mapstructure:"api"`
+	Webhook       WebhookConfig       `mapstructure:"webhook"`
+	Polling       PollingConfig       `mapstructure:"polling"`
+	Failover      FailoverConfig      `mapstructure:"failover"`
+	Chaos         ChaosConfig         `mapstructure:"chaos"`
 }
 
 type ManagerBotConfig struct {
@@ -18,6 +38,14 @@ type ManagerBotConfig struct {
 	Superusers []int64 `mapstructure:"superusers"`
 }
 
+// NotificationsConfig chooses where error alerts, reports and audit events
+// for the superusers are sent
+type NotificationsConfig struct {
+	SuperuserDMs bool     `mapstructure:"superuser_dms"` // Message every superuser privately
+	LogChatID    int64    `mapstructure:"log_chat_id"`   // Channel or group the ManagerBot also posts to; 0 disables the log chat
+	AuditActions []string `mapstructure:"audit_actions"` // Audit log actions posted to the log chat as they are written
+}
+
 type DatabaseConfig struct {
 	Type string `mapstructure:"type"`
 	DSN  string `mapstructure:"dsn"`
@@ -25,9 +53,31 @@ type DatabaseConfig struct {
 
 type RedisConfig struct {
 	Enabled  bool   `mapstructure:"enabled"`
-	Address  string `mapstructure:"address"`
+	Mode     string `mapstructure:"mode"`    // standalone, sentinel or cluster
+	Address  string `mapstructure:"address"` // Used in standalone mode
+	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
-	DB       int    `mapstructure:"db"`
+	DB       int    `mapstructure:"db"` // Ignored in cluster mode
+
+	// Sentinel mode
+	MasterName        string   `mapstructure:"master_name"`
+	SentinelAddresses []string `mapstructure:"sentinel_addresses"`
+	SentinelUsername  string   `mapstructure:"sentinel_username"`
+	SentinelPassword  string   `mapstructure:"sentinel_password"`
+
+	// Cluster mode
+	ClusterAddresses []string `mapstructure:"cluster_addresses"`
+
+	TLS RedisTLSConfig `mapstructure:"tls"`
+}
+
+type RedisTLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"`   // Optional: CA bundle to verify the server
+	CertFile           string `mapstructure:"cert_file"` // Optional: client certificate for mutual TLS
+	KeyFile            string `mapstructure:"key_file"`  // Optional: client key for mutual TLS
+	ServerName         string `mapstructure:"server_name"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
 }
 
 type RateLimitConfig struct {
@@ -36,8 +86,17 @@ type RateLimitConfig struct {
 }
 
 type RetryConfig struct {
-	MaxAttempts     int `mapstructure:"max_attempts"`
-	IntervalSeconds int `mapstructure:"interval_seconds"`
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// IntervalSeconds is the wait before the first retry. Each further retry
+	// waits Multiplier times longer, up to MaxIntervalSeconds, minus a random
+	// jitter of up to half the wait. Telegram flood waits (429 retry_after)
+	// replace the computed wait.
+	IntervalSeconds    int     `mapstructure:"interval_seconds"`
+	Multiplier         float64 `mapstructure:"multiplier"`
+	MaxIntervalSeconds int     `mapstructure:"max_interval_seconds"`
+	// QueueMaxAttempts is how often the retry_queue worker tries a message that
+	// failed all retries before dropping it; 0 drops failed messages at once
+	QueueMaxAttempts int `mapstructure:"queue_max_attempts"`
 }
 
 type LogConfig struct {
@@ -53,6 +112,216 @@ type ProxyConfig struct {
 	Password string `mapstructure:"password"` // Optional: proxy password
 }
 
+// BotAPIConfig points all bots at another Bot API server, e.g. a self-hosted
+// telegram-bot-api or the fake server of the loadtest command
+type BotAPIConfig struct {
+	URL string `mapstructure:"url"` // Empty for Telegram's, https://api.telegram.org
+}
+
 type AdFilterConfig struct {
-	Enabled bool `mapstructure:"enabled"` // Enable ad filtering (block messages with mentions or URLs)
+	Enabled bool `mapstructure:"enabled"` // Enable ad filtering (block messages with mentions or URLs) and per-bot filter rules
+}
+
+// FailureNoticeConfig controls the notice sent to a guest when none of the
+// recipients could receive their message
+type FailureNoticeConfig struct {
+	Enabled         bool `mapstructure:"enabled"`
+	CooldownSeconds int  `mapstructure:"cooldown_seconds"` // Minimum time between notices to the same guest
+}
+
+// AccessDeniedConfig controls the alert sent to superusers when someone keeps
+// trying commands or buttons they are not allowed to use
+type AccessDeniedConfig struct {
+	AlertThreshold int `mapstructure:"alert_threshold"` // Denied attempts by one user within the window that trigger an alert; 0 disables alerts
+	WindowSeconds  int `mapstructure:"window_seconds"`
+}
+
+// LoopDetectionConfig controls how forwarding loops between group chats are
+// detected, e.g. when two ForwarderBots forward each other's messages
+type LoopDetectionConfig struct {
+	Threshold     int `mapstructure:"threshold"` // Forwards in each direction between two chats within the window that count as a loop; 0 disables detection
+	WindowSeconds int `mapstructure:"window_seconds"`
+	PauseSeconds  int `mapstructure:"pause_seconds"` // How long forwarding between the two chats stops once a loop is found
+}
+
+// AntiFloodConfig mutes guests who send many messages in a short time, beyond
+// what the per-guest rate limit holds back
+type AntiFloodConfig struct {
+	MaxMessages   int `mapstructure:"max_messages"` // Messages from one guest within the window that count as flooding; 0 disables anti-flood
+	WindowSeconds int `mapstructure:"window_seconds"`
+	MuteSeconds   int `mapstructure:"mute_seconds"` // How long a flooding guest is blacklisted before being let back in
+}
+
+// LaterConfig limits the messages guests hold for later delivery with /later
+type LaterConfig struct {
+	MaxDelayHours int `mapstructure:"max_delay_hours"` // Longest delay a guest may ask for; 0 disables /later
+	MaxPerGuest   int `mapstructure:"max_per_guest"`   // Messages one guest may have waiting per bot
+}
+
+// TiersConfig sets what managers on each subscription tier may use
+type TiersConfig struct {
+	Free TierConfig `mapstructure:"free"`
+	Pro  TierConfig `mapstructure:"pro"`
+}
+
+type TierConfig struct {
+	MaxBots     int  `mapstructure:"max_bots"`    // Most ForwarderBots a manager may register; 0 is unlimited
+	Broadcast   bool `mapstructure:"broadcast"`   // ForwarderBot /broadcast
+	Archive     bool `mapstructure:"archive"`     // /replay archive
+	Translation bool `mapstructure:"translation"` // Reserved for message translation, which does not exist yet
+}
+
+// WorkersConfig configures the periodic background workers
+type WorkersConfig struct {
+	AutoApprove   WorkerConfig `mapstructure:"auto_approve"`   // Auto-approve expired blacklist requests
+	GroupCheck    WorkerConfig `mapstructure:"group_check"`    // Verify group recipients are still reachable
+	RecipientInfo WorkerConfig `mapstructure:"recipient_info"` // Refresh recipient chat titles and usernames
+	BotInfo       WorkerConfig `mapstructure:"bot_info"`       // Refresh ForwarderBot usernames
+	StatsDaily    WorkerConfig `mapstructure:"stats_daily"`    // Write per-bot daily statistics rollups
+	RetryQueue    WorkerConfig `mapstructure:"retry_queue"`    // Retry deliveries that failed all retries
+	MappingPrune  WorkerConfig `mapstructure:"mapping_prune"`  // Delete message mappings past their retention
+	MuteExpiry    WorkerConfig `mapstructure:"mute_expiry"`    // Lift temporary anti-flood mutes that have expired
+	Later         WorkerConfig `mapstructure:"later"`          // Deliver guest messages held with /later once due
+}
+
+// MinMessageMappingRetentionDays is the shortest message mapping retention.
+// Daily statistics are recomputed from mappings of the last two weeks, so
+// shorter retention would lower them.
+const MinMessageMappingRetentionDays = 30
+
+// RetentionConfig sets how long stored data is kept
+type RetentionConfig struct {
+	// MessageMappingDays is how long the mappings between guest and recipient
+	// messages are kept; 0 keeps them forever. Replies to messages older than
+	// this no longer reach the guest. Bots can override it in their settings.
+	MessageMappingDays int `mapstructure:"message_mapping_days"`
+	PruneBatchSize     int `mapstructure:"prune_batch_size"` // Mappings deleted per query by the mapping_prune worker
+}
+
+type WorkerConfig struct {
+	Enabled         bool `mapstructure:"enabled"`
+	IntervalSeconds int  `mapstructure:"interval_seconds"`
+	JitterSeconds   int  `mapstructure:"jitter_seconds"` // Random delay before the first run, up to this many seconds
+}
+
+// LLMConfig configures draft replies from a language model (/suggest).
+// Conversation text is sent to the endpoint, so it is disabled by default.
+type LLMConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	Provider        string `mapstructure:"provider"` // openai: any OpenAI-compatible chat completions API
+	Endpoint        string `mapstructure:"endpoint"` // e.g. https://api.openai.com/v1/chat/completions
+	APIKey          string `mapstructure:"api_key"`
+	Model           string `mapstructure:"model"`
+	SystemPrompt    string `mapstructure:"system_prompt"`    // Instructions for the model; a generic support prompt if empty
+	ContextMessages int    `mapstructure:"context_messages"` // Recent messages per conversation sent as context
+	MaxTokens       int    `mapstructure:"max_tokens"`
+	TimeoutSeconds  int    `mapstructure:"timeout_seconds"`
+}
+
+// TranscriptionConfig configures speech-to-text for guest voice messages.
+// Audio is sent to the endpoint, so it is disabled by default; managers then
+// turn it on per bot in the settings.
+type TranscriptionConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	Provider           string `mapstructure:"provider"` // openai: any OpenAI-compatible audio transcriptions API
+	Endpoint           string `mapstructure:"endpoint"` // e.g. https://api.openai.com/v1/audio/transcriptions
+	APIKey             string `mapstructure:"api_key"`
+	Model              string `mapstructure:"model"`
+	Language           string `mapstructure:"language"`             // Optional ISO-639-1 hint, detected if empty
+	MaxDurationSeconds int    `mapstructure:"max_duration_seconds"` // Longer voice messages are not transcribed; 0 is unlimited
+	TimeoutSeconds     int    `mapstructure:"timeout_seconds"`
+}
+
+// OCRConfig configures reading text in images guests send. Managers turn it on
+// per bot in the settings once it is enabled here.
+type OCRConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	Provider       string `mapstructure:"provider"`       // tesseract: local binary; http: a service answering {"text": "..."}
+	TesseractPath  string `mapstructure:"tesseract_path"` // Name or path of the tesseract binary
+	Endpoint       string `mapstructure:"endpoint"`       // URL the http provider posts images to
+	APIKey         string `mapstructure:"api_key"`
+	Language       string `mapstructure:"language"` // e.g. eng or chi_sim+eng for tesseract; empty uses the provider's default
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"`
+}
+
+// FileScanConfig configures scanning files guests send for malware. Managers
+// turn it on per bot in the settings once it is enabled here.
+type FileScanConfig struct {
+	Enabled                 bool   `mapstructure:"enabled"`
+	Provider                string `mapstructure:"provider"`       // clamav or virustotal
+	ClamAVAddress           string `mapstructure:"clamav_address"` // unix:///path/to/clamd.sock, tcp://host:port or host:port
+	VirusTotalAPIKey        string `mapstructure:"virustotal_api_key"`
+	VirusTotalMinDetections int    `mapstructure:"virustotal_min_detections"` // Engines that must flag a file before it is withheld
+	TimeoutSeconds          int    `mapstructure:"timeout_seconds"`
+	// WithholdUnscanned also withholds files that could not be scanned, e.g. when
+	// the scanner is down or the file is over Telegram's 20 MB download limit
+	WithholdUnscanned bool `mapstructure:"withhold_unscanned"`
+}
+
+// APIConfig serves an HTTP API for managing bots without the Telegram UI
+type APIConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	ListenAddr string   `mapstructure:"listen_addr"` // Local address to listen on, e.g. 127.0.0.1:8080
+	Keys       []APIKey `mapstructure:"keys"`
+}
+
+// APIKey lets its holder act as a Telegram user: superusers manage every bot,
+// other users the bots they manage
+type APIKey struct {
+	Name   string `mapstructure:"name"` // Identifies the key in logs and the audit log
+	Key    string `mapstructure:"key"`  // Sent as "Authorization: Bearer <key>"; at least 32 characters
+	UserID int64  `mapstructure:"user_id"`
+}
+
+// WebhookConfig makes all bots receive updates through one HTTP listener
+// instead of long polling. Each bot gets its own path below the public URL.
+type WebhookConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	PublicURL   string `mapstructure:"public_url"`   // HTTPS URL Telegram posts to, e.g. https://bot.example.com/telegram
+	ListenAddr  string `mapstructure:"listen_addr"`  // Local address to listen on, e.g. :8443
+	CertFile    string `mapstructure:"cert_file"`    // Serve TLS directly; leave empty behind a TLS-terminating proxy
+	KeyFile     string `mapstructure:"key_file"`     // Private key for cert_file
+	SelfSigned  bool   `mapstructure:"self_signed"`  // Upload cert_file to Telegram so it trusts a self-signed certificate
+	SecretToken string `mapstructure:"secret_token"` // Telegram sends it with every update; requests without it are rejected
+}
+
+// PollingConfig configures how bots receive updates with long polling.
+// AllowedUpdates also applies to webhooks.
+type PollingConfig struct {
+	TimeoutSeconds int `mapstructure:"timeout_seconds"` // How long Telegram holds a getUpdates request open when there are no updates
+	Limit          int `mapstructure:"limit"`           // Most updates per getUpdates request, 1-100
+	// AllowedUpdates are the update types Telegram sends; empty sends the types the bots handle
+	AllowedUpdates []string          `mapstructure:"allowed_updates"`
+	Bots           []PollingOverride `mapstructure:"bots"` // Per-ForwarderBot overrides
+}
+
+// PollingOverride changes the polling options of one ForwarderBot. Zero
+// values keep the global options.
+type PollingOverride struct {
+	Bot            string   `mapstructure:"bot"` // Bot ID or @username
+	TimeoutSeconds int      `mapstructure:"timeout_seconds"`
+	Limit          int      `mapstructure:"limit"`
+	AllowedUpdates []string `mapstructure:"allowed_updates"`
+}
+
+// FailoverConfig runs the instance in an active/standby pair sharing one
+// database. Only the instance holding the lease polls the bots; a standby
+// waits and takes over once the lease expires.
+type FailoverConfig struct {
+	Enabled             bool   `mapstructure:"enabled"`
+	InstanceID          string `mapstructure:"instance_id"`           // Owner of the lease; empty uses the hostname plus a random suffix
+	LeaseSeconds        int    `mapstructure:"lease_seconds"`         // How long the lease stays valid without a heartbeat
+	HeartbeatSeconds    int    `mapstructure:"heartbeat_seconds"`     // How often the active instance renews the lease and a standby checks it
+	FencingDelaySeconds int    `mapstructure:"fencing_delay_seconds"` // How long a new active instance waits after taking the lease before polling
+}
+
+// ChaosConfig makes a share of Bot API requests fail on purpose, for testing
+// how the bots cope with Telegram outages. Never enable it in production.
+type ChaosConfig struct {
+	Enabled           bool     `mapstructure:"enabled"`
+	RateLimitRate     float64  `mapstructure:"rate_limit_rate"`     // Share of requests answered with 429 Too Many Requests
+	RetryAfterSeconds int      `mapstructure:"retry_after_seconds"` // retry_after sent with injected 429 errors
+	ServerErrorRate   float64  `mapstructure:"server_error_rate"`   // Share of requests answered with 500 Internal Server Error
+	TimeoutRate       float64  `mapstructure:"timeout_rate"`        // Share of requests that time out
+	Methods           []string `mapstructure:"methods"`             // Bot API methods to affect, e.g. sendMessage; empty affects all but getMe
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 
@@ -46,21 +47,32 @@ func setDefaults() {
 	viper.SetDefault("database.dsn", "bot.db")
 
 	viper.SetDefault("redis.enabled", false)
+	viper.SetDefault("redis.mode", "standalone")
 	viper.SetDefault("redis.address", "localhost:6379")
+	viper.SetDefault("redis.username", "")
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.master_name", "")
+	viper.SetDefault("redis.sentinel_addresses", []string{})
+	viper.SetDefault("redis.cluster_addresses", []string{})
+	viper.SetDefault("redis.tls.enabled", false)
+	viper.SetDefault("redis.tls.insecure_skip_verify", false)
 
 	viper.SetDefault("rate_limit.telegram_api", 25)
 	viper.SetDefault("rate_limit.guest_message", 1)
 
 	viper.SetDefault("retry.max_attempts", 10)
-	viper.SetDefault("retry.interval_seconds", 30)
+	viper.SetDefault("retry.interval_seconds", 1)
+	viper.SetDefault("retry.multiplier", 2.0)
+	viper.SetDefault("retry.max_interval_seconds", 60)
+	viper.SetDefault("retry.queue_max_attempts", 20)
 
 	viper.SetDefault("log.level", "debug")
 	viper.SetDefault("log.output", "stdout")
 	viper.SetDefault("log.file_path", "bot.log")
 
 	viper.SetDefault("environment", "development")
+	viper.SetDefault("locale", "en")
 	viper.SetDefault("encryption_key", "") // Must be set in production
 
 	viper.SetDefault("proxy.enabled", false)
@@ -68,7 +80,136 @@ func setDefaults() {
 	viper.SetDefault("proxy.username", "")
 	viper.SetDefault("proxy.password", "")
 
+	viper.SetDefault("bot_api.url", "")
+
 	viper.SetDefault("ad_filter.enabled", false)
+
+	viper.SetDefault("notifications.superuser_dms", true)
+	viper.SetDefault("notifications.log_chat_id", 0)
+	viper.SetDefault("notifications.audit_actions", []string{
+		"add_bot", "delete_bot", "suspend_manager", "unsuspend_manager", "set_token",
+		"add_superuser", "del_superuser", "lockdown", "lift_lockdown", "refund",
+	})
+
+	viper.SetDefault("failure_notice.enabled", true)
+	viper.SetDefault("failure_notice.cooldown_seconds", 600)
+
+	viper.SetDefault("access_denied.alert_threshold", 5)
+	viper.SetDefault("access_denied.window_seconds", 600)
+
+	viper.SetDefault("loop_detection.threshold", 5)
+	viper.SetDefault("loop_detection.window_seconds", 60)
+	viper.SetDefault("loop_detection.pause_seconds", 600)
+
+	viper.SetDefault("anti_flood.max_messages", 20)
+	viper.SetDefault("anti_flood.window_seconds", 60)
+	viper.SetDefault("anti_flood.mute_seconds", 900)
+
+	viper.SetDefault("later.max_delay_hours", 168)
+	viper.SetDefault("later.max_per_guest", 5)
+
+	viper.SetDefault("tiers.free.max_bots", 3)
+	viper.SetDefault("tiers.free.broadcast", false)
+	viper.SetDefault("tiers.free.archive", false)
+	viper.SetDefault("tiers.free.translation", false)
+	viper.SetDefault("tiers.pro.max_bots", 0)
+	viper.SetDefault("tiers.pro.broadcast", true)
+	viper.SetDefault("tiers.pro.archive", true)
+	viper.SetDefault("tiers.pro.translation", true)
+
+	viper.SetDefault("llm.enabled", false)
+	viper.SetDefault("llm.provider", "openai")
+	viper.SetDefault("llm.endpoint", "https://api.openai.com/v1/chat/completions")
+	viper.SetDefault("llm.api_key", "")
+	viper.SetDefault("llm.model", "")
+	viper.SetDefault("llm.system_prompt", "")
+	viper.SetDefault("llm.context_messages", 20)
+	viper.SetDefault("llm.max_tokens", 500)
+	viper.SetDefault("llm.timeout_seconds", 30)
+
+	viper.SetDefault("transcription.enabled", false)
+	viper.SetDefault("transcription.provider", "openai")
+	viper.SetDefault("transcription.endpoint", "https://api.openai.com/v1/audio/transcriptions")
+	viper.SetDefault("transcription.api_key", "")
+	viper.SetDefault("transcription.model", "whisper-1")
+	viper.SetDefault("transcription.language", "")
+	viper.SetDefault("transcription.max_duration_seconds", 300)
+	viper.SetDefault("transcription.timeout_seconds", 60)
+
+	viper.SetDefault("ocr.enabled", false)
+	viper.SetDefault("ocr.provider", "tesseract")
+	viper.SetDefault("ocr.tesseract_path", "tesseract")
+	viper.SetDefault("ocr.endpoint", "")
+	viper.SetDefault("ocr.api_key", "")
+	viper.SetDefault("ocr.language", "")
+	viper.SetDefault("ocr.timeout_seconds", 15)
+
+	viper.SetDefault("file_scan.enabled", false)
+	viper.SetDefault("file_scan.provider", "clamav")
+	viper.SetDefault("file_scan.clamav_address", "tcp://127.0.0.1:3310")
+	viper.SetDefault("file_scan.virustotal_api_key", "")
+	viper.SetDefault("file_scan.virustotal_min_detections", 2)
+	viper.SetDefault("file_scan.timeout_seconds", 60)
+	viper.SetDefault("file_scan.withhold_unscanned", false)
+
+	viper.SetDefault("api.enabled", false)
+	viper.SetDefault("api.listen_addr", "127.0.0.1:8080")
+
+	viper.SetDefault("webhook.enabled", false)
+	viper.SetDefault("webhook.public_url", "")
+	viper.SetDefault("webhook.listen_addr", ":8443")
+	viper.SetDefault("webhook.cert_file", "")
+	viper.SetDefault("webhook.key_file", "")
+	viper.SetDefault("webhook.self_signed", false)
+	viper.SetDefault("webhook.secret_token", "")
+
+	viper.SetDefault("polling.timeout_seconds", 30)
+	viper.SetDefault("polling.limit", 100)
+	viper.SetDefault("polling.allowed_updates", []string{})
+
+	viper.SetDefault("failover.enabled", false)
+	viper.SetDefault("failover.instance_id", "")
+	viper.SetDefault("failover.lease_seconds", 30)
+	viper.SetDefault("failover.heartbeat_seconds", 10)
+	viper.SetDefault("failover.fencing_delay_seconds", 45)
+
+	viper.SetDefault("chaos.enabled", false)
+	viper.SetDefault("chaos.rate_limit_rate", 0.0)
+	viper.SetDefault("chaos.retry_after_seconds", 1)
+	viper.SetDefault("chaos.server_error_rate", 0.0)
+	viper.SetDefault("chaos.timeout_rate", 0.0)
+	viper.SetDefault("chaos.methods", []string{})
+
+	viper.SetDefault("workers.auto_approve.enabled", true)
+	viper.SetDefault("workers.auto_approve.interval_seconds", 3600)
+	viper.SetDefault("workers.auto_approve.jitter_seconds", 300)
+	viper.SetDefault("workers.group_check.enabled", true)
+	viper.SetDefault("workers.group_check.interval_seconds", 86400)
+	viper.SetDefault("workers.group_check.jitter_seconds", 600)
+	viper.SetDefault("workers.recipient_info.enabled", true)
+	viper.SetDefault("workers.recipient_info.interval_seconds", 86400)
+	viper.SetDefault("workers.recipient_info.jitter_seconds", 900)
+	viper.SetDefault("workers.bot_info.enabled", true)
+	viper.SetDefault("workers.bot_info.interval_seconds", 86400)
+	viper.SetDefault("workers.bot_info.jitter_seconds", 900)
+	viper.SetDefault("workers.stats_daily.enabled", true)
+	viper.SetDefault("workers.stats_daily.interval_seconds", 86400)
+	viper.SetDefault("workers.stats_daily.jitter_seconds", 600)
+	viper.SetDefault("workers.retry_queue.enabled", true)
+	viper.SetDefault("workers.retry_queue.interval_seconds", 60)
+	viper.SetDefault("workers.retry_queue.jitter_seconds", 30)
+	viper.SetDefault("workers.mapping_prune.enabled", true)
+	viper.SetDefault("workers.mapping_prune.interval_seconds", 3600)
+	viper.SetDefault("workers.mapping_prune.jitter_seconds", 600)
+	viper.SetDefault("workers.mute_expiry.enabled", true)
+	viper.SetDefault("workers.mute_expiry.interval_seconds", 60)
+	viper.SetDefault("workers.mute_expiry.jitter_seconds", 10)
+	viper.SetDefault("workers.later.enabled", true)
+	viper.SetDefault("workers.later.interval_seconds", 60)
+	viper.SetDefault("workers.later.jitter_seconds", 10)
+
+	viper.SetDefault("retention.message_mapping_days", 0)
+	viper.SetDefault("retention.prune_batch_size", 1000)
 }
 
 func validate(cfg *Config) error {
@@ -80,6 +221,10 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("manager_bot.superusers must have at least one superuser")
 	}
 
+	if !cfg.Notifications.SuperuserDMs && cfg.Notifications.LogChatID == 0 {
+		return fmt.Errorf("notifications.log_chat_id is required when notifications.superuser_dms is off")
+	}
+
 	if cfg.Database.Type == "" {
 		return fmt.Errorf("database.type is required")
 	}
@@ -88,8 +233,30 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("database.dsn is required")
 	}
 
-	if cfg.Redis.Enabled && cfg.Redis.Address == "" {
-		return fmt.Errorf("redis.address is required when redis is enabled")
+	if cfg.Redis.Enabled {
+		switch cfg.Redis.Mode {
+		case "", "standalone":
+			if cfg.Redis.Address == "" {
+				return fmt.Errorf("redis.address is required when redis is enabled")
+			}
+		case "sentinel":
+			if cfg.Redis.MasterName == "" {
+				return fmt.Errorf("redis.master_name is required in sentinel mode")
+			}
+			if len(cfg.Redis.SentinelAddresses) == 0 {
+				return fmt.Errorf("redis.sentinel_addresses must have at least one address in sentinel mode")
+			}
+		case "cluster":
+			if len(cfg.Redis.ClusterAddresses) == 0 {
+				return fmt.Errorf("redis.cluster_addresses must have at least one address in cluster mode")
+			}
+		default:
+			return fmt.Errorf("redis.mode must be one of: standalone, sentinel, cluster")
+		}
+
+		if cfg.Redis.TLS.Enabled && (cfg.Redis.TLS.CertFile == "") != (cfg.Redis.TLS.KeyFile == "") {
+			return fmt.Errorf("redis.tls.cert_file and redis.tls.key_file must be set together")
+		}
 	}
 
 	if cfg.RateLimit.TelegramAPI <= 0 {
@@ -108,10 +275,262 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("retry.interval_seconds must be greater than 0")
 	}
 
+	if cfg.Retry.Multiplier < 1 {
+		return fmt.Errorf("retry.multiplier must be at least 1")
+	}
+
+	if cfg.Retry.MaxIntervalSeconds < cfg.Retry.IntervalSeconds {
+		return fmt.Errorf("retry.max_interval_seconds must not be less than retry.interval_seconds")
+	}
+
+	if cfg.Retry.QueueMaxAttempts < 0 {
+		return fmt.Errorf("retry.queue_max_attempts must not be negative")
+	}
+
 	if cfg.Proxy.Enabled && cfg.Proxy.URL == "" {
 		return fmt.Errorf("proxy.url is required when proxy is enabled")
 	}
 
+	if cfg.BotAPI.URL != "" {
+		apiURL, err := url.Parse(cfg.BotAPI.URL)
+		if err != nil || (apiURL.Scheme != "http" && apiURL.Scheme != "https") || apiURL.Host == "" {
+			return fmt.Errorf("bot_api.url must be an http or https URL")
+		}
+	}
+
+	if cfg.FailureNotice.CooldownSeconds < 0 {
+		return fmt.Errorf("failure_notice.cooldown_seconds must not be negative")
+	}
+
+	if cfg.AccessDenied.AlertThreshold < 0 {
+		return fmt.Errorf("access_denied.alert_threshold must not be negative")
+	}
+
+	if cfg.AccessDenied.AlertThreshold > 0 && cfg.AccessDenied.WindowSeconds <= 0 {
+		return fmt.Errorf("access_denied.window_seconds must be greater than 0")
+	}
+
+	if cfg.LoopDetection.Threshold < 0 {
+		return fmt.Errorf("loop_detection.threshold must not be negative")
+	}
+
+	if cfg.LoopDetection.Threshold > 0 && (cfg.LoopDetection.WindowSeconds <= 0 || cfg.LoopDetection.PauseSeconds <= 0) {
+		return fmt.Errorf("loop_detection.window_seconds and loop_detection.pause_seconds must be greater than 0")
+	}
+
+	if cfg.AntiFlood.MaxMessages < 0 {
+		return fmt.Errorf("anti_flood.max_messages must not be negative")
+	}
+
+	if cfg.AntiFlood.MaxMessages > 0 && (cfg.AntiFlood.WindowSeconds <= 0 || cfg.AntiFlood.MuteSeconds <= 0) {
+		return fmt.Errorf("anti_flood.window_seconds and anti_flood.mute_seconds must be greater than 0")
+	}
+
+	if cfg.Later.MaxDelayHours < 0 {
+		return fmt.Errorf("later.max_delay_hours must not be negative")
+	}
+
+	if cfg.Later.MaxDelayHours > 0 && cfg.Later.MaxPerGuest <= 0 {
+		return fmt.Errorf("later.max_per_guest must be greater than 0")
+	}
+
+	if cfg.Tiers.Free.MaxBots < 0 || cfg.Tiers.Pro.MaxBots < 0 {
+		return fmt.Errorf("tiers.*.max_bots must not be negative")
+	}
+
+	if cfg.LLM.Enabled {
+		if cfg.LLM.Provider != "" && cfg.LLM.Provider != "openai" {
+			return fmt.Errorf("llm.provider must be openai")
+		}
+		if cfg.LLM.Endpoint == "" {
+			return fmt.Errorf("llm.endpoint is required when llm is enabled")
+		}
+		if cfg.LLM.Model == "" {
+			return fmt.Errorf("llm.model is required when llm is enabled")
+		}
+		if cfg.LLM.ContextMessages <= 0 {
+			return fmt.Errorf("llm.context_messages must be greater than 0")
+		}
+		if cfg.LLM.TimeoutSeconds <= 0 {
+			return fmt.Errorf("llm.timeout_seconds must be greater than 0")
+		}
+	}
+
+	if cfg.Transcription.Enabled {
+		if cfg.Transcription.Provider != "" && cfg.Transcription.Provider != "openai" {
+			return fmt.Errorf("transcription.provider must be openai")
+		}
+		if cfg.Transcription.Endpoint == "" {
+			return fmt.Errorf("transcription.endpoint is required when transcription is enabled")
+		}
+		if cfg.Transcription.Model == "" {
+			return fmt.Errorf("transcription.model is required when transcription is enabled")
+		}
+		if cfg.Transcription.MaxDurationSeconds < 0 {
+			return fmt.Errorf("transcription.max_duration_seconds must not be negative")
+		}
+		if cfg.Transcription.TimeoutSeconds <= 0 {
+			return fmt.Errorf("transcription.timeout_seconds must be greater than 0")
+		}
+	}
+
+	if cfg.OCR.Enabled {
+		switch cfg.OCR.Provider {
+		case "", "tesseract":
+			if cfg.OCR.TesseractPath == "" {
+				return fmt.Errorf("ocr.tesseract_path is required for the tesseract provider")
+			}
+		case "http":
+			if cfg.OCR.Endpoint == "" {
+				return fmt.Errorf("ocr.endpoint is required for the http provider")
+			}
+		default:
+			return fmt.Errorf("ocr.provider must be tesseract or http")
+		}
+		if cfg.OCR.TimeoutSeconds <= 0 {
+			return fmt.Errorf("ocr.timeout_seconds must be greater than 0")
+		}
+	}
+
+	if cfg.FileScan.Enabled {
+		switch cfg.FileScan.Provider {
+		case "", "clamav":
+			if cfg.FileScan.ClamAVAddress == "" {
+				return fmt.Errorf("file_scan.clamav_address is required for the clamav provider")
+			}
+		case "virustotal":
+			if cfg.FileScan.VirusTotalAPIKey == "" {
+				return fmt.Errorf("file_scan.virustotal_api_key is required for the virustotal provider")
+			}
+			if cfg.FileScan.VirusTotalMinDetections <= 0 {
+				return fmt.Errorf("file_scan.virustotal_min_detections must be greater than 0")
+			}
+		default:
+			return fmt.Errorf("file_scan.provider must be clamav or virustotal")
+		}
+		if cfg.FileScan.TimeoutSeconds <= 0 {
+			return fmt.Errorf("file_scan.timeout_seconds must be greater than 0")
+		}
+	}
+
+	if cfg.API.Enabled {
+		if cfg.API.ListenAddr == "" {
+			return fmt.Errorf("api.listen_addr is required when api is enabled")
+		}
+		if len(cfg.API.Keys) == 0 {
+			return fmt.Errorf("api.keys must contain at least one key when api is enabled")
+		}
+		seen := make(map[string]bool, len(cfg.API.Keys))
+		for i, key := range cfg.API.Keys {
+			if len(key.Key) < 32 {
+				return fmt.Errorf("api.keys[%d].key must be at least 32 characters", i)
+			}
+			if seen[key.Key] {
+				return fmt.Errorf("api.keys[%d].key is used more than once", i)
+			}
+			seen[key.Key] = true
+			if key.UserID == 0 {
+				return fmt.Errorf("api.keys[%d].user_id is required", i)
+			}
+		}
+	}
+
+	if cfg.Webhook.Enabled {
+		u, err := url.Parse(cfg.Webhook.PublicURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("webhook.public_url must be an https URL")
+		}
+		if cfg.Webhook.ListenAddr == "" {
+			return fmt.Errorf("webhook.listen_addr is required when webhook is enabled")
+		}
+		if (cfg.Webhook.CertFile == "") != (cfg.Webhook.KeyFile == "") {
+			return fmt.Errorf("webhook.cert_file and webhook.key_file must be set together")
+		}
+		if cfg.Webhook.SelfSigned && cfg.Webhook.CertFile == "" {
+			return fmt.Errorf("webhook.self_signed requires webhook.cert_file")
+		}
+		if !validSecretToken(cfg.Webhook.SecretToken) {
+			return fmt.Errorf("webhook.secret_token must be 1-256 characters of A-Z, a-z, 0-9, _ and -")
+		}
+	}
+
+	if err := validatePolling("polling", cfg.Polling.TimeoutSeconds, cfg.Polling.Limit, cfg.Polling.AllowedUpdates); err != nil {
+		return err
+	}
+	if cfg.Polling.Limit == 0 {
+		return fmt.Errorf("polling.limit must be between 1 and %d", maxPollingLimit)
+	}
+	for i, override := range cfg.Polling.Bots {
+		if override.Bot == "" {
+			return fmt.Errorf("polling.bots[%d].bot is required", i)
+		}
+		prefix := fmt.Sprintf("polling.bots[%d]", i)
+		if err := validatePolling(prefix, override.TimeoutSeconds, override.Limit, override.AllowedUpdates); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Failover.Enabled {
+		if cfg.Failover.HeartbeatSeconds <= 0 {
+			return fmt.Errorf("failover.heartbeat_seconds must be greater than 0")
+		}
+		if cfg.Failover.LeaseSeconds <= cfg.Failover.HeartbeatSeconds {
+			return fmt.Errorf("failover.lease_seconds must be greater than failover.heartbeat_seconds")
+		}
+		if cfg.Failover.FencingDelaySeconds < 0 {
+			return fmt.Errorf("failover.fencing_delay_seconds must not be negative")
+		}
+	}
+
+	if cfg.Chaos.Enabled {
+		if cfg.Environment == "production" {
+			return fmt.Errorf("chaos must not be enabled in production")
+		}
+		rates := map[string]float64{
+			"rate_limit_rate":   cfg.Chaos.RateLimitRate,
+			"server_error_rate": cfg.Chaos.ServerErrorRate,
+			"timeout_rate":      cfg.Chaos.TimeoutRate,
+		}
+		for name, rate := range rates {
+			if rate < 0 || rate > 1 {
+				return fmt.Errorf("chaos.%s must be between 0 and 1", name)
+			}
+		}
+		if cfg.Chaos.RateLimitRate+cfg.Chaos.ServerErrorRate+cfg.Chaos.TimeoutRate > 1 {
+			return fmt.Errorf("chaos rates must add up to at most 1")
+		}
+		if cfg.Chaos.RetryAfterSeconds < 1 {
+			return fmt.Errorf("chaos.retry_after_seconds must be greater than 0")
+		}
+	}
+
+	workers := map[string]WorkerConfig{
+		"auto_approve":   cfg.Workers.AutoApprove,
+		"group_check":    cfg.Workers.GroupCheck,
+		"recipient_info": cfg.Workers.RecipientInfo,
+		"bot_info":       cfg.Workers.BotInfo,
+		"stats_daily":    cfg.Workers.StatsDaily,
+		"retry_queue":    cfg.Workers.RetryQueue,
+		"mapping_prune":  cfg.Workers.MappingPrune,
+		"mute_expiry":    cfg.Workers.MuteExpiry,
+		"later":          cfg.Workers.Later,
+	}
+	for name, worker := range workers {
+		if worker.Enabled && worker.IntervalSeconds <= 0 {
+			return fmt.Errorf("workers.%s.interval_seconds must be greater than 0", name)
+		}
+		if worker.JitterSeconds < 0 {
+			return fmt.Errorf("workers.%s.jitter_seconds must not be negative", name)
+		}
+	}
+
+	if days := cfg.Retention.MessageMappingDays; days != 0 && days < MinMessageMappingRetentionDays {
+		return fmt.Errorf("retention.message_mapping_days must be 0 or at least %d", MinMessageMappingRetentionDays)
+	}
+	if cfg.Retention.PruneBatchSize <= 0 {
+		return fmt.Errorf("retention.prune_batch_size must be greater than 0")
+	}
+
 	// Validate log output
 	validOutputs := map[string]bool{
 		"stdout": true,
@@ -130,6 +549,52 @@ func validate(cfg *Config) error {
 	return nil
 }
 
+// Limits of the getUpdates parameters
+const (
+	maxPollingTimeout = 50 // Telegram closes longer requests itself
+	maxPollingLimit   = 100
+)
+
+// updateTypes are the update types Telegram can be asked to send
+var updateTypes = map[string]bool{
+	"message": true, "edited_message": true, "channel_post": true, "edited_channel_post": true,
+	"business_connection": true, "business_message": true, "edited_business_message": true,
+	"deleted_business_messages": true, "message_reaction": true, "message_reaction_count": true,
+	"inline_query": true, "chosen_inline_result": true, "callback_query": true, "shipping_query": true,
+	"pre_checkout_query": true, "purchased_paid_media": true, "poll": true, "poll_answer": true,
+	"my_chat_member": true, "chat_member": true, "chat_join_request": true, "chat_boost": true,
+	"removed_chat_boost": true,
+}
+
+// validatePolling checks polling options; a zero limit is left to the caller
+func validatePolling(prefix string, timeout int, limit int, allowedUpdates []string) error {
+	if timeout < 0 || timeout > maxPollingTimeout {
+		return fmt.Errorf("%s.timeout_seconds must be between 0 and %d", prefix, maxPollingTimeout)
+	}
+	if limit < 0 || limit > maxPollingLimit {
+		return fmt.Errorf("%s.limit must be between 1 and %d", prefix, maxPollingLimit)
+	}
+	for _, updateType := range allowedUpdates {
+		if !updateTypes[updateType] {
+			return fmt.Errorf("%s.allowed_updates: unknown update type %q", prefix, updateType)
+		}
+	}
+	return nil
+}
+
+// validSecretToken reports whether token is allowed as a Telegram webhook secret token
+func validSecretToken(token string) bool {
+	if len(token) == 0 || len(token) > 256 {
+		return false
+	}
+	for _, c := range token {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
 func LoadFromFile(filePath string) (*Config, error) {
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
@@ -162,12 +627,18 @@ func SaveExampleConfig(filePath string) error {
   token: "YOUR_MANAGER_BOT_TOKEN"
   superusers: [123456789, 987654321]
 
+notifications:
+  superuser_dms: true
+  log_chat_id: 0
+  audit_actions: ["add_bot", "delete_bot", "suspend_manager", "unsuspend_manager", "set_token", "add_superuser", "del_superuser", "lockdown", "lift_lockdown", "refund"]
+
 database:
   type: "sqlite"
   dsn: "bot.db"
 
 redis:
   enabled: false
+  mode: "standalone"
   address: "localhost:6379"
   password: ""
   db: 0
@@ -178,7 +649,10 @@ rate_limit:
 
 retry:
   max_attempts: 10
-  interval_seconds: 30
+  interval_seconds: 1
+  multiplier: 2
+  max_interval_seconds: 60
+  queue_max_attempts: 20
 
 log:
   level: "debug"
@@ -186,6 +660,158 @@ log:
   file_path: "bot.log"
 
 environment: "development"
+
+# Date and number formatting: en, en-us, en-gb, de, es, fr, ru, ja, zh
+locale: "en"
+
+failure_notice:
+  enabled: true
+  cooldown_seconds: 600
+
+access_denied:
+  alert_threshold: 5
+  window_seconds: 600
+
+loop_detection:
+  threshold: 5
+  window_seconds: 60
+  pause_seconds: 600
+
+anti_flood:
+  max_messages: 20
+  window_seconds: 60
+  mute_seconds: 900
+
+later:
+  max_delay_hours: 168
+  max_per_guest: 5
+
+tiers:
+  free:
+    max_bots: 3
+    broadcast: false
+    archive: false
+    translation: false
+  pro:
+    max_bots: 0
+    broadcast: true
+    archive: true
+    translation: true
+
+llm:
+  enabled: false
+  provider: "openai"
+  endpoint: "https://api.openai.com/v1/chat/completions"
+  api_key: ""
+  model: ""
+  context_messages: 20
+  max_tokens: 500
+  timeout_seconds: 30
+
+transcription:
+  enabled: false
+  provider: "openai"
+  endpoint: "https://api.openai.com/v1/audio/transcriptions"
+  api_key: ""
+  model: "whisper-1"
+  language: ""
+  max_duration_seconds: 300
+  timeout_seconds: 60
+
+ocr:
+  enabled: false
+  provider: "tesseract"
+  tesseract_path: "tesseract"
+  endpoint: ""
+  api_key: ""
+  language: ""
+  timeout_seconds: 15
+
+file_scan:
+  enabled: false
+  provider: "clamav"
+  clamav_address: "tcp://127.0.0.1:3310"
+  virustotal_api_key: ""
+  virustotal_min_detections: 2
+  timeout_seconds: 60
+  withhold_unscanned: false
+
+api:
+  enabled: false
+  listen_addr: "127.0.0.1:8080"
+  keys: []
+
+webhook:
+  enabled: false
+  public_url: ""
+  listen_addr: ":8443"
+  cert_file: ""
+  key_file: ""
+  self_signed: false
+  secret_token: ""
+
+polling:
+  timeout_seconds: 30
+  limit: 100
+  allowed_updates: []
+  bots: []
+
+failover:
+  enabled: false
+  instance_id: ""
+  lease_seconds: 30
+  heartbeat_seconds: 10
+  fencing_delay_seconds: 45
+
+chaos:
+  enabled: false
+  rate_limit_rate: 0.0
+  retry_after_seconds: 1
+  server_error_rate: 0.0
+  timeout_rate: 0.0
+  methods: []
+
+workers:
+  auto_approve:
+    enabled: true
+    interval_seconds: 3600
+    jitter_seconds: 300
+  group_check:
+    enabled: true
+    interval_seconds: 86400
+    jitter_seconds: 600
+  recipient_info:
+    enabled: true
+    interval_seconds: 86400
+    jitter_seconds: 900
+  bot_info:
+    enabled: true
+    interval_seconds: 86400
+    jitter_seconds: 900
+  stats_daily:
+    enabled: true
+    interval_seconds: 86400
+    jitter_seconds: 600
+  retry_queue:
+    enabled: true
+    interval_seconds: 60
+    jitter_seconds: 30
+  mapping_prune:
+    enabled: true
+    interval_seconds: 3600
+    jitter_seconds: 600
+  mute_expiry:
+    enabled: true
+    interval_seconds: 60
+    jitter_seconds: 10
+  later:
+    enabled: true
+    interval_seconds: 60
+    jitter_seconds: 10
+
+retention:
+  message_mapping_days: 0
+  prune_batch_size: 1000
 `
 
 	return os.WriteFile(filePath, []byte(exampleConfig), 0644)
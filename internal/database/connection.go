@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"go-telegram-forwarder-bot/internal/config"
 	"gorm.io/driver/mysql"
@@ -31,3 +33,38 @@ func Connect(cfg config.DatabaseConfig) (*gorm.DB, error) {
 
 	return db, nil
 }
+
+// PingDB checks that the database is reachable. database/sql replaces broken
+// connections on its own, so a successful ping also means the pool has recovered.
+func PingDB(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// RetryDBPing pings the database up to maxRetries times, doubling the wait
+// between attempts starting at interval
+func RetryDBPing(ctx context.Context, db *gorm.DB, maxRetries int, interval time.Duration) error {
+	var err error
+	for i := 0; i < maxRetries; i++ {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err = PingDB(pingCtx, db)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		if i < maxRetries-1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+			interval *= 2
+		}
+	}
+
+	return fmt.Errorf("database unreachable after %d retries: %w", maxRetries, err)
+}
@@ -2,12 +2,19 @@ package database
 
 import (
 	"fmt"
+
 	"go-telegram-forwarder-bot/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func Migrate(db *gorm.DB) error {
+	// Inbound messages were previously counted from message mappings; backfill
+	// them the first time the table is created
+	needsInboundBackfill := !db.Migrator().HasTable(&models.InboundMessage{})
+
 	if err := db.AutoMigrate(
 		&models.User{},
 		&models.ForwarderBot{},
@@ -18,10 +25,35 @@ func Migrate(db *gorm.DB) error {
 		&models.BlacklistApprovalMessage{},
 		&models.MessageMapping{},
 		&models.AuditLog{},
+		&models.Report{},
+		&models.WorkerLock{},
+		&models.AdminInvite{},
+		&models.InboundMessage{},
+		&models.StatsDaily{},
+		&models.BotUsage{},
+		&models.Payment{},
+		&models.WelcomeAssignment{},
+		&models.BotSetting{},
+		&models.AutoReply{},
+		&models.FilterRule{},
+		&models.QuarantinedMessage{},
+		&models.GuestTopic{},
+		&models.Superuser{},
+		&models.PendingDelivery{},
+		&models.RoutingRule{},
+		&models.DeadLetter{},
+		&models.ScheduledMessage{},
+		&models.BotDataKey{},
 	); err != nil {
 		return err
 	}
 
+	if needsInboundBackfill {
+		if err := backfillInboundMessages(db); err != nil {
+			return fmt.Errorf("failed to backfill inbound messages: %w", err)
+		}
+	}
+
 	// Create composite indexes
 	if err := createIndexes(db); err != nil {
 		return err
@@ -88,7 +120,16 @@ func createIndexes(db *gorm.DB) error {
 		{"idx_guest_message", "message_mappings", []string{"guest_chat_id", "guest_message_id"}, false},
 		{"idx_recipient_message", "message_mappings", []string{"recipient_chat_id", "recipient_message_id"}, false},
 		{"idx_bot_created", "message_mappings", []string{"bot_id", "created_at"}, false},
-		{"idx_guest_bot_user", "guests", []string{"bot_id", "guest_user_id"}, true},
+		// One mapping per copy of a message, so handling an update twice does not duplicate it
+		{"idx_mapping_guest_copy", "message_mappings", []string{"bot_id", "direction", "guest_chat_id", "guest_message_id", "recipient_chat_id"}, true},
+		{"idx_mapping_recipient_copy", "message_mappings", []string{"bot_id", "direction", "recipient_chat_id", "recipient_message_id", "guest_chat_id"}, true},
+	}
+
+	if !migrator.HasIndex("message_mappings", "idx_mapping_guest_copy") ||
+		!migrator.HasIndex("message_mappings", "idx_mapping_recipient_copy") {
+		if err := dedupeMessageMappings(db); err != nil {
+			return fmt.Errorf("failed to remove duplicate message mappings: %w", err)
+		}
 	}
 
 	for _, idx := range indexes {
@@ -132,3 +173,69 @@ func createIndexes(db *gorm.DB) error {
 
 	return nil
 }
+
+// dedupeMessageMappings removes mappings recorded twice for the same copy of a
+// message, e.g. when an update was handled twice, so the unique indexes can be
+// created. The derived table is needed because MySQL cannot select from the
+// table it deletes from.
+func dedupeMessageMappings(db *gorm.DB) error {
+	for _, columns := range []string{
+		"bot_id, direction, guest_chat_id, guest_message_id, recipient_chat_id",
+		"bot_id, direction, recipient_chat_id, recipient_message_id, guest_chat_id",
+	} {
+		if err := db.Exec(fmt.Sprintf(`DELETE FROM message_mappings WHERE id NOT IN (
+			SELECT id FROM (SELECT MIN(id) AS id FROM message_mappings GROUP BY %s) AS keep
+		)`, columns)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillInboundMessages creates one InboundMessage per distinct guest message
+// found in existing inbound message mappings
+func backfillInboundMessages(db *gorm.DB) error {
+	type inboundRow struct {
+		BotID          uuid.UUID
+		GuestChatID    int64
+		GuestMessageID int64
+		CopyCount      int
+	}
+
+	var rows []inboundRow
+	if err := db.Model(&models.MessageMapping{}).
+		Select("bot_id, guest_chat_id, guest_message_id, COUNT(*) AS copy_count").
+		Where("direction = ?", models.MessageDirectionInbound).
+		Group("bot_id, guest_chat_id, guest_message_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	messages := make([]*models.InboundMessage, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, &models.InboundMessage{
+			BotID:          row.BotID,
+			GuestChatID:    row.GuestChatID,
+			GuestMessageID: row.GuestMessageID,
+			RecipientCount: row.CopyCount,
+			DeliveredCount: row.CopyCount,
+		})
+	}
+
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(messages, 500).Error; err != nil {
+		return err
+	}
+
+	// Keep the original receive time so time-based statistics stay correct.
+	// Done in SQL because MIN() over a datetime column loses its type on SQLite.
+	return db.Exec(`UPDATE inbound_messages SET created_at = (
+		SELECT MIN(m.created_at) FROM message_mappings m
+		WHERE m.bot_id = inbound_messages.bot_id
+			AND m.guest_chat_id = inbound_messages.guest_chat_id
+			AND m.guest_message_id = inbound_messages.guest_message_id
+			AND m.direction = ?
+	)`, models.MessageDirectionInbound).Error
+}
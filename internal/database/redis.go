@@ -2,7 +2,10 @@ package database
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"os"
 	"time"
 
 	"go-telegram-forwarder-bot/internal/config"
@@ -10,28 +13,104 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-func ConnectRedis(cfg config.RedisConfig) (*redis.Client, error) {
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+// ConnectRedis connects to Redis in standalone, sentinel or cluster mode.
+// The returned client is nil if Redis is disabled.
+func ConnectRedis(cfg config.RedisConfig) (redis.UniversalClient, error) {
 	if !cfg.Enabled {
 		return nil, nil
 	}
 
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.Address,
-		Password: cfg.Password,
-		DB:       cfg.DB,
-	})
+	tlsConfig, err := buildRedisTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Redis TLS config: %w", err)
+	}
+
+	var rdb redis.UniversalClient
+	switch cfg.Mode {
+	case "", RedisModeStandalone:
+		rdb = redis.NewClient(&redis.Options{
+			Addr:      cfg.Address,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			DB:        cfg.DB,
+			TLSConfig: tlsConfig,
+		})
+	case RedisModeSentinel:
+		rdb = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.SentinelAddresses,
+			SentinelUsername: cfg.SentinelUsername,
+			SentinelPassword: cfg.SentinelPassword,
+			Username:         cfg.Username,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			TLSConfig:        tlsConfig,
+		})
+	case RedisModeCluster:
+		// Redis Cluster only supports database 0
+		rdb = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:     cfg.ClusterAddresses,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			TLSConfig: tlsConfig,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported Redis mode: %s", cfg.Mode)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
 	return rdb, nil
 }
 
-func RetryRedisConnection(cfg config.RedisConfig, maxRetries int, interval time.Duration) (*redis.Client, error) {
+// buildRedisTLSConfig returns nil if TLS is disabled
+func buildRedisTLSConfig(cfg config.RedisTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		caPEM, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func RetryRedisConnection(cfg config.RedisConfig, maxRetries int, interval time.Duration) (redis.UniversalClient, error) {
 	if !cfg.Enabled {
 		return nil, nil
 	}
@@ -0,0 +1,108 @@
+package i18n
+
+// en is the English bundle. Every key used by the bots must be here; other
+// bundles fall back to it.
+var en = map[string]string{
+	"common.error":         "An error occurred. Please try again later.",
+	"list.separator":       ", ",
+	"language.current":     "Language: %s",
+	"language.auto":        "Automatic, from your Telegram app (%s)",
+	"language.choose":      "Choose the language I use with you:",
+	"language.usage":       "Or send /language <code>, e.g. /language ru. /language auto follows your Telegram app again.",
+	"language.unknown":     "Unsupported language %q. Supported: %s",
+	"language.set":         "Language set to %s.",
+	"language.set_auto":    "I will follow the language of your Telegram app again (%s).",
+	"language.button_auto": "Automatic",
+	"language.failed":      "Failed to update your language. Please try again later.",
+
+	"duration.a_minute":      "a minute",
+	"duration.minutes.one":   "about %d minute",
+	"duration.minutes.other": "about %d minutes",
+	"duration.hours.one":     "about %d hour",
+	"duration.hours.other":   "about %d hours",
+	"duration.days.one":      "about %d day",
+	"duration.days.other":    "about %d days",
+
+	"guest.failure_notice":    "We couldn't receive your message right now. Please try again later.",
+	"guest.throttled":         "You're sending messages too fast. Messages sent now are not delivered; please wait %ds and try again.",
+	"guest.muted":             "You've sent too many messages in a short time. Your messages will not be delivered for %s.",
+	"guest.queue.received":    "✅ Your message was received.",
+	"guest.queue.next":        "Yours is next in line.",
+	"guest.queue.ahead.one":   "%d conversation is ahead of yours.",
+	"guest.queue.ahead.other": "%d conversations are ahead of yours.",
+	"guest.queue.reply_time":  "The team usually replies within %s.",
+
+	"guest.filtered":                "Your message was not forwarded because it contains %s.",
+	"guest.filtered.via_bot":        "Your message was not forwarded because it was sent via another bot.",
+	"guest.filtered.reason.mention": "a mention (@username)",
+	"guest.filtered.reason.link":    "a link (http/https)",
+	"guest.filtered.reason.button":  "buttons",
+	"guest.filtered.reason.via_bot": "content sent via another bot",
+
+	"guest.file.unchecked": "Your file was not delivered because it could not be checked for viruses. Please try again later or send a smaller file.",
+	"guest.file.flagged":   "Your file was not delivered because the virus scanner flagged it.",
+
+	"guest.terms.prompt":        "Before your messages can be delivered, please read and accept the following terms:\n\n%s",
+	"guest.terms.accept_button": "✅ I accept",
+	"guest.terms.accepted":      "Thank you. You can now send messages.",
+	"guest.terms.accepted_on":   "Accepted on %s",
+	"guest.terms.changed":       "The terms have changed. Please review the updated version.",
+
+	"guest.help.default": "This bot lets you contact the team behind it.\n\n" +
+		"Just send your message here, text or media. It is passed on to the team, and their replies will arrive in this chat.\n" +
+		"To add to a conversation, reply to one of the team's messages.",
+	"guest.help.commands": "Commands:",
+	"guest.help.help":     "/help - Show this message",
+	"guest.help.terms":    "/terms - Show the terms of this bot",
+	"guest.help.credits":  "/credits - Show your message credits and buy more",
+	"guest.help.later":    "/later <delay> <text> - Send a message later, e.g. /later 2h I'll send the documents then",
+	"guest.help.unban":    "/unban - Ask to be unblocked if your messages are no longer delivered",
+	"guest.help.report":   "/report <reason> - Report abuse of this bot to the instance administrators",
+	"guest.help.language": "/language - Choose the language of this bot's messages",
+
+	"manager.help.title":            "*ManagerBot Commands*",
+	"manager.help.help":             "*/help* - Show this help message",
+	"manager.help.addbot":           "*/addbot <token>* - Register a new ForwarderBot",
+	"manager.help.mybots":           "*/mybots* - List all your ForwarderBots",
+	"manager.help.settoken":         "*/settoken <bot> <token>* - Replace a bot's token after revoking it in @BotFather",
+	"manager.help.retryqueue":       "*/retryqueue <bot> [flush|clear]* - Show, retry or drop messages that failed to deliver",
+	"manager.help.replay":           "*/replay <bot> queue [n,...]|archive <chat_id> <from> [to]* - Deliver queued messages now, or re-deliver received messages to a recipient",
+	"manager.help.timezone":         "*/timezone <timezone>* - Set your timezone for displayed times (`default` for the server's)",
+	"manager.help.language":         "*/language [code]* - Choose the language the bots use with you",
+	"manager.help.settings":         "*/settings <bot>* - Open a bot's settings, e.g. to hide guest names",
+	"manager.help.importsettings":   "*/importsettings <bot>* - Reply to an exported settings file to apply it to a bot",
+	"manager.help.superuser_title":  "*Superuser Commands:*",
+	"manager.help.manage":           "*/manage* - Open management menu",
+	"manager.help.stats":            "*/stats* - View global statistics",
+	"manager.help.suspendmanager":   "*/suspendmanager <user_id>* - Suspend a manager and pause their bots",
+	"manager.help.unsuspendmanager": "*/unsuspendmanager <user_id>* - Lift a manager suspension",
+	"manager.help.reports":          "*/reports* - Review open abuse reports",
+	"manager.help.setquota":         "*/setquota <bot> <messages|off>* - Set a bot's monthly message quota",
+	"manager.help.usage_cmd":        "*/usage [YYYY-MM] [csv|json]* - Export monthly usage per manager",
+	"manager.help.registry":         "*/registry [csv|json]* - Export all registered bots",
+	"manager.help.settier":          "*/settier <user_id> <free|pro>* - Set a manager's subscription tier",
+	"manager.help.addsuper":         "*/addsuper <user_id>* - Make a user a superuser",
+	"manager.help.delsuper":         "*/delsuper <user_id>* - Remove a superuser",
+	"manager.help.lockdown":         "*/lockdown <on|duration|off> [note]* - Disable commands for everyone but superusers",
+	"manager.help.usage_title":      "*Usage:*",
+	"manager.help.step1":            "1. Use /addbot to register a ForwarderBot",
+	"manager.help.step2":            "2. Use /mybots to manage your bots",
+	"manager.help.step3":            "3. Each ForwarderBot can forward messages between Guests and Recipients",
+	"manager.help.groups":           "Only /help works in groups. Send the other commands to me in a private chat.",
+
+	"later.private_only":    "Please send /later in a private chat with this bot.",
+	"later.disabled":        "Sending messages later is not available on this bot.",
+	"later.usage":           "Usage: /later <delay> <text>\nThe delay is like 30m, 2h or 1d, e.g. /later 2h I'll send the documents then.\n/later shows your waiting messages and /later cancel drops them.",
+	"later.too_long":        "The delay can be at most %s.",
+	"later.paywall":         "Messages to this bot must be paid for, so they cannot be sent later.",
+	"later.limit":           "You already have %d messages waiting. Wait until they are sent or drop them with /later cancel.",
+	"later.scheduled":       "⏰ Your message will be delivered in %s.",
+	"later.none":            "You have no messages waiting.",
+	"later.list":            "⏰ Your waiting messages:",
+	"later.due_in":          "in %s",
+	"later.cancel_hint":     "Send /later cancel to drop them.",
+	"later.cancelled.one":   "%d waiting message dropped.",
+	"later.cancelled.other": "%d waiting messages dropped.",
+	"later.delivered":       "⏰ Your message was delivered.",
+	"later.failed":          "⏰ Your message could not be delivered. Please send it again.",
+}
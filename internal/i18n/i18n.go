@@ -0,0 +1,142 @@
+// Package i18n translates the messages the bots send to users.
+//
+// Messages are looked up by key in the bundle of the user's language and fall
+// back to English, so strings can be translated a few at a time: a key missing
+// from a bundle is shown in English rather than breaking the message.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Default is the language used when a user has no preference and Telegram
+// does not report a supported one
+const Default = "en"
+
+// Language is a language the bots can speak
+type Language struct {
+	Code string // e.g. en, zh-CN
+	Name string // Name of the language in itself, shown in the /language menu
+}
+
+// languages are the supported languages, in the order they are offered
+var languages = []Language{
+	{Code: "en", Name: "English"},
+	{Code: "zh-CN", Name: "简体中文"},
+	{Code: "ru", Name: "Русский"},
+}
+
+// bundles holds the messages of each language by key
+var bundles = map[string]map[string]string{
+	"en":    en,
+	"zh-CN": zhCN,
+	"ru":    ru,
+}
+
+// Languages returns the supported languages
+func Languages() []Language {
+	return append([]Language(nil), languages...)
+}
+
+// Name returns the name of a supported language in itself, or the code if it is not supported
+func Name(code string) string {
+	for _, language := range languages {
+		if language.Code == code {
+			return language.Name
+		}
+	}
+	return code
+}
+
+// Match returns the supported language for a Telegram language_code or a
+// name typed by a user, e.g. "zh-hans", "zh_CN" or "RU". Chinese variants all
+// map to Simplified Chinese, the only Chinese bundle.
+func Match(code string) (string, bool) {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+	if code == "" {
+		return "", false
+	}
+	base, _, _ := strings.Cut(code, "-")
+	switch base {
+	case "en":
+		return "en", true
+	case "zh", "cn":
+		return "zh-CN", true
+	case "ru":
+		return "ru", true
+	}
+	return "", false
+}
+
+// Resolve returns the language to talk to a user in: their preference if they
+// set one with /language, otherwise the language their Telegram app reports
+func Resolve(preference string, telegramCode string) string {
+	if _, ok := bundles[preference]; ok {
+		return preference
+	}
+	if lang, ok := Match(telegramCode); ok {
+		return lang
+	}
+	return Default
+}
+
+// T returns the message for key in lang, formatted with args like fmt.Sprintf.
+// Messages missing from lang are taken from English; unknown keys are returned
+// as is, so they show up in testing.
+func T(lang string, key string, args ...interface{}) string {
+	text, ok := bundles[lang][key]
+	if !ok {
+		if text, ok = en[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// N is T for messages about a count n, choosing the plural form lang uses for
+// n. Plural forms are stored as key.one, key.few, key.many and key.other; n is
+// passed to the message before args.
+func N(lang string, key string, n int64, args ...interface{}) string {
+	args = append([]interface{}{n}, args...)
+	form := key + "." + pluralForm(lang, n)
+	if _, ok := bundles[lang][form]; ok {
+		return T(lang, form, args...)
+	}
+	if _, ok := bundles[lang][key+".other"]; ok {
+		return T(lang, key+".other", args...)
+	}
+	if lang != Default {
+		// Missing in lang: fall back to the English plural forms
+		return N(Default, key, n, args[1:]...)
+	}
+	return key
+}
+
+// pluralForm returns the CLDR plural category of n in lang
+func pluralForm(lang string, n int64) string {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case "zh-CN":
+		return "other"
+	case "ru":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
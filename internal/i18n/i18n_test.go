@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var verbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*[a-zA-Z%]`)
+
+func TestBundlesMatchEnglish(t *testing.T) {
+	for lang, bundle := range bundles {
+		for key, text := range bundle {
+			base := key
+			if i := strings.LastIndex(key, "."); i >= 0 {
+				switch key[i+1:] {
+				case "one", "few", "many", "other":
+					base = key[:i]
+				}
+			}
+			english, ok := en[key]
+			if !ok && base != key {
+				english, ok = en[base+".other"]
+			}
+			if !ok {
+				t.Errorf("%s: key %q is not in the English bundle", lang, key)
+				continue
+			}
+			if got, want := verbPattern.FindAllString(text, -1), verbPattern.FindAllString(english, -1); strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("%s: %q has verbs %v, English has %v", lang, key, got, want)
+			}
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := map[string]string{
+		"en":      "en",
+		"en-GB":   "en",
+		"zh-hans": "zh-CN",
+		"zh_CN":   "zh-CN",
+		"zh-TW":   "zh-CN",
+		"RU":      "ru",
+		"ru-RU":   "ru",
+	}
+	for code, want := range tests {
+		if got, ok := Match(code); !ok || got != want {
+			t.Errorf("Match(%q) = %q, %v, want %q", code, got, ok, want)
+		}
+	}
+	for _, code := range []string{"", "de", "xx-en"} {
+		if got, ok := Match(code); ok {
+			t.Errorf("Match(%q) = %q, want no match", code, got)
+		}
+	}
+}
+
+func TestResolvePrefersTheUsersChoice(t *testing.T) {
+	if got := Resolve("ru", "zh-hans"); got != "ru" {
+		t.Fatalf("Resolve with a preference = %q, want ru", got)
+	}
+	if got := Resolve("", "zh-hans"); got != "zh-CN" {
+		t.Fatalf("Resolve from Telegram = %q, want zh-CN", got)
+	}
+	if got := Resolve("", "de"); got != Default {
+		t.Fatalf("Resolve of an unsupported language = %q, want %q", got, Default)
+	}
+}
+
+func TestTFallsBackToEnglish(t *testing.T) {
+	bundles["test"] = map[string]string{"language.set": "Sprache: %s"}
+	defer delete(bundles, "test")
+
+	if got := T("test", "language.set", "Deutsch"); got != "Sprache: Deutsch" {
+		t.Fatalf("T with a translation = %q", got)
+	}
+	if got, want := T("test", "common.error"), en["common.error"]; got != want {
+		t.Fatalf("T without a translation = %q, want the English %q", got, want)
+	}
+	if got := T("test", "no.such.key"); got != "no.such.key" {
+		t.Fatalf("T of an unknown key = %q, want the key", got)
+	}
+}
+
+func TestNPluralForms(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int64
+		want string
+	}{
+		{"en", 1, "1 conversation is ahead of yours."},
+		{"en", 2, "2 conversations are ahead of yours."},
+		{"ru", 1, "Перед вами 1 разговор."},
+		{"ru", 3, "Перед вами 3 разговора."},
+		{"ru", 5, "Перед вами 5 разговоров."},
+		{"ru", 11, "Перед вами 11 разговоров."},
+		{"ru", 21, "Перед вами 21 разговор."},
+		{"ru", 22, "Перед вами 22 разговора."},
+		{"zh-CN", 1, "你前面还有 1 个对话。"},
+	}
+	for _, tt := range tests {
+		if got := N(tt.lang, "guest.queue.ahead", tt.n); got != tt.want {
+			t.Errorf("N(%s, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
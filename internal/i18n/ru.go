@@ -0,0 +1,112 @@
+package i18n
+
+// ru is the Russian bundle
+var ru = map[string]string{
+	"common.error":         "Произошла ошибка. Пожалуйста, попробуйте позже.",
+	"list.separator":       ", ",
+	"language.current":     "Язык: %s",
+	"language.auto":        "Автоматически, как в приложении Telegram (%s)",
+	"language.choose":      "Выберите язык, на котором я буду с вами общаться:",
+	"language.usage":       "Или отправьте /language <код>, например /language en. /language auto снова использует язык приложения Telegram.",
+	"language.unknown":     "Язык %q не поддерживается. Доступные языки: %s",
+	"language.set":         "Выбран язык: %s.",
+	"language.set_auto":    "Я снова буду использовать язык вашего приложения Telegram (%s).",
+	"language.button_auto": "Автоматически",
+	"language.failed":      "Не удалось изменить язык. Пожалуйста, попробуйте позже.",
+
+	"duration.a_minute":     "минуты",
+	"duration.minutes.one":  "примерно %d минуты",
+	"duration.minutes.few":  "примерно %d минут",
+	"duration.minutes.many": "примерно %d минут",
+	"duration.hours.one":    "примерно %d часа",
+	"duration.hours.few":    "примерно %d часов",
+	"duration.hours.many":   "примерно %d часов",
+	"duration.days.one":     "примерно %d дня",
+	"duration.days.few":     "примерно %d дней",
+	"duration.days.many":    "примерно %d дней",
+
+	"guest.failure_notice":   "Сейчас мы не можем принять ваше сообщение. Пожалуйста, попробуйте позже.",
+	"guest.throttled":        "Вы отправляете сообщения слишком часто. Сообщения, отправленные сейчас, не будут доставлены; подождите %d с и попробуйте снова.",
+	"guest.muted":            "Вы отправили слишком много сообщений за короткое время. Ваши сообщения не будут доставляться в течение %s.",
+	"guest.queue.received":   "✅ Ваше сообщение получено.",
+	"guest.queue.next":       "Вы следующий в очереди.",
+	"guest.queue.ahead.one":  "Перед вами %d разговор.",
+	"guest.queue.ahead.few":  "Перед вами %d разговора.",
+	"guest.queue.ahead.many": "Перед вами %d разговоров.",
+	"guest.queue.reply_time": "Обычно команда отвечает в течение %s.",
+
+	"guest.filtered":                "Ваше сообщение не было переслано, так как оно содержит %s.",
+	"guest.filtered.via_bot":        "Ваше сообщение не было переслано, так как оно отправлено через другого бота.",
+	"guest.filtered.reason.mention": "упоминание (@username)",
+	"guest.filtered.reason.link":    "ссылку (http/https)",
+	"guest.filtered.reason.button":  "кнопки",
+	"guest.filtered.reason.via_bot": "содержимое, отправленное через другого бота",
+
+	"guest.file.unchecked": "Ваш файл не был доставлен, так как его не удалось проверить на вирусы. Попробуйте позже или отправьте файл меньшего размера.",
+	"guest.file.flagged":   "Ваш файл не был доставлен, так как антивирус пометил его как опасный.",
+
+	"guest.terms.prompt":        "Прежде чем ваши сообщения смогут быть доставлены, прочитайте и примите следующие условия:\n\n%s",
+	"guest.terms.accept_button": "✅ Принимаю",
+	"guest.terms.accepted":      "Спасибо. Теперь вы можете отправлять сообщения.",
+	"guest.terms.accepted_on":   "Принято %s",
+	"guest.terms.changed":       "Условия изменились. Пожалуйста, ознакомьтесь с новой версией.",
+
+	"guest.help.default": "С помощью этого бота можно связаться с его командой.\n\n" +
+		"Просто отправьте сюда сообщение — текст или медиа. Оно будет передано команде, а её ответы придут в этот чат.\n" +
+		"Чтобы дополнить разговор, ответьте на одно из сообщений команды.",
+	"guest.help.commands": "Команды:",
+	"guest.help.help":     "/help - Показать это сообщение",
+	"guest.help.terms":    "/terms - Показать условия этого бота",
+	"guest.help.credits":  "/credits - Показать оплаченные сообщения и купить ещё",
+	"guest.help.later":    "/later <задержка> <текст> - Отправить сообщение позже, например /later 2h Пришлю документы тогда",
+	"guest.help.unban":    "/unban - Попросить о разблокировке, если ваши сообщения больше не доставляются",
+	"guest.help.report":   "/report <причина> - Сообщить администраторам о злоупотреблении этим ботом",
+	"guest.help.language": "/language - Выбрать язык сообщений бота",
+
+	"manager.help.title":            "*Команды ManagerBot*",
+	"manager.help.help":             "*/help* - Показать эту справку",
+	"manager.help.addbot":           "*/addbot <token>* - Зарегистрировать новый ForwarderBot",
+	"manager.help.mybots":           "*/mybots* - Список ваших ForwarderBot",
+	"manager.help.settoken":         "*/settoken <bot> <token>* - Заменить токен бота после его отзыва в @BotFather",
+	"manager.help.retryqueue":       "*/retryqueue <bot> [flush|clear]* - Показать, повторить или удалить недоставленные сообщения",
+	"manager.help.replay":           "*/replay <bot> queue [n,...]|archive <chat_id> <from> [to]* - Доставить сообщения из очереди сейчас или повторно доставить полученные сообщения получателю",
+	"manager.help.timezone":         "*/timezone <часовой пояс>* - Часовой пояс для отображения времени (`default` — пояс сервера)",
+	"manager.help.language":         "*/language [код]* - Выбрать язык, на котором боты общаются с вами",
+	"manager.help.settings":         "*/settings <bot>* - Открыть настройки бота, например чтобы скрыть имена гостей",
+	"manager.help.importsettings":   "*/importsettings <bot>* - Ответьте на экспортированный файл настроек, чтобы применить его к боту",
+	"manager.help.superuser_title":  "*Команды суперпользователя:*",
+	"manager.help.manage":           "*/manage* - Открыть меню управления",
+	"manager.help.stats":            "*/stats* - Общая статистика",
+	"manager.help.suspendmanager":   "*/suspendmanager <user_id>* - Заблокировать менеджера и остановить его ботов",
+	"manager.help.unsuspendmanager": "*/unsuspendmanager <user_id>* - Снять блокировку менеджера",
+	"manager.help.reports":          "*/reports* - Открытые жалобы на злоупотребления",
+	"manager.help.setquota":         "*/setquota <bot> <messages|off>* - Месячная квота сообщений бота",
+	"manager.help.usage_cmd":        "*/usage [YYYY-MM] [csv|json]* - Экспорт месячного использования по менеджерам",
+	"manager.help.registry":         "*/registry [csv|json]* - Экспорт списка всех зарегистрированных ботов",
+	"manager.help.settier":          "*/settier <user_id> <free|pro>* - Тариф менеджера",
+	"manager.help.addsuper":         "*/addsuper <user_id>* - Сделать пользователя суперпользователем",
+	"manager.help.delsuper":         "*/delsuper <user_id>* - Удалить суперпользователя",
+	"manager.help.lockdown":         "*/lockdown <on|duration|off> [note]* - Отключить команды для всех, кроме суперпользователей",
+	"manager.help.usage_title":      "*Как пользоваться:*",
+	"manager.help.step1":            "1. Зарегистрируйте ForwarderBot командой /addbot",
+	"manager.help.step2":            "2. Управляйте ботами через /mybots",
+	"manager.help.step3":            "3. Каждый ForwarderBot пересылает сообщения между гостями и получателями",
+	"manager.help.groups":           "В группах работает только /help. Остальные команды отправляйте мне в личном чате.",
+
+	"later.private_only":   "Пожалуйста, отправьте /later в личном чате с этим ботом.",
+	"later.disabled":       "Отложенная отправка сообщений недоступна в этом боте.",
+	"later.usage":          "Использование: /later <задержка> <текст>\nЗадержка вида 30m, 2h или 1d, например /later 2h Пришлю документы тогда.\n/later показывает ожидающие сообщения, /later cancel отменяет их.",
+	"later.too_long":       "Задержка может быть не больше %s.",
+	"later.paywall":        "Сообщения этому боту платные, поэтому их нельзя отправить позже.",
+	"later.limit":          "У вас уже %d ожидающих сообщений. Дождитесь их отправки или отмените их командой /later cancel.",
+	"later.scheduled":      "⏰ Ваше сообщение будет доставлено через %s.",
+	"later.none":           "У вас нет ожидающих сообщений.",
+	"later.list":           "⏰ Ваши ожидающие сообщения:",
+	"later.due_in":         "через %s",
+	"later.cancel_hint":    "Отправьте /later cancel, чтобы отменить их.",
+	"later.cancelled.one":  "Отменено %d ожидающее сообщение.",
+	"later.cancelled.few":  "Отменено %d ожидающих сообщения.",
+	"later.cancelled.many": "Отменено %d ожидающих сообщений.",
+	"later.delivered":      "⏰ Ваше сообщение доставлено.",
+	"later.failed":         "⏰ Не удалось доставить ваше сообщение. Пожалуйста, отправьте его ещё раз.",
+}
@@ -0,0 +1,102 @@
+package i18n
+
+// zhCN is the Simplified Chinese bundle
+var zhCN = map[string]string{
+	"common.error":         "发生错误，请稍后再试。",
+	"list.separator":       "、",
+	"language.current":     "语言：%s",
+	"language.auto":        "自动，跟随 Telegram 应用（%s）",
+	"language.choose":      "请选择我与你交流时使用的语言：",
+	"language.usage":       "也可以发送 /language <代码>，例如 /language ru。发送 /language auto 重新跟随 Telegram 应用的语言。",
+	"language.unknown":     "不支持的语言 %q。支持的语言：%s",
+	"language.set":         "语言已设置为%s。",
+	"language.set_auto":    "将重新跟随 Telegram 应用的语言（%s）。",
+	"language.button_auto": "自动",
+	"language.failed":      "更新语言失败，请稍后再试。",
+
+	"duration.a_minute":      "1 分钟",
+	"duration.minutes.other": "约 %d 分钟",
+	"duration.hours.other":   "约 %d 小时",
+	"duration.days.other":    "约 %d 天",
+
+	"guest.failure_notice":    "暂时无法接收你的消息，请稍后再试。",
+	"guest.throttled":         "你发送消息太快了。现在发送的消息不会被送达，请等待 %d 秒后再试。",
+	"guest.muted":             "你在短时间内发送了过多消息，接下来%s内你的消息将不会被送达。",
+	"guest.queue.received":    "✅ 已收到你的消息。",
+	"guest.queue.next":        "下一个就轮到你。",
+	"guest.queue.ahead.other": "你前面还有 %d 个对话。",
+	"guest.queue.reply_time":  "团队通常会在%s内回复。",
+
+	"guest.filtered":                "你的消息未被转发，因为其中包含%s。",
+	"guest.filtered.via_bot":        "你的消息未被转发，因为它是通过其他 Bot 发送的。",
+	"guest.filtered.reason.mention": "提及（@用户名）",
+	"guest.filtered.reason.link":    "链接（http/https）",
+	"guest.filtered.reason.button":  "按钮",
+	"guest.filtered.reason.via_bot": "通过其他 Bot 发送的内容",
+
+	"guest.file.unchecked": "你的文件未被送达，因为无法进行病毒检查。请稍后再试，或发送更小的文件。",
+	"guest.file.flagged":   "你的文件未被送达，因为病毒扫描器将其标记为可疑文件。",
+
+	"guest.terms.prompt":        "在你的消息被送达之前，请阅读并接受以下条款：\n\n%s",
+	"guest.terms.accept_button": "✅ 我接受",
+	"guest.terms.accepted":      "谢谢，你现在可以发送消息了。",
+	"guest.terms.accepted_on":   "已于 %s 接受",
+	"guest.terms.changed":       "条款已更新，请查看新版本。",
+
+	"guest.help.default": "你可以通过这个 Bot 联系其背后的团队。\n\n" +
+		"直接在这里发送消息即可，文字或媒体都可以。消息会转交给团队，他们的回复也会发送到这个对话中。\n" +
+		"如需补充内容，请回复团队发来的某条消息。",
+	"guest.help.commands": "命令：",
+	"guest.help.help":     "/help - 显示此消息",
+	"guest.help.terms":    "/terms - 查看本 Bot 的条款",
+	"guest.help.credits":  "/credits - 查看消息额度并购买更多",
+	"guest.help.later":    "/later <延迟> <内容> - 稍后发送消息，例如 /later 2h 到时我再发送文件",
+	"guest.help.unban":    "/unban - 如果消息不再被送达，申请解除封禁",
+	"guest.help.report":   "/report <原因> - 向实例管理员举报滥用本 Bot 的行为",
+	"guest.help.language": "/language - 选择本 Bot 消息使用的语言",
+
+	"manager.help.title":            "*ManagerBot 命令*",
+	"manager.help.help":             "*/help* - 显示此帮助信息",
+	"manager.help.addbot":           "*/addbot <token>* - 注册新的 ForwarderBot",
+	"manager.help.mybots":           "*/mybots* - 列出你的所有 ForwarderBot",
+	"manager.help.settoken":         "*/settoken <bot> <token>* - 在 @BotFather 中撤销 Token 后替换 Bot 的 Token",
+	"manager.help.retryqueue":       "*/retryqueue <bot> [flush|clear]* - 查看、重试或丢弃投递失败的消息",
+	"manager.help.replay":           "*/replay <bot> queue [n,...]|archive <chat_id> <起始> [结束]* - 立即投递重试队列中的消息，或将收到的消息重新投递给某个 Recipient",
+	"manager.help.timezone":         "*/timezone <时区>* - 设置显示时间所用的时区（`default` 使用服务器时区）",
+	"manager.help.language":         "*/language [代码]* - 选择 Bot 与你交流时使用的语言",
+	"manager.help.settings":         "*/settings <bot>* - 打开 Bot 的设置，例如隐藏 Guest 姓名",
+	"manager.help.importsettings":   "*/importsettings <bot>* - 回复导出的设置文件，将其应用到 Bot",
+	"manager.help.superuser_title":  "*Superuser 命令：*",
+	"manager.help.manage":           "*/manage* - 打开管理菜单",
+	"manager.help.stats":            "*/stats* - 查看全局统计",
+	"manager.help.suspendmanager":   "*/suspendmanager <user_id>* - 暂停 Manager 并停止其 Bot",
+	"manager.help.unsuspendmanager": "*/unsuspendmanager <user_id>* - 解除 Manager 的暂停",
+	"manager.help.reports":          "*/reports* - 处理未解决的滥用举报",
+	"manager.help.setquota":         "*/setquota <bot> <消息数|off>* - 设置 Bot 的月度消息配额",
+	"manager.help.usage_cmd":        "*/usage [YYYY-MM] [csv|json]* - 导出每个 Manager 的月度用量",
+	"manager.help.registry":         "*/registry [csv|json]* - 导出所有已注册 Bot 的清单",
+	"manager.help.settier":          "*/settier <user_id> <free|pro>* - 设置 Manager 的订阅等级",
+	"manager.help.addsuper":         "*/addsuper <user_id>* - 将用户设为 Superuser",
+	"manager.help.delsuper":         "*/delsuper <user_id>* - 移除 Superuser",
+	"manager.help.lockdown":         "*/lockdown <on|时长|off> [备注]* - 对 Superuser 以外的所有人禁用命令",
+	"manager.help.usage_title":      "*使用方法：*",
+	"manager.help.step1":            "1. 使用 /addbot 注册 ForwarderBot",
+	"manager.help.step2":            "2. 使用 /mybots 管理你的 Bot",
+	"manager.help.step3":            "3. 每个 ForwarderBot 在 Guest 和 Recipient 之间转发消息",
+	"manager.help.groups":           "在群组中只能使用 /help，其他命令请在私聊中发送给我。",
+
+	"later.private_only":    "请在与本 Bot 的私聊中发送 /later。",
+	"later.disabled":        "本 Bot 未开启稍后发送消息。",
+	"later.usage":           "用法：/later <延迟> <内容>\n延迟格式如 30m、2h 或 1d，例如 /later 2h 到时我再发送文件。\n/later 查看等待发送的消息，/later cancel 取消它们。",
+	"later.too_long":        "延迟最长为 %s。",
+	"later.paywall":         "本 Bot 的消息需要付费，因此无法稍后发送。",
+	"later.limit":           "你已有 %d 条消息在等待发送。请等待它们送达，或用 /later cancel 取消。",
+	"later.scheduled":       "⏰ 你的消息将在 %s后送达。",
+	"later.none":            "你没有等待发送的消息。",
+	"later.list":            "⏰ 等待发送的消息：",
+	"later.due_in":          "%s后",
+	"later.cancel_hint":     "发送 /later cancel 可取消它们。",
+	"later.cancelled.other": "已取消 %d 条等待发送的消息。",
+	"later.delivered":       "⏰ 你的消息已送达。",
+	"later.failed":          "⏰ 你的消息未能送达，请重新发送。",
+}
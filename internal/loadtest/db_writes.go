@@ -0,0 +1,73 @@
+package loadtest
+
+import (
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// writeCounter counts the write statements run through a gorm connection, by
+// table. Only writes made while it is counting are kept, so migrations and
+// setup do not skew the rates.
+type writeCounter struct {
+	mu       sync.Mutex
+	counting bool
+	byTable  map[string]int64
+}
+
+func newWriteCounter() *writeCounter {
+	return &writeCounter{byTable: make(map[string]int64)}
+}
+
+// register hooks the counter into the create, update and delete callbacks of
+// db, and into raw statements that write
+func (c *writeCounter) register(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("loadtest:count_create", c.count); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("loadtest:count_update", c.count); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("loadtest:count_delete", c.count); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("gorm:raw").Register("loadtest:count_raw", func(db *gorm.DB) {
+		sql := strings.ToUpper(strings.TrimSpace(db.Statement.SQL.String()))
+		if strings.HasPrefix(sql, "INSERT") || strings.HasPrefix(sql, "UPDATE") || strings.HasPrefix(sql, "DELETE") {
+			c.count(db)
+		}
+	})
+}
+
+// start resets the counts and starts counting
+func (c *writeCounter) start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counting = true
+	c.byTable = make(map[string]int64)
+}
+
+// stop stops counting and returns the writes by table
+func (c *writeCounter) stop() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counting = false
+	return c.byTable
+}
+
+func (c *writeCounter) count(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	table := db.Statement.Table
+	if table == "" {
+		table = "(raw)"
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counting {
+		c.byTable[table]++
+	}
+}
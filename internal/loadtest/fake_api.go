@@ -0,0 +1,349 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// maxPollWait bounds how long a getUpdates request is held open, so the bots
+// notice a shutdown quickly
+const maxPollWait = 5 * time.Second
+
+// messageMarkerPattern finds the marker of a synthetic guest message in the
+// text of a copy, when the bot copies messages instead of forwarding them
+var messageMarkerPattern = regexp.MustCompile(`loadtest (-?\d+):(\d+)`)
+
+// messageKey identifies a guest message
+type messageKey struct {
+	chatID    int64
+	messageID int64
+}
+
+// fakeBot is the state of one bot token on the fake server
+type fakeBot struct {
+	updates  []gotgbot.Update // Updates not yet confirmed by the bot
+	nextID   int64            // Next update ID
+	polling  chan struct{}    // Closed on the bot's first getUpdates
+	pollOnce sync.Once
+	wake     chan struct{} // Closed and replaced when updates arrive
+}
+
+// fakeBotAPI is a Bot API server that feeds synthetic guest messages to the
+// bots and records when their copies reach a recipient. It answers every
+// other method with a plausible result, so the bots run as they would against
+// Telegram, minus the network and Telegram's own limits.
+type fakeBotAPI struct {
+	mu         sync.Mutex
+	bots       map[string]*fakeBot // By token
+	recipients map[int64]bool      // Chat IDs that count as recipients
+	guests     map[int64]bool      // Chat IDs of the synthetic guests
+	sent       map[messageKey]time.Time
+	nextMsgID  int64
+	closed     chan struct{}
+	closeOnce  sync.Once
+
+	delivered    int
+	latencies    []time.Duration
+	lastDelivery time.Time
+	guestNotices int
+	requests     map[string]int // Requests by method
+}
+
+func newFakeBotAPI() *fakeBotAPI {
+	return &fakeBotAPI{
+		bots:       make(map[string]*fakeBot),
+		recipients: make(map[int64]bool),
+		guests:     make(map[int64]bool),
+		sent:       make(map[messageKey]time.Time),
+		closed:     make(chan struct{}),
+		requests:   make(map[string]int),
+	}
+}
+
+// close releases the getUpdates requests being held open
+func (f *fakeBotAPI) close() {
+	f.closeOnce.Do(func() { close(f.closed) })
+}
+
+// addRecipient makes messages to chatID count as deliveries
+func (f *fakeBotAPI) addRecipient(chatID int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recipients[chatID] = true
+}
+
+// bot returns the state of a token, creating it on first use. f.mu must be held.
+func (f *fakeBotAPI) bot(token string) *fakeBot {
+	bot, ok := f.bots[token]
+	if !ok {
+		bot = &fakeBot{
+			nextID:  1,
+			polling: make(chan struct{}),
+			wake:    make(chan struct{}),
+		}
+		f.bots[token] = bot
+	}
+	return bot
+}
+
+// waitPolling blocks until the bot with the token has asked for updates
+func (f *fakeBotAPI) waitPolling(token string, timeout time.Duration) error {
+	f.mu.Lock()
+	polling := f.bot(token).polling
+	f.mu.Unlock()
+
+	select {
+	case <-polling:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("bot did not start polling within %s", timeout)
+	}
+}
+
+// send queues a private message from a guest to the bot with the token
+func (f *fakeBotAPI) send(token string, guestID int64, messageID int64) {
+	now := time.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bot := f.bot(token)
+	bot.updates = append(bot.updates, gotgbot.Update{
+		UpdateId: bot.nextID,
+		Message: &gotgbot.Message{
+			MessageId: messageID,
+			Date:      now.Unix(),
+			Chat:      gotgbot.Chat{Id: guestID, Type: "private", FirstName: "Guest"},
+			From:      &gotgbot.User{Id: guestID, FirstName: "Guest", LanguageCode: "en"},
+			Text:      fmt.Sprintf("Hello from loadtest %d:%d", guestID, messageID),
+		},
+	})
+	bot.nextID++
+	f.sent[messageKey{chatID: guestID, messageID: messageID}] = now
+	f.guests[guestID] = true
+	close(bot.wake)
+	bot.wake = make(chan struct{})
+}
+
+// progress returns the number of deliveries so far and the time of the last
+func (f *fakeBotAPI) progress() (int, time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.delivered, f.lastDelivery
+}
+
+func (f *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Requests go to /bot<token>/<method>
+	path := strings.TrimPrefix(r.URL.Path, "/bot")
+	token, method, ok := strings.Cut(path, "/")
+	if !ok || token == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	params := make(map[string]string)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		if err := r.ParseMultipartForm(10 << 20); err == nil {
+			for key, values := range r.MultipartForm.Value {
+				params[key] = values[0]
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&params); err != nil && r.ContentLength != 0 {
+		writeError(w, http.StatusBadRequest, "Bad Request: invalid parameters")
+		return
+	}
+
+	f.mu.Lock()
+	f.requests[method]++
+	f.mu.Unlock()
+
+	result, err := f.handle(r, token, method, params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Bad Request: "+err.Error())
+		return
+	}
+	writeResult(w, result)
+}
+
+// handle answers one Bot API request
+func (f *fakeBotAPI) handle(r *http.Request, token string, method string, params map[string]string) (any, error) {
+	switch method {
+	case "getMe":
+		id, _, _ := strings.Cut(token, ":")
+		botID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token")
+		}
+		return gotgbot.User{
+			Id:        botID,
+			IsBot:     true,
+			FirstName: "Load test",
+			Username:  fmt.Sprintf("loadtest_%d_bot", botID),
+		}, nil
+	case "getUpdates":
+		return f.getUpdates(r, token, params), nil
+	case "sendMessage", "forwardMessage", "copyMessage":
+		chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+		f.record(method, chatID, params)
+		message := f.message(chatID)
+		if method == "copyMessage" {
+			return gotgbot.MessageId{MessageId: message.MessageId}, nil
+		}
+		return message, nil
+	case "sendPhoto", "sendDocument", "sendVideo", "sendAudio", "sendVoice", "sendAnimation", "sendSticker",
+		"editMessageText", "editMessageCaption", "editMessageReplyMarkup", "sendInvoice":
+		chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+		return f.message(chatID), nil
+	case "getChat":
+		chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+		return gotgbot.ChatFullInfo{Id: chatID, Type: "private", FirstName: "Load test"}, nil
+	case "getChatMember":
+		userID, _ := strconv.ParseInt(params["user_id"], 10, 64)
+		return map[string]any{"status": "member", "user": gotgbot.User{Id: userID, FirstName: "Load test"}}, nil
+	case "getMyCommands":
+		return []gotgbot.BotCommand{}, nil
+	case "getWebhookInfo":
+		return gotgbot.WebhookInfo{}, nil
+	default:
+		// setMyCommands, deleteWebhook, sendChatAction, setMessageReaction, ...
+		return true, nil
+	}
+}
+
+// getUpdates returns the queued updates from the offset on, waiting for new
+// ones up to the requested timeout
+func (f *fakeBotAPI) getUpdates(r *http.Request, token string, params map[string]string) []gotgbot.Update {
+	offset, _ := strconv.ParseInt(params["offset"], 10, 64)
+	limit, _ := strconv.Atoi(params["limit"])
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	// Like Telegram, no timeout means a short poll
+	seconds, _ := strconv.Atoi(params["timeout"])
+	wait := min(time.Duration(seconds)*time.Second, maxPollWait)
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+
+	for {
+		f.mu.Lock()
+		bot := f.bot(token)
+		bot.pollOnce.Do(func() { close(bot.polling) })
+		// Updates before the offset are confirmed and can be dropped
+		kept := bot.updates[:0]
+		for _, update := range bot.updates {
+			if update.UpdateId >= offset {
+				kept = append(kept, update)
+			}
+		}
+		bot.updates = kept
+		if len(kept) > 0 {
+			updates := make([]gotgbot.Update, min(limit, len(kept)))
+			copy(updates, kept)
+			f.mu.Unlock()
+			return updates
+		}
+		wake := bot.wake
+		f.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-deadline.C:
+			return []gotgbot.Update{}
+		case <-f.closed:
+			return []gotgbot.Update{}
+		case <-r.Context().Done():
+			return []gotgbot.Update{}
+		}
+	}
+}
+
+// record counts a message sent by a bot, as a delivery if it went to a
+// recipient and as a notice if it went back to a guest
+func (f *fakeBotAPI) record(method string, chatID int64, params map[string]string) {
+	now := time.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.recipients[chatID] {
+		if f.guests[chatID] {
+			f.guestNotices++
+		}
+		return
+	}
+
+	var key messageKey
+	if method == "sendMessage" {
+		match := messageMarkerPattern.FindStringSubmatch(params["text"])
+		if match == nil {
+			return // A notice to the recipient rather than a copy
+		}
+		key.chatID, _ = strconv.ParseInt(match[1], 10, 64)
+		key.messageID, _ = strconv.ParseInt(match[2], 10, 64)
+	} else {
+		key.chatID, _ = strconv.ParseInt(params["from_chat_id"], 10, 64)
+		key.messageID, _ = strconv.ParseInt(params["message_id"], 10, 64)
+	}
+	sentAt, ok := f.sent[key]
+	if !ok {
+		return
+	}
+	f.delivered++
+	f.latencies = append(f.latencies, now.Sub(sentAt))
+	f.lastDelivery = now
+}
+
+// message returns a message the bot just sent to chatID. f.mu must not be held.
+func (f *fakeBotAPI) message(chatID int64) gotgbot.Message {
+	f.mu.Lock()
+	f.nextMsgID++
+	id := f.nextMsgID
+	f.mu.Unlock()
+
+	chatType := "private"
+	if chatID < 0 {
+		chatType = "supergroup"
+	}
+	return gotgbot.Message{
+		MessageId: id,
+		Date:      time.Now().Unix(),
+		Chat:      gotgbot.Chat{Id: chatID, Type: chatType},
+	}
+}
+
+// latencyPercentiles returns the given percentiles of the delivery latencies
+// and the maximum
+func (f *fakeBotAPI) latencyPercentiles(percentiles ...float64) ([]time.Duration, time.Duration) {
+	f.mu.Lock()
+	latencies := append([]time.Duration(nil), f.latencies...)
+	f.mu.Unlock()
+
+	values := make([]time.Duration, len(percentiles))
+	if len(latencies) == 0 {
+		return values, 0
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	for i, p := range percentiles {
+		index := int(float64(len(latencies))*p/100+0.5) - 1
+		values[i] = latencies[max(0, min(index, len(latencies)-1))]
+	}
+	return values, latencies[len(latencies)-1]
+}
+
+func writeResult(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func writeError(w http.ResponseWriter, code int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": code, "description": description})
+}
@@ -0,0 +1,74 @@
+package loadtest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func newTestBot(t *testing.T, api *fakeBotAPI, token string) *gotgbot.Bot {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	t.Cleanup(api.close)
+
+	b, err := gotgbot.NewBot(token, &gotgbot.BotOpts{
+		BotClient: &gotgbot.BaseBotClient{
+			DefaultRequestOpts: &gotgbot.RequestOpts{APIURL: server.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+	return b
+}
+
+func TestFakeBotAPIDeliversGuestMessages(t *testing.T) {
+	api := newFakeBotAPI()
+	token := fakeToken(forwarderTelegramID)
+	b := newTestBot(t, api, token)
+	if b.Id != forwarderTelegramID {
+		t.Fatalf("getMe returned bot %d, want %d", b.Id, forwarderTelegramID)
+	}
+
+	recipient := firstRecipientChatID
+	api.addRecipient(recipient)
+	guest := firstGuestUserID
+	api.send(token, guest, 1)
+	api.send(token, guest, 2)
+
+	updates, err := b.GetUpdates(&gotgbot.GetUpdatesOpts{Timeout: 1})
+	if err != nil {
+		t.Fatalf("GetUpdates: %v", err)
+	}
+	if len(updates) != 2 || updates[0].Message.From.Id != guest || updates[1].Message.MessageId != 2 {
+		t.Fatalf("GetUpdates = %+v, want both guest messages", updates)
+	}
+	// Confirmed updates are not handed out again
+	if updates, _ := b.GetUpdates(&gotgbot.GetUpdatesOpts{Offset: updates[1].UpdateId + 1, Timeout: 0}); len(updates) != 0 {
+		t.Fatalf("GetUpdates after the offset = %+v, want none", updates)
+	}
+
+	// One message forwarded, the other copied as text, plus a notice to the guest
+	if _, err := b.ForwardMessage(recipient, guest, 1, nil); err != nil {
+		t.Fatalf("ForwardMessage: %v", err)
+	}
+	if _, err := b.SendMessage(recipient, updates[1].Message.Text, nil); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if _, err := b.SendMessage(guest, "Slow down", nil); err != nil {
+		t.Fatalf("SendMessage to guest: %v", err)
+	}
+
+	if delivered, _ := api.progress(); delivered != 2 {
+		t.Fatalf("delivered = %d, want 2", delivered)
+	}
+	if api.guestNotices != 1 {
+		t.Fatalf("guest notices = %d, want 1", api.guestNotices)
+	}
+	percentiles, maxLatency := api.latencyPercentiles(50, 99)
+	if percentiles[0] <= 0 || percentiles[1] > maxLatency {
+		t.Fatalf("latencies p50 %s, p99 %s, max %s; want positive and ordered", percentiles[0], percentiles[1], maxLatency)
+	}
+}
@@ -0,0 +1,377 @@
+// Package loadtest runs the bot against a fake Bot API server with synthetic
+// guests, to measure how many messages an instance can forward and how much
+// it writes to the database while doing so.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/app"
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/database"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Synthetic Telegram IDs, far above those of real users and bots
+const (
+	managerBotTelegramID  int64 = 7_000_000_000
+	forwarderTelegramID   int64 = 7_000_000_001 // Of the first ForwarderBot
+	managerUserID         int64 = 8_000_000_000
+	firstGuestUserID      int64 = 8_100_000_000
+	firstRecipientChatID  int64 = 8_900_000_000
+	startupTimeout              = 30 * time.Second
+	shutdownTimeout             = 30 * time.Second
+	progressCheckInterval       = 100 * time.Millisecond
+)
+
+// Options describe the simulated load
+type Options struct {
+	Guests           int           // Synthetic guests sending messages at the same time
+	MessagesPerGuest int           // Messages each guest sends
+	Interval         time.Duration // Time between two messages of a guest
+	Bots             int           // ForwarderBots the guests are spread over
+	Recipients       int           // Recipients of each bot, including its manager
+	Timeout          time.Duration // Gives up waiting for deliveries after this long
+	// IdleTimeout ends the test once every message was sent and nothing was
+	// delivered for this long, e.g. because the rate limit dropped messages
+	IdleTimeout time.Duration
+	// DatabaseType and DatabaseDSN select a scratch database to write to. An
+	// empty DatabaseType uses a temporary SQLite file that is removed afterwards.
+	DatabaseType string
+	DatabaseDSN  string
+}
+
+// Report is the outcome of a load test
+type Report struct {
+	Options       Options
+	Sent          int           // Guest messages sent
+	SendDuration  time.Duration // From the first to the last guest message
+	Expected      int           // Copies the recipients should get
+	Delivered     int           // Copies that reached a recipient
+	Duration      time.Duration // From the first guest message to the last message or delivery, whichever is later
+	GuestNotices  int           // Messages sent back to guests, e.g. slow mode notices
+	P50, P95, P99 time.Duration // Time from a guest message to a copy reaching a recipient
+	MaxLatency    time.Duration
+	Writes        map[string]int64 // Database write statements by table
+	Requests      map[string]int   // Bot API requests by method
+}
+
+// Run starts the application against a fake Bot API server, registers the
+// bots and recipients, lets the guests send their messages and reports how
+// they were delivered. cfg is the regular configuration; everything that
+// reaches outside the instance (webhook, API, Redis, proxy, failover) is
+// turned off, and the database is replaced by a scratch one.
+func Run(ctx context.Context, cfg *config.Config, log *zap.Logger, opts Options) (*Report, error) {
+	if opts.Guests <= 0 || opts.MessagesPerGuest <= 0 || opts.Bots <= 0 || opts.Recipients <= 0 {
+		return nil, errors.New("guests, messages, bots and recipients must be greater than 0")
+	}
+
+	api := newFakeBotAPI()
+	server := httptest.NewServer(api)
+	defer server.Close()
+	defer api.close() // Before closing the server, which waits for held getUpdates requests
+
+	testCfg := *cfg
+	testCfg.BotAPI.URL = server.URL
+	testCfg.ManagerBot = config.ManagerBotConfig{
+		Token:      fakeToken(managerBotTelegramID),
+		Superusers: []int64{managerUserID},
+	}
+	testCfg.Webhook.Enabled = false
+	testCfg.API.Enabled = false
+	testCfg.Redis.Enabled = false
+	testCfg.Proxy.Enabled = false
+	testCfg.Failover.Enabled = false
+	testCfg.Polling.Bots = nil
+
+	db, cleanup, err := openDatabase(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	writes := newWriteCounter()
+	if err := writes.register(db); err != nil {
+		return nil, fmt.Errorf("failed to count database writes: %w", err)
+	}
+
+	appCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	application, err := app.New(appCtx, &testCfg, log, app.WithDB(db))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize application: %w", err)
+	}
+
+	tokens, err := setUp(ctx, application, api, repository.NewRecipientRepository(db), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- application.Run() }()
+	stop := func() {
+		cancel()
+		select {
+		case err := <-runErr:
+			if err != nil {
+				log.Warn("Application stopped with an error", zap.Error(err))
+			}
+		case <-time.After(shutdownTimeout):
+			log.Warn("Application did not stop in time")
+		}
+	}
+	for _, token := range tokens {
+		if err := api.waitPolling(token, startupTimeout); err != nil {
+			stop()
+			return nil, err
+		}
+	}
+
+	log.Info("Load test started",
+		zap.Int("guests", opts.Guests),
+		zap.Int("messages_per_guest", opts.MessagesPerGuest),
+		zap.Int("bots", opts.Bots),
+		zap.Int("recipients", opts.Recipients))
+	writes.start()
+	start := time.Now()
+	sendDone := make(chan time.Time, 1)
+	go func() { sendDone <- sendMessages(appCtx, api, tokens, opts) }()
+
+	report := &Report{
+		Options:  opts,
+		Expected: opts.Guests * opts.MessagesPerGuest * opts.Recipients,
+	}
+	sendEnd := waitForDeliveries(ctx, api, report.Expected, sendDone, opts)
+	delivered, lastDelivery := api.progress()
+	report.Writes = writes.stop()
+	stop()
+
+	report.Sent = opts.Guests * opts.MessagesPerGuest
+	if !sendEnd.IsZero() {
+		report.SendDuration = sendEnd.Sub(start)
+	}
+	report.Delivered = delivered
+	report.Duration = report.SendDuration
+	if delivered > 0 && lastDelivery.Sub(start) > report.Duration {
+		report.Duration = lastDelivery.Sub(start)
+	}
+	percentiles, maxLatency := api.latencyPercentiles(50, 95, 99)
+	report.P50, report.P95, report.P99, report.MaxLatency = percentiles[0], percentiles[1], percentiles[2], maxLatency
+	api.mu.Lock()
+	report.GuestNotices = api.guestNotices
+	report.Requests = api.requests
+	api.mu.Unlock()
+	return report, ctx.Err()
+}
+
+// openDatabase connects to the scratch database of opts, or creates a
+// temporary SQLite database. The returned function removes the temporary one.
+func openDatabase(opts Options) (db *gorm.DB, cleanup func(), err error) {
+	dbCfg := config.DatabaseConfig{Type: opts.DatabaseType, DSN: opts.DatabaseDSN}
+	cleanup = func() {}
+	if dbCfg.Type == "" {
+		dir, err := os.MkdirTemp("", "forwarder-loadtest-")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create temporary database: %w", err)
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+		dbCfg = config.DatabaseConfig{
+			Type: "sqlite",
+			DSN:  filepath.Join(dir, "loadtest.db") + "?_busy_timeout=5000",
+		}
+	}
+	db, err = database.Connect(dbCfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	// Lookups that find nothing are routine and would drown the report
+	db.Logger = gormlogger.Default.LogMode(gormlogger.Silent)
+	return db, cleanup, nil
+}
+
+// setUp registers the bots for the synthetic manager and gives each of them
+// its recipients. It returns the bots' tokens.
+func setUp(ctx context.Context, application *app.App, api *fakeBotAPI, recipientRepo repository.RecipientRepository, opts Options) ([]string, error) {
+	// The manager is added as a recipient of every bot it registers
+	api.addRecipient(managerUserID)
+	nextRecipient := firstRecipientChatID
+
+	tokens := make([]string, 0, opts.Bots)
+	for i := 0; i < opts.Bots; i++ {
+		token := fakeToken(forwarderTelegramID + int64(i))
+		bot, err := application.RegisterBot(ctx, token, managerUserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to register bot %d: %w", i+1, err)
+		}
+		for j := 1; j < opts.Recipients; j++ {
+			recipient := &models.Recipient{
+				BotID:         bot.ID,
+				ChatID:        nextRecipient,
+				RecipientType: models.RecipientTypeUser,
+			}
+			if err := recipientRepo.Create(recipient); err != nil {
+				return nil, fmt.Errorf("failed to add recipient: %w", err)
+			}
+			api.addRecipient(nextRecipient)
+			nextRecipient++
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// sendMessages lets every guest send its messages, spread over the bots, and
+// returns when the last one was sent
+func sendMessages(ctx context.Context, api *fakeBotAPI, tokens []string, opts Options) time.Time {
+	var wg sync.WaitGroup
+	for g := 0; g < opts.Guests; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token := tokens[g%len(tokens)]
+			guestID := firstGuestUserID + int64(g)
+			// Guests start at random points of the first interval, like real ones
+			wait := time.Duration(0)
+			if opts.Interval > 0 {
+				wait = rand.N(opts.Interval)
+			}
+			for m := 1; m <= opts.MessagesPerGuest; m++ {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+				api.send(token, guestID, int64(m))
+				wait = opts.Interval
+			}
+		}()
+	}
+	wg.Wait()
+	return time.Now()
+}
+
+// waitForDeliveries waits until every copy was delivered, nothing was
+// delivered for the idle timeout after the last message was sent, or the
+// timeout passes. It returns when the last message was sent, if it was.
+func waitForDeliveries(ctx context.Context, api *fakeBotAPI, expected int, sendDone <-chan time.Time, opts Options) time.Time {
+	timeout := time.NewTimer(opts.Timeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(progressCheckInterval)
+	defer ticker.Stop()
+
+	var sendEnd time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return sendEnd
+		case <-timeout.C:
+			return sendEnd
+		case sendEnd = <-sendDone:
+		case <-ticker.C:
+		}
+		delivered, lastDelivery := api.progress()
+		if delivered >= expected {
+			return sendEnd
+		}
+		if !sendEnd.IsZero() && opts.IdleTimeout > 0 {
+			idleSince := sendEnd
+			if lastDelivery.After(idleSince) {
+				idleSince = lastDelivery
+			}
+			if time.Since(idleSince) >= opts.IdleTimeout {
+				return sendEnd
+			}
+		}
+	}
+}
+
+// fakeToken returns a well-formed bot token for a bot ID
+func fakeToken(botID int64) string {
+	return fmt.Sprintf("%d:%s", botID, strings.Repeat("L", 35))
+}
+
+// Print writes the report in a human-readable form
+func (r *Report) Print(w io.Writer) {
+	opts := r.Options
+	fmt.Fprintf(w, "Load test: %d guests x %d messages, every %s, over %d bot(s) with %d recipient(s) each\n\n",
+		opts.Guests, opts.MessagesPerGuest, opts.Interval, opts.Bots, opts.Recipients)
+
+	fmt.Fprintf(w, "Messages sent:      %d in %s (%s msg/s)\n", r.Sent, round(r.SendDuration), rate(int64(r.Sent), r.SendDuration))
+	fmt.Fprintf(w, "Copies delivered:   %d of %d in %s (%s msg/s)\n", r.Delivered, r.Expected, round(r.Duration), rate(int64(r.Delivered), r.Duration))
+	if r.Delivered < r.Expected {
+		fmt.Fprintf(w, "                    %d not delivered, e.g. held back by rate limits or anti-flood\n", r.Expected-r.Delivered)
+	}
+	fmt.Fprintf(w, "Notices to guests:  %d\n", r.GuestNotices)
+	fmt.Fprintf(w, "Delivery latency:   p50 %s, p95 %s, p99 %s, max %s\n\n", round(r.P50), round(r.P95), round(r.P99), round(r.MaxLatency))
+
+	var total int64
+	for _, count := range r.Writes {
+		total += count
+	}
+	fmt.Fprintf(w, "Database writes:    %d (%s/s)\n", total, rate(total, r.Duration))
+	for _, table := range sortedByCount(r.Writes) {
+		fmt.Fprintf(w, "  %-30s %8d (%s/s)\n", table, r.Writes[table], rate(r.Writes[table], r.Duration))
+	}
+
+	requests := make(map[string]int64, len(r.Requests))
+	var totalRequests int64
+	for method, count := range r.Requests {
+		if method == "getUpdates" {
+			continue // Long polling, not load
+		}
+		requests[method] = int64(count)
+		totalRequests += int64(count)
+	}
+	fmt.Fprintf(w, "\nBot API requests:   %d (%s/s), not counting getUpdates\n", totalRequests, rate(totalRequests, r.Duration))
+	for _, method := range sortedByCount(requests) {
+		fmt.Fprintf(w, "  %-30s %8d\n", method, requests[method])
+	}
+}
+
+// sortedByCount returns the keys of counts, highest count first
+func sortedByCount(counts map[string]int64) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func rate(count int64, d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", float64(count)/d.Seconds())
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	}
+	return d
+}
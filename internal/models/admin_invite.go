@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AdminInviteStatus string
+
+const (
+	AdminInviteStatusPending  AdminInviteStatus = "pending"  // Link not opened yet
+	AdminInviteStatusClaimed  AdminInviteStatus = "claimed"  // Opened, waiting for manager confirmation
+	AdminInviteStatusAccepted AdminInviteStatus = "accepted" // Confirmed, user is now admin
+	AdminInviteStatusRejected AdminInviteStatus = "rejected"
+)
+
+// AdminInvite is a one-time deep link that lets a user request admin rights
+// on a ForwarderBot without the manager knowing their numeric ID
+type AdminInvite struct {
+	ID                      uuid.UUID         `gorm:"type:char(36);primary_key"`
+	BotID                   uuid.UUID         `gorm:"type:char(36);not null;index"`
+	Bot                     ForwarderBot      `gorm:"foreignKey:BotID"`
+	Token                   string            `gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedByUserID         uuid.UUID         `gorm:"type:char(36);not null"`
+	Status                  AdminInviteStatus `gorm:"type:varchar(20);not null;default:'pending';index"`
+	ClaimedByTelegramUserID *int64
+	ClaimedByUsername       *string `gorm:"type:varchar(255)"`
+	ExpiresAt               time.Time
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+	DeletedAt               gorm.DeletedAt `gorm:"index"`
+}
+
+func (ai *AdminInvite) BeforeCreate(tx *gorm.DB) error {
+	if ai.ID == uuid.Nil {
+		ai.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired reports whether the invite can no longer be claimed
+func (ai *AdminInvite) IsExpired() bool {
+	return time.Now().After(ai.ExpiresAt)
+}
@@ -10,14 +10,41 @@ import (
 type AuditLogAction string
 
 const (
-	AuditLogActionAddBot       AuditLogAction = "add_bot"
-	AuditLogActionDeleteBot    AuditLogAction = "delete_bot"
-	AuditLogActionBan          AuditLogAction = "ban"
-	AuditLogActionUnban        AuditLogAction = "unban"
-	AuditLogActionAddAdmin     AuditLogAction = "add_admin"
-	AuditLogActionDelAdmin     AuditLogAction = "del_admin"
-	AuditLogActionAddRecipient AuditLogAction = "add_recipient"
-	AuditLogActionDelRecipient AuditLogAction = "del_recipient"
+	AuditLogActionAddBot            AuditLogAction = "add_bot"
+	AuditLogActionDeleteBot         AuditLogAction = "delete_bot"
+	AuditLogActionBan               AuditLogAction = "ban"
+	AuditLogActionUnban             AuditLogAction = "unban"
+	AuditLogActionAddAdmin          AuditLogAction = "add_admin"
+	AuditLogActionDelAdmin          AuditLogAction = "del_admin"
+	AuditLogActionAddRecipient      AuditLogAction = "add_recipient"
+	AuditLogActionDelRecipient      AuditLogAction = "del_recipient"
+	AuditLogActionSuspend           AuditLogAction = "suspend_manager"
+	AuditLogActionUnsuspend         AuditLogAction = "unsuspend_manager"
+	AuditLogActionReport            AuditLogAction = "report"
+	AuditLogActionCloseReport       AuditLogAction = "close_report"
+	AuditLogActionSetFallback       AuditLogAction = "set_fallback"
+	AuditLogActionSetQuota          AuditLogAction = "set_quota"
+	AuditLogActionSetPaywall        AuditLogAction = "set_paywall"
+	AuditLogActionRefund            AuditLogAction = "refund"
+	AuditLogActionSetTier           AuditLogAction = "set_tier"
+	AuditLogActionRecipientMembers  AuditLogAction = "recipient_members"
+	AuditLogActionMigrateRecipient  AuditLogAction = "migrate_recipient"
+	AuditLogActionAutoLeave         AuditLogAction = "auto_leave"
+	AuditLogActionUpdateSetting     AuditLogAction = "update_setting"
+	AuditLogActionDeleteMessage     AuditLogAction = "delete_message"
+	AuditLogActionSetToken          AuditLogAction = "set_token"
+	AuditLogActionAddSuperuser      AuditLogAction = "add_superuser"
+	AuditLogActionDelSuperuser      AuditLogAction = "del_superuser"
+	AuditLogActionLockdown          AuditLogAction = "lockdown"
+	AuditLogActionLiftLockdown      AuditLogAction = "lift_lockdown"
+	AuditLogActionClearRetryQueue   AuditLogAction = "clear_retry_queue"
+	AuditLogActionAccessDenied      AuditLogAction = "access_denied"
+	AuditLogActionBroadcast         AuditLogAction = "broadcast"
+	AuditLogActionReplay            AuditLogAction = "replay"
+	AuditLogActionRequeueDeadLetter AuditLogAction = "requeue_dead_letter"
+	AuditLogActionDiscardDeadLetter AuditLogAction = "discard_dead_letter"
+	AuditLogActionAutoMute          AuditLogAction = "auto_mute"
+	AuditLogActionUpdateRecipient   AuditLogAction = "update_recipient"
 )
 
 type AuditLog struct {
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AutoReply is a canned reply a ForwarderBot sends to guests whose message
+// contains one of the rule's keywords
+type AutoReply struct {
+	ID       uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID    uuid.UUID    `gorm:"type:char(36);not null;index"`
+	Bot      ForwarderBot `gorm:"foreignKey:BotID"`
+	Keywords string       `gorm:"type:text;not null"` // Comma-separated, see utils.ParseKeywordList
+	Reply    string       `gorm:"type:text;not null"`
+	// SuppressForward answers matching messages without forwarding them to recipients
+	SuppressForward bool `gorm:"not null;default:false"`
+	// HitCount is how many guest messages the rule answered
+	HitCount  int64 `gorm:"not null;default:0"`
+	LastHitAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (a *AutoReply) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
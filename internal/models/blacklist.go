@@ -36,6 +36,20 @@ type Blacklist struct {
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	// DecidedBy is the user who approved or rejected the request; nil while
+	// pending and for requests approved automatically
+	DecidedBy *uuid.UUID `gorm:"type:char(36)"`
+	// ExpiresAt ends a temporary ban, such as an anti-flood mute; nil for
+	// bans that last until an unban
+	ExpiresAt *time.Time `gorm:"index"`
+	// Reason is the free-text reason given with the request; empty when none
+	// was given
+	Reason string `gorm:"type:text"`
+}
+
+// Expired reports whether the request is a temporary ban that has run out by now
+func (b *Blacklist) Expired(now time.Time) bool {
+	return b.RequestType == BlacklistRequestTypeBan && b.ExpiresAt != nil && !now.Before(*b.ExpiresAt)
 }
 
 func (b *Blacklist) BeforeCreate(tx *gorm.DB) error {
@@ -26,7 +26,7 @@ func (ba *BotAdmin) BeforeCreate(tx *gorm.DB) error {
 	// Check uniqueness
 	var count int64
 	tx.Model(&BotAdmin{}).
-		Where("bot_id = ? AND admin_user_id = ? AND deleted_at IS NULL", ba.BotID, ba.AdminUserID).
+		Where("bot_id = ? AND admin_user_id = ?", ba.BotID, ba.AdminUserID).
 		Count(&count)
 	if count > 0 {
 		return gorm.ErrDuplicatedKey
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BotDataKey is the key a bot's stored message content is encrypted with. It
+// is stored wrapped with a key derived from the master encryption key, so the
+// database alone does not reveal the content.
+type BotDataKey struct {
+	BotID      uuid.UUID `gorm:"type:char(36);primary_key"`
+	WrappedKey string    `gorm:"type:text;not null"`
+	CreatedAt  time.Time
+}
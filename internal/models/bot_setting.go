@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BotSetting is one per-bot option stored as a key-value pair, so new options
+// do not need a column on ForwarderBot. The known keys and their defaults are
+// defined in the settings service.
+type BotSetting struct {
+	ID        uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID     uuid.UUID    `gorm:"type:char(36);not null;uniqueIndex:idx_bot_setting_name"`
+	Bot       ForwarderBot `gorm:"foreignKey:BotID"`
+	Name      string       `gorm:"type:varchar(64);not null;uniqueIndex:idx_bot_setting_name"`
+	Value     string       `gorm:"type:text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (s *BotSetting) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BotUsageMonthFormat is the layout of BotUsage.Month
+const BotUsageMonthFormat = "2006-01"
+
+// BotUsage counts the messages a ForwarderBot forwarded in one calendar month,
+// for ForwarderBot.MonthlyQuota enforcement
+type BotUsage struct {
+	ID           uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID        uuid.UUID    `gorm:"type:char(36);not null;uniqueIndex:idx_bot_usage_month"`
+	Bot          ForwarderBot `gorm:"foreignKey:BotID"`
+	Month        string       `gorm:"type:varchar(7);not null;uniqueIndex:idx_bot_usage_month"` // BotUsageMonthFormat
+	MessageCount int64        `gorm:"not null;default:0"`
+	WarnedAt     *time.Time   // When the manager was warned about 80% usage
+	ExceededAt   *time.Time   // When the quota was reached and forwarding paused
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (u *BotUsage) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeadLetter is a guest message that could not be delivered to a recipient
+// and will not be retried on its own: it failed for a permanent reason, or the
+// retry queue gave up on it. Managers inspect it and requeue or discard it.
+type DeadLetter struct {
+	ID              uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID           uuid.UUID    `gorm:"type:char(36);not null;index;uniqueIndex:idx_dead_letter_message"`
+	Bot             ForwarderBot `gorm:"foreignKey:BotID"`
+	RecipientID     uuid.UUID    `gorm:"type:char(36);not null;uniqueIndex:idx_dead_letter_message"`
+	RecipientChatID int64        `gorm:"not null;default:0"`
+	GuestChatID     int64        `gorm:"not null;uniqueIndex:idx_dead_letter_message"`
+	GuestMessageID  int64        `gorm:"not null;uniqueIndex:idx_dead_letter_message"`
+	// Message is the guest's message as JSON, so it can be forwarded or copied as received
+	Message       string `gorm:"type:text;not null"`
+	Reason        string `gorm:"type:varchar(50);not null;default:''"` // message.FailureReason of the last attempt
+	LastError     string `gorm:"type:text"`
+	Attempts      int    `gorm:"not null;default:0"` // Attempts of the first delivery, including retries
+	QueueAttempts int    `gorm:"not null;default:0"` // Retry queue attempts; 0 if it was never queued
+	CreatedAt     time.Time
+}
+
+func (d *DeadLetter) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FilterAction is what happens to a guest message that matches a filter rule
+type FilterAction string
+
+const (
+	// FilterActionDrop discards the message and tells the guest it was blocked
+	FilterActionDrop FilterAction = "drop"
+	// FilterActionQuarantine holds the message until staff deliver or discard it
+	FilterActionQuarantine FilterAction = "quarantine"
+)
+
+// FilterRule is a keyword or regular expression a ForwarderBot's ad filter
+// checks guest messages against
+type FilterRule struct {
+	ID      uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID   uuid.UUID    `gorm:"type:char(36);not null;index"`
+	Bot     ForwarderBot `gorm:"foreignKey:BotID"`
+	Pattern string       `gorm:"type:varchar(255);not null"`
+	// IsRegex matches Pattern as a regular expression instead of a case-insensitive keyword
+	IsRegex bool         `gorm:"not null;default:false"`
+	Action  FilterAction `gorm:"type:varchar(20);not null;default:'drop'"`
+	// HitCount is how many guest messages the rule caught
+	HitCount  int64 `gorm:"not null;default:0"`
+	LastHitAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (f *FilterRule) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
@@ -1,8 +1,12 @@
 package models
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
+	"go-telegram-forwarder-bot/internal/utils"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -13,14 +17,108 @@ type ForwarderBot struct {
 	Name      string    `gorm:"type:varchar(255)"`
 	ManagerID uuid.UUID `gorm:"type:char(36);not null;index"`
 	Manager   User      `gorm:"foreignKey:ManagerID"`
+	// TelegramBotID is the bot's Telegram user ID; 0 for bots registered before it was stored
+	TelegramBotID int64 `gorm:"not null;default:0;index"`
+	// TermsText is the ToS/privacy notice guests must accept before forwarding; empty disables the gate
+	TermsText string `gorm:"type:text"`
+	// TermsVersion is bumped whenever TermsText changes so guests accept again
+	TermsVersion int `gorm:"not null;default:0"`
+	// GuestRateLimit is the sustained guest messages per second; 0 uses the global rate_limit.guest_message
+	GuestRateLimit int `gorm:"not null;default:0"`
+	// GuestRateBurst is how many messages a guest may send at once; 0 means the same as the rate
+	GuestRateBurst int `gorm:"not null;default:0"`
+	// APIRateLimit is the bot's own Telegram API requests per second; 0 shares the global rate_limit.telegram_api
+	APIRateLimit int `gorm:"not null;default:0"`
+	// MonthlyQuota is the most messages the bot may forward per calendar month, set by superusers; 0 is unlimited
+	MonthlyQuota int64 `gorm:"not null;default:0"`
+	// PaywallMode is PaywallModeOff, PaywallModeRequired or PaywallModePriority
+	PaywallMode PaywallMode `gorm:"type:varchar(20);not null;default:''"`
+	// PaywallPrice is the price in Telegram Stars of one purchase of PaywallCredits messages
+	PaywallPrice int `gorm:"not null;default:0"`
+	// PaywallCredits is how many messages one purchase pays for
+	PaywallCredits int `gorm:"not null;default:0"`
+	// WelcomeText is sent to guests on /start and with their first message; empty
+	// disables the welcome message. It is Markdown and may use placeholders such as {first_name}.
+	WelcomeText string `gorm:"type:text"`
+	// WelcomeTextB is a second welcome variant; when set, new guests get either variant at random
+	WelcomeTextB string `gorm:"type:text"`
+	// AlertKeywords is a comma-separated list of words that make a guest message ping the manager
+	AlertKeywords string `gorm:"type:text"`
+	// AllowGroupGuests treats members of groups that are not recipients as guests; by default only private chats are
+	AllowGroupGuests bool `gorm:"not null;default:false"`
+	// AutoLeaveGroups makes the bot leave groups it is added to by anyone but the manager or admins
+	AutoLeaveGroups bool `gorm:"not null;default:true"`
+	// PrivacyMode copies guest messages to recipients instead of forwarding them, so the guest's name is not shown
+	PrivacyMode bool `gorm:"not null;default:false"`
+	// PrivacyHeader is the line shown above copied guest messages; empty uses DefaultPrivacyHeader
+	PrivacyHeader string `gorm:"type:varchar(255);not null;default:''"`
+	// GuestTopics gives each guest their own topic in recipient groups that have topics enabled
+	GuestTopics bool `gorm:"not null;default:false"`
+	// OfficeHours is when the team replies to guests, e.g. "09:00-18:00 Mon-Fri"; empty is always open
+	OfficeHours string `gorm:"type:varchar(255);not null;default:''"`
+	// AwayMessage is sent to guests who write outside office hours; empty uses DefaultAwayMessage
+	AwayMessage string `gorm:"type:text"`
+	// DryRun processes and logs guest messages without delivering them to recipients,
+	// e.g. to try filters and routing rules on live traffic
+	DryRun bool `gorm:"not null;default:false"`
+	// Timezone is the IANA timezone times are shown in by this bot; empty uses the manager's timezone
+	Timezone  string `gorm:"type:varchar(64);not null;default:''"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
+type PaywallMode string
+
+const (
+	// PaywallModeOff forwards guest messages for free
+	PaywallModeOff PaywallMode = ""
+	// PaywallModeRequired only forwards messages the guest paid for
+	PaywallModeRequired PaywallMode = "required"
+	// PaywallModePriority forwards every message and marks paid ones as priority
+	PaywallModePriority PaywallMode = "priority"
+)
+
+// DefaultPrivacyHeader is shown above guest messages in privacy mode unless
+// the bot sets its own. {guest} is replaced with the guest's number.
+const DefaultPrivacyHeader = "From guest #{guest}"
+
+// DefaultAwayMessage is sent to guests outside office hours unless the bot sets
+// its own. {hours} is replaced with the office hours and {opens_at} with when
+// they next begin.
+const DefaultAwayMessage = "🌙 We are outside our office hours ({hours}). Your message was forwarded and we will reply after we open again at {opens_at}."
+
+// GuestHeader returns the header shown above a guest's messages in privacy mode
+func (b *ForwarderBot) GuestHeader(guest *Guest) string {
+	header := b.PrivacyHeader
+	if header == "" {
+		header = DefaultPrivacyHeader
+	}
+	return strings.ReplaceAll(header, "{guest}", strconv.Itoa(guest.Number()))
+}
+
 func (b *ForwarderBot) BeforeCreate(tx *gorm.DB) error {
 	if b.ID == uuid.Nil {
 		b.ID = uuid.New()
 	}
 	return nil
 }
+
+// TelegramID returns the bot's Telegram user ID, read from the token if it was
+// not stored. token is the decrypted token.
+func (b *ForwarderBot) TelegramID(token string) int64 {
+	if b.TelegramBotID != 0 {
+		return b.TelegramBotID
+	}
+	botID, _ := utils.ParseBotToken(token)
+	return botID
+}
+
+// Location returns the timezone times are shown in by this bot: its own, else
+// the manager's (requires Manager to be loaded), else the server's
+func (b *ForwarderBot) Location() *time.Location {
+	if b.Timezone != "" {
+		return utils.LocationOrLocal(b.Timezone)
+	}
+	return b.Manager.Location()
+}
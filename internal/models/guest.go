@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/binary"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,11 +10,29 @@ import (
 
 type Guest struct {
 	ID          uuid.UUID    `gorm:"type:char(36);primary_key"`
-	BotID       uuid.UUID    `gorm:"type:char(36);not null;index"`
+	BotID       uuid.UUID    `gorm:"type:char(36);not null;index;uniqueIndex:idx_guest_bot_user"`
 	Bot         ForwarderBot `gorm:"foreignKey:BotID"`
-	GuestUserID int64        `gorm:"not null"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	GuestUserID int64        `gorm:"not null;uniqueIndex:idx_guest_bot_user"`
+	// TermsAcceptedVersion is the ForwarderBot.TermsVersion the guest last accepted
+	TermsAcceptedVersion int `gorm:"not null;default:0"`
+	TermsAcceptedAt      *time.Time
+	// PaidCredits is how many paid messages the guest has left under the bot's paywall
+	PaidCredits int `gorm:"not null;default:0"`
+	// AlertKeyword is the alert keyword the conversation was tagged with; empty when not tagged
+	AlertKeyword string `gorm:"type:varchar(255);not null;default:''"`
+	AlertedAt    *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	// AwaitingReplySince is when the guest's oldest unanswered message arrived; nil once replied to
+	AwaitingReplySince *time.Time `gorm:"index"`
+	// LastRepliedAt and LastResponseSeconds describe the most recent end of a wait
+	LastRepliedAt       *time.Time
+	LastResponseSeconds int `gorm:"not null;default:0"`
+	// BlockedBotAt is when a message to the guest failed because they blocked
+	// the bot; nil once they write again or a message gets through
+	BlockedBotAt *time.Time
+	// Topics are the guest's forum topics in recipient groups, one per group
+	Topics []GuestTopic `gorm:"foreignKey:GuestID"`
 }
 
 func (g *Guest) BeforeCreate(tx *gorm.DB) error {
@@ -22,3 +41,15 @@ func (g *Guest) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// Number is a short number recipients can tell guests apart by when the bot
+// hides guest names. It is derived from the random ID, so it reveals nothing
+// about the guest, but two guests may occasionally share one.
+func (g *Guest) Number() int {
+	return int(binary.BigEndian.Uint32(g.ID[:4]) % 1000000)
+}
+
+// HasAcceptedTerms reports whether the guest accepted the given terms version
+func (g *Guest) HasAcceptedTerms(version int) bool {
+	return g.TermsAcceptedAt != nil && g.TermsAcceptedVersion >= version
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GuestTopic is the forum topic a guest's messages go to in a recipient group
+// with topics enabled, so staff get one thread per guest
+type GuestTopic struct {
+	ID              uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID           uuid.UUID    `gorm:"type:char(36);not null;index"`
+	Bot             ForwarderBot `gorm:"foreignKey:BotID"`
+	GuestID         uuid.UUID    `gorm:"type:char(36);not null;uniqueIndex:idx_guest_topic_chat"`
+	Guest           Guest        `gorm:"foreignKey:GuestID"`
+	RecipientChatID int64        `gorm:"not null;uniqueIndex:idx_guest_topic_chat;index:idx_guest_topic_thread"`
+	ThreadID        int64        `gorm:"not null;index:idx_guest_topic_thread"` // message_thread_id of the topic
+	CreatedAt       time.Time
+}
+
+func (t *GuestTopic) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InboundMessage records one guest message received by a bot, independent of
+// how many recipients it was copied to. MessageMapping keeps one row per copy,
+// so statistics count inbound messages here instead.
+type InboundMessage struct {
+	ID             uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID          uuid.UUID    `gorm:"type:char(36);not null;uniqueIndex:idx_inbound_guest_message;index:idx_inbound_bot_created"`
+	Bot            ForwarderBot `gorm:"foreignKey:BotID"`
+	GuestChatID    int64        `gorm:"not null;uniqueIndex:idx_inbound_guest_message"`
+	GuestMessageID int64        `gorm:"not null;uniqueIndex:idx_inbound_guest_message"`
+	RecipientCount int          `gorm:"not null;default:0"` // Recipients the message was sent to
+	DeliveredCount int          `gorm:"not null;default:0"` // Recipients that received a copy
+	CreatedAt      time.Time    `gorm:"index:idx_inbound_bot_created"`
+}
+
+func (m *InboundMessage) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -24,6 +24,9 @@ type MessageMapping struct {
 	RecipientMessageID int64            `gorm:"not null;index:idx_recipient_message"`
 	Direction          MessageDirection `gorm:"type:varchar(20);not null"`
 	CreatedAt          time.Time        `gorm:"index:idx_bot_created"`
+	// GuestUserID is who wrote an inbound guest message. It differs from
+	// GuestChatID when a group takes part as a guest; 0 on older mappings.
+	GuestUserID int64 `gorm:"not null;default:0"`
 }
 
 func (m *MessageMapping) BeforeCreate(tx *gorm.DB) error {
@@ -32,3 +35,13 @@ func (m *MessageMapping) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// GuestSenderID returns the Telegram user who wrote the guest message:
+// GuestUserID when recorded, otherwise GuestChatID, which is the guest's user
+// ID in a private chat
+func (m *MessageMapping) GuestSenderID() int64 {
+	if m.GuestUserID != 0 {
+		return m.GuestUserID
+	}
+	return m.GuestChatID
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPaid     PaymentStatus = "paid"
+	PaymentStatusRefunded PaymentStatus = "refunded"
+)
+
+// PaymentCurrencyStars is the currency code of Telegram Stars
+const PaymentCurrencyStars = "XTR"
+
+// Payment is a Telegram Stars payment a guest made to a ForwarderBot's paywall
+type Payment struct {
+	ID          uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID       uuid.UUID    `gorm:"type:char(36);not null;index"`
+	Bot         ForwarderBot `gorm:"foreignKey:BotID"`
+	GuestUserID int64        `gorm:"not null;index"`
+	// TelegramPaymentChargeID identifies the payment at Telegram and is needed for refunds
+	TelegramPaymentChargeID string        `gorm:"type:varchar(255);not null;uniqueIndex"`
+	Payload                 string        `gorm:"type:varchar(128);not null"`
+	Currency                string        `gorm:"type:varchar(10);not null"`
+	Amount                  int64         `gorm:"not null"`
+	Credits                 int           `gorm:"not null"` // Messages the payment bought
+	Status                  PaymentStatus `gorm:"type:varchar(20);not null;default:'paid';index"`
+	RefundedAt              *time.Time
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+}
+
+func (p *Payment) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PendingDelivery is a guest message that could not be delivered to a
+// recipient after all retries and is tried again later by the retry queue
+type PendingDelivery struct {
+	ID             uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID          uuid.UUID    `gorm:"type:char(36);not null;index;uniqueIndex:idx_pending_delivery_message"`
+	Bot            ForwarderBot `gorm:"foreignKey:BotID"`
+	RecipientID    uuid.UUID    `gorm:"type:char(36);not null;uniqueIndex:idx_pending_delivery_message"`
+	GuestChatID    int64        `gorm:"not null;uniqueIndex:idx_pending_delivery_message"`
+	GuestMessageID int64        `gorm:"not null;uniqueIndex:idx_pending_delivery_message"`
+	// Message is the guest's message as JSON, so it can be forwarded or copied as received
+	Message       string    `gorm:"type:text;not null"`
+	Attempts      int       `gorm:"not null;default:0"` // Queue attempts, not counting the retries before it was queued
+	LastError     string    `gorm:"type:text"`
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (p *PendingDelivery) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// QuarantinedMessage is a guest message held back by a quarantine filter rule
+// until staff deliver or discard it
+type QuarantinedMessage struct {
+	ID             uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID          uuid.UUID    `gorm:"type:char(36);not null;index"`
+	Bot            ForwarderBot `gorm:"foreignKey:BotID"`
+	GuestChatID    int64        `gorm:"not null"`
+	GuestMessageID int64        `gorm:"not null"`
+	// Pattern is the pattern of the rule that matched, kept if the rule is removed
+	Pattern string `gorm:"type:varchar(255);not null"`
+	// Message is the guest's message as JSON, so it can be forwarded or copied as received
+	Message   string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+}
+
+func (q *QuarantinedMessage) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	return nil
+}
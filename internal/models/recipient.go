@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,11 +22,40 @@ type Recipient struct {
 	Bot           ForwarderBot  `gorm:"foreignKey:BotID"`
 	RecipientType RecipientType `gorm:"type:varchar(20);not null"`
 	ChatID        int64         `gorm:"not null"`
+	IsFallback    bool          `gorm:"not null;default:false"` // Always receives forwards, even when other recipients are muted, quiet or failing
+	Title         *string       `gorm:"type:varchar(255)"`      // Chat title, or the user's full name for private chats
+	Username      *string       `gorm:"type:varchar(255)"`
+	Alias         *string       `gorm:"type:varchar(64)"` // Set by the manager, takes precedence over Title
+	InfoUpdatedAt *time.Time    // Last time Title and Username were fetched from Telegram
+	IsForum       bool          `gorm:"not null;default:false"` // The group has topics enabled, fetched with Title
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     gorm.DeletedAt `gorm:"index"`
 }
 
+// DisplayName returns the alias, title or @username of the recipient,
+// or an empty string if none are known
+func (r *Recipient) DisplayName() string {
+	if r.Alias != nil && *r.Alias != "" {
+		return *r.Alias
+	}
+	if r.Title != nil && *r.Title != "" {
+		return *r.Title
+	}
+	if r.Username != nil && *r.Username != "" {
+		return "@" + *r.Username
+	}
+	return ""
+}
+
+// Label returns the display name followed by the chat ID, or just the chat ID
+func (r *Recipient) Label() string {
+	if name := r.DisplayName(); name != "" {
+		return fmt.Sprintf("%s (%d)", name, r.ChatID)
+	}
+	return fmt.Sprintf("%d", r.ChatID)
+}
+
 func (r *Recipient) BeforeCreate(tx *gorm.DB) error {
 	if r.ID == uuid.Nil {
 		r.ID = uuid.New()
@@ -34,7 +64,7 @@ func (r *Recipient) BeforeCreate(tx *gorm.DB) error {
 	// Check uniqueness
 	var count int64
 	tx.Model(&Recipient{}).
-		Where("bot_id = ? AND chat_id = ? AND deleted_at IS NULL", r.BotID, r.ChatID).
+		Where("bot_id = ? AND chat_id = ?", r.BotID, r.ChatID).
 		Count(&count)
 	if count > 0 {
 		return errors.New("recipient already exists")
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusResolved  ReportStatus = "resolved"
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+// Report is an abuse report filed by a guest against a ForwarderBot.
+// Reports are reviewed by superusers rather than by the bot's own manager.
+type Report struct {
+	ID               uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID            uuid.UUID    `gorm:"type:char(36);not null;index"`
+	Bot              ForwarderBot `gorm:"foreignKey:BotID"`
+	ReporterUserID   int64        `gorm:"not null;index"`
+	ReporterUsername *string      `gorm:"type:varchar(255)"`
+	Reason           string       `gorm:"type:text"`
+	Status           ReportStatus `gorm:"type:varchar(20);not null;default:'open';index"`
+	ResolvedByUserID *uuid.UUID   `gorm:"type:char(36)"`
+	ResolvedBy       *User        `gorm:"foreignKey:ResolvedByUserID"`
+	ResolvedAt       *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+}
+
+func (r *Report) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
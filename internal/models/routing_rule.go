@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoutingRuleKind is what a routing rule checks guest messages for
+type RoutingRuleKind string
+
+const (
+	// RoutingRuleKeyword matches messages containing Value, ignoring case
+	RoutingRuleKeyword RoutingRuleKind = "keyword"
+	// RoutingRuleMedia matches messages of one type, such as photo or voice
+	RoutingRuleMedia RoutingRuleKind = "media"
+	// RoutingRuleLanguage matches guests whose Telegram app uses a language,
+	// such as en, which includes regional variants like en-GB
+	RoutingRuleLanguage RoutingRuleKind = "language"
+)
+
+// RoutingRule limits which guest messages a recipient gets. A recipient
+// without rules gets every message; one with rules only gets messages
+// matching at least one of them.
+type RoutingRule struct {
+	ID          uuid.UUID       `gorm:"type:char(36);primary_key"`
+	BotID       uuid.UUID       `gorm:"type:char(36);not null;index"`
+	Bot         ForwarderBot    `gorm:"foreignKey:BotID"`
+	RecipientID uuid.UUID       `gorm:"type:char(36);not null;index"`
+	Recipient   Recipient       `gorm:"foreignKey:RecipientID"`
+	Kind        RoutingRuleKind `gorm:"type:varchar(20);not null"`
+	Value       string          `gorm:"type:varchar(255);not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (r *RoutingRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
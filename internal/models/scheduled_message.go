@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScheduledMessage is a message a guest asked the bot to hold with /later and
+// deliver to the recipients at DueAt
+type ScheduledMessage struct {
+	ID             uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID          uuid.UUID    `gorm:"type:char(36);not null;index"`
+	Bot            ForwarderBot `gorm:"foreignKey:BotID"`
+	GuestChatID    int64        `gorm:"not null;index"`
+	GuestMessageID int64        `gorm:"not null"` // The /later command, so replies to the delivered message quote it
+	// Message is the held message as JSON: the guest's command with the text
+	// replaced by the part to deliver
+	Message   string    `gorm:"type:text;not null"`
+	Attempts  int       `gorm:"not null;default:0"` // Failed delivery runs
+	DueAt     time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+func (m *ScheduledMessage) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StatsDailyDayFormat is the layout of StatsDaily.Day
+const StatsDailyDayFormat = "2006-01-02"
+
+// StatsDaily is a per-bot rollup of one calendar day, written by the stats_daily worker
+type StatsDaily struct {
+	ID            uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID         uuid.UUID    `gorm:"type:char(36);not null;uniqueIndex:idx_stats_daily_bot_day"`
+	Bot           ForwarderBot `gorm:"foreignKey:BotID"`
+	Day           string       `gorm:"type:varchar(10);not null;uniqueIndex:idx_stats_daily_bot_day"` // StatsDailyDayFormat
+	InboundCount  int64        `gorm:"not null;default:0"`
+	OutboundCount int64        `gorm:"not null;default:0"`
+	NewGuestCount int64        `gorm:"not null;default:0"`
+	CopyCount     int64        `gorm:"not null;default:0"` // Recipient copies attempted for inbound messages
+	FailureCount  int64        `gorm:"not null;default:0"` // Recipient copies that were not delivered
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (s *StatsDaily) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Superuser grants a Telegram user superuser rights. The superusers from the
+// config are added on every start; others are added with /addsuper.
+type Superuser struct {
+	TelegramUserID int64      `gorm:"primary_key;autoIncrement:false"`
+	AddedByID      *uuid.UUID `gorm:"type:char(36)"` // nil for superusers from the config
+	CreatedAt      time.Time
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"go-telegram-forwarder-bot/internal/utils"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -11,14 +13,41 @@ type User struct {
 	ID             uuid.UUID `gorm:"type:char(36);primary_key"`
 	TelegramUserID int64     `gorm:"uniqueIndex;not null"`
 	Username       *string   `gorm:"type:varchar(255)"`
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	SuspendedAt    *time.Time
+	// Tier is the subscription tier set by superusers; it decides which features the user's bots get
+	Tier UserTier `gorm:"type:varchar(20);not null;default:'free'"`
+	// Timezone is the IANA timezone times are shown in for this user; empty uses the server's
+	Timezone string `gorm:"type:varchar(64);not null;default:''"`
+	// Language is the language the bots use with this user, set with /language; empty follows the Telegram app's language
+	Language  string `gorm:"type:varchar(16);not null;default:''"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
+type UserTier string
+
+const (
+	UserTierFree UserTier = "free"
+	UserTierPro  UserTier = "pro"
+)
+
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	if u.Tier == "" {
+		u.Tier = UserTierFree
+	}
 	return nil
 }
+
+// IsSuspended reports whether the user has been suspended by a superuser
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+// Location returns the user's timezone, or the server's if none is set
+func (u *User) Location() *time.Location {
+	return utils.LocationOrLocal(u.Timezone)
+}
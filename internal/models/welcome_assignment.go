@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	WelcomeVariantA = "A"
+	WelcomeVariantB = "B"
+)
+
+// WelcomeAssignment records which welcome message variant a new guest was shown,
+// and whether they went on to send a message, for A/B testing of welcome texts
+type WelcomeAssignment struct {
+	ID          uuid.UUID    `gorm:"type:char(36);primary_key"`
+	BotID       uuid.UUID    `gorm:"type:char(36);not null;uniqueIndex:idx_welcome_assignment_guest"`
+	Bot         ForwarderBot `gorm:"foreignKey:BotID"`
+	GuestUserID int64        `gorm:"not null;uniqueIndex:idx_welcome_assignment_guest"`
+	Variant     string       `gorm:"type:varchar(1);not null"` // WelcomeVariantA or WelcomeVariantB
+	ConvertedAt *time.Time   // When the guest first sent a message that was forwarded
+	CreatedAt   time.Time
+}
+
+func (w *WelcomeAssignment) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
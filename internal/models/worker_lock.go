@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// WorkerLock is a lease row used to coordinate periodic workers between
+// instances when Redis is not available.
+type WorkerLock struct {
+	Name      string    `gorm:"type:varchar(191);primary_key"`
+	Owner     string    `gorm:"type:varchar(64);not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+type AdminInviteRepository interface {
+	Create(invite *models.AdminInvite) error
+	GetByID(id uuid.UUID) (*models.AdminInvite, error)
+	GetByToken(token string) (*models.AdminInvite, error)
+	// Claim records the user who opened a pending invite.
+	// Returns false if the invite was already used.
+	Claim(id uuid.UUID, telegramUserID int64, username *string) (bool, error)
+	// Resolve moves a claimed invite to accepted or rejected.
+	// Returns false if the invite was no longer waiting for confirmation.
+	Resolve(id uuid.UUID, status models.AdminInviteStatus) (bool, error)
+	// Accept moves a claimed invite to accepted and makes the user an admin
+	// of the bot in one transaction. It returns the new admin, or nil if the
+	// user already was one, and false if the invite was no longer waiting
+	// for confirmation.
+	Accept(id uuid.UUID, botID uuid.UUID, adminUserID uuid.UUID) (*models.BotAdmin, bool, error)
+	// Unclaim returns a claimed invite to pending so the link can be opened again
+	Unclaim(id uuid.UUID) error
+}
+
+type adminInviteRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminInviteRepository(db *gorm.DB) AdminInviteRepository {
+	return &adminInviteRepository{db: db}
+}
+
+func (r *adminInviteRepository) Create(invite *models.AdminInvite) error {
+	return r.db.Create(invite).Error
+}
+
+func (r *adminInviteRepository) GetByID(id uuid.UUID) (*models.AdminInvite, error) {
+	var invite models.AdminInvite
+	if err := r.db.First(&invite, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *adminInviteRepository) GetByToken(token string) (*models.AdminInvite, error) {
+	var invite models.AdminInvite
+	if err := r.db.Where("token = ?", token).First(&invite).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *adminInviteRepository) Claim(id uuid.UUID, telegramUserID int64, username *string) (bool, error) {
+	result := r.db.Model(&models.AdminInvite{}).
+		Where("id = ? AND status = ?", id, models.AdminInviteStatusPending).
+		Updates(map[string]interface{}{
+			"status":                      models.AdminInviteStatusClaimed,
+			"claimed_by_telegram_user_id": telegramUserID,
+			"claimed_by_username":         username,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *adminInviteRepository) Resolve(id uuid.UUID, status models.AdminInviteStatus) (bool, error) {
+	result := r.db.Model(&models.AdminInvite{}).
+		Where("id = ? AND status = ?", id, models.AdminInviteStatusClaimed).
+		Update("status", status)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *adminInviteRepository) Accept(id uuid.UUID, botID uuid.UUID, adminUserID uuid.UUID) (*models.BotAdmin, bool, error) {
+	var admin *models.BotAdmin
+	accepted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.AdminInvite{}).
+			Where("id = ? AND status = ?", id, models.AdminInviteStatusClaimed).
+			Update("status", models.AdminInviteStatusAccepted)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		accepted = true
+
+		var count int64
+		if err := tx.Model(&models.BotAdmin{}).
+			Where("bot_id = ? AND admin_user_id = ?", botID, adminUserID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		admin = &models.BotAdmin{BotID: botID, AdminUserID: adminUserID}
+		return tx.Create(admin).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return admin, accepted, nil
+}
+
+func (r *adminInviteRepository) Unclaim(id uuid.UUID) error {
+	return r.db.Model(&models.AdminInvite{}).
+		Where("id = ? AND status = ?", id, models.AdminInviteStatusClaimed).
+		Updates(map[string]interface{}{
+			"status":                      models.AdminInviteStatusPending,
+			"claimed_by_telegram_user_id": nil,
+			"claimed_by_username":         nil,
+		}).Error
+}
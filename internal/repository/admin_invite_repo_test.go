@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestAdminInviteAccept(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewAdminInviteRepository(db)
+	admins := NewBotAdminRepository(db)
+	botID, userID := uuid.New(), uuid.New()
+
+	invite := &models.AdminInvite{BotID: botID, Token: "token", CreatedByUserID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.Create(invite); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Only claimed invites can be accepted
+	if _, accepted, err := repo.Accept(invite.ID, botID, userID); err != nil || accepted {
+		t.Fatalf("Accept of a pending invite = %v, %v; want not accepted", accepted, err)
+	}
+
+	if claimed, err := repo.Claim(invite.ID, 42, nil); err != nil || !claimed {
+		t.Fatalf("Claim = %v, %v; want claimed", claimed, err)
+	}
+	// A failed confirmation returns the invite to pending so it can be claimed again
+	if err := repo.Unclaim(invite.ID); err != nil {
+		t.Fatalf("Unclaim: %v", err)
+	}
+	if got, _ := repo.GetByID(invite.ID); got.Status != models.AdminInviteStatusPending || got.ClaimedByTelegramUserID != nil {
+		t.Fatalf("invite after Unclaim = %s claimed by %v, want pending and unclaimed", got.Status, got.ClaimedByTelegramUserID)
+	}
+	if claimed, err := repo.Claim(invite.ID, 42, nil); err != nil || !claimed {
+		t.Fatalf("Claim again = %v, %v; want claimed", claimed, err)
+	}
+
+	admin, accepted, err := repo.Accept(invite.ID, botID, userID)
+	if err != nil || !accepted || admin == nil {
+		t.Fatalf("Accept = %v, %v, %v; want a new admin", admin, accepted, err)
+	}
+	if isAdmin, _ := admins.IsAdmin(botID, userID); !isAdmin {
+		t.Fatal("user is not an admin after Accept")
+	}
+	if _, accepted, err := repo.Accept(invite.ID, botID, userID); err != nil || accepted {
+		t.Fatalf("Accept again = %v, %v; want not accepted", accepted, err)
+	}
+}
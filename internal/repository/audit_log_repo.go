@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"go-telegram-forwarder-bot/internal/models"
 	"gorm.io/gorm"
@@ -11,6 +13,14 @@ type AuditLogRepository interface {
 	GetByID(id uuid.UUID) (*models.AuditLog, error)
 	GetByUserID(userID uuid.UUID, limit int) ([]*models.AuditLog, error)
 	GetByActionType(actionType models.AuditLogAction, limit int) ([]*models.AuditLog, error)
+	GetLatestByResource(actionType models.AuditLogAction, resourceID uuid.UUID) (*models.AuditLog, error)
+	// GetLatestByResources is GetLatestByResource for many resources in one
+	// query, keyed by resource ID; resources without a log are left out
+	GetLatestByResources(actionType models.AuditLogAction, resourceIDs []uuid.UUID) (map[uuid.UUID]*models.AuditLog, error)
+	// GetByBotIDBetween returns the logs about a bot or its recipients, admins,
+	// blacklist entries, reports, payments and messages created in [start,
+	// end), oldest first
+	GetByBotIDBetween(botID uuid.UUID, start, end time.Time, limit int) ([]*models.AuditLog, error)
 	WithTx(tx *gorm.DB) AuditLogRepository
 }
 
@@ -58,6 +68,61 @@ func (r *auditLogRepository) GetByActionType(actionType models.AuditLogAction, l
 	return logs, nil
 }
 
+func (r *auditLogRepository) GetLatestByResource(actionType models.AuditLogAction, resourceID uuid.UUID) (*models.AuditLog, error) {
+	var log models.AuditLog
+	if err := r.db.Where("action_type = ? AND resource_id = ?", actionType, resourceID).
+		Order("created_at DESC").
+		Preload("User").
+		First(&log).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (r *auditLogRepository) GetLatestByResources(actionType models.AuditLogAction, resourceIDs []uuid.UUID) (map[uuid.UUID]*models.AuditLog, error) {
+	latest := make(map[uuid.UUID]*models.AuditLog, len(resourceIDs))
+	if len(resourceIDs) == 0 {
+		return latest, nil
+	}
+	var logs []*models.AuditLog
+	if err := r.db.Where("action_type = ? AND resource_id IN ?", actionType, resourceIDs).
+		Order("created_at DESC").
+		Preload("User").
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		if _, ok := latest[log.ResourceID]; !ok {
+			latest[log.ResourceID] = log
+		}
+	}
+	return latest, nil
+}
+
+// botResourceTables are the tables whose rows belong to a bot and are the
+// resource of audit logs. Soft-deleted rows are included, so logs about a
+// removed recipient or admin are still found.
+var botResourceTables = []string{"recipients", "bot_admins", "blacklists", "reports", "payments", "message_mappings"}
+
+func (r *auditLogRepository) GetByBotIDBetween(botID uuid.UUID, start, end time.Time, limit int) ([]*models.AuditLog, error) {
+	resources := r.db.Where("resource_id = ?", botID)
+	for _, table := range botResourceTables {
+		resources = resources.Or("resource_id IN (?)", r.db.Table(table).Select("id").Where("bot_id = ?", botID))
+	}
+
+	var logs []*models.AuditLog
+	query := r.db.Where("created_at >= ? AND created_at < ?", start, end).
+		Where(resources).
+		Order("created_at ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Preload("User").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
 func (r *auditLogRepository) WithTx(tx *gorm.DB) AuditLogRepository {
 	return &auditLogRepository{db: tx}
 }
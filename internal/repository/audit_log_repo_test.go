@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestAuditLogGetByBotIDBetween(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewAuditLogRepository(db)
+	botID := uuid.New()
+	now := time.Now()
+
+	recipient := &models.Recipient{ID: uuid.New(), BotID: botID, RecipientType: models.RecipientTypeUser, ChatID: 1}
+	if err := db.Create(recipient).Error; err != nil {
+		t.Fatalf("create recipient: %v", err)
+	}
+	// Logs about a removed recipient still belong to the bot
+	if err := db.Delete(recipient).Error; err != nil {
+		t.Fatalf("delete recipient: %v", err)
+	}
+
+	logs := []*models.AuditLog{
+		{ActionType: models.AuditLogActionSetPaywall, ResourceType: "bot", ResourceID: botID, CreatedAt: now.Add(-2 * time.Hour)},
+		{ActionType: models.AuditLogActionAddRecipient, ResourceType: "recipient", ResourceID: recipient.ID, CreatedAt: now.Add(-time.Hour)},
+		// Outside the range
+		{ActionType: models.AuditLogActionSetPaywall, ResourceType: "bot", ResourceID: botID, CreatedAt: now.Add(-48 * time.Hour)},
+		// Another bot
+		{ActionType: models.AuditLogActionSetPaywall, ResourceType: "bot", ResourceID: uuid.New(), CreatedAt: now.Add(-time.Hour)},
+	}
+	for _, log := range logs {
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	got, err := repo.GetByBotIDBetween(botID, now.Add(-24*time.Hour), now, 0)
+	if err != nil {
+		t.Fatalf("GetByBotIDBetween: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != logs[0].ID || got[1].ID != logs[1].ID {
+		t.Fatalf("GetByBotIDBetween returned %d logs, want the bot's two logs in the range, oldest first", len(got))
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+type AutoReplyRepository interface {
+	Create(rule *models.AutoReply) error
+	// GetByBotID returns the bot's rules in the order they were added
+	GetByBotID(botID uuid.UUID) ([]*models.AutoReply, error)
+	// RecordHit counts one answered guest message for the bot's rule
+	RecordHit(botID uuid.UUID, id uuid.UUID) error
+	// ResetHits clears the hit statistics of all of the bot's rules
+	ResetHits(botID uuid.UUID) error
+	// Delete removes the bot's rule; it returns ErrNotOwned for another bot's rule
+	Delete(botID uuid.UUID, id uuid.UUID) error
+}
+
+type autoReplyRepository struct {
+	db *gorm.DB
+}
+
+func NewAutoReplyRepository(db *gorm.DB) AutoReplyRepository {
+	return &autoReplyRepository{db: db}
+}
+
+func (r *autoReplyRepository) Create(rule *models.AutoReply) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *autoReplyRepository) GetByBotID(botID uuid.UUID) ([]*models.AutoReply, error) {
+	var rules []*models.AutoReply
+	if err := r.db.Where("bot_id = ?", botID).
+		Order("created_at ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *autoReplyRepository) RecordHit(botID uuid.UUID, id uuid.UUID) error {
+	return r.db.Model(&models.AutoReply{}).
+		Where("id = ? AND bot_id = ?", id, botID).
+		UpdateColumns(map[string]interface{}{
+			"hit_count":   gorm.Expr("hit_count + 1"),
+			"last_hit_at": time.Now(),
+		}).Error
+}
+
+func (r *autoReplyRepository) ResetHits(botID uuid.UUID) error {
+	return r.db.Model(&models.AutoReply{}).
+		Where("bot_id = ?", botID).
+		UpdateColumns(map[string]interface{}{
+			"hit_count":   0,
+			"last_hit_at": nil,
+		}).Error
+}
+
+func (r *autoReplyRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.AutoReply{}, id, botID)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestUserGetByIDs(t *testing.T) {
+	repo := NewUserRepository(newTestDB(t))
+
+	var ids []uuid.UUID
+	for telegramUserID := int64(1); telegramUserID <= 3; telegramUserID++ {
+		user, err := repo.GetOrCreateByTelegramUserID(telegramUserID, nil)
+		if err != nil {
+			t.Fatalf("GetOrCreateByTelegramUserID: %v", err)
+		}
+		ids = append(ids, user.ID)
+	}
+
+	users, err := repo.GetByIDs([]uuid.UUID{ids[0], ids[2], uuid.New()})
+	if err != nil {
+		t.Fatalf("GetByIDs: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("got %d users, want 2", len(users))
+	}
+	for _, user := range users {
+		if user.ID != ids[0] && user.ID != ids[2] {
+			t.Fatalf("got user %s that was not asked for", user.ID)
+		}
+	}
+
+	if users, err := repo.GetByIDs(nil); err != nil || len(users) != 0 {
+		t.Fatalf("GetByIDs(nil) = %d users, %v; want none", len(users), err)
+	}
+}
+
+func TestAuditLogGetLatestByResources(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewAuditLogRepository(db)
+	user, err := NewUserRepository(db).GetOrCreateByTelegramUserID(7, nil)
+	if err != nil {
+		t.Fatalf("GetOrCreateByTelegramUserID: %v", err)
+	}
+
+	first, second, unlogged := uuid.New(), uuid.New(), uuid.New()
+	base := time.Now().Add(-time.Hour)
+	logs := []*models.AuditLog{
+		{ActionType: models.AuditLogActionAddAdmin, ResourceID: first, Details: "old", CreatedAt: base},
+		{ActionType: models.AuditLogActionAddAdmin, ResourceID: first, Details: "new", UserID: &user.ID, CreatedAt: base.Add(time.Minute)},
+		{ActionType: models.AuditLogActionDelAdmin, ResourceID: first, Details: "other action", CreatedAt: base.Add(2 * time.Minute)},
+		{ActionType: models.AuditLogActionAddAdmin, ResourceID: second, Details: "only", CreatedAt: base},
+	}
+	for _, log := range logs {
+		log.ResourceType = "admin"
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	latest, err := repo.GetLatestByResources(models.AuditLogActionAddAdmin, []uuid.UUID{first, second, unlogged})
+	if err != nil {
+		t.Fatalf("GetLatestByResources: %v", err)
+	}
+	if len(latest) != 2 {
+		t.Fatalf("got logs for %d resources, want 2", len(latest))
+	}
+	if got := latest[first]; got.Details != "new" || got.User == nil || got.User.ID != user.ID {
+		t.Fatalf("latest log of first resource = %q with user %v, want \"new\" by the user", got.Details, got.User)
+	}
+	if got := latest[second]; got.Details != "only" {
+		t.Fatalf("latest log of second resource = %q, want \"only\"", got.Details)
+	}
+	if _, ok := latest[unlogged]; ok {
+		t.Fatal("got a log for a resource without one")
+	}
+}
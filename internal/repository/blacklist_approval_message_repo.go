@@ -8,6 +8,8 @@ import (
 
 type BlacklistApprovalMessageRepository interface {
 	Create(msg *models.BlacklistApprovalMessage) error
+	// CreateBatch stores the approval messages of one fan-out in a single insert
+	CreateBatch(msgs []*models.BlacklistApprovalMessage) error
 	GetByBlacklistID(blacklistID uuid.UUID) ([]*models.BlacklistApprovalMessage, error)
 	GetByBlacklistIDAndUserID(blacklistID uuid.UUID, userID uuid.UUID) (*models.BlacklistApprovalMessage, error)
 	DeleteByBlacklistID(blacklistID uuid.UUID) error
@@ -25,9 +27,16 @@ func (r *blacklistApprovalMessageRepository) Create(msg *models.BlacklistApprova
 	return r.db.Create(msg).Error
 }
 
+func (r *blacklistApprovalMessageRepository) CreateBatch(msgs []*models.BlacklistApprovalMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	return r.db.Create(msgs).Error
+}
+
 func (r *blacklistApprovalMessageRepository) GetByBlacklistID(blacklistID uuid.UUID) ([]*models.BlacklistApprovalMessage, error) {
 	var messages []*models.BlacklistApprovalMessage
-	if err := r.db.Where("blacklist_id = ? AND deleted_at IS NULL", blacklistID).
+	if err := r.db.Where("blacklist_id = ?", blacklistID).
 		Preload("User").Find(&messages).Error; err != nil {
 		return nil, err
 	}
@@ -36,7 +45,7 @@ func (r *blacklistApprovalMessageRepository) GetByBlacklistID(blacklistID uuid.U
 
 func (r *blacklistApprovalMessageRepository) GetByBlacklistIDAndUserID(blacklistID uuid.UUID, userID uuid.UUID) (*models.BlacklistApprovalMessage, error) {
 	var msg models.BlacklistApprovalMessage
-	if err := r.db.Where("blacklist_id = ? AND user_id = ? AND deleted_at IS NULL", blacklistID, userID).
+	if err := r.db.Where("blacklist_id = ? AND user_id = ?", blacklistID, userID).
 		First(&msg).Error; err != nil {
 		return nil, err
 	}
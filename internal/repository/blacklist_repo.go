@@ -14,13 +14,22 @@ type BlacklistRepository interface {
 	GetAllByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) ([]*models.Blacklist, error)
 	GetActiveByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) (*models.Blacklist, error)
 	GetPendingByBotID(botID uuid.UUID) ([]*models.Blacklist, error)
+	// GetByBotID returns all of the bot's requests with their guests, most recent first
+	GetByBotID(botID uuid.UUID) ([]*models.Blacklist, error)
 	GetPendingOrApprovedBanByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) (*models.Blacklist, error)
 	GetLatestApprovedUnbanByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) (*models.Blacklist, error)
 	GetLatestByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) (*models.Blacklist, error)
+	// Update saves the request; it returns ErrNotOwned if the stored request
+	// belongs to a bot other than blacklist.BotID
 	Update(blacklist *models.Blacklist) error
-	ApprovePending(id uuid.UUID) error
-	RejectPending(id uuid.UUID) error
+	// ApprovePending and RejectPending decide a pending request. They report false
+	// if the request was no longer pending, e.g. because someone else decided it first.
+	ApprovePending(id uuid.UUID, decidedBy uuid.UUID) (bool, error)
+	RejectPending(id uuid.UUID, decidedBy uuid.UUID) (bool, error)
 	AutoApproveExpired() error
+	// GetExpiredBans returns temporary bans that expired before now and are
+	// still the latest request of their guest
+	GetExpiredBans(now time.Time) ([]*models.Blacklist, error)
 }
 
 type blacklistRepository struct {
@@ -54,7 +63,16 @@ func (r *blacklistRepository) GetByBotIDAndGuestID(botID uuid.UUID, guestID uuid
 
 func (r *blacklistRepository) GetAllByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) ([]*models.Blacklist, error) {
 	var blacklists []*models.Blacklist
-	if err := r.db.Where("bot_id = ? AND guest_id = ? AND deleted_at IS NULL", botID, guestID).
+	if err := r.db.Where("bot_id = ? AND guest_id = ?", botID, guestID).
+		Order("created_at DESC").Find(&blacklists).Error; err != nil {
+		return nil, err
+	}
+	return blacklists, nil
+}
+
+func (r *blacklistRepository) GetByBotID(botID uuid.UUID) ([]*models.Blacklist, error) {
+	var blacklists []*models.Blacklist
+	if err := r.db.Preload("Guest").Where("bot_id = ?", botID).
 		Order("created_at DESC").Find(&blacklists).Error; err != nil {
 		return nil, err
 	}
@@ -63,7 +81,7 @@ func (r *blacklistRepository) GetAllByBotIDAndGuestID(botID uuid.UUID, guestID u
 
 func (r *blacklistRepository) GetActiveByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) (*models.Blacklist, error) {
 	var blacklist models.Blacklist
-	if err := r.db.Where("bot_id = ? AND guest_id = ? AND status = ? AND deleted_at IS NULL",
+	if err := r.db.Where("bot_id = ? AND guest_id = ? AND status = ?",
 		botID, guestID, models.BlacklistStatusApproved).
 		Order("created_at DESC").First(&blacklist).Error; err != nil {
 		return nil, err
@@ -73,7 +91,7 @@ func (r *blacklistRepository) GetActiveByBotIDAndGuestID(botID uuid.UUID, guestI
 
 func (r *blacklistRepository) GetPendingOrApprovedBanByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) (*models.Blacklist, error) {
 	var blacklist models.Blacklist
-	if err := r.db.Where("bot_id = ? AND guest_id = ? AND request_type = ? AND status IN ? AND deleted_at IS NULL",
+	if err := r.db.Where("bot_id = ? AND guest_id = ? AND request_type = ? AND status IN ?",
 		botID, guestID, models.BlacklistRequestTypeBan, []models.BlacklistStatus{models.BlacklistStatusPending, models.BlacklistStatusApproved}).
 		Order("created_at DESC").First(&blacklist).Error; err != nil {
 		return nil, err
@@ -83,7 +101,7 @@ func (r *blacklistRepository) GetPendingOrApprovedBanByBotIDAndGuestID(botID uui
 
 func (r *blacklistRepository) GetLatestApprovedUnbanByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) (*models.Blacklist, error) {
 	var blacklist models.Blacklist
-	if err := r.db.Where("bot_id = ? AND guest_id = ? AND request_type = ? AND status = ? AND deleted_at IS NULL",
+	if err := r.db.Where("bot_id = ? AND guest_id = ? AND request_type = ? AND status = ?",
 		botID, guestID, models.BlacklistRequestTypeUnban, models.BlacklistStatusApproved).
 		Order("created_at DESC").First(&blacklist).Error; err != nil {
 		return nil, err
@@ -95,7 +113,7 @@ func (r *blacklistRepository) GetLatestApprovedUnbanByBotIDAndGuestID(botID uuid
 // This is optimized to only fetch the most recent record instead of all records
 func (r *blacklistRepository) GetLatestByBotIDAndGuestID(botID uuid.UUID, guestID uuid.UUID) (*models.Blacklist, error) {
 	var blacklist models.Blacklist
-	if err := r.db.Where("bot_id = ? AND guest_id = ? AND deleted_at IS NULL",
+	if err := r.db.Where("bot_id = ? AND guest_id = ?",
 		botID, guestID).
 		Order("created_at DESC").First(&blacklist).Error; err != nil {
 		return nil, err
@@ -113,23 +131,38 @@ func (r *blacklistRepository) GetPendingByBotID(botID uuid.UUID) ([]*models.Blac
 }
 
 func (r *blacklistRepository) Update(blacklist *models.Blacklist) error {
-	return r.db.Save(blacklist).Error
+	if err := checkOwner(r.db, &models.Blacklist{}, blacklist.ID, blacklist.BotID); err != nil {
+		return err
+	}
+	return updateExisting(r.db, blacklist)
 }
 
-func (r *blacklistRepository) ApprovePending(id uuid.UUID) error {
+func (r *blacklistRepository) ApprovePending(id uuid.UUID, decidedBy uuid.UUID) (bool, error) {
 	now := time.Now()
-	return r.db.Model(&models.Blacklist{}).
+	result := r.db.Model(&models.Blacklist{}).
 		Where("id = ? AND status = ?", id, models.BlacklistStatusPending).
 		Updates(map[string]interface{}{
 			"status":      models.BlacklistStatusApproved,
 			"approved_at": &now,
-		}).Error
+			"decided_by":  decidedBy,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
 }
 
-func (r *blacklistRepository) RejectPending(id uuid.UUID) error {
-	return r.db.Model(&models.Blacklist{}).
+func (r *blacklistRepository) RejectPending(id uuid.UUID, decidedBy uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.Blacklist{}).
 		Where("id = ? AND status = ?", id, models.BlacklistStatusPending).
-		Update("status", models.BlacklistStatusRejected).Error
+		Updates(map[string]interface{}{
+			"status":     models.BlacklistStatusRejected,
+			"decided_by": decidedBy,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
 }
 
 func (r *blacklistRepository) AutoApproveExpired() error {
@@ -142,3 +175,14 @@ func (r *blacklistRepository) AutoApproveExpired() error {
 			"approved_at": &now,
 		}).Error
 }
+
+func (r *blacklistRepository) GetExpiredBans(now time.Time) ([]*models.Blacklist, error) {
+	var blacklists []*models.Blacklist
+	if err := r.db.Where("request_type = ? AND expires_at IS NOT NULL AND expires_at <= ?", models.BlacklistRequestTypeBan, now).
+		Where("NOT EXISTS (?)", r.db.Table("blacklists AS later").Select("1").
+			Where("later.bot_id = blacklists.bot_id AND later.guest_id = blacklists.guest_id AND later.created_at > blacklists.created_at AND later.deleted_at IS NULL")).
+		Find(&blacklists).Error; err != nil {
+		return nil, err
+	}
+	return blacklists, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestBlacklistRepositoryGetExpiredBans(t *testing.T) {
+	repo := NewBlacklistRepository(newTestDB(t))
+	botID := uuid.New()
+	now := time.Now()
+	expired, pending := now.Add(-time.Minute), now.Add(time.Minute)
+
+	ban := func(guestID uuid.UUID, createdAt time.Time, expiresAt *time.Time) *models.Blacklist {
+		t.Helper()
+		blacklist := &models.Blacklist{
+			BotID:         botID,
+			GuestID:       guestID,
+			Status:        models.BlacklistStatusApproved,
+			RequestUserID: uuid.New(),
+			RequestType:   models.BlacklistRequestTypeBan,
+			ExpiresAt:     expiresAt,
+			CreatedAt:     createdAt,
+		}
+		if err := repo.Create(blacklist); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return blacklist
+	}
+
+	muted := ban(uuid.New(), now.Add(-time.Hour), &expired)
+	ban(uuid.New(), now.Add(-time.Hour), &pending) // Not expired yet
+	ban(uuid.New(), now.Add(-time.Hour), nil)      // Permanent
+
+	// An expired mute followed by another request is not the guest's state anymore
+	unbannedGuest := uuid.New()
+	ban(unbannedGuest, now.Add(-time.Hour), &expired)
+	if err := repo.Create(&models.Blacklist{
+		BotID:         botID,
+		GuestID:       unbannedGuest,
+		Status:        models.BlacklistStatusApproved,
+		RequestUserID: uuid.New(),
+		RequestType:   models.BlacklistRequestTypeUnban,
+		CreatedAt:     now.Add(-30 * time.Minute),
+	}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := repo.GetExpiredBans(now)
+	if err != nil {
+		t.Fatalf("GetExpiredBans: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != muted.ID {
+		t.Fatalf("GetExpiredBans = %v, want only the expired mute that is still the latest request", got)
+	}
+}
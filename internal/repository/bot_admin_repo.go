@@ -12,7 +12,8 @@ type BotAdminRepository interface {
 	GetByBotID(botID uuid.UUID) ([]*models.BotAdmin, error)
 	GetByBotIDAndUserID(botID uuid.UUID, userID uuid.UUID) (*models.BotAdmin, error)
 	IsAdmin(botID uuid.UUID, userID uuid.UUID) (bool, error)
-	Delete(id uuid.UUID) error
+	// Delete removes the bot's admin; it returns ErrNotOwned for another bot's admin
+	Delete(botID uuid.UUID, id uuid.UUID) error
 	DeleteByBotIDAndUserID(botID uuid.UUID, userID uuid.UUID) error
 }
 
@@ -57,15 +58,15 @@ func (r *botAdminRepository) GetByBotIDAndUserID(botID uuid.UUID, userID uuid.UU
 func (r *botAdminRepository) IsAdmin(botID uuid.UUID, userID uuid.UUID) (bool, error) {
 	var count int64
 	if err := r.db.Model(&models.BotAdmin{}).
-		Where("bot_id = ? AND admin_user_id = ? AND deleted_at IS NULL", botID, userID).
+		Where("bot_id = ? AND admin_user_id = ?", botID, userID).
 		Count(&count).Error; err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (r *botAdminRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.BotAdmin{}, "id = ?", id).Error
+func (r *botAdminRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.BotAdmin{}, id, botID)
 }
 
 func (r *botAdminRepository) DeleteByBotIDAndUserID(botID uuid.UUID, userID uuid.UUID) error {
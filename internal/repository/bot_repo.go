@@ -14,6 +14,8 @@ type BotRepository interface {
 	Update(bot *models.ForwarderBot) error
 	Delete(id uuid.UUID) error
 	GetByToken(token string) (*models.ForwarderBot, error)
+	// GetByTelegramBotID returns the bot with the given Telegram user ID
+	GetByTelegramBotID(telegramBotID int64) (*models.ForwarderBot, error)
 	WithTx(tx *gorm.DB) BotRepository
 }
 
@@ -54,7 +56,7 @@ func (r *botRepository) GetAll() ([]*models.ForwarderBot, error) {
 }
 
 func (r *botRepository) Update(bot *models.ForwarderBot) error {
-	return r.db.Save(bot).Error
+	return updateExisting(r.db, bot)
 }
 
 func (r *botRepository) Delete(id uuid.UUID) error {
@@ -69,6 +71,14 @@ func (r *botRepository) GetByToken(token string) (*models.ForwarderBot, error) {
 	return &bot, nil
 }
 
+func (r *botRepository) GetByTelegramBotID(telegramBotID int64) (*models.ForwarderBot, error) {
+	var bot models.ForwarderBot
+	if err := r.db.Where("telegram_bot_id = ?", telegramBotID).First(&bot).Error; err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
 func (r *botRepository) WithTx(tx *gorm.DB) BotRepository {
 	return &botRepository{db: tx}
 }
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BotSettingRepository interface {
+	// GetByBotID returns the bot's stored settings keyed by name
+	GetByBotID(botID uuid.UUID) (map[string]string, error)
+	GetByBotIDAndName(botID uuid.UUID, name string) (*models.BotSetting, error)
+	// Set creates or replaces the value of a setting
+	Set(botID uuid.UUID, name, value string) error
+	Delete(botID uuid.UUID, name string) error
+	DeleteByBotID(botID uuid.UUID) error
+}
+
+type botSettingRepository struct {
+	db *gorm.DB
+}
+
+func NewBotSettingRepository(db *gorm.DB) BotSettingRepository {
+	return &botSettingRepository{db: db}
+}
+
+func (r *botSettingRepository) GetByBotID(botID uuid.UUID) (map[string]string, error) {
+	var settings []*models.BotSetting
+	if err := r.db.Where("bot_id = ?", botID).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		values[setting.Name] = setting.Value
+	}
+	return values, nil
+}
+
+func (r *botSettingRepository) GetByBotIDAndName(botID uuid.UUID, name string) (*models.BotSetting, error) {
+	var setting models.BotSetting
+	if err := r.db.Where("bot_id = ? AND name = ?", botID, name).First(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *botSettingRepository) Set(botID uuid.UUID, name, value string) error {
+	setting := &models.BotSetting{
+		BotID: botID,
+		Name:  name,
+		Value: value,
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bot_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(setting).Error
+}
+
+func (r *botSettingRepository) Delete(botID uuid.UUID, name string) error {
+	return r.db.Where("bot_id = ? AND name = ?", botID, name).Delete(&models.BotSetting{}).Error
+}
+
+func (r *botSettingRepository) DeleteByBotID(botID uuid.UUID) error {
+	return r.db.Where("bot_id = ?", botID).Delete(&models.BotSetting{}).Error
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BotUsageRepository interface {
+	// GetByBotIDAndMonth returns the usage of a month, or a zero usage if nothing was recorded
+	GetByBotIDAndMonth(botID uuid.UUID, month string) (*models.BotUsage, error)
+	// TryIncrement counts one message unless the month already reached limit (0 means no limit).
+	// It reports whether the message was counted.
+	TryIncrement(botID uuid.UUID, month string, limit int64) (bool, error)
+	// MarkWarned sets WarnedAt once per month; it reports whether this call set it
+	MarkWarned(botID uuid.UUID, month string) (bool, error)
+	// MarkExceeded sets ExceededAt once per month; it reports whether this call set it
+	MarkExceeded(botID uuid.UUID, month string) (bool, error)
+	// ClearFlags resets the warning and exceeded markers, e.g. after the quota changed
+	ClearFlags(botID uuid.UUID, month string) error
+}
+
+type botUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewBotUsageRepository(db *gorm.DB) BotUsageRepository {
+	return &botUsageRepository{db: db}
+}
+
+func (r *botUsageRepository) GetByBotIDAndMonth(botID uuid.UUID, month string) (*models.BotUsage, error) {
+	var usage models.BotUsage
+	err := r.db.Where("bot_id = ? AND month = ?", botID, month).First(&usage).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.BotUsage{BotID: botID, Month: month}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+func (r *botUsageRepository) TryIncrement(botID uuid.UUID, month string, limit int64) (bool, error) {
+	usage := &models.BotUsage{BotID: botID, Month: month}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(usage).Error; err != nil {
+		return false, err
+	}
+
+	query := r.db.Model(&models.BotUsage{}).Where("bot_id = ? AND month = ?", botID, month)
+	if limit > 0 {
+		query = query.Where("message_count < ?", limit)
+	}
+	result := query.UpdateColumn("message_count", gorm.Expr("message_count + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *botUsageRepository) MarkWarned(botID uuid.UUID, month string) (bool, error) {
+	result := r.db.Model(&models.BotUsage{}).
+		Where("bot_id = ? AND month = ? AND warned_at IS NULL", botID, month).
+		Update("warned_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *botUsageRepository) MarkExceeded(botID uuid.UUID, month string) (bool, error) {
+	result := r.db.Model(&models.BotUsage{}).
+		Where("bot_id = ? AND month = ? AND exceeded_at IS NULL", botID, month).
+		Update("exceeded_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *botUsageRepository) ClearFlags(botID uuid.UUID, month string) error {
+	return r.db.Model(&models.BotUsage{}).
+		Where("bot_id = ? AND month = ?", botID, month).
+		Updates(map[string]interface{}{"warned_at": nil, "exceeded_at": nil}).Error
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContentCipher encrypts the guest messages the repositories store, e.g. in
+// the retry queue, with a data key per bot. The data keys are created on first
+// use and stored wrapped with a key derived from the master encryption key.
+type ContentCipher struct {
+	db          *gorm.DB
+	wrappingKey []byte
+
+	mu   sync.Mutex
+	keys map[uuid.UUID][]byte // Unwrapped data keys by bot
+}
+
+func NewContentCipher(db *gorm.DB, masterKey []byte) (*ContentCipher, error) {
+	wrappingKey, err := utils.DeriveKey(masterKey, utils.KeyPurposeDataKeyWrap)
+	if err != nil {
+		return nil, err
+	}
+	return &ContentCipher{
+		db:          db,
+		wrappingKey: wrappingKey,
+		keys:        make(map[uuid.UUID][]byte),
+	}, nil
+}
+
+// encrypt encrypts the content of one record of the bot
+func (c *ContentCipher) encrypt(botID uuid.UUID, record uuid.UUID, content string) (string, error) {
+	dataKey, err := c.dataKey(botID)
+	if err != nil {
+		return "", err
+	}
+	return utils.EncryptContent(content, dataKey, record.String())
+}
+
+// decrypt reverses encrypt. Messages stored as plain JSON before they were
+// encrypted are returned as they are.
+func (c *ContentCipher) decrypt(botID uuid.UUID, record uuid.UUID, stored string) (string, error) {
+	if stored == "" || strings.HasPrefix(stored, "{") {
+		return stored, nil
+	}
+	dataKey, err := c.dataKey(botID)
+	if err != nil {
+		return "", err
+	}
+	return utils.DecryptContent(stored, dataKey, record.String())
+}
+
+// decryptOrEmpty decrypts content for a reader. Content that cannot be
+// decrypted, e.g. after the master key was replaced, reads as empty, which
+// callers treat like any other message they cannot decode.
+func (c *ContentCipher) decryptOrEmpty(botID uuid.UUID, record uuid.UUID, stored string) string {
+	content, err := c.decrypt(botID, record, stored)
+	if err != nil {
+		return ""
+	}
+	return content
+}
+
+// dataKey returns the bot's data key, creating it the first time
+func (c *ContentCipher) dataKey(botID uuid.UUID) ([]byte, error) {
+	c.mu.Lock()
+	dataKey, ok := c.keys[botID]
+	c.mu.Unlock()
+	if ok {
+		return dataKey, nil
+	}
+
+	var stored models.BotDataKey
+	err := c.db.Where("bot_id = ?", botID).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var wrapped string
+		if _, wrapped, err = utils.NewDataKey(c.wrappingKey, botID.String()); err != nil {
+			return nil, err
+		}
+		created := models.BotDataKey{BotID: botID, WrappedKey: wrapped}
+		if err := c.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&created).Error; err != nil {
+			return nil, fmt.Errorf("failed to store data key: %w", err)
+		}
+		// Another instance may have stored a key first; use whichever was kept
+		err = c.db.Where("bot_id = ?", botID).First(&stored).Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data key: %w", err)
+	}
+
+	dataKey, err = utils.UnwrapDataKey(stored.WrappedKey, c.wrappingKey, botID.String())
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.keys[botID] = dataKey
+	c.mu.Unlock()
+	return dataKey, nil
+}
+
+// withEncrypted runs store with the content encrypted for the record and puts
+// the plaintext back afterwards, so the caller's value stays readable
+func (c *ContentCipher) withEncrypted(botID uuid.UUID, record uuid.UUID, content *string, store func() error) error {
+	plaintext := *content
+	encrypted, err := c.encrypt(botID, record, plaintext)
+	if err != nil {
+		return err
+	}
+	*content = encrypted
+	err = store()
+	*content = plaintext
+	return err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"testing"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func newTestCipher(t *testing.T, db *gorm.DB) *ContentCipher {
+	t.Helper()
+	key, err := utils.GenerateEncryptionKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	cipher, err := NewContentCipher(db, key)
+	if err != nil {
+		t.Fatalf("NewContentCipher: %v", err)
+	}
+	return cipher
+}
+
+func TestQuarantinedMessageContentIsEncrypted(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewQuarantinedMessageRepository(db, newTestCipher(t, db))
+	botID := uuid.New()
+	content := `{"message_id":10,"text":"hello"}`
+
+	msg := &models.QuarantinedMessage{BotID: botID, GuestChatID: 1, GuestMessageID: 10, Pattern: "hello", Message: content}
+	if err := repo.Create(msg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if msg.Message != content {
+		t.Fatalf("Create left Message = %q, want the plaintext", msg.Message)
+	}
+
+	var stored models.QuarantinedMessage
+	if err := db.Where("id = ?", msg.ID).First(&stored).Error; err != nil {
+		t.Fatalf("failed to read stored message: %v", err)
+	}
+	if stored.Message == content {
+		t.Fatal("message was stored unencrypted")
+	}
+
+	got, err := repo.GetByBotIDAndID(botID, msg.ID)
+	if err != nil || got.Message != content {
+		t.Fatalf("GetByBotIDAndID = %v, %v; want the plaintext", got, err)
+	}
+
+	// Content bound to one record cannot be read as another
+	moved := models.QuarantinedMessage{BotID: botID, GuestChatID: 1, GuestMessageID: 11, Pattern: "hello", Message: stored.Message}
+	if err := db.Create(&moved).Error; err != nil {
+		t.Fatalf("failed to copy message: %v", err)
+	}
+	if got, err := repo.GetByBotIDAndID(botID, moved.ID); err != nil || got.Message != "" {
+		t.Fatalf("copied message = %v, %v; want it unreadable", got, err)
+	}
+
+	// Messages stored before content was encrypted are still readable
+	legacy := models.QuarantinedMessage{BotID: botID, GuestChatID: 1, GuestMessageID: 12, Pattern: "hello", Message: content}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatalf("failed to store legacy message: %v", err)
+	}
+	if got, err := repo.GetByBotIDAndID(botID, legacy.ID); err != nil || got.Message != content {
+		t.Fatalf("legacy message = %v, %v; want it as stored", got, err)
+	}
+}
+
+func TestContentCipherKeepsDataKeyAcrossInstances(t *testing.T) {
+	db := newTestDB(t)
+	key, err := utils.GenerateEncryptionKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	first, _ := NewContentCipher(db, key)
+	second, _ := NewContentCipher(db, key)
+	botID, record := uuid.New(), uuid.New()
+
+	encrypted, err := first.encrypt(botID, record, "{}")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if got, err := second.decrypt(botID, record, encrypted); err != nil || got != "{}" {
+		t.Fatalf("decrypt by another instance = %q, %v; want the plaintext", got, err)
+	}
+
+	var count int64
+	db.Model(&models.BotDataKey{}).Where("bot_id = ?", botID).Count(&count)
+	if count != 1 {
+		t.Fatalf("stored %d data keys for the bot, want 1", count)
+	}
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeadLetterRepository interface {
+	// Create stores the dead letter and reports whether the same message was
+	// not stored for the recipient already
+	Create(deadLetter *models.DeadLetter) (bool, error)
+	GetByID(id uuid.UUID) (*models.DeadLetter, error)
+	// GetByBotID returns up to limit of the bot's dead letters after skipping
+	// offset, newest first
+	GetByBotID(botID uuid.UUID, offset int, limit int) ([]*models.DeadLetter, error)
+	CountByBotID(botID uuid.UUID) (int64, error)
+	Delete(botID uuid.UUID, id uuid.UUID) error
+	// Requeue moves the dead letter to the retry queue, due at nextAttemptAt,
+	// and returns the queued delivery
+	Requeue(botID uuid.UUID, id uuid.UUID, nextAttemptAt time.Time) (*models.PendingDelivery, error)
+}
+
+type deadLetterRepository struct {
+	db     *gorm.DB
+	cipher *ContentCipher
+}
+
+func NewDeadLetterRepository(db *gorm.DB, cipher *ContentCipher) DeadLetterRepository {
+	return &deadLetterRepository{db: db, cipher: cipher}
+}
+
+func (r *deadLetterRepository) Create(deadLetter *models.DeadLetter) (bool, error) {
+	if deadLetter.ID == uuid.Nil {
+		deadLetter.ID = uuid.New()
+	}
+	var stored bool
+	err := r.cipher.withEncrypted(deadLetter.BotID, deadLetter.ID, &deadLetter.Message, func() error {
+		result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(deadLetter)
+		stored = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return stored, nil
+}
+
+func (r *deadLetterRepository) GetByID(id uuid.UUID) (*models.DeadLetter, error) {
+	var deadLetter models.DeadLetter
+	if err := r.db.Where("id = ?", id).First(&deadLetter).Error; err != nil {
+		return nil, err
+	}
+	deadLetter.Message = r.cipher.decryptOrEmpty(deadLetter.BotID, deadLetter.ID, deadLetter.Message)
+	return &deadLetter, nil
+}
+
+func (r *deadLetterRepository) GetByBotID(botID uuid.UUID, offset int, limit int) ([]*models.DeadLetter, error) {
+	var deadLetters []*models.DeadLetter
+	err := r.db.Where("bot_id = ?", botID).
+		Order("created_at DESC, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&deadLetters).Error
+	for _, deadLetter := range deadLetters {
+		deadLetter.Message = r.cipher.decryptOrEmpty(deadLetter.BotID, deadLetter.ID, deadLetter.Message)
+	}
+	return deadLetters, err
+}
+
+func (r *deadLetterRepository) CountByBotID(botID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.DeadLetter{}).Where("bot_id = ?", botID).Count(&count).Error
+	return count, err
+}
+
+func (r *deadLetterRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.DeadLetter{}, id, botID)
+}
+
+func (r *deadLetterRepository) Requeue(botID uuid.UUID, id uuid.UUID, nextAttemptAt time.Time) (*models.PendingDelivery, error) {
+	// Load the bot's data key first; the transaction holds its connection
+	if _, err := r.cipher.dataKey(botID); err != nil {
+		return nil, err
+	}
+
+	var delivery *models.PendingDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkOwner(tx, &models.DeadLetter{}, id, botID); err != nil {
+			return err
+		}
+		var deadLetter models.DeadLetter
+		if err := tx.Where("id = ?", id).First(&deadLetter).Error; err != nil {
+			return err
+		}
+		// The content is bound to the record, so it is encrypted again for the delivery
+		content, err := r.cipher.decrypt(deadLetter.BotID, deadLetter.ID, deadLetter.Message)
+		if err != nil {
+			return err
+		}
+
+		delivery = &models.PendingDelivery{
+			ID:             uuid.New(),
+			BotID:          deadLetter.BotID,
+			RecipientID:    deadLetter.RecipientID,
+			GuestChatID:    deadLetter.GuestChatID,
+			GuestMessageID: deadLetter.GuestMessageID,
+			Message:        content,
+			LastError:      deadLetter.LastError,
+			NextAttemptAt:  nextAttemptAt,
+		}
+		// A message that is queued already stays queued as it is
+		err = r.cipher.withEncrypted(delivery.BotID, delivery.ID, &delivery.Message, func() error {
+			return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(delivery).Error
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&deadLetter).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
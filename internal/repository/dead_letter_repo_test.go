@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestDeadLetterRepository(t *testing.T) {
+	db := newTestDB(t)
+	cipher := newTestCipher(t, db)
+	repo := NewDeadLetterRepository(db, cipher)
+	botID, recipientID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	older := &models.DeadLetter{BotID: botID, RecipientID: recipientID, GuestChatID: 1, GuestMessageID: 10, Message: "{}", CreatedAt: now.Add(-time.Hour)}
+	newer := &models.DeadLetter{BotID: botID, RecipientID: recipientID, GuestChatID: 1, GuestMessageID: 11, Message: "{}", CreatedAt: now}
+	for _, deadLetter := range []*models.DeadLetter{older, newer} {
+		if created, err := repo.Create(deadLetter); err != nil || !created {
+			t.Fatalf("Create = %v, %v; want created", created, err)
+		}
+	}
+	// The same message for the same recipient is only stored once
+	duplicate := &models.DeadLetter{BotID: botID, RecipientID: recipientID, GuestChatID: 1, GuestMessageID: 10, Message: "{}"}
+	if created, err := repo.Create(duplicate); err != nil || created {
+		t.Fatalf("Create duplicate = %v, %v; want not created", created, err)
+	}
+
+	got, err := repo.GetByBotID(botID, 0, 10)
+	if err != nil {
+		t.Fatalf("GetByBotID: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != newer.ID || got[1].ID != older.ID {
+		t.Fatalf("GetByBotID = %v, want both dead letters, newest first", got)
+	}
+	if got, _ := repo.GetByBotID(botID, 1, 10); len(got) != 1 || got[0].ID != older.ID {
+		t.Fatalf("GetByBotID with offset 1 = %v, want the older dead letter", got)
+	}
+
+	// Another bot can neither requeue nor discard the dead letters
+	if _, err := repo.Requeue(uuid.New(), older.ID, now); !errors.Is(err, ErrNotOwned) {
+		t.Fatalf("Requeue by another bot = %v, want ErrNotOwned", err)
+	}
+	if err := repo.Delete(uuid.New(), older.ID); !errors.Is(err, ErrNotOwned) {
+		t.Fatalf("Delete by another bot = %v, want ErrNotOwned", err)
+	}
+
+	delivery, err := repo.Requeue(botID, older.ID, now)
+	if err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	if delivery.GuestMessageID != 10 || delivery.RecipientID != recipientID {
+		t.Fatalf("Requeue queued %+v, want the dead letter's message", delivery)
+	}
+	queue := NewPendingDeliveryRepository(db, cipher)
+	if due, _ := queue.GetDue(botID, now, 10); len(due) != 1 || due[0].ID != delivery.ID || due[0].Message != "{}" {
+		t.Fatalf("retry queue = %v, want the requeued message", due)
+	}
+
+	if err := repo.Delete(botID, newer.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if count, err := repo.CountByBotID(botID); err != nil || count != 0 {
+		t.Fatalf("CountByBotID = %d, %v; want 0", count, err)
+	}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+type FilterRuleRepository interface {
+	Create(rule *models.FilterRule) error
+	// GetByBotID returns the bot's rules in the order they were added
+	GetByBotID(botID uuid.UUID) ([]*models.FilterRule, error)
+	// RecordHit counts one caught guest message for the bot's rule
+	RecordHit(botID uuid.UUID, id uuid.UUID) error
+	// Delete removes the bot's rule; it returns ErrNotOwned for another bot's rule
+	Delete(botID uuid.UUID, id uuid.UUID) error
+}
+
+type filterRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewFilterRuleRepository(db *gorm.DB) FilterRuleRepository {
+	return &filterRuleRepository{db: db}
+}
+
+func (r *filterRuleRepository) Create(rule *models.FilterRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *filterRuleRepository) GetByBotID(botID uuid.UUID) ([]*models.FilterRule, error) {
+	var rules []*models.FilterRule
+	if err := r.db.Where("bot_id = ?", botID).
+		Order("created_at ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *filterRuleRepository) RecordHit(botID uuid.UUID, id uuid.UUID) error {
+	return r.db.Model(&models.FilterRule{}).
+		Where("id = ? AND bot_id = ?", id, botID).
+		UpdateColumns(map[string]interface{}{
+			"hit_count":   gorm.Expr("hit_count + 1"),
+			"last_hit_at": time.Now(),
+		}).Error
+}
+
+func (r *filterRuleRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.FilterRule{}, id, botID)
+}
@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"go-telegram-forwarder-bot/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GuestRepository interface {
@@ -12,8 +15,40 @@ type GuestRepository interface {
 	GetByBotID(botID uuid.UUID) ([]*models.Guest, error)
 	GetByBotIDAndUserID(botID uuid.UUID, userID int64) (*models.Guest, error)
 	GetOrCreateByBotIDAndUserID(botID uuid.UUID, userID int64) (*models.Guest, error)
+	// CreateIfMissing creates the bot's guest unless it exists and reports
+	// whether it was created, i.e. whether this is a new guest
+	CreateIfMissing(botID uuid.UUID, userID int64) (bool, error)
 	CountByBotID(botID uuid.UUID) (int64, error)
-	Delete(id uuid.UUID) error
+	CountCreatedByBotIDBetween(botID uuid.UUID, start time.Time, end time.Time) (int64, error)
+	// AddCredits adds paid message credits to the guest, creating the guest if needed
+	AddCredits(botID uuid.UUID, userID int64, credits int) error
+	// ConsumeCredit takes one paid message credit; it reports false if the guest has none left
+	ConsumeCredit(botID uuid.UUID, userID int64) (bool, error)
+	// RemoveCredits takes back up to credits paid messages, e.g. after a refund
+	RemoveCredits(botID uuid.UUID, userID int64, credits int) error
+	// TagAlert tags the guest's conversation with a matched alert keyword
+	TagAlert(botID uuid.UUID, userID int64, keyword string) error
+	// ClearAlert removes the alert tag; it reports whether the guest was tagged
+	ClearAlert(botID uuid.UUID, userID int64) (bool, error)
+	// GetAlertedByBotID returns the guests with tagged conversations, most recent first
+	GetAlertedByBotID(botID uuid.UUID) ([]*models.Guest, error)
+	// MarkAwaitingReply starts the guest's wait for a reply unless one is already
+	// running; it reports whether a new wait was started
+	MarkAwaitingReply(botID uuid.UUID, userID int64, at time.Time) (bool, error)
+	// MarkReplied ends the guest's wait and records how long it took
+	MarkReplied(botID uuid.UUID, userID int64, at time.Time) error
+	// SetBlockedBot records whether the guest blocked the bot
+	SetBlockedBot(botID uuid.UUID, userID int64, blocked bool) error
+	// CountAwaitingBefore counts the bot's guests that have been waiting since before the given time
+	CountAwaitingBefore(botID uuid.UUID, before time.Time) (int64, error)
+	// AverageResponseTime returns the mean wait of the guests answered since the
+	// given time and how many there were
+	AverageResponseTime(botID uuid.UUID, since time.Time) (time.Duration, int64, error)
+	// Update saves the guest; it returns ErrNotOwned if the stored guest
+	// belongs to a bot other than guest.BotID
+	Update(guest *models.Guest) error
+	// Delete removes the bot's guest; it returns ErrNotOwned for another bot's guest
+	Delete(botID uuid.UUID, id uuid.UUID) error
 }
 
 type guestRepository struct {
@@ -62,16 +97,33 @@ func (r *guestRepository) GetOrCreateByBotIDAndUserID(botID uuid.UUID, userID in
 		return nil, err
 	}
 
+	// The first messages of a new guest can arrive at the same time; whoever
+	// inserts second gets the row the first one created
 	newGuest := &models.Guest{
 		BotID:       botID,
 		GuestUserID: userID,
 	}
-	if err := r.Create(newGuest); err != nil {
-		return nil, err
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(newGuest)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return r.GetByBotIDAndUserID(botID, userID)
 	}
 	return newGuest, nil
 }
 
+func (r *guestRepository) CreateIfMissing(botID uuid.UUID, userID int64) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Guest{
+		BotID:       botID,
+		GuestUserID: userID,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (r *guestRepository) CountByBotID(botID uuid.UUID) (int64, error) {
 	var count int64
 	if err := r.db.Model(&models.Guest{}).Where("bot_id = ?", botID).Count(&count).Error; err != nil {
@@ -80,6 +132,147 @@ func (r *guestRepository) CountByBotID(botID uuid.UUID) (int64, error) {
 	return count, nil
 }
 
-func (r *guestRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Guest{}, "id = ?", id).Error
+func (r *guestRepository) CountCreatedByBotIDBetween(botID uuid.UUID, start time.Time, end time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Guest{}).
+		Where("bot_id = ? AND created_at >= ? AND created_at < ?", botID, start, end).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *guestRepository) AddCredits(botID uuid.UUID, userID int64, credits int) error {
+	if _, err := r.GetOrCreateByBotIDAndUserID(botID, userID); err != nil {
+		return err
+	}
+	return r.db.Model(&models.Guest{}).
+		Where("bot_id = ? AND guest_user_id = ?", botID, userID).
+		UpdateColumn("paid_credits", gorm.Expr("paid_credits + ?", credits)).Error
+}
+
+func (r *guestRepository) ConsumeCredit(botID uuid.UUID, userID int64) (bool, error) {
+	result := r.db.Model(&models.Guest{}).
+		Where("bot_id = ? AND guest_user_id = ? AND paid_credits > 0", botID, userID).
+		UpdateColumn("paid_credits", gorm.Expr("paid_credits - 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *guestRepository) RemoveCredits(botID uuid.UUID, userID int64, credits int) error {
+	return r.db.Model(&models.Guest{}).
+		Where("bot_id = ? AND guest_user_id = ?", botID, userID).
+		UpdateColumn("paid_credits", gorm.Expr("CASE WHEN paid_credits > ? THEN paid_credits - ? ELSE 0 END", credits, credits)).Error
+}
+
+func (r *guestRepository) TagAlert(botID uuid.UUID, userID int64, keyword string) error {
+	if _, err := r.GetOrCreateByBotIDAndUserID(botID, userID); err != nil {
+		return err
+	}
+	return r.db.Model(&models.Guest{}).
+		Where("bot_id = ? AND guest_user_id = ?", botID, userID).
+		Updates(map[string]interface{}{
+			"alert_keyword": keyword,
+			"alerted_at":    time.Now(),
+		}).Error
+}
+
+func (r *guestRepository) ClearAlert(botID uuid.UUID, userID int64) (bool, error) {
+	result := r.db.Model(&models.Guest{}).
+		Where("bot_id = ? AND guest_user_id = ? AND alert_keyword <> ''", botID, userID).
+		Updates(map[string]interface{}{
+			"alert_keyword": "",
+			"alerted_at":    nil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *guestRepository) GetAlertedByBotID(botID uuid.UUID) ([]*models.Guest, error) {
+	var guests []*models.Guest
+	if err := r.db.Where("bot_id = ? AND alert_keyword <> ''", botID).
+		Order("alerted_at DESC").
+		Find(&guests).Error; err != nil {
+		return nil, err
+	}
+	return guests, nil
+}
+
+func (r *guestRepository) MarkAwaitingReply(botID uuid.UUID, userID int64, at time.Time) (bool, error) {
+	result := r.db.Model(&models.Guest{}).
+		Where("bot_id = ? AND guest_user_id = ? AND awaiting_reply_since IS NULL", botID, userID).
+		UpdateColumn("awaiting_reply_since", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *guestRepository) SetBlockedBot(botID uuid.UUID, userID int64, blocked bool) error {
+	query := r.db.Model(&models.Guest{}).Where("bot_id = ? AND guest_user_id = ?", botID, userID)
+	if !blocked {
+		return query.Where("blocked_bot_at IS NOT NULL").UpdateColumn("blocked_bot_at", nil).Error
+	}
+	// Keep the time of the first failure
+	return query.Where("blocked_bot_at IS NULL").UpdateColumn("blocked_bot_at", time.Now()).Error
+}
+
+func (r *guestRepository) MarkReplied(botID uuid.UUID, userID int64, at time.Time) error {
+	guest, err := r.GetByBotIDAndUserID(botID, userID)
+	if err != nil {
+		return err
+	}
+	if guest.AwaitingReplySince == nil {
+		return nil
+	}
+	waited := at.Sub(*guest.AwaitingReplySince)
+	if waited < 0 {
+		waited = 0
+	}
+	return r.db.Model(&models.Guest{}).
+		Where("id = ? AND awaiting_reply_since = ?", guest.ID, *guest.AwaitingReplySince).
+		UpdateColumns(map[string]interface{}{
+			"awaiting_reply_since":  nil,
+			"last_replied_at":       at,
+			"last_response_seconds": int(waited.Seconds()),
+		}).Error
+}
+
+func (r *guestRepository) CountAwaitingBefore(botID uuid.UUID, before time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Guest{}).
+		Where("bot_id = ? AND awaiting_reply_since < ?", botID, before).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *guestRepository) AverageResponseTime(botID uuid.UUID, since time.Time) (time.Duration, int64, error) {
+	var row struct {
+		AvgSeconds float64
+		Count      int64
+	}
+	if err := r.db.Model(&models.Guest{}).
+		Select("COALESCE(AVG(last_response_seconds), 0) AS avg_seconds, COUNT(*) AS count").
+		Where("bot_id = ? AND last_replied_at >= ?", botID, since).
+		Scan(&row).Error; err != nil {
+		return 0, 0, err
+	}
+	return time.Duration(row.AvgSeconds * float64(time.Second)), row.Count, nil
+}
+
+func (r *guestRepository) Update(guest *models.Guest) error {
+	if err := checkOwner(r.db, &models.Guest{}, guest.ID, guest.BotID); err != nil {
+		return err
+	}
+	return updateExisting(r.db, guest)
+}
+
+func (r *guestRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.Guest{}, id, botID)
 }
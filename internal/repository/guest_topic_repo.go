@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+type GuestTopicRepository interface {
+	Create(topic *models.GuestTopic) error
+	// GetByGuestAndChat returns the guest's topic in a recipient chat
+	GetByGuestAndChat(guestID uuid.UUID, recipientChatID int64) (*models.GuestTopic, error)
+	// GetByThread returns the topic with its guest, for routing staff messages in the topic
+	GetByThread(botID uuid.UUID, recipientChatID int64, threadID int64) (*models.GuestTopic, error)
+	// Delete forgets a topic, e.g. after staff deleted it in Telegram
+	Delete(botID uuid.UUID, id uuid.UUID) error
+}
+
+type guestTopicRepository struct {
+	db *gorm.DB
+}
+
+func NewGuestTopicRepository(db *gorm.DB) GuestTopicRepository {
+	return &guestTopicRepository{db: db}
+}
+
+func (r *guestTopicRepository) Create(topic *models.GuestTopic) error {
+	return r.db.Create(topic).Error
+}
+
+func (r *guestTopicRepository) GetByGuestAndChat(guestID uuid.UUID, recipientChatID int64) (*models.GuestTopic, error) {
+	var topic models.GuestTopic
+	if err := r.db.Where("guest_id = ? AND recipient_chat_id = ?", guestID, recipientChatID).
+		First(&topic).Error; err != nil {
+		return nil, err
+	}
+	return &topic, nil
+}
+
+func (r *guestTopicRepository) GetByThread(botID uuid.UUID, recipientChatID int64, threadID int64) (*models.GuestTopic, error) {
+	var topic models.GuestTopic
+	if err := r.db.Preload("Guest").
+		Where("bot_id = ? AND recipient_chat_id = ? AND thread_id = ?", botID, recipientChatID, threadID).
+		First(&topic).Error; err != nil {
+		return nil, err
+	}
+	return &topic, nil
+}
+
+func (r *guestTopicRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.GuestTopic{}, id, botID)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InboundMessageRepository interface {
+	// Record stores a guest message once; recording the same message again is a no-op
+	Record(message *models.InboundMessage) error
+	CountByBotID(botID uuid.UUID) (int64, error)
+	// CountByBotIDAndGuestChatID counts the messages received from one guest chat
+	CountByBotIDAndGuestChatID(botID uuid.UUID, guestChatID int64) (int64, error)
+	// CountByBotIDBetween counts messages received in [start, end) and their recipient copies
+	CountByBotIDBetween(botID uuid.UUID, start time.Time, end time.Time) (*InboundMessageCounts, error)
+	// GetByBotIDBetween returns up to limit messages received in [start, end), oldest first
+	GetByBotIDBetween(botID uuid.UUID, start time.Time, end time.Time, limit int) ([]*models.InboundMessage, error)
+	// TopGuestsByBotIDBetween returns the limit guest chats that sent the most
+	// messages in [start, end), most active first
+	TopGuestsByBotIDBetween(botID uuid.UUID, start time.Time, end time.Time, limit int) ([]GuestMessageCount, error)
+	// LastReceivedAt returns when the bot last received a guest message, or
+	// the zero time if it never did
+	LastReceivedAt(botID uuid.UUID) (time.Time, error)
+}
+
+// InboundMessageCounts aggregates inbound messages over a time range
+type InboundMessageCounts struct {
+	Messages int64 // Guest messages received
+	Copies   int64 // Recipient copies attempted
+	Failures int64 // Recipient copies that were not delivered
+}
+
+// GuestMessageCount is how many messages one guest chat sent
+type GuestMessageCount struct {
+	GuestChatID int64
+	Messages    int64
+}
+
+type inboundMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewInboundMessageRepository(db *gorm.DB) InboundMessageRepository {
+	return &inboundMessageRepository{db: db}
+}
+
+func (r *inboundMessageRepository) Record(message *models.InboundMessage) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(message).Error
+}
+
+func (r *inboundMessageRepository) CountByBotID(botID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.InboundMessage{}).
+		Where("bot_id = ?", botID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *inboundMessageRepository) CountByBotIDAndGuestChatID(botID uuid.UUID, guestChatID int64) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.InboundMessage{}).
+		Where("bot_id = ? AND guest_chat_id = ?", botID, guestChatID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *inboundMessageRepository) CountByBotIDBetween(botID uuid.UUID, start time.Time, end time.Time) (*InboundMessageCounts, error) {
+	var counts InboundMessageCounts
+	if err := r.db.Model(&models.InboundMessage{}).
+		Select("COUNT(*) AS messages, "+
+			"COALESCE(SUM(recipient_count), 0) AS copies, "+
+			"COALESCE(SUM(recipient_count - delivered_count), 0) AS failures").
+		Where("bot_id = ? AND created_at >= ? AND created_at < ?", botID, start, end).
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+func (r *inboundMessageRepository) GetByBotIDBetween(botID uuid.UUID, start time.Time, end time.Time, limit int) ([]*models.InboundMessage, error) {
+	var messages []*models.InboundMessage
+	if err := r.db.Where("bot_id = ? AND created_at >= ? AND created_at < ?", botID, start, end).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *inboundMessageRepository) TopGuestsByBotIDBetween(botID uuid.UUID, start time.Time, end time.Time, limit int) ([]GuestMessageCount, error) {
+	var counts []GuestMessageCount
+	if err := r.db.Model(&models.InboundMessage{}).
+		Select("guest_chat_id, COUNT(*) AS messages").
+		Where("bot_id = ? AND created_at >= ? AND created_at < ?", botID, start, end).
+		Group("guest_chat_id").
+		Order("messages DESC, guest_chat_id ASC").
+		Limit(limit).
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *inboundMessageRepository) LastReceivedAt(botID uuid.UUID) (time.Time, error) {
+	var messages []*models.InboundMessage
+	if err := r.db.Where("bot_id = ?", botID).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&messages).Error; err != nil {
+		return time.Time{}, err
+	}
+	if len(messages) == 0 {
+		return time.Time{}, nil
+	}
+	return messages[0].CreatedAt, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestInboundMessageTopGuests(t *testing.T) {
+	repo := NewInboundMessageRepository(newTestDB(t))
+	botID := uuid.New()
+	now := time.Now()
+
+	messages := map[int64]int{1: 2, 2: 3, 3: 1}
+	messageID := int64(0)
+	for guestChatID, count := range messages {
+		for i := 0; i < count; i++ {
+			messageID++
+			if err := repo.Record(&models.InboundMessage{BotID: botID, GuestChatID: guestChatID, GuestMessageID: messageID, CreatedAt: now.Add(-time.Hour)}); err != nil {
+				t.Fatalf("Record: %v", err)
+			}
+		}
+	}
+	// Messages outside the range and of other bots are not counted
+	repo.Record(&models.InboundMessage{BotID: botID, GuestChatID: 3, GuestMessageID: 100, CreatedAt: now.Add(-48 * time.Hour)})
+	repo.Record(&models.InboundMessage{BotID: botID, GuestChatID: 3, GuestMessageID: 101, CreatedAt: now.Add(-48 * time.Hour)})
+	repo.Record(&models.InboundMessage{BotID: uuid.New(), GuestChatID: 3, GuestMessageID: 102, CreatedAt: now.Add(-time.Hour)})
+
+	top, err := repo.TopGuestsByBotIDBetween(botID, now.Add(-24*time.Hour), now, 2)
+	if err != nil {
+		t.Fatalf("TopGuestsByBotIDBetween: %v", err)
+	}
+	want := []GuestMessageCount{{GuestChatID: 2, Messages: 3}, {GuestChatID: 1, Messages: 2}}
+	if len(top) != len(want) || top[0] != want[0] || top[1] != want[1] {
+		t.Fatalf("TopGuestsByBotIDBetween = %v, want %v", top, want)
+	}
+}
+
+func TestInboundMessageLastReceivedAt(t *testing.T) {
+	repo := NewInboundMessageRepository(newTestDB(t))
+	botID := uuid.New()
+	now := time.Now()
+
+	if last, err := repo.LastReceivedAt(botID); err != nil || !last.IsZero() {
+		t.Fatalf("LastReceivedAt without messages = %v, %v; want the zero time", last, err)
+	}
+
+	for i, age := range []time.Duration{48 * time.Hour, time.Hour, 24 * time.Hour} {
+		if err := repo.Record(&models.InboundMessage{BotID: botID, GuestChatID: 1, GuestMessageID: int64(i), CreatedAt: now.Add(-age)}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	// A newer message of another bot is ignored
+	repo.Record(&models.InboundMessage{BotID: uuid.New(), GuestChatID: 1, GuestMessageID: 100, CreatedAt: now})
+
+	last, err := repo.LastReceivedAt(botID)
+	if err != nil {
+		t.Fatalf("LastReceivedAt: %v", err)
+	}
+	if want := now.Add(-time.Hour); !last.Equal(want) {
+		t.Fatalf("LastReceivedAt = %v, want %v", last, want)
+	}
+}
@@ -1,18 +1,32 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"go-telegram-forwarder-bot/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MessageMappingRepository interface {
+	// Create stores the mapping unless one already exists for the same message
+	// and direction in the same target chat, e.g. when an update is handled twice
 	Create(mapping *models.MessageMapping) error
 	GetByID(id uuid.UUID) (*models.MessageMapping, error)
 	GetByGuestMessage(botID uuid.UUID, guestChatID int64, guestMessageID int64) (*models.MessageMapping, error)
 	GetAllByGuestMessage(botID uuid.UUID, guestChatID int64, guestMessageID int64) ([]*models.MessageMapping, error)
 	GetByRecipientMessage(botID uuid.UUID, recipientChatID int64, recipientMessageID int64) (*models.MessageMapping, error)
 	CountByBotIDAndDirection(botID uuid.UUID, direction models.MessageDirection) (int64, error)
+	// CountByBotID counts all stored mappings of a bot, in both directions
+	CountByBotID(botID uuid.UUID) (int64, error)
+	CountByBotIDAndDirectionBetween(botID uuid.UUID, direction models.MessageDirection, start time.Time, end time.Time) (int64, error)
+	// LastCreatedAt returns when the bot's last mapping in the direction was
+	// stored, or the zero time if there is none
+	LastCreatedAt(botID uuid.UUID, direction models.MessageDirection) (time.Time, error)
+	// DeleteOlderThan deletes up to limit of the bot's oldest mappings created
+	// before the given time and returns how many were deleted
+	DeleteOlderThan(botID uuid.UUID, before time.Time, limit int) (int64, error)
 }
 
 type messageMappingRepository struct {
@@ -24,7 +38,7 @@ func NewMessageMappingRepository(db *gorm.DB) MessageMappingRepository {
 }
 
 func (r *messageMappingRepository) Create(mapping *models.MessageMapping) error {
-	return r.db.Create(mapping).Error
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(mapping).Error
 }
 
 func (r *messageMappingRepository) GetByID(id uuid.UUID) (*models.MessageMapping, error) {
@@ -74,3 +88,55 @@ func (r *messageMappingRepository) CountByBotIDAndDirection(botID uuid.UUID, dir
 	}
 	return count, nil
 }
+
+func (r *messageMappingRepository) CountByBotIDAndDirectionBetween(botID uuid.UUID, direction models.MessageDirection, start time.Time, end time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.MessageMapping{}).
+		Where("bot_id = ? AND direction = ? AND created_at >= ? AND created_at < ?", botID, direction, start, end).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *messageMappingRepository) CountByBotID(botID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.MessageMapping{}).
+		Where("bot_id = ?", botID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *messageMappingRepository) LastCreatedAt(botID uuid.UUID, direction models.MessageDirection) (time.Time, error) {
+	var mappings []*models.MessageMapping
+	if err := r.db.Where("bot_id = ? AND direction = ?", botID, direction).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&mappings).Error; err != nil {
+		return time.Time{}, err
+	}
+	if len(mappings) == 0 {
+		return time.Time{}, nil
+	}
+	return mappings[0].CreatedAt, nil
+}
+
+func (r *messageMappingRepository) DeleteOlderThan(botID uuid.UUID, before time.Time, limit int) (int64, error) {
+	// Pick the batch through idx_bot_created, then delete by primary key, since
+	// DELETE ... LIMIT is not supported by every database
+	var ids []uuid.UUID
+	if err := r.db.Model(&models.MessageMapping{}).
+		Where("bot_id = ? AND created_at < ?", botID, before).
+		Order("created_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Where("id IN ?", ids).Delete(&models.MessageMapping{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestMessageMappingDeleteOlderThan(t *testing.T) {
+	repo := NewMessageMappingRepository(newTestDB(t))
+	botID, otherBotID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	for i, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, 36 * time.Hour, time.Hour} {
+		for _, id := range []uuid.UUID{botID, otherBotID} {
+			mapping := &models.MessageMapping{
+				BotID:              id,
+				GuestChatID:        1,
+				GuestMessageID:     int64(i),
+				RecipientChatID:    2,
+				RecipientMessageID: int64(i),
+				Direction:          models.MessageDirectionInbound,
+				CreatedAt:          now.Add(-age),
+			}
+			if err := repo.Create(mapping); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+	}
+
+	before := now.Add(-24 * time.Hour)
+	// The oldest go first, one batch at a time
+	if deleted, err := repo.DeleteOlderThan(botID, before, 2); err != nil || deleted != 2 {
+		t.Fatalf("DeleteOlderThan = %d, %v; want 2", deleted, err)
+	}
+	if _, err := repo.GetByGuestMessage(botID, 1, 2); err != nil {
+		t.Fatalf("the newest old mapping was deleted before the older ones: %v", err)
+	}
+	if deleted, err := repo.DeleteOlderThan(botID, before, 2); err != nil || deleted != 1 {
+		t.Fatalf("second DeleteOlderThan = %d, %v; want 1", deleted, err)
+	}
+	if deleted, err := repo.DeleteOlderThan(botID, before, 2); err != nil || deleted != 0 {
+		t.Fatalf("third DeleteOlderThan = %d, %v; want 0", deleted, err)
+	}
+
+	if count, _ := repo.CountByBotID(botID); count != 1 {
+		t.Fatalf("CountByBotID = %d, want the recent mapping left", count)
+	}
+	if count, _ := repo.CountByBotID(otherBotID); count != 4 {
+		t.Fatalf("CountByBotID of another bot = %d, want 4 untouched", count)
+	}
+}
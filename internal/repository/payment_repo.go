@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepository interface {
+	// Create records a payment. It reports false if the charge was already recorded,
+	// so a redelivered update does not grant credits twice.
+	Create(payment *models.Payment) (bool, error)
+	GetByChargeID(chargeID string) (*models.Payment, error)
+	// GetByBotID returns the bot's most recent payments, newest first
+	GetByBotID(botID uuid.UUID, limit int) ([]*models.Payment, error)
+	// MarkRefunded sets a paid payment to refunded; it reports whether this call changed it
+	MarkRefunded(id uuid.UUID) (bool, error)
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+func (r *paymentRepository) Create(payment *models.Payment) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(payment)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *paymentRepository) GetByChargeID(chargeID string) (*models.Payment, error) {
+	var payment models.Payment
+	if err := r.db.Where("telegram_payment_charge_id = ?", chargeID).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *paymentRepository) GetByBotID(botID uuid.UUID, limit int) ([]*models.Payment, error) {
+	var payments []*models.Payment
+	if err := r.db.Where("bot_id = ?", botID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) MarkRefunded(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.Payment{}).
+		Where("id = ? AND status = ?", id, models.PaymentStatusPaid).
+		Updates(map[string]interface{}{
+			"status":      models.PaymentStatusRefunded,
+			"refunded_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
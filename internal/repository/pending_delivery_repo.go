@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PendingDeliveryRepository interface {
+	// Create queues the delivery and reports whether it was not queued already
+	Create(delivery *models.PendingDelivery) (bool, error)
+	// GetDue returns up to limit of the bot's deliveries due at or before the
+	// given time, the longest waiting first
+	GetDue(botID uuid.UUID, before time.Time, limit int) ([]*models.PendingDelivery, error)
+	// GetByBotID returns up to limit of the bot's deliveries, oldest first
+	GetByBotID(botID uuid.UUID, limit int) ([]*models.PendingDelivery, error)
+	CountByBotID(botID uuid.UUID) (int64, error)
+	// Update stores the delivery's attempts, last error and next attempt time
+	Update(delivery *models.PendingDelivery) error
+	Delete(botID uuid.UUID, id uuid.UUID) error
+	// DeleteByBotID removes all of the bot's deliveries and returns how many there were
+	DeleteByBotID(botID uuid.UUID) (int64, error)
+}
+
+type pendingDeliveryRepository struct {
+	db     *gorm.DB
+	cipher *ContentCipher
+}
+
+func NewPendingDeliveryRepository(db *gorm.DB, cipher *ContentCipher) PendingDeliveryRepository {
+	return &pendingDeliveryRepository{db: db, cipher: cipher}
+}
+
+func (r *pendingDeliveryRepository) Create(delivery *models.PendingDelivery) (bool, error) {
+	if delivery.ID == uuid.Nil {
+		delivery.ID = uuid.New()
+	}
+	var queued bool
+	err := r.cipher.withEncrypted(delivery.BotID, delivery.ID, &delivery.Message, func() error {
+		result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+		queued = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return queued, nil
+}
+
+func (r *pendingDeliveryRepository) GetDue(botID uuid.UUID, before time.Time, limit int) ([]*models.PendingDelivery, error) {
+	var deliveries []*models.PendingDelivery
+	err := r.db.Where("bot_id = ? AND next_attempt_at <= ?", botID, before).
+		Order("next_attempt_at, created_at").
+		Limit(limit).
+		Find(&deliveries).Error
+	r.decrypt(deliveries)
+	return deliveries, err
+}
+
+func (r *pendingDeliveryRepository) GetByBotID(botID uuid.UUID, limit int) ([]*models.PendingDelivery, error) {
+	var deliveries []*models.PendingDelivery
+	err := r.db.Where("bot_id = ?", botID).
+		Order("created_at").
+		Limit(limit).
+		Find(&deliveries).Error
+	r.decrypt(deliveries)
+	return deliveries, err
+}
+
+func (r *pendingDeliveryRepository) CountByBotID(botID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PendingDelivery{}).Where("bot_id = ?", botID).Count(&count).Error
+	return count, err
+}
+
+func (r *pendingDeliveryRepository) Update(delivery *models.PendingDelivery) error {
+	// The message is left as stored; writing it back would store it unencrypted
+	return r.db.Model(&models.PendingDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"attempts":        delivery.Attempts,
+			"last_error":      delivery.LastError,
+			"next_attempt_at": delivery.NextAttemptAt,
+		}).Error
+}
+
+func (r *pendingDeliveryRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.PendingDelivery{}, id, botID)
+}
+
+func (r *pendingDeliveryRepository) DeleteByBotID(botID uuid.UUID) (int64, error) {
+	result := r.db.Where("bot_id = ?", botID).Delete(&models.PendingDelivery{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *pendingDeliveryRepository) decrypt(deliveries []*models.PendingDelivery) {
+	for _, delivery := range deliveries {
+		delivery.Message = r.cipher.decryptOrEmpty(delivery.BotID, delivery.ID, delivery.Message)
+	}
+}
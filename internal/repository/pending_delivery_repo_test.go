@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestPendingDeliveryQueue(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPendingDeliveryRepository(db, newTestCipher(t, db))
+	botID, recipientID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	due := &models.PendingDelivery{BotID: botID, RecipientID: recipientID, GuestChatID: 1, GuestMessageID: 10, Message: "{}", NextAttemptAt: now.Add(-time.Minute)}
+	later := &models.PendingDelivery{BotID: botID, RecipientID: recipientID, GuestChatID: 1, GuestMessageID: 11, Message: "{}", NextAttemptAt: now.Add(time.Hour)}
+	for _, delivery := range []*models.PendingDelivery{due, later} {
+		if queued, err := repo.Create(delivery); err != nil || !queued {
+			t.Fatalf("Create = %v, %v; want queued", queued, err)
+		}
+	}
+	// The same message for the same recipient is only queued once
+	duplicate := &models.PendingDelivery{BotID: botID, RecipientID: recipientID, GuestChatID: 1, GuestMessageID: 10, Message: "{}", NextAttemptAt: now}
+	if queued, err := repo.Create(duplicate); err != nil || queued {
+		t.Fatalf("Create duplicate = %v, %v; want not queued", queued, err)
+	}
+
+	got, err := repo.GetDue(botID, now, 10)
+	if err != nil {
+		t.Fatalf("GetDue: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != due.ID {
+		t.Fatalf("GetDue = %v, want only the due delivery", got)
+	}
+	if got, _ := repo.GetDue(uuid.New(), now, 10); len(got) != 0 {
+		t.Fatalf("GetDue for another bot = %v, want none", got)
+	}
+
+	if count, err := repo.CountByBotID(botID); err != nil || count != 2 {
+		t.Fatalf("CountByBotID = %d, %v; want 2", count, err)
+	}
+
+	if err := repo.Delete(botID, due.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	deleted, err := repo.DeleteByBotID(botID)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteByBotID = %d, %v; want 1", deleted, err)
+	}
+}
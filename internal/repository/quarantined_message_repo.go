@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+type QuarantinedMessageRepository interface {
+	Create(msg *models.QuarantinedMessage) error
+	// GetByBotIDAndID returns the bot's quarantined message
+	GetByBotIDAndID(botID uuid.UUID, id uuid.UUID) (*models.QuarantinedMessage, error)
+	// Take deletes the bot's quarantined message and reports whether it was
+	// still there, so only one of several staff members can decide it
+	Take(botID uuid.UUID, id uuid.UUID) (bool, error)
+}
+
+type quarantinedMessageRepository struct {
+	db     *gorm.DB
+	cipher *ContentCipher
+}
+
+func NewQuarantinedMessageRepository(db *gorm.DB, cipher *ContentCipher) QuarantinedMessageRepository {
+	return &quarantinedMessageRepository{db: db, cipher: cipher}
+}
+
+func (r *quarantinedMessageRepository) Create(msg *models.QuarantinedMessage) error {
+	if msg.ID == uuid.Nil {
+		msg.ID = uuid.New()
+	}
+	return r.cipher.withEncrypted(msg.BotID, msg.ID, &msg.Message, func() error {
+		return r.db.Create(msg).Error
+	})
+}
+
+func (r *quarantinedMessageRepository) GetByBotIDAndID(botID uuid.UUID, id uuid.UUID) (*models.QuarantinedMessage, error) {
+	var msg models.QuarantinedMessage
+	if err := r.db.Where("id = ? AND bot_id = ?", id, botID).First(&msg).Error; err != nil {
+		return nil, err
+	}
+	msg.Message = r.cipher.decryptOrEmpty(msg.BotID, msg.ID, msg.Message)
+	return &msg, nil
+}
+
+func (r *quarantinedMessageRepository) Take(botID uuid.UUID, id uuid.UUID) (bool, error) {
+	result := r.db.Where("id = ? AND bot_id = ?", id, botID).Delete(&models.QuarantinedMessage{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -11,9 +11,14 @@ type RecipientRepository interface {
 	GetByID(id uuid.UUID) (*models.Recipient, error)
 	GetByBotID(botID uuid.UUID) ([]*models.Recipient, error)
 	GetByBotIDAndChatID(botID uuid.UUID, chatID int64) (*models.Recipient, error)
+	// Update saves the recipient; it returns ErrNotOwned if the stored
+	// recipient belongs to a bot other than recipient.BotID
 	Update(recipient *models.Recipient) error
-	Delete(id uuid.UUID) error
+	// Delete removes the bot's recipient; it returns ErrNotOwned for another bot's recipient
+	Delete(botID uuid.UUID, id uuid.UUID) error
 	DeleteByBotIDAndChatID(botID uuid.UUID, chatID int64) error
+	// SetFallback makes recipientID the bot's only fallback recipient, or clears it if nil
+	SetFallback(botID uuid.UUID, recipientID *uuid.UUID) error
 	WithTx(tx *gorm.DB) RecipientRepository
 }
 
@@ -54,17 +59,36 @@ func (r *recipientRepository) GetByBotIDAndChatID(botID uuid.UUID, chatID int64)
 }
 
 func (r *recipientRepository) Update(recipient *models.Recipient) error {
-	return r.db.Save(recipient).Error
+	if err := checkOwner(r.db, &models.Recipient{}, recipient.ID, recipient.BotID); err != nil {
+		return err
+	}
+	return updateExisting(r.db, recipient)
 }
 
-func (r *recipientRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Recipient{}, "id = ?", id).Error
+func (r *recipientRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.Recipient{}, id, botID)
 }
 
 func (r *recipientRepository) DeleteByBotIDAndChatID(botID uuid.UUID, chatID int64) error {
 	return r.db.Where("bot_id = ? AND chat_id = ?", botID, chatID).Delete(&models.Recipient{}).Error
 }
 
+func (r *recipientRepository) SetFallback(botID uuid.UUID, recipientID *uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Recipient{}).
+			Where("bot_id = ? AND is_fallback = ?", botID, true).
+			Update("is_fallback", false).Error; err != nil {
+			return err
+		}
+		if recipientID == nil {
+			return nil
+		}
+		return tx.Model(&models.Recipient{}).
+			Where("id = ? AND bot_id = ?", *recipientID, botID).
+			Update("is_fallback", true).Error
+	})
+}
+
 func (r *recipientRepository) WithTx(tx *gorm.DB) RecipientRepository {
 	return &recipientRepository{db: tx}
 }
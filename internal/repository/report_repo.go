@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+type ReportRepository interface {
+	Create(report *models.Report) error
+	GetByID(id uuid.UUID) (*models.Report, error)
+	GetByStatus(status models.ReportStatus, limit int) ([]*models.Report, error)
+	GetOpenByBotIDAndReporter(botID uuid.UUID, reporterUserID int64) (*models.Report, error)
+	// CloseOpen moves an open report to a final status.
+	// Returns false if the report was no longer open.
+	CloseOpen(id uuid.UUID, status models.ReportStatus, resolvedBy uuid.UUID) (bool, error)
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+func (r *reportRepository) Create(report *models.Report) error {
+	return r.db.Create(report).Error
+}
+
+func (r *reportRepository) GetByID(id uuid.UUID) (*models.Report, error) {
+	var report models.Report
+	if err := r.db.Preload("Bot").Preload("Bot.Manager").Preload("ResolvedBy").
+		First(&report, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *reportRepository) GetByStatus(status models.ReportStatus, limit int) ([]*models.Report, error) {
+	var reports []*models.Report
+	query := r.db.Where("status = ?", status).Order("created_at ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Preload("Bot").Preload("Bot.Manager").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (r *reportRepository) GetOpenByBotIDAndReporter(botID uuid.UUID, reporterUserID int64) (*models.Report, error) {
+	var report models.Report
+	if err := r.db.Where("bot_id = ? AND reporter_user_id = ? AND status = ?",
+		botID, reporterUserID, models.ReportStatusOpen).
+		First(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *reportRepository) CloseOpen(id uuid.UUID, status models.ReportStatus, resolvedBy uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.Report{}).
+		Where("id = ? AND status = ?", id, models.ReportStatusOpen).
+		Updates(map[string]interface{}{
+			"status":              status,
+			"resolved_by_user_id": resolvedBy,
+			"resolved_at":         &now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+type RoutingRuleRepository interface {
+	Create(rule *models.RoutingRule) error
+	// GetByBotID returns the bot's rules in the order they were added
+	GetByBotID(botID uuid.UUID) ([]*models.RoutingRule, error)
+	// Delete removes the bot's rule; it returns ErrNotOwned for another bot's rule
+	Delete(botID uuid.UUID, id uuid.UUID) error
+}
+
+type routingRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoutingRuleRepository(db *gorm.DB) RoutingRuleRepository {
+	return &routingRuleRepository{db: db}
+}
+
+func (r *routingRuleRepository) Create(rule *models.RoutingRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *routingRuleRepository) GetByBotID(botID uuid.UUID) ([]*models.RoutingRule, error) {
+	var rules []*models.RoutingRule
+	if err := r.db.Where("bot_id = ?", botID).
+		Order("created_at ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *routingRuleRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.RoutingRule{}, id, botID)
+}
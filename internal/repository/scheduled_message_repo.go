@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+type ScheduledMessageRepository interface {
+	Create(message *models.ScheduledMessage) error
+	// GetDue returns up to limit of the bot's held messages due at or before
+	// the given time, the earliest first
+	GetDue(botID uuid.UUID, before time.Time, limit int) ([]*models.ScheduledMessage, error)
+	// GetByGuest returns the messages a guest has waiting, the earliest due first
+	GetByGuest(botID uuid.UUID, guestChatID int64) ([]*models.ScheduledMessage, error)
+	CountByGuest(botID uuid.UUID, guestChatID int64) (int64, error)
+	// Reschedule stores the message's attempts and due time. A message deleted
+	// meanwhile, e.g. with /later cancel, stays deleted.
+	Reschedule(message *models.ScheduledMessage) error
+	Delete(botID uuid.UUID, id uuid.UUID) error
+	// DeleteByGuest removes the messages a guest has waiting and returns how many there were
+	DeleteByGuest(botID uuid.UUID, guestChatID int64) (int64, error)
+}
+
+type scheduledMessageRepository struct {
+	db     *gorm.DB
+	cipher *ContentCipher
+}
+
+func NewScheduledMessageRepository(db *gorm.DB, cipher *ContentCipher) ScheduledMessageRepository {
+	return &scheduledMessageRepository{db: db, cipher: cipher}
+}
+
+func (r *scheduledMessageRepository) Create(message *models.ScheduledMessage) error {
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
+	return r.cipher.withEncrypted(message.BotID, message.ID, &message.Message, func() error {
+		return r.db.Create(message).Error
+	})
+}
+
+func (r *scheduledMessageRepository) GetDue(botID uuid.UUID, before time.Time, limit int) ([]*models.ScheduledMessage, error) {
+	var messages []*models.ScheduledMessage
+	err := r.db.Where("bot_id = ? AND due_at <= ?", botID, before).
+		Order("due_at, created_at").
+		Limit(limit).
+		Find(&messages).Error
+	r.decrypt(messages)
+	return messages, err
+}
+
+func (r *scheduledMessageRepository) GetByGuest(botID uuid.UUID, guestChatID int64) ([]*models.ScheduledMessage, error) {
+	var messages []*models.ScheduledMessage
+	err := r.db.Where("bot_id = ? AND guest_chat_id = ?", botID, guestChatID).
+		Order("due_at, created_at").
+		Find(&messages).Error
+	r.decrypt(messages)
+	return messages, err
+}
+
+func (r *scheduledMessageRepository) CountByGuest(botID uuid.UUID, guestChatID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ScheduledMessage{}).
+		Where("bot_id = ? AND guest_chat_id = ?", botID, guestChatID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *scheduledMessageRepository) Reschedule(message *models.ScheduledMessage) error {
+	return r.db.Model(&models.ScheduledMessage{}).
+		Where("id = ?", message.ID).
+		Updates(map[string]interface{}{
+			"attempts": message.Attempts,
+			"due_at":   message.DueAt,
+		}).Error
+}
+
+func (r *scheduledMessageRepository) Delete(botID uuid.UUID, id uuid.UUID) error {
+	return deleteOwned(r.db, &models.ScheduledMessage{}, id, botID)
+}
+
+func (r *scheduledMessageRepository) DeleteByGuest(botID uuid.UUID, guestChatID int64) (int64, error) {
+	result := r.db.Where("bot_id = ? AND guest_chat_id = ?", botID, guestChatID).Delete(&models.ScheduledMessage{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *scheduledMessageRepository) decrypt(messages []*models.ScheduledMessage) {
+	for _, message := range messages {
+		message.Message = r.cipher.decryptOrEmpty(message.BotID, message.ID, message.Message)
+	}
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestScheduledMessages(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewScheduledMessageRepository(db, newTestCipher(t, db))
+	botID := uuid.New()
+	now := time.Now()
+
+	due := &models.ScheduledMessage{BotID: botID, GuestChatID: 1, GuestMessageID: 10, Message: "{}", DueAt: now.Add(-time.Minute)}
+	later := &models.ScheduledMessage{BotID: botID, GuestChatID: 1, GuestMessageID: 11, Message: "{}", DueAt: now.Add(time.Hour)}
+	other := &models.ScheduledMessage{BotID: botID, GuestChatID: 2, GuestMessageID: 12, Message: "{}", DueAt: now.Add(-time.Hour)}
+	for _, message := range []*models.ScheduledMessage{later, due, other} {
+		if err := repo.Create(message); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	got, err := repo.GetDue(botID, now, 10)
+	if err != nil {
+		t.Fatalf("GetDue: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != other.ID || got[1].ID != due.ID {
+		t.Fatalf("GetDue = %v, want the two due messages, earliest first", got)
+	}
+	if got, _ := repo.GetDue(uuid.New(), now, 10); len(got) != 0 {
+		t.Fatalf("GetDue for another bot = %v, want none", got)
+	}
+
+	waiting, err := repo.GetByGuest(botID, 1)
+	if err != nil || len(waiting) != 2 || waiting[0].ID != due.ID {
+		t.Fatalf("GetByGuest = %v, %v; want the guest's two messages, earliest first", waiting, err)
+	}
+	if count, err := repo.CountByGuest(botID, 1); err != nil || count != 2 {
+		t.Fatalf("CountByGuest = %d, %v; want 2", count, err)
+	}
+
+	due.Attempts = 1
+	due.DueAt = now.Add(time.Hour)
+	if err := repo.Reschedule(due); err != nil {
+		t.Fatalf("Reschedule: %v", err)
+	}
+	if got, _ := repo.GetDue(botID, now, 10); len(got) != 1 || got[0].ID != other.ID {
+		t.Fatalf("GetDue after Reschedule = %v, want only the other message", got)
+	}
+
+	if err := repo.Delete(uuid.New(), other.ID); err == nil {
+		t.Fatalf("Delete by another bot succeeded")
+	}
+	if err := repo.Delete(botID, other.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	deleted, err := repo.DeleteByGuest(botID, 1)
+	if err != nil || deleted != 2 {
+		t.Fatalf("DeleteByGuest = %d, %v; want 2", deleted, err)
+	}
+
+	// Rescheduling a message cancelled meanwhile must not bring it back
+	if err := repo.Reschedule(due); err != nil {
+		t.Fatalf("Reschedule of a deleted message: %v", err)
+	}
+	if count, _ := repo.CountByGuest(botID, 1); count != 0 {
+		t.Fatalf("CountByGuest after rescheduling a deleted message = %d, want 0", count)
+	}
+}
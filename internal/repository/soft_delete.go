@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Soft-deleted models (those with a gorm.DeletedAt field) follow three rules:
+//
+//   - Queries rely on GORM's default scope to hide deleted rows. Do not add
+//     "deleted_at IS NULL" by hand; it is easy to forget in one query and makes
+//     the others look like the odd ones out.
+//   - Deleted rows are only read with Unscoped, in methods whose name says so.
+//   - Updates go through updateExisting rather than Save. Save writes every
+//     column, including deleted_at, so saving a record that was deleted in the
+//     meantime would bring it back, possibly next to a newer row for the same
+//     bot and chat.
+
+// updateExisting writes every column of a soft-deletable record that has not
+// been deleted. It returns gorm.ErrRecordNotFound instead of inserting or
+// restoring the row when the record is gone. Associations are not saved.
+func updateExisting(db *gorm.DB, value interface{}) error {
+	result := db.Select("*").Omit("created_at", "deleted_at", clause.Associations).Updates(value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
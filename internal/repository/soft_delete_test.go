@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestRecipientUpdateDoesNotRestoreDeletedRow(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewRecipientRepository(db)
+	botID := uuid.New()
+
+	stale := &models.Recipient{BotID: botID, ChatID: 100, RecipientType: models.RecipientTypeUser}
+	if err := repo.Create(stale); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.DeleteByBotIDAndChatID(botID, 100); err != nil {
+		t.Fatalf("DeleteByBotIDAndChatID: %v", err)
+	}
+	if _, err := repo.GetByBotIDAndChatID(botID, 100); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByBotIDAndChatID after delete: got %v, want ErrRecordNotFound", err)
+	}
+
+	// The chat can be added again once the old recipient is deleted
+	current := &models.Recipient{BotID: botID, ChatID: 100, RecipientType: models.RecipientTypeUser}
+	if err := repo.Create(current); err != nil {
+		t.Fatalf("Create after delete: %v", err)
+	}
+
+	// A handler still holding the deleted recipient must not bring it back
+	alias := "stale"
+	stale.Alias = &alias
+	if err := repo.Update(stale); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Update of deleted recipient: got %v, want ErrRecordNotFound", err)
+	}
+
+	var active int64
+	if err := db.Model(&models.Recipient{}).Where("bot_id = ? AND chat_id = ?", botID, 100).Count(&active).Error; err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if active != 1 {
+		t.Fatalf("active recipients for the chat = %d, want 1", active)
+	}
+	got, err := repo.GetByBotIDAndChatID(botID, 100)
+	if err != nil {
+		t.Fatalf("GetByBotIDAndChatID: %v", err)
+	}
+	if got.ID != current.ID || got.Alias != nil {
+		t.Fatalf("GetByBotIDAndChatID returned %s (alias %q), want the re-added recipient %s", got.ID, got.DisplayName(), current.ID)
+	}
+	if recipients, _ := repo.GetByBotID(botID); len(recipients) != 1 {
+		t.Fatalf("GetByBotID returned %d recipients, want 1", len(recipients))
+	}
+}
+
+func TestBotAdminSoftDelete(t *testing.T) {
+	repo := NewBotAdminRepository(newTestDB(t))
+	botID, userID := uuid.New(), uuid.New()
+
+	admin := &models.BotAdmin{BotID: botID, AdminUserID: userID}
+	if err := repo.Create(admin); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.Create(&models.BotAdmin{BotID: botID, AdminUserID: userID}); !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Fatalf("Create of duplicate admin: got %v, want ErrDuplicatedKey", err)
+	}
+
+	if err := repo.DeleteByBotIDAndUserID(botID, userID); err != nil {
+		t.Fatalf("DeleteByBotIDAndUserID: %v", err)
+	}
+	if isAdmin, _ := repo.IsAdmin(botID, userID); isAdmin {
+		t.Fatal("deleted admin is still an admin")
+	}
+	if _, err := repo.GetByBotIDAndUserID(botID, userID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByBotIDAndUserID after delete: got %v, want ErrRecordNotFound", err)
+	}
+	if admins, _ := repo.GetByBotID(botID); len(admins) != 0 {
+		t.Fatalf("GetByBotID returned %d admins, want 0", len(admins))
+	}
+
+	// Deleted admins do not block adding the user again
+	if err := repo.Create(&models.BotAdmin{BotID: botID, AdminUserID: userID}); err != nil {
+		t.Fatalf("Create after delete: %v", err)
+	}
+	if isAdmin, _ := repo.IsAdmin(botID, userID); !isAdmin {
+		t.Fatal("re-added admin is not an admin")
+	}
+}
+
+func TestBlacklistIgnoresDeletedRequests(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewBlacklistRepository(db)
+	botID, guestID := uuid.New(), uuid.New()
+
+	ban := &models.Blacklist{
+		BotID:         botID,
+		GuestID:       guestID,
+		RequestUserID: uuid.New(),
+		RequestType:   models.BlacklistRequestTypeBan,
+		Status:        models.BlacklistStatusApproved,
+	}
+	if err := repo.Create(ban); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := db.Delete(ban).Error; err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := repo.GetLatestByBotIDAndGuestID(botID, guestID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetLatestByBotIDAndGuestID: got %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetActiveByBotIDAndGuestID(botID, guestID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetActiveByBotIDAndGuestID: got %v, want ErrRecordNotFound", err)
+	}
+	if all, _ := repo.GetAllByBotIDAndGuestID(botID, guestID); len(all) != 0 {
+		t.Fatalf("GetAllByBotIDAndGuestID returned %d requests, want 0", len(all))
+	}
+
+	ban.Status = models.BlacklistStatusRejected
+	if err := repo.Update(ban); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Update of deleted request: got %v, want ErrRecordNotFound", err)
+	}
+}
+
+func TestBotUpdate(t *testing.T) {
+	repo := NewBotRepository(newTestDB(t))
+
+	bot := &models.ForwarderBot{Token: "token", ManagerID: uuid.New(), AutoLeaveGroups: true}
+	if err := repo.Create(bot); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Zero values are written like any other value
+	bot.AutoLeaveGroups = false
+	if err := repo.Update(bot); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := repo.GetByID(bot.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.AutoLeaveGroups {
+		t.Fatal("Update did not write AutoLeaveGroups = false")
+	}
+
+	if err := repo.Delete(bot.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	bot.Name = "renamed"
+	if err := repo.Update(bot); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Update of deleted bot: got %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetByID(bot.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("deleted bot was restored: GetByID returned %v", err)
+	}
+	if _, err := repo.GetByToken("token"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByToken of deleted bot: got %v, want ErrRecordNotFound", err)
+	}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StatsDailyRepository interface {
+	// Upsert creates or replaces the rollup for the stat's bot and day
+	Upsert(stat *models.StatsDaily) error
+	// GetByBotIDAndDayRange returns rollups with fromDay <= day <= toDay, oldest first
+	GetByBotIDAndDayRange(botID uuid.UUID, fromDay string, toDay string) ([]*models.StatsDaily, error)
+	// GetByDayRange returns rollups of all bots with fromDay <= day <= toDay, oldest first
+	GetByDayRange(fromDay string, toDay string) ([]*models.StatsDaily, error)
+}
+
+type statsDailyRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsDailyRepository(db *gorm.DB) StatsDailyRepository {
+	return &statsDailyRepository{db: db}
+}
+
+func (r *statsDailyRepository) Upsert(stat *models.StatsDaily) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "bot_id"}, {Name: "day"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"inbound_count", "outbound_count", "new_guest_count", "copy_count", "failure_count", "updated_at",
+		}),
+	}).Create(stat).Error
+}
+
+func (r *statsDailyRepository) GetByBotIDAndDayRange(botID uuid.UUID, fromDay string, toDay string) ([]*models.StatsDaily, error) {
+	var stats []*models.StatsDaily
+	if err := r.db.Where("bot_id = ? AND day >= ? AND day <= ?", botID, fromDay, toDay).
+		Order("day ASC").
+		Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *statsDailyRepository) GetByDayRange(fromDay string, toDay string) ([]*models.StatsDaily, error) {
+	var stats []*models.StatsDaily
+	if err := r.db.Where("day >= ? AND day <= ?", fromDay, toDay).
+		Order("day ASC").
+		Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
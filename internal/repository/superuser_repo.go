@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SuperuserRepository interface {
+	GetAll() ([]*models.Superuser, error)
+	// Add stores the superuser and reports whether they were not one already
+	Add(superuser *models.Superuser) (bool, error)
+	// Delete removes the superuser and reports whether they were one
+	Delete(telegramUserID int64) (bool, error)
+	// DeleteConfiguredExcept removes the superusers added from the config
+	// that are not in keep, returning how many were removed. Superusers
+	// added with /addsuper are kept.
+	DeleteConfiguredExcept(keep []int64) (int64, error)
+}
+
+type superuserRepository struct {
+	db *gorm.DB
+}
+
+func NewSuperuserRepository(db *gorm.DB) SuperuserRepository {
+	return &superuserRepository{db: db}
+}
+
+func (r *superuserRepository) GetAll() ([]*models.Superuser, error) {
+	var superusers []*models.Superuser
+	err := r.db.Order("created_at").Find(&superusers).Error
+	return superusers, err
+}
+
+func (r *superuserRepository) Add(superuser *models.Superuser) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(superuser)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *superuserRepository) Delete(telegramUserID int64) (bool, error) {
+	result := r.db.Delete(&models.Superuser{}, "telegram_user_id = ?", telegramUserID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *superuserRepository) DeleteConfiguredExcept(keep []int64) (int64, error) {
+	query := r.db.Where("added_by_id IS NULL")
+	if len(keep) > 0 {
+		query = query.Where("telegram_user_id NOT IN ?", keep)
+	}
+	result := query.Delete(&models.Superuser{})
+	return result.RowsAffected, result.Error
+}
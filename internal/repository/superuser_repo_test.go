@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestSuperuserAddAndDelete(t *testing.T) {
+	repo := NewSuperuserRepository(newTestDB(t))
+
+	added, err := repo.Add(&models.Superuser{TelegramUserID: 42})
+	if err != nil || !added {
+		t.Fatalf("Add = %v, %v; want added", added, err)
+	}
+	// Adding an existing superuser, as happens on every start, is not an error
+	added, err = repo.Add(&models.Superuser{TelegramUserID: 42})
+	if err != nil || added {
+		t.Fatalf("Add again = %v, %v; want not added", added, err)
+	}
+
+	superusers, err := repo.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(superusers) != 1 || superusers[0].TelegramUserID != 42 {
+		t.Fatalf("GetAll = %v, want only 42", superusers)
+	}
+
+	removed, err := repo.Delete(42)
+	if err != nil || !removed {
+		t.Fatalf("Delete = %v, %v; want removed", removed, err)
+	}
+	removed, err = repo.Delete(42)
+	if err != nil || removed {
+		t.Fatalf("Delete again = %v, %v; want not removed", removed, err)
+	}
+}
+
+func TestSuperuserDeleteConfiguredExcept(t *testing.T) {
+	repo := NewSuperuserRepository(newTestDB(t))
+
+	addedBy := uuid.New()
+	for _, superuser := range []*models.Superuser{
+		{TelegramUserID: 1},
+		{TelegramUserID: 2},
+		{TelegramUserID: 3, AddedByID: &addedBy},
+	} {
+		if _, err := repo.Add(superuser); err != nil {
+			t.Fatalf("Add %d: %v", superuser.TelegramUserID, err)
+		}
+	}
+
+	// 2 left the config; 3 was added with /addsuper and stays
+	removed, err := repo.DeleteConfiguredExcept([]int64{1})
+	if err != nil || removed != 1 {
+		t.Fatalf("DeleteConfiguredExcept = %d, %v; want 1 removed", removed, err)
+	}
+
+	superusers, err := repo.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	ids := make([]int64, 0, len(superusers))
+	for _, superuser := range superusers {
+		ids = append(ids, superuser.TelegramUserID)
+	}
+	if len(ids) != 2 || !slices.Contains(ids, 1) || !slices.Contains(ids, 3) {
+		t.Fatalf("superusers = %v, want 1 and 3", ids)
+	}
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrNotOwned is returned when a record passed to a bot-scoped method belongs
+// to another bot. Callers look records up by bot, so this means a bug rather
+// than user error; it keeps one bot's commands from changing another's data.
+var ErrNotOwned = errors.New("record belongs to another bot")
+
+// checkOwner returns ErrNotOwned if the record of model with the id belongs to
+// a bot other than botID, or gorm.ErrRecordNotFound if there is no such record
+func checkOwner(db *gorm.DB, model interface{}, id uuid.UUID, botID uuid.UUID) error {
+	var owner struct {
+		BotID uuid.UUID
+	}
+	if err := db.Model(model).Select("bot_id").Where("id = ?", id).Take(&owner).Error; err != nil {
+		return err
+	}
+	if owner.BotID != botID {
+		return ErrNotOwned
+	}
+	return nil
+}
+
+// deleteOwned deletes the record of model with the id if it belongs to botID
+func deleteOwned(db *gorm.DB, model interface{}, id uuid.UUID, botID uuid.UUID) error {
+	if err := checkOwner(db, model, id, botID); err != nil {
+		return err
+	}
+	return db.Where("id = ? AND bot_id = ?", id, botID).Delete(model).Error
+}
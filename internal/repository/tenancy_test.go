@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"go-telegram-forwarder-bot/internal/database"
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	// Every connection to :memory: is a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestRecipientRepositoryTenancy(t *testing.T) {
+	repo := NewRecipientRepository(newTestDB(t))
+	botA, botB := uuid.New(), uuid.New()
+
+	recipient := &models.Recipient{BotID: botA, ChatID: 100, RecipientType: models.RecipientTypeUser}
+	if err := repo.Create(recipient); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.Delete(botB, recipient.ID); !errors.Is(err, ErrNotOwned) {
+		t.Fatalf("Delete from another bot: got %v, want ErrNotOwned", err)
+	}
+	if _, err := repo.GetByID(recipient.ID); err != nil {
+		t.Fatalf("recipient was deleted by another bot: %v", err)
+	}
+
+	moved := *recipient
+	moved.BotID = botB
+	stolen := "stolen"
+	moved.Alias = &stolen
+	if err := repo.Update(&moved); !errors.Is(err, ErrNotOwned) {
+		t.Fatalf("Update moving to another bot: got %v, want ErrNotOwned", err)
+	}
+	stored, err := repo.GetByID(recipient.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.BotID != botA || stored.Alias != nil {
+		t.Fatalf("recipient was changed through another bot: bot %s, alias %q", stored.BotID, stored.DisplayName())
+	}
+
+	if err := repo.SetFallback(botB, &recipient.ID); err != nil {
+		t.Fatalf("SetFallback: %v", err)
+	}
+	if stored, _ := repo.GetByID(recipient.ID); stored.IsFallback {
+		t.Fatal("another bot made the recipient its fallback")
+	}
+
+	support := "support"
+	recipient.Alias = &support
+	if err := repo.Update(recipient); err != nil {
+		t.Fatalf("Update by owner: %v", err)
+	}
+	if err := repo.Delete(botA, recipient.ID); err != nil {
+		t.Fatalf("Delete by owner: %v", err)
+	}
+	if err := repo.Delete(botA, recipient.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Delete of deleted recipient: got %v, want ErrRecordNotFound", err)
+	}
+}
+
+func TestAutoReplyRepositoryTenancy(t *testing.T) {
+	repo := NewAutoReplyRepository(newTestDB(t))
+	botA, botB := uuid.New(), uuid.New()
+
+	rule := &models.AutoReply{BotID: botA, Keywords: "price", Reply: "See the pinned message"}
+	if err := repo.Create(rule); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.RecordHit(botB, rule.ID); err != nil {
+		t.Fatalf("RecordHit: %v", err)
+	}
+	if err := repo.Delete(botB, rule.ID); !errors.Is(err, ErrNotOwned) {
+		t.Fatalf("Delete from another bot: got %v, want ErrNotOwned", err)
+	}
+	rules, err := repo.GetByBotID(botA)
+	if err != nil {
+		t.Fatalf("GetByBotID: %v", err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("rule was deleted by another bot")
+	}
+	if rules[0].HitCount != 0 {
+		t.Fatalf("another bot counted a hit: HitCount = %d", rules[0].HitCount)
+	}
+	if rules, _ := repo.GetByBotID(botB); len(rules) != 0 {
+		t.Fatalf("another bot sees %d rules, want 0", len(rules))
+	}
+
+	if err := repo.RecordHit(botA, rule.ID); err != nil {
+		t.Fatalf("RecordHit by owner: %v", err)
+	}
+	if err := repo.Delete(botA, rule.ID); err != nil {
+		t.Fatalf("Delete by owner: %v", err)
+	}
+}
+
+func TestBotAdminRepositoryTenancy(t *testing.T) {
+	repo := NewBotAdminRepository(newTestDB(t))
+	botA, botB := uuid.New(), uuid.New()
+	user := uuid.New()
+
+	admin := &models.BotAdmin{BotID: botA, AdminUserID: user}
+	if err := repo.Create(admin); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.Delete(botB, admin.ID); !errors.Is(err, ErrNotOwned) {
+		t.Fatalf("Delete from another bot: got %v, want ErrNotOwned", err)
+	}
+	if isAdmin, err := repo.IsAdmin(botA, user); err != nil || !isAdmin {
+		t.Fatalf("admin was removed by another bot: isAdmin=%v err=%v", isAdmin, err)
+	}
+	if isAdmin, _ := repo.IsAdmin(botB, user); isAdmin {
+		t.Fatal("admin of one bot is admin of another")
+	}
+
+	if err := repo.Delete(botA, admin.ID); err != nil {
+		t.Fatalf("Delete by owner: %v", err)
+	}
+	if isAdmin, _ := repo.IsAdmin(botA, user); isAdmin {
+		t.Fatal("admin still present after Delete by owner")
+	}
+}
+
+func TestGuestRepositoryTenancy(t *testing.T) {
+	repo := NewGuestRepository(newTestDB(t))
+	botA, botB := uuid.New(), uuid.New()
+
+	guest, err := repo.GetOrCreateByBotIDAndUserID(botA, 42)
+	if err != nil {
+		t.Fatalf("GetOrCreateByBotIDAndUserID: %v", err)
+	}
+
+	moved := *guest
+	moved.BotID = botB
+	if err := repo.Update(&moved); !errors.Is(err, ErrNotOwned) {
+		t.Fatalf("Update moving to another bot: got %v, want ErrNotOwned", err)
+	}
+	if err := repo.Delete(botB, guest.ID); !errors.Is(err, ErrNotOwned) {
+		t.Fatalf("Delete from another bot: got %v, want ErrNotOwned", err)
+	}
+	stored, err := repo.GetByID(guest.ID)
+	if err != nil {
+		t.Fatalf("guest was deleted by another bot: %v", err)
+	}
+	if stored.BotID != botA {
+		t.Fatalf("guest moved to bot %s", stored.BotID)
+	}
+
+	// The same Telegram user is a separate guest of each bot
+	other, err := repo.GetOrCreateByBotIDAndUserID(botB, 42)
+	if err != nil {
+		t.Fatalf("GetOrCreateByBotIDAndUserID: %v", err)
+	}
+	if other.ID == guest.ID {
+		t.Fatal("two bots share one guest record")
+	}
+
+	guest.TermsAcceptedVersion = 2
+	if err := repo.Update(guest); err != nil {
+		t.Fatalf("Update by owner: %v", err)
+	}
+
+	// Updating a removed guest does not bring it back
+	if err := repo.Delete(botA, guest.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Update(guest); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Update of a removed guest: got %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetByID(guest.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("removed guest was stored again: %v", err)
+	}
+}
+
+func TestBlacklistRepositoryTenancy(t *testing.T) {
+	repo := NewBlacklistRepository(newTestDB(t))
+	botA, botB := uuid.New(), uuid.New()
+	guestID := uuid.New()
+
+	blacklist := &models.Blacklist{
+		BotID:         botA,
+		GuestID:       guestID,
+		RequestUserID: uuid.New(),
+		RequestType:   models.BlacklistRequestTypeBan,
+		Status:        models.BlacklistStatusPending,
+	}
+	if err := repo.Create(blacklist); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	moved := *blacklist
+	moved.BotID = botB
+	moved.Status = models.BlacklistStatusRejected
+	if err := repo.Update(&moved); !errors.Is(err, ErrNotOwned) {
+		t.Fatalf("Update moving to another bot: got %v, want ErrNotOwned", err)
+	}
+	if _, err := repo.GetActiveByBotIDAndGuestID(botB, guestID); err == nil {
+		t.Fatal("another bot sees the ban")
+	}
+	stored, err := repo.GetByID(blacklist.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.BotID != botA || stored.Status != models.BlacklistStatusPending {
+		t.Fatalf("request was changed through another bot: bot %s, status %s", stored.BotID, stored.Status)
+	}
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"testing"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// createFirst makes the next Create of a T insert the row made by competitor
+// just before it, as if a concurrent request had won the race to create it
+func createFirst[T any](t *testing.T, db *gorm.DB, competitor *T) {
+	t.Helper()
+	done := false
+	if err := db.Callback().Create().Before("gorm:create").Register("test:create_first", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*T); !ok || done {
+			return
+		}
+		done = true
+		if err := tx.Session(&gorm.Session{NewDB: true}).Create(competitor).Error; err != nil {
+			t.Errorf("competing Create: %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+}
+
+func TestGuestGetOrCreateLosesRace(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewGuestRepository(db)
+	botID := uuid.New()
+
+	winner := &models.Guest{BotID: botID, GuestUserID: 42}
+	createFirst(t, db, winner)
+
+	guest, err := repo.GetOrCreateByBotIDAndUserID(botID, 42)
+	if err != nil {
+		t.Fatalf("GetOrCreateByBotIDAndUserID: %v", err)
+	}
+	if guest.ID != winner.ID {
+		t.Fatalf("got guest %s, want the concurrently created %s", guest.ID, winner.ID)
+	}
+	if count, _ := repo.CountByBotID(botID); count != 1 {
+		t.Fatalf("%d guest rows, want 1", count)
+	}
+}
+
+func TestGuestCreateIfMissing(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewGuestRepository(db)
+	botID := uuid.New()
+
+	created, err := repo.CreateIfMissing(botID, 42)
+	if err != nil {
+		t.Fatalf("CreateIfMissing: %v", err)
+	}
+	if !created {
+		t.Fatal("first CreateIfMissing reported an existing guest")
+	}
+	if created, err := repo.CreateIfMissing(botID, 42); err != nil || created {
+		t.Fatalf("second CreateIfMissing = %v, %v; want false, nil", created, err)
+	}
+	if created, err := repo.CreateIfMissing(uuid.New(), 42); err != nil || !created {
+		t.Fatalf("CreateIfMissing for another bot = %v, %v; want true, nil", created, err)
+	}
+	if count, _ := repo.CountByBotID(botID); count != 1 {
+		t.Fatalf("%d guest rows, want 1", count)
+	}
+}
+
+func TestUserGetOrCreateLosesRace(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+
+	winner := &models.User{TelegramUserID: 42}
+	createFirst(t, db, winner)
+
+	user, err := repo.GetOrCreateByTelegramUserID(42, nil)
+	if err != nil {
+		t.Fatalf("GetOrCreateByTelegramUserID: %v", err)
+	}
+	if user.ID != winner.ID {
+		t.Fatalf("got user %s, want the concurrently created %s", user.ID, winner.ID)
+	}
+}
+
+func TestMessageMappingCreateIsIdempotent(t *testing.T) {
+	repo := NewMessageMappingRepository(newTestDB(t))
+	botID := uuid.New()
+
+	inbound := func(recipientChatID, recipientMessageID int64) *models.MessageMapping {
+		return &models.MessageMapping{
+			BotID:              botID,
+			GuestChatID:        42,
+			GuestMessageID:     7,
+			RecipientChatID:    recipientChatID,
+			RecipientMessageID: recipientMessageID,
+			Direction:          models.MessageDirectionInbound,
+		}
+	}
+
+	if err := repo.Create(inbound(100, 1)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// The same guest message handled twice
+	if err := repo.Create(inbound(100, 2)); err != nil {
+		t.Fatalf("Create of duplicate: %v", err)
+	}
+	// Another recipient's copy
+	if err := repo.Create(inbound(200, 1)); err != nil {
+		t.Fatalf("Create for another recipient: %v", err)
+	}
+	// A staff reply whose guest copy happens to have the same IDs
+	outbound := inbound(100, 1)
+	outbound.Direction = models.MessageDirectionOutbound
+	if err := repo.Create(outbound); err != nil {
+		t.Fatalf("Create outbound: %v", err)
+	}
+
+	if count, _ := repo.CountByBotID(botID); count != 3 {
+		t.Fatalf("%d mappings, want 3", count)
+	}
+	mapping, err := repo.GetByRecipientMessage(botID, 100, 1)
+	if err != nil {
+		t.Fatalf("GetByRecipientMessage: %v", err)
+	}
+	if mapping.GuestMessageID != 7 {
+		t.Fatalf("mapping points at guest message %d, want 7", mapping.GuestMessageID)
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"github.com/google/uuid"
 	"go-telegram-forwarder-bot/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	Create(user *models.User) error
 	GetByID(id uuid.UUID) (*models.User, error)
+	// GetByIDs returns the users with the given IDs in one query; IDs without a
+	// user are skipped
+	GetByIDs(ids []uuid.UUID) ([]*models.User, error)
 	GetByTelegramUserID(telegramUserID int64) (*models.User, error)
 	GetOrCreateByTelegramUserID(telegramUserID int64, username *string) (*models.User, error)
 	Update(user *models.User) error
@@ -35,6 +39,17 @@ func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) GetByIDs(ids []uuid.UUID) ([]*models.User, error) {
+	var users []*models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Order("created_at").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *userRepository) GetByTelegramUserID(telegramUserID int64) (*models.User, error) {
 	var user models.User
 	if err := r.db.Where("telegram_user_id = ?", telegramUserID).First(&user).Error; err != nil {
@@ -60,19 +75,23 @@ func (r *userRepository) GetOrCreateByTelegramUserID(telegramUserID int64, usern
 		return nil, err
 	}
 
-	// Create new user
+	// Create new user; if a concurrent call created it first, use that one
 	newUser := &models.User{
 		TelegramUserID: telegramUserID,
 		Username:       username,
 	}
-	if err := r.Create(newUser); err != nil {
-		return nil, err
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(newUser)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return r.GetByTelegramUserID(telegramUserID)
 	}
 	return newUser, nil
 }
 
 func (r *userRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+	return updateExisting(r.db, user)
 }
 
 func (r *userRepository) Delete(id uuid.UUID) error {
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WelcomeAssignmentRepository interface {
+	// Create stores an assignment; it reports false if the guest already had one
+	Create(assignment *models.WelcomeAssignment) (bool, error)
+	GetByBotIDAndUserID(botID uuid.UUID, userID int64) (*models.WelcomeAssignment, error)
+	// MarkConverted sets ConvertedAt once; it reports whether this call set it
+	MarkConverted(botID uuid.UUID, userID int64) (bool, error)
+	// CountByBotID counts assigned and converted guests per variant
+	CountByBotID(botID uuid.UUID) ([]*WelcomeVariantCounts, error)
+	DeleteByBotID(botID uuid.UUID) error
+}
+
+// WelcomeVariantCounts aggregates the guests shown one welcome variant
+type WelcomeVariantCounts struct {
+	Variant   string
+	Assigned  int64
+	Converted int64
+}
+
+type welcomeAssignmentRepository struct {
+	db *gorm.DB
+}
+
+func NewWelcomeAssignmentRepository(db *gorm.DB) WelcomeAssignmentRepository {
+	return &welcomeAssignmentRepository{db: db}
+}
+
+func (r *welcomeAssignmentRepository) Create(assignment *models.WelcomeAssignment) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(assignment)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *welcomeAssignmentRepository) GetByBotIDAndUserID(botID uuid.UUID, userID int64) (*models.WelcomeAssignment, error) {
+	var assignment models.WelcomeAssignment
+	if err := r.db.Where("bot_id = ? AND guest_user_id = ?", botID, userID).First(&assignment).Error; err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+func (r *welcomeAssignmentRepository) MarkConverted(botID uuid.UUID, userID int64) (bool, error) {
+	result := r.db.Model(&models.WelcomeAssignment{}).
+		Where("bot_id = ? AND guest_user_id = ? AND converted_at IS NULL", botID, userID).
+		Update("converted_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *welcomeAssignmentRepository) CountByBotID(botID uuid.UUID) ([]*WelcomeVariantCounts, error) {
+	var counts []*WelcomeVariantCounts
+	if err := r.db.Model(&models.WelcomeAssignment{}).
+		Select("variant, COUNT(*) AS assigned, COUNT(converted_at) AS converted").
+		Where("bot_id = ?", botID).
+		Group("variant").
+		Order("variant").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *welcomeAssignmentRepository) DeleteByBotID(botID uuid.UUID) error {
+	return r.db.Where("bot_id = ?", botID).Delete(&models.WelcomeAssignment{}).Error
+}
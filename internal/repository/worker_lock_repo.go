@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WorkerLockRepository interface {
+	// TryAcquire takes the named lock for owner until expiresAt.
+	// The lock is granted if it does not exist, has expired, or is already held by owner.
+	TryAcquire(name string, owner string, expiresAt time.Time) (bool, error)
+	Release(name string, owner string) error
+}
+
+type workerLockRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkerLockRepository(db *gorm.DB) WorkerLockRepository {
+	return &workerLockRepository{db: db}
+}
+
+func (r *workerLockRepository) TryAcquire(name string, owner string, expiresAt time.Time) (bool, error) {
+	now := time.Now()
+
+	lock := &models.WorkerLock{
+		Name:      name,
+		Owner:     owner,
+		ExpiresAt: expiresAt,
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(lock)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// Row already exists: take it over only if expired or ours
+	result = r.db.Model(&models.WorkerLock{}).
+		Where("name = ? AND (expires_at < ? OR owner = ?)", name, now, owner).
+		Updates(map[string]interface{}{
+			"owner":      owner,
+			"expires_at": expiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *workerLockRepository) Release(name string, owner string) error {
+	return r.db.Where("name = ? AND owner = ?", name, owner).Delete(&models.WorkerLock{}).Error
+}
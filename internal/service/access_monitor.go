@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// accessMonitorSweepSize is how many users may be tracked before users without
+// recent attempts are forgotten
+const accessMonitorSweepSize = 1000
+
+// AccessMonitor records commands and buttons refused for lack of permission in
+// the audit log, and alerts the superusers when one user is refused
+// access_denied.alert_threshold times within access_denied.window_seconds.
+// Each user is alerted about at most once per window.
+type AccessMonitor struct {
+	auditLogRepo  repository.AuditLogRepository
+	userRepo      repository.UserRepository
+	errorNotifier *ErrorNotifier
+	threshold     int
+	window        time.Duration
+	logger        *zap.Logger
+
+	mu       sync.Mutex
+	attempts map[int64][]time.Time // Recent denied attempts per Telegram user
+	alerted  map[int64]time.Time   // Last alert per Telegram user
+}
+
+func NewAccessMonitor(cfg *config.Config, auditLogRepo repository.AuditLogRepository, userRepo repository.UserRepository, logger *zap.Logger) *AccessMonitor {
+	return &AccessMonitor{
+		auditLogRepo: auditLogRepo,
+		userRepo:     userRepo,
+		threshold:    cfg.AccessDenied.AlertThreshold,
+		window:       time.Duration(cfg.AccessDenied.WindowSeconds) * time.Second,
+		logger:       logger,
+		attempts:     make(map[int64][]time.Time),
+		alerted:      make(map[int64]time.Time),
+	}
+}
+
+// SetErrorNotifier sets the notifier used to alert the superusers
+func (m *AccessMonitor) SetErrorNotifier(errorNotifier *ErrorNotifier) {
+	m.errorNotifier = errorNotifier
+}
+
+// Denied records that the Telegram user was refused an action, such as a
+// command or button, on the ForwarderBot botID, or on the ManagerBot if botID
+// is uuid.Nil
+func (m *AccessMonitor) Denied(ctx context.Context, telegramUserID int64, username string, botID uuid.UUID, action string) {
+	m.logger.Info("Access denied",
+		zap.Int64("user_id", telegramUserID),
+		zap.String("bot_id", botID.String()),
+		zap.String("action", action))
+
+	m.record(telegramUserID, username, botID, action)
+
+	count, alert := m.count(telegramUserID, time.Now())
+	if alert {
+		m.alert(ctx, telegramUserID, username, botID, action, count)
+	}
+}
+
+// record writes the attempt to the audit log. Users without an account are
+// logged by their Telegram ID only, rather than creating one for them.
+func (m *AccessMonitor) record(telegramUserID int64, username string, botID uuid.UUID, action string) {
+	details := map[string]interface{}{
+		"telegram_user_id": telegramUserID,
+		"action":           action,
+	}
+	if username != "" {
+		details["username"] = username
+	}
+	encoded, _ := json.Marshal(details)
+
+	auditLog := &models.AuditLog{
+		ActionType:   models.AuditLogActionAccessDenied,
+		ResourceType: "bot",
+		ResourceID:   botID,
+		Details:      string(encoded),
+	}
+	if botID == uuid.Nil {
+		auditLog.ResourceType = "system"
+	}
+	if user, err := m.userRepo.GetByTelegramUserID(telegramUserID); err == nil {
+		auditLog.UserID = &user.ID
+	}
+	if err := m.auditLogRepo.Create(auditLog); err != nil {
+		m.logger.Warn("Failed to record denied access", zap.Error(err))
+	}
+}
+
+// count adds an attempt by the user and returns their attempts within the
+// window, and whether the superusers should be alerted about them
+func (m *AccessMonitor) count(telegramUserID int64, now time.Time) (int, bool) {
+	if m.threshold <= 0 {
+		return 0, false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.attempts) > accessMonitorSweepSize {
+		m.sweep(now)
+	}
+
+	recent := m.attempts[telegramUserID][:0]
+	for _, at := range m.attempts[telegramUserID] {
+		if now.Sub(at) < m.window {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, now)
+	m.attempts[telegramUserID] = recent
+
+	if len(recent) < m.threshold {
+		return len(recent), false
+	}
+	if last, ok := m.alerted[telegramUserID]; ok && now.Sub(last) < m.window {
+		return len(recent), false
+	}
+	m.alerted[telegramUserID] = now
+	return len(recent), true
+}
+
+// sweep forgets users without attempts or alerts within the window
+func (m *AccessMonitor) sweep(now time.Time) {
+	for telegramUserID, attempts := range m.attempts {
+		if len(attempts) == 0 || now.Sub(attempts[len(attempts)-1]) >= m.window {
+			delete(m.attempts, telegramUserID)
+		}
+	}
+	for telegramUserID, last := range m.alerted {
+		if now.Sub(last) >= m.window {
+			delete(m.alerted, telegramUserID)
+		}
+	}
+}
+
+func (m *AccessMonitor) alert(ctx context.Context, telegramUserID int64, username string, botID uuid.UUID, action string, count int) {
+	m.logger.Warn("Repeated access denied",
+		zap.Int64("user_id", telegramUserID),
+		zap.Int("attempts", count),
+		zap.Duration("window", m.window))
+	if m.errorNotifier == nil {
+		return
+	}
+
+	user := fmt.Sprintf("`%d`", telegramUserID)
+	if username != "" {
+		user += " (@" + utils.EscapeMarkdown(username) + ")"
+	}
+	where := "ManagerBot"
+	if botID != uuid.Nil {
+		where = fmt.Sprintf("ForwarderBot `%s`", botID.String())
+	}
+	message := fmt.Sprintf(
+		"*Repeated Access Denied*\n\n"+
+			"User: %s\n"+
+			"Denied attempts: %d in the last %s\n"+
+			"Last attempt: %s on %s\n"+
+			"Time: %s\n\n"+
+			"Every attempt is in the audit log as `%s`.",
+		user,
+		count,
+		m.window,
+		utils.EscapeMarkdown(action),
+		where,
+		time.Now().Format("2006-01-02 15:04:05"),
+		models.AuditLogActionAccessDenied,
+	)
+	m.errorNotifier.NotifySuperusers(ctx, message)
+}
@@ -0,0 +1,207 @@
+// Package adfilter checks guest messages against each bot's own filter rules:
+// case-insensitive keywords or regular expressions that drop or quarantine
+// matching messages. It is only used when ad_filter is enabled in the config.
+package adfilter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxRules limits the number of filter rules per bot
+	MaxRules = 100
+	// MaxPatternLength limits the length of a keyword or regular expression
+	MaxPatternLength = 200
+)
+
+// ErrAlreadyDecided is returned when delivering or discarding a quarantined
+// message someone else already decided
+var ErrAlreadyDecided = errors.New("quarantined message already decided")
+
+type Service struct {
+	ruleRepo       repository.FilterRuleRepository
+	quarantineRepo repository.QuarantinedMessageRepository
+	logger         *zap.Logger
+
+	// compiled caches regular expressions by pattern, since rules are checked
+	// for every guest message
+	mu       sync.RWMutex
+	compiled map[string]*regexp.Regexp
+}
+
+func NewService(
+	ruleRepo repository.FilterRuleRepository,
+	quarantineRepo repository.QuarantinedMessageRepository,
+	logger *zap.Logger,
+) *Service {
+	return &Service{
+		ruleRepo:       ruleRepo,
+		quarantineRepo: quarantineRepo,
+		logger:         logger,
+		compiled:       make(map[string]*regexp.Regexp),
+	}
+}
+
+// ValidatePattern checks a keyword or regular expression before it is saved
+func ValidatePattern(pattern string, isRegex bool) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("pattern is empty")
+	}
+	if utf8.RuneCountInString(pattern) > MaxPatternLength {
+		return fmt.Errorf("pattern is longer than %d characters", MaxPatternLength)
+	}
+	if isRegex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+	return nil
+}
+
+// Rules returns the bot's rules in the order they are checked
+func (s *Service) Rules(botID uuid.UUID) ([]*models.FilterRule, error) {
+	return s.ruleRepo.GetByBotID(botID)
+}
+
+// AddRule validates and saves a rule
+func (s *Service) AddRule(rule *models.FilterRule) error {
+	if err := ValidatePattern(rule.Pattern, rule.IsRegex); err != nil {
+		return err
+	}
+	return s.ruleRepo.Create(rule)
+}
+
+// DeleteRule removes one of the bot's rules
+func (s *Service) DeleteRule(botID uuid.UUID, id uuid.UUID) error {
+	return s.ruleRepo.Delete(botID, id)
+}
+
+// Match returns the first of the bot's rules that matches any of the texts,
+// or nil if none does, and counts the hit
+func (s *Service) Match(botID uuid.UUID, texts ...string) (*models.FilterRule, error) {
+	rules, err := s.ruleRepo.GetByBotID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get filter rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		if !s.matches(rule, texts) {
+			continue
+		}
+		if err := s.ruleRepo.RecordHit(botID, rule.ID); err != nil {
+			s.logger.Warn("Failed to record filter rule hit",
+				zap.String("rule_id", rule.ID.String()),
+				zap.Error(err))
+		}
+		return rule, nil
+	}
+	return nil, nil
+}
+
+func (s *Service) matches(rule *models.FilterRule, texts []string) bool {
+	var re *regexp.Regexp
+	if rule.IsRegex {
+		re = s.regexp(rule.Pattern)
+		if re == nil {
+			return false
+		}
+	}
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+		if re != nil {
+			if re.MatchString(text) {
+				return true
+			}
+		} else if strings.Contains(strings.ToLower(text), strings.ToLower(rule.Pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// regexp returns the compiled pattern, or nil if it does not compile
+func (s *Service) regexp(pattern string) *regexp.Regexp {
+	s.mu.RLock()
+	re, ok := s.compiled[pattern]
+	s.mu.RUnlock()
+	if ok {
+		return re
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		// Patterns are validated when added, so this only happens for rows edited by hand
+		s.logger.Warn("Invalid filter pattern", zap.String("pattern", pattern), zap.Error(err))
+	}
+	s.mu.Lock()
+	s.compiled[pattern] = re
+	s.mu.Unlock()
+	return re
+}
+
+// Quarantine holds a guest message caught by a quarantine rule
+func (s *Service) Quarantine(botID uuid.UUID, rule *models.FilterRule, msg *gotgbot.Message) (*models.QuarantinedMessage, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+	quarantined := &models.QuarantinedMessage{
+		BotID:          botID,
+		GuestChatID:    msg.Chat.Id,
+		GuestMessageID: msg.MessageId,
+		Pattern:        rule.Pattern,
+		Message:        string(data),
+	}
+	if err := s.quarantineRepo.Create(quarantined); err != nil {
+		return nil, err
+	}
+	return quarantined, nil
+}
+
+// Release removes a quarantined message and returns it so it can be
+// delivered. It returns ErrAlreadyDecided if someone else decided it first.
+func (s *Service) Release(botID uuid.UUID, id uuid.UUID) (*gotgbot.Message, error) {
+	quarantined, err := s.quarantineRepo.GetByBotIDAndID(botID, id)
+	if err != nil {
+		return nil, ErrAlreadyDecided
+	}
+	var msg gotgbot.Message
+	if err := json.Unmarshal([]byte(quarantined.Message), &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+	if err := s.take(botID, id); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// Discard removes a quarantined message without delivering it
+func (s *Service) Discard(botID uuid.UUID, id uuid.UUID) error {
+	return s.take(botID, id)
+}
+
+func (s *Service) take(botID uuid.UUID, id uuid.UUID) error {
+	taken, err := s.quarantineRepo.Take(botID, id)
+	if err != nil {
+		return err
+	}
+	if !taken {
+		return ErrAlreadyDecided
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// auditQueueSize is how many audit logs may wait to be written. When the
+	// queue is full, Create writes the log itself instead of dropping it.
+	auditQueueSize = 1024
+
+	// auditWriteAttempts is how often a log is written before it is given up on
+	auditWriteAttempts = 3
+
+	// auditRetryDelay is the wait before the first retry; it doubles after each attempt
+	auditRetryDelay = 500 * time.Millisecond
+)
+
+// AuditWriter writes audit logs in the background so handlers do not wait for
+// the database. Reads and WithTx go straight to the wrapped repository, so audit
+// logs written inside a transaction are still part of it.
+type AuditWriter struct {
+	repository.AuditLogRepository
+
+	queue         chan *models.AuditLog
+	errorNotifier *ErrorNotifier
+	logger        *zap.Logger
+
+	mu     sync.RWMutex // guards closed against Create sending on a closed queue
+	closed bool
+	done   chan struct{}
+
+	overflowed atomic.Int64
+	failed     atomic.Int64
+}
+
+func NewAuditWriter(repo repository.AuditLogRepository, logger *zap.Logger) *AuditWriter {
+	w := &AuditWriter{
+		AuditLogRepository: repo,
+		queue:              make(chan *models.AuditLog, auditQueueSize),
+		logger:             logger,
+		done:               make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// SetErrorNotifier sets the notifier told when audit logs cannot be written.
+// Written logs are passed to it too, so it can post important events to the
+// log chat.
+func (w *AuditWriter) SetErrorNotifier(errorNotifier *ErrorNotifier) {
+	w.errorNotifier = errorNotifier
+}
+
+// Create queues the log for writing and returns at once. Write failures are
+// logged and reported to the superusers rather than returned.
+func (w *AuditWriter) Create(log *models.AuditLog) error {
+	// Assign what the database would, so the caller sees the ID and the log
+	// keeps the time of the action rather than of the write
+	if log.ID == uuid.Nil {
+		log.ID = uuid.New()
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+
+	w.mu.RLock()
+	if !w.closed {
+		select {
+		case w.queue <- log:
+			w.mu.RUnlock()
+			return nil
+		default:
+		}
+	}
+	closed := w.closed
+	w.mu.RUnlock()
+
+	if !closed {
+		w.overflowed.Add(1)
+		w.logger.Warn("Audit log queue is full, writing inline",
+			zap.String("action", string(log.ActionType)))
+	}
+	w.write(log, false)
+	return nil
+}
+
+// Pending returns how many audit logs are waiting to be written
+func (w *AuditWriter) Pending() int {
+	return len(w.queue)
+}
+
+// Overflowed returns how many audit logs were written inline because the queue was full
+func (w *AuditWriter) Overflowed() int64 {
+	return w.overflowed.Load()
+}
+
+// Failed returns how many audit logs could not be written
+func (w *AuditWriter) Failed() int64 {
+	return w.failed.Load()
+}
+
+// Close writes the queued audit logs and stops the writer. Logs created after
+// Close are written directly. Called on shutdown.
+func (w *AuditWriter) Close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+	<-w.done
+}
+
+func (w *AuditWriter) run() {
+	defer close(w.done)
+	for log := range w.queue {
+		w.write(log, true)
+	}
+}
+
+// write stores the log, retrying with backoff when retry is set
+func (w *AuditWriter) write(log *models.AuditLog, retry bool) {
+	attempts := 1
+	if retry {
+		attempts = auditWriteAttempts
+	}
+
+	var err error
+	delay := auditRetryDelay
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = w.AuditLogRepository.Create(log); err == nil {
+			if w.errorNotifier != nil {
+				w.errorNotifier.LogAuditEvent(log)
+			}
+			return
+		}
+		if attempt < attempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	// The log is only kept in the application log from here on
+	w.failed.Add(1)
+	fields := []zap.Field{
+		zap.String("action", string(log.ActionType)),
+		zap.String("resource_type", log.ResourceType),
+		zap.String("resource_id", log.ResourceID.String()),
+		zap.String("details", log.Details),
+		zap.Time("created_at", log.CreatedAt),
+		zap.Error(err),
+	}
+	if log.UserID != nil {
+		fields = append(fields, zap.String("user_id", log.UserID.String()))
+	}
+	w.logger.Error("Failed to write audit log", fields...)
+
+	if w.errorNotifier != nil {
+		w.errorNotifier.NotifyCriticalError(context.Background(), ErrorTypeAuditLog, err,
+			fmt.Sprintf("audit log %s could not be written after %d attempts", log.ActionType, attempts))
+	}
+}
@@ -14,6 +14,10 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrAlreadyDecided is returned when approving or rejecting a request that was
+// already approved or rejected, e.g. by another admin a moment earlier
+var ErrAlreadyDecided = errors.New("request already decided")
+
 type Service struct {
 	blacklistRepo repository.BlacklistRepository
 	guestRepo     repository.GuestRepository
@@ -53,54 +57,67 @@ func (s *Service) IsBlacklisted(botID uuid.UUID, guestUserID int64) (bool, error
 		return false, err
 	}
 
-	// Ban logic: approved or pending → blacklisted, rejected → not blacklisted
-	if latest.RequestType == models.BlacklistRequestTypeBan {
-		if latest.Status == models.BlacklistStatusApproved || latest.Status == models.BlacklistStatusPending {
-			s.logger.Debug("User is blacklisted due to ban",
-				zap.String("bot_id", botID.String()),
-				zap.String("guest_id", guest.ID.String()),
-				zap.String("ban_status", string(latest.Status)),
-				zap.Time("ban_created_at", latest.CreatedAt))
-			return true, nil
-		}
-		// Ban rejected → not blacklisted
-		s.logger.Debug("User is not blacklisted (ban rejected)",
-			zap.String("bot_id", botID.String()),
-			zap.String("guest_id", guest.ID.String()))
-		return false, nil
-	}
-
-	// Unban logic: rejected or pending → blacklisted, approved → not blacklisted
-	if latest.RequestType == models.BlacklistRequestTypeUnban {
-		if latest.Status == models.BlacklistStatusRejected || latest.Status == models.BlacklistStatusPending {
-			s.logger.Debug("User is blacklisted (unban rejected or pending)",
-				zap.String("bot_id", botID.String()),
-				zap.String("guest_id", guest.ID.String()),
-				zap.String("unban_status", string(latest.Status)),
-				zap.Time("unban_created_at", latest.CreatedAt))
-			return true, nil
-		}
-		// Unban approved → not blacklisted
-		s.logger.Debug("User is not blacklisted (unban approved)",
-			zap.String("bot_id", botID.String()),
-			zap.String("guest_id", guest.ID.String()),
-			zap.Time("unban_created_at", latest.CreatedAt))
-		return false, nil
-	}
-
-	// Should not reach here, but default to not blacklisted
-	s.logger.Warn("Unexpected blacklist record type",
+	blacklisted := isBlacklisting(latest, time.Now())
+	s.logger.Debug("Blacklist state checked",
 		zap.String("bot_id", botID.String()),
 		zap.String("guest_id", guest.ID.String()),
 		zap.String("request_type", string(latest.RequestType)),
-		zap.String("status", string(latest.Status)))
-	return false, nil
+		zap.String("status", string(latest.Status)),
+		zap.Time("request_created_at", latest.CreatedAt),
+		zap.Bool("blacklisted", blacklisted))
+	return blacklisted, nil
+}
+
+// isBlacklisting reports whether a guest whose latest request is latest is
+// blacklisted at now. A ban counts while pending or once approved, until it
+// expires; an unban lifts it only once approved.
+func isBlacklisting(latest *models.Blacklist, now time.Time) bool {
+	switch latest.RequestType {
+	case models.BlacklistRequestTypeBan:
+		if latest.Expired(now) {
+			return false
+		}
+		return latest.Status == models.BlacklistStatusApproved || latest.Status == models.BlacklistStatusPending
+	case models.BlacklistRequestTypeUnban:
+		return latest.Status == models.BlacklistStatusRejected || latest.Status == models.BlacklistStatusPending
+	}
+	return false
+}
+
+// GetHistory returns every ban and unban request for the guest, most recent first
+func (s *Service) GetHistory(botID uuid.UUID, guestID uuid.UUID) ([]*models.Blacklist, error) {
+	return s.blacklistRepo.GetAllByBotIDAndGuestID(botID, guestID)
+}
+
+// GetBlacklisted returns the latest request of every blacklisted guest of the
+// bot, with the guest loaded, most recent first
+func (s *Service) GetBlacklisted(botID uuid.UUID) ([]*models.Blacklist, error) {
+	requests, err := s.blacklistRepo.GetByBotID(botID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	seen := make(map[uuid.UUID]bool)
+	blacklisted := make([]*models.Blacklist, 0)
+	for _, request := range requests {
+		if seen[request.GuestID] {
+			continue
+		}
+		seen[request.GuestID] = true
+		if isBlacklisting(request, now) {
+			blacklisted = append(blacklisted, request)
+		}
+	}
+	return blacklisted, nil
 }
 
+// CreateBanRequest records a pending ban for the guest. reason is optional
+// free text kept on the request for the approval message and the history.
 func (s *Service) CreateBanRequest(
 	botID uuid.UUID,
 	guestUserID int64,
 	requestUserID uuid.UUID,
+	reason string,
 ) (*models.Blacklist, error) {
 	guest, err := s.guestRepo.GetOrCreateByBotIDAndUserID(botID, guestUserID)
 	if err != nil {
@@ -113,8 +130,8 @@ func (s *Service) CreateBanRequest(
 	if err == nil && latest != nil {
 		canTrigger := false
 		if latest.RequestType == models.BlacklistRequestTypeBan {
-			// Can trigger if ban is pending or rejected
-			if latest.Status == models.BlacklistStatusPending || latest.Status == models.BlacklistStatusRejected {
+			// Can trigger if ban is pending or rejected, or a temporary ban has expired
+			if latest.Status == models.BlacklistStatusPending || latest.Status == models.BlacklistStatusRejected || latest.Expired(time.Now()) {
 				canTrigger = true
 			}
 		} else if latest.RequestType == models.BlacklistRequestTypeUnban {
@@ -135,6 +152,7 @@ func (s *Service) CreateBanRequest(
 		Status:        models.BlacklistStatusPending,
 		RequestUserID: requestUserID,
 		RequestType:   models.BlacklistRequestTypeBan,
+		Reason:        reason,
 	}
 
 	if err := s.blacklistRepo.Create(blacklist); err != nil {
@@ -192,12 +210,94 @@ func (s *Service) CreateUnbanRequest(
 	return blacklist, nil
 }
 
-func (s *Service) ApproveRequest(blacklistID uuid.UUID) error {
-	return s.blacklistRepo.ApprovePending(blacklistID)
+// Mute blacklists the guest until the given time with an approved ban on
+// behalf of requestUserID. The mute_expiry worker lifts the ban once it
+// expires; until then it can be lifted like any other ban.
+func (s *Service) Mute(
+	botID uuid.UUID,
+	guestUserID int64,
+	requestUserID uuid.UUID,
+	until time.Time,
+	reason string,
+) (*models.Blacklist, error) {
+	guest, err := s.guestRepo.GetOrCreateByBotIDAndUserID(botID, guestUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	blacklist := &models.Blacklist{
+		BotID:         botID,
+		GuestID:       guest.ID,
+		Status:        models.BlacklistStatusApproved,
+		RequestUserID: requestUserID,
+		RequestType:   models.BlacklistRequestTypeBan,
+		ApprovedAt:    &now,
+		ExpiresAt:     &until,
+		Reason:        reason,
+	}
+	if err := s.blacklistRepo.Create(blacklist); err != nil {
+		return nil, err
+	}
+	return blacklist, nil
 }
 
-func (s *Service) RejectRequest(blacklistID uuid.UUID) error {
-	return s.blacklistRepo.RejectPending(blacklistID)
+// LiftExpiredMutes records an approved unban for every temporary ban that has
+// expired and was not followed by another request, so the history shows when
+// the guest was let back in
+func (s *Service) LiftExpiredMutes(ctx context.Context) error {
+	now := time.Now()
+	expired, err := s.blacklistRepo.GetExpiredBans(now)
+	if err != nil {
+		return err
+	}
+	for _, ban := range expired {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		unban := &models.Blacklist{
+			BotID:         ban.BotID,
+			GuestID:       ban.GuestID,
+			Status:        models.BlacklistStatusApproved,
+			RequestUserID: ban.RequestUserID,
+			RequestType:   models.BlacklistRequestTypeUnban,
+			ApprovedAt:    &now,
+		}
+		if err := s.blacklistRepo.Create(unban); err != nil {
+			return err
+		}
+		s.logger.Info("Temporary ban lifted",
+			zap.String("bot_id", ban.BotID.String()),
+			zap.String("guest_id", ban.GuestID.String()),
+			zap.Time("expired_at", *ban.ExpiresAt))
+	}
+	return nil
+}
+
+// ApproveRequest approves a pending request on behalf of decidedBy. It returns
+// ErrAlreadyDecided if the request is no longer pending.
+func (s *Service) ApproveRequest(blacklistID uuid.UUID, decidedBy uuid.UUID) error {
+	approved, err := s.blacklistRepo.ApprovePending(blacklistID, decidedBy)
+	if err != nil {
+		return err
+	}
+	if !approved {
+		return ErrAlreadyDecided
+	}
+	return nil
+}
+
+// RejectRequest rejects a pending request on behalf of decidedBy. It returns
+// ErrAlreadyDecided if the request is no longer pending.
+func (s *Service) RejectRequest(blacklistID uuid.UUID, decidedBy uuid.UUID) error {
+	rejected, err := s.blacklistRepo.RejectPending(blacklistID, decidedBy)
+	if err != nil {
+		return err
+	}
+	if !rejected {
+		return ErrAlreadyDecided
+	}
+	return nil
 }
 
 func (s *Service) GetPendingRequests(botID uuid.UUID) ([]*models.Blacklist, error) {
@@ -207,20 +307,3 @@ func (s *Service) GetPendingRequests(botID uuid.UUID) ([]*models.Blacklist, erro
 func (s *Service) AutoApproveExpired(ctx context.Context) error {
 	return s.blacklistRepo.AutoApproveExpired()
 }
-
-func (s *Service) StartAutoApproveWorker(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Hour)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if err := s.AutoApproveExpired(ctx); err != nil {
-				s.logger.Error("Failed to auto-approve expired blacklist requests",
-					zap.Error(err))
-			}
-		}
-	}
-}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// BotInfoRefresher keeps the username and Telegram ID stored on ForwarderBots in
+// sync with Telegram, since usernames can be changed in BotFather at any time
+type BotInfoRefresher struct {
+	botRepo repository.BotRepository
+	logger  *zap.Logger
+}
+
+func NewBotInfoRefresher(botRepo repository.BotRepository, logger *zap.Logger) *BotInfoRefresher {
+	return &BotInfoRefresher{
+		botRepo: botRepo,
+		logger:  logger,
+	}
+}
+
+// Refresh calls GetMe with the bot's own client and stores its current username
+// and Telegram ID. It returns the bot as stored after the refresh; the database
+// is only written if something changed.
+func (r *BotInfoRefresher) Refresh(ctx context.Context, b *gotgbot.Bot, botID uuid.UUID) (*models.ForwarderBot, error) {
+	me, err := b.GetMeWithContext(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bot info: %w", err)
+	}
+
+	bot, err := r.botRepo.GetByID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bot: %w", err)
+	}
+	if bot.Name == me.Username && bot.TelegramBotID == me.Id {
+		return bot, nil
+	}
+
+	oldName := bot.Name
+	bot.Name = me.Username
+	bot.TelegramBotID = me.Id
+	if err := r.botRepo.Update(bot); err != nil {
+		return nil, fmt.Errorf("failed to update bot: %w", err)
+	}
+
+	r.logger.Info("Bot info refreshed",
+		zap.String("bot_id", botID.String()),
+		zap.String("old_name", oldName),
+		zap.String("new_name", bot.Name),
+		zap.Int64("telegram_bot_id", bot.TelegramBotID))
+	return bot, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/database"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// dbCheckInterval is how often the database is pinged while healthy
+	dbCheckInterval = 30 * time.Second
+	// dbOutageCheckInterval is how often the database is pinged during an outage
+	dbOutageCheckInterval = 5 * time.Second
+)
+
+// DBHealth monitors the database connection. While the database is down,
+// update handlers wait for it briefly with WaitHealthy instead of failing every
+// query, and superusers are alerted through the ErrorNotifier.
+type DBHealth struct {
+	db            *gorm.DB
+	errorNotifier *ErrorNotifier
+	logger        *zap.Logger
+
+	mu        sync.RWMutex
+	healthy   bool
+	recovered chan struct{} // Closed when the current outage ends
+}
+
+func NewDBHealth(db *gorm.DB, errorNotifier *ErrorNotifier, logger *zap.Logger) *DBHealth {
+	return &DBHealth{
+		db:            db,
+		errorNotifier: errorNotifier,
+		logger:        logger,
+		healthy:       true,
+		recovered:     make(chan struct{}),
+	}
+}
+
+// Healthy reports whether the last check reached the database
+func (h *DBHealth) Healthy() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.healthy
+}
+
+// WaitHealthy returns immediately while the database is healthy. During an
+// outage it blocks until the database recovers, maxWait passes or ctx is done,
+// and reports whether the database is healthy.
+func (h *DBHealth) WaitHealthy(ctx context.Context, maxWait time.Duration) bool {
+	h.mu.RLock()
+	healthy, recovered := h.healthy, h.recovered
+	h.mu.RUnlock()
+	if healthy {
+		return true
+	}
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+	select {
+	case <-recovered:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Monitor pings the database until ctx is done. A failed ping is retried with
+// backoff before the database is marked down; it is then checked more often
+// until it recovers.
+func (h *DBHealth) Monitor(ctx context.Context) {
+	interval := dbCheckInterval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := database.PingDB(pingCtx, h.db)
+		cancel()
+
+		if err == nil {
+			if !h.Healthy() {
+				h.setHealthy(true)
+				h.logger.Info("Database connection restored")
+			}
+			interval = dbCheckInterval
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !h.Healthy() {
+			h.logger.Debug("Database still unreachable", zap.Error(err))
+			continue
+		}
+
+		h.logger.Warn("Database connection lost, attempting to reconnect", zap.Error(err))
+		if retryErr := database.RetryDBPing(ctx, h.db, 3, 2*time.Second); retryErr == nil {
+			h.logger.Info("Database reconnected successfully")
+			continue
+		} else if ctx.Err() != nil {
+			return
+		} else {
+			err = retryErr
+		}
+
+		h.logger.Error("Failed to reconnect to database after retries", zap.Error(err))
+		h.setHealthy(false)
+		if h.errorNotifier != nil {
+			h.errorNotifier.NotifyCriticalError(ctx, ErrorTypeDatabase, err,
+				"Database connection lost and reconnection failed after 3 retries; updates are held until it recovers")
+		}
+		interval = dbOutageCheckInterval
+	}
+}
+
+func (h *DBHealth) setHealthy(healthy bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.healthy == healthy {
+		return
+	}
+	h.healthy = healthy
+	if healthy {
+		close(h.recovered)
+	} else {
+		h.recovered = make(chan struct{})
+	}
+}
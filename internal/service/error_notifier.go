@@ -2,25 +2,42 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
 	"go-telegram-forwarder-bot/internal/utils"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"go.uber.org/zap"
 )
 
+// ErrorNotifier alerts the superusers by private message and, when a log chat
+// is configured, by posting to a channel or group all of them can watch.
+// Selected audit events are posted to the log chat as well.
 type ErrorNotifier struct {
 	bot          *gotgbot.Bot
-	superusers   []int64
+	superusers   *Superusers
+	users        repository.UserRepository
 	logger       *zap.Logger
 	notifiedErrs map[string]time.Time
 	mutex        sync.RWMutex
+
+	superuserDMs bool
+	logChatID    int64
+	auditActions map[models.AuditLogAction]bool
+	auditEvents  chan *models.AuditLog // nil unless audit events are posted to the log chat
 }
 
+// auditEventQueueSize is how many audit events may wait to be posted to the
+// log chat. Events beyond it are dropped; they are still in the audit log.
+const auditEventQueueSize = 256
+
 type ErrorType string
 
 const (
@@ -28,17 +45,39 @@ const (
 	ErrorTypeRedis    ErrorType = "redis"
 	ErrorTypeBotToken ErrorType = "bot_token"
 	ErrorTypeSystem   ErrorType = "system"
+	ErrorTypeAuditLog ErrorType = "audit_log"
 )
 
-func NewErrorNotifier(bot *gotgbot.Bot, cfg *config.Config, logger *zap.Logger) *ErrorNotifier {
+func NewErrorNotifier(bot *gotgbot.Bot, superusers *Superusers, logger *zap.Logger) *ErrorNotifier {
 	return &ErrorNotifier{
 		bot:          bot,
-		superusers:   cfg.ManagerBot.Superusers,
+		superusers:   superusers,
 		logger:       logger,
 		notifiedErrs: make(map[string]time.Time),
+		superuserDMs: true,
+	}
+}
+
+// SetNotifications sets where notifications go and which audit events are
+// posted to the log chat
+func (en *ErrorNotifier) SetNotifications(cfg config.NotificationsConfig) {
+	en.superuserDMs = cfg.SuperuserDMs
+	en.logChatID = cfg.LogChatID
+	en.auditActions = make(map[models.AuditLogAction]bool, len(cfg.AuditActions))
+	for _, action := range cfg.AuditActions {
+		en.auditActions[models.AuditLogAction(action)] = true
+	}
+	if en.logChatID != 0 && len(en.auditActions) > 0 && en.auditEvents == nil {
+		en.auditEvents = make(chan *models.AuditLog, auditEventQueueSize)
+		go en.postAuditEvents()
 	}
 }
 
+// SetUsers sets the repository used to show who performed an audit event
+func (en *ErrorNotifier) SetUsers(users repository.UserRepository) {
+	en.users = users
+}
+
 func (en *ErrorNotifier) NotifyCriticalError(ctx context.Context, errType ErrorType, err error, details string) {
 	en.mutex.Lock()
 	defer en.mutex.Unlock()
@@ -70,19 +109,115 @@ func (en *ErrorNotifier) NotifyCriticalError(ctx context.Context, errType ErrorT
 		time.Now().Format("2006-01-02 15:04:05"),
 	)
 
-	// Notify all superusers
-	for _, superuserID := range en.superusers {
-		_, sendErr := en.bot.SendMessage(superuserID, message, &gotgbot.SendMessageOpts{
-			ParseMode: "Markdown",
-		})
-		if sendErr != nil {
-			en.logger.Warn("Failed to send error notification to superuser",
-				zap.Int64("superuser_id", superuserID),
-				zap.Error(sendErr))
-		}
-	}
+	en.send(message)
 
 	en.logger.Error("Critical error notified to superusers",
 		zap.String("error_type", key),
 		zap.Error(err))
 }
+
+// Snapshot encodes when each error type was last notified, so a restart does
+// not repeat alerts within the debounce period
+func (en *ErrorNotifier) Snapshot() ([]byte, error) {
+	en.mutex.RLock()
+	defer en.mutex.RUnlock()
+	return json.Marshal(en.notifiedErrs)
+}
+
+// Restore merges saved notification times, keeping the later of two
+func (en *ErrorNotifier) Restore(data []byte) error {
+	var notified map[string]time.Time
+	if err := json.Unmarshal(data, &notified); err != nil {
+		return fmt.Errorf("failed to decode error notifier state: %w", err)
+	}
+
+	en.mutex.Lock()
+	defer en.mutex.Unlock()
+	for key, at := range notified {
+		if at.After(en.notifiedErrs[key]) {
+			en.notifiedErrs[key] = at
+		}
+	}
+	return nil
+}
+
+// NotifySuperusers sends a Markdown message to all superusers without debouncing.
+// Used for events that need individual attention, such as abuse reports.
+func (en *ErrorNotifier) NotifySuperusers(ctx context.Context, message string) {
+	en.send(message)
+}
+
+// LogAuditEvent queues the audit log for posting to the log chat if its action
+// is one of notifications.audit_actions, and returns at once. Nothing is sent
+// without a log chat, and the event is dropped if the log chat is too far behind.
+func (en *ErrorNotifier) LogAuditEvent(log *models.AuditLog) {
+	if en.auditEvents == nil || !en.auditActions[log.ActionType] {
+		return
+	}
+	select {
+	case en.auditEvents <- log:
+	default:
+		en.logger.Warn("Audit event queue is full, not posting to the log chat",
+			zap.String("action", string(log.ActionType)),
+			zap.String("audit_log_id", log.ID.String()))
+	}
+}
+
+func (en *ErrorNotifier) postAuditEvents() {
+	for log := range en.auditEvents {
+		en.postAuditEvent(log)
+	}
+}
+
+func (en *ErrorNotifier) postAuditEvent(log *models.AuditLog) {
+	message := fmt.Sprintf("*Audit: %s*\n\n", utils.EscapeMarkdown(string(log.ActionType)))
+	if log.UserID != nil && en.users != nil {
+		if user, err := en.users.GetByID(*log.UserID); err == nil {
+			message += fmt.Sprintf("By: `%d`", user.TelegramUserID)
+			if user.Username != nil && *user.Username != "" {
+				message += " @" + utils.EscapeMarkdown(*user.Username)
+			}
+			message += "\n"
+		}
+	}
+	message += fmt.Sprintf("Resource: %s `%s`\n", utils.EscapeMarkdown(log.ResourceType), log.ResourceID)
+	if log.Details != "" {
+		// Backticks would end the code span early
+		message += fmt.Sprintf("Details: `%s`\n", strings.ReplaceAll(log.Details, "`", "'"))
+	}
+	message += "Time: " + log.CreatedAt.Format("2006-01-02 15:04:05")
+
+	en.postToLogChat(message)
+}
+
+// send delivers a Markdown message to every superuser, unless superuser DMs
+// are turned off, and to the log chat
+func (en *ErrorNotifier) send(message string) {
+	if en.superuserDMs {
+		for _, superuserID := range en.superusers.List() {
+			_, sendErr := en.bot.SendMessage(superuserID, message, &gotgbot.SendMessageOpts{
+				ParseMode: "Markdown",
+			})
+			if sendErr != nil {
+				en.logger.Warn("Failed to send notification to superuser",
+					zap.Int64("superuser_id", superuserID),
+					zap.Error(sendErr))
+			}
+		}
+	}
+	en.postToLogChat(message)
+}
+
+func (en *ErrorNotifier) postToLogChat(message string) {
+	if en.logChatID == 0 {
+		return
+	}
+	_, err := en.bot.SendMessage(en.logChatID, message, &gotgbot.SendMessageOpts{
+		ParseMode: "Markdown",
+	})
+	if err != nil {
+		en.logger.Warn("Failed to post notification to the log chat",
+			zap.Int64("chat_id", en.logChatID),
+			zap.Error(err))
+	}
+}
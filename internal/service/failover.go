@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// failoverLeaseName is the worker_locks row held by the active instance
+const failoverLeaseName = "failover:active"
+
+// ErrLeaseLost is returned when the active instance can no longer be sure it
+// holds the failover lease and has to stop polling
+var ErrLeaseLost = errors.New("failover lease lost")
+
+// Failover elects the active instance of an active/standby deployment with a
+// lease in the worker_locks table. The active instance renews the lease every
+// heartbeat; a standby checks it just as often and takes over once it expires.
+type Failover struct {
+	repo         repository.WorkerLockRepository
+	instanceID   string
+	lease        time.Duration
+	heartbeat    time.Duration
+	fencingDelay time.Duration
+	logger       *zap.Logger
+
+	expiresAt time.Time // When the lease last renewed by this instance expires
+}
+
+func NewFailover(repo repository.WorkerLockRepository, cfg *config.FailoverConfig, logger *zap.Logger) *Failover {
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "instance"
+		}
+		instanceID = hostname + "-" + uuid.NewString()[:8]
+	}
+	return &Failover{
+		repo:         repo,
+		instanceID:   instanceID,
+		lease:        time.Duration(cfg.LeaseSeconds) * time.Second,
+		heartbeat:    time.Duration(cfg.HeartbeatSeconds) * time.Second,
+		fencingDelay: time.Duration(cfg.FencingDelaySeconds) * time.Second,
+		logger:       logger.With(zap.String("instance_id", instanceID)),
+	}
+}
+
+// Acquire blocks while another instance holds the lease. Once this instance
+// takes it, Acquire keeps renewing it through the fencing delay, which gives a
+// previous active instance that lost the lease time to stop polling first.
+func (f *Failover) Acquire(ctx context.Context) error {
+	standby := false
+	for {
+		expiresAt := time.Now().Add(f.lease)
+		acquired, err := f.repo.TryAcquire(failoverLeaseName, f.instanceID, expiresAt)
+		if err != nil {
+			f.logger.Warn("Failed to check the failover lease", zap.Error(err))
+		} else if acquired {
+			f.expiresAt = expiresAt
+			break
+		} else if !standby {
+			f.logger.Info("Another instance is active, standing by")
+			standby = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.heartbeat):
+		}
+	}
+
+	f.logger.Info("Failover lease acquired, waiting for the fencing delay",
+		zap.Duration("fencing_delay", f.fencingDelay))
+	timer := time.NewTimer(f.fencingDelay)
+	defer timer.Stop()
+	return f.hold(ctx, timer.C)
+}
+
+// Hold renews the lease until ctx is done and returns nil, or returns
+// ErrLeaseLost once the lease is taken over or cannot be renewed before it
+// expires. The lease is not released; call Release after polling has stopped.
+func (f *Failover) Hold(ctx context.Context) error {
+	err := f.hold(ctx, nil)
+	if errors.Is(err, ErrLeaseLost) {
+		return err
+	}
+	return nil
+}
+
+// Release gives up the lease so a standby can take over without waiting for
+// it to expire
+func (f *Failover) Release() {
+	if err := f.repo.Release(failoverLeaseName, f.instanceID); err != nil {
+		f.logger.Warn("Failed to release the failover lease", zap.Error(err))
+		return
+	}
+	f.logger.Info("Failover lease released")
+}
+
+// hold renews the lease every heartbeat until ctx is done, done fires or the
+// lease is lost. A nil done never fires.
+func (f *Failover) hold(ctx context.Context, done <-chan time.Time) error {
+	ticker := time.NewTicker(f.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			return nil
+		case <-ticker.C:
+		}
+		if err := f.renew(); err != nil {
+			return err
+		}
+	}
+}
+
+// renew extends the lease. A failed renewal is retried on the next heartbeat
+// unless the lease would expire before then, since a standby may take over as
+// soon as it does.
+func (f *Failover) renew() error {
+	expiresAt := time.Now().Add(f.lease)
+	renewed, err := f.repo.TryAcquire(failoverLeaseName, f.instanceID, expiresAt)
+	if err != nil {
+		if time.Now().Add(f.heartbeat).Before(f.expiresAt) {
+			f.logger.Warn("Failed to renew the failover lease, retrying", zap.Error(err))
+			return nil
+		}
+		return fmt.Errorf("%w: not renewed before it expires: %v", ErrLeaseLost, err)
+	}
+	if !renewed {
+		return fmt.Errorf("%w: taken over by another instance", ErrLeaseLost)
+	}
+	f.expiresAt = expiresAt
+	return nil
+}
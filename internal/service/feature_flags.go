@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+)
+
+// Feature is a capability that depends on the manager's subscription tier
+type Feature string
+
+const (
+	FeatureBroadcast   Feature = "broadcast"
+	FeatureArchive     Feature = "archive"
+	FeatureTranslation Feature = "translation" // Reserved: nothing is translated yet
+)
+
+// FeatureFlags decides which features a manager may use based on their tier.
+// Handlers consult it instead of checking tiers themselves, so the limits of
+// each tier live in one place (the tiers config section).
+type FeatureFlags struct {
+	config     *config.Config
+	superusers *Superusers
+	userRepo   repository.UserRepository
+	botRepo    repository.BotRepository
+}
+
+func NewFeatureFlags(cfg *config.Config, superusers *Superusers, userRepo repository.UserRepository, botRepo repository.BotRepository) *FeatureFlags {
+	return &FeatureFlags{
+		config:     cfg,
+		superusers: superusers,
+		userRepo:   userRepo,
+		botRepo:    botRepo,
+	}
+}
+
+// EffectiveTier returns the tier whose limits apply to the user. Superusers always get pro.
+func (f *FeatureFlags) EffectiveTier(user *models.User) models.UserTier {
+	if f.superusers.IsSuperuser(user.TelegramUserID) {
+		return models.UserTierPro
+	}
+	if user.Tier == models.UserTierPro {
+		return models.UserTierPro
+	}
+	return models.UserTierFree
+}
+
+func (f *FeatureFlags) tierConfig(user *models.User) config.TierConfig {
+	if f.EffectiveTier(user) == models.UserTierPro {
+		return f.config.Tiers.Pro
+	}
+	return f.config.Tiers.Free
+}
+
+// IsEnabled reports whether the user's tier includes the feature
+func (f *FeatureFlags) IsEnabled(user *models.User, feature Feature) bool {
+	tier := f.tierConfig(user)
+	switch feature {
+	case FeatureBroadcast:
+		return tier.Broadcast
+	case FeatureArchive:
+		return tier.Archive
+	case FeatureTranslation:
+		return tier.Translation
+	default:
+		return false
+	}
+}
+
+// IsEnabledForBot reports whether the tier of the bot's manager includes the feature
+func (f *FeatureFlags) IsEnabledForBot(botID uuid.UUID, feature Feature) (bool, error) {
+	bot, err := f.botRepo.GetByID(botID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bot: %w", err)
+	}
+	manager, err := f.userRepo.GetByID(bot.ManagerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get manager: %w", err)
+	}
+	return f.IsEnabled(manager, feature), nil
+}
+
+// MaxBots returns how many bots the user may register; 0 is unlimited
+func (f *FeatureFlags) MaxBots(user *models.User) int {
+	return f.tierConfig(user).MaxBots
+}
+
+// CanAddBot reports whether the user may register another bot, along with their limit
+func (f *FeatureFlags) CanAddBot(user *models.User) (bool, int, error) {
+	limit := f.MaxBots(user)
+	if limit == 0 {
+		return true, 0, nil
+	}
+	bots, err := f.botRepo.GetByManagerID(user.ID)
+	if err != nil {
+		return false, limit, fmt.Errorf("failed to get bots: %w", err)
+	}
+	return len(bots) < limit, limit, nil
+}
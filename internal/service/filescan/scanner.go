@@ -0,0 +1,176 @@
+// Package filescan checks files guests send for malware before they are
+// forwarded. It is only used when enabled in the config.
+package filescan
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Verdict is the outcome of scanning one file
+type Verdict struct {
+	Infected bool
+	Threat   string // Name of the detected threat, if known
+}
+
+// Scanner checks a file for malware
+type Scanner interface {
+	Scan(ctx context.Context, data []byte, filename string) (Verdict, error)
+}
+
+// clamAVScanner streams files to a clamd daemon with the INSTREAM command
+type clamAVScanner struct {
+	network string // "unix" or "tcp"
+	address string
+}
+
+// clamdChunkSize is how much of the file is sent per INSTREAM chunk
+const clamdChunkSize = 64 << 10
+
+// newClamAVScanner parses a clamd address: unix:///path/to/clamd.sock,
+// tcp://host:port or host:port
+func newClamAVScanner(address string) (*clamAVScanner, error) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		return &clamAVScanner{network: "unix", address: strings.TrimPrefix(address, "unix://")}, nil
+	case strings.HasPrefix(address, "tcp://"):
+		return &clamAVScanner{network: "tcp", address: strings.TrimPrefix(address, "tcp://")}, nil
+	case strings.Contains(address, "://"):
+		return nil, fmt.Errorf("unsupported clamd address: %s", address)
+	default:
+		return &clamAVScanner{network: "tcp", address: address}, nil
+	}
+}
+
+func (s *clamAVScanner) Scan(ctx context.Context, data []byte, _ string) (Verdict, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return Verdict{}, fmt.Errorf("failed to set clamd deadline: %w", err)
+		}
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Verdict{}, fmt.Errorf("failed to send to clamd: %w", err)
+	}
+	var size [4]byte
+	for start := 0; start < len(data); start += clamdChunkSize {
+		chunk := data[start:min(start+clamdChunkSize, len(data))]
+		binary.BigEndian.PutUint32(size[:], uint32(len(chunk)))
+		if _, err := conn.Write(size[:]); err != nil {
+			return Verdict{}, fmt.Errorf("failed to send to clamd: %w", err)
+		}
+		if _, err := conn.Write(chunk); err != nil {
+			return Verdict{}, fmt.Errorf("failed to send to clamd: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	if _, err := conn.Write(size[:]); err != nil {
+		return Verdict{}, fmt.Errorf("failed to send to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return Verdict{}, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamdReply(reply)
+}
+
+// parseClamdReply reads replies like "stream: OK" and "stream: Eicar-Signature FOUND"
+func parseClamdReply(reply string) (Verdict, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return Verdict{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return Verdict{Infected: true, Threat: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		return Verdict{}, fmt.Errorf("clamd error: %s", reply)
+	}
+}
+
+// virusTotalScanner looks files up on VirusTotal by their SHA-256 hash. Files
+// are never uploaded, so guests' files stay private; files VirusTotal has not
+// seen before count as clean.
+type virusTotalScanner struct {
+	apiKey        string
+	minDetections int
+	httpClient    *http.Client
+}
+
+const virusTotalFileURL = "https://www.virustotal.com/api/v3/files/"
+
+type virusTotalResponse struct {
+	Data struct {
+		Attributes struct {
+			LastAnalysisStats struct {
+				Malicious int `json:"malicious"`
+			} `json:"last_analysis_stats"`
+			PopularThreatClassification struct {
+				SuggestedThreatLabel string `json:"suggested_threat_label"`
+			} `json:"popular_threat_classification"`
+		} `json:"attributes"`
+	} `json:"data"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *virusTotalScanner) Scan(ctx context.Context, data []byte, _ string) (Verdict, error) {
+	sum := sha256.Sum256(data)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, virusTotalFileURL+hex.EncodeToString(sum[:]), nil)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("x-apikey", s.apiKey)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Verdict{}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	var response virusTotalResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Verdict{}, fmt.Errorf("failed to decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if response.Error != nil {
+		return Verdict{}, fmt.Errorf("VirusTotal error (status %d): %s", resp.StatusCode, response.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	attributes := response.Data.Attributes
+	if attributes.LastAnalysisStats.Malicious < s.minDetections {
+		return Verdict{}, nil
+	}
+	threat := attributes.PopularThreatClassification.SuggestedThreatLabel
+	if threat == "" {
+		threat = fmt.Sprintf("%d detections", attributes.LastAnalysisStats.Malicious)
+	}
+	return Verdict{Infected: true, Threat: threat}, nil
+}
@@ -0,0 +1,87 @@
+package filescan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+// maxFileSize is the largest file bots may download from Telegram
+const maxFileSize = 20 << 20
+
+// ErrTooLarge is returned for files bots cannot download, so they cannot be scanned
+var ErrTooLarge = errors.New("file is too large to scan")
+
+// Service downloads files from Telegram and scans them
+type Service struct {
+	scanner Scanner
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewService creates the service with the scanner selected by file_scan.provider
+func NewService(cfg *config.FileScanConfig, proxy *config.ProxyConfig, logger *zap.Logger) (*Service, error) {
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+
+	var scanner Scanner
+	switch cfg.Provider {
+	case "", "clamav":
+		clamAV, err := newClamAVScanner(cfg.ClamAVAddress)
+		if err != nil {
+			return nil, err
+		}
+		scanner = clamAV
+	case "virustotal":
+		httpClient, err := utils.CreateHTTPClientWithProxy(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		httpClient.Timeout = timeout
+		scanner = &virusTotalScanner{
+			apiKey:        cfg.VirusTotalAPIKey,
+			minDetections: cfg.VirusTotalMinDetections,
+			httpClient:    httpClient,
+		}
+	default:
+		return nil, fmt.Errorf("unknown file scan provider: %s", cfg.Provider)
+	}
+
+	return &Service{
+		scanner: scanner,
+		timeout: timeout,
+		logger:  logger,
+	}, nil
+}
+
+// ScanDocument downloads a document and scans it
+func (s *Service) ScanDocument(ctx context.Context, bot *gotgbot.Bot, doc *gotgbot.Document) (Verdict, error) {
+	if doc.FileSize > maxFileSize {
+		return Verdict{}, ErrTooLarge
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	data, err := utils.DownloadFile(ctx, bot, doc.FileId, maxFileSize)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	verdict, err := s.scanner.Scan(ctx, data, doc.FileName)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to scan file: %w", err)
+	}
+	s.logger.Debug("File scanned",
+		zap.Int("file_bytes", len(data)),
+		zap.Bool("infected", verdict.Infected),
+		zap.Duration("took", time.Since(start)))
+	return verdict, nil
+}
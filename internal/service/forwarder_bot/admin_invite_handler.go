@@ -0,0 +1,322 @@
+package forwarder_bot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// adminInvitePayloadPrefix marks /start deep link payloads that carry an admin invite token
+	adminInvitePayloadPrefix = "admininvite_"
+
+	// adminInviteTTL is how long an invite link can be opened
+	adminInviteTTL = 24 * time.Hour
+)
+
+// startPayload returns the deep link parameter of a /start command, if any
+func startPayload(command string) string {
+	parts := strings.Fields(command)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+func newInviteToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// handleInviteAdmin creates a one-time admin invite link for the manager to share
+func (s *Service) handleInviteAdmin(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	manager, err := s.userRepo.GetByTelegramUserID(update.EffectiveUser.Id)
+	if err != nil {
+		s.logger.Error("Failed to get manager user", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		s.logger.Error("Failed to generate invite token", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	invite := &models.AdminInvite{
+		BotID:           s.botID,
+		Token:           token,
+		CreatedByUserID: manager.ID,
+		Status:          models.AdminInviteStatusPending,
+		ExpiresAt:       time.Now().Add(adminInviteTTL),
+	}
+	if err := s.adminInviteRepo.Create(invite); err != nil {
+		s.logger.Error("Failed to create admin invite", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to create invite. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Admin invite created",
+		zap.String("bot_id", s.botID.String()),
+		zap.String("invite_id", invite.ID.String()),
+		zap.Int64("manager_user_id", update.EffectiveUser.Id))
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s%s", b.User.Username, adminInvitePayloadPrefix, token)
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Send this one-time link to the person you want to add as admin:\n\n%s\n\n"+
+			"The link expires on %s. You will be asked to confirm before they become admin.",
+			link, s.formatTime(invite.ExpiresAt)), nil)
+	return err
+}
+
+// handleAdminInviteStart is called when a user opens an admin invite link.
+// The invite is claimed and the manager is asked to confirm.
+func (s *Service) handleAdminInviteStart(ctx context.Context, b *gotgbot.Bot, update *ext.Context, token string) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+
+	invite, err := s.adminInviteRepo.GetByToken(token)
+	if err != nil || invite.BotID != s.botID {
+		_, err := b.SendMessage(chatID, "This invite link is invalid.", nil)
+		return err
+	}
+	if invite.Status != models.AdminInviteStatusPending {
+		_, err := b.SendMessage(chatID, "This invite link has already been used.", nil)
+		return err
+	}
+	if invite.IsExpired() {
+		_, err := b.SendMessage(chatID, "This invite link has expired. Please ask the manager for a new one.", nil)
+		return err
+	}
+
+	isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID)
+	if isManagerOrAdmin {
+		_, err := b.SendMessage(chatID, "You are already the manager or an admin of this bot.", nil)
+		return err
+	}
+
+	var usernamePtr *string
+	if update.EffectiveUser.Username != "" {
+		username := update.EffectiveUser.Username
+		usernamePtr = &username
+	}
+
+	claimed, err := s.adminInviteRepo.Claim(invite.ID, userID, usernamePtr)
+	if err != nil {
+		s.logger.Error("Failed to claim admin invite", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if !claimed {
+		_, err := b.SendMessage(chatID, "This invite link has already been used.", nil)
+		return err
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot for admin invite", zap.Error(err))
+		s.unclaimAdminInvite(invite)
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	candidate := fmt.Sprintf("%d", userID)
+	if usernamePtr != nil {
+		candidate = fmt.Sprintf("@%s (%d)", *usernamePtr, userID)
+	}
+	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "✅ Approve", CallbackData: fmt.Sprintf("admininvite:approve:%s", invite.ID.String())},
+			{Text: "❌ Reject", CallbackData: fmt.Sprintf("admininvite:reject:%s", invite.ID.String())},
+		},
+	}}
+	_, err = b.SendMessage(bot.Manager.TelegramUserID,
+		fmt.Sprintf("*Admin Invite*\n\n%s opened your admin invite link and wants to become an admin of @%s.",
+			utils.EscapeMarkdown(candidate), utils.EscapeMarkdown(bot.Name)),
+		&gotgbot.SendMessageOpts{
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+	if err != nil {
+		s.logger.Error("Failed to send admin invite confirmation to manager", zap.Error(err))
+		// The manager never saw the request, so the link must work again
+		s.unclaimAdminInvite(invite)
+		_, err := b.SendMessage(chatID, "Could not reach the manager. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Admin invite claimed",
+		zap.String("bot_id", s.botID.String()),
+		zap.String("invite_id", invite.ID.String()),
+		zap.Int64("user_id", userID))
+
+	_, err = b.SendMessage(chatID, "Your request has been sent to the manager. You will be notified once it is confirmed.", nil)
+	return err
+}
+
+func (s *Service) handleAdminInviteCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	userID := update.EffectiveUser.Id
+
+	if len(parts) < 2 || (parts[0] != "approve" && parts[0] != "reject") {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+
+	isManager, err := s.IsManager(ctx, userID)
+	if err != nil || !isManager {
+		s.accessDenied(ctx, update, "admin invite callback")
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Only the manager can confirm admin invites.",
+		})
+		return err
+	}
+
+	inviteID, err := uuid.Parse(parts[1])
+	if err != nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid invite ID",
+		})
+		return err
+	}
+
+	invite, err := s.adminInviteRepo.GetByID(inviteID)
+	if err != nil || invite.BotID != s.botID || invite.ClaimedByTelegramUserID == nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invite not found",
+		})
+		return err
+	}
+
+	status := models.AdminInviteStatusRejected
+	if parts[0] == "approve" {
+		status = models.AdminInviteStatusAccepted
+	}
+
+	// Accepting adds the admin in the same transaction, so a failure leaves
+	// the invite claimed and the manager can try again
+	var resolved bool
+	if status == models.AdminInviteStatusAccepted {
+		resolved, err = s.addAdminFromInvite(update.EffectiveUser.Id, invite)
+	} else {
+		resolved, err = s.adminInviteRepo.Resolve(inviteID, status)
+	}
+	if err != nil {
+		s.logger.Error("Failed to resolve admin invite", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "An error occurred. Please try again later.",
+		})
+		return err
+	}
+	if !resolved {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "This invite has already been handled.",
+		})
+		return err
+	}
+
+	candidateID := *invite.ClaimedByTelegramUserID
+	resultText := fmt.Sprintf("Admin invite for %d rejected.", candidateID)
+	candidateText := "Your admin request was rejected by the manager."
+
+	if status == models.AdminInviteStatusAccepted {
+		resultText = fmt.Sprintf("User %d has been added as admin successfully!", candidateID)
+		candidateText = "The manager approved your request. You are now an admin of this bot. Use /help to see available commands."
+	}
+
+	s.logger.Info("Admin invite resolved",
+		zap.String("bot_id", s.botID.String()),
+		zap.String("invite_id", inviteID.String()),
+		zap.String("status", string(status)),
+		zap.Int64("candidate_user_id", candidateID))
+
+	_, err = b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: resultText,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	if update.CallbackQuery.Message != nil {
+		_, _, err = b.EditMessageText(resultText, &gotgbot.EditMessageTextOpts{
+			ChatId:    update.EffectiveChat.Id,
+			MessageId: update.CallbackQuery.Message.GetMessageId(),
+		})
+		if err != nil {
+			s.logger.Warn("Failed to update admin invite message", zap.Error(err))
+		}
+	}
+
+	if _, err := b.SendMessage(candidateID, candidateText, nil); err != nil {
+		s.logger.Warn("Failed to notify invited user",
+			zap.Int64("candidate_user_id", candidateID),
+			zap.Error(err))
+	}
+	return nil
+}
+
+// addAdminFromInvite accepts the claimed invite and registers the user who
+// claimed it as admin. It reports false if the invite was already handled.
+func (s *Service) addAdminFromInvite(managerTelegramUserID int64, invite *models.AdminInvite) (bool, error) {
+	adminUser, err := s.userRepo.GetOrCreateByTelegramUserID(*invite.ClaimedByTelegramUserID, invite.ClaimedByUsername)
+	if err != nil {
+		return false, fmt.Errorf("failed to get or create admin user: %w", err)
+	}
+
+	botAdmin, accepted, err := s.adminInviteRepo.Accept(invite.ID, s.botID, adminUser.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to add admin: %w", err)
+	}
+	// Nothing to record if the invite was handled already or the user was an admin before
+	if !accepted || botAdmin == nil {
+		return accepted, nil
+	}
+
+	// Log audit
+	user, _ := s.userRepo.GetByTelegramUserID(managerTelegramUserID)
+	if user != nil {
+		details, _ := json.Marshal(map[string]interface{}{
+			"admin_user_id": *invite.ClaimedByTelegramUserID,
+			"invite_id":     invite.ID.String(),
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &user.ID,
+			ActionType:   models.AuditLogActionAddAdmin,
+			ResourceType: "admin",
+			ResourceID:   botAdmin.ID,
+			Details:      string(details),
+		}
+		s.auditLogRepo.Create(auditLog)
+	}
+	return true, nil
+}
+
+// unclaimAdminInvite makes a claimed invite usable again after the claim
+// could not be passed on to the manager
+func (s *Service) unclaimAdminInvite(invite *models.AdminInvite) {
+	if err := s.adminInviteRepo.Unclaim(invite.ID); err != nil {
+		s.logger.Warn("Failed to reopen admin invite",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("invite_id", invite.ID.String()),
+			zap.Error(err))
+	}
+}
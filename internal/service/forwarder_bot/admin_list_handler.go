@@ -0,0 +1,124 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/message"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// adminStatus is the result of checking an admin's account on Telegram
+type adminStatus string
+
+const (
+	adminStatusActive     adminStatus = "✅ active"
+	adminStatusBlocked    adminStatus = "🚫 blocked the bot"
+	adminStatusNotFound   adminStatus = "❓ not found on Telegram"
+	adminStatusUnverified adminStatus = "⚠️ could not verify"
+)
+
+func (s *Service) handleListAdmins(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	admins, err := s.botAdminRepo.GetByBotID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get admins", zap.Error(err))
+		_, err := b.SendMessage(update.EffectiveChat.Id,
+			"An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	if len(admins) == 0 {
+		_, err := b.SendMessage(update.EffectiveChat.Id,
+			"No admins configured.", nil)
+		return err
+	}
+
+	adminIDs := make([]uuid.UUID, 0, len(admins))
+	for _, admin := range admins {
+		adminIDs = append(adminIDs, admin.ID)
+	}
+	addedLogs, err := s.auditLogRepo.GetLatestByResources(models.AuditLogActionAddAdmin, adminIDs)
+	if err != nil {
+		s.logger.Warn("Failed to get admin audit logs", zap.Error(err))
+	}
+
+	var text strings.Builder
+	text.WriteString("*Admins:*\n\n")
+	loc := s.location()
+	for i, admin := range admins {
+		status := s.checkAdminStatus(b, &admin.AdminUser)
+
+		username := "Unknown"
+		if admin.AdminUser.Username != nil {
+			username = *admin.AdminUser.Username
+		}
+		text.WriteString(fmt.Sprintf("%d. @%s (%d) - %s\n", i+1,
+			utils.EscapeMarkdown(username), admin.AdminUser.TelegramUserID, status))
+		text.WriteString(fmt.Sprintf("   Added %s%s\n",
+			utils.FormatTimestamp(admin.CreatedAt, loc), adminAddedBy(addedLogs[admin.ID])))
+	}
+
+	_, err = b.SendMessage(update.EffectiveChat.Id, text.String(), &gotgbot.SendMessageOpts{
+		ParseMode: "Markdown",
+	})
+	return err
+}
+
+// checkAdminStatus verifies the admin's account still exists and has not blocked the bot.
+// A username change seen on Telegram is stored on the user record.
+func (s *Service) checkAdminStatus(b *gotgbot.Bot, adminUser *models.User) adminStatus {
+	chat, err := b.GetChat(adminUser.TelegramUserID, nil)
+	if err != nil {
+		return s.classifyAdminError(adminUser, err)
+	}
+
+	if chat.Username != "" && (adminUser.Username == nil || *adminUser.Username != chat.Username) {
+		username := chat.Username
+		adminUser.Username = &username
+		if err := s.userRepo.Update(adminUser); err != nil {
+			s.logger.Warn("Failed to update admin username",
+				zap.Int64("admin_user_id", adminUser.TelegramUserID),
+				zap.Error(err))
+		}
+	}
+
+	// GetChat succeeds for users who blocked the bot, so probe with a chat action
+	if _, err := b.SendChatAction(adminUser.TelegramUserID, "typing", nil); err != nil {
+		return s.classifyAdminError(adminUser, err)
+	}
+	return adminStatusActive
+}
+
+func (s *Service) classifyAdminError(adminUser *models.User, err error) adminStatus {
+	s.logger.Debug("Admin validation failed",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("admin_user_id", adminUser.TelegramUserID),
+		zap.Error(err))
+
+	switch message.ClassifyError(err) {
+	case message.FailureReasonForbidden:
+		return adminStatusBlocked
+	case message.FailureReasonChatNotFound, message.FailureReasonBadRequest:
+		return adminStatusNotFound
+	default:
+		return adminStatusUnverified
+	}
+}
+
+// adminAddedBy returns " by <user>" from the admin's add_admin audit log, or "" if unknown
+func adminAddedBy(auditLog *models.AuditLog) string {
+	if auditLog == nil || auditLog.User == nil {
+		return ""
+	}
+	if auditLog.User.Username != nil {
+		return fmt.Sprintf(" by @%s", utils.EscapeMarkdown(*auditLog.User.Username))
+	}
+	return fmt.Sprintf(" by %d", auditLog.User.TelegramUserID)
+}
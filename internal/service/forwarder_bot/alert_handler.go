@@ -0,0 +1,187 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// raiseKeywordAlert checks a forwarded guest message, including imageText read
+// from its image, for the bot's alert keywords. On a match the conversation is
+// tagged and the manager is mentioned under each recipient's copy of the message.
+func (s *Service) raiseKeywordAlert(b *gotgbot.Bot, guestChatID int64, guestUserID int64, msg *gotgbot.Message, imageText string) {
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot for keyword alert", zap.Error(err))
+		return
+	}
+	keywords := utils.ParseKeywordList(bot.AlertKeywords)
+	if len(keywords) == 0 {
+		return
+	}
+	matched := utils.MatchKeywords(msg.Text+"\n"+msg.Caption+"\n"+imageText, keywords)
+	if len(matched) == 0 {
+		return
+	}
+
+	tag := strings.Join(matched, ", ")
+	s.logger.Info("Guest message matched alert keywords",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", guestUserID),
+		zap.Int64("message_id", msg.MessageId),
+		zap.Strings("keywords", matched))
+
+	if err := s.guestRepo.TagAlert(s.botID, guestUserID, tag); err != nil {
+		s.logger.Warn("Failed to tag conversation with alert",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", guestUserID),
+			zap.Error(err))
+	}
+
+	text := fmt.Sprintf("🚨 *Keyword alert:* %s", utils.EscapeMarkdown(tag))
+	if manager, err := s.userRepo.GetByID(bot.ManagerID); err == nil {
+		text += fmt.Sprintf("\n[Manager](tg://user?id=%d), this conversation needs attention.", manager.TelegramUserID)
+	} else {
+		s.logger.Warn("Failed to get manager for keyword alert", zap.Error(err))
+	}
+
+	mappings, err := s.messageMappingRepo.GetAllByGuestMessage(s.botID, guestChatID, msg.MessageId)
+	if err != nil {
+		s.logger.Warn("Failed to get message mappings for keyword alert",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("guest_message_id", msg.MessageId),
+			zap.Error(err))
+		return
+	}
+	for _, mapping := range mappings {
+		_, err := b.SendMessage(mapping.RecipientChatID, text, &gotgbot.SendMessageOpts{
+			ParseMode: "Markdown",
+			ReplyParameters: &gotgbot.ReplyParameters{
+				MessageId:                mapping.RecipientMessageID,
+				AllowSendingWithoutReply: true,
+			},
+		})
+		if err != nil {
+			s.logger.Warn("Failed to send keyword alert",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("recipient_chat_id", mapping.RecipientChatID),
+				zap.Error(err))
+		}
+	}
+}
+
+// handleSetAlerts sets (or with "off", clears) the bot's alert keywords
+func (s *Service) handleSetAlerts(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	arg := ""
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		arg = strings.TrimSpace(parts[1])
+	}
+	if arg == "" {
+		current := "none"
+		if keywords := utils.ParseKeywordList(bot.AlertKeywords); len(keywords) > 0 {
+			current = strings.Join(keywords, ", ")
+		}
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("Current alert keywords: %s\n\n"+
+				"Usage: /setalerts <keyword, keyword, ...>\n"+
+				"Example: /setalerts refund, lawyer, urgent\n"+
+				"Use /setalerts off to disable keyword alerts.", current), nil)
+		return err
+	}
+
+	var keywords []string
+	if !strings.EqualFold(arg, "off") {
+		keywords = utils.ParseKeywordList(arg)
+	}
+	bot.AlertKeywords = strings.Join(keywords, ",")
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update alert keywords", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update alert keywords. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Alert keywords updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Strings("keywords", keywords))
+
+	if len(keywords) == 0 {
+		_, err = b.SendMessage(chatID, "Keyword alerts have been disabled.", nil)
+		return err
+	}
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Alert keywords set: %s\nGuest messages containing them will ping the manager and tag the conversation.",
+			strings.Join(keywords, ", ")), nil)
+	return err
+}
+
+// handleAlerts lists conversations tagged by keyword alerts, or clears a tag
+func (s *Service) handleAlerts(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	parts := strings.Fields(update.EffectiveMessage.Text)
+
+	if len(parts) >= 2 && strings.EqualFold(parts[1], "clear") {
+		if len(parts) < 3 {
+			_, err := b.SendMessage(chatID, "Usage: /alerts clear <user_id>", nil)
+			return err
+		}
+		guestUserID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			_, err := b.SendMessage(chatID, "Invalid user ID.", nil)
+			return err
+		}
+		cleared, err := s.guestRepo.ClearAlert(s.botID, guestUserID)
+		if err != nil {
+			s.logger.Error("Failed to clear alert tag", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to clear the alert. Please try again later.", nil)
+			return err
+		}
+		if !cleared {
+			_, err := b.SendMessage(chatID, fmt.Sprintf("User %d has no open alert.", guestUserID), nil)
+			return err
+		}
+		_, err = b.SendMessage(chatID, fmt.Sprintf("Alert for user %d cleared.", guestUserID), nil)
+		return err
+	}
+
+	guests, err := s.guestRepo.GetAlertedByBotID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get alerted guests", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if len(guests) == 0 {
+		_, err := b.SendMessage(chatID, "No conversations are tagged with alerts.", nil)
+		return err
+	}
+
+	var text strings.Builder
+	text.WriteString("Conversations tagged with alerts:\n")
+	loc := s.location()
+	for _, guest := range guests {
+		alertedAt := ""
+		if guest.AlertedAt != nil {
+			alertedAt = guest.AlertedAt.In(loc).Format("2006-01-02 15:04")
+		}
+		text.WriteString(fmt.Sprintf("\nUser %d: %s (%s)", guest.GuestUserID, guest.AlertKeyword, alertedAt))
+	}
+	text.WriteString("\n\nUse /alerts clear <user_id> once a conversation is handled.")
+	_, err = b.SendMessage(chatID, text.String(), nil)
+	return err
+}
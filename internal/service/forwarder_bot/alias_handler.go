@@ -0,0 +1,73 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// maxRecipientAliasLength matches the Recipient.Alias column size
+const maxRecipientAliasLength = 64
+
+// handleSetAlias sets (or with no alias, clears) the manager-chosen display name of a recipient
+func (s *Service) handleSetAlias(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	parts := strings.SplitN(strings.TrimSpace(update.EffectiveMessage.Text), " ", 3)
+	if len(parts) < 2 {
+		_, err := b.SendMessage(chatID,
+			"Usage: /setalias <chat_id> [alias]\nExample: /setalias -1001234567890 Support team\nOmit the alias to clear it.", nil)
+		return err
+	}
+
+	recipientChatID, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Invalid chat ID: %v", err), nil)
+		return err
+	}
+
+	alias := ""
+	if len(parts) == 3 {
+		alias = strings.TrimSpace(parts[2])
+	}
+	if utf8.RuneCountInString(alias) > maxRecipientAliasLength {
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("Alias is too long (maximum %d characters).", maxRecipientAliasLength), nil)
+		return err
+	}
+
+	recipient, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, recipientChatID)
+	if err != nil {
+		_, err := b.SendMessage(chatID, "Recipient not found.", nil)
+		return err
+	}
+
+	if alias == "" {
+		recipient.Alias = nil
+	} else {
+		recipient.Alias = &alias
+	}
+	if err := s.recipientRepo.Update(recipient); err != nil {
+		s.logger.Error("Failed to update recipient alias", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update alias. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Recipient alias updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("recipient_chat_id", recipientChatID),
+		zap.String("alias", alias),
+		zap.Int64("user_id", update.EffectiveUser.Id))
+
+	if alias == "" {
+		_, err = b.SendMessage(chatID, fmt.Sprintf("Alias cleared. Recipient is now shown as %s.", recipient.Label()), nil)
+		return err
+	}
+	_, err = b.SendMessage(chatID, fmt.Sprintf("Recipient is now shown as %s.", recipient.Label()), nil)
+	return err
+}
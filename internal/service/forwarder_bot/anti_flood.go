@@ -0,0 +1,72 @@
+package forwarder_bot
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/i18n"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/message"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+// SetFloodDetector makes the bot mute guests who send too many messages
+// within a short time
+func (s *Service) SetFloodDetector(detector *message.FloodDetector) {
+	s.floodDetector = detector
+}
+
+// muteIfFlooding counts a guest message and, once the guest goes over the
+// anti-flood limit, blacklists the guest for a while and tells them so. It
+// reports whether the guest was muted, in which case the message is dropped.
+// The bot's manager and admins are never muted.
+func (s *Service) muteIfFlooding(ctx context.Context, b *gotgbot.Bot, chatID int64, userID int64, lang string) bool {
+	if s.floodDetector == nil || !s.floodDetector.Message(s.botID, userID, time.Now()) {
+		return false
+	}
+	if isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID); isManagerOrAdmin {
+		return false
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot for anti-flood mute", zap.Error(err))
+		return false
+	}
+	mute := time.Duration(s.config.AntiFlood.MuteSeconds) * time.Second
+	ban, err := s.blacklistService.Mute(s.botID, userID, bot.ManagerID, time.Now().Add(mute), "Anti-flood: too many messages")
+	if err != nil {
+		s.logger.Warn("Failed to mute flooding guest",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		return false
+	}
+	s.logger.Info("Guest muted for flooding",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Time("expires_at", *ban.ExpiresAt))
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"blacklist_id":  ban.ID.String(),
+		"guest_user_id": userID,
+		"expires_at":    ban.ExpiresAt,
+	})
+	s.auditLogRepo.Create(&models.AuditLog{
+		ActionType:   models.AuditLogActionAutoMute,
+		ResourceType: "blacklist",
+		ResourceID:   ban.ID,
+		Details:      string(details),
+	})
+
+	if _, err := b.SendMessage(chatID, i18n.T(lang, "guest.muted", approximateDuration(lang, mute)), nil); err != nil {
+		s.logger.Warn("Failed to send anti-flood notice to guest",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+	return true
+}
@@ -0,0 +1,234 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// maxAutoReplies limits the number of auto-reply rules per bot
+const maxAutoReplies = 50
+
+const autoReplyUsage = "Usage:\n" +
+	"/autoreply - List rules and how often they were used\n" +
+	"/autoreply add <keyword, ...> | <reply> - Reply instantly and still forward the message\n" +
+	"/autoreply faq <keyword, ...> | <reply> - Reply instantly instead of forwarding the message\n" +
+	"/autoreply remove <number> - Remove a rule\n" +
+	"/autoreply reset - Reset the hit statistics\n\n" +
+	"Example: /autoreply faq pricing, price | Our price list: https://example.com/pricing"
+
+// applyAutoReply answers a guest message with the first auto-reply rule whose
+// keywords it contains. It reports whether the message should not be forwarded.
+func (s *Service) applyAutoReply(b *gotgbot.Bot, chatID int64, userID int64, msg *gotgbot.Message) bool {
+	rules, err := s.autoReplyRepo.GetByBotID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get auto-reply rules",
+			zap.String("bot_id", s.botID.String()),
+			zap.Error(err))
+		return false
+	}
+
+	text := msg.Text + "\n" + msg.Caption
+	for _, rule := range rules {
+		if len(utils.MatchKeywords(text, utils.ParseKeywordList(rule.Keywords))) == 0 {
+			continue
+		}
+
+		s.logger.Debug("Guest message matched auto-reply rule",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.String("rule_id", rule.ID.String()),
+			zap.Bool("suppress_forward", rule.SuppressForward))
+
+		_, err := b.SendMessage(chatID, rule.Reply, &gotgbot.SendMessageOpts{
+			ReplyParameters: &gotgbot.ReplyParameters{
+				MessageId:                msg.MessageId,
+				AllowSendingWithoutReply: true,
+			},
+		})
+		if err != nil {
+			s.logger.Warn("Failed to send auto-reply",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+			// The guest got no answer, so make sure the team sees the message
+			return false
+		}
+
+		if err := s.autoReplyRepo.RecordHit(s.botID, rule.ID); err != nil {
+			s.logger.Warn("Failed to record auto-reply hit",
+				zap.String("rule_id", rule.ID.String()),
+				zap.Error(err))
+		}
+		return rule.SuppressForward
+	}
+	return false
+}
+
+// handleAutoReply lists, adds and removes the bot's auto-reply rules
+func (s *Service) handleAutoReply(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+
+	args := ""
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		args = strings.TrimSpace(parts[1])
+	}
+	action, rest, _ := strings.Cut(args, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch strings.ToLower(action) {
+	case "":
+		return s.listAutoReplies(b, chatID)
+	case "add", "faq":
+		return s.addAutoReply(b, chatID, userID, rest, strings.EqualFold(action, "faq"))
+	case "remove":
+		return s.removeAutoReply(b, chatID, userID, rest)
+	case "reset":
+		if err := s.autoReplyRepo.ResetHits(s.botID); err != nil {
+			s.logger.Error("Failed to reset auto-reply statistics", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to reset the statistics. Please try again later.", nil)
+			return err
+		}
+		_, err := b.SendMessage(chatID, "Auto-reply statistics have been reset.", nil)
+		return err
+	default:
+		_, err := b.SendMessage(chatID, autoReplyUsage, nil)
+		return err
+	}
+}
+
+func (s *Service) listAutoReplies(b *gotgbot.Bot, chatID int64) error {
+	rules, err := s.autoReplyRepo.GetByBotID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get auto-reply rules", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if len(rules) == 0 {
+		_, err := b.SendMessage(chatID, "No auto-replies are set.\n\n"+autoReplyUsage, nil)
+		return err
+	}
+
+	var text strings.Builder
+	text.WriteString("Auto-replies (first match wins):\n")
+	for i, rule := range rules {
+		mode := "also forwarded"
+		if rule.SuppressForward {
+			mode = "FAQ, not forwarded"
+		}
+		fmt.Fprintf(&text, "\n%d. %s (%s)\n   → %s\n   %s hits",
+			i+1, strings.Join(utils.ParseKeywordList(rule.Keywords), ", "), mode,
+			truncateRunes(rule.Reply, 60), utils.FormatNumber(rule.HitCount))
+		if rule.LastHitAt != nil {
+			fmt.Fprintf(&text, ", last %s", utils.FormatShortDate(rule.LastHitAt.In(s.location())))
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString("\nUse /autoreply remove <number> to remove a rule.")
+
+	_, err = b.SendMessage(chatID, text.String(), nil)
+	return err
+}
+
+func (s *Service) addAutoReply(b *gotgbot.Bot, chatID int64, userID int64, args string, suppressForward bool) error {
+	keywordList, reply, ok := strings.Cut(args, "|")
+	keywords := utils.ParseKeywordList(keywordList)
+	reply = strings.TrimSpace(reply)
+	if !ok || len(keywords) == 0 || reply == "" {
+		_, err := b.SendMessage(chatID, autoReplyUsage, nil)
+		return err
+	}
+
+	rules, err := s.autoReplyRepo.GetByBotID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get auto-reply rules", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if len(rules) >= maxAutoReplies {
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("A bot can have at most %d auto-replies. Remove one first.", maxAutoReplies), nil)
+		return err
+	}
+
+	rule := &models.AutoReply{
+		BotID:           s.botID,
+		Keywords:        strings.Join(keywords, ","),
+		Reply:           reply,
+		SuppressForward: suppressForward,
+	}
+	if err := s.autoReplyRepo.Create(rule); err != nil {
+		s.logger.Error("Failed to create auto-reply rule", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to add the auto-reply. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Auto-reply rule added",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Strings("keywords", keywords),
+		zap.Bool("suppress_forward", suppressForward))
+
+	text := fmt.Sprintf("Auto-reply #%d added for: %s\nGuests will get the reply instantly", len(rules)+1, strings.Join(keywords, ", "))
+	if suppressForward {
+		text += " and their message will not be forwarded."
+	} else {
+		text += "; their message is still forwarded."
+	}
+	_, err = b.SendMessage(chatID, text, nil)
+	return err
+}
+
+func (s *Service) removeAutoReply(b *gotgbot.Bot, chatID int64, userID int64, arg string) error {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		_, err := b.SendMessage(chatID, "Usage: /autoreply remove <number>\nSee /autoreply for the numbers.", nil)
+		return err
+	}
+
+	rules, err := s.autoReplyRepo.GetByBotID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get auto-reply rules", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if n > len(rules) {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("There is no auto-reply #%d. See /autoreply for the list.", n), nil)
+		return err
+	}
+
+	rule := rules[n-1]
+	if err := s.autoReplyRepo.Delete(s.botID, rule.ID); err != nil {
+		s.logger.Error("Failed to delete auto-reply rule", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to remove the auto-reply. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Auto-reply rule removed",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.String("rule_id", rule.ID.String()))
+
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Auto-reply #%d (%s) removed.", n, strings.Join(utils.ParseKeywordList(rule.Keywords), ", ")), nil)
+	return err
+}
+
+// truncateRunes shortens s to at most n characters for previews
+func truncateRunes(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -3,10 +3,13 @@ package forwarder_bot
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
 	"go-telegram-forwarder-bot/internal/models"
+	blacklistservice "go-telegram-forwarder-bot/internal/service/blacklist"
+	"go-telegram-forwarder-bot/internal/utils"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
@@ -23,18 +26,17 @@ func (s *Service) sendApprovalRequestToManagersAndAdmins(
 	messageText string,
 	buttons [][]gotgbot.InlineKeyboardButton,
 ) error {
-	// Get bot manager
+	// Get bot manager, loaded with the bot
 	bot, err := s.botRepo.GetByID(s.botID)
 	if err != nil {
 		return fmt.Errorf("failed to get bot: %w", err)
 	}
-
-	manager, err := s.userRepo.GetByID(bot.ManagerID)
-	if err != nil {
-		return fmt.Errorf("failed to get manager: %w", err)
+	manager := &bot.Manager
+	if manager.ID == uuid.Nil {
+		return fmt.Errorf("failed to get manager of bot %s", s.botID)
 	}
 
-	// Get all admins
+	// Get all admins with their users
 	admins, err := s.botAdminRepo.GetByBotID(s.botID)
 	if err != nil {
 		s.logger.Warn("Failed to get admins", zap.Error(err))
@@ -42,52 +44,37 @@ func (s *Service) sendApprovalRequestToManagersAndAdmins(
 	}
 
 	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons}
-
-	// Send to manager
-	managerMsg, err := b.SendMessage(manager.TelegramUserID, messageText, &gotgbot.SendMessageOpts{
-		ParseMode:   "Markdown",
-		ReplyMarkup: keyboard,
-	})
-	if err != nil {
-		s.logger.Warn("Failed to send approval request to manager", zap.Error(err))
-	} else {
-		// Store message ID
-		approvalMsg := &models.BlacklistApprovalMessage{
-			BlacklistID: blacklistID,
-			UserID:      manager.ID,
-			ChatID:      manager.TelegramUserID,
-			MessageID:   managerMsg.MessageId,
-		}
-		if err := s.blacklistApprovalMessageRepo.Create(approvalMsg); err != nil {
-			s.logger.Warn("Failed to store approval message for manager", zap.Error(err))
-		}
+	approvers := make([]*models.User, 0, len(admins)+1)
+	approvers = append(approvers, manager)
+	for _, admin := range admins {
+		approvers = append(approvers, &admin.AdminUser)
 	}
 
-	// Send to all admins
-	for _, admin := range admins {
-		adminMsg, err := b.SendMessage(admin.AdminUser.TelegramUserID, messageText, &gotgbot.SendMessageOpts{
+	// Send to the manager and all admins, then store the message IDs in one insert
+	approvalMessages := make([]*models.BlacklistApprovalMessage, 0, len(approvers))
+	for _, approver := range approvers {
+		sent, err := b.SendMessage(approver.TelegramUserID, messageText, &gotgbot.SendMessageOpts{
 			ParseMode:   "Markdown",
 			ReplyMarkup: keyboard,
 		})
 		if err != nil {
-			s.logger.Warn("Failed to send approval request to admin",
-				zap.String("admin_id", admin.AdminUser.ID.String()),
+			s.logger.Warn("Failed to send approval request",
+				zap.String("user_id", approver.ID.String()),
+				zap.Bool("is_manager", approver.ID == bot.ManagerID),
 				zap.Error(err))
 			continue
 		}
-
-		// Store message ID
-		approvalMsg := &models.BlacklistApprovalMessage{
+		approvalMessages = append(approvalMessages, &models.BlacklistApprovalMessage{
 			BlacklistID: blacklistID,
-			UserID:      admin.AdminUser.ID,
-			ChatID:      admin.AdminUser.TelegramUserID,
-			MessageID:   adminMsg.MessageId,
-		}
-		if err := s.blacklistApprovalMessageRepo.Create(approvalMsg); err != nil {
-			s.logger.Warn("Failed to store approval message for admin",
-				zap.String("admin_id", admin.AdminUser.ID.String()),
-				zap.Error(err))
-		}
+			UserID:      approver.ID,
+			ChatID:      approver.TelegramUserID,
+			MessageID:   sent.MessageId,
+		})
+	}
+	if err := s.blacklistApprovalMessageRepo.CreateBatch(approvalMessages); err != nil {
+		s.logger.Warn("Failed to store approval messages",
+			zap.String("blacklist_id", blacklistID.String()),
+			zap.Error(err))
 	}
 
 	return nil
@@ -134,9 +121,8 @@ func (s *Service) handleBan(ctx context.Context, b *gotgbot.Bot, update *ext.Con
 		return err
 	}
 
-	// For private chats, GuestChatID equals GuestUserID
-	// For group chats, we would need to query the guest table, but guests are always private chats
-	guestUserID := mapping.GuestChatID
+	// Ban the member who wrote the message, not a group taking part as a guest
+	guestUserID := mapping.GuestSenderID()
 
 	s.logger.Debug("Found guest user ID from message mapping",
 		zap.String("bot_id", s.botID.String()),
@@ -145,11 +131,12 @@ func (s *Service) handleBan(ctx context.Context, b *gotgbot.Bot, update *ext.Con
 		zap.Int64("guest_message_id", mapping.GuestMessageID))
 
 	// Check permission: Manager, Admin, or any user in recipient chat
-	isManagerOrAdmin, err := s.IsManagerOrAdmin(userID)
+	isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
 	if err != nil {
 		s.logger.Warn("Failed to check permission", zap.Error(err))
 	}
 	if !isManagerOrAdmin && recipient.RecipientType != models.RecipientTypeGroup {
+		s.accessDenied(ctx, update, "/ban")
 		_, err := b.SendMessage(update.EffectiveChat.Id,
 			"You are not authorized to use this command.", nil)
 		return err
@@ -164,8 +151,14 @@ func (s *Service) handleBan(ctx context.Context, b *gotgbot.Bot, update *ext.Con
 		return err
 	}
 
+	// Anything after the command is kept as the reason for the ban
+	var reason string
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		reason = strings.TrimSpace(parts[1])
+	}
+
 	// Create ban request
-	blacklist, err := s.blacklistService.CreateBanRequest(s.botID, guestUserID, requestUser.ID)
+	blacklist, err := s.blacklistService.CreateBanRequest(s.botID, guestUserID, requestUser.ID, reason)
 	if err != nil {
 		s.logger.Error("Failed to create ban request", zap.Error(err))
 		// Check if error is due to trigger condition
@@ -186,8 +179,11 @@ func (s *Service) handleBan(ctx context.Context, b *gotgbot.Bot, update *ext.Con
 		zap.String("blacklist_id", blacklist.ID.String()))
 	guest, err := s.guestRepo.GetByBotIDAndUserID(s.botID, guestUserID)
 	if err == nil {
-		_, _ = b.SendMessage(guest.GuestUserID,
-			"You have been banned from this bot.", nil)
+		notice := "You have been banned from this bot."
+		if reason != "" {
+			notice += "\nReason: " + reason
+		}
+		_, _ = b.SendMessage(guest.GuestUserID, notice, nil)
 	} else {
 		s.logger.Warn("Failed to get guest for ban notification",
 			zap.String("bot_id", s.botID.String()),
@@ -203,6 +199,12 @@ func (s *Service) handleBan(ctx context.Context, b *gotgbot.Bot, update *ext.Con
 			"Chat: `%d`",
 		guestUserID, userID, chatID,
 	)
+	if mapping.GuestChatID != guestUserID {
+		message += fmt.Sprintf("\nWritten in group: `%d`", mapping.GuestChatID)
+	}
+	if reason != "" {
+		message += "\nReason: " + utils.EscapeMarkdown(reason)
+	}
 
 	buttons := [][]gotgbot.InlineKeyboardButton{
 		{
@@ -226,6 +228,98 @@ func (s *Service) handleBan(ctx context.Context, b *gotgbot.Bot, update *ext.Con
 	return err
 }
 
+// handleBanInfo shows the ban and unban history, with reasons, of the guest
+// who wrote the replied-to message. It takes the same permission as /ban.
+func (s *Service) handleBanInfo(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	if update.EffectiveMessage.ReplyToMessage == nil {
+		_, err := b.SendMessage(update.EffectiveChat.Id,
+			"Please reply to a message from the guest whose ban history you want to see.", nil)
+		return err
+	}
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+
+	recipient, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, chatID)
+	if err != nil {
+		_, err := b.SendMessage(chatID, "This command can only be used in recipient chats.", nil)
+		return err
+	}
+	isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Failed to check permission", zap.Error(err))
+	}
+	if !isManagerOrAdmin && recipient.RecipientType != models.RecipientTypeGroup {
+		s.accessDenied(ctx, update, "/baninfo")
+		_, err := b.SendMessage(chatID, "You are not authorized to use this command.", nil)
+		return err
+	}
+
+	mapping, err := s.messageMappingRepo.GetByRecipientMessage(s.botID, chatID, update.EffectiveMessage.ReplyToMessage.MessageId)
+	if err != nil {
+		_, err := b.SendMessage(chatID,
+			"Failed to find the corresponding guest. Please make sure you are replying to a forwarded message.", nil)
+		return err
+	}
+	guestUserID := mapping.GuestSenderID()
+
+	guest, err := s.guestRepo.GetByBotIDAndUserID(s.botID, guestUserID)
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Guest %d has never been banned.", guestUserID), nil)
+		return err
+	}
+	history, err := s.blacklistService.GetHistory(s.botID, guest.ID)
+	if err != nil {
+		s.logger.Error("Failed to get blacklist history", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if len(history) == 0 {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Guest %d has never been banned.", guestUserID), nil)
+		return err
+	}
+
+	_, err = b.SendMessage(chatID, s.formatBanHistory(guestUserID, history), nil)
+	return err
+}
+
+// formatBanHistory lists a guest's requests, given most recent first, one
+// entry per request
+func (s *Service) formatBanHistory(guestUserID int64, history []*models.Blacklist) string {
+	seen := make(map[uuid.UUID]bool)
+	var requestUserIDs []uuid.UUID
+	for _, request := range history {
+		if !seen[request.RequestUserID] {
+			seen[request.RequestUserID] = true
+			requestUserIDs = append(requestUserIDs, request.RequestUserID)
+		}
+	}
+	requestUsers := make(map[uuid.UUID]*models.User, len(requestUserIDs))
+	users, err := s.userRepo.GetByIDs(requestUserIDs)
+	if err != nil {
+		s.logger.Warn("Failed to load ban requesters", zap.Error(err))
+	}
+	for _, user := range users {
+		requestUsers[user.ID] = user
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Ban history of guest %d:\n", guestUserID)
+	for _, request := range history {
+		fmt.Fprintf(&text, "\n%s %s on %s", request.RequestType, request.Status, s.formatTime(request.CreatedAt))
+		if requestUser, ok := requestUsers[request.RequestUserID]; ok {
+			fmt.Fprintf(&text, " by %d", requestUser.TelegramUserID)
+		}
+		text.WriteString("\n")
+		if request.ExpiresAt != nil {
+			fmt.Fprintf(&text, "Until: %s\n", s.formatTime(*request.ExpiresAt))
+		}
+		if request.Reason != "" {
+			fmt.Fprintf(&text, "Reason: %s\n", request.Reason)
+		}
+	}
+	return strings.TrimRight(text.String(), "\n")
+}
+
 func (s *Service) handleUnban(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
 	userID := update.EffectiveUser.Id
 	chatID := update.EffectiveChat.Id
@@ -286,9 +380,8 @@ func (s *Service) handleUnban(ctx context.Context, b *gotgbot.Bot, update *ext.C
 			return err
 		}
 
-		// For private chats, GuestChatID equals GuestUserID
-		// For group chats, we would need to query the guest table, but guests are always private chats
-		guestUserID = mapping.GuestChatID
+		// Unban the member who wrote the message, not a group taking part as a guest
+		guestUserID = mapping.GuestSenderID()
 
 		s.logger.Debug("Found guest user ID from message mapping for unban",
 			zap.String("bot_id", s.botID.String()),
@@ -297,11 +390,12 @@ func (s *Service) handleUnban(ctx context.Context, b *gotgbot.Bot, update *ext.C
 			zap.Int64("guest_message_id", mapping.GuestMessageID))
 
 		// Check permission: Manager, Admin, or any user in recipient chat
-		isManagerOrAdmin, err := s.IsManagerOrAdmin(userID)
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
 		if err != nil {
 			s.logger.Warn("Failed to check permission", zap.Error(err))
 		}
 		if !isManagerOrAdmin && recipient.RecipientType != models.RecipientTypeGroup {
+			s.accessDenied(ctx, update, "/unban")
 			_, err := b.SendMessage(update.EffectiveChat.Id,
 				"You are not authorized to use this command.", nil)
 			return err
@@ -399,8 +493,9 @@ func (s *Service) handleBlacklistCallback(ctx context.Context, b *gotgbot.Bot, u
 		return err
 	}
 
+	// Callback data comes from the client, so make sure the request is this bot's
 	blacklist, err := s.blacklistRepo.GetByID(blacklistID)
-	if err != nil {
+	if err != nil || blacklist.BotID != s.botID {
 		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 			Text: "Blacklist request not found",
 		})
@@ -409,8 +504,9 @@ func (s *Service) handleBlacklistCallback(ctx context.Context, b *gotgbot.Bot, u
 
 	// Check if user is manager or admin
 	userID := update.EffectiveUser.Id
-	isManagerOrAdmin, err := s.IsManagerOrAdmin(userID)
+	isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
 	if err != nil || !isManagerOrAdmin {
+		s.accessDenied(ctx, update, "blacklist callback")
 		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 			Text: "Only the manager or admin can approve/reject requests",
 		})
@@ -438,10 +534,9 @@ func (s *Service) handleBlacklistCallback(ctx context.Context, b *gotgbot.Bot, u
 		executorName = "@" + *user.Username
 	}
 
-	// Answer callback query first
-	_, err = b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{})
-	if err != nil {
-		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	// Another manager or admin may have decided the request since this message was sent
+	if blacklist.Status != models.BlacklistStatusPending {
+		return s.answerAlreadyDecided(b, update, blacklist)
 	}
 
 	// Get all approval messages for this blacklist request
@@ -453,13 +548,19 @@ func (s *Service) handleBlacklistCallback(ctx context.Context, b *gotgbot.Bot, u
 
 	switch action {
 	case "approve":
-		if err := s.blacklistService.ApproveRequest(blacklistID); err != nil {
+		if err := s.blacklistService.ApproveRequest(blacklistID, user.ID); err != nil {
+			if errors.Is(err, blacklistservice.ErrAlreadyDecided) {
+				return s.answerAlreadyDecidedByID(b, update, blacklistID)
+			}
 			s.logger.Error("Failed to approve request", zap.Error(err))
 			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 				Text: "Failed to approve request",
 			})
 			return err
 		}
+		if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+			s.logger.Warn("Failed to answer callback query", zap.Error(err))
+		}
 
 		// Notify guest (only for unban, ban notification is sent when request is created)
 		guest, err := s.guestRepo.GetByID(blacklist.GuestID)
@@ -476,6 +577,7 @@ func (s *Service) handleBlacklistCallback(ctx context.Context, b *gotgbot.Bot, u
 			details, _ := json.Marshal(map[string]interface{}{
 				"blacklist_id": blacklistID.String(),
 				"request_type": blacklist.RequestType,
+				"reason":       blacklist.Reason,
 			})
 			auditLog := &models.AuditLog{
 				UserID:       &user.ID,
@@ -496,13 +598,19 @@ func (s *Service) handleBlacklistCallback(ctx context.Context, b *gotgbot.Bot, u
 		return nil
 
 	case "reject":
-		if err := s.blacklistService.RejectRequest(blacklistID); err != nil {
+		if err := s.blacklistService.RejectRequest(blacklistID, user.ID); err != nil {
+			if errors.Is(err, blacklistservice.ErrAlreadyDecided) {
+				return s.answerAlreadyDecidedByID(b, update, blacklistID)
+			}
 			s.logger.Error("Failed to reject request", zap.Error(err))
 			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 				Text: "Failed to reject request",
 			})
 			return err
 		}
+		if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+			s.logger.Warn("Failed to answer callback query", zap.Error(err))
+		}
 
 		// Notify guest when ban is rejected
 		guest, err := s.guestRepo.GetByID(blacklist.GuestID)
@@ -536,6 +644,53 @@ func (s *Service) handleBlacklistCallback(ctx context.Context, b *gotgbot.Bot, u
 	}
 }
 
+// answerAlreadyDecidedByID reloads a request that someone else decided first and
+// tells the presser who it was
+func (s *Service) answerAlreadyDecidedByID(b *gotgbot.Bot, update *ext.Context, blacklistID uuid.UUID) error {
+	blacklist, err := s.blacklistRepo.GetByID(blacklistID)
+	if err != nil {
+		s.logger.Warn("Failed to reload decided request", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "This request was already decided.",
+		})
+		return err
+	}
+	return s.answerAlreadyDecided(b, update, blacklist)
+}
+
+// answerAlreadyDecided answers an Approve/Reject press on a request that is no
+// longer pending with its outcome, e.g. "Already approved by @alice"
+func (s *Service) answerAlreadyDecided(b *gotgbot.Bot, update *ext.Context, blacklist *models.Blacklist) error {
+	s.logger.Debug("Approval button pressed for decided request",
+		zap.String("bot_id", s.botID.String()),
+		zap.String("blacklist_id", blacklist.ID.String()),
+		zap.String("status", string(blacklist.Status)),
+		zap.Int64("user_id", update.EffectiveUser.Id))
+
+	text := fmt.Sprintf("Already %s", blacklist.Status)
+	switch {
+	case blacklist.DecidedBy == nil && blacklist.Status == models.BlacklistStatusApproved:
+		text += " automatically after 24 hours"
+	case blacklist.DecidedBy != nil:
+		decider, err := s.userRepo.GetByID(*blacklist.DecidedBy)
+		if err == nil {
+			if decider.TelegramUserID == update.EffectiveUser.Id {
+				text += " by you"
+			} else if decider.Username != nil && *decider.Username != "" {
+				text += " by @" + *decider.Username
+			} else {
+				text += fmt.Sprintf(" by %d", decider.TelegramUserID)
+			}
+		}
+	}
+
+	_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text:      text + ".",
+		ShowAlert: true,
+	})
+	return err
+}
+
 // editApprovalMessages edits all approval messages to show the result
 func (s *Service) editApprovalMessages(
 	ctx context.Context,
@@ -574,6 +729,9 @@ func (s *Service) editApprovalMessages(
 			"Guest User ID: `%d`\n"+
 			"Requested by: `%d`\n",
 		requestTypeText, guestUserID, requestUserID)
+	if blacklist.Reason != "" {
+		baseMessage += "Reason: " + utils.EscapeMarkdown(blacklist.Reason) + "\n"
+	}
 
 	// Edit each message
 	for _, msg := range approvalMessages {
@@ -0,0 +1,32 @@
+package forwarder_bot
+
+import (
+	"errors"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// fromForwarderBot reports whether the message was written by a ForwarderBot
+// of this instance. Such messages are never forwarded: they are copies of
+// guest messages or replies, and forwarding them again can make two bots that
+// share a group forward each other's messages in a loop.
+func (s *Service) fromForwarderBot(message *gotgbot.Message) bool {
+	if message.From == nil || !message.From.IsBot {
+		return false
+	}
+	bot, err := s.botRepo.GetByTelegramBotID(message.From.Id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
+	if err != nil {
+		s.logger.Warn("Failed to look up message author", zap.Error(err))
+		return false
+	}
+	s.logger.Debug("Ignoring message from a ForwarderBot",
+		zap.String("bot_id", s.botID.String()),
+		zap.String("author_bot_id", bot.ID.String()),
+		zap.Int64("chat_id", message.Chat.Id))
+	return true
+}
@@ -0,0 +1,304 @@
+package forwarder_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service"
+	"go-telegram-forwarder-bot/internal/service/message"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	broadcastUsage = "Usage:\n" +
+		"/broadcast <text> - Send an announcement to every guest of this bot\n" +
+		"Reply to a message with /broadcast to send that message instead, e.g. a photo with a caption.\n\n" +
+		"You will be asked to confirm before anything is sent. Blacklisted guests are skipped."
+
+	// broadcastConfirmTimeout is how long a broadcast waits for confirmation
+	broadcastConfirmTimeout = 10 * time.Minute
+	// broadcastProgressInterval limits how often the progress message is edited
+	broadcastProgressInterval = 3 * time.Second
+)
+
+// pendingBroadcast is an announcement waiting for its author to confirm it
+type pendingBroadcast struct {
+	UserID     int64  // Telegram user who started the broadcast
+	Text       string // Sent as is unless MessageID is set
+	FromChatID int64  // Chat of the message to copy
+	MessageID  int64  // Message to copy to every guest, 0 to send Text
+	CreatedAt  time.Time
+}
+
+// send delivers the announcement to one guest
+func (p *pendingBroadcast) send(b *gotgbot.Bot, chatID int64) error {
+	if p.MessageID != 0 {
+		_, err := b.CopyMessage(chatID, p.FromChatID, p.MessageID, nil)
+		return err
+	}
+	_, err := b.SendMessage(chatID, p.Text, nil)
+	return err
+}
+
+// broadcastGuests returns the bot's guests that are not blacklisted
+func (s *Service) broadcastGuests() ([]*models.Guest, error) {
+	guests, err := s.guestRepo.GetByBotID(s.botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guests: %w", err)
+	}
+	blacklisted, err := s.blacklistService.GetBlacklisted(s.botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blacklist: %w", err)
+	}
+	skip := make(map[uuid.UUID]bool, len(blacklisted))
+	for _, request := range blacklisted {
+		skip[request.GuestID] = true
+	}
+
+	kept := make([]*models.Guest, 0, len(guests))
+	for _, guest := range guests {
+		if !skip[guest.ID] {
+			kept = append(kept, guest)
+		}
+	}
+	return kept, nil
+}
+
+// handleBroadcast prepares an announcement to every guest and asks to confirm it
+func (s *Service) handleBroadcast(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	msg := update.EffectiveMessage
+
+	if s.featureFlags != nil {
+		enabled, err := s.featureFlags.IsEnabledForBot(s.botID, service.FeatureBroadcast)
+		if err != nil {
+			s.logger.Error("Failed to check broadcast feature", zap.Error(err))
+			_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+			return err
+		}
+		if !enabled {
+			_, err := b.SendMessage(chatID, "Broadcasts are not included in the plan of this bot's manager.", nil)
+			return err
+		}
+	}
+
+	pending := &pendingBroadcast{
+		UserID:    update.EffectiveUser.Id,
+		CreatedAt: time.Now(),
+	}
+	if parts := strings.SplitN(msg.Text, " ", 2); len(parts) == 2 {
+		pending.Text = strings.TrimSpace(parts[1])
+	}
+	preview := pending.Text
+	if pending.Text == "" && msg.ReplyToMessage != nil {
+		pending.FromChatID = chatID
+		pending.MessageID = msg.ReplyToMessage.MessageId
+		preview = msg.ReplyToMessage.Text
+		if preview == "" {
+			preview = msg.ReplyToMessage.Caption
+		}
+		if preview == "" {
+			preview = "(the message you replied to)"
+		}
+	}
+	if pending.Text == "" && pending.MessageID == 0 {
+		_, err := b.SendMessage(chatID, broadcastUsage, nil)
+		return err
+	}
+
+	if s.broadcasting.Load() {
+		_, err := b.SendMessage(chatID, "A broadcast is already being sent. Wait for its report before starting another.", nil)
+		return err
+	}
+
+	guests, err := s.broadcastGuests()
+	if err != nil {
+		s.logger.Error("Failed to get broadcast guests", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if len(guests) == 0 {
+		_, err := b.SendMessage(chatID, "This bot has no guests to broadcast to.", nil)
+		return err
+	}
+
+	// Forget broadcasts that were never confirmed
+	s.pendingBroadcasts.Range(func(key, value interface{}) bool {
+		if time.Since(value.(*pendingBroadcast).CreatedAt) > broadcastConfirmTimeout {
+			s.pendingBroadcasts.Delete(key)
+		}
+		return true
+	})
+	id := uuid.New().String()
+	s.pendingBroadcasts.Store(id, pending)
+
+	text := fmt.Sprintf("📣 Send this announcement to %d guests?\n\n%s", len(guests), truncateRunes(preview, 1000))
+	markup := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+			{Text: "📣 Send", CallbackData: "broadcast:send:" + id},
+			{Text: "Cancel", CallbackData: "broadcast:cancel:" + id},
+		}},
+	}
+	_, err = b.SendMessage(chatID, text, &gotgbot.SendMessageOpts{ReplyMarkup: markup})
+	return err
+}
+
+// handleBroadcastCallback sends or cancels a broadcast awaiting confirmation
+func (s *Service) handleBroadcastCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	userID := update.EffectiveUser.Id
+
+	if len(parts) < 2 {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+
+	isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID)
+	if !isManagerOrAdmin {
+		s.accessDenied(ctx, update, "broadcast callback")
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "You are not authorized to use this button.",
+		})
+		return err
+	}
+
+	value, ok := s.pendingBroadcasts.Load(parts[1])
+	pending, _ := value.(*pendingBroadcast)
+	if !ok || time.Since(pending.CreatedAt) > broadcastConfirmTimeout {
+		s.pendingBroadcasts.Delete(parts[1])
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "This broadcast expired. Send /broadcast again.",
+		})
+		return err
+	}
+	if pending.UserID != userID {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Only the person who started this broadcast can confirm it.",
+		})
+		return err
+	}
+
+	chatID := update.EffectiveChat.Id
+	messageID := update.EffectiveMessage.MessageId
+	switch parts[0] {
+	case "cancel":
+		s.pendingBroadcasts.Delete(parts[1])
+		s.editBroadcastMessage(b, chatID, messageID, "Broadcast cancelled.")
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{Text: "Cancelled"})
+		return err
+	case "send":
+		if !s.broadcasting.CompareAndSwap(false, true) {
+			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "A broadcast is already being sent. Wait for its report before starting another.",
+			})
+			return err
+		}
+		s.pendingBroadcasts.Delete(parts[1])
+		s.editBroadcastMessage(b, chatID, messageID, "📣 Broadcast starting…")
+		// Sending to every guest takes a while, so do it outside the update
+		go s.runBroadcast(ctx, b, pending, chatID, messageID)
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{Text: "Broadcast started"})
+		return err
+	default:
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+}
+
+// runBroadcast sends a confirmed broadcast, keeping the confirmation message
+// up to date with its progress and finally the delivery report
+func (s *Service) runBroadcast(ctx context.Context, b *gotgbot.Bot, pending *pendingBroadcast, chatID int64, messageID int64) {
+	defer s.broadcasting.Store(false)
+
+	guests, err := s.broadcastGuests()
+	if err != nil {
+		s.logger.Error("Failed to get broadcast guests", zap.Error(err))
+		s.editBroadcastMessage(b, chatID, messageID, "Failed to start the broadcast. Please try again later.")
+		return
+	}
+
+	s.logger.Info("Broadcast started",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", pending.UserID),
+		zap.Int("guest_count", len(guests)))
+
+	lastProgress := time.Now()
+	report := s.messageForwarder.Broadcast(ctx, s.botID, guests,
+		func(guestChatID int64) error {
+			return pending.send(b, guestChatID)
+		},
+		func(progress message.BroadcastReport) {
+			if time.Since(lastProgress) < broadcastProgressInterval {
+				return
+			}
+			lastProgress = time.Now()
+			s.editBroadcastMessage(b, chatID, messageID,
+				fmt.Sprintf("📣 Broadcasting… %d of %d guests", progress.Done(), progress.Total))
+		})
+
+	s.editBroadcastMessage(b, chatID, messageID, formatBroadcastReport(report))
+	s.auditBroadcast(pending.UserID, report)
+}
+
+// editBroadcastMessage replaces the text and buttons of the broadcast message
+func (s *Service) editBroadcastMessage(b *gotgbot.Bot, chatID int64, messageID int64, text string) {
+	if _, _, err := b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+		ChatId:    chatID,
+		MessageId: messageID,
+	}); err != nil {
+		s.logger.Debug("Failed to update broadcast message",
+			zap.String("bot_id", s.botID.String()),
+			zap.Error(err))
+	}
+}
+
+// auditBroadcast records who sent a broadcast and how it went
+func (s *Service) auditBroadcast(telegramUserID int64, report message.BroadcastReport) {
+	user, err := s.userRepo.GetByTelegramUserID(telegramUserID)
+	if err != nil || user == nil {
+		return
+	}
+	details, _ := json.Marshal(map[string]interface{}{
+		"total":       report.Total,
+		"sent":        report.Sent,
+		"blocked":     report.Blocked,
+		"unreachable": report.Unreachable,
+		"failed":      report.Failed,
+		"cancelled":   report.Cancelled,
+	})
+	s.auditLogRepo.Create(&models.AuditLog{
+		UserID:       &user.ID,
+		ActionType:   models.AuditLogActionBroadcast,
+		ResourceType: "bot",
+		ResourceID:   s.botID,
+		Details:      string(details),
+	})
+}
+
+// formatBroadcastReport describes the outcome of a broadcast
+func formatBroadcastReport(report message.BroadcastReport) string {
+	var text strings.Builder
+	if report.Cancelled {
+		fmt.Fprintf(&text, "📣 Broadcast stopped after %d of %d guests because the bot was stopped.\n\n", report.Done(), report.Total)
+	} else {
+		fmt.Fprintf(&text, "📣 Broadcast finished: %d guests\n\n", report.Total)
+	}
+	fmt.Fprintf(&text, "Sent: %d\n", report.Sent)
+	fmt.Fprintf(&text, "Blocked the bot: %d\n", report.Blocked)
+	fmt.Fprintf(&text, "Unreachable: %d\n", report.Unreachable)
+	if report.Failed > 0 {
+		fmt.Fprintf(&text, "Failed: %d (Telegram errors, see the logs)\n", report.Failed)
+	}
+	return text.String()
+}
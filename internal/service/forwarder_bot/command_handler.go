@@ -1,6 +1,7 @@
 package forwarder_bot
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/statistics"
 	"go-telegram-forwarder-bot/internal/utils"
 	"go.uber.org/zap"
 )
@@ -37,10 +39,9 @@ func (s *Service) handleAddRecipient(ctx context.Context, b *gotgbot.Bot, update
 		return err
 	}
 
-	// Determine recipient type (simplified: assume user if chat_id > 0, group if < 0)
-	recipientType := models.RecipientTypeUser
-	if chatID < 0 {
-		recipientType = models.RecipientTypeGroup
+	recipientType, ok, err := s.checkNewRecipient(ctx, b, update.EffectiveChat.Id, chatID)
+	if !ok {
+		return err
 	}
 
 	recipient := &models.Recipient{
@@ -55,6 +56,7 @@ func (s *Service) handleAddRecipient(ctx context.Context, b *gotgbot.Bot, update
 			"Failed to add recipient. Please try again later.", nil)
 		return err
 	}
+	s.refreshRecipientInfo(ctx, b, recipient)
 
 	// Log audit
 	userID := update.EffectiveUser.Id
@@ -75,7 +77,7 @@ func (s *Service) handleAddRecipient(ctx context.Context, b *gotgbot.Bot, update
 	}
 
 	_, err = b.SendMessage(update.EffectiveChat.Id,
-		fmt.Sprintf("Recipient %d has been added successfully!", chatID), nil)
+		fmt.Sprintf("Recipient %s has been added successfully!", recipient.Label()), nil)
 	return err
 }
 
@@ -101,7 +103,7 @@ func (s *Service) handleDelRecipient(ctx context.Context, b *gotgbot.Bot, update
 		return err
 	}
 
-	if err := s.recipientRepo.Delete(recipient.ID); err != nil {
+	if err := s.recipientRepo.Delete(s.botID, recipient.ID); err != nil {
 		s.logger.Error("Failed to delete recipient", zap.Error(err))
 		_, err := b.SendMessage(update.EffectiveChat.Id,
 			"Failed to delete recipient. Please try again later.", nil)
@@ -148,7 +150,11 @@ func (s *Service) handleListRecipient(ctx context.Context, b *gotgbot.Bot, updat
 	var message strings.Builder
 	message.WriteString("*Recipients:*\n\n")
 	for i, recipient := range recipients {
-		message.WriteString(fmt.Sprintf("%d. %s: %d\n", i+1, recipient.RecipientType, recipient.ChatID))
+		message.WriteString(fmt.Sprintf("%d. %s: %s", i+1, recipient.RecipientType, utils.EscapeMarkdown(recipient.Label())))
+		if recipient.IsFallback {
+			message.WriteString(" (fallback)")
+		}
+		message.WriteString("\n")
 	}
 
 	_, err = b.SendMessage(update.EffectiveChat.Id, message.String(), &gotgbot.SendMessageOpts{
@@ -257,7 +263,7 @@ func (s *Service) handleDelAdmin(ctx context.Context, b *gotgbot.Bot, update *ex
 		return err
 	}
 
-	if err := s.botAdminRepo.Delete(botAdmin.ID); err != nil {
+	if err := s.botAdminRepo.Delete(s.botID, botAdmin.ID); err != nil {
 		s.logger.Error("Failed to delete admin", zap.Error(err))
 		_, err := b.SendMessage(update.EffectiveChat.Id,
 			"Failed to remove admin. Please try again later.", nil)
@@ -286,38 +292,16 @@ func (s *Service) handleDelAdmin(ctx context.Context, b *gotgbot.Bot, update *ex
 	return err
 }
 
-func (s *Service) handleListAdmins(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
-	admins, err := s.botAdminRepo.GetByBotID(s.botID)
-	if err != nil {
-		s.logger.Error("Failed to get admins", zap.Error(err))
-		_, err := b.SendMessage(update.EffectiveChat.Id,
-			"An error occurred. Please try again later.", nil)
-		return err
-	}
-
-	if len(admins) == 0 {
-		_, err := b.SendMessage(update.EffectiveChat.Id,
-			"No admins configured.", nil)
-		return err
-	}
-
-	var message strings.Builder
-	message.WriteString("*Admins:*\n\n")
-	for i, admin := range admins {
-		username := "Unknown"
-		if admin.AdminUser.Username != nil {
-			username = *admin.AdminUser.Username
+func (s *Service) handleStats(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	if parts := strings.Fields(update.EffectiveMessage.Text); len(parts) > 1 {
+		days, ok := parseStatsRange(parts[1])
+		if !ok || len(parts) > 2 {
+			_, err := b.SendMessage(update.EffectiveChat.Id, statsRangeUsage, nil)
+			return err
 		}
-		message.WriteString(fmt.Sprintf("%d. @%s (%d)\n", i+1, utils.EscapeMarkdown(username), admin.AdminUser.TelegramUserID))
+		return s.handleRangeStats(ctx, b, update, days)
 	}
 
-	_, err = b.SendMessage(update.EffectiveChat.Id, message.String(), &gotgbot.SendMessageOpts{
-		ParseMode: "Markdown",
-	})
-	return err
-}
-
-func (s *Service) handleStats(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
 	stats, err := s.statsService.GetBotStatistics(s.botID)
 	if err != nil {
 		s.logger.Error("Failed to get statistics", zap.Error(err))
@@ -328,25 +312,74 @@ func (s *Service) handleStats(ctx context.Context, b *gotgbot.Bot, update *ext.C
 
 	message := fmt.Sprintf(
 		"*Bot Statistics*\n\n"+
-			"Inbound Messages: %d\n"+
-			"Outbound Messages: %d\n"+
-			"Total Guests: %d",
-		stats.InboundCount,
-		stats.OutboundCount,
-		stats.GuestCount,
+			"Inbound Messages: %s\n"+
+			"Outbound Messages: %s\n"+
+			"Total Guests: %s",
+		utils.FormatNumber(stats.InboundCount),
+		utils.FormatNumber(stats.OutboundCount),
+		utils.FormatNumber(stats.GuestCount),
 	)
+	message += s.welcomeStatsText()
 
 	_, err = b.SendMessage(update.EffectiveChat.Id, message, &gotgbot.SendMessageOpts{
 		ParseMode: "Markdown",
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	series, err := s.statsService.GetBotDailySeries(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get daily statistics", zap.Error(err))
+		return nil
+	}
+	s.sendStatsCharts(b, update.EffectiveChat.Id, series, []statsChart{
+		{name: "messages", caption: "Messages per day (last 14 days)", render: statistics.RenderMessagesChart},
+		{name: "guests", caption: "Guest growth (last 14 days)", render: statistics.RenderGuestsChart},
+	})
+	return nil
+}
+
+// statsChart is one chart of a /stats album
+type statsChart struct {
+	name    string
+	caption string
+	render  func(*statistics.DailySeries) ([]byte, error)
+}
+
+// sendStatsCharts sends the charts of the daily series as one photo album.
+// Charts are best effort: failures are logged and the text statistics stand alone.
+func (s *Service) sendStatsCharts(b *gotgbot.Bot, chatID int64, series *statistics.DailySeries, charts []statsChart) {
+	if !series.HasData {
+		return
+	}
+
+	media := make([]gotgbot.InputMedia, 0, len(charts))
+	for _, chart := range charts {
+		png, err := chart.render(series)
+		if err != nil {
+			s.logger.Warn("Failed to render statistics chart",
+				zap.String("chart", chart.name),
+				zap.Error(err))
+			return
+		}
+		media = append(media, gotgbot.InputMediaPhoto{
+			Media:   gotgbot.InputFileByReader(chart.name+".png", bytes.NewReader(png)),
+			Caption: chart.caption,
+		})
+	}
+	if _, err := b.SendMediaGroup(chatID, media, nil); err != nil {
+		s.logger.Warn("Failed to send statistics charts",
+			zap.Int64("chat_id", chatID),
+			zap.Error(err))
+	}
 }
 
 func (s *Service) handleHelp(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
 	userID := update.EffectiveUser.Id
 	chatID := update.EffectiveChat.Id
-	isManager, _ := s.IsManager(userID)
-	isManagerOrAdmin, _ := s.IsManagerOrAdmin(userID)
+	isManager, _ := s.IsManager(ctx, userID)
+	isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID)
 
 	// Check if user is a recipient
 	isRecipient := false
@@ -355,8 +388,11 @@ func (s *Service) handleHelp(ctx context.Context, b *gotgbot.Bot, update *ext.Co
 		isRecipient = true
 	}
 
-	// Determine if user is a pure guest (not manager, not admin, not recipient)
-	isPureGuest := !isManagerOrAdmin && !isRecipient
+	// Pure guests (not manager, not admin, not recipient) get their own help
+	// without any staff commands
+	if !isManagerOrAdmin && !isRecipient {
+		return s.handleGuestHelp(ctx, b, update)
+	}
 
 	helpText := "*ForwarderBot Commands*\n\n"
 	helpText += "*/help* - Show this help message\n"
@@ -366,36 +402,130 @@ func (s *Service) handleHelp(ctx context.Context, b *gotgbot.Bot, update *ext.Co
 		helpText += "*/addrecipient <chat_id>* - Add a recipient\n"
 		helpText += "*/delrecipient <chat_id>* - Remove a recipient\n"
 		helpText += "*/listrecipient* - List all recipients\n"
+		helpText += "*/setalias <chat_id> [alias]* - Set a recipient's display name (no alias to clear)\n"
+		helpText += "*/setfallback <chat_id|off>* - Set the fallback recipient that always receives forwards\n"
 	}
 
 	if isManagerOrAdmin {
 		helpText += "\n*Admin Management:*\n"
 		if isManager {
 			helpText += "*/addadmin <user_id>* - Add an admin (Manager only)\n"
+			helpText += "*/inviteadmin* - Create a one-time admin invite link (Manager only)\n"
 			helpText += "*/deladmin <user_id>* - Remove an admin (Manager only)\n"
 		}
 		helpText += "*/listadmins* - List all admins\n"
 	}
 
+	if isManagerOrAdmin {
+		helpText += "\n*Guest Rate Limit:*\n"
+		helpText += "*/setratelimit <per_second> [burst]* - Set guest rate limit (use `default` to reset)\n"
+		helpText += "*/setratelimit api <per_second>* - Give this bot its own Telegram API limit (use `default` to share the global one)\n"
+	}
+
+	if isManagerOrAdmin {
+		helpText += "\n*Terms of Service:*\n"
+		helpText += "*/setterms <text>* - Set the terms guests must accept (no text to disable)\n"
+		helpText += "*/terms* - Show the current terms\n"
+	}
+
+	if isManagerOrAdmin {
+		helpText += "\n*Welcome Message:*\n"
+		helpText += "*/setwelcome <a|b> [text]* - Set welcome message A or its A/B variant B (no text to clear)\n"
+		helpText += "*/setwelcome reset* - Clear the welcome A/B results\n"
+		helpText += "*/sethelp [text]* - Set the help text guests see on /help (no text to use the default)\n"
+	}
+
+	if isManagerOrAdmin {
+		helpText += "\n*Office Hours:*\n"
+		helpText += "*/sethours <HH:MM-HH:MM> [days] [timezone]* - Set when the team replies, e.g. `/sethours 09:00-18:00 Mon-Fri` (`off` to clear)\n"
+		helpText += "*/setaway [text]* - Set the message guests get outside office hours; `{hours}` and `{opens_at}` are filled in (no text to use the default)\n"
+	}
+
+	if isManagerOrAdmin {
+		helpText += "\n*Guest Privacy:*\n"
+		helpText += "*/setprivacyheader [text]* - Set the header above guest messages when guest names are hidden; `{guest}` is the guest's number (no text to use the default)\n"
+	}
+
+	if isManagerOrAdmin {
+		helpText += "\n*Keyword Alerts:*\n"
+		helpText += "*/setalerts <keyword, ...>* - Ping the manager when guests use these words (`off` to disable)\n"
+		helpText += "*/alerts* - List tagged conversations (`/alerts clear <user_id>` to untag)\n"
+	}
+
+	if isManagerOrAdmin {
+		helpText += "\n*Auto-replies:*\n"
+		helpText += "*/autoreply* - List keyword auto-replies and their hit counts\n"
+		helpText += "*/autoreply add <keyword, ...> | <reply>* - Answer guests instantly and still forward the message\n"
+		helpText += "*/autoreply faq <keyword, ...> | <reply>* - Answer guests instantly without forwarding\n"
+		helpText += "*/autoreply remove <n>* - Remove a rule (`/autoreply reset` clears the statistics)\n"
+	}
+
+	if isManagerOrAdmin && s.adFilter != nil {
+		helpText += "\n*Message Filters:*\n"
+		helpText += "*/addfilter [quarantine] <keyword or /regex/>* - Block matching guest messages, or hold them for a recipient to deliver or discard\n"
+		helpText += "*/delfilter <n>* - Remove a filter\n"
+		helpText += "*/listfilters* - List filters and their hit counts\n"
+	}
+
+	if isManagerOrAdmin && s.routingRuleRepo != nil {
+		helpText += "\n*Routing:*\n"
+		helpText += "*/addroute <chat_id> <keyword|media|lang> <value>* - Only send the recipient matching guest messages, e.g. `/addroute -100123 lang de`\n"
+		helpText += "*/delroute <n>* - Remove a route\n"
+		helpText += "*/listroutes* - List routes by recipient\n"
+	}
+
+	if isManagerOrAdmin {
+		helpText += "\n*Broadcast:*\n"
+		helpText += "*/broadcast <text>* - Send an announcement to every guest after confirming (reply to a message to send that message)\n"
+	}
+
+	if isManagerOrAdmin {
+		helpText += "\n*Timezone:*\n"
+		helpText += "*/settimezone <timezone>* - Set the timezone for times shown by this bot (`default` to follow the manager's)\n"
+	}
+
+	if isManager {
+		helpText += "\n*Groups:*\n"
+		helpText += "*/groupguests <on|off>* - Treat members of unregistered groups as guests (Manager only)\n"
+		helpText += "*/autoleave <on|off>* - Leave groups added by anyone but the manager or admins (Manager only)\n"
+	}
+
+	if isManager {
+		helpText += "\n*Paywall:*\n"
+		helpText += "*/paywall [off|required|priority] [stars] [messages]* - Show or set the Telegram Stars paywall (Manager only)\n"
+		helpText += "*/refund <charge_id>* - Refund a guest's payment (Manager only)\n"
+	}
+
 	if isManagerOrAdmin {
 		helpText += "\n*Statistics:*\n"
 		helpText += "*/stats* - View bot statistics\n"
+		helpText += "*/stats <7d|4w|...>* - Messages, new guests and the most active guests over the last days or weeks\n"
 	}
 
 	helpText += "\n*Blacklist Management:*\n"
-	// Only show /ban command if user is not a pure guest
-	if !isPureGuest {
-		helpText += "*/ban* - Ban a guest (reply to their message)\n"
-	}
-	helpText += "*/unban* - Unban a guest (reply to their message, or use directly to request unban for yourself)\n"
-
-	if !isPureGuest {
-		helpText += "\n*Note:*\n"
-		helpText += "- Ban command can be used by Manager, Admins, or any user in a group recipient\n"
-		helpText += "- Unban command: Reply to a message to unban someone else (requires permission), or use directly to request unban for yourself if you are blacklisted"
-	} else {
-		helpText += "\n*Note:*\n"
-		helpText += "- Unban command: Use directly to request unban for yourself if you are blacklisted"
+	helpText += "*/ban [reason]* - Ban a guest (reply to their message)\n"
+	helpText += "*/baninfo* - Show a guest's ban history with reasons (reply to their message)\n"
+	helpText += "*/unban* - Unban a guest (reply to their message)\n"
+
+	helpText += "\n*Message Deletion:*\n"
+	helpText += "*/del* - Delete a message for the guest (reply to a forwarded message or your reply)\n"
+	helpText += "*/del all* - Also delete it in every recipient chat\n"
+
+	helpText += "\n*Note:*\n"
+	helpText += "- Ban, baninfo and del commands can be used by Manager, Admins, or any user in a group recipient\n"
+	helpText += "- Unban command: Reply to a message to unban someone else (requires permission)"
+
+	if s.suggester != nil {
+		helpText += "\n\n*Draft Replies:*\n"
+		helpText += "*/suggest* - Draft a reply to a guest with AI (reply to their message), then send, edit or discard it"
+	}
+
+	helpText += "\n\n*Abuse Reporting:*\n"
+	helpText += "*/report <reason>* - Report abuse of this bot to the instance administrators\n"
+	helpText += "*/credits* - Show your message credits and buy more, if the bot has a paywall\n"
+	helpText += "*/language [code]* - Choose the language the bots use with you\n"
+	if !isManagerOrAdmin {
+		helpText += "*/terms* - Show the terms of this bot\n"
 	}
 
 	helpText += "\n\n*How it works:*\n"
@@ -0,0 +1,145 @@
+package forwarder_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// handleDelete deletes the guest's side of the message the command replies to:
+// the guest's own message or the reply staff sent them. "/del all" also
+// deletes every recipient copy of it.
+func (s *Service) handleDelete(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+
+	replyTo := update.EffectiveMessage.ReplyToMessage
+	if replyTo == nil {
+		_, err := b.SendMessage(chatID,
+			"Please reply to a forwarded message or a reply you sent with /del to delete it for the guest.\n"+
+				"Use /del all to also delete it in every recipient chat.", nil)
+		return err
+	}
+
+	recipient, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, chatID)
+	if err != nil {
+		_, err := b.SendMessage(chatID, "This command can only be used in recipient chats.", nil)
+		return err
+	}
+
+	// Same permission as /ban: Manager, Admin, or any user in a group recipient
+	isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Failed to check permission", zap.Error(err))
+	}
+	if !isManagerOrAdmin && recipient.RecipientType != models.RecipientTypeGroup {
+		s.accessDenied(ctx, update, "/del")
+		_, err := b.SendMessage(chatID, "You are not authorized to use this command.", nil)
+		return err
+	}
+
+	mapping, err := s.messageMappingRepo.GetByRecipientMessage(s.botID, chatID, replyTo.MessageId)
+	if err != nil {
+		_, err := b.SendMessage(chatID,
+			"Failed to find the corresponding guest message. Please make sure you are replying to a forwarded message or a reply you sent.", nil)
+		return err
+	}
+
+	args := strings.Fields(update.EffectiveMessage.Text)
+	deleteCopies := len(args) > 1 && strings.EqualFold(args[1], "all")
+
+	guestDeleted := true
+	if _, err := b.DeleteMessage(mapping.GuestChatID, mapping.GuestMessageID, nil); err != nil {
+		guestDeleted = false
+		s.logger.Warn("Failed to delete guest message",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("guest_chat_id", mapping.GuestChatID),
+			zap.Int64("guest_message_id", mapping.GuestMessageID),
+			zap.Error(err))
+	}
+
+	copiesDeleted, copiesFailed := 0, 0
+	if deleteCopies {
+		copies, err := s.messageMappingRepo.GetAllByGuestMessage(s.botID, mapping.GuestChatID, mapping.GuestMessageID)
+		if err != nil {
+			s.logger.Warn("Failed to get recipient copies", zap.Error(err))
+		}
+		for _, c := range copies {
+			if _, err := b.DeleteMessage(c.RecipientChatID, c.RecipientMessageID, nil); err != nil {
+				copiesFailed++
+				s.logger.Warn("Failed to delete recipient copy",
+					zap.String("bot_id", s.botID.String()),
+					zap.Int64("recipient_chat_id", c.RecipientChatID),
+					zap.Int64("recipient_message_id", c.RecipientMessageID),
+					zap.Error(err))
+				continue
+			}
+			copiesDeleted++
+		}
+	}
+
+	s.logger.Info("Message deleted",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int64("guest_chat_id", mapping.GuestChatID),
+		zap.Int64("guest_message_id", mapping.GuestMessageID),
+		zap.Bool("guest_deleted", guestDeleted),
+		zap.Int("copies_deleted", copiesDeleted))
+
+	// Log audit
+	username := update.EffectiveUser.Username
+	var usernamePtr *string
+	if username != "" {
+		usernamePtr = &username
+	}
+	user, err := s.userRepo.GetOrCreateByTelegramUserID(userID, usernamePtr)
+	if err == nil {
+		details, _ := json.Marshal(map[string]interface{}{
+			"bot_id":            s.botID.String(),
+			"recipient_chat_id": chatID,
+			"guest_chat_id":     mapping.GuestChatID,
+			"guest_message_id":  mapping.GuestMessageID,
+			"direction":         mapping.Direction,
+			"guest_deleted":     guestDeleted,
+			"copies_deleted":    copiesDeleted,
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &user.ID,
+			ActionType:   models.AuditLogActionDeleteMessage,
+			ResourceType: "message",
+			ResourceID:   mapping.ID,
+			Details:      string(details),
+		}
+		s.auditLogRepo.Create(auditLog)
+	}
+
+	var result string
+	if guestDeleted {
+		result = "Message deleted for the guest."
+	} else {
+		// Telegram only lets bots delete messages less than 48 hours old
+		result = "Could not delete the message for the guest. It may be older than 48 hours or already deleted."
+	}
+	if deleteCopies {
+		result += fmt.Sprintf("\nRecipient copies deleted: %d", copiesDeleted)
+		if copiesFailed > 0 {
+			result += fmt.Sprintf(" (%d failed)", copiesFailed)
+		}
+	}
+	if deleteCopies && copiesDeleted > 0 {
+		// The replied-to copy in this chat is gone, so do not reply to it
+		_, err = b.SendMessage(chatID, result, nil)
+		return err
+	}
+	_, err = b.SendMessage(chatID, result, &gotgbot.SendMessageOpts{
+		ReplyParameters: &gotgbot.ReplyParameters{MessageId: replyTo.MessageId, AllowSendingWithoutReply: true},
+	})
+	return err
+}
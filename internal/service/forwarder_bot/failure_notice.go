@@ -0,0 +1,97 @@
+package forwarder_bot
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/i18n"
+	"go-telegram-forwarder-bot/internal/service/message"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+// quotaExceededReplyText is sent to recipients whose reply was not forwarded because of the monthly quota
+const quotaExceededReplyText = "This bot has used its monthly message quota. Forwarding is paused until next month or until the quota is raised."
+
+// isQuotaExceeded reports whether a forwarding error means the bot's monthly quota is used up
+func isQuotaExceeded(err error) bool {
+	return errors.Is(err, message.ErrQuotaExceeded)
+}
+
+// asGuestThrottled reports whether a forwarding error is a guest rate limit rejection
+func asGuestThrottled(err error) (*message.GuestThrottledError, bool) {
+	var throttled *message.GuestThrottledError
+	if errors.As(err, &throttled) {
+		return throttled, true
+	}
+	return nil, false
+}
+
+// notifyGuestThrottled tells a rate limited guest to slow down. The limiter only
+// sets Notify on the first rejected message of a throttling episode.
+func (s *Service) notifyGuestThrottled(b *gotgbot.Bot, chatID int64, userID int64, lang string, throttled *message.GuestThrottledError) {
+	if !throttled.Notify {
+		return
+	}
+
+	seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	if _, err := b.SendMessage(chatID, i18n.T(lang, "guest.throttled", seconds), nil); err != nil {
+		s.logger.Warn("Failed to send slow mode notice to guest",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+}
+
+// notifyGuestOnTotalFailure tells the guest their message was not delivered when
+// every recipient failed and none will get it from the retry queue. Notices are
+// limited to one per guest per cooldown.
+func (s *Service) notifyGuestOnTotalFailure(b *gotgbot.Bot, chatID int64, userID int64, lang string, messageID int64, result *message.ForwardResult) {
+	if result == nil || !result.AllFailed() || result.Queued > 0 {
+		return
+	}
+	s.sendGuestFailureNotice(b, chatID, userID, lang, messageID)
+}
+
+// sendGuestFailureNotice tells the guest their message was not delivered, at most
+// once per guest per cooldown
+func (s *Service) sendGuestFailureNotice(b *gotgbot.Bot, chatID int64, userID int64, lang string, messageID int64) {
+	if !s.config.FailureNotice.Enabled {
+		return
+	}
+
+	cooldown := time.Duration(s.config.FailureNotice.CooldownSeconds) * time.Second
+	now := time.Now()
+	if last, ok := s.failureNoticeCache.Load(userID); ok && now.Sub(last.(time.Time)) < cooldown {
+		s.logger.Debug("Skipping delivery failure notice due to cooldown",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Time("last_notified", last.(time.Time)))
+		return
+	}
+	s.failureNoticeCache.Store(userID, now)
+
+	_, err := b.SendMessage(chatID, i18n.T(lang, "guest.failure_notice"), &gotgbot.SendMessageOpts{
+		ReplyParameters: &gotgbot.ReplyParameters{
+			MessageId:                messageID,
+			AllowSendingWithoutReply: true,
+		},
+	})
+	if err != nil {
+		s.logger.Warn("Failed to send delivery failure notice to guest",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		return
+	}
+
+	s.logger.Info("Guest notified about delivery failure",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int64("message_id", messageID))
+}
@@ -0,0 +1,105 @@
+package forwarder_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// handleSetFallback sets or clears the bot's fallback recipient.
+// The chat is added as a recipient if it is not one already.
+func (s *Service) handleSetFallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	parts := strings.Fields(update.EffectiveMessage.Text)
+	if len(parts) < 2 {
+		_, err := b.SendMessage(chatID,
+			"Usage: /setfallback <chat_id|off>\n"+
+				"The fallback recipient (e.g. an archive channel) always receives forwards, even when other recipients are muted or failing.\n"+
+				"Example: /setfallback -1001234567890", nil)
+		return err
+	}
+
+	if strings.EqualFold(parts[1], "off") {
+		if err := s.recipientRepo.SetFallback(s.botID, nil); err != nil {
+			s.logger.Error("Failed to clear fallback recipient", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to clear fallback recipient. Please try again later.", nil)
+			return err
+		}
+		s.recordFallbackAudit(update.EffectiveUser.Id, uuid.Nil, 0)
+		s.logger.Info("Fallback recipient cleared",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", update.EffectiveUser.Id))
+		_, err := b.SendMessage(chatID, "Fallback recipient has been cleared.", nil)
+		return err
+	}
+
+	fallbackChatID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Invalid chat ID: %v", err), nil)
+		return err
+	}
+
+	recipient, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, fallbackChatID)
+	if err != nil {
+		recipientType, ok, err := s.checkNewRecipient(ctx, b, chatID, fallbackChatID)
+		if !ok {
+			return err
+		}
+		recipient = &models.Recipient{
+			BotID:         s.botID,
+			RecipientType: recipientType,
+			ChatID:        fallbackChatID,
+		}
+		if err := s.recipientRepo.Create(recipient); err != nil {
+			s.logger.Error("Failed to create fallback recipient", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to add recipient. Please try again later.", nil)
+			return err
+		}
+		s.refreshRecipientInfo(ctx, b, recipient)
+	}
+
+	if err := s.recipientRepo.SetFallback(s.botID, &recipient.ID); err != nil {
+		s.logger.Error("Failed to set fallback recipient", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to set fallback recipient. Please try again later.", nil)
+		return err
+	}
+
+	s.recordFallbackAudit(update.EffectiveUser.Id, recipient.ID, fallbackChatID)
+	s.logger.Info("Fallback recipient set",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Int64("fallback_chat_id", fallbackChatID))
+
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Recipient %s is now the fallback recipient and will receive every forwarded message.", recipient.Label()), nil)
+	return err
+}
+
+func (s *Service) recordFallbackAudit(userID int64, recipientID uuid.UUID, fallbackChatID int64) {
+	user, _ := s.userRepo.GetByTelegramUserID(userID)
+	if user == nil {
+		return
+	}
+	details, _ := json.Marshal(map[string]interface{}{
+		"bot_id":  s.botID.String(),
+		"chat_id": fallbackChatID,
+		"enabled": recipientID != uuid.Nil,
+	})
+	auditLog := &models.AuditLog{
+		UserID:       &user.ID,
+		ActionType:   models.AuditLogActionSetFallback,
+		ResourceType: "recipient",
+		ResourceID:   recipientID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+}
@@ -0,0 +1,137 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/i18n"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// scanGuestFile scans a document sent by a guest and reports whether it was
+// withheld. Files that could not be scanned are forwarded unless
+// file_scan.withhold_unscanned is set.
+func (s *Service) scanGuestFile(ctx context.Context, b *gotgbot.Bot, update *ext.Context, lang string) bool {
+	msg := update.EffectiveMessage
+	if s.fileScanner == nil || msg.Document == nil {
+		return false
+	}
+	if s.settings == nil || !s.settings.GetBool(s.botID, settings.KeyScanFiles) {
+		return false
+	}
+
+	verdict, err := s.fileScanner.ScanDocument(ctx, b, msg.Document)
+	var reason, guestText string
+	switch {
+	case err != nil:
+		s.logger.Warn("Failed to scan guest file",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", msg.Chat.Id),
+			zap.Int64("message_id", msg.MessageId),
+			zap.Error(err))
+		if !s.config.FileScan.WithholdUnscanned {
+			return false
+		}
+		reason = "could not be scanned"
+		guestText = i18n.T(lang, "guest.file.unchecked")
+	case !verdict.Infected:
+		return false
+	default:
+		reason = "flagged by the virus scanner"
+		if verdict.Threat != "" {
+			reason += " (" + verdict.Threat + ")"
+		}
+		guestText = i18n.T(lang, "guest.file.flagged")
+	}
+
+	s.logger.Info("Guest file withheld",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("chat_id", msg.Chat.Id),
+		zap.Int64("message_id", msg.MessageId),
+		zap.String("file_name", msg.Document.FileName),
+		zap.String("reason", reason))
+	s.notifyFileWithheld(ctx, b, update, reason)
+
+	if _, err := b.SendMessage(msg.Chat.Id, guestText, &gotgbot.SendMessageOpts{
+		ReplyParameters: &gotgbot.ReplyParameters{MessageId: msg.MessageId, AllowSendingWithoutReply: true},
+	}); err != nil {
+		s.logger.Warn("Failed to tell guest about withheld file", zap.Error(err))
+	}
+	return true
+}
+
+// notifyFileWithheld tells the recipients and the manager that a guest's file
+// was withheld. Recipients can reply to the notice to answer the guest.
+func (s *Service) notifyFileWithheld(ctx context.Context, b *gotgbot.Bot, update *ext.Context, reason string) {
+	msg := update.EffectiveMessage
+	guestChatID := msg.Chat.Id
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot for withheld file notice", zap.Error(err))
+		return
+	}
+	guest := s.guestLabel(bot, update.EffectiveUser)
+	fileName := msg.Document.FileName
+	if fileName == "" {
+		fileName = "Unnamed file"
+	}
+
+	recipients, err := s.recipientRepo.GetByBotID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get recipients for withheld file notice", zap.Error(err))
+	}
+	text := fmt.Sprintf("🛡 File withheld\n\nFrom: %s\nFile: %s\nReason: %s\n\n"+
+		"The file was not delivered. Reply to this message to answer the guest.", guest, fileName, reason)
+	for _, recipient := range recipients {
+		notice, err := b.SendMessage(recipient.ChatID, text, nil)
+		if err != nil {
+			s.logger.Warn("Failed to send withheld file notice",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("recipient_chat_id", recipient.ChatID),
+				zap.Error(err))
+			continue
+		}
+		mapping := &models.MessageMapping{
+			BotID:              s.botID,
+			GuestChatID:        guestChatID,
+			GuestMessageID:     msg.MessageId,
+			RecipientChatID:    recipient.ChatID,
+			RecipientMessageID: notice.MessageId,
+			Direction:          models.MessageDirectionInbound,
+		}
+		if err := s.messageMappingRepo.Create(mapping); err != nil {
+			s.logger.Warn("Failed to create message mapping for withheld file notice", zap.Error(err))
+		}
+	}
+
+	if s.managerNotifier != nil {
+		notification := fmt.Sprintf("🛡 *File withheld*\n\nBot: @%s\nFrom: %s\nFile: %s\nReason: %s",
+			utils.EscapeMarkdown(bot.Name), utils.EscapeMarkdown(guest),
+			utils.EscapeMarkdown(fileName), utils.EscapeMarkdown(reason))
+		if err := s.managerNotifier.NotifyManager(ctx, s.botID, notification); err != nil {
+			s.logger.Warn("Failed to notify manager about withheld file", zap.Error(err))
+		}
+	}
+}
+
+// guestLabel names a guest in notices the way their forwarded messages would:
+// by name, or by the privacy header when the bot hides guest names
+func (s *Service) guestLabel(bot *models.ForwarderBot, user *gotgbot.User) string {
+	if bot.PrivacyMode {
+		guest, err := s.guestRepo.GetOrCreateByBotIDAndUserID(s.botID, user.Id)
+		if err != nil {
+			s.logger.Warn("Failed to get guest", zap.Error(err))
+			return "A guest"
+		}
+		return bot.GuestHeader(guest)
+	}
+	return fmt.Sprintf("%s (%d)", strings.TrimSpace(user.FirstName+" "+user.LastName), user.Id)
+}
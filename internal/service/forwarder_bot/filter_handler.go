@@ -0,0 +1,377 @@
+package forwarder_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/adfilter"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const addFilterUsage = "Usage:\n" +
+	"/addfilter <keyword> - Block guest messages containing the keyword\n" +
+	"/addfilter /<regex>/ - Block guest messages matching a regular expression\n" +
+	"/addfilter quarantine <keyword or /regex/> - Hold matching messages until staff deliver or discard them\n\n" +
+	"Keywords ignore case; add (?i) to a regular expression to ignore case.\n" +
+	"Example: /addfilter quarantine /t\\.me/\\+\\w+/"
+
+// applyFilterRules checks a guest message from user against the bot's filter
+// rules and reports whether it was dropped or quarantined instead of forwarded
+func (s *Service) applyFilterRules(b *gotgbot.Bot, msg *gotgbot.Message, user *gotgbot.User, imageText string) bool {
+	if s.adFilter == nil {
+		return false
+	}
+	chatID := msg.Chat.Id
+	userID := user.Id
+
+	texts := []string{msg.Text, msg.Caption, imageText}
+	if msg.Quote != nil {
+		texts = append(texts, msg.Quote.Text)
+	}
+	rule, err := s.adFilter.Match(s.botID, texts...)
+	if err != nil {
+		s.logger.Warn("Failed to check filter rules",
+			zap.String("bot_id", s.botID.String()),
+			zap.Error(err))
+		return false
+	}
+	if rule == nil {
+		return false
+	}
+
+	s.logger.Debug("Guest message matched filter rule",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int64("message_id", msg.MessageId),
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("action", string(rule.Action)))
+
+	if rule.Action == models.FilterActionQuarantine {
+		quarantined, err := s.adFilter.Quarantine(s.botID, rule, msg)
+		if err == nil {
+			s.sendQuarantineNotices(b, msg, user, quarantined)
+			return true
+		}
+		// Without a stored copy the message could never be delivered, so drop it
+		s.logger.Warn("Failed to quarantine message, dropping it instead",
+			zap.String("bot_id", s.botID.String()),
+			zap.Error(err))
+	}
+
+	if _, err := b.SendMessage(chatID,
+		"Your message was not forwarded because it matches a filter set by this bot's owner.", nil); err != nil {
+		s.logger.Warn("Failed to send filter notification",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", chatID),
+			zap.Error(err))
+	}
+	return true
+}
+
+// sendQuarantineNotices asks the recipients to deliver or discard a quarantined message
+func (s *Service) sendQuarantineNotices(b *gotgbot.Bot, msg *gotgbot.Message, user *gotgbot.User, quarantined *models.QuarantinedMessage) {
+	guest := fmt.Sprintf("%d", user.Id)
+	if bot, err := s.botRepo.GetByID(s.botID); err == nil {
+		guest = s.guestLabel(bot, user)
+	}
+
+	preview := msg.Text
+	if preview == "" {
+		preview = msg.Caption
+	}
+	if preview == "" {
+		preview = "(no text)"
+	}
+	text := fmt.Sprintf("🚫 Message quarantined\n\nFrom: %s\nFilter: %s\n\n%s",
+		guest, quarantined.Pattern, truncateRunes(preview, 500))
+
+	markup := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+			{Text: "✅ Deliver", CallbackData: "filter:deliver:" + quarantined.ID.String()},
+			{Text: "🗑 Discard", CallbackData: "filter:discard:" + quarantined.ID.String()},
+		}},
+	}
+
+	recipients, err := s.recipientRepo.GetByBotID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get recipients for quarantine notice", zap.Error(err))
+		return
+	}
+	for _, recipient := range recipients {
+		if _, err := b.SendMessage(recipient.ChatID, text, &gotgbot.SendMessageOpts{ReplyMarkup: markup}); err != nil {
+			s.logger.Warn("Failed to send quarantine notice",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("recipient_chat_id", recipient.ChatID),
+				zap.Error(err))
+		}
+	}
+}
+
+// handleFilterCallback delivers or discards a quarantined message
+func (s *Service) handleFilterCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+
+	if len(parts) < 2 || s.adFilter == nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+
+	// Same permission as /ban: Manager, Admin, or any user in a group recipient
+	recipient, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, chatID)
+	isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID)
+	if err != nil || (!isManagerOrAdmin && recipient.RecipientType != models.RecipientTypeGroup) {
+		s.accessDenied(ctx, update, "quarantine callback")
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "You are not authorized to use this button.",
+		})
+		return err
+	}
+
+	var result string
+	switch parts[0] {
+	case "deliver":
+		msg, err := s.adFilter.Release(s.botID, id)
+		if err != nil {
+			return s.answerQuarantineError(b, update, err)
+		}
+		forwardResult, err := s.messageForwarder.ForwardToRecipients(ctx, b, s.botID, msg.Chat.Id, msg)
+		if err != nil || (forwardResult.SuccessCount == 0 && !forwardResult.DryRun) {
+			s.logger.Warn("Failed to deliver quarantined message",
+				zap.String("bot_id", s.botID.String()),
+				zap.String("quarantine_id", id.String()),
+				zap.Error(err))
+			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "Failed to deliver the message. The guest may have deleted it.",
+			})
+			return err
+		}
+		result = "✅ Delivered"
+		if forwardResult.DryRun {
+			result = "📝 Logged (dry run, not delivered)"
+		}
+	case "discard":
+		if err := s.adFilter.Discard(s.botID, id); err != nil {
+			return s.answerQuarantineError(b, update, err)
+		}
+		result = "🗑 Discarded"
+	default:
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+
+	s.logger.Info("Quarantined message decided",
+		zap.String("bot_id", s.botID.String()),
+		zap.String("quarantine_id", id.String()),
+		zap.Int64("user_id", userID),
+		zap.String("decision", parts[0]))
+
+	if update.EffectiveMessage != nil {
+		executor := update.EffectiveUser.FirstName
+		if update.EffectiveUser.Username != "" {
+			executor = "@" + update.EffectiveUser.Username
+		}
+		if _, _, err := b.EditMessageText(update.EffectiveMessage.Text+"\n\n"+result+" by "+executor,
+			&gotgbot.EditMessageTextOpts{ChatId: chatID, MessageId: update.EffectiveMessage.MessageId}); err != nil {
+			s.logger.Debug("Failed to update quarantine notice", zap.Error(err))
+		}
+	}
+	_, err = b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{Text: result})
+	return err
+}
+
+func (s *Service) answerQuarantineError(b *gotgbot.Bot, update *ext.Context, err error) error {
+	text := "An error occurred. Please try again later."
+	if errors.Is(err, adfilter.ErrAlreadyDecided) {
+		text = "This message was already delivered or discarded."
+	} else {
+		s.logger.Error("Failed to decide quarantined message", zap.Error(err))
+	}
+	_, err = b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{Text: text})
+	return err
+}
+
+// handleAddFilter adds a keyword or regular expression filter rule
+func (s *Service) handleAddFilter(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	if s.adFilter == nil {
+		_, err := b.SendMessage(chatID, "The ad filter is not enabled on this instance.", nil)
+		return err
+	}
+
+	args := ""
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		args = strings.TrimSpace(parts[1])
+	}
+	action := models.FilterActionDrop
+	if first, rest, ok := strings.Cut(args, " "); ok {
+		switch strings.ToLower(first) {
+		case "quarantine":
+			action = models.FilterActionQuarantine
+			args = strings.TrimSpace(rest)
+		case "drop":
+			args = strings.TrimSpace(rest)
+		}
+	}
+	if args == "" {
+		_, err := b.SendMessage(chatID, addFilterUsage, nil)
+		return err
+	}
+
+	pattern, isRegex := args, false
+	if len(args) > 2 && strings.HasPrefix(args, "/") && strings.HasSuffix(args, "/") {
+		pattern, isRegex = args[1:len(args)-1], true
+	}
+	if err := adfilter.ValidatePattern(pattern, isRegex); err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Failed to add the filter: %v\n\n%s", err, addFilterUsage), nil)
+		return err
+	}
+
+	rules, err := s.adFilter.Rules(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get filter rules", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if len(rules) >= adfilter.MaxRules {
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("A bot can have at most %d filters. Remove one first.", adfilter.MaxRules), nil)
+		return err
+	}
+
+	rule := &models.FilterRule{
+		BotID:   s.botID,
+		Pattern: pattern,
+		IsRegex: isRegex,
+		Action:  action,
+	}
+	if err := s.adFilter.AddRule(rule); err != nil {
+		s.logger.Error("Failed to create filter rule", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to add the filter. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Filter rule added",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.String("pattern", pattern),
+		zap.Bool("regex", isRegex),
+		zap.String("action", string(action)))
+
+	text := fmt.Sprintf("Filter #%d added: %s\n", len(rules)+1, formatFilterRule(rule))
+	if action == models.FilterActionQuarantine {
+		text += "Matching guest messages will be held until a recipient delivers or discards them."
+	} else {
+		text += "Matching guest messages will not be forwarded."
+	}
+	_, err = b.SendMessage(chatID, text, nil)
+	return err
+}
+
+// handleDelFilter removes a filter rule by its number in /listfilters
+func (s *Service) handleDelFilter(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	if s.adFilter == nil {
+		_, err := b.SendMessage(chatID, "The ad filter is not enabled on this instance.", nil)
+		return err
+	}
+
+	var n int
+	var err error
+	if parts := strings.Fields(update.EffectiveMessage.Text); len(parts) == 2 {
+		n, err = strconv.Atoi(parts[1])
+	}
+	if err != nil || n < 1 {
+		_, err := b.SendMessage(chatID, "Usage: /delfilter <number>\nSee /listfilters for the numbers.", nil)
+		return err
+	}
+
+	rules, err := s.adFilter.Rules(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get filter rules", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if n > len(rules) {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("There is no filter #%d. See /listfilters for the list.", n), nil)
+		return err
+	}
+
+	rule := rules[n-1]
+	if err := s.adFilter.DeleteRule(s.botID, rule.ID); err != nil {
+		s.logger.Error("Failed to delete filter rule", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to remove the filter. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Filter rule removed",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.String("rule_id", rule.ID.String()))
+
+	_, err = b.SendMessage(chatID, fmt.Sprintf("Filter #%d (%s) removed.", n, formatFilterRule(rule)), nil)
+	return err
+}
+
+// handleListFilters lists the bot's filter rules with their hit counts
+func (s *Service) handleListFilters(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	if s.adFilter == nil {
+		_, err := b.SendMessage(chatID, "The ad filter is not enabled on this instance.", nil)
+		return err
+	}
+
+	rules, err := s.adFilter.Rules(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get filter rules", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if len(rules) == 0 {
+		_, err := b.SendMessage(chatID, "No filters are set.\n\n"+addFilterUsage, nil)
+		return err
+	}
+
+	var text strings.Builder
+	text.WriteString("Filters (first match wins):\n")
+	for i, rule := range rules {
+		fmt.Fprintf(&text, "\n%d. %s\n   %s hits", i+1, formatFilterRule(rule), utils.FormatNumber(rule.HitCount))
+		if rule.LastHitAt != nil {
+			fmt.Fprintf(&text, ", last %s", utils.FormatShortDate(rule.LastHitAt.In(s.location())))
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString("\nUse /delfilter <number> to remove a filter.")
+
+	_, err = b.SendMessage(chatID, text.String(), nil)
+	return err
+}
+
+// formatFilterRule describes a rule as it was entered, with its action
+func formatFilterRule(rule *models.FilterRule) string {
+	pattern := rule.Pattern
+	if rule.IsRegex {
+		pattern = "/" + pattern + "/"
+	}
+	return fmt.Sprintf("%s (%s)", pattern, rule.Action)
+}
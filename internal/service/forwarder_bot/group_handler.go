@@ -0,0 +1,254 @@
+package forwarder_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// guestGroup returns the bot when chat is a group taking part as a guest
+// source, i.e. any group that is not a recipient, and nil for private chats and
+// recipient groups. Callers check AllowGroupGuests on the returned bot.
+func (s *Service) guestGroup(chat *gotgbot.Chat) (*models.ForwarderBot, error) {
+	if chat.Type == "private" {
+		return nil, nil
+	}
+	if _, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, chat.Id); err == nil {
+		return nil, nil
+	}
+	return s.botRepo.GetByID(s.botID)
+}
+
+// isReplyToBot reports whether msg replies to a message the bot sent
+func isReplyToBot(b *gotgbot.Bot, msg *gotgbot.Message) bool {
+	return msg.ReplyToMessage != nil && msg.ReplyToMessage.From != nil && msg.ReplyToMessage.From.Id == b.Id
+}
+
+// notifyUnauthorizedGroup tells the manager, once per chat, that the bot is
+// ignoring a group it is not registered in
+func (s *Service) notifyUnauthorizedGroup(b *gotgbot.Bot, bot *models.ForwarderBot, chat *gotgbot.Chat) {
+	if _, notified := s.unauthorizedGroupCache.LoadOrStore(chat.Id, struct{}{}); notified {
+		return
+	}
+
+	title := chat.Title
+	if title == "" {
+		title = "Untitled group"
+	}
+	text := fmt.Sprintf("⚠️ *Unregistered group*\n\n@%s is in %s (`%d`), which is not a recipient. "+
+		"Messages there are ignored.\n\n"+
+		"Register the group as a recipient to receive forwards there, or use /groupguests on in this bot "+
+		"to accept group members as guests.",
+		utils.EscapeMarkdown(bot.Name), utils.EscapeMarkdown(title), chat.Id)
+	if _, err := b.SendMessage(bot.Manager.TelegramUserID, text, &gotgbot.SendMessageOpts{
+		ParseMode: "Markdown",
+	}); err != nil {
+		s.logger.Warn("Failed to notify manager about unregistered group",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", chat.Id),
+			zap.Error(err))
+	}
+}
+
+// handleGroupGuests shows or sets whether members of unregistered groups are treated as guests
+func (s *Service) handleGroupGuests(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	arg := ""
+	if parts := strings.Fields(update.EffectiveMessage.Text); len(parts) >= 2 {
+		arg = strings.ToLower(parts[1])
+	}
+
+	switch arg {
+	case "on", "off":
+		bot.AllowGroupGuests = arg == "on"
+	default:
+		current := "off (only private chats are guests)"
+		if bot.AllowGroupGuests {
+			current = "on (members of unregistered groups are guests)"
+		}
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("Group guests: %s\n\nUsage: /groupguests <on|off>", current), nil)
+		return err
+	}
+
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update group guests setting", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update the setting. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Group guests setting updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Bool("allow_group_guests", bot.AllowGroupGuests))
+
+	if bot.AllowGroupGuests {
+		_, err = b.SendMessage(chatID,
+			"Group guests enabled. Messages in groups that are not recipients will be forwarded like guest messages.", nil)
+		return err
+	}
+	s.unauthorizedGroupCache.Clear()
+	_, err = b.SendMessage(chatID,
+		"Group guests disabled. Only private chats are treated as guests; unregistered groups are ignored.", nil)
+	return err
+}
+
+// isBotAdded reports whether msg announces the bot joining a group, either by
+// being added as a member or by being part of a newly created group
+func isBotAdded(b *gotgbot.Bot, msg *gotgbot.Message) bool {
+	if msg.GroupChatCreated || msg.SupergroupChatCreated {
+		return true
+	}
+	for _, member := range msg.NewChatMembers {
+		if member.Id == b.Id {
+			return true
+		}
+	}
+	return false
+}
+
+// handleBotAdded leaves a group the bot was added to by someone other than the
+// manager or an admin, unless the group is a recipient or auto-leave is off
+func (s *Service) handleBotAdded(ctx context.Context, b *gotgbot.Bot, msg *gotgbot.Message) error {
+	chat := &msg.Chat
+	if _, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, chat.Id); err == nil {
+		return nil
+	}
+
+	var addedBy int64
+	if msg.From != nil {
+		addedBy = msg.From.Id
+		if isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, addedBy); err == nil && isManagerOrAdmin {
+			s.logger.Info("Bot added to a group by its manager or an admin",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("chat_id", chat.Id),
+				zap.Int64("user_id", addedBy))
+			return nil
+		}
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		return err
+	}
+	if !bot.AutoLeaveGroups {
+		return nil
+	}
+
+	s.logger.Warn("Bot added to a group by a stranger, leaving",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("chat_id", chat.Id),
+		zap.Int64("user_id", addedBy))
+
+	if _, err := b.SendMessage(chat.Id,
+		"This bot only works in private chats and in groups set up by its manager, so it is leaving this group.", nil); err != nil {
+		s.logger.Debug("Failed to post auto-leave notice",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", chat.Id),
+			zap.Error(err))
+	}
+	if _, err := b.LeaveChat(chat.Id, nil); err != nil {
+		s.logger.Error("Failed to leave unauthorized group",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", chat.Id),
+			zap.Error(err))
+		return err
+	}
+
+	adder := fmt.Sprintf("%d", addedBy)
+	if msg.From != nil && msg.From.Username != "" {
+		adder = fmt.Sprintf("@%s (%d)", msg.From.Username, addedBy)
+	}
+	title := chat.Title
+	if title == "" {
+		title = "Untitled group"
+	}
+	text := fmt.Sprintf("🚪 *Left group*\n\n%s added @%s to %s (`%d`). The bot left automatically.\n\n"+
+		"Use /autoleave off in this bot to let anyone add it to groups.",
+		utils.EscapeMarkdown(adder), utils.EscapeMarkdown(bot.Name), utils.EscapeMarkdown(title), chat.Id)
+	if _, err := b.SendMessage(bot.Manager.TelegramUserID, text, &gotgbot.SendMessageOpts{
+		ParseMode: "Markdown",
+	}); err != nil {
+		s.logger.Warn("Failed to notify manager about auto-leave",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", chat.Id),
+			zap.Error(err))
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"chat_id":    chat.Id,
+		"chat_title": chat.Title,
+		"added_by":   addedBy,
+	})
+	auditLog := &models.AuditLog{
+		ActionType:   models.AuditLogActionAutoLeave,
+		ResourceType: "bot",
+		ResourceID:   s.botID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+	return nil
+}
+
+// handleAutoLeave shows or sets whether the bot leaves groups it is added to by strangers
+func (s *Service) handleAutoLeave(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	arg := ""
+	if parts := strings.Fields(update.EffectiveMessage.Text); len(parts) >= 2 {
+		arg = strings.ToLower(parts[1])
+	}
+
+	switch arg {
+	case "on", "off":
+		bot.AutoLeaveGroups = arg == "on"
+	default:
+		current := "off"
+		if bot.AutoLeaveGroups {
+			current = "on"
+		}
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("Auto-leave: %s\n\nWhen on, the bot leaves groups added by anyone other than the manager or admins.\n\n"+
+				"Usage: /autoleave <on|off>", current), nil)
+		return err
+	}
+
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update auto-leave setting", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update the setting. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Auto-leave setting updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Bool("auto_leave_groups", bot.AutoLeaveGroups))
+
+	if bot.AutoLeaveGroups {
+		_, err = b.SendMessage(chatID, "Auto-leave enabled. The bot will leave groups added by strangers.", nil)
+		return err
+	}
+	_, err = b.SendMessage(chatID, "Auto-leave disabled. The bot stays in any group it is added to.", nil)
+	return err
+}
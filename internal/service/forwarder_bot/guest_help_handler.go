@@ -0,0 +1,98 @@
+package forwarder_bot
+
+import (
+	"context"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/i18n"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// guestHelpText returns the bot's custom guest help text, or the default one
+// in lang. Custom help text is shown as written, whatever the guest's language.
+func (s *Service) guestHelpText(lang string) string {
+	defaultGuestHelpText := i18n.T(lang, "guest.help.default")
+	if s.settings == nil {
+		return defaultGuestHelpText
+	}
+	text, err := s.settings.Get(s.botID, settings.KeyGuestHelp)
+	if err != nil {
+		s.logger.Warn("Failed to get guest help text",
+			zap.String("bot_id", s.botID.String()),
+			zap.Error(err))
+		return defaultGuestHelpText
+	}
+	if text == "" {
+		return defaultGuestHelpText
+	}
+	return text
+}
+
+// handleGuestHelp shows guests how the bot works and the few commands meant for
+// them. Nothing about recipients, bans or admins is shown.
+func (s *Service) handleGuestHelp(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	lang := s.language(ctx, update.EffectiveUser)
+	var text strings.Builder
+	text.WriteString(s.guestHelpText(lang))
+	text.WriteString("\n\n" + i18n.T(lang, "guest.help.commands") + "\n")
+	text.WriteString(i18n.T(lang, "guest.help.help") + "\n")
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot", zap.Error(err))
+	}
+	if bot != nil && bot.TermsText != "" {
+		text.WriteString(i18n.T(lang, "guest.help.terms") + "\n")
+	}
+	if bot != nil && bot.PaywallMode != models.PaywallModeOff {
+		text.WriteString(i18n.T(lang, "guest.help.credits") + "\n")
+	}
+	if s.laterEnabled() {
+		text.WriteString(i18n.T(lang, "guest.help.later") + "\n")
+	}
+	text.WriteString(i18n.T(lang, "guest.help.unban") + "\n")
+	text.WriteString(i18n.T(lang, "guest.help.report") + "\n")
+	text.WriteString(i18n.T(lang, "guest.help.language"))
+
+	// Custom help text is sent as written, without Markdown
+	_, err = b.SendMessage(update.EffectiveChat.Id, text.String(), nil)
+	return err
+}
+
+// handleSetGuestHelp sets the help text guests see on /help
+func (s *Service) handleSetGuestHelp(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	if s.settings == nil {
+		_, err := b.SendMessage(chatID, "Settings are not available.", nil)
+		return err
+	}
+
+	text := ""
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		text = strings.TrimSpace(parts[1])
+	}
+
+	if err := s.settings.Set(s.botID, settings.KeyGuestHelp, text); err != nil {
+		s.logger.Error("Failed to update guest help text", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update the help text. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Guest help text updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Bool("custom", text != ""))
+
+	if text == "" {
+		_, err := b.SendMessage(chatID, "Guest help reset. Guests now see the default explanation on /help.", nil)
+		return err
+	}
+	_, err := b.SendMessage(chatID, "Guest help updated. Guests see it on /help, followed by the commands available to them.", nil)
+	return err
+}
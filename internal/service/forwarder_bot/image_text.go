@@ -0,0 +1,74 @@
+package forwarder_bot
+
+import (
+	"context"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+// readImageText returns the text in a guest's photo or image file, or "" if
+// the message has no image, the bot does not read images or recognition fails.
+// A failure only costs the extra checks, so the message is forwarded anyway.
+func (s *Service) readImageText(ctx context.Context, b *gotgbot.Bot, msg *gotgbot.Message) string {
+	if s.ocr == nil || (len(msg.Photo) == 0 && msg.Document == nil) {
+		return ""
+	}
+	if s.settings == nil || !s.settings.GetBool(s.botID, settings.KeyReadImages) {
+		return ""
+	}
+
+	text, err := s.ocr.RecognizeImage(ctx, b, msg)
+	if err != nil {
+		s.logger.Warn("Failed to read text in image",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", msg.Chat.Id),
+			zap.Int64("message_id", msg.MessageId),
+			zap.Error(err))
+		return ""
+	}
+	return text
+}
+
+// attachImageText replies to each recipient's copy of a guest image with the
+// text read from it, so it can be found by searching the chat
+func (s *Service) attachImageText(b *gotgbot.Bot, guestChatID int64, msg *gotgbot.Message, text string) {
+	if text == "" {
+		return
+	}
+
+	mappings, err := s.messageMappingRepo.GetAllByGuestMessage(s.botID, guestChatID, msg.MessageId)
+	if err != nil {
+		s.logger.Warn("Failed to get copies of image",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Error(err))
+		return
+	}
+
+	note := "🔍 Text in image:\n" + text
+	if runes := []rune(note); len(runes) > maxMessageLength {
+		note = string(runes[:maxMessageLength-1]) + "…"
+	}
+
+	for _, mapping := range mappings {
+		if mapping.Direction != models.MessageDirectionInbound {
+			continue
+		}
+		_, err := b.SendMessage(mapping.RecipientChatID, note, &gotgbot.SendMessageOpts{
+			ReplyParameters: &gotgbot.ReplyParameters{
+				MessageId:                mapping.RecipientMessageID,
+				AllowSendingWithoutReply: true,
+			},
+		})
+		if err != nil {
+			s.logger.Warn("Failed to send image text to recipient",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("recipient_chat_id", mapping.RecipientChatID),
+				zap.Error(err))
+		}
+	}
+}
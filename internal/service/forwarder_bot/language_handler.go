@@ -0,0 +1,123 @@
+package forwarder_bot
+
+import (
+	"context"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/i18n"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// language returns the language to talk to a Telegram user in: the one they
+// chose with /language on any bot, otherwise the one of their Telegram app
+func (s *Service) language(ctx context.Context, telegramUser *gotgbot.User) string {
+	if telegramUser == nil {
+		return i18n.Default
+	}
+	preference := ""
+	if user, err := s.cachedUser(ctx, telegramUser.Id); err == nil {
+		preference = user.Language
+	}
+	return i18n.Resolve(preference, telegramUser.LanguageCode)
+}
+
+// handleLanguage shows the language menu or sets the caller's language. Guests
+// and staff alike can use it; the choice applies to every bot of this instance.
+func (s *Service) handleLanguage(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	lang := s.language(ctx, update.EffectiveUser)
+
+	arg := ""
+	if parts := strings.Fields(update.EffectiveMessage.Text); len(parts) >= 2 {
+		arg = parts[1]
+	}
+	if arg == "" {
+		current := i18n.Name(lang)
+		if user, err := s.cachedUser(ctx, update.EffectiveUser.Id); err != nil || user.Language == "" {
+			current = i18n.T(lang, "language.auto", current)
+		}
+		var row []gotgbot.InlineKeyboardButton
+		for _, language := range i18n.Languages() {
+			row = append(row, gotgbot.InlineKeyboardButton{
+				Text:         language.Name,
+				CallbackData: "language:" + language.Code,
+			})
+		}
+		keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			row,
+			{{Text: i18n.T(lang, "language.button_auto"), CallbackData: "language:auto"}},
+		}}
+		_, err := b.SendMessage(chatID,
+			i18n.T(lang, "language.current", current)+"\n\n"+
+				i18n.T(lang, "language.choose")+"\n"+
+				i18n.T(lang, "language.usage"),
+			&gotgbot.SendMessageOpts{ReplyMarkup: keyboard})
+		return err
+	}
+
+	_, err := b.SendMessage(chatID, s.setLanguage(ctx, update.EffectiveUser, arg), nil)
+	return err
+}
+
+// handleLanguageCallback applies a choice from the language menu
+func (s *Service) handleLanguageCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	text := s.setLanguage(ctx, update.EffectiveUser, parts[0])
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, nil); err != nil {
+		return err
+	}
+	if update.CallbackQuery.Message == nil {
+		return nil
+	}
+	_, _, err := b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+		ChatId:    update.EffectiveChat.Id,
+		MessageId: update.CallbackQuery.Message.GetMessageId(),
+	})
+	return err
+}
+
+// setLanguage stores a language code, or "auto" to follow the Telegram app,
+// and returns the confirmation to show in the new language
+func (s *Service) setLanguage(ctx context.Context, telegramUser *gotgbot.User, code string) string {
+	language := ""
+	if !strings.EqualFold(code, "auto") {
+		matched, ok := i18n.Match(code)
+		if !ok {
+			lang := s.language(ctx, telegramUser)
+			codes := make([]string, 0, len(i18n.Languages()))
+			for _, language := range i18n.Languages() {
+				codes = append(codes, language.Code)
+			}
+			return i18n.T(lang, "language.unknown", code, strings.Join(codes, i18n.T(lang, "list.separator")))
+		}
+		language = matched
+	}
+
+	var username *string
+	if telegramUser.Username != "" {
+		username = &telegramUser.Username
+	}
+	user, err := s.userRepo.GetOrCreateByTelegramUserID(telegramUser.Id, username)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.Error(err))
+		return i18n.T(s.language(ctx, telegramUser), "common.error")
+	}
+	user.Language = language
+	if err := s.userRepo.Update(user); err != nil {
+		s.logger.Error("Failed to update user language", zap.Error(err))
+		return i18n.T(s.language(ctx, telegramUser), "language.failed")
+	}
+
+	s.logger.Info("User language updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", telegramUser.Id),
+		zap.String("language", language))
+
+	lang := i18n.Resolve(language, telegramUser.LanguageCode)
+	if language == "" {
+		return i18n.T(lang, "language.set_auto", i18n.Name(lang))
+	}
+	return i18n.T(lang, "language.set", i18n.Name(lang))
+}
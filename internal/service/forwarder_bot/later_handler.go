@@ -0,0 +1,365 @@
+package forwarder_bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/i18n"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+const (
+	laterBatchSize   = 50              // Held messages delivered per bot and run
+	laterMaxAttempts = 3               // Failed runs before a held message is given up on
+	laterRetryDelay  = 5 * time.Minute // Wait after a failed run, multiplied by the attempts
+	laterMinDelay    = time.Minute
+)
+
+// SetScheduledMessages lets guests hold messages for later delivery with /later
+func (s *Service) SetScheduledMessages(repo repository.ScheduledMessageRepository) {
+	s.scheduledMessages = repo
+}
+
+// laterEnabled reports whether guests can use /later
+func (s *Service) laterEnabled() bool {
+	return s.scheduledMessages != nil && s.config.Later.MaxDelayHours > 0
+}
+
+// parseLaterDelay parses the delay of /later: a Go duration such as 30m or
+// 1h30m, or a number of days such as 2d
+func parseLaterDelay(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid delay %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	delay, err := time.ParseDuration(value)
+	if err != nil || delay < laterMinDelay {
+		return 0, fmt.Errorf("invalid delay %q", value)
+	}
+	return delay, nil
+}
+
+// laterArgs splits "/later <delay> <text>" into the delay and the text, which
+// keeps its line breaks
+func laterArgs(command string) (string, string) {
+	fields := strings.Fields(command)
+	if len(fields) < 2 {
+		return "", ""
+	}
+	rest := strings.TrimSpace(command[len(fields[0]):])
+	return fields[1], strings.TrimSpace(rest[len(fields[1]):])
+}
+
+// handleLater holds a guest's message and delivers it to the recipients after
+// the requested delay. Without arguments it lists the guest's waiting
+// messages; /later cancel drops them.
+func (s *Service) handleLater(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+	lang := s.language(ctx, update.EffectiveUser)
+
+	if update.EffectiveChat.Type != "private" {
+		_, err := b.SendMessage(chatID, i18n.T(lang, "later.private_only"), nil)
+		return err
+	}
+	if !s.laterEnabled() {
+		_, err := b.SendMessage(chatID, i18n.T(lang, "later.disabled"), nil)
+		return err
+	}
+
+	// Blacklisted guests are ignored, as their messages are
+	if isBlacklisted, err := s.blacklistService.IsBlacklisted(s.botID, userID); err != nil {
+		s.logger.Warn("Failed to check blacklist", zap.Error(err))
+	} else if isBlacklisted {
+		return nil
+	}
+	if s.muteIfFlooding(ctx, b, chatID, userID, lang) {
+		return nil
+	}
+	if isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID); !isManagerOrAdmin {
+		s.welcomeNewGuest(b, update)
+	}
+
+	delayArg, text := laterArgs(update.EffectiveMessage.Text)
+	switch {
+	case delayArg == "":
+		return s.listLater(ctx, b, chatID, lang)
+	case delayArg == "cancel":
+		deleted, err := s.scheduledMessages.DeleteByGuest(s.botID, chatID)
+		if err != nil {
+			s.logger.Error("Failed to cancel held messages", zap.Error(err))
+			_, err := b.SendMessage(chatID, i18n.T(lang, "common.error"), nil)
+			return err
+		}
+		_, err = b.SendMessage(chatID, i18n.N(lang, "later.cancelled", deleted), nil)
+		return err
+	}
+
+	delay, err := parseLaterDelay(delayArg)
+	if err != nil || text == "" {
+		_, err := b.SendMessage(chatID, i18n.T(lang, "later.usage"), nil)
+		return err
+	}
+	maxDelay := time.Duration(s.config.Later.MaxDelayHours) * time.Hour
+	if delay > maxDelay {
+		_, err := b.SendMessage(chatID, i18n.T(lang, "later.too_long", approximateDuration(lang, maxDelay)), nil)
+		return err
+	}
+
+	accepted, err := s.checkTermsAccepted(b, update, lang)
+	if err != nil {
+		s.logger.Warn("Failed to check terms acceptance", zap.Error(err))
+		return err
+	}
+	if !accepted {
+		return nil
+	}
+
+	// The held message is the command with its text replaced, so it is sent
+	// as the guest's own message and replies to it quote the command
+	held := *update.EffectiveMessage
+	held.Text = text
+	held.Entities = nil
+	if s.screenHeld(b, &held, lang) {
+		return nil
+	}
+
+	// Held messages are delivered without asking for credits, so the paywall would not apply
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot for /later", zap.Error(err))
+		_, err := b.SendMessage(chatID, i18n.T(lang, "common.error"), nil)
+		return err
+	}
+	if bot.PaywallMode == models.PaywallModeRequired {
+		_, err := b.SendMessage(chatID, i18n.T(lang, "later.paywall"), nil)
+		return err
+	}
+
+	waiting, err := s.scheduledMessages.CountByGuest(s.botID, chatID)
+	if err != nil {
+		s.logger.Error("Failed to count held messages", zap.Error(err))
+		_, err := b.SendMessage(chatID, i18n.T(lang, "common.error"), nil)
+		return err
+	}
+	if waiting >= int64(s.config.Later.MaxPerGuest) {
+		_, err := b.SendMessage(chatID, i18n.T(lang, "later.limit", s.config.Later.MaxPerGuest), nil)
+		return err
+	}
+
+	data, err := json.Marshal(&held)
+	if err != nil {
+		return fmt.Errorf("failed to encode held message: %w", err)
+	}
+	scheduled := &models.ScheduledMessage{
+		BotID:          s.botID,
+		GuestChatID:    chatID,
+		GuestMessageID: held.MessageId,
+		Message:        string(data),
+		DueAt:          time.Now().Add(delay),
+	}
+	if err := s.scheduledMessages.Create(scheduled); err != nil {
+		s.logger.Error("Failed to hold message", zap.Error(err))
+		_, err := b.SendMessage(chatID, i18n.T(lang, "common.error"), nil)
+		return err
+	}
+
+	s.logger.Info("Guest message held for later",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("guest_chat_id", chatID),
+		zap.String("scheduled_message_id", scheduled.ID.String()),
+		zap.Time("due_at", scheduled.DueAt))
+
+	_, err = b.SendMessage(chatID, i18n.T(lang, "later.scheduled", approximateDuration(lang, delay)), &gotgbot.SendMessageOpts{
+		ReplyParameters: &gotgbot.ReplyParameters{MessageId: held.MessageId, AllowSendingWithoutReply: true},
+	})
+	return err
+}
+
+// listLater shows a guest the messages they have waiting
+func (s *Service) listLater(ctx context.Context, b *gotgbot.Bot, chatID int64, lang string) error {
+	waiting, err := s.scheduledMessages.GetByGuest(s.botID, chatID)
+	if err != nil {
+		s.logger.Error("Failed to get held messages", zap.Error(err))
+		_, err := b.SendMessage(chatID, i18n.T(lang, "common.error"), nil)
+		return err
+	}
+	if len(waiting) == 0 {
+		_, err := b.SendMessage(chatID, i18n.T(lang, "later.none")+"\n\n"+i18n.T(lang, "later.usage"), nil)
+		return err
+	}
+
+	var text strings.Builder
+	text.WriteString(i18n.T(lang, "later.list") + "\n")
+	now := time.Now()
+	for _, scheduled := range waiting {
+		var held gotgbot.Message
+		if err := json.Unmarshal([]byte(scheduled.Message), &held); err != nil {
+			continue
+		}
+		preview := []rune(held.Text)
+		if len(preview) > 40 {
+			preview = append(preview[:40], '…')
+		}
+		text.WriteString(fmt.Sprintf("\n• %s: %s", i18n.T(lang, "later.due_in", approximateDuration(lang, scheduled.DueAt.Sub(now))), string(preview)))
+	}
+	text.WriteString("\n\n" + i18n.T(lang, "later.cancel_hint"))
+	_, err = b.SendMessage(chatID, text.String(), nil)
+	return err
+}
+
+// screenHeld runs the content checks HandleMessage applies, the ad filter and
+// the bot's filter rules, on a held message and reports whether it was
+// blocked. The guest is told why. Held messages are checked when /later is
+// accepted and again when they are due, as the rules may have changed.
+func (s *Service) screenHeld(b *gotgbot.Bot, held *gotgbot.Message, lang string) bool {
+	if s.config.AdFilter.Enabled {
+		if hasAd, reason := s.containsAdContent(held, ""); hasAd {
+			s.logger.Debug("Held message contains ad content, blocking",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", held.From.Id),
+				zap.String("reason", reason))
+			if _, err := b.SendMessage(held.Chat.Id, adFilterNoticeText(lang, reason), nil); err != nil {
+				s.logger.Warn("Failed to send ad filter notification",
+					zap.String("bot_id", s.botID.String()),
+					zap.Int64("chat_id", held.Chat.Id),
+					zap.Error(err))
+			}
+			return true
+		}
+	}
+	return s.applyFilterRules(b, held, held.From, "")
+}
+
+// DeliverScheduled delivers the bot's held messages that are due. Run
+// periodically by the later worker.
+func (s *Service) DeliverScheduled(ctx context.Context, b *gotgbot.Bot) error {
+	if s.scheduledMessages == nil {
+		return nil
+	}
+	due, err := s.scheduledMessages.GetDue(s.botID, time.Now(), laterBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get held messages: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		return fmt.Errorf("failed to get bot: %w", err)
+	}
+
+	for _, scheduled := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.deliverScheduled(ctx, b, bot, scheduled)
+	}
+	return nil
+}
+
+// deliverScheduled makes one attempt at a held message. The message is
+// removed once at least one recipient got it, the guest was banned meanwhile,
+// or it failed too often.
+func (s *Service) deliverScheduled(ctx context.Context, b *gotgbot.Bot, bot *models.ForwarderBot, scheduled *models.ScheduledMessage) {
+	var held gotgbot.Message
+	if err := json.Unmarshal([]byte(scheduled.Message), &held); err != nil || held.From == nil {
+		s.logger.Warn("Dropping undecodable held message",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("scheduled_message_id", scheduled.ID.String()),
+			zap.Error(err))
+		s.removeScheduled(scheduled)
+		return
+	}
+	if isBlacklisted, err := s.blacklistService.IsBlacklisted(s.botID, held.From.Id); err == nil && isBlacklisted {
+		s.logger.Debug("Guest was blacklisted, dropping held message",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("scheduled_message_id", scheduled.ID.String()))
+		s.removeScheduled(scheduled)
+		return
+	}
+	lang := s.language(ctx, held.From)
+	if s.screenHeld(b, &held, lang) {
+		s.removeScheduled(scheduled)
+		return
+	}
+
+	header := fmt.Sprintf("%s\n⏰ Sent with /later, written %s",
+		s.guestLabel(bot, held.From), s.formatTime(scheduled.CreatedAt))
+	result, err := s.messageForwarder.ForwardHeld(ctx, b, s.botID, scheduled.GuestChatID, &held, header)
+	// No recipients is not a failure the next run would fix
+	if err == nil && (result.SuccessCount > 0 || result.FailureCount == 0) {
+		s.removeScheduled(scheduled)
+		s.logger.Info("Held message delivered",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("scheduled_message_id", scheduled.ID.String()),
+			zap.Int("success_count", result.SuccessCount))
+		if result.SuccessCount > 0 {
+			s.raiseKeywordAlert(b, scheduled.GuestChatID, held.From.Id, &held, "")
+			s.sendLaterNotice(b, scheduled, i18n.T(lang, "later.delivered"))
+		}
+		return
+	}
+
+	scheduled.Attempts++
+	if err == nil {
+		err = errors.New("no recipient received the message")
+	}
+	if scheduled.Attempts >= laterMaxAttempts {
+		s.logger.Warn("Giving up on held message",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("scheduled_message_id", scheduled.ID.String()),
+			zap.Int("attempts", scheduled.Attempts),
+			zap.Error(err))
+		s.removeScheduled(scheduled)
+		s.sendLaterNotice(b, scheduled, i18n.T(lang, "later.failed"))
+		return
+	}
+	s.logger.Debug("Held message not delivered, trying again later",
+		zap.String("bot_id", s.botID.String()),
+		zap.String("scheduled_message_id", scheduled.ID.String()),
+		zap.Int("attempts", scheduled.Attempts),
+		zap.Error(err))
+	scheduled.DueAt = time.Now().Add(time.Duration(scheduled.Attempts) * laterRetryDelay)
+	if err := s.scheduledMessages.Reschedule(scheduled); err != nil {
+		s.logger.Warn("Failed to reschedule held message",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("scheduled_message_id", scheduled.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// sendLaterNotice tells the guest what became of a held message, as a reply
+// to the /later command
+func (s *Service) sendLaterNotice(b *gotgbot.Bot, scheduled *models.ScheduledMessage, text string) {
+	if _, err := b.SendMessage(scheduled.GuestChatID, text, &gotgbot.SendMessageOpts{
+		ReplyParameters: &gotgbot.ReplyParameters{MessageId: scheduled.GuestMessageID, AllowSendingWithoutReply: true},
+	}); err != nil {
+		s.logger.Debug("Failed to send /later notice to guest",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("guest_chat_id", scheduled.GuestChatID),
+			zap.Error(err))
+	}
+}
+
+func (s *Service) removeScheduled(scheduled *models.ScheduledMessage) {
+	if err := s.scheduledMessages.Delete(s.botID, scheduled.ID); err != nil {
+		s.logger.Warn("Failed to remove held message",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("scheduled_message_id", scheduled.ID.String()),
+			zap.Error(err))
+	}
+}
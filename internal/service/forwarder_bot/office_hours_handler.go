@@ -0,0 +1,171 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/officehours"
+	"go-telegram-forwarder-bot/internal/service/settings"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+const officeHoursUsage = "Usage: /sethours <HH:MM-HH:MM> [days] [timezone]\n" +
+	"Examples:\n" +
+	"/sethours 09:00-18:00 Mon-Fri\n" +
+	"/sethours 10:00-16:00 Sat,Sun Europe/Berlin\n" +
+	"/sethours 22:00-06:00 daily\n" +
+	"Without a timezone the bot's timezone is used. Use /sethours off to stay open all the time."
+
+// officeHours returns the bot's office hours, or nil if it is always open
+func (s *Service) officeHours(bot *models.ForwarderBot) *officehours.Schedule {
+	if bot.OfficeHours == "" {
+		return nil
+	}
+	schedule, err := officehours.Parse(bot.OfficeHours)
+	if err != nil {
+		s.logger.Warn("Ignoring invalid office hours",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("office_hours", bot.OfficeHours),
+			zap.Error(err))
+		return nil
+	}
+	return schedule
+}
+
+// sendAwayMessage sends the away message to a guest who just started waiting
+// for a reply outside office hours. It reports whether the message was sent, so
+// the queue notice is not sent on top of it.
+func (s *Service) sendAwayMessage(b *gotgbot.Bot, chatID int64, userID int64) bool {
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot for away message", zap.Error(err))
+		return false
+	}
+	schedule := s.officeHours(bot)
+	now := time.Now()
+	if schedule == nil || schedule.IsOpen(now, bot.Location()) {
+		return false
+	}
+
+	loc := schedule.Location(bot.Location())
+	text := bot.AwayMessage
+	if text == "" {
+		text = models.DefaultAwayMessage
+	}
+	text = utils.FillPlaceholders(text, map[string]string{
+		"hours":    schedule.String(),
+		"opens_at": utils.FormatTimestamp(schedule.NextOpening(now, bot.Location()), loc),
+	})
+
+	if _, err := b.SendMessage(chatID, text, nil); err != nil {
+		s.logger.Warn("Failed to send away message to guest",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		return false
+	}
+	s.logger.Debug("Away message sent to guest",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID))
+	return true
+}
+
+// handleSetOfficeHours shows, sets or clears the bot's office hours
+func (s *Service) handleSetOfficeHours(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	if s.settings == nil {
+		_, err := b.SendMessage(chatID, "Settings are not available.", nil)
+		return err
+	}
+
+	spec := ""
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		spec = strings.TrimSpace(parts[1])
+	}
+	if spec == "" {
+		bot, err := s.botRepo.GetByID(s.botID)
+		if err != nil {
+			s.logger.Error("Failed to get bot", zap.Error(err))
+			_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+			return err
+		}
+		current := "none, always open"
+		if schedule := s.officeHours(bot); schedule != nil {
+			current = schedule.String()
+			if !schedule.IsOpen(time.Now(), bot.Location()) {
+				current += " (closed now)"
+			}
+		}
+		_, err = b.SendMessage(chatID, fmt.Sprintf("Office hours: %s\n\n%s", current, officeHoursUsage), nil)
+		return err
+	}
+
+	value := ""
+	if !strings.EqualFold(spec, "off") {
+		schedule, err := officehours.Parse(spec)
+		if err != nil {
+			_, err := b.SendMessage(chatID, fmt.Sprintf("Invalid office hours: %v\n\n%s", err, officeHoursUsage), nil)
+			return err
+		}
+		value = schedule.String()
+	}
+
+	if err := s.settings.Set(s.botID, settings.KeyOfficeHours, value); err != nil {
+		s.logger.Error("Failed to update office hours", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update the office hours. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Bot office hours updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.String("office_hours", value))
+
+	if value == "" {
+		_, err := b.SendMessage(chatID, "Office hours cleared. Guests no longer get the away message.", nil)
+		return err
+	}
+	_, err := b.SendMessage(chatID, fmt.Sprintf("Office hours set to %s. Guests writing outside them still have their messages forwarded and get the away message (see /setaway).", value), nil)
+	return err
+}
+
+// handleSetAwayMessage sets the message guests get outside office hours
+func (s *Service) handleSetAwayMessage(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	if s.settings == nil {
+		_, err := b.SendMessage(chatID, "Settings are not available.", nil)
+		return err
+	}
+
+	text := ""
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		text = strings.TrimSpace(parts[1])
+	}
+
+	if err := s.settings.Set(s.botID, settings.KeyAwayMessage, text); err != nil {
+		s.logger.Error("Failed to update away message", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update the away message. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Bot away message updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Bool("custom", text != ""))
+
+	if text == "" {
+		_, err := b.SendMessage(chatID, "Away message reset to the default:\n\n"+models.DefaultAwayMessage, nil)
+		return err
+	}
+	_, err := b.SendMessage(chatID, "Away message updated. {hours} and {opens_at} are replaced with the office hours and when they next begin.", nil)
+	return err
+}
@@ -0,0 +1,540 @@
+package forwarder_bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// paywallInvoiceCooldown keeps a guest who sends several unpaid messages from getting an invoice for each
+	paywallInvoiceCooldown = time.Minute
+	paywallPayloadPrefix   = "paywall"
+	recentPaymentsLimit    = 10
+)
+
+// paywallDecision is the outcome of checking a guest message against the bot's paywall
+type paywallDecision struct {
+	Forward  bool // Whether the message may be forwarded
+	Paid     bool // Whether a paid credit was used for the message
+	Priority bool // Whether recipients should see the message marked as priority
+}
+
+// paywallPayload encodes what an invoice sells, so the pre-checkout query can be
+// checked against the bot's settings at the time of payment
+func paywallPayload(botID uuid.UUID, credits int, price int) string {
+	return fmt.Sprintf("%s:%s:%d:%d", paywallPayloadPrefix, botID.String(), credits, price)
+}
+
+func parsePaywallPayload(payload string) (botID uuid.UUID, credits int, price int, err error) {
+	parts := strings.Split(payload, ":")
+	if len(parts) != 4 || parts[0] != paywallPayloadPrefix {
+		return uuid.Nil, 0, 0, fmt.Errorf("invalid paywall payload")
+	}
+	if botID, err = uuid.Parse(parts[1]); err != nil {
+		return uuid.Nil, 0, 0, fmt.Errorf("invalid bot ID in payload: %w", err)
+	}
+	if credits, err = strconv.Atoi(parts[2]); err != nil {
+		return uuid.Nil, 0, 0, fmt.Errorf("invalid credits in payload: %w", err)
+	}
+	if price, err = strconv.Atoi(parts[3]); err != nil {
+		return uuid.Nil, 0, 0, fmt.Errorf("invalid price in payload: %w", err)
+	}
+	return botID, credits, price, nil
+}
+
+// checkPaywall decides whether a guest message may be forwarded under the bot's paywall.
+// In required mode a message without a paid credit is held back and the guest gets an invoice.
+// In priority mode every message is forwarded and messages with a paid credit are marked.
+// Managers, admins and group chats are never charged.
+func (s *Service) checkPaywall(ctx context.Context, b *gotgbot.Bot, update *ext.Context) (paywallDecision, error) {
+	free := paywallDecision{Forward: true}
+	if update.EffectiveChat.Type != "private" {
+		return free, nil
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		return paywallDecision{}, fmt.Errorf("failed to get bot: %w", err)
+	}
+	if bot.PaywallMode == models.PaywallModeOff {
+		return free, nil
+	}
+
+	userID := update.EffectiveUser.Id
+	if isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID); isManagerOrAdmin {
+		return free, nil
+	}
+
+	consumed, err := s.guestRepo.ConsumeCredit(s.botID, userID)
+	if err != nil {
+		return paywallDecision{}, fmt.Errorf("failed to consume paid credit: %w", err)
+	}
+	if consumed {
+		s.logger.Debug("Paid credit used for guest message",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.String("paywall_mode", string(bot.PaywallMode)))
+		return paywallDecision{
+			Forward:  true,
+			Paid:     true,
+			Priority: bot.PaywallMode == models.PaywallModePriority,
+		}, nil
+	}
+
+	if bot.PaywallMode == models.PaywallModePriority {
+		return free, nil
+	}
+
+	now := time.Now()
+	if last, ok := s.paywallInvoiceCache.Load(userID); ok && now.Sub(last.(time.Time)) < paywallInvoiceCooldown {
+		return paywallDecision{}, nil
+	}
+	s.paywallInvoiceCache.Store(userID, now)
+
+	s.logger.Debug("Guest has no paid credits, sending invoice",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID))
+	if err := s.sendPaywallInvoice(b, update.EffectiveChat.Id, bot); err != nil {
+		return paywallDecision{}, err
+	}
+	return paywallDecision{}, nil
+}
+
+// refundPaywallCredit gives back a credit that was used for a message nobody received
+func (s *Service) refundPaywallCredit(userID int64) {
+	if err := s.guestRepo.AddCredits(s.botID, userID, 1); err != nil {
+		s.logger.Warn("Failed to give back paid credit",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+}
+
+// markPriorityMessage replies to each recipient's copy of a paid message so it stands out
+func (s *Service) markPriorityMessage(b *gotgbot.Bot, guestChatID int64, guestMessageID int64) {
+	mappings, err := s.messageMappingRepo.GetAllByGuestMessage(s.botID, guestChatID, guestMessageID)
+	if err != nil {
+		s.logger.Warn("Failed to get message mappings for priority notice",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("guest_message_id", guestMessageID),
+			zap.Error(err))
+		return
+	}
+	for _, mapping := range mappings {
+		_, err := b.SendMessage(mapping.RecipientChatID, "⭐ Priority message (paid with Telegram Stars)", &gotgbot.SendMessageOpts{
+			ReplyParameters: &gotgbot.ReplyParameters{
+				MessageId:                mapping.RecipientMessageID,
+				AllowSendingWithoutReply: true,
+			},
+		})
+		if err != nil {
+			s.logger.Warn("Failed to send priority notice",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("recipient_chat_id", mapping.RecipientChatID),
+				zap.Error(err))
+		}
+	}
+}
+
+// sendPaywallInvoice sends a Telegram Stars invoice for the bot's current paywall offer
+func (s *Service) sendPaywallInvoice(b *gotgbot.Bot, chatID int64, bot *models.ForwarderBot) error {
+	title := "Message credits"
+	description := fmt.Sprintf("%d messages to %s.", bot.PaywallCredits, bot.Name)
+	if bot.PaywallMode == models.PaywallModePriority {
+		title = "Priority messages"
+		description = fmt.Sprintf("%d messages marked as priority for %s.", bot.PaywallCredits, bot.Name)
+	}
+	if bot.PaywallMode == models.PaywallModeRequired {
+		description += " Messages are only delivered after payment."
+	}
+
+	_, err := b.SendInvoice(
+		chatID,
+		title,
+		description,
+		paywallPayload(bot.ID, bot.PaywallCredits, bot.PaywallPrice),
+		models.PaymentCurrencyStars,
+		[]gotgbot.LabeledPrice{{
+			Label:  fmt.Sprintf("%d messages", bot.PaywallCredits),
+			Amount: int64(bot.PaywallPrice),
+		}},
+		nil,
+	)
+	return err
+}
+
+// HandlePreCheckoutQuery confirms a paywall payment if the invoice still matches the bot's offer
+func (s *Service) HandlePreCheckoutQuery(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	query := update.PreCheckoutQuery
+
+	reject := func(reason string, message string) error {
+		s.logger.Debug("Rejecting pre-checkout query",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", query.From.Id),
+			zap.String("payload", query.InvoicePayload),
+			zap.String("reason", reason))
+		_, err := b.AnswerPreCheckoutQuery(query.Id, false, &gotgbot.AnswerPreCheckoutQueryOpts{
+			ErrorMessage: message,
+		})
+		return err
+	}
+
+	botID, credits, price, err := parsePaywallPayload(query.InvoicePayload)
+	if err != nil || botID != s.botID || query.Currency != models.PaymentCurrencyStars || query.TotalAmount != int64(price) {
+		return reject("invalid invoice", "This invoice is not valid.")
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		return reject("bot lookup failed", "Payments are temporarily unavailable. Please try again later.")
+	}
+	if bot.PaywallMode == models.PaywallModeOff || bot.PaywallPrice != price || bot.PaywallCredits != credits {
+		return reject("offer changed", "This offer has changed. Please send a message or use /credits to get a new invoice.")
+	}
+
+	_, err = b.AnswerPreCheckoutQuery(query.Id, true, nil)
+	return err
+}
+
+// handlePaymentMessage records successful and refunded paywall payments
+func (s *Service) handlePaymentMessage(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	msg := update.EffectiveMessage
+	if msg.SuccessfulPayment != nil {
+		return s.handleSuccessfulPayment(b, update.EffectiveChat.Id, update.EffectiveUser.Id, msg.SuccessfulPayment)
+	}
+	return s.handleRefundedPayment(msg.RefundedPayment)
+}
+
+func (s *Service) handleSuccessfulPayment(b *gotgbot.Bot, chatID int64, userID int64, payment *gotgbot.SuccessfulPayment) error {
+	_, credits, _, err := parsePaywallPayload(payment.InvoicePayload)
+	if err != nil {
+		s.logger.Warn("Successful payment with unknown payload",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.String("payload", payment.InvoicePayload),
+			zap.String("charge_id", payment.TelegramPaymentChargeId))
+		return nil
+	}
+
+	created, err := s.paymentRepo.Create(&models.Payment{
+		BotID:                   s.botID,
+		GuestUserID:             userID,
+		TelegramPaymentChargeID: payment.TelegramPaymentChargeId,
+		Payload:                 payment.InvoicePayload,
+		Currency:                payment.Currency,
+		Amount:                  payment.TotalAmount,
+		Credits:                 credits,
+		Status:                  models.PaymentStatusPaid,
+	})
+	if err != nil {
+		s.logger.Error("Failed to record payment",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.String("charge_id", payment.TelegramPaymentChargeId),
+			zap.Error(err))
+		return err
+	}
+	if !created {
+		return nil
+	}
+
+	if err := s.guestRepo.AddCredits(s.botID, userID, credits); err != nil {
+		s.logger.Error("Failed to add paid credits",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.String("charge_id", payment.TelegramPaymentChargeId),
+			zap.Error(err))
+		return err
+	}
+	s.paywallInvoiceCache.Delete(userID)
+
+	s.logger.Info("Guest paid for message credits",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int64("amount", payment.TotalAmount),
+		zap.Int("credits", credits),
+		zap.String("charge_id", payment.TelegramPaymentChargeId))
+
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Thank you! %d message credits were added. Please send your message again.", credits), nil)
+	return err
+}
+
+func (s *Service) handleRefundedPayment(refund *gotgbot.RefundedPayment) error {
+	payment, err := s.paymentRepo.GetByChargeID(refund.TelegramPaymentChargeId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if payment.BotID != s.botID {
+		return nil
+	}
+
+	// A refund made with /refund has already taken the credits back
+	_, err = s.revokePayment(payment)
+	return err
+}
+
+// revokePayment marks a payment refunded and takes back its credits.
+// It reports false if the payment had already been refunded.
+func (s *Service) revokePayment(payment *models.Payment) (bool, error) {
+	updated, err := s.paymentRepo.MarkRefunded(payment.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark payment refunded: %w", err)
+	}
+	if !updated {
+		return false, nil
+	}
+	if err := s.guestRepo.RemoveCredits(s.botID, payment.GuestUserID, payment.Credits); err != nil {
+		return true, fmt.Errorf("failed to remove refunded credits: %w", err)
+	}
+
+	s.logger.Info("Payment refunded",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", payment.GuestUserID),
+		zap.String("charge_id", payment.TelegramPaymentChargeID),
+		zap.Int("credits", payment.Credits))
+	return true, nil
+}
+
+// handleCredits shows a guest their paid credits and sends an invoice to buy more
+func (s *Service) handleCredits(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if bot.PaywallMode == models.PaywallModeOff {
+		_, err := b.SendMessage(chatID, "This bot does not sell message credits.", nil)
+		return err
+	}
+	if update.EffectiveChat.Type != "private" {
+		_, err := b.SendMessage(chatID, "Please use /credits in a private chat with this bot.", nil)
+		return err
+	}
+
+	credits := 0
+	if guest, err := s.guestRepo.GetByBotIDAndUserID(s.botID, update.EffectiveUser.Id); err == nil {
+		credits = guest.PaidCredits
+	}
+	if _, err := b.SendMessage(chatID, fmt.Sprintf("You have %d message credits.", credits), nil); err != nil {
+		return err
+	}
+	return s.sendPaywallInvoice(b, chatID, bot)
+}
+
+// handlePaywall shows or changes the bot's paywall settings
+func (s *Service) handlePaywall(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	parts := strings.Fields(update.EffectiveMessage.Text)
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	if len(parts) < 2 {
+		return s.sendPaywallStatus(b, chatID, bot)
+	}
+
+	mode := models.PaywallMode(strings.ToLower(parts[1]))
+	price, credits := 0, 0
+	switch mode {
+	case "off":
+		mode = models.PaywallModeOff
+	case models.PaywallModeRequired, models.PaywallModePriority:
+		if len(parts) < 3 {
+			_, err := b.SendMessage(chatID, "Usage: /paywall <required|priority> <stars> [messages]", nil)
+			return err
+		}
+		price, err = strconv.Atoi(parts[2])
+		if err != nil || price < 1 || price > settings.MaxPaywallPrice {
+			_, err := b.SendMessage(chatID,
+				fmt.Sprintf("Price must be a number of Stars between 1 and %d.", settings.MaxPaywallPrice), nil)
+			return err
+		}
+		credits = 1
+		if len(parts) >= 4 {
+			credits, err = strconv.Atoi(parts[3])
+			if err != nil || credits < 1 || credits > settings.MaxPaywallCredits {
+				_, err := b.SendMessage(chatID,
+					fmt.Sprintf("Messages per purchase must be a number between 1 and %d.", settings.MaxPaywallCredits), nil)
+				return err
+			}
+		}
+	default:
+		_, err := b.SendMessage(chatID, "Usage: /paywall [off|required|priority] [stars] [messages]", nil)
+		return err
+	}
+
+	modeChanged := bot.PaywallMode != mode
+	bot.PaywallMode = mode
+	bot.PaywallPrice = price
+	bot.PaywallCredits = credits
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update paywall", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update paywall. Please try again later.", nil)
+		return err
+	}
+	// The bot only receives payment checkouts while the paywall is on
+	if modeChanged && s.settings != nil {
+		s.settings.Changed(s.botID, settings.KeyPaywallMode)
+	}
+
+	s.logger.Info("Bot paywall updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.String("mode", string(mode)),
+		zap.Int("price", price),
+		zap.Int("credits", credits))
+
+	if user, _ := s.userRepo.GetByTelegramUserID(update.EffectiveUser.Id); user != nil {
+		details, _ := json.Marshal(map[string]interface{}{
+			"mode":    string(mode),
+			"price":   price,
+			"credits": credits,
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &user.ID,
+			ActionType:   models.AuditLogActionSetPaywall,
+			ResourceType: "bot",
+			ResourceID:   s.botID,
+			Details:      string(details),
+		}
+		s.auditLogRepo.Create(auditLog)
+	}
+
+	switch mode {
+	case models.PaywallModeOff:
+		_, err = b.SendMessage(chatID, "Paywall disabled. Guest messages are forwarded for free. Guests keep any credits they bought.", nil)
+	case models.PaywallModeRequired:
+		_, err = b.SendMessage(chatID,
+			fmt.Sprintf("Paywall enabled: guests must pay %d ⭐ per %d messages before their messages are forwarded.", price, credits), nil)
+	default:
+		_, err = b.SendMessage(chatID,
+			fmt.Sprintf("Priority paywall enabled: guests can pay %d ⭐ per %d priority messages with /credits. Unpaid messages are still forwarded.", price, credits), nil)
+	}
+	return err
+}
+
+func (s *Service) sendPaywallStatus(b *gotgbot.Bot, chatID int64, bot *models.ForwarderBot) error {
+	var text strings.Builder
+	switch bot.PaywallMode {
+	case models.PaywallModeRequired:
+		text.WriteString(fmt.Sprintf("Paywall: required, %d ⭐ per %d messages\n", bot.PaywallPrice, bot.PaywallCredits))
+	case models.PaywallModePriority:
+		text.WriteString(fmt.Sprintf("Paywall: priority, %d ⭐ per %d messages\n", bot.PaywallPrice, bot.PaywallCredits))
+	default:
+		text.WriteString("Paywall: off\n")
+	}
+
+	payments, err := s.paymentRepo.GetByBotID(s.botID, recentPaymentsLimit)
+	if err != nil {
+		s.logger.Warn("Failed to get recent payments", zap.Error(err))
+	} else if len(payments) > 0 {
+		text.WriteString("\nRecent payments:\n")
+		loc := bot.Location()
+		for _, payment := range payments {
+			status := ""
+			if payment.Status == models.PaymentStatusRefunded {
+				status = " (refunded)"
+			}
+			text.WriteString(fmt.Sprintf("%s  user %d  %d ⭐  %d messages%s\n  %s\n",
+				payment.CreatedAt.In(loc).Format("2006-01-02 15:04"), payment.GuestUserID, payment.Amount,
+				payment.Credits, status, payment.TelegramPaymentChargeID))
+		}
+	}
+
+	text.WriteString("\nUsage: /paywall <off|required|priority> [stars] [messages]\n")
+	text.WriteString("Refund a payment with /refund <charge_id>")
+	_, err = b.SendMessage(chatID, text.String(), nil)
+	return err
+}
+
+// handleRefund refunds a guest's Stars payment and takes back its remaining credits
+func (s *Service) handleRefund(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	parts := strings.Fields(update.EffectiveMessage.Text)
+	if len(parts) < 2 {
+		_, err := b.SendMessage(chatID, "Usage: /refund <charge_id>\nUse /paywall to see recent payments.", nil)
+		return err
+	}
+	chargeID := parts[1]
+
+	payment, err := s.paymentRepo.GetByChargeID(chargeID)
+	if err != nil || payment.BotID != s.botID {
+		_, err := b.SendMessage(chatID, "Payment not found.", nil)
+		return err
+	}
+	if payment.Status == models.PaymentStatusRefunded {
+		_, err := b.SendMessage(chatID, "This payment has already been refunded.", nil)
+		return err
+	}
+
+	if _, err := b.RefundStarPayment(payment.GuestUserID, payment.TelegramPaymentChargeID, nil); err != nil {
+		s.logger.Warn("Failed to refund Star payment",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("charge_id", chargeID),
+			zap.Error(err))
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Telegram rejected the refund: %v", err), nil)
+		return err
+	}
+
+	if _, err := s.revokePayment(payment); err != nil {
+		s.logger.Error("Failed to record refund",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("charge_id", chargeID),
+			zap.Error(err))
+	}
+
+	if user, _ := s.userRepo.GetByTelegramUserID(update.EffectiveUser.Id); user != nil {
+		details, _ := json.Marshal(map[string]interface{}{
+			"charge_id":     chargeID,
+			"guest_user_id": payment.GuestUserID,
+			"amount":        payment.Amount,
+			"credits":       payment.Credits,
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &user.ID,
+			ActionType:   models.AuditLogActionRefund,
+			ResourceType: "payment",
+			ResourceID:   payment.ID,
+			Details:      string(details),
+		}
+		s.auditLogRepo.Create(auditLog)
+	}
+
+	if _, err := b.SendMessage(payment.GuestUserID,
+		fmt.Sprintf("Your payment of %d ⭐ has been refunded.", payment.Amount), nil); err != nil {
+		s.logger.Debug("Failed to notify guest about refund",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", payment.GuestUserID),
+			zap.Error(err))
+	}
+
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Refunded %d ⭐ to user %d.", payment.Amount, payment.GuestUserID), nil)
+	return err
+}
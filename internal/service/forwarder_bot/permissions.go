@@ -0,0 +1,90 @@
+package forwarder_bot
+
+import (
+	"context"
+	"sync"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// permissionCache remembers the lookups behind IsManager and IsAdmin while one
+// update is handled. A single command checks the sender's role several times
+// (the command gate, /help, the staff command menu), and each check would
+// otherwise query the bot, the user and the admin list again.
+type permissionCache struct {
+	mu     sync.Mutex
+	bot    *lookup[*models.ForwarderBot]
+	users  map[int64]*lookup[*models.User]
+	admins map[uuid.UUID]*lookup[bool]
+}
+
+type lookup[T any] struct {
+	value T
+	err   error
+}
+
+type permissionCacheKey struct{}
+
+// withPermissionCache returns a context that caches permission lookups for the
+// update being handled. A context that already has a cache is returned as is,
+// so nested handlers share it.
+func withPermissionCache(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(permissionCacheKey{}).(*permissionCache); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, permissionCacheKey{}, &permissionCache{
+		users:  make(map[int64]*lookup[*models.User]),
+		admins: make(map[uuid.UUID]*lookup[bool]),
+	})
+}
+
+// cachedBot returns the bot, loading it once per update
+func (s *Service) cachedBot(ctx context.Context) (*models.ForwarderBot, error) {
+	cache, ok := ctx.Value(permissionCacheKey{}).(*permissionCache)
+	if !ok {
+		return s.botRepo.GetByID(s.botID)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.bot == nil {
+		bot, err := s.botRepo.GetByID(s.botID)
+		cache.bot = &lookup[*models.ForwarderBot]{value: bot, err: err}
+	}
+	return cache.bot.value, cache.bot.err
+}
+
+// cachedUser returns the user with the given Telegram ID, loading it once per update
+func (s *Service) cachedUser(ctx context.Context, telegramUserID int64) (*models.User, error) {
+	cache, ok := ctx.Value(permissionCacheKey{}).(*permissionCache)
+	if !ok {
+		return s.userRepo.GetByTelegramUserID(telegramUserID)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	result, ok := cache.users[telegramUserID]
+	if !ok {
+		user, err := s.userRepo.GetByTelegramUserID(telegramUserID)
+		result = &lookup[*models.User]{value: user, err: err}
+		cache.users[telegramUserID] = result
+	}
+	return result.value, result.err
+}
+
+// cachedIsAdmin reports whether the user is an admin of the bot, checking once per update
+func (s *Service) cachedIsAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	cache, ok := ctx.Value(permissionCacheKey{}).(*permissionCache)
+	if !ok {
+		return s.botAdminRepo.IsAdmin(s.botID, userID)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	result, ok := cache.admins[userID]
+	if !ok {
+		isAdmin, err := s.botAdminRepo.IsAdmin(s.botID, userID)
+		result = &lookup[bool]{value: isAdmin, err: err}
+		cache.admins[userID] = result
+	}
+	return result.value, result.err
+}
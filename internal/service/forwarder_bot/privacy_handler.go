@@ -0,0 +1,69 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// handleSetPrivacyHeader sets the header shown above guest messages when the
+// bot hides guest names
+func (s *Service) handleSetPrivacyHeader(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	if s.settings == nil {
+		_, err := b.SendMessage(chatID, "Settings are not available.", nil)
+		return err
+	}
+
+	header := ""
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		header = strings.TrimSpace(parts[1])
+	}
+	if strings.Contains(header, "\n") {
+		_, err := b.SendMessage(chatID, "The header must be a single line.", nil)
+		return err
+	}
+	if len([]rune(header)) > settings.MaxPrivacyHeaderLength {
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("The header must be at most %d characters.", settings.MaxPrivacyHeaderLength), nil)
+		return err
+	}
+
+	if err := s.settings.Set(s.botID, settings.KeyPrivacyHeader, header); err != nil {
+		s.logger.Error("Failed to update privacy header", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update the header. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Privacy header updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Bool("custom", header != ""))
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Header updated.", nil)
+		return err
+	}
+
+	// Show the header as recipients will see it, with an example guest number
+	example := bot.GuestHeader(&models.Guest{ID: s.botID})
+	text := fmt.Sprintf("Header updated. Guest messages will look like:\n\n%s\n…", example)
+	if header == "" {
+		text = fmt.Sprintf("Header reset to the default. Guest messages will look like:\n\n%s\n…", example)
+	}
+	if !bot.PrivacyMode {
+		text += "\n\nGuest names are not hidden yet. The manager can turn on \"Hide guest names\" in the bot's settings in the ManagerBot."
+	}
+	_, err = b.SendMessage(chatID, text, nil)
+	return err
+}
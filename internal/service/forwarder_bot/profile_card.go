@@ -0,0 +1,108 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ProfileCard describes the guest to recipients before the first message of a
+// conversation, for bots that turn the card on. In privacy mode the card shows
+// the guest's header instead of their name, username and ID.
+func (s *Service) ProfileCard(ctx context.Context, botID uuid.UUID, guest *models.Guest, message *gotgbot.Message, private bool) string {
+	if s.settings == nil || !s.settings.GetBool(botID, settings.KeyProfileCard) {
+		return ""
+	}
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot for guest profile card",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return ""
+	}
+
+	var card strings.Builder
+	card.WriteString("👤 New conversation\n")
+	if private {
+		card.WriteString(bot.GuestHeader(guest) + "\n")
+	} else if from := message.From; from != nil {
+		name := strings.TrimSpace(from.FirstName + " " + from.LastName)
+		if name != "" {
+			fmt.Fprintf(&card, "Name: %s\n", name)
+		}
+		if from.Username != "" {
+			fmt.Fprintf(&card, "Username: @%s\n", from.Username)
+		}
+		fmt.Fprintf(&card, "ID: %d\n", from.Id)
+	}
+	if message.From != nil && message.From.LanguageCode != "" {
+		fmt.Fprintf(&card, "Language: %s\n", message.From.LanguageCode)
+	}
+
+	count, err := s.statsService.GetGuestMessageCount(botID, guest.GuestUserID)
+	if err != nil {
+		s.logger.Warn("Failed to count guest messages for profile card",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+	} else if count == 0 {
+		card.WriteString("Earlier messages: none, this is the first\n")
+	} else {
+		fmt.Fprintf(&card, "Earlier messages: %s since %s\n",
+			utils.FormatNumber(count), utils.FormatTimestamp(guest.CreatedAt, bot.Location()))
+	}
+
+	history, err := s.blacklistService.GetHistory(botID, guest.ID)
+	if err != nil {
+		s.logger.Warn("Failed to get blacklist history for profile card",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+	} else {
+		card.WriteString("Blacklist: " + blacklistSummary(history, bot.Location()))
+	}
+	return strings.TrimRight(card.String(), "\n")
+}
+
+// blacklistSummary describes a guest's ban and unban requests, given most recent first
+func blacklistSummary(history []*models.Blacklist, loc *time.Location) string {
+	bans, unbans, pending := 0, 0, 0
+	var lastBan *models.Blacklist
+	for _, request := range history {
+		switch {
+		case request.Status == models.BlacklistStatusPending:
+			pending++
+		case request.Status != models.BlacklistStatusApproved:
+			continue
+		case request.RequestType == models.BlacklistRequestTypeBan:
+			bans++
+			if lastBan == nil {
+				lastBan = request
+			}
+		case request.RequestType == models.BlacklistRequestTypeUnban:
+			unbans++
+		}
+	}
+	if bans == 0 && pending == 0 {
+		return "never banned"
+	}
+
+	parts := make([]string, 0, 3)
+	if bans > 0 {
+		parts = append(parts, fmt.Sprintf("banned %d time(s), last on %s", bans, utils.FormatTimestamp(lastBan.CreatedAt, loc)))
+	}
+	if unbans > 0 {
+		parts = append(parts, fmt.Sprintf("unbanned %d time(s)", unbans))
+	}
+	if pending > 0 {
+		parts = append(parts, fmt.Sprintf("%d request(s) pending", pending))
+	}
+	return strings.Join(parts, ", ")
+}
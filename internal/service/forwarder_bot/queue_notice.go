@@ -0,0 +1,89 @@
+package forwarder_bot
+
+import (
+	"time"
+
+	"go-telegram-forwarder-bot/internal/i18n"
+	"go-telegram-forwarder-bot/internal/service/settings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// queueNoticeWindow is how far back replies count towards the usual response time
+	queueNoticeWindow = 7 * 24 * time.Hour
+	// queueNoticeMinReplies is how many replies are needed before a response time is quoted
+	queueNoticeMinReplies = 3
+)
+
+// sendQueueNotice tells a guest who just started waiting for a reply how many
+// conversations are ahead of theirs and how long replies usually take. It is
+// only sent if the bot has the queue notice setting enabled.
+func (s *Service) sendQueueNotice(b *gotgbot.Bot, chatID int64, userID int64, lang string) {
+	if s.settings == nil || !s.settings.GetBool(s.botID, settings.KeyQueueNotice) {
+		return
+	}
+
+	guest, err := s.guestRepo.GetByBotIDAndUserID(s.botID, chatID)
+	if err != nil || guest.AwaitingReplySince == nil {
+		s.logger.Warn("Failed to get conversation status for queue notice",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		return
+	}
+
+	ahead, err := s.guestRepo.CountAwaitingBefore(s.botID, *guest.AwaitingReplySince)
+	if err != nil {
+		s.logger.Warn("Failed to count waiting conversations",
+			zap.String("bot_id", s.botID.String()),
+			zap.Error(err))
+		return
+	}
+	average, replies, err := s.guestRepo.AverageResponseTime(s.botID, time.Now().Add(-queueNoticeWindow))
+	if err != nil {
+		s.logger.Warn("Failed to get average response time",
+			zap.String("bot_id", s.botID.String()),
+			zap.Error(err))
+		replies = 0
+	}
+
+	if _, err := b.SendMessage(chatID, queueNoticeText(lang, ahead, average, replies), nil); err != nil {
+		s.logger.Warn("Failed to send queue notice to guest",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+}
+
+// queueNoticeText builds the queue notice in lang. The response time is left
+// out until enough recent replies exist to make it meaningful.
+func queueNoticeText(lang string, ahead int64, average time.Duration, replies int64) string {
+	text := i18n.T(lang, "guest.queue.received") + " "
+	if ahead == 0 {
+		text += i18n.T(lang, "guest.queue.next")
+	} else {
+		text += i18n.N(lang, "guest.queue.ahead", ahead)
+	}
+	if replies >= queueNoticeMinReplies {
+		text += " " + i18n.T(lang, "guest.queue.reply_time", approximateDuration(lang, average))
+	}
+	return text
+}
+
+// approximateDuration rounds a duration to a rough, human-friendly phrase in lang
+func approximateDuration(lang string, d time.Duration) string {
+	minutes := int64(d.Round(time.Minute) / time.Minute)
+	hours := int64(d.Round(time.Hour) / time.Hour)
+	switch {
+	case minutes < 1:
+		return i18n.T(lang, "duration.a_minute")
+	case minutes < 60:
+		return i18n.N(lang, "duration.minutes", minutes)
+	case hours < 48:
+		return i18n.N(lang, "duration.hours", hours)
+	default:
+		return i18n.N(lang, "duration.days", int64(d.Round(24*time.Hour)/(24*time.Hour)))
+	}
+}
@@ -0,0 +1,153 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// handleSetRateLimit sets the per-bot guest rate limit and burst, or with
+// "api" the bot's Telegram API limit, or resets them to the global defaults
+func (s *Service) handleSetRateLimit(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	parts := strings.Fields(update.EffectiveMessage.Text)
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	if len(parts) < 2 {
+		current := fmt.Sprintf("global default (%d/s)", s.config.RateLimit.GuestMessage)
+		if bot.GuestRateLimit > 0 {
+			current = fmt.Sprintf("%d/s", bot.GuestRateLimit)
+		}
+		burst := "same as rate"
+		if bot.GuestRateBurst > 0 {
+			burst = fmt.Sprintf("%d messages", bot.GuestRateBurst)
+		}
+		api := fmt.Sprintf("shared global limit (%d/s)", s.config.RateLimit.TelegramAPI)
+		if bot.APIRateLimit > 0 {
+			api = fmt.Sprintf("%d/s", bot.APIRateLimit)
+		}
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("Current guest rate limit: %s, burst: %s\n"+
+				"Current Telegram API limit: %s\n\n"+
+				"Usage: /setratelimit <messages_per_second> [burst]\n"+
+				"Example: /setratelimit 1 3 (3 messages at once, then 1 per second)\n"+
+				"Use /setratelimit default to use the global setting.\n\n"+
+				"/setratelimit api <requests_per_second|default> sets how fast this bot may call Telegram, "+
+				"e.g. for a busy bot. By default it shares the global limit with other bots.", current, burst, api), nil)
+		return err
+	}
+
+	if strings.EqualFold(parts[1], "api") {
+		return s.setAPIRateLimit(b, update, bot, parts[2:])
+	}
+
+	rate, burst := 0, 0
+	if !strings.EqualFold(parts[1], "default") {
+		rate, err = strconv.Atoi(parts[1])
+		if err != nil || rate < 1 || rate > settings.MaxGuestRateLimit {
+			_, err := b.SendMessage(chatID,
+				fmt.Sprintf("Rate must be a number between 1 and %d.", settings.MaxGuestRateLimit), nil)
+			return err
+		}
+		if len(parts) >= 3 {
+			burst, err = strconv.Atoi(parts[2])
+			if err != nil || burst < 1 || burst > settings.MaxGuestRateBurst {
+				_, err := b.SendMessage(chatID,
+					fmt.Sprintf("Burst must be a number between 1 and %d.", settings.MaxGuestRateBurst), nil)
+				return err
+			}
+		}
+	}
+
+	bot.GuestRateLimit = rate
+	bot.GuestRateBurst = burst
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update guest rate limit", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update rate limit. Please try again later.", nil)
+		return err
+	}
+	s.rateLimitChanged(settings.KeyRateLimit)
+
+	s.logger.Info("Guest rate limit updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Int("rate", rate),
+		zap.Int("burst", burst))
+
+	if rate == 0 {
+		_, err = b.SendMessage(chatID,
+			fmt.Sprintf("Guest rate limit reset to the global default (%d/s).", s.config.RateLimit.GuestMessage), nil)
+		return err
+	}
+	if burst == 0 {
+		burst = rate
+	}
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Guest rate limit set to %d/s with a burst of %d messages.", rate, burst), nil)
+	return err
+}
+
+// setAPIRateLimit sets how many Telegram API requests per second the bot may
+// make, or with "default" makes it share the global limit again
+func (s *Service) setAPIRateLimit(b *gotgbot.Bot, update *ext.Context, bot *models.ForwarderBot, args []string) error {
+	chatID := update.EffectiveChat.Id
+	if len(args) != 1 {
+		_, err := b.SendMessage(chatID, "Usage: /setratelimit api <requests_per_second|default>", nil)
+		return err
+	}
+
+	rate := 0
+	if !strings.EqualFold(args[0], "default") {
+		var err error
+		rate, err = strconv.Atoi(args[0])
+		if err != nil || rate < 1 || rate > settings.MaxAPIRateLimit {
+			_, err := b.SendMessage(chatID,
+				fmt.Sprintf("Rate must be a number between 1 and %d.", settings.MaxAPIRateLimit), nil)
+			return err
+		}
+	}
+
+	bot.APIRateLimit = rate
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update API rate limit", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update rate limit. Please try again later.", nil)
+		return err
+	}
+	s.rateLimitChanged(settings.KeyAPIRateLimit)
+
+	s.logger.Info("Telegram API rate limit updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Int("rate", rate))
+
+	if rate == 0 {
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("The bot shares the global Telegram API limit (%d/s) again.", s.config.RateLimit.TelegramAPI), nil)
+		return err
+	}
+	_, err := b.SendMessage(chatID,
+		fmt.Sprintf("The bot may now make %d Telegram API requests per second.", rate), nil)
+	return err
+}
+
+// rateLimitChanged makes a changed limit take effect now rather than when the
+// rate limiter's cache expires
+func (s *Service) rateLimitChanged(key string) {
+	if s.settings != nil {
+		s.settings.Changed(s.botID, key)
+	}
+}
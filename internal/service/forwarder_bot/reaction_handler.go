@@ -0,0 +1,92 @@
+package forwarder_bot
+
+import (
+	"context"
+
+	"go-telegram-forwarder-bot/internal/service/settings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// HandleMessageReaction mirrors a reaction between a guest message and its
+// copies: a reaction in a recipient chat is set on the guest's message, and a
+// guest's reaction on every recipient copy. Telegram does not send updates for
+// reactions set by bots, so mirrored reactions do not come back.
+func (s *Service) HandleMessageReaction(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	reaction := update.MessageReaction
+	if s.settings == nil || !s.settings.GetBool(s.botID, settings.KeyReactionSync) {
+		return nil
+	}
+	chatID := reaction.Chat.Id
+
+	var targets []reactionTarget
+	if _, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, chatID); err == nil {
+		mapping, err := s.messageMappingRepo.GetByRecipientMessage(s.botID, chatID, reaction.MessageId)
+		if err != nil {
+			return nil
+		}
+		targets = append(targets, reactionTarget{chatID: mapping.GuestChatID, messageID: mapping.GuestMessageID})
+	} else {
+		if reaction.User != nil {
+			if blacklisted, err := s.blacklistService.IsBlacklisted(s.botID, reaction.User.Id); err == nil && blacklisted {
+				return nil
+			}
+		}
+		mappings, err := s.messageMappingRepo.GetAllByGuestMessage(s.botID, chatID, reaction.MessageId)
+		if err != nil {
+			s.logger.Warn("Failed to get copies of reacted message",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("chat_id", chatID),
+				zap.Int64("message_id", reaction.MessageId),
+				zap.Error(err))
+			return err
+		}
+		for _, mapping := range mappings {
+			targets = append(targets, reactionTarget{chatID: mapping.RecipientChatID, messageID: mapping.RecipientMessageID})
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	// Bots set at most one reaction per message; an empty list removes it
+	reactions := mirroredReaction(reaction.NewReaction)
+	for _, target := range targets {
+		if _, err := b.SetMessageReaction(target.chatID, target.messageID, &gotgbot.SetMessageReactionOpts{
+			Reaction: reactions,
+		}); err != nil {
+			// The chat may not allow the reaction, or the message is gone
+			s.logger.Debug("Failed to mirror reaction",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("chat_id", target.chatID),
+				zap.Int64("message_id", target.messageID),
+				zap.Error(err))
+		}
+	}
+	s.logger.Debug("Reaction mirrored",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("chat_id", chatID),
+		zap.Int64("message_id", reaction.MessageId),
+		zap.Int("targets", len(targets)))
+	return nil
+}
+
+// reactionTarget is a message a reaction is mirrored to
+type reactionTarget struct {
+	chatID    int64
+	messageID int64
+}
+
+// mirroredReaction returns the reaction the bot sets for a user's reactions:
+// the most recent emoji, since bots cannot use custom emoji or paid
+// reactions freely. No emoji removes the bot's reaction.
+func mirroredReaction(reactions []gotgbot.ReactionType) []gotgbot.ReactionType {
+	for i := len(reactions) - 1; i >= 0; i-- {
+		if emoji, ok := reactions[i].(gotgbot.ReactionTypeEmoji); ok {
+			return []gotgbot.ReactionType{emoji}
+		}
+	}
+	return []gotgbot.ReactionType{}
+}
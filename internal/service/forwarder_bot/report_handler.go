@@ -0,0 +1,140 @@
+package forwarder_bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// handleReport files an abuse report against this bot and escalates it to the superusers
+func (s *Service) handleReport(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /report command",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	if update.EffectiveChat.Type != "private" {
+		_, err := b.SendMessage(chatID, "Please send /report in a private chat with this bot.", nil)
+		return err
+	}
+
+	reason := ""
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		reason = strings.TrimSpace(parts[1])
+	}
+	if reason == "" {
+		_, err := b.SendMessage(chatID,
+			"Usage: /report <reason>\nDescribe what went wrong. Your report is sent to the administrators of this service, not to the bot owner.", nil)
+		return err
+	}
+
+	// Only keep one open report per guest and bot to avoid flooding superusers
+	existing, err := s.reportRepo.GetOpenByBotIDAndReporter(s.botID, userID)
+	if err == nil && existing != nil {
+		s.logger.Debug("Guest already has an open report",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.String("report_id", existing.ID.String()))
+		_, err := b.SendMessage(chatID, "You already have an open report for this bot. The administrators will review it shortly.", nil)
+		return err
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Error("Failed to check existing reports", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot for report", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	var usernamePtr *string
+	if update.EffectiveUser.Username != "" {
+		username := update.EffectiveUser.Username
+		usernamePtr = &username
+	}
+
+	report := &models.Report{
+		BotID:            s.botID,
+		ReporterUserID:   userID,
+		ReporterUsername: usernamePtr,
+		Reason:           reason,
+		Status:           models.ReportStatusOpen,
+	}
+	if err := s.reportRepo.Create(report); err != nil {
+		s.logger.Error("Failed to create report", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to submit your report. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Abuse report created",
+		zap.String("bot_id", s.botID.String()),
+		zap.String("report_id", report.ID.String()),
+		zap.Int64("reporter_user_id", userID))
+
+	// Log audit
+	reporter, err := s.userRepo.GetOrCreateByTelegramUserID(userID, usernamePtr)
+	if err == nil {
+		details, _ := json.Marshal(map[string]interface{}{
+			"bot_id":    s.botID.String(),
+			"report_id": report.ID.String(),
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &reporter.ID,
+			ActionType:   models.AuditLogActionReport,
+			ResourceType: "report",
+			ResourceID:   report.ID,
+			Details:      string(details),
+		}
+		s.auditLogRepo.Create(auditLog)
+	}
+
+	if s.superuserNotifier != nil {
+		reporterName := fmt.Sprintf("%d", userID)
+		if usernamePtr != nil {
+			reporterName = fmt.Sprintf("@%s (%d)", *usernamePtr, userID)
+		}
+		managerID := int64(0)
+		if bot.Manager.TelegramUserID != 0 {
+			managerID = bot.Manager.TelegramUserID
+		}
+		notification := fmt.Sprintf(
+			"🚩 *Abuse Report*\n\n"+
+				"Bot: @%s\n"+
+				"Manager ID: %d\n"+
+				"Reporter: %s\n"+
+				"Reason: %s\n"+
+				"Time: %s\n\n"+
+				"Use /reports to review open reports.",
+			utils.EscapeMarkdown(bot.Name),
+			managerID,
+			utils.EscapeMarkdown(reporterName),
+			utils.EscapeMarkdown(reason),
+			time.Now().Format("2006-01-02 15:04:05"),
+		)
+		s.superuserNotifier.NotifySuperusers(ctx, notification)
+	} else {
+		s.logger.Warn("Superuser notifier not set, report not escalated",
+			zap.String("report_id", report.ID.String()))
+	}
+
+	_, err = b.SendMessage(chatID, "Thank you. Your report has been forwarded to the administrators of this service.", nil)
+	return err
+}
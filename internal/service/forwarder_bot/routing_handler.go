@@ -0,0 +1,232 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/message"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var addRouteUsage = "Usage:\n" +
+	"/addroute <chat_id> keyword <word or phrase> - Send messages containing it to the recipient\n" +
+	"/addroute <chat_id> media <type> - Send messages of a type to the recipient\n" +
+	"/addroute <chat_id> lang <code> - Send messages from guests whose Telegram uses the language\n\n" +
+	"Media types: " + strings.Join(message.MediaTypes, ", ") + "\n" +
+	"A recipient without routes gets every message; one with routes only gets messages matching one of them. " +
+	"The fallback recipient always gets every message.\n" +
+	"Example: /addroute -1001234567890 lang de"
+
+// routingRules returns the bot's routing rules of recipients that still
+// exist, with the recipients by ID
+func (s *Service) routingRules() ([]*models.RoutingRule, map[uuid.UUID]*models.Recipient, error) {
+	recipients, err := s.recipientRepo.GetByBotID(s.botID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get recipients: %w", err)
+	}
+	byID := make(map[uuid.UUID]*models.Recipient, len(recipients))
+	for _, recipient := range recipients {
+		byID[recipient.ID] = recipient
+	}
+
+	rules, err := s.routingRuleRepo.GetByBotID(s.botID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get routing rules: %w", err)
+	}
+	kept := rules[:0]
+	for _, rule := range rules {
+		if _, ok := byID[rule.RecipientID]; ok {
+			kept = append(kept, rule)
+		}
+	}
+	return kept, byID, nil
+}
+
+// handleAddRoute adds a routing rule to a recipient
+func (s *Service) handleAddRoute(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	if s.routingRuleRepo == nil {
+		_, err := b.SendMessage(chatID, "Routing is not available.", nil)
+		return err
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(update.EffectiveMessage.Text), " ", 4)
+	if len(parts) < 4 {
+		_, err := b.SendMessage(chatID, addRouteUsage, nil)
+		return err
+	}
+	recipientChatID, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Invalid chat ID: %v", err), nil)
+		return err
+	}
+	kind, value, err := message.ParseRoutingRule(strings.TrimSpace(parts[2]), parts[3])
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Failed to add the route: %v\n\n%s", err, addRouteUsage), nil)
+		return err
+	}
+
+	recipient, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, recipientChatID)
+	if err != nil {
+		_, err := b.SendMessage(chatID, "Recipient not found.", nil)
+		return err
+	}
+
+	rules, _, err := s.routingRules()
+	if err != nil {
+		s.logger.Error("Failed to get routing rules", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if len(rules) >= message.MaxRoutingRules {
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("A bot can have at most %d routes. Remove one first.", message.MaxRoutingRules), nil)
+		return err
+	}
+
+	rule := &models.RoutingRule{
+		BotID:       s.botID,
+		RecipientID: recipient.ID,
+		Kind:        kind,
+		Value:       value,
+	}
+	if err := s.routingRuleRepo.Create(rule); err != nil {
+		s.logger.Error("Failed to create routing rule", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to add the route. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Routing rule added",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Int64("recipient_chat_id", recipient.ChatID),
+		zap.String("kind", string(kind)),
+		zap.String("value", value))
+
+	text := fmt.Sprintf("Route #%d added: %s gets %s.", len(rules)+1, recipient.Label(), formatRoutingRule(rule))
+	if recipient.IsFallback {
+		text += "\nThis is the fallback recipient, so it keeps getting every message until it is no longer the fallback."
+	}
+	_, err = b.SendMessage(chatID, text, nil)
+	return err
+}
+
+// handleDelRoute removes a routing rule by its number in /listroutes
+func (s *Service) handleDelRoute(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	if s.routingRuleRepo == nil {
+		_, err := b.SendMessage(chatID, "Routing is not available.", nil)
+		return err
+	}
+
+	var n int
+	var err error
+	if parts := strings.Fields(update.EffectiveMessage.Text); len(parts) == 2 {
+		n, err = strconv.Atoi(parts[1])
+	}
+	if err != nil || n < 1 {
+		_, err := b.SendMessage(chatID, "Usage: /delroute <number>\nSee /listroutes for the numbers.", nil)
+		return err
+	}
+
+	rules, recipients, err := s.routingRules()
+	if err != nil {
+		s.logger.Error("Failed to get routing rules", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if n > len(rules) {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("There is no route #%d. See /listroutes for the list.", n), nil)
+		return err
+	}
+
+	rule := rules[n-1]
+	if err := s.routingRuleRepo.Delete(s.botID, rule.ID); err != nil {
+		s.logger.Error("Failed to delete routing rule", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to remove the route. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Routing rule removed",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.String("rule_id", rule.ID.String()))
+
+	_, err = b.SendMessage(chatID, fmt.Sprintf("Route #%d (%s to %s) removed.",
+		n, formatRoutingRule(rule), recipients[rule.RecipientID].Label()), nil)
+	return err
+}
+
+// handleListRoutes lists the bot's routing rules grouped by recipient
+func (s *Service) handleListRoutes(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	if s.routingRuleRepo == nil {
+		_, err := b.SendMessage(chatID, "Routing is not available.", nil)
+		return err
+	}
+
+	rules, recipients, err := s.routingRules()
+	if err != nil {
+		s.logger.Error("Failed to get routing rules", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if len(rules) == 0 {
+		_, err := b.SendMessage(chatID, "No routes are set, so every recipient gets every message.\n\n"+addRouteUsage, nil)
+		return err
+	}
+
+	// Numbers follow the order the rules were added, as /delroute expects
+	numbers := make(map[uuid.UUID]int, len(rules))
+	var order []uuid.UUID
+	byRecipient := make(map[uuid.UUID][]*models.RoutingRule)
+	for i, rule := range rules {
+		numbers[rule.ID] = i + 1
+		if _, ok := byRecipient[rule.RecipientID]; !ok {
+			order = append(order, rule.RecipientID)
+		}
+		byRecipient[rule.RecipientID] = append(byRecipient[rule.RecipientID], rule)
+	}
+
+	var text strings.Builder
+	text.WriteString("Routes (a recipient gets a message if any of its routes matches):\n")
+	for _, recipientID := range order {
+		recipient := recipients[recipientID]
+		fmt.Fprintf(&text, "\n%s", recipient.Label())
+		if recipient.IsFallback {
+			text.WriteString(" - fallback, gets every message")
+		}
+		text.WriteString("\n")
+		for _, rule := range byRecipient[recipientID] {
+			fmt.Fprintf(&text, "%d. %s\n", numbers[rule.ID], formatRoutingRule(rule))
+		}
+	}
+	if len(order) < len(recipients) {
+		text.WriteString("\nRecipients without routes get every message.\n")
+	}
+	text.WriteString("\nUse /delroute <number> to remove a route.")
+
+	_, err = b.SendMessage(chatID, text.String(), nil)
+	return err
+}
+
+// formatRoutingRule describes what a rule matches
+func formatRoutingRule(rule *models.RoutingRule) string {
+	switch rule.Kind {
+	case models.RoutingRuleKeyword:
+		return fmt.Sprintf("messages containing %q", rule.Value)
+	case models.RoutingRuleMedia:
+		return rule.Value + " messages"
+	case models.RoutingRuleLanguage:
+		return fmt.Sprintf("guests using %s", rule.Value)
+	default:
+		return fmt.Sprintf("%s %s", rule.Kind, rule.Value)
+	}
+}
@@ -2,14 +2,21 @@ package forwarder_bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/i18n"
+	"go-telegram-forwarder-bot/internal/models"
 	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/service"
 	"go-telegram-forwarder-bot/internal/service/blacklist"
+	"go-telegram-forwarder-bot/internal/service/filescan"
+	"go-telegram-forwarder-bot/internal/service/llm"
 	"go-telegram-forwarder-bot/internal/service/message"
 	"go-telegram-forwarder-bot/internal/service/statistics"
 	"go-telegram-forwarder-bot/internal/utils"
@@ -31,13 +38,112 @@ type Service struct {
 	messageMappingRepo           repository.MessageMappingRepository
 	userRepo                     repository.UserRepository
 	auditLogRepo                 repository.AuditLogRepository
+	reportRepo                   repository.ReportRepository
+	adminInviteRepo              repository.AdminInviteRepository
+	paymentRepo                  repository.PaymentRepository
+	welcomeAssignmentRepo        repository.WelcomeAssignmentRepository
+	autoReplyRepo                repository.AutoReplyRepository
 	messageForwarder             *message.Forwarder
 	blacklistService             *blacklist.Service
 	statsService                 *statistics.Service
 	config                       *config.Config
 	logger                       *zap.Logger
 	encryptionKey                []byte
-	commandsCache                sync.Map // Cache to track users whose commands have been updated
+	superuserNotifier            SuperuserNotifierInterface
+	recipientInfoRefresher       RecipientInfoRefresherInterface
+	settings                     SettingsInterface
+	suggester                    SuggesterInterface
+	transcriber                  TranscriberInterface
+	ocr                          OCRInterface
+	fileScanner                  FileScannerInterface
+	adFilter                     AdFilterInterface
+	routingRuleRepo              repository.RoutingRuleRepository
+	managerNotifier              message.ManagerNotifierInterface
+	lockdown                     LockdownInterface
+	accessMonitor                AccessMonitorInterface
+	featureFlags                 FeatureFlagsInterface
+	recipientValidator           RecipientValidatorInterface
+	floodDetector                *message.FloodDetector
+	scheduledMessages            repository.ScheduledMessageRepository
+	commandsCache                sync.Map    // Cache to track users whose commands have been updated
+	failureNoticeCache           sync.Map    // Guest user ID -> time of last delivery failure notice
+	paywallInvoiceCache          sync.Map    // Guest user ID -> time of last unrequested paywall invoice
+	unauthorizedGroupCache       sync.Map    // Unregistered group chat IDs the manager was already told about
+	pendingBroadcasts            sync.Map    // Broadcast ID -> *pendingBroadcast awaiting confirmation
+	broadcasting                 atomic.Bool // Set while a broadcast is being sent
+}
+
+// SuperuserNotifierInterface delivers messages to the instance's superusers via the ManagerBot
+type SuperuserNotifierInterface interface {
+	NotifySuperusers(ctx context.Context, message string)
+}
+
+// RecipientInfoRefresherInterface fetches and stores a recipient's chat title and username
+type RecipientInfoRefresherInterface interface {
+	Refresh(ctx context.Context, bot *gotgbot.Bot, recipient *models.Recipient) error
+}
+
+// SettingsInterface reads and writes per-bot settings stored outside the bot record
+type SettingsInterface interface {
+	Get(botID uuid.UUID, key string) (string, error)
+	GetBool(botID uuid.UUID, key string) bool
+	Set(botID uuid.UUID, key, value string) error
+	Changed(botID uuid.UUID, key string)
+}
+
+// SuggesterInterface keeps recent conversation text and drafts staff replies from it
+type SuggesterInterface interface {
+	Record(botID uuid.UUID, guestChatID int64, role llm.Role, text string)
+	Draft(ctx context.Context, botID uuid.UUID, guestChatID int64) (string, error)
+}
+
+// AdFilterInterface checks guest messages against the bot's own filter rules
+// and holds quarantined messages
+type AdFilterInterface interface {
+	Match(botID uuid.UUID, texts ...string) (*models.FilterRule, error)
+	Rules(botID uuid.UUID) ([]*models.FilterRule, error)
+	AddRule(rule *models.FilterRule) error
+	DeleteRule(botID uuid.UUID, id uuid.UUID) error
+	Quarantine(botID uuid.UUID, rule *models.FilterRule, msg *gotgbot.Message) (*models.QuarantinedMessage, error)
+	Release(botID uuid.UUID, id uuid.UUID) (*gotgbot.Message, error)
+	Discard(botID uuid.UUID, id uuid.UUID) error
+}
+
+// FileScannerInterface checks files guests send for malware
+type FileScannerInterface interface {
+	ScanDocument(ctx context.Context, bot *gotgbot.Bot, doc *gotgbot.Document) (filescan.Verdict, error)
+}
+
+// OCRInterface reads the text in images guests send
+type OCRInterface interface {
+	RecognizeImage(ctx context.Context, bot *gotgbot.Bot, msg *gotgbot.Message) (string, error)
+}
+
+// LockdownInterface tells whether the /lockdown of the ManagerBot keeps a user out
+type LockdownInterface interface {
+	// Reject tells the user of the update they are locked out and reports whether they are
+	Reject(b *gotgbot.Bot, update *ext.Context) (bool, error)
+}
+
+// AccessMonitorInterface audits commands and buttons refused for lack of
+// permission and alerts superusers about users who keep trying
+type AccessMonitorInterface interface {
+	Denied(ctx context.Context, telegramUserID int64, username string, botID uuid.UUID, action string)
+}
+
+// FeatureFlagsInterface decides which features the tier of the bot's manager includes
+type FeatureFlagsInterface interface {
+	IsEnabledForBot(botID uuid.UUID, feature service.Feature) (bool, error)
+}
+
+// RecipientValidatorInterface checks chats before they are added as recipients
+type RecipientValidatorInterface interface {
+	Validate(b *gotgbot.Bot, forwarderBot *models.ForwarderBot, chatID int64) (models.RecipientType, error)
+}
+
+// TranscriberInterface turns guest voice messages into text
+type TranscriberInterface interface {
+	TranscribeVoice(ctx context.Context, bot *gotgbot.Bot, voice *gotgbot.Voice) (string, error)
 }
 
 func NewService(
@@ -51,6 +157,11 @@ func NewService(
 	messageMappingRepo repository.MessageMappingRepository,
 	userRepo repository.UserRepository,
 	auditLogRepo repository.AuditLogRepository,
+	reportRepo repository.ReportRepository,
+	adminInviteRepo repository.AdminInviteRepository,
+	paymentRepo repository.PaymentRepository,
+	welcomeAssignmentRepo repository.WelcomeAssignmentRepository,
+	autoReplyRepo repository.AutoReplyRepository,
 	messageForwarder *message.Forwarder,
 	blacklistService *blacklist.Service,
 	statsService *statistics.Service,
@@ -73,6 +184,11 @@ func NewService(
 		messageMappingRepo:           messageMappingRepo,
 		userRepo:                     userRepo,
 		auditLogRepo:                 auditLogRepo,
+		reportRepo:                   reportRepo,
+		adminInviteRepo:              adminInviteRepo,
+		paymentRepo:                  paymentRepo,
+		welcomeAssignmentRepo:        welcomeAssignmentRepo,
+		autoReplyRepo:                autoReplyRepo,
 		messageForwarder:             messageForwarder,
 		blacklistService:             blacklistService,
 		statsService:                 statsService,
@@ -82,11 +198,157 @@ func NewService(
 	}, nil
 }
 
-func (s *Service) IsManager(userID int64) (bool, error) {
+// SetSuperuserNotifier sets the notifier used to escalate abuse reports to superusers
+func (s *Service) SetSuperuserNotifier(notifier SuperuserNotifierInterface) {
+	s.superuserNotifier = notifier
+}
+
+// SetRecipientInfoRefresher sets the refresher used to fetch display info of new recipients
+func (s *Service) SetRecipientInfoRefresher(refresher RecipientInfoRefresherInterface) {
+	s.recipientInfoRefresher = refresher
+}
+
+// SetSettings sets the per-bot settings service, e.g. for the guest help text
+func (s *Service) SetSettings(settingsService SettingsInterface) {
+	s.settings = settingsService
+}
+
+// SetSuggester enables /suggest draft replies
+func (s *Service) SetSuggester(suggester SuggesterInterface) {
+	s.suggester = suggester
+}
+
+// SetTranscriber enables voice message transcription for bots that turn it on
+func (s *Service) SetTranscriber(transcriber TranscriberInterface) {
+	s.transcriber = transcriber
+}
+
+// SetOCR enables reading text in guest images for bots that turn it on
+func (s *Service) SetOCR(ocr OCRInterface) {
+	s.ocr = ocr
+}
+
+// SetAdFilter enables the bot's own filter rules and the filter commands
+func (s *Service) SetAdFilter(adFilter AdFilterInterface) {
+	s.adFilter = adFilter
+}
+
+// SetRoutingRules enables the routing commands
+func (s *Service) SetRoutingRules(repo repository.RoutingRuleRepository) {
+	s.routingRuleRepo = repo
+}
+
+// SetFileScanner enables scanning guest files for bots that turn it on
+func (s *Service) SetFileScanner(scanner FileScannerInterface) {
+	s.fileScanner = scanner
+}
+
+// SetManagerNotifier sets how the bot's manager is notified through the ManagerBot
+func (s *Service) SetManagerNotifier(notifier message.ManagerNotifierInterface) {
+	s.managerNotifier = notifier
+}
+
+// SetLockdown sets the lockdown that disables commands for everyone but superusers
+func (s *Service) SetLockdown(lockdown LockdownInterface) {
+	s.lockdown = lockdown
+}
+
+// SetAccessMonitor sets the monitor told about refused commands and buttons
+func (s *Service) SetAccessMonitor(monitor AccessMonitorInterface) {
+	s.accessMonitor = monitor
+}
+
+// SetFeatureFlags sets the tier feature flags consulted by /broadcast
+func (s *Service) SetFeatureFlags(flags FeatureFlagsInterface) {
+	s.featureFlags = flags
+}
+
+// SetRecipientValidator makes /addrecipient and /setfallback reject the bot
+// itself, unreachable chats and guests
+func (s *Service) SetRecipientValidator(validator RecipientValidatorInterface) {
+	s.recipientValidator = validator
+}
+
+// accessDenied records that the user of the update was refused the action
+func (s *Service) accessDenied(ctx context.Context, update *ext.Context, action string) {
+	if s.accessMonitor == nil || update.EffectiveUser == nil {
+		return
+	}
+	s.accessMonitor.Denied(ctx, update.EffectiveUser.Id, update.EffectiveUser.Username, s.botID, action)
+}
+
+// lockedOut reports whether the lockdown keeps the user out, telling them so
+func (s *Service) lockedOut(b *gotgbot.Bot, update *ext.Context) bool {
+	if s.lockdown == nil {
+		return false
+	}
+	blocked, err := s.lockdown.Reject(b, update)
+	if !blocked {
+		return false
+	}
+
+	s.logger.Debug("Update rejected during lockdown",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id))
+	if err != nil {
+		s.logger.Debug("Failed to send lockdown notice", zap.Error(err))
+	}
+	return true
+}
+
+// refreshRecipientInfo fetches the display info of a newly added recipient.
+// Failures are not fatal: the periodic refresh will retry.
+func (s *Service) refreshRecipientInfo(ctx context.Context, b *gotgbot.Bot, recipient *models.Recipient) {
+	if s.recipientInfoRefresher == nil {
+		return
+	}
+	if err := s.recipientInfoRefresher.Refresh(ctx, b, recipient); err != nil {
+		s.logger.Debug("Failed to fetch recipient info",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", recipient.ChatID),
+			zap.Error(err))
+	}
+}
+
+// checkNewRecipient returns the type of a chat about to be added as a
+// recipient. If the chat cannot be one, it tells the user why and returns
+// ok false.
+func (s *Service) checkNewRecipient(ctx context.Context, b *gotgbot.Bot, chatID int64, newRecipientChatID int64) (recipientType models.RecipientType, ok bool, err error) {
+	if s.recipientValidator == nil {
+		// Without a validator, guess from the ID: groups and channels are negative
+		if newRecipientChatID < 0 {
+			return models.RecipientTypeGroup, true, nil
+		}
+		return models.RecipientTypeUser, true, nil
+	}
+
+	bot, err := s.cachedBot(ctx)
+	if err == nil {
+		recipientType, err = s.recipientValidator.Validate(b, bot, newRecipientChatID)
+	}
+	if err != nil {
+		var invalid *service.InvalidRecipientError
+		if errors.As(err, &invalid) {
+			_, err := b.SendMessage(chatID, fmt.Sprintf("Cannot add %d as a recipient: %s.", newRecipientChatID, invalid.Reason), nil)
+			return "", false, err
+		}
+		s.logger.Error("Failed to validate recipient",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", newRecipientChatID),
+			zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to add recipient. Please try again later.", nil)
+		return "", false, err
+	}
+	return recipientType, true, nil
+}
+
+// IsManager reports whether the user is the bot's manager. The lookups are
+// cached for the rest of the update when ctx comes from an update handler.
+func (s *Service) IsManager(ctx context.Context, userID int64) (bool, error) {
 	s.logger.Debug("Checking if user is manager",
 		zap.String("bot_id", s.botID.String()),
 		zap.Int64("user_id", userID))
-	bot, err := s.botRepo.GetByID(s.botID)
+	bot, err := s.cachedBot(ctx)
 	if err != nil {
 		s.logger.Debug("Failed to get bot for manager check",
 			zap.String("bot_id", s.botID.String()),
@@ -95,7 +357,7 @@ func (s *Service) IsManager(userID int64) (bool, error) {
 		return false, err
 	}
 
-	user, err := s.userRepo.GetByTelegramUserID(userID)
+	user, err := s.cachedUser(ctx, userID)
 	if err != nil {
 		s.logger.Debug("Failed to get user for manager check",
 			zap.String("bot_id", s.botID.String()),
@@ -114,11 +376,12 @@ func (s *Service) IsManager(userID int64) (bool, error) {
 	return isManager, nil
 }
 
-func (s *Service) IsAdmin(userID int64) (bool, error) {
+// IsAdmin reports whether the user is an admin of the bot, cached like IsManager
+func (s *Service) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 	s.logger.Debug("Checking if user is admin",
 		zap.String("bot_id", s.botID.String()),
 		zap.Int64("user_id", userID))
-	user, err := s.userRepo.GetByTelegramUserID(userID)
+	user, err := s.cachedUser(ctx, userID)
 	if err != nil {
 		s.logger.Debug("Failed to get user for admin check",
 			zap.String("bot_id", s.botID.String()),
@@ -127,7 +390,7 @@ func (s *Service) IsAdmin(userID int64) (bool, error) {
 		return false, err
 	}
 
-	isAdmin, err := s.botAdminRepo.IsAdmin(s.botID, user.ID)
+	isAdmin, err := s.cachedIsAdmin(ctx, user.ID)
 	if err != nil {
 		s.logger.Debug("Failed to check admin status",
 			zap.String("bot_id", s.botID.String()),
@@ -142,11 +405,11 @@ func (s *Service) IsAdmin(userID int64) (bool, error) {
 	return isAdmin, err
 }
 
-func (s *Service) IsManagerOrAdmin(userID int64) (bool, error) {
+func (s *Service) IsManagerOrAdmin(ctx context.Context, userID int64) (bool, error) {
 	s.logger.Debug("Checking if user is manager or admin",
 		zap.String("bot_id", s.botID.String()),
 		zap.Int64("user_id", userID))
-	isManager, err := s.IsManager(userID)
+	isManager, err := s.IsManager(ctx, userID)
 	if err != nil {
 		return false, err
 	}
@@ -157,58 +420,19 @@ func (s *Service) IsManagerOrAdmin(userID int64) (bool, error) {
 		return true, nil
 	}
 
-	return s.IsAdmin(userID)
+	return s.IsAdmin(ctx, userID)
 }
 
-// updateCommands updates the command menu for all users (global commands)
+// updateCommands updates the command menu for all users (global commands).
+// Private chats get the guest commands only; managers and admins get the full
+// list through updateStaffCommands.
 func (s *Service) updateCommands(_ context.Context, b *gotgbot.Bot) {
 	// Check cache to avoid frequent API calls
 	if _, exists := s.commandsCache.Load("commands_set"); exists {
 		return
 	}
 
-	// Include all commands for all users
-	var commands []gotgbot.BotCommand
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "help",
-		Description: "Show help message",
-	})
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "addrecipient",
-		Description: "Add a recipient",
-	})
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "delrecipient",
-		Description: "Remove a recipient",
-	})
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "listrecipient",
-		Description: "List all recipients",
-	})
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "addadmin",
-		Description: "Add an admin (Manager only)",
-	})
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "deladmin",
-		Description: "Remove an admin (Manager only)",
-	})
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "listadmins",
-		Description: "List all admins",
-	})
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "stats",
-		Description: "View bot statistics",
-	})
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "ban",
-		Description: "Ban a guest (reply to their message)",
-	})
-	commands = append(commands, gotgbot.BotCommand{
-		Command:     "unban",
-		Description: "Unban a guest (reply to their message, or use directly to request unban for yourself)",
-	})
+	commands := guestCommands()
 
 	// Set commands for private chats (default scope)
 	scope := gotgbot.BotCommandScopeDefault{}
@@ -230,7 +454,8 @@ func (s *Service) updateCommands(_ context.Context, b *gotgbot.Bot) {
 		Scope: groupScope,
 	}
 
-	_, err = b.SetMyCommands(commands, groupOpts)
+	// Group chats are recipients, whose members handle guests
+	_, err = b.SetMyCommands(s.staffCommands(), groupOpts)
 	if err != nil {
 		s.logger.Warn("Failed to set commands for group chats",
 			zap.String("bot_id", s.botID.String()),
@@ -257,64 +482,236 @@ func (s *Service) updateCommands(_ context.Context, b *gotgbot.Bot) {
 		zap.Int("command_count", len(commands)))
 }
 
-// isSystemMessage checks if a message is a system message (e.g., user joined/left, chat title changed, etc.)
-// System messages cannot be forwarded and should be ignored
-func (s *Service) isSystemMessage(message *gotgbot.Message) bool {
-	// System messages typically don't have a From field
-	if message.From == nil {
-		return true
+// updateStaffCommands shows the full command list to a manager or admin in
+// their private chat with the bot
+func (s *Service) updateStaffCommands(b *gotgbot.Bot, userID int64) {
+	cacheKey := fmt.Sprintf("staff:%d", userID)
+	if _, exists := s.commandsCache.Load(cacheKey); exists {
+		return
 	}
 
-	// Check for various system message types
-	if len(message.NewChatMembers) > 0 {
-		return true
-	}
-	if message.LeftChatMember != nil {
-		return true
-	}
-	if message.NewChatTitle != "" {
-		return true
-	}
-	if message.NewChatPhoto != nil {
-		return true
-	}
-	if message.DeleteChatPhoto {
-		return true
-	}
-	if message.GroupChatCreated {
-		return true
-	}
-	if message.SupergroupChatCreated {
-		return true
-	}
-	if message.ChannelChatCreated {
-		return true
-	}
-	if message.MigrateToChatId != 0 {
-		return true
+	_, err := b.SetMyCommands(s.staffCommands(), &gotgbot.SetMyCommandsOpts{
+		Scope: gotgbot.BotCommandScopeChat{ChatId: userID},
+	})
+	if err != nil {
+		s.logger.Warn("Failed to set staff commands",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		return
 	}
-	if message.PinnedMessage != nil {
-		return true
+	s.commandsCache.Store(cacheKey, true)
+}
+
+// guestCommands are the commands shown to guests
+func guestCommands() []gotgbot.BotCommand {
+	return []gotgbot.BotCommand{
+		{Command: "help", Description: "How this bot works"},
+		{Command: "terms", Description: "Show the terms of this bot"},
+		{Command: "credits", Description: "Show your message credits and buy more"},
+		{Command: "later", Description: "Send a message later, e.g. /later 2h <text>"},
+		{Command: "unban", Description: "Ask to be unblocked"},
+		{Command: "report", Description: "Report abuse of this bot to the instance administrators"},
+		{Command: "language", Description: "Choose the language of this bot's messages"},
 	}
-	if message.ProximityAlertTriggered != nil {
-		return true
+}
+
+// staffCommands are the commands shown to managers, admins and group recipients
+func (s *Service) staffCommands() []gotgbot.BotCommand {
+	var commands []gotgbot.BotCommand
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "help",
+		Description: "Show help message",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "addrecipient",
+		Description: "Add a recipient",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "delrecipient",
+		Description: "Remove a recipient",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "listrecipient",
+		Description: "List all recipients",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "setalias",
+		Description: "Set or clear a recipient's alias",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "setfallback",
+		Description: "Set or clear the fallback recipient",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "addadmin",
+		Description: "Add an admin (Manager only)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "inviteadmin",
+		Description: "Create a one-time admin invite link (Manager only)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "deladmin",
+		Description: "Remove an admin (Manager only)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "listadmins",
+		Description: "List all admins",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "stats",
+		Description: "View bot statistics",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "ban",
+		Description: "Ban a guest, with an optional reason (reply to their message)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "baninfo",
+		Description: "Show a guest's ban history (reply to their message)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "unban",
+		Description: "Unban a guest (reply to their message, or use directly to request unban for yourself)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "del",
+		Description: "Delete a message for the guest (reply to it; /del all also deletes recipient copies)",
+	})
+	if s.suggester != nil {
+		commands = append(commands, gotgbot.BotCommand{
+			Command:     "suggest",
+			Description: "Draft a reply to a guest (reply to their message)",
+		})
 	}
-	if message.WebAppData != nil {
-		return true
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "terms",
+		Description: "Show the terms of this bot",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "setratelimit",
+		Description: "Set the guest rate limit and burst for this bot",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "setterms",
+		Description: "Set or clear the terms guests must accept",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "setwelcome",
+		Description: "Set the welcome message and its A/B variant",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "sethelp",
+		Description: "Set the help text guests see",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "sethours",
+		Description: "Set the office hours guests get replies in",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "setaway",
+		Description: "Set the message guests get outside office hours",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "setprivacyheader",
+		Description: "Set the header shown above guest messages when names are hidden",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "setalerts",
+		Description: "Set keywords that ping the manager",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "alerts",
+		Description: "List conversations tagged by keyword alerts",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "autoreply",
+		Description: "Manage keyword auto-replies for guests",
+	})
+	if s.adFilter != nil {
+		commands = append(commands, gotgbot.BotCommand{
+			Command:     "addfilter",
+			Description: "Block or quarantine guest messages by keyword or regex",
+		})
+		commands = append(commands, gotgbot.BotCommand{
+			Command:     "delfilter",
+			Description: "Remove a message filter",
+		})
+		commands = append(commands, gotgbot.BotCommand{
+			Command:     "listfilters",
+			Description: "List message filters and their hit counts",
+		})
 	}
-	if message.ConnectedWebsite != "" {
-		return true
+	if s.routingRuleRepo != nil {
+		commands = append(commands, gotgbot.BotCommand{
+			Command:     "addroute",
+			Description: "Route guest messages to a recipient by keyword, media type or language",
+		})
+		commands = append(commands, gotgbot.BotCommand{
+			Command:     "delroute",
+			Description: "Remove a route",
+		})
+		commands = append(commands, gotgbot.BotCommand{
+			Command:     "listroutes",
+			Description: "List routes by recipient",
+		})
 	}
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "broadcast",
+		Description: "Send an announcement to every guest",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "settimezone",
+		Description: "Set the timezone for times shown by this bot",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "groupguests",
+		Description: "Accept members of unregistered groups as guests (Manager only)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "autoleave",
+		Description: "Leave groups added by strangers (Manager only)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "paywall",
+		Description: "Show or set the Telegram Stars paywall (Manager only)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "refund",
+		Description: "Refund a guest's Stars payment (Manager only)",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "credits",
+		Description: "Show your message credits and buy more",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "report",
+		Description: "Report abuse of this bot to the instance administrators",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "language",
+		Description: "Choose the language the bots use with you",
+	})
 
-	return false
+	return commands
 }
 
+// adMentionPattern matches @username in text without entities: @ followed by
+// the characters Telegram usernames may contain
+var adMentionPattern = regexp.MustCompile(`@[a-zA-Z0-9_]{1,32}(?:\.[a-zA-Z0-9_]{1,32})*`)
+
+// adURLPattern matches http:// or https:// followed by valid URL characters,
+// excluding common delimiters and whitespace
+var adURLPattern = regexp.MustCompile(`https?://[^\s<>"{}|\\^\[\]]+`)
+
 // containsAdContent checks if a message contains ad content (mentions, URLs, buttons, or via bot)
 // Checks both Entities (for text messages) and CaptionEntities (for media messages)
 // Also checks for ReplyMarkup (inline keyboard buttons or reply keyboard) and ViaBot
 // Also checks Quote.Text if ExternalReply is present (for quoted parts of external replied messages)
+// and imageText, the text read from the message's image if any
 // Returns true if the message contains mentions, URLs, buttons, or via bot, and a reason string
-func (s *Service) containsAdContent(message *gotgbot.Message) (bool, string) {
+func (s *Service) containsAdContent(message *gotgbot.Message, imageText string) (bool, string) {
 	var hasMention bool
 	var hasLink bool
 	var hasButton bool
@@ -381,25 +778,27 @@ func (s *Service) containsAdContent(message *gotgbot.Message) (bool, string) {
 			// Use regex to match @username pattern (e.g., @username, @user_name, @user123)
 			// Pattern: @ followed by alphanumeric characters, underscores, and dots (Telegram username rules)
 			// This avoids matching email addresses like user@example.com
-			if !hasMention {
-				mentionPattern := regexp.MustCompile(`@[a-zA-Z0-9_]{1,32}(?:\.[a-zA-Z0-9_]{1,32})*`)
-				if mentionPattern.MatchString(quoteText) {
-					hasMention = true
-				}
+			if !hasMention && adMentionPattern.MatchString(quoteText) {
+				hasMention = true
 			}
 
 			// Use regex to match URLs (http:// or https:// followed by valid URL characters)
-			// Pattern matches http:// or https:// followed by valid URL characters
-			// Excludes common delimiters and whitespace
-			if !hasLink {
-				urlPattern := regexp.MustCompile(`https?://[^\s<>"{}|\\^\[\]]+`)
-				if urlPattern.MatchString(quoteText) {
-					hasLink = true
-				}
+			if !hasLink && adURLPattern.MatchString(quoteText) {
+				hasLink = true
 			}
 		}
 	}
 
+	// Text read from images has no entities either, so check it like quotes
+	if imageText != "" {
+		if !hasMention && adMentionPattern.MatchString(imageText) {
+			hasMention = true
+		}
+		if !hasLink && adURLPattern.MatchString(imageText) {
+			hasLink = true
+		}
+	}
+
 	if !hasMention && !hasLink && !hasButton && !hasViaBot {
 		return false, ""
 	}
@@ -422,35 +821,57 @@ func (s *Service) containsAdContent(message *gotgbot.Message) (bool, string) {
 	return true, reasonStr
 }
 
+// adFilterNoticeText tells a guest in their language why the ad filter blocked
+// their message. reason is the " or "-joined list from containsAdContent.
+func adFilterNoticeText(lang string, reason string) string {
+	if reason == "via bot" {
+		return i18n.T(lang, "guest.filtered.via_bot")
+	}
+	var reasons []string
+	for _, r := range strings.Split(reason, " or ") {
+		reasons = append(reasons, i18n.T(lang, "guest.filtered.reason."+strings.ReplaceAll(r, " ", "_")))
+	}
+	return i18n.T(lang, "guest.filtered", strings.Join(reasons, i18n.T(lang, "list.separator")))
+}
+
 func (s *Service) HandleMessage(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	ctx = withPermissionCache(ctx)
 	message := update.EffectiveMessage
 	chatID := update.EffectiveChat.Id
-	userID := update.EffectiveUser.Id
 	messageID := message.MessageId
 
-	// Update commands menu for user (only for private chats)
-	if update.EffectiveChat.Type == "private" {
-		s.updateCommands(ctx, b)
+	// Service messages (members joining, pins, video chats, etc.) cannot be forwarded.
+	// Payment messages are service messages too, but update the guest's paywall credits
+	if message.SuccessfulPayment != nil || message.RefundedPayment != nil {
+		return s.handlePaymentMessage(ctx, b, update)
+	}
+	if kind := classifyServiceMessage(message); kind != serviceMessageNone {
+		return s.handleServiceMessage(ctx, b, update, kind)
 	}
 
-	s.logger.Debug("ForwarderBot message received",
-		zap.String("bot_id", s.botID.String()),
+	if s.fromForwarderBot(message) {
+		return nil
+	}
+
+	userID := update.EffectiveUser.Id
+	lang := s.language(ctx, update.EffectiveUser)
+
+	// Update commands menu for user (only for private chats)
+	if update.EffectiveChat.Type == "private" {
+		s.updateCommands(ctx, b)
+		if isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID); isManagerOrAdmin {
+			s.updateStaffCommands(b, userID)
+		}
+	}
+
+	s.logger.Debug("ForwarderBot message received",
+		zap.String("bot_id", s.botID.String()),
 		zap.Int64("message_id", messageID),
 		zap.Int64("user_id", userID),
 		zap.Int64("chat_id", chatID),
 		zap.String("text", message.Text),
 		zap.Bool("is_reply", message.ReplyToMessage != nil))
 
-	// Check if message is a system message (e.g., user joined/left, chat title changed, etc.)
-	// System messages cannot be forwarded and should be ignored
-	if s.isSystemMessage(message) {
-		s.logger.Debug("Message is a system message, ignoring",
-			zap.String("bot_id", s.botID.String()),
-			zap.Int64("message_id", messageID),
-			zap.Int64("chat_id", chatID))
-		return nil
-	}
-
 	// Check if message is a command
 	if message.Text != "" && strings.HasPrefix(message.Text, "/") {
 		s.logger.Debug("Message is a command, delegating to HandleCommand",
@@ -460,8 +881,25 @@ func (s *Service) HandleMessage(ctx context.Context, b *gotgbot.Bot, update *ext
 		return s.HandleCommand(ctx, b, update)
 	}
 
-	// Check if message is a reply
-	if message.ReplyToMessage != nil {
+	// Only private chats are guest traffic unless the manager allows group guests;
+	// recipient groups are handled by HandleReply
+	groupBot, err := s.guestGroup(update.EffectiveChat)
+	if err != nil {
+		s.logger.Warn("Failed to check group authorization", zap.Error(err))
+		return err
+	}
+	if groupBot != nil && !groupBot.AllowGroupGuests {
+		s.logger.Debug("Message from an unregistered group, ignoring",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("message_id", messageID),
+			zap.Int64("chat_id", chatID))
+		s.notifyUnauthorizedGroup(b, groupBot, update.EffectiveChat)
+		return nil
+	}
+
+	// Check if message is a reply. Members of a guest group also reply to each
+	// other; only replies to the bot continue a conversation with recipients.
+	if message.ReplyToMessage != nil && (groupBot == nil || isReplyToBot(b, message)) {
 		s.logger.Debug("Message is a reply, delegating to HandleReply",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("message_id", messageID),
@@ -488,9 +926,36 @@ func (s *Service) HandleMessage(ctx context.Context, b *gotgbot.Bot, update *ext
 		zap.Int64("user_id", userID),
 		zap.Int64("message_id", messageID))
 
+	if s.muteIfFlooding(ctx, b, chatID, userID, lang) {
+		return nil
+	}
+
+	if update.EffectiveChat.Type == "private" {
+		if isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID); !isManagerOrAdmin {
+			s.welcomeNewGuest(b, update)
+		}
+	}
+
+	// Check ToS acceptance before forwarding
+	accepted, err := s.checkTermsAccepted(b, update, lang)
+	if err != nil {
+		s.logger.Warn("Failed to check terms acceptance", zap.Error(err))
+		return err
+	}
+	if !accepted {
+		s.logger.Debug("Guest has not accepted terms, message not forwarded",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Int64("message_id", messageID))
+		return nil
+	}
+
+	// Text in screenshots is checked by the ad filter and alert keywords like message text
+	imageText := s.readImageText(ctx, b, message)
+
 	// Check for ad content if ad filter is enabled
 	if s.config.AdFilter.Enabled {
-		hasAd, reason := s.containsAdContent(message)
+		hasAd, reason := s.containsAdContent(message, imageText)
 		if hasAd {
 			s.logger.Debug("Message contains ad content, blocking",
 				zap.String("bot_id", s.botID.String()),
@@ -499,23 +964,7 @@ func (s *Service) HandleMessage(ctx context.Context, b *gotgbot.Bot, update *ext
 				zap.String("reason", reason))
 
 			// Notify guest about blocked message
-			var notificationText string
-			switch reason {
-			case "mention":
-				notificationText = "Your message was not forwarded because it contains a mention (@username)."
-			case "link":
-				notificationText = "Your message was not forwarded because it contains a link (http/https)."
-			case "button":
-				notificationText = "Your message was not forwarded because it contains buttons."
-			case "via bot":
-				notificationText = "Your message was not forwarded because it was sent via another bot."
-			default:
-				// Handle combinations: replace " or " with ", " for better readability
-				reasonDisplay := strings.ReplaceAll(reason, " or ", ", ")
-				notificationText = fmt.Sprintf("Your message was not forwarded because it contains %s.", reasonDisplay)
-			}
-
-			_, err := b.SendMessage(chatID, notificationText, nil)
+			_, err := b.SendMessage(chatID, adFilterNoticeText(lang, reason), nil)
 			if err != nil {
 				s.logger.Warn("Failed to send ad filter notification",
 					zap.String("bot_id", s.botID.String()),
@@ -527,12 +976,59 @@ func (s *Service) HandleMessage(ctx context.Context, b *gotgbot.Bot, update *ext
 		}
 	}
 
+	// The bot's own filter rules run after the built-in checks
+	if s.applyFilterRules(b, message, update.EffectiveUser, imageText) {
+		return nil
+	}
+
+	if s.scanGuestFile(ctx, b, update, lang) {
+		return nil
+	}
+
+	// Answer common questions right away; FAQ-only rules end here
+	if s.applyAutoReply(b, chatID, userID, message) {
+		s.logger.Debug("Message answered by auto-reply, not forwarded",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Int64("message_id", messageID))
+		return nil
+	}
+
+	paywall, err := s.checkPaywall(ctx, b, update)
+	if err != nil {
+		s.logger.Warn("Failed to check paywall", zap.Error(err))
+		return err
+	}
+	if !paywall.Forward {
+		s.logger.Debug("Guest has not paid, message not forwarded",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Int64("message_id", messageID))
+		return nil
+	}
+	delivered := false
+	if paywall.Paid {
+		defer func() {
+			if !delivered {
+				s.refundPaywallCredit(userID)
+			}
+		}()
+	}
+
 	// Forward message to all recipients
 	s.logger.Debug("Forwarding message to recipients",
 		zap.String("bot_id", s.botID.String()),
 		zap.Int64("message_id", messageID),
 		zap.Int64("guest_chat_id", chatID))
 	result, err := s.messageForwarder.ForwardToRecipients(ctx, b, s.botID, chatID, message)
+	if throttled, ok := asGuestThrottled(err); ok {
+		s.notifyGuestThrottled(b, chatID, userID, lang, throttled)
+		return nil
+	}
+	if isQuotaExceeded(err) {
+		s.sendGuestFailureNotice(b, chatID, userID, lang, message.MessageId)
+		return nil
+	}
 	if err != nil {
 		s.logger.Error("Failed to forward message", zap.Error(err))
 		return err
@@ -552,10 +1048,29 @@ func (s *Service) HandleMessage(ctx context.Context, b *gotgbot.Bot, update *ext
 			zap.Int("failures", result.FailureCount))
 	}
 
+	delivered = result.SuccessCount > 0
+	if delivered && paywall.Priority {
+		s.markPriorityMessage(b, chatID, messageID)
+	}
+	if delivered {
+		s.recordWelcomeConversion(userID)
+		s.raiseKeywordAlert(b, chatID, userID, message, imageText)
+		s.recordConversation(chatID, llm.RoleGuest, message)
+		s.attachTranscription(ctx, b, chatID, message)
+		s.attachImageText(b, chatID, message, imageText)
+	}
+	// Outside office hours the away message replaces the queue notice
+	if result.WaitStarted && !s.sendAwayMessage(b, chatID, userID) {
+		s.sendQueueNotice(b, chatID, userID, lang)
+	}
+
+	s.notifyGuestOnTotalFailure(b, chatID, userID, lang, messageID, result)
+
 	return nil
 }
 
 func (s *Service) HandleReply(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	ctx = withPermissionCache(ctx)
 	replyMessage := update.EffectiveMessage
 	chatID := update.EffectiveChat.Id
 	messageID := replyMessage.MessageId
@@ -582,6 +1097,10 @@ func (s *Service) HandleReply(ctx context.Context, b *gotgbot.Bot, update *ext.C
 			zap.Int64("message_id", messageID),
 			zap.Int64("recipient_chat_id", chatID))
 		err = s.messageForwarder.ForwardReplyToGuest(ctx, b, s.botID, chatID, replyMessage)
+		if isQuotaExceeded(err) {
+			_, err := b.SendMessage(chatID, quotaExceededReplyText, nil)
+			return err
+		}
 		if err != nil {
 			s.logger.Debug("Failed to forward reply to guest",
 				zap.String("bot_id", s.botID.String()),
@@ -591,6 +1110,7 @@ func (s *Service) HandleReply(ctx context.Context, b *gotgbot.Bot, update *ext.C
 			s.logger.Debug("Reply forwarded to guest successfully",
 				zap.String("bot_id", s.botID.String()),
 				zap.Int64("message_id", messageID))
+			s.recordStaffReply(chatID, replyMessage)
 		}
 		return err
 	}
@@ -604,6 +1124,7 @@ func (s *Service) HandleReply(ctx context.Context, b *gotgbot.Bot, update *ext.C
 
 	// Check if user is blacklisted
 	userID := update.EffectiveUser.Id
+	lang := s.language(ctx, update.EffectiveUser)
 	isBlacklisted, err := s.blacklistService.IsBlacklisted(s.botID, userID)
 	if err != nil {
 		s.logger.Warn("Failed to check blacklist", zap.Error(err))
@@ -615,6 +1136,46 @@ func (s *Service) HandleReply(ctx context.Context, b *gotgbot.Bot, update *ext.C
 		return nil
 	}
 
+	if s.muteIfFlooding(ctx, b, chatID, userID, lang) {
+		return nil
+	}
+
+	accepted, err := s.checkTermsAccepted(b, update, lang)
+	if err != nil {
+		s.logger.Warn("Failed to check terms acceptance", zap.Error(err))
+		return err
+	}
+	if !accepted {
+		return nil
+	}
+
+	// Replies are checked against the bot's filter rules like other messages
+	imageText := s.readImageText(ctx, b, replyMessage)
+	if s.applyFilterRules(b, replyMessage, update.EffectiveUser, imageText) {
+		return nil
+	}
+
+	if s.scanGuestFile(ctx, b, update, lang) {
+		return nil
+	}
+
+	paywall, err := s.checkPaywall(ctx, b, update)
+	if err != nil {
+		s.logger.Warn("Failed to check paywall", zap.Error(err))
+		return err
+	}
+	if !paywall.Forward {
+		return nil
+	}
+	delivered := false
+	if paywall.Paid {
+		defer func() {
+			if !delivered {
+				s.refundPaywallCredit(userID)
+			}
+		}()
+	}
+
 	// Find all message mappings for the replied message
 	mappings, err := s.messageMappingRepo.GetAllByGuestMessage(s.botID, chatID, replyToMessageID)
 	if err != nil {
@@ -641,6 +1202,15 @@ func (s *Service) HandleReply(ctx context.Context, b *gotgbot.Bot, update *ext.C
 		zap.Int64("reply_to_message_id", replyToMessageID),
 		zap.Int("mapping_count", len(mappings)))
 
+	// One guest reply counts once against the quota, however many recipients
+	// it goes to. Dry-run replies are only logged, so they do not count.
+	if !s.messageForwarder.IsDryRun(s.botID) {
+		if err := s.messageForwarder.ConsumeQuota(ctx, s.botID); err != nil {
+			s.sendGuestFailureNotice(b, chatID, userID, lang, messageID)
+			return nil
+		}
+	}
+
 	// Forward reply to all corresponding recipients
 	for _, mapping := range mappings {
 		s.logger.Debug("Forwarding guest reply to recipient",
@@ -653,7 +1223,7 @@ func (s *Service) HandleReply(ctx context.Context, b *gotgbot.Bot, update *ext.C
 			b,
 			s.botID,
 			chatID,
-			messageID,
+			replyMessage,
 			replyToMessageID,
 			mapping.RecipientChatID,
 		)
@@ -664,16 +1234,25 @@ func (s *Service) HandleReply(ctx context.Context, b *gotgbot.Bot, update *ext.C
 				zap.Int64("recipient_chat_id", mapping.RecipientChatID),
 				zap.Error(err))
 		} else {
+			delivered = true
 			s.logger.Debug("Guest reply forwarded to recipient successfully",
 				zap.String("bot_id", s.botID.String()),
 				zap.Int64("recipient_chat_id", mapping.RecipientChatID))
 		}
 	}
 
+	if delivered {
+		s.raiseKeywordAlert(b, chatID, userID, replyMessage, imageText)
+		s.recordConversation(chatID, llm.RoleGuest, replyMessage)
+		s.attachTranscription(ctx, b, chatID, replyMessage)
+		s.attachImageText(b, chatID, replyMessage, imageText)
+	}
+
 	return nil
 }
 
 func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	ctx = withPermissionCache(ctx)
 	command := update.EffectiveMessage.Text
 	if command == "" {
 		return nil
@@ -693,7 +1272,30 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 		zap.Int64("chat_id", chatID),
 		zap.String("command", command))
 
+	if s.lockedOut(b, update) {
+		return nil
+	}
+
 	switch {
+	case strings.HasPrefix(command, "/start"):
+		s.logger.Debug("Handling /start command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		if payload := startPayload(command); strings.HasPrefix(payload, adminInvitePayloadPrefix) {
+			return s.handleAdminInviteStart(ctx, b, update, strings.TrimPrefix(payload, adminInvitePayloadPrefix))
+		}
+		if update.EffectiveChat.Type == "private" {
+			if isManagerOrAdmin, _ := s.IsManagerOrAdmin(ctx, userID); !isManagerOrAdmin {
+				sent, err := s.sendWelcome(b, update)
+				if err != nil {
+					s.logger.Warn("Failed to send welcome message", zap.Error(err))
+				}
+				if sent {
+					return err
+				}
+			}
+		}
+		return s.handleHelp(ctx, b, update)
 	case strings.HasPrefix(command, "/help"):
 		s.logger.Debug("Handling /help command",
 			zap.String("bot_id", s.botID.String()),
@@ -703,12 +1305,13 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 		s.logger.Debug("Handling /addrecipient command",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("user_id", userID))
-		isManagerOrAdmin, err := s.IsManagerOrAdmin(userID)
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
 		if err != nil || !isManagerOrAdmin {
 			s.logger.Debug("Access denied for /addrecipient",
 				zap.String("bot_id", s.botID.String()),
 				zap.Int64("user_id", userID),
 				zap.Bool("is_manager_or_admin", isManagerOrAdmin))
+			s.accessDenied(ctx, update, "/addrecipient")
 			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
 			return err
 		}
@@ -717,11 +1320,12 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 		s.logger.Debug("Handling /delrecipient command",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("user_id", userID))
-		isManagerOrAdmin, err := s.IsManagerOrAdmin(userID)
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
 		if err != nil || !isManagerOrAdmin {
 			s.logger.Debug("Access denied for /delrecipient",
 				zap.String("bot_id", s.botID.String()),
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/delrecipient")
 			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
 			return err
 		}
@@ -730,37 +1334,82 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 		s.logger.Debug("Handling /listrecipient command",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("user_id", userID))
-		isManagerOrAdmin, err := s.IsManagerOrAdmin(userID)
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
 		if err != nil || !isManagerOrAdmin {
 			s.logger.Debug("Access denied for /listrecipient",
 				zap.String("bot_id", s.botID.String()),
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/listrecipient")
 			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
 			return err
 		}
 		return s.handleListRecipient(ctx, b, update)
+	case strings.HasPrefix(command, "/setalias"):
+		s.logger.Debug("Handling /setalias command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /setalias",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/setalias")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetAlias(ctx, b, update)
+	case strings.HasPrefix(command, "/setfallback"):
+		s.logger.Debug("Handling /setfallback command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /setfallback",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/setfallback")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetFallback(ctx, b, update)
 	case strings.HasPrefix(command, "/addadmin"):
 		s.logger.Debug("Handling /addadmin command",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("user_id", userID))
-		isManager, err := s.IsManager(userID)
+		isManager, err := s.IsManager(ctx, userID)
 		if err != nil || !isManager {
 			s.logger.Debug("Access denied for /addadmin - not manager",
 				zap.String("bot_id", s.botID.String()),
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/addadmin")
 			_, err := b.SendMessage(update.EffectiveChat.Id, "Only the manager can use this command.", nil)
 			return err
 		}
 		return s.handleAddAdmin(ctx, b, update)
+	case strings.HasPrefix(command, "/inviteadmin"):
+		s.logger.Debug("Handling /inviteadmin command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManager, err := s.IsManager(ctx, userID)
+		if err != nil || !isManager {
+			s.logger.Debug("Access denied for /inviteadmin - not manager",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/inviteadmin")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "Only the manager can use this command.", nil)
+			return err
+		}
+		return s.handleInviteAdmin(ctx, b, update)
 	case strings.HasPrefix(command, "/deladmin"):
 		s.logger.Debug("Handling /deladmin command",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("user_id", userID))
-		isManager, err := s.IsManager(userID)
+		isManager, err := s.IsManager(ctx, userID)
 		if err != nil || !isManager {
 			s.logger.Debug("Access denied for /deladmin - not manager",
 				zap.String("bot_id", s.botID.String()),
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/deladmin")
 			_, err := b.SendMessage(update.EffectiveChat.Id, "Only the manager can use this command.", nil)
 			return err
 		}
@@ -769,11 +1418,12 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 		s.logger.Debug("Handling /listadmins command",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("user_id", userID))
-		isManagerOrAdmin, err := s.IsManagerOrAdmin(userID)
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
 		if err != nil || !isManagerOrAdmin {
 			s.logger.Debug("Access denied for /listadmins",
 				zap.String("bot_id", s.botID.String()),
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/listadmins")
 			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
 			return err
 		}
@@ -782,25 +1432,374 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 		s.logger.Debug("Handling /stats command",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("user_id", userID))
-		isManagerOrAdmin, err := s.IsManagerOrAdmin(userID)
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
 		if err != nil || !isManagerOrAdmin {
 			s.logger.Debug("Access denied for /stats",
 				zap.String("bot_id", s.botID.String()),
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/stats")
 			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
 			return err
 		}
 		return s.handleStats(ctx, b, update)
+	case strings.HasPrefix(command, "/baninfo"):
+		s.logger.Debug("Handling /baninfo command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		return s.handleBanInfo(ctx, b, update)
 	case strings.HasPrefix(command, "/ban"):
 		s.logger.Debug("Handling /ban command",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("user_id", userID))
 		return s.handleBan(ctx, b, update)
+	case strings.HasPrefix(command, "/addfilter"):
+		s.logger.Debug("Handling /addfilter command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /addfilter",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/addfilter")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleAddFilter(ctx, b, update)
+	case strings.HasPrefix(command, "/delfilter"):
+		s.logger.Debug("Handling /delfilter command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /delfilter",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/delfilter")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleDelFilter(ctx, b, update)
+	case strings.HasPrefix(command, "/listfilters"):
+		s.logger.Debug("Handling /listfilters command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /listfilters",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/listfilters")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleListFilters(ctx, b, update)
+	case strings.HasPrefix(command, "/addroute"):
+		s.logger.Debug("Handling /addroute command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /addroute",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/addroute")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleAddRoute(ctx, b, update)
+	case strings.HasPrefix(command, "/delroute"):
+		s.logger.Debug("Handling /delroute command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /delroute",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/delroute")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleDelRoute(ctx, b, update)
+	case strings.HasPrefix(command, "/listroutes"):
+		s.logger.Debug("Handling /listroutes command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /listroutes",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/listroutes")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleListRoutes(ctx, b, update)
+	case strings.HasPrefix(command, "/broadcast"):
+		s.logger.Debug("Handling /broadcast command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /broadcast",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/broadcast")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleBroadcast(ctx, b, update)
+	case strings.HasPrefix(command, "/del"):
+		s.logger.Debug("Handling /del command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		return s.handleDelete(ctx, b, update)
+	case strings.HasPrefix(command, "/suggest"):
+		s.logger.Debug("Handling /suggest command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		return s.handleSuggest(ctx, b, update)
 	case strings.HasPrefix(command, "/unban"):
 		s.logger.Debug("Handling /unban command",
 			zap.String("bot_id", s.botID.String()),
 			zap.Int64("user_id", userID))
 		return s.handleUnban(ctx, b, update)
+	case strings.HasPrefix(command, "/setratelimit"):
+		s.logger.Debug("Handling /setratelimit command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /setratelimit",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/setratelimit")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetRateLimit(ctx, b, update)
+	case strings.HasPrefix(command, "/setprivacyheader"):
+		s.logger.Debug("Handling /setprivacyheader command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /setprivacyheader",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/setprivacyheader")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetPrivacyHeader(ctx, b, update)
+	case strings.HasPrefix(command, "/sethelp"):
+		s.logger.Debug("Handling /sethelp command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /sethelp",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/sethelp")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetGuestHelp(ctx, b, update)
+	case strings.HasPrefix(command, "/setterms"):
+		s.logger.Debug("Handling /setterms command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /setterms",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/setterms")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetTerms(ctx, b, update)
+	case strings.HasPrefix(command, "/terms"):
+		s.logger.Debug("Handling /terms command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		return s.handleTerms(ctx, b, update)
+	case strings.HasPrefix(command, "/setwelcome"):
+		s.logger.Debug("Handling /setwelcome command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /setwelcome",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/setwelcome")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetWelcome(ctx, b, update)
+	case strings.HasPrefix(command, "/sethours"):
+		s.logger.Debug("Handling /sethours command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /sethours",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/sethours")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetOfficeHours(ctx, b, update)
+	case strings.HasPrefix(command, "/setaway"):
+		s.logger.Debug("Handling /setaway command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /setaway",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/setaway")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetAwayMessage(ctx, b, update)
+	case strings.HasPrefix(command, "/autoreply"):
+		s.logger.Debug("Handling /autoreply command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /autoreply",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/autoreply")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleAutoReply(ctx, b, update)
+	case strings.HasPrefix(command, "/setalerts"):
+		s.logger.Debug("Handling /setalerts command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /setalerts",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/setalerts")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetAlerts(ctx, b, update)
+	case strings.HasPrefix(command, "/alerts"):
+		s.logger.Debug("Handling /alerts command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /alerts",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/alerts")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleAlerts(ctx, b, update)
+	case strings.HasPrefix(command, "/settimezone"):
+		s.logger.Debug("Handling /settimezone command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+		if err != nil || !isManagerOrAdmin {
+			s.logger.Debug("Access denied for /settimezone",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/settimezone")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		return s.handleSetTimezone(ctx, b, update)
+	case strings.HasPrefix(command, "/groupguests"):
+		s.logger.Debug("Handling /groupguests command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManager, err := s.IsManager(ctx, userID)
+		if err != nil || !isManager {
+			s.logger.Debug("Access denied for /groupguests - not manager",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/groupguests")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "Only the manager can use this command.", nil)
+			return err
+		}
+		return s.handleGroupGuests(ctx, b, update)
+	case strings.HasPrefix(command, "/autoleave"):
+		s.logger.Debug("Handling /autoleave command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManager, err := s.IsManager(ctx, userID)
+		if err != nil || !isManager {
+			s.logger.Debug("Access denied for /autoleave - not manager",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/autoleave")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "Only the manager can use this command.", nil)
+			return err
+		}
+		return s.handleAutoLeave(ctx, b, update)
+	case strings.HasPrefix(command, "/paywall"):
+		s.logger.Debug("Handling /paywall command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManager, err := s.IsManager(ctx, userID)
+		if err != nil || !isManager {
+			s.logger.Debug("Access denied for /paywall - not manager",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/paywall")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "Only the manager can use this command.", nil)
+			return err
+		}
+		return s.handlePaywall(ctx, b, update)
+	case strings.HasPrefix(command, "/refund"):
+		s.logger.Debug("Handling /refund command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		isManager, err := s.IsManager(ctx, userID)
+		if err != nil || !isManager {
+			s.logger.Debug("Access denied for /refund - not manager",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/refund")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "Only the manager can use this command.", nil)
+			return err
+		}
+		return s.handleRefund(ctx, b, update)
+	case strings.HasPrefix(command, "/credits"):
+		s.logger.Debug("Handling /credits command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		return s.handleCredits(ctx, b, update)
+	case strings.HasPrefix(command, "/report"):
+		s.logger.Debug("Handling /report command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		return s.handleReport(ctx, b, update)
+	case strings.HasPrefix(command, "/later"):
+		s.logger.Debug("Handling /later command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		return s.handleLater(ctx, b, update)
+	case strings.HasPrefix(command, "/language"):
+		s.logger.Debug("Handling /language command",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+		return s.handleLanguage(ctx, b, update)
 	default:
 		s.logger.Debug("Unknown command received",
 			zap.Int64("user_id", userID),
@@ -812,6 +1811,7 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 }
 
 func (s *Service) HandleCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	ctx = withPermissionCache(ctx)
 	userID := update.EffectiveUser.Id
 	data := update.CallbackQuery.Data
 	parts := strings.Split(data, ":")
@@ -832,6 +1832,10 @@ func (s *Service) HandleCallback(ctx context.Context, b *gotgbot.Bot, update *ex
 		return fmt.Errorf("invalid callback data: %s", data)
 	}
 
+	if s.lockedOut(b, update) {
+		return nil
+	}
+
 	action := parts[0]
 	s.logger.Debug("Processing callback action",
 		zap.String("bot_id", s.botID.String()),
@@ -846,6 +1850,42 @@ func (s *Service) HandleCallback(ctx context.Context, b *gotgbot.Bot, update *ex
 			zap.Int64("user_id", userID),
 			zap.Strings("sub_parts", parts[1:]))
 		err = s.handleBlacklistCallback(ctx, b, update, parts[1:])
+	case "admininvite":
+		s.logger.Debug("Handling admin invite callback",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleAdminInviteCallback(ctx, b, update, parts[1:])
+	case "terms":
+		s.logger.Debug("Handling terms callback",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleTermsCallback(ctx, b, update, parts[1:])
+	case "filter":
+		s.logger.Debug("Handling filter callback",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleFilterCallback(ctx, b, update, parts[1:])
+	case "suggest":
+		s.logger.Debug("Handling suggest callback",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleSuggestCallback(ctx, b, update, parts[1:])
+	case "broadcast":
+		s.logger.Debug("Handling broadcast callback",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleBroadcastCallback(ctx, b, update, parts[1:])
+	case "language":
+		s.logger.Debug("Handling language callback",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleLanguageCallback(ctx, b, update, parts[1:])
 	default:
 		s.logger.Debug("Unknown callback action",
 			zap.String("bot_id", s.botID.String()),
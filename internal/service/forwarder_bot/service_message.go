@@ -0,0 +1,208 @@
+package forwarder_bot
+
+import (
+	"context"
+	"encoding/json"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// serviceMessageKind classifies messages Telegram generates for chat events.
+// None of them can be forwarded; most are dropped, a few are acted on.
+type serviceMessageKind string
+
+const (
+	serviceMessageNone         serviceMessageKind = ""              // Regular message
+	serviceMessageIgnored      serviceMessageKind = "ignored"       // Dropped without side effects
+	serviceMessageMemberJoined serviceMessageKind = "member_joined" // Monitored in recipient groups
+	serviceMessageMemberLeft   serviceMessageKind = "member_left"   // Monitored in recipient groups
+	serviceMessageChatMigrated serviceMessageKind = "chat_migrated" // Recipient group upgraded to a supergroup
+)
+
+// classifyServiceMessage reports which kind of service message msg is, or
+// serviceMessageNone for regular messages. Payment messages are handled
+// separately before classification.
+func classifyServiceMessage(msg *gotgbot.Message) serviceMessageKind {
+	switch {
+	case len(msg.NewChatMembers) > 0:
+		return serviceMessageMemberJoined
+	case msg.LeftChatMember != nil:
+		return serviceMessageMemberLeft
+	case msg.MigrateToChatId != 0:
+		return serviceMessageChatMigrated
+	}
+
+	// Messages without a sender are generated by Telegram
+	if msg.From == nil {
+		return serviceMessageIgnored
+	}
+
+	switch {
+	case msg.NewChatTitle != "",
+		len(msg.NewChatPhoto) > 0,
+		msg.DeleteChatPhoto,
+		msg.GroupChatCreated,
+		msg.SupergroupChatCreated,
+		msg.ChannelChatCreated,
+		msg.MigrateFromChatId != 0,
+		msg.MessageAutoDeleteTimerChanged != nil,
+		msg.PinnedMessage != nil,
+		msg.UsersShared != nil,
+		msg.ChatShared != nil,
+		msg.Gift != nil,
+		msg.UniqueGift != nil,
+		msg.ConnectedWebsite != "",
+		msg.WriteAccessAllowed != nil,
+		msg.ProximityAlertTriggered != nil,
+		msg.BoostAdded != nil,
+		msg.ChatBackgroundSet != nil,
+		msg.ChecklistTasksDone != nil,
+		msg.ChecklistTasksAdded != nil,
+		msg.DirectMessagePriceChanged != nil,
+		msg.PaidMessagePriceChanged != nil,
+		msg.ForumTopicCreated != nil,
+		msg.ForumTopicEdited != nil,
+		msg.ForumTopicClosed != nil,
+		msg.ForumTopicReopened != nil,
+		msg.GeneralForumTopicHidden != nil,
+		msg.GeneralForumTopicUnhidden != nil,
+		msg.GiveawayCreated != nil,
+		msg.GiveawayWinners != nil,
+		msg.GiveawayCompleted != nil,
+		msg.VideoChatScheduled != nil,
+		msg.VideoChatStarted != nil,
+		msg.VideoChatEnded != nil,
+		msg.VideoChatParticipantsInvited != nil,
+		msg.WebAppData != nil:
+		return serviceMessageIgnored
+	}
+
+	return serviceMessageNone
+}
+
+// handleServiceMessage acts on the bot joining a group and on service messages
+// from recipient groups, and drops everything else
+func (s *Service) handleServiceMessage(ctx context.Context, b *gotgbot.Bot, update *ext.Context, kind serviceMessageKind) error {
+	msg := update.EffectiveMessage
+	chatID := msg.Chat.Id
+
+	// Joining a group is checked first, since a group created with the bot
+	// in it is otherwise an ignored service message
+	if msg.Chat.Type != "private" && isBotAdded(b, msg) {
+		return s.handleBotAdded(ctx, b, msg)
+	}
+
+	if kind == serviceMessageIgnored {
+		s.logger.Debug("Service message ignored",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("message_id", msg.MessageId),
+			zap.Int64("chat_id", chatID))
+		return nil
+	}
+
+	recipient, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, chatID)
+	if err != nil {
+		s.logger.Debug("Service message from a non-recipient chat, ignoring",
+			zap.String("bot_id", s.botID.String()),
+			zap.String("kind", string(kind)),
+			zap.Int64("chat_id", chatID))
+		return nil
+	}
+
+	switch kind {
+	case serviceMessageMemberJoined, serviceMessageMemberLeft:
+		s.recordRecipientMembers(b, recipient, msg, kind)
+	case serviceMessageChatMigrated:
+		return s.migrateRecipientChat(recipient, msg.MigrateToChatId)
+	}
+	return nil
+}
+
+// recordRecipientMembers logs and audits members joining or leaving a recipient group
+func (s *Service) recordRecipientMembers(b *gotgbot.Bot, recipient *models.Recipient, msg *gotgbot.Message, kind serviceMessageKind) {
+	var members []gotgbot.User
+	if kind == serviceMessageMemberJoined {
+		members = msg.NewChatMembers
+	} else {
+		members = []gotgbot.User{*msg.LeftChatMember}
+	}
+
+	userIDs := make([]int64, 0, len(members))
+	botRemoved := false
+	for _, member := range members {
+		userIDs = append(userIDs, member.Id)
+		if kind == serviceMessageMemberLeft && member.Id == b.Id {
+			botRemoved = true
+		}
+	}
+
+	if botRemoved {
+		s.logger.Warn("Bot was removed from a recipient group",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", recipient.ChatID))
+	} else {
+		s.logger.Info("Recipient group membership changed",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", recipient.ChatID),
+			zap.String("kind", string(kind)),
+			zap.Int64s("user_ids", userIDs))
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"chat_id":     recipient.ChatID,
+		"event":       string(kind),
+		"user_ids":    userIDs,
+		"bot_removed": botRemoved,
+	})
+	auditLog := &models.AuditLog{
+		ActionType:   models.AuditLogActionRecipientMembers,
+		ResourceType: "recipient",
+		ResourceID:   recipient.ID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+}
+
+// migrateRecipientChat follows a recipient group that was upgraded to a supergroup,
+// which gives it a new chat ID
+func (s *Service) migrateRecipientChat(recipient *models.Recipient, newChatID int64) error {
+	if _, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, newChatID); err == nil {
+		s.logger.Debug("Migrated recipient chat already registered",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("chat_id", newChatID))
+		return nil
+	}
+
+	oldChatID := recipient.ChatID
+	recipient.ChatID = newChatID
+	if err := s.recipientRepo.Update(recipient); err != nil {
+		s.logger.Error("Failed to migrate recipient chat",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("old_chat_id", oldChatID),
+			zap.Int64("new_chat_id", newChatID),
+			zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Recipient group migrated to supergroup",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("old_chat_id", oldChatID),
+		zap.Int64("new_chat_id", newChatID))
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"old_chat_id": oldChatID,
+		"new_chat_id": newChatID,
+	})
+	auditLog := &models.AuditLog{
+		ActionType:   models.AuditLogActionMigrateRecipient,
+		ResourceType: "recipient",
+		ResourceID:   recipient.ID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+	return nil
+}
@@ -0,0 +1,128 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/service/statistics"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+var statsRangeUsage = fmt.Sprintf("Usage: /stats [range]\n\n"+
+	"The range is a number of days or weeks, e.g. /stats 7d or /stats 4w, up to %d days. "+
+	"Without a range, lifetime totals are shown.", statistics.MaxRangeDays)
+
+// parseStatsRange parses a /stats range such as 7d or 4w into days
+func parseStatsRange(value string) (int, bool) {
+	value = strings.ToLower(value)
+	if len(value) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	switch value[len(value)-1] {
+	case 'd':
+	case 'w':
+		n *= 7
+	default:
+		return 0, false
+	}
+	if n > statistics.MaxRangeDays {
+		return 0, false
+	}
+	return n, true
+}
+
+// handleRangeStats shows the bot's statistics over the last days complete days:
+// totals from the daily rollups, the most active guests and per-day charts
+func (s *Service) handleRangeStats(ctx context.Context, b *gotgbot.Bot, update *ext.Context, days int) error {
+	chatID := update.EffectiveChat.Id
+
+	stats, err := s.statsService.GetBotRangeStatistics(s.botID, days)
+	if err != nil {
+		s.logger.Error("Failed to get range statistics",
+			zap.Int("days", days),
+			zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to retrieve statistics. Please try again later.", nil)
+		return err
+	}
+
+	bot, err := s.cachedBot(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to retrieve statistics. Please try again later.", nil)
+		return err
+	}
+
+	_, err = b.SendMessage(chatID, s.formatRangeStats(bot, stats), &gotgbot.SendMessageOpts{
+		ParseMode: "Markdown",
+	})
+	if err != nil {
+		return err
+	}
+
+	period := statsPeriod(days)
+	s.sendStatsCharts(b, chatID, stats.Series, []statsChart{
+		{name: "messages", caption: "Messages per day (" + period + ")", render: statistics.RenderMessagesChart},
+		{name: "new_guests", caption: "New guests per day (" + period + ")", render: statistics.RenderNewGuestsChart},
+	})
+	return nil
+}
+
+// formatRangeStats describes range statistics as Markdown
+func (s *Service) formatRangeStats(bot *models.ForwarderBot, stats *statistics.RangeStatistics) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*Bot Statistics (%s)*\n\n", statsPeriod(stats.Days))
+	fmt.Fprintf(&text, "Inbound Messages: %s (%s/day)\n", utils.FormatNumber(stats.Inbound), perDay(stats.Inbound, stats.Days))
+	fmt.Fprintf(&text, "Outbound Messages: %s (%s/day)\n", utils.FormatNumber(stats.Outbound), perDay(stats.Outbound, stats.Days))
+	fmt.Fprintf(&text, "New Guests: %s (%s/day)\n", utils.FormatNumber(stats.NewGuests), perDay(stats.NewGuests, stats.Days))
+	if stats.Failures > 0 {
+		fmt.Fprintf(&text, "Failed Deliveries: %s\n", utils.FormatNumber(stats.Failures))
+	}
+
+	if len(stats.TopGuests) > 0 {
+		text.WriteString("\n*Most Active Guests*\n")
+		for i, guest := range stats.TopGuests {
+			fmt.Fprintf(&text, "%d. %s - %s messages\n", i+1,
+				s.rangeGuestLabel(bot, guest), utils.FormatNumber(guest.Messages))
+		}
+	}
+	return text.String()
+}
+
+// rangeGuestLabel names a guest in the statistics: a link to the user, or the
+// privacy header when the bot hides guest names
+func (s *Service) rangeGuestLabel(bot *models.ForwarderBot, count repository.GuestMessageCount) string {
+	if bot.PrivacyMode {
+		guest, err := s.guestRepo.GetByBotIDAndUserID(s.botID, count.GuestChatID)
+		if err != nil {
+			s.logger.Warn("Failed to get guest", zap.Error(err))
+			return "A guest"
+		}
+		return utils.EscapeMarkdown(bot.GuestHeader(guest))
+	}
+	return fmt.Sprintf("[%d](tg://user?id=%d)", count.GuestChatID, count.GuestChatID)
+}
+
+// statsPeriod describes the last days days, e.g. "last 7 days"
+func statsPeriod(days int) string {
+	if days == 1 {
+		return "last day"
+	}
+	return fmt.Sprintf("last %d days", days)
+}
+
+// perDay returns the daily average of total over days with one decimal
+func perDay(total int64, days int) string {
+	return utils.FormatDecimal(float64(total)/float64(days), 1)
+}
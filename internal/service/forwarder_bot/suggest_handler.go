@@ -0,0 +1,256 @@
+package forwarder_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/llm"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// maxCopyTextLength is Telegram's limit for copy-text buttons
+const maxCopyTextLength = 256
+
+// maxMessageLength is Telegram's limit for message text
+const maxMessageLength = 4096
+
+// recordConversation keeps a guest message as context for draft replies
+func (s *Service) recordConversation(guestChatID int64, role llm.Role, msg *gotgbot.Message) {
+	if s.suggester == nil {
+		return
+	}
+	text := msg.Text
+	if text == "" {
+		text = msg.Caption
+	}
+	s.suggester.Record(s.botID, guestChatID, role, text)
+}
+
+// recordStaffReply keeps a reply that was sent to a guest as context for draft replies
+func (s *Service) recordStaffReply(recipientChatID int64, reply *gotgbot.Message) {
+	if s.suggester == nil || reply.ReplyToMessage == nil {
+		return
+	}
+	mapping, err := s.messageMappingRepo.GetByRecipientMessage(s.botID, recipientChatID, reply.ReplyToMessage.MessageId)
+	if err != nil {
+		return
+	}
+	s.recordConversation(mapping.GuestChatID, llm.RoleAssistant, reply)
+}
+
+// canUseDraftReplies reports whether the user may request and send draft
+// replies in the chat. Like /ban, members of group recipients may reply to
+// guests anyway, so they may use drafts too.
+func (s *Service) canUseDraftReplies(ctx context.Context, userID int64, recipient *models.Recipient) bool {
+	if recipient.RecipientType == models.RecipientTypeGroup {
+		return true
+	}
+	isManagerOrAdmin, err := s.IsManagerOrAdmin(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Failed to check permission", zap.Error(err))
+	}
+	return isManagerOrAdmin
+}
+
+// handleSuggest drafts a reply to the guest whose message the command replies
+// to and posts it for staff to send, edit or discard
+func (s *Service) handleSuggest(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+
+	recipient, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, chatID)
+	if err != nil {
+		_, err := b.SendMessage(chatID, "This command can only be used in recipient chats.", nil)
+		return err
+	}
+	if !s.canUseDraftReplies(ctx, userID, recipient) {
+		s.accessDenied(ctx, update, "/suggest")
+		_, err := b.SendMessage(chatID, "You are not authorized to use this command.", nil)
+		return err
+	}
+	if s.suggester == nil {
+		_, err := b.SendMessage(chatID, "Draft replies are not enabled on this instance.", nil)
+		return err
+	}
+
+	replyTo := update.EffectiveMessage.ReplyToMessage
+	if replyTo == nil {
+		_, err := b.SendMessage(chatID, "Please reply to a guest's message with /suggest to get a draft reply.", nil)
+		return err
+	}
+	mapping, err := s.messageMappingRepo.GetByRecipientMessage(s.botID, chatID, replyTo.MessageId)
+	if err != nil {
+		_, err := b.SendMessage(chatID,
+			"Failed to find the corresponding guest. Please make sure you are replying to a forwarded message.", nil)
+		return err
+	}
+
+	if _, err := b.SendChatAction(chatID, "typing", nil); err != nil {
+		s.logger.Debug("Failed to send typing action", zap.Error(err))
+	}
+
+	draft, err := s.suggester.Draft(ctx, s.botID, mapping.GuestChatID)
+	if errors.Is(err, llm.ErrNoHistory) {
+		_, err := b.SendMessage(chatID,
+			"No recent text from this conversation is known yet. Drafts use messages received since the bot last started.", nil)
+		return err
+	}
+	if err != nil {
+		s.logger.Warn("Failed to draft reply",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("guest_chat_id", mapping.GuestChatID),
+			zap.Error(err))
+		_, err := b.SendMessage(chatID, "Could not get a draft reply right now. Please reply yourself or try again later.", nil)
+		return err
+	}
+	if runes := []rune(draft); len(runes) > maxMessageLength {
+		draft = string(runes[:maxMessageLength])
+	}
+
+	s.logger.Info("Draft reply requested",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int64("guest_chat_id", mapping.GuestChatID))
+
+	// The draft replies to the guest's message, so sending it is an ordinary
+	// reply to the guest and staff can still answer differently themselves
+	_, err = b.SendMessage(chatID, draft, &gotgbot.SendMessageOpts{
+		ReplyParameters: &gotgbot.ReplyParameters{MessageId: replyTo.MessageId},
+		ReplyMarkup:     draftKeyboard(draft),
+	})
+	return err
+}
+
+func draftKeyboard(draft string) gotgbot.InlineKeyboardMarkup {
+	keyboard := [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "✅ Send to guest", CallbackData: "suggest:send"},
+			{Text: "🗑 Discard", CallbackData: "suggest:discard"},
+		},
+	}
+	if len([]rune(draft)) <= maxCopyTextLength {
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: "✏️ Copy to edit", CopyText: &gotgbot.CopyTextButton{Text: draft}},
+		})
+	}
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+func (s *Service) handleSuggestCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+	draftMessage := update.EffectiveMessage
+
+	if len(parts) > 0 && parts[0] == "noop" {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, nil)
+		return err
+	}
+
+	recipient, err := s.recipientRepo.GetByBotIDAndChatID(s.botID, chatID)
+	if err != nil || !s.canUseDraftReplies(ctx, userID, recipient) {
+		s.accessDenied(ctx, update, "draft reply callback")
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "You are not authorized to use this button.",
+		})
+		return err
+	}
+	if draftMessage == nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, nil)
+		return err
+	}
+
+	switch {
+	case len(parts) > 0 && parts[0] == "discard":
+		if _, err := b.DeleteMessage(chatID, draftMessage.MessageId, nil); err != nil {
+			s.logger.Warn("Failed to delete draft reply", zap.Error(err))
+		}
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Draft discarded.",
+		})
+		return err
+	case len(parts) > 0 && parts[0] == "send":
+		return s.sendDraftReply(ctx, b, update, draftMessage)
+	default:
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+}
+
+// sendDraftReply sends the draft message to the guest like a reply from staff
+func (s *Service) sendDraftReply(ctx context.Context, b *gotgbot.Bot, update *ext.Context, draftMessage *gotgbot.Message) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+
+	if draftMessage.ReplyToMessage == nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "The guest's message is no longer available. Please reply yourself.",
+		})
+		return err
+	}
+
+	// Remove the buttons first so the draft is not sent twice and they do not
+	// end up in the guest's copy
+	_, _, err := b.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+		ChatId:    chatID,
+		MessageId: draftMessage.MessageId,
+	})
+	if err != nil {
+		s.logger.Debug("Failed to remove draft buttons, draft was likely handled already", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "This draft was already handled.",
+		})
+		return err
+	}
+
+	if err := s.messageForwarder.ForwardReplyToGuest(ctx, b, s.botID, chatID, draftMessage); err != nil {
+		s.logger.Warn("Failed to send draft reply to guest",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		text := "Failed to send the draft. Please try again or reply yourself."
+		if isQuotaExceeded(err) {
+			text = quotaExceededReplyText
+		}
+		if _, _, err := b.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+			ChatId:      chatID,
+			MessageId:   draftMessage.MessageId,
+			ReplyMarkup: draftKeyboard(draftMessage.Text),
+		}); err != nil {
+			s.logger.Warn("Failed to restore draft buttons", zap.Error(err))
+		}
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text:      text,
+			ShowAlert: true,
+		})
+		return err
+	}
+
+	s.logger.Info("Draft reply sent to guest",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int64("draft_message_id", draftMessage.MessageId))
+	s.recordStaffReply(chatID, draftMessage)
+
+	_, _, err = b.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+		ChatId:    chatID,
+		MessageId: draftMessage.MessageId,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{{Text: fmt.Sprintf("✅ Sent by %s", update.EffectiveUser.FirstName), CallbackData: "suggest:noop"}},
+		}},
+	})
+	if err != nil {
+		s.logger.Warn("Failed to mark draft as sent", zap.Error(err))
+	}
+
+	_, err = b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: "Sent to the guest.",
+	})
+	return err
+}
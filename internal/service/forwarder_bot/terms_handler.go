@@ -0,0 +1,219 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/i18n"
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// checkTermsAccepted enforces the per-bot ToS gate for guest messages.
+// Returns true if the message may be forwarded. If the guest still has to
+// accept the current terms, the notice is sent and false is returned.
+func (s *Service) checkTermsAccepted(b *gotgbot.Bot, update *ext.Context, lang string) (bool, error) {
+	// The notice is only shown to guests talking to the bot directly
+	if update.EffectiveChat.Type != "private" {
+		return true, nil
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bot: %w", err)
+	}
+	if bot.TermsText == "" {
+		return true, nil
+	}
+
+	userID := update.EffectiveUser.Id
+	guest, err := s.guestRepo.GetByBotIDAndUserID(s.botID, userID)
+	if err == nil && guest.HasAcceptedTerms(bot.TermsVersion) {
+		return true, nil
+	}
+
+	s.logger.Debug("Guest has not accepted current terms, sending notice",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int("terms_version", bot.TermsVersion))
+
+	if err := s.sendTermsNotice(b, update.EffectiveChat.Id, bot, lang); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// sendTermsNotice sends the bot's terms with an accept button. Only the text
+// around the terms is translated; the terms are shown as the manager wrote them.
+func (s *Service) sendTermsNotice(b *gotgbot.Bot, chatID int64, bot *models.ForwarderBot, lang string) error {
+	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: i18n.T(lang, "guest.terms.accept_button"), CallbackData: fmt.Sprintf("terms:accept:%d", bot.TermsVersion)},
+		},
+	}}
+	text := i18n.T(lang, "guest.terms.prompt", bot.TermsText)
+	_, err := b.SendMessage(chatID, text, &gotgbot.SendMessageOpts{
+		ReplyMarkup: keyboard,
+	})
+	return err
+}
+
+func (s *Service) handleTermsCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	userID := update.EffectiveUser.Id
+	lang := s.language(ctx, update.EffectiveUser)
+
+	if len(parts) > 0 && parts[0] == "noop" {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, nil)
+		return err
+	}
+
+	if len(parts) < 2 || parts[0] != "accept" {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+
+	version, err := strconv.Atoi(parts[1])
+	if err != nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid terms version",
+		})
+		return err
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: i18n.T(lang, "common.error"),
+		})
+		return err
+	}
+
+	if version != bot.TermsVersion {
+		s.logger.Debug("Guest accepted outdated terms version",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Int("accepted_version", version),
+			zap.Int("current_version", bot.TermsVersion))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: i18n.T(lang, "guest.terms.changed"),
+		})
+		if err != nil {
+			s.logger.Warn("Failed to answer callback query", zap.Error(err))
+		}
+		if bot.TermsText == "" {
+			return nil
+		}
+		return s.sendTermsNotice(b, update.EffectiveChat.Id, bot, lang)
+	}
+
+	guest, err := s.guestRepo.GetOrCreateByBotIDAndUserID(s.botID, userID)
+	if err != nil {
+		s.logger.Error("Failed to get or create guest", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: i18n.T(lang, "common.error"),
+		})
+		return err
+	}
+
+	now := time.Now()
+	guest.TermsAcceptedVersion = version
+	guest.TermsAcceptedAt = &now
+	if err := s.guestRepo.Update(guest); err != nil {
+		s.logger.Error("Failed to record terms acceptance", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: i18n.T(lang, "common.error"),
+		})
+		return err
+	}
+
+	s.logger.Info("Guest accepted terms",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int("terms_version", version))
+
+	_, err = b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: i18n.T(lang, "guest.terms.accepted"),
+	})
+	if err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	if update.CallbackQuery.Message != nil {
+		_, _, err = b.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+			ChatId:    update.EffectiveChat.Id,
+			MessageId: update.CallbackQuery.Message.GetMessageId(),
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+				{{Text: i18n.T(lang, "guest.terms.accepted_on", s.formatTime(now)), CallbackData: "terms:noop"}},
+			}},
+		})
+		if err != nil {
+			s.logger.Warn("Failed to update terms message", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// handleSetTerms sets (or with no argument, clears) the bot's terms notice
+func (s *Service) handleSetTerms(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	text := ""
+	if parts := strings.SplitN(update.EffectiveMessage.Text, " ", 2); len(parts) == 2 {
+		text = strings.TrimSpace(parts[1])
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	bot.TermsText = text
+	if text != "" {
+		bot.TermsVersion++
+	}
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update terms", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update terms. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Bot terms updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Int("terms_version", bot.TermsVersion),
+		zap.Bool("enabled", text != ""))
+
+	if text == "" {
+		_, err = b.SendMessage(chatID, "Terms notice has been disabled. Guests can send messages without accepting terms.", nil)
+		return err
+	}
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Terms updated to version %d. All guests must accept the new version before their messages are forwarded.", bot.TermsVersion), nil)
+	return err
+}
+
+// handleTerms shows the bot's current terms notice
+func (s *Service) handleTerms(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(update.EffectiveChat.Id, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if bot.TermsText == "" {
+		_, err := b.SendMessage(update.EffectiveChat.Id, "This bot has no terms notice.", nil)
+		return err
+	}
+	return s.sendTermsNotice(b, update.EffectiveChat.Id, bot, s.language(ctx, update.EffectiveUser))
+}
@@ -0,0 +1,83 @@
+package forwarder_bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// location returns the timezone this bot shows times in
+func (s *Service) location() *time.Location {
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		return time.Local
+	}
+	return bot.Location()
+}
+
+// formatTime formats t in the bot's timezone
+func (s *Service) formatTime(t time.Time) string {
+	return utils.FormatTimestamp(t, s.location())
+}
+
+// handleSetTimezone shows or sets the timezone used for times shown by this bot
+func (s *Service) handleSetTimezone(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	arg := ""
+	if parts := strings.Fields(update.EffectiveMessage.Text); len(parts) >= 2 {
+		arg = parts[1]
+	}
+	if arg == "" {
+		source := "manager's timezone"
+		if bot.Timezone != "" {
+			source = "set for this bot"
+		}
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("Timezone: %s (%s)\n\n"+
+				"Usage: /settimezone <timezone>\n"+
+				"Example: /settimezone Asia/Shanghai\n"+
+				"Use /settimezone default to follow the manager's timezone.",
+				utils.TimezoneLabel(bot.Location()), source), nil)
+		return err
+	}
+
+	timezone := ""
+	if !strings.EqualFold(arg, "default") {
+		loc, err := utils.LoadTimezone(arg)
+		if err != nil {
+			_, err := b.SendMessage(chatID,
+				fmt.Sprintf("Unknown timezone %q. Use an IANA name such as Europe/Berlin, Asia/Shanghai or UTC.", arg), nil)
+			return err
+		}
+		timezone = loc.String()
+	}
+
+	bot.Timezone = timezone
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update bot timezone", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update the timezone. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Bot timezone updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.String("timezone", timezone))
+
+	_, err = b.SendMessage(chatID, fmt.Sprintf("Timezone set to %s.", utils.TimezoneLabel(bot.Location())), nil)
+	return err
+}
@@ -0,0 +1,73 @@
+package forwarder_bot
+
+import (
+	"context"
+	"errors"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+	"go-telegram-forwarder-bot/internal/service/transcription"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+// attachTranscription replies to each recipient's copy of a guest voice message
+// with its transcription. If transcription fails the recipients are told so
+// they know to listen instead of waiting for the text.
+func (s *Service) attachTranscription(ctx context.Context, b *gotgbot.Bot, guestChatID int64, msg *gotgbot.Message) {
+	if s.transcriber == nil || msg.Voice == nil {
+		return
+	}
+	if s.settings == nil || !s.settings.GetBool(s.botID, settings.KeyTranscribe) {
+		return
+	}
+
+	mappings, err := s.messageMappingRepo.GetAllByGuestMessage(s.botID, guestChatID, msg.MessageId)
+	if err != nil {
+		s.logger.Warn("Failed to get copies of voice message",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Error(err))
+		return
+	}
+
+	text, err := s.transcriber.TranscribeVoice(ctx, b, msg.Voice)
+	var note string
+	switch {
+	case errors.Is(err, transcription.ErrTooLong):
+		note = "📝 This voice message is too long to transcribe."
+	case err != nil:
+		s.logger.Warn("Failed to transcribe voice message",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Int64("message_id", msg.MessageId),
+			zap.Error(err))
+		note = "📝 Transcription unavailable. Please listen to the voice message."
+	case text == "":
+		note = "📝 No speech recognized."
+	default:
+		note = "📝 " + text
+	}
+	if runes := []rune(note); len(runes) > maxMessageLength {
+		note = string(runes[:maxMessageLength-1]) + "…"
+	}
+
+	for _, mapping := range mappings {
+		if mapping.Direction != models.MessageDirectionInbound {
+			continue
+		}
+		_, err := b.SendMessage(mapping.RecipientChatID, note, &gotgbot.SendMessageOpts{
+			ReplyParameters: &gotgbot.ReplyParameters{
+				MessageId:                mapping.RecipientMessageID,
+				AllowSendingWithoutReply: true,
+			},
+		})
+		if err != nil {
+			s.logger.Warn("Failed to send transcription to recipient",
+				zap.String("bot_id", s.botID.String()),
+				zap.Int64("recipient_chat_id", mapping.RecipientChatID),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package forwarder_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// welcomeText returns the text of a welcome variant
+func welcomeText(bot *models.ForwarderBot, variant string) string {
+	if variant == models.WelcomeVariantB && bot.WelcomeTextB != "" {
+		return bot.WelcomeTextB
+	}
+	return bot.WelcomeText
+}
+
+// welcomePlaceholders lists the placeholders welcome messages may use
+const welcomePlaceholders = "{first_name}, {last_name}, {full_name}, {username}, {user_id}, {bot_name}"
+
+// renderWelcome fills in the placeholders of a welcome text for the guest,
+// escaping the values for Markdown if markdown is set. {username} falls back
+// to the first name for guests without a username.
+func renderWelcome(text string, bot *models.ForwarderBot, user *gotgbot.User, markdown bool) string {
+	escape := func(value string) string {
+		if markdown {
+			return utils.EscapeMarkdown(value)
+		}
+		return value
+	}
+	username := user.FirstName
+	if user.Username != "" {
+		username = "@" + user.Username
+	}
+	return utils.FillPlaceholders(text, map[string]string{
+		"first_name": escape(user.FirstName),
+		"last_name":  escape(user.LastName),
+		"full_name":  escape(strings.TrimSpace(user.FirstName + " " + user.LastName)),
+		"username":   escape(username),
+		"user_id":    strconv.FormatInt(user.Id, 10),
+		"bot_name":   escape("@" + bot.Name),
+	})
+}
+
+// sendWelcomeText sends a welcome variant to the guest as Markdown, or as plain
+// text if Telegram rejects the Markdown
+func (s *Service) sendWelcomeText(b *gotgbot.Bot, chatID int64, bot *models.ForwarderBot, variant string, user *gotgbot.User) error {
+	text := welcomeText(bot, variant)
+	_, err := b.SendMessage(chatID, renderWelcome(text, bot, user, true), &gotgbot.SendMessageOpts{
+		ParseMode: "Markdown",
+	})
+	if err == nil {
+		return nil
+	}
+	s.logger.Warn("Failed to send welcome message as Markdown, sending plain text",
+		zap.String("bot_id", s.botID.String()),
+		zap.String("variant", variant),
+		zap.Error(err))
+	_, err = b.SendMessage(chatID, renderWelcome(text, bot, user, false), nil)
+	return err
+}
+
+// assignWelcomeVariant picks the welcome variant for a new guest, at random
+// when both variants are set, and records it so conversions can be compared
+func (s *Service) assignWelcomeVariant(bot *models.ForwarderBot, userID int64) string {
+	variant := models.WelcomeVariantA
+	if bot.WelcomeTextB != "" && rand.Intn(2) == 1 {
+		variant = models.WelcomeVariantB
+	}
+	if _, err := s.welcomeAssignmentRepo.Create(&models.WelcomeAssignment{
+		BotID:       s.botID,
+		GuestUserID: userID,
+		Variant:     variant,
+	}); err != nil {
+		s.logger.Warn("Failed to record welcome variant",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	} else {
+		s.logger.Debug("Assigned welcome variant to new guest",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.String("variant", variant))
+	}
+	return variant
+}
+
+// sendWelcome sends the bot's welcome message to a guest on /start.
+// Guests seen for the first time are assigned a variant (at random when both
+// variants are set) so conversions can be compared. It reports false if the
+// bot has no welcome message.
+func (s *Service) sendWelcome(b *gotgbot.Bot, update *ext.Context) (bool, error) {
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bot: %w", err)
+	}
+	if bot.WelcomeText == "" {
+		return false, nil
+	}
+
+	userID := update.EffectiveUser.Id
+	variant := models.WelcomeVariantA
+	assignment, err := s.welcomeAssignmentRepo.GetByBotIDAndUserID(s.botID, userID)
+	switch {
+	case err == nil:
+		variant = assignment.Variant
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// Guests who already wrote before the welcome message existed are not part of the test
+		if _, guestErr := s.guestRepo.GetByBotIDAndUserID(s.botID, userID); errors.Is(guestErr, gorm.ErrRecordNotFound) {
+			variant = s.assignWelcomeVariant(bot, userID)
+		}
+	default:
+		s.logger.Warn("Failed to get welcome variant", zap.Error(err))
+	}
+
+	return true, s.sendWelcomeText(b, update.EffectiveChat.Id, bot, variant, update.EffectiveUser)
+}
+
+// welcomeNewGuest sends the welcome message to a guest whose first message
+// this is, unless they already got it on /start. The A/B test measures
+// whether guests write after being welcomed, so guests who write right away
+// get message A and are not part of it.
+func (s *Service) welcomeNewGuest(b *gotgbot.Bot, update *ext.Context) {
+	userID := update.EffectiveUser.Id
+	created, err := s.guestRepo.CreateIfMissing(s.botID, userID)
+	if err != nil {
+		s.logger.Warn("Failed to register guest", zap.Error(err))
+		return
+	}
+	if !created {
+		return
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot", zap.Error(err))
+		return
+	}
+	if bot.WelcomeText == "" {
+		return
+	}
+	_, err = s.welcomeAssignmentRepo.GetByBotIDAndUserID(s.botID, userID)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Warn("Failed to get welcome variant", zap.Error(err))
+		return
+	}
+
+	s.logger.Debug("Welcoming new guest",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", userID))
+	if err := s.sendWelcomeText(b, update.EffectiveChat.Id, bot, models.WelcomeVariantA, update.EffectiveUser); err != nil {
+		s.logger.Warn("Failed to send welcome message", zap.Error(err))
+	}
+}
+
+// recordWelcomeConversion marks a guest who was shown a welcome variant as converted
+// once a message of theirs has been forwarded
+func (s *Service) recordWelcomeConversion(userID int64) {
+	converted, err := s.welcomeAssignmentRepo.MarkConverted(s.botID, userID)
+	if err != nil {
+		s.logger.Warn("Failed to record welcome conversion",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		return
+	}
+	if converted {
+		s.logger.Debug("Guest converted after welcome message",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", userID))
+	}
+}
+
+// handleSetWelcome sets or clears a welcome message variant, or resets the A/B test results
+func (s *Service) handleSetWelcome(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	parts := strings.SplitN(update.EffectiveMessage.Text, " ", 3)
+
+	usage := "Usage: /setwelcome <a|b> [text]\n" +
+		"Variant A is the welcome message sent on /start and to guests writing for the first time; set variant B to split new guests between the two.\n" +
+		"The text may use Markdown and the placeholders " + welcomePlaceholders + ".\n" +
+		"Leave out the text to clear a variant. Use /setwelcome reset to clear the A/B results."
+	if len(parts) < 2 {
+		_, err := b.SendMessage(chatID, usage, nil)
+		return err
+	}
+
+	bot, err := s.botRepo.GetByID(s.botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	text := ""
+	if len(parts) == 3 {
+		text = strings.TrimSpace(parts[2])
+	}
+
+	var reply string
+	switch strings.ToLower(strings.TrimSpace(parts[1])) {
+	case "a":
+		bot.WelcomeText = text
+		reply = "Welcome message A updated."
+		if text == "" {
+			bot.WelcomeTextB = ""
+			reply = "Welcome message disabled."
+		}
+	case "b":
+		if text != "" && bot.WelcomeText == "" {
+			_, err := b.SendMessage(chatID, "Set welcome message A first.", nil)
+			return err
+		}
+		bot.WelcomeTextB = text
+		reply = "Welcome message B updated. New guests are now split between A and B."
+		if text == "" {
+			reply = "Welcome message B cleared. All guests now get message A."
+		}
+	case "reset":
+		if err := s.welcomeAssignmentRepo.DeleteByBotID(s.botID); err != nil {
+			s.logger.Error("Failed to reset welcome assignments", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to reset the A/B results. Please try again later.", nil)
+			return err
+		}
+		s.logger.Info("Welcome A/B results reset",
+			zap.String("bot_id", s.botID.String()),
+			zap.Int64("user_id", update.EffectiveUser.Id))
+		_, err := b.SendMessage(chatID, "Welcome A/B results have been reset.", nil)
+		return err
+	default:
+		_, err := b.SendMessage(chatID, usage, nil)
+		return err
+	}
+
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update welcome message", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update welcome message. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Bot welcome message updated",
+		zap.String("bot_id", s.botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Bool("variant_a", bot.WelcomeText != ""),
+		zap.Bool("variant_b", bot.WelcomeTextB != ""))
+
+	_, err = b.SendMessage(chatID, reply, nil)
+	return err
+}
+
+// welcomeStatsText formats the welcome A/B results for /stats
+func (s *Service) welcomeStatsText() string {
+	stats, err := s.statsService.GetWelcomeStats(s.botID)
+	if err != nil {
+		s.logger.Warn("Failed to get welcome statistics", zap.Error(err))
+		return ""
+	}
+	if len(stats) == 0 {
+		return ""
+	}
+
+	var text strings.Builder
+	text.WriteString("\n\n*Welcome A/B Test*")
+	for _, variant := range stats {
+		text.WriteString(fmt.Sprintf("\nVariant %s: %s guests, %s continued (%s%%)",
+			variant.Variant, utils.FormatNumber(variant.Assigned), utils.FormatNumber(variant.Converted),
+			utils.FormatDecimal(variant.ConversionRate(), 1)))
+	}
+	return text.String()
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"go-telegram-forwarder-bot/internal/models"
 	"go-telegram-forwarder-bot/internal/repository"
@@ -53,7 +52,7 @@ func (gm *GroupMonitor) CheckRecipient(ctx context.Context, bot *gotgbot.Bot, bo
 				zap.Error(err))
 
 			// Delete recipient
-			if delErr := gm.recipientRepo.Delete(recipient.ID); delErr != nil {
+			if delErr := gm.recipientRepo.Delete(botID, recipient.ID); delErr != nil {
 				gm.logger.Error("Failed to delete invalid recipient",
 					zap.String("bot_id", botID.String()),
 					zap.Int64("chat_id", recipient.ChatID),
@@ -81,24 +80,8 @@ func (gm *GroupMonitor) CheckRecipient(ctx context.Context, bot *gotgbot.Bot, bo
 	return true
 }
 
-func (gm *GroupMonitor) StartPeriodicCheck(ctx context.Context, bot *gotgbot.Bot, botID uuid.UUID) {
-	ticker := time.NewTicker(24 * time.Hour)
-	defer ticker.Stop()
-
-	// Initial check
-	gm.checkAllRecipients(ctx, bot, botID)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			gm.checkAllRecipients(ctx, bot, botID)
-		}
-	}
-}
-
-func (gm *GroupMonitor) checkAllRecipients(ctx context.Context, bot *gotgbot.Bot, botID uuid.UUID) {
+// CheckAllRecipients verifies every group recipient of a bot
+func (gm *GroupMonitor) CheckAllRecipients(ctx context.Context, bot *gotgbot.Bot, botID uuid.UUID) {
 	recipients, err := gm.recipientRepo.GetByBotID(botID)
 	if err != nil {
 		gm.logger.Warn("Failed to get recipients for periodic check",
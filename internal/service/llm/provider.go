@@ -0,0 +1,140 @@
+// Package llm drafts replies to guests with a language model. It is only used
+// when enabled in the config, because conversation text leaves the instance.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/utils"
+)
+
+// Role says who wrote a message in the conversation
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleGuest     Role = "user"      // The guest
+	RoleAssistant Role = "assistant" // The bot's staff
+)
+
+// Message is one message of the conversation sent to the provider
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// Provider completes a conversation with the next assistant message
+type Provider interface {
+	Complete(ctx context.Context, messages []Message) (string, error)
+}
+
+// NewProvider creates the provider selected by llm.provider
+func NewProvider(cfg *config.LLMConfig, proxy *config.ProxyConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "", "openai":
+		httpClient, err := utils.CreateHTTPClientWithProxy(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		httpClient.Timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+		return &openAIProvider{
+			endpoint:   cfg.Endpoint,
+			apiKey:     cfg.APIKey,
+			model:      cfg.Model,
+			maxTokens:  cfg.MaxTokens,
+			httpClient: httpClient,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown llm provider: %s", cfg.Provider)
+	}
+}
+
+// openAIProvider talks to an OpenAI-compatible chat completions endpoint, which
+// most hosted and self-hosted model servers offer
+type openAIProvider struct {
+	endpoint   string
+	apiKey     string
+	model      string
+	maxTokens  int
+	httpClient *http.Client
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model     string        `json:"model"`
+	Messages  []chatMessage `json:"messages"`
+	MaxTokens int           `json:"max_tokens,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, messages []Message) (string, error) {
+	request := chatRequest{
+		Model:     p.model,
+		Messages:  make([]chatMessage, 0, len(messages)),
+		MaxTokens: p.maxTokens,
+	}
+	for _, m := range messages {
+		request.Messages = append(request.Messages, chatMessage{Role: string(m.Role), Content: m.Content})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Responses are small; the limit only guards against a misbehaving server
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var response chatResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("failed to decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if response.Error != nil {
+		return "", fmt.Errorf("provider error (status %d): %s", resp.StatusCode, response.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("response has no choices")
+	}
+
+	return strings.TrimSpace(response.Choices[0].Message.Content), nil
+}
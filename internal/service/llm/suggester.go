@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// historyTTL drops conversations nobody wrote in for this long
+	historyTTL = 24 * time.Hour
+	// maxConversations bounds the memory used for history
+	maxConversations = 10000
+	// maxMessageLength truncates long messages kept as context
+	maxMessageLength = 2000
+)
+
+const defaultSystemPrompt = "You help the support staff of a Telegram bot answer a user. " +
+	"Write the next reply from the staff to the user, in the user's language. " +
+	"Be brief, friendly and factual, and do not promise anything the conversation does not support. " +
+	"Reply with the message text only."
+
+// ErrNoHistory is returned by Draft when nothing of the conversation is known,
+// e.g. after a restart
+var ErrNoHistory = errors.New("no conversation history")
+
+type conversationKey struct {
+	botID       uuid.UUID
+	guestChatID int64
+}
+
+type conversation struct {
+	messages  []Message
+	updatedAt time.Time
+}
+
+// Suggester keeps the recent text of each conversation in memory and drafts
+// replies from it. History is not persisted and not shared between instances.
+type Suggester struct {
+	provider     Provider
+	systemPrompt string
+	maxMessages  int
+	logger       *zap.Logger
+
+	mu            sync.Mutex
+	conversations map[conversationKey]*conversation
+}
+
+func NewSuggester(provider Provider, cfg *config.LLMConfig, logger *zap.Logger) *Suggester {
+	systemPrompt := cfg.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = defaultSystemPrompt
+	}
+	return &Suggester{
+		provider:      provider,
+		systemPrompt:  systemPrompt,
+		maxMessages:   cfg.ContextMessages,
+		logger:        logger,
+		conversations: make(map[conversationKey]*conversation),
+	}
+}
+
+// Record adds a message to the guest's conversation. Messages without text,
+// like stickers or photos without a caption, are skipped.
+func (s *Suggester) Record(botID uuid.UUID, guestChatID int64, role Role, text string) {
+	if text == "" {
+		return
+	}
+	if runes := []rune(text); len(runes) > maxMessageLength {
+		text = string(runes[:maxMessageLength])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	key := conversationKey{botID: botID, guestChatID: guestChatID}
+	c, ok := s.conversations[key]
+	if !ok {
+		if len(s.conversations) >= maxConversations {
+			s.evictLocked(now)
+		}
+		c = &conversation{}
+		s.conversations[key] = c
+	}
+	c.messages = append(c.messages, Message{Role: role, Content: text})
+	if len(c.messages) > s.maxMessages {
+		c.messages = c.messages[len(c.messages)-s.maxMessages:]
+	}
+	c.updatedAt = now
+}
+
+// evictLocked drops expired conversations, or the least recently updated one
+// if none has expired
+func (s *Suggester) evictLocked(now time.Time) {
+	var oldestKey conversationKey
+	var oldest time.Time
+	for key, c := range s.conversations {
+		if now.Sub(c.updatedAt) > historyTTL {
+			delete(s.conversations, key)
+			continue
+		}
+		if oldest.IsZero() || c.updatedAt.Before(oldest) {
+			oldestKey, oldest = key, c.updatedAt
+		}
+	}
+	if len(s.conversations) >= maxConversations {
+		delete(s.conversations, oldestKey)
+	}
+}
+
+func (s *Suggester) history(botID uuid.UUID, guestChatID int64) []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := conversationKey{botID: botID, guestChatID: guestChatID}
+	c, ok := s.conversations[key]
+	if !ok {
+		return nil
+	}
+	if time.Since(c.updatedAt) > historyTTL {
+		delete(s.conversations, key)
+		return nil
+	}
+	return append([]Message(nil), c.messages...)
+}
+
+// Draft asks the provider for the next staff reply to the guest
+func (s *Suggester) Draft(ctx context.Context, botID uuid.UUID, guestChatID int64) (string, error) {
+	history := s.history(botID, guestChatID)
+	if len(history) == 0 {
+		return "", ErrNoHistory
+	}
+
+	messages := make([]Message, 0, len(history)+1)
+	messages = append(messages, Message{Role: RoleSystem, Content: s.systemPrompt})
+	messages = append(messages, history...)
+
+	start := time.Now()
+	draft, err := s.provider.Complete(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	s.logger.Debug("Draft reply generated",
+		zap.String("bot_id", botID.String()),
+		zap.Int64("guest_chat_id", guestChatID),
+		zap.Int("context_messages", len(history)),
+		zap.Duration("took", time.Since(start)))
+
+	if draft == "" {
+		return "", errors.New("provider returned an empty draft")
+	}
+	return draft, nil
+}
@@ -0,0 +1,38 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/repository"
+)
+
+// DBLocker implements Locker with lease rows in the worker_locks table.
+// Used when Redis is not enabled.
+type DBLocker struct {
+	repo repository.WorkerLockRepository
+}
+
+func NewDBLocker(repo repository.WorkerLockRepository) *DBLocker {
+	return &DBLocker{repo: repo}
+}
+
+func (l *DBLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	token := newToken()
+	acquired, err := l.repo.TryAcquire(key, token, time.Now().Add(ttl))
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !acquired {
+		return nil, nil
+	}
+
+	return &Lock{
+		Key:   key,
+		token: token,
+		release: func(ctx context.Context) error {
+			return l.repo.Release(key, token)
+		},
+	}, nil
+}
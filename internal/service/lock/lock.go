@@ -0,0 +1,53 @@
+// Package lock provides distributed locks used to make sure periodic
+// workers only run on one instance at a time.
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Locker acquires named, expiring locks shared between instances
+type Locker interface {
+	// TryLock attempts to acquire key for ttl without blocking.
+	// Returns nil if the lock is currently held by someone else.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error)
+}
+
+// Lock is a held lock. It expires on its own after the TTL passed to TryLock.
+type Lock struct {
+	Key     string
+	token   string
+	release func(ctx context.Context) error
+}
+
+// Release frees the lock early. Releasing a lock that has already expired
+// (and possibly been taken by another instance) is a no-op.
+func (l *Lock) Release(ctx context.Context) error {
+	if l == nil || l.release == nil {
+		return nil
+	}
+	return l.release(ctx)
+}
+
+// AcquireForRun reports whether this instance should run the worker
+// identified by key. The lock is deliberately not released after the run:
+// it expires after ttl, so other instances skip the same tick. Callers
+// should pass a ttl slightly shorter than the worker interval.
+// A nil locker always allows the run (single-instance deployments).
+func AcquireForRun(ctx context.Context, locker Locker, key string, ttl time.Duration) (bool, error) {
+	if locker == nil {
+		return true, nil
+	}
+	held, err := locker.TryLock(ctx, key, ttl)
+	if err != nil {
+		return false, err
+	}
+	return held != nil, nil
+}
+
+func newToken() string {
+	return uuid.New().String()
+}
@@ -0,0 +1,67 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseScript deletes the key only if it is still held by the given token
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker implements Locker with SET NX PX
+type RedisLocker struct {
+	client redis.UniversalClient
+	prefix string
+	mu     sync.RWMutex
+}
+
+func NewRedisLocker(client redis.UniversalClient) *RedisLocker {
+	return &RedisLocker{
+		client: client,
+		prefix: "lock:",
+	}
+}
+
+// SetClient replaces the Redis client, e.g. after a reconnect
+func (l *RedisLocker) SetClient(client redis.UniversalClient) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.client = client
+}
+
+func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	l.mu.RLock()
+	client := l.client
+	l.mu.RUnlock()
+
+	if client == nil {
+		return nil, fmt.Errorf("redis client is not available")
+	}
+
+	fullKey := l.prefix + key
+	token := newToken()
+	ok, err := client.SetNX(ctx, fullKey, token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return &Lock{
+		Key:   key,
+		token: token,
+		release: func(ctx context.Context) error {
+			return releaseScript.Run(ctx, client, []string{fullKey}, token).Err()
+		},
+	}, nil
+}
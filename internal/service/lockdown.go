@@ -0,0 +1,142 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// lockdownNotice is what everyone but superusers gets during a lockdown
+const lockdownNotice = "🚧 This service is temporarily under maintenance. Commands are disabled, please try again later."
+
+// callbackAlertLimit is the most characters a callback query alert can show
+const callbackAlertLimit = 200
+
+// LockdownState describes an active lockdown
+type LockdownState struct {
+	Note      string
+	StartedBy int64 // Telegram user ID of the superuser
+	StartedAt time.Time
+	Until     time.Time // Zero until the lockdown is lifted by hand
+}
+
+// Lockdown is the break-glass switch behind /lockdown. While it is on, only
+// superusers can use commands and buttons in the ManagerBot, the ForwarderBots
+// and the HTTP API. Guest messages are still forwarded. The lockdown is kept in
+// memory; with Redis it is saved on shutdown and restored at startup like other
+// state, otherwise a restart lifts it.
+type Lockdown struct {
+	superusers *Superusers
+
+	mu    sync.RWMutex
+	state *LockdownState
+}
+
+func NewLockdown(superusers *Superusers) *Lockdown {
+	return &Lockdown{superusers: superusers}
+}
+
+// Start turns the lockdown on, replacing any active one. A duration of zero
+// keeps it on until Lift is called.
+func (l *Lockdown) Start(startedBy int64, note string, duration time.Duration) LockdownState {
+	state := LockdownState{
+		Note:      note,
+		StartedBy: startedBy,
+		StartedAt: time.Now(),
+	}
+	if duration > 0 {
+		state.Until = state.StartedAt.Add(duration)
+	}
+
+	l.mu.Lock()
+	l.state = &state
+	l.mu.Unlock()
+	return state
+}
+
+// Lift turns the lockdown off and reports whether it was on
+func (l *Lockdown) Lift() bool {
+	_, active := l.State()
+	l.mu.Lock()
+	l.state = nil
+	l.mu.Unlock()
+	return active
+}
+
+// State returns the active lockdown, if any
+func (l *Lockdown) State() (LockdownState, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.state == nil || (!l.state.Until.IsZero() && time.Now().After(l.state.Until)) {
+		return LockdownState{}, false
+	}
+	return *l.state, true
+}
+
+// Blocks reports whether the Telegram user is locked out, and returns the
+// notice to show them
+func (l *Lockdown) Blocks(telegramUserID int64) (string, bool) {
+	state, active := l.State()
+	if !active || l.superusers.IsSuperuser(telegramUserID) {
+		return "", false
+	}
+	if state.Note != "" {
+		return lockdownNotice + "\n\n" + state.Note, true
+	}
+	return lockdownNotice, true
+}
+
+// Reject tells the user of the update that the lockdown keeps them out and
+// reports whether it does. Button presses get the notice as an alert.
+func (l *Lockdown) Reject(b *gotgbot.Bot, update *ext.Context) (bool, error) {
+	notice, blocked := l.Blocks(update.EffectiveUser.Id)
+	if !blocked {
+		return false, nil
+	}
+
+	var err error
+	if update.CallbackQuery != nil {
+		if runes := []rune(notice); len(runes) > callbackAlertLimit {
+			notice = string(runes[:callbackAlertLimit-1]) + "…"
+		}
+		_, err = b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text:      notice,
+			ShowAlert: true,
+		})
+	} else {
+		_, err = b.SendMessage(update.EffectiveChat.Id, notice, nil)
+	}
+	return true, err
+}
+
+// Snapshot encodes the active lockdown, if any
+func (l *Lockdown) Snapshot() ([]byte, error) {
+	state, active := l.State()
+	if !active {
+		return json.Marshal(nil)
+	}
+	return json.Marshal(state)
+}
+
+// Restore puts a saved lockdown back in place unless it has ended meanwhile
+// or another one was started since
+func (l *Lockdown) Restore(data []byte) error {
+	var state *LockdownState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to decode lockdown state: %w", err)
+	}
+	if state == nil || (!state.Until.IsZero() && time.Now().After(state.Until)) {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.state == nil {
+		l.state = state
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package manager_bot
+
+import (
+	"context"
+	"fmt"
+
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// botOpts returns the options for talking to Telegram as another bot, routing
+// requests through the configured proxy and Bot API server if set
+func (s *Service) botOpts() (*gotgbot.BotOpts, error) {
+	if !s.config.Proxy.Enabled {
+		return utils.WithBotAPIURL(nil, s.config.BotAPI.URL), nil
+	}
+	httpClient, err := utils.CreateHTTPClientWithProxy(&s.config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	return utils.WithBotAPIURL(&gotgbot.BotOpts{
+		BotClient: &gotgbot.BaseBotClient{
+			Client: *httpClient,
+		},
+	}, s.config.BotAPI.URL), nil
+}
+
+// handleRefreshBotInfo re-reads a ForwarderBot's username and Telegram ID with
+// its own token, e.g. after the username was changed in BotFather
+func (s *Service) handleRefreshBotInfo(ctx context.Context, b *gotgbot.Bot, update *ext.Context, botID uuid.UUID, isSuperuser bool) error {
+	answer := func(text string) error {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text:      text,
+			ShowAlert: true,
+		})
+		return err
+	}
+
+	if s.botInfo == nil {
+		return answer("Refreshing bot info is not available.")
+	}
+
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		return answer("Failed to load bot information")
+	}
+	oldName, oldTelegramID := bot.Name, bot.TelegramBotID
+
+	token, err := utils.DecryptToken(bot.Token, s.encryptionKey)
+	if err != nil {
+		s.logger.Error("Failed to decrypt bot token",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return answer("Failed to read the bot token.")
+	}
+
+	opts, err := s.botOpts()
+	if err != nil {
+		s.logger.Error("Failed to create proxy HTTP client", zap.Error(err))
+		return answer("Proxy configuration error. Please try again later.")
+	}
+	if opts == nil {
+		opts = &gotgbot.BotOpts{}
+	}
+	// The refresher calls GetMe itself, so skip the check done by NewBot
+	opts.DisableTokenCheck = true
+	forwarderBot, err := gotgbot.NewBot(token, opts)
+	if err != nil {
+		return answer("Failed to create bot client.")
+	}
+
+	refreshed, err := s.botInfo.Refresh(ctx, forwarderBot, botID)
+	if err != nil {
+		s.logger.Warn("Failed to refresh bot info",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return answer("Telegram rejected the bot token. It may have been revoked in BotFather.")
+	}
+
+	var notice string
+	switch {
+	case refreshed.Name != oldName:
+		notice = fmt.Sprintf("Username updated: @%s → @%s", oldName, refreshed.Name)
+	case refreshed.TelegramBotID != oldTelegramID:
+		notice = fmt.Sprintf("Telegram ID recorded: %d", refreshed.TelegramBotID)
+	default:
+		notice = "No changes. The bot info is up to date."
+	}
+	if err := answer(notice); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	// Only managers and superusers get past handleBotCallback
+	return s.showBotView(b, update, botID, !isSuperuser, isSuperuser)
+}
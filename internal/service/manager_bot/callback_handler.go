@@ -41,6 +41,10 @@ func (s *Service) handleManageCallback(ctx context.Context, b *gotgbot.Bot, upda
 		return s.handleAllBots(ctx, b, update)
 	case "all_managers":
 		return s.handleAllManagers(ctx, b, update)
+	case "leaderboard":
+		return s.handleLeaderboard(ctx, b, update, parts[1:])
+	case "stats":
+		return s.handleStatsCallback(ctx, b, update, parts[1:])
 	case "bot":
 		if len(parts) < 2 {
 			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
@@ -101,6 +105,7 @@ func (s *Service) handleBotCallback(ctx context.Context, b *gotgbot.Bot, update
 				zap.Int64("user_id", userID),
 				zap.String("bot_id", botID.String()),
 				zap.String("action", action))
+			s.accessDenied(ctx, update, "bot callback")
 			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 				Text: "You are not authorized to access this bot.",
 			})
@@ -113,6 +118,12 @@ func (s *Service) handleBotCallback(ctx context.Context, b *gotgbot.Bot, update
 		return s.handleViewBot(ctx, b, update, botID)
 	case "delete":
 		return s.handleConfirmDeleteBot(ctx, b, update, botID)
+	case "refresh":
+		return s.handleRefreshBotInfo(ctx, b, update, botID, isSuperuser)
+	case "export":
+		return s.handleExportCallback(b, update, botID, parts[2:])
+	case "deadletters":
+		return s.showDeadLetters(b, update, botID, parts[2:])
 	default:
 		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 			Text: "Unknown action",
@@ -153,6 +164,7 @@ func (s *Service) handleDeleteBotCallback(ctx context.Context, b *gotgbot.Bot, u
 			s.logger.Debug("Access denied for delete bot",
 				zap.Int64("user_id", userID),
 				zap.String("bot_id", botID.String()))
+			s.accessDenied(ctx, update, "delete bot")
 			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 				Text: "You are not authorized to delete this bot.",
 			})
@@ -188,32 +200,8 @@ func (s *Service) executeDeleteBot(ctx context.Context, b *gotgbot.Bot, update *
 		s.logger.Warn("Failed to answer callback query", zap.Error(err))
 	}
 
-	bot, err := s.botRepo.GetByID(botID)
+	bot, err := s.DeleteBot(botID)
 	if err != nil {
-		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
-			Text: "Failed to load bot information",
-		})
-		return err
-	}
-
-	// Stop the bot immediately if BotManager is available
-	if s.botManager != nil {
-		s.logger.Debug("Stopping ForwarderBot immediately",
-			zap.String("bot_id", botID.String()),
-			zap.String("bot_name", bot.Name))
-		if stopErr := s.botManager.StopBot(botID); stopErr != nil {
-			s.logger.Warn("Failed to stop ForwarderBot immediately",
-				zap.String("bot_id", botID.String()),
-				zap.Error(stopErr))
-			// Continue with deletion anyway
-		} else {
-			s.logger.Debug("ForwarderBot stopped successfully",
-				zap.String("bot_id", botID.String()),
-				zap.String("bot_name", bot.Name))
-		}
-	}
-
-	if err := s.botRepo.Delete(botID); err != nil {
 		s.logger.Error("Failed to delete bot", zap.Error(err))
 		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 			Text: "Failed to delete bot",
@@ -263,6 +251,7 @@ func (s *Service) handleManageMenu(ctx context.Context, b *gotgbot.Bot, update *
 	if !s.IsSuperuser(userID) {
 		s.logger.Debug("Access denied for manage menu",
 			zap.Int64("user_id", userID))
+		s.accessDenied(ctx, update, "manage menu")
 		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 			Text: "You are not authorized to access this.",
 		})
@@ -282,6 +271,9 @@ func (s *Service) handleManageMenu(ctx context.Context, b *gotgbot.Bot, update *
 		{
 			{Text: "View All Managers", CallbackData: "manage:all_managers"},
 		},
+		{
+			{Text: "Bot Leaderboard (7 days)", CallbackData: "manage:leaderboard:volume:0"},
+		},
 	}
 
 	messageID, err := getMessageIDFromCallback(update.CallbackQuery.Message)
@@ -318,6 +310,7 @@ func (s *Service) handleAllBots(ctx context.Context, b *gotgbot.Bot, update *ext
 	if !s.IsSuperuser(userID) {
 		s.logger.Debug("Access denied for all_bots",
 			zap.Int64("user_id", userID))
+		s.accessDenied(ctx, update, "all_bots")
 		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 			Text: "You are not authorized to access this.",
 		})
@@ -330,6 +323,12 @@ func (s *Service) handleAllBots(ctx context.Context, b *gotgbot.Bot, update *ext
 		s.logger.Warn("Failed to answer callback query", zap.Error(err))
 	}
 
+	return s.showAllBots(b, update)
+}
+
+// showAllBots renders the list of all bots into the callback's message.
+// Access must already be checked and the callback query answered by the caller.
+func (s *Service) showAllBots(b *gotgbot.Bot, update *ext.Context) error {
 	bots, err := s.botRepo.GetAll()
 	if err != nil {
 		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
@@ -396,17 +395,20 @@ func (s *Service) handleAllManagers(ctx context.Context, b *gotgbot.Bot, update
 		return err
 	}
 
-	managerMap := make(map[uuid.UUID]*models.User)
+	seen := make(map[uuid.UUID]bool)
+	var managerIDs []uuid.UUID
 	for _, bot := range bots {
-		if _, exists := managerMap[bot.ManagerID]; !exists {
-			manager, err := s.userRepo.GetByID(bot.ManagerID)
-			if err == nil {
-				managerMap[bot.ManagerID] = manager
-			}
+		if !seen[bot.ManagerID] {
+			seen[bot.ManagerID] = true
+			managerIDs = append(managerIDs, bot.ManagerID)
 		}
 	}
+	managers, err := s.userRepo.GetByIDs(managerIDs)
+	if err != nil {
+		s.logger.Warn("Failed to load managers", zap.Error(err))
+	}
 
-	if len(managerMap) == 0 {
+	if len(managers) == 0 {
 		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 			Text: "No managers found",
 		})
@@ -414,7 +416,7 @@ func (s *Service) handleAllManagers(ctx context.Context, b *gotgbot.Bot, update
 	}
 
 	var buttons [][]gotgbot.InlineKeyboardButton
-	for _, manager := range managerMap {
+	for _, manager := range managers {
 		username := "Unknown"
 		if manager.Username != nil {
 			username = *manager.Username
@@ -524,6 +526,10 @@ func (s *Service) handleViewManager(ctx context.Context, b *gotgbot.Bot, update
 		len(bots),
 	)
 
+	if manager.IsSuspended() {
+		message += fmt.Sprintf("\nStatus: Suspended since %s", utils.FormatTimestamp(*manager.SuspendedAt, s.userLocation(update.EffectiveUser.Id)))
+	}
+
 	if stats != nil && len(stats.Bots) > 0 {
 		totalInbound := int64(0)
 		totalOutbound := int64(0)
@@ -535,12 +541,12 @@ func (s *Service) handleViewManager(ctx context.Context, b *gotgbot.Bot, update
 		}
 		message += fmt.Sprintf(
 			"\n\n*Statistics*\n"+
-				"Total Inbound: %d\n"+
-				"Total Outbound: %d\n"+
-				"Total Guests: %d",
-			totalInbound,
-			totalOutbound,
-			totalGuests,
+				"Total Inbound: %s\n"+
+				"Total Outbound: %s\n"+
+				"Total Guests: %s",
+			utils.FormatNumber(totalInbound),
+			utils.FormatNumber(totalOutbound),
+			utils.FormatNumber(totalGuests),
 		)
 	}
 
@@ -615,6 +621,7 @@ func (s *Service) handleViewBot(ctx context.Context, b *gotgbot.Bot, update *ext
 			s.logger.Debug("Access denied for bot view",
 				zap.Int64("user_id", userID),
 				zap.String("bot_id", botID.String()))
+			s.accessDenied(ctx, update, "bot view")
 			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 				Text: "You are not authorized to view this bot.",
 			})
@@ -628,6 +635,12 @@ func (s *Service) handleViewBot(ctx context.Context, b *gotgbot.Bot, update *ext
 		s.logger.Warn("Failed to answer callback query", zap.Error(err))
 	}
 
+	return s.showBotView(b, update, botID, isManager, isSuperuser)
+}
+
+// showBotView renders the bot view into the callback's message. Permissions must
+// already be checked and the callback query answered by the caller.
+func (s *Service) showBotView(b *gotgbot.Bot, update *ext.Context, botID uuid.UUID, isManager, isSuperuser bool) error {
 	bot, err := s.botRepo.GetByID(botID)
 	if err != nil {
 		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
@@ -648,23 +661,94 @@ func (s *Service) handleViewBot(ctx context.Context, b *gotgbot.Bot, update *ext
 			"Created: %s",
 		utils.EscapeMarkdown(bot.Name),
 		bot.Manager.TelegramUserID,
-		bot.CreatedAt.Format("2006-01-02 15:04:05"),
+		utils.FormatTimestamp(bot.CreatedAt, s.userLocation(update.EffectiveUser.Id)),
 	)
 
 	if stats != nil {
 		message += fmt.Sprintf(
 			"\n\n*Statistics*\n"+
-				"Inbound: %d\n"+
-				"Outbound: %d\n"+
-				"Guests: %d",
-			stats.InboundCount,
-			stats.OutboundCount,
-			stats.GuestCount,
+				"Inbound: %s\n"+
+				"Outbound: %s\n"+
+				"Guests: %s",
+			utils.FormatNumber(stats.InboundCount),
+			utils.FormatNumber(stats.OutboundCount),
+			utils.FormatNumber(stats.GuestCount),
+		)
+	}
+
+	if usage := s.quotaUsageLine(bot); usage != "" {
+		message += "\n" + utils.EscapeMarkdown(usage)
+	}
+
+	trend, err := s.statsService.GetBotTrend(botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot trend", zap.Error(err))
+	} else {
+		message += fmt.Sprintf(
+			"\n\n*Last 7 Days*\n"+
+				"Inbound: %s (%s)\n"+
+				"Outbound: %s (%s)\n"+
+				"New Guests: %s (%s)\n"+
+				"Failures: %s (%s)",
+			utils.FormatNumber(trend.Inbound.Current), trend.Inbound.Summary(),
+			utils.FormatNumber(trend.Outbound.Current), trend.Outbound.Summary(),
+			utils.FormatNumber(trend.NewGuests.Current), trend.NewGuests.Summary(),
+			utils.FormatNumber(trend.Failures.Current), trend.Failures.Summary(),
 		)
 	}
 
-	// Only show Delete Bot button if user is the manager or superuser
+	recipients, err := s.recipientRepo.GetByBotID(botID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot recipients", zap.Error(err))
+	} else if len(recipients) > 0 {
+		message += "\n\n*Recipients*"
+		for _, recipient := range recipients {
+			message += fmt.Sprintf("\n- %s: %s", recipient.RecipientType, utils.EscapeMarkdown(recipient.Label()))
+			if recipient.IsFallback {
+				message += " (fallback)"
+			}
+		}
+	}
+
+	// Only show Settings and Delete Bot buttons if user is the manager or superuser
 	buttons := [][]gotgbot.InlineKeyboardButton{}
+	if (isManager || isSuperuser) && s.settings != nil {
+		buttons = append(buttons, []gotgbot.InlineKeyboardButton{
+			{
+				Text:         "Settings",
+				CallbackData: fmt.Sprintf("settings:%s", botID.String()),
+			},
+		})
+	}
+	if (isManager || isSuperuser) && s.botInfo != nil {
+		buttons = append(buttons, []gotgbot.InlineKeyboardButton{
+			{
+				Text:         "Refresh info",
+				CallbackData: fmt.Sprintf("bot:refresh:%s", botID.String()),
+			},
+		})
+	}
+	if isManager || isSuperuser {
+		row := []gotgbot.InlineKeyboardButton{
+			{
+				Text:         "Export",
+				CallbackData: fmt.Sprintf("bot:export:%s", botID.String()),
+			},
+		}
+		if s.deadLetters != nil {
+			text := "Dead Letters"
+			if count, err := s.deadLetters.CountByBotID(botID); err != nil {
+				s.logger.Warn("Failed to count dead letters", zap.Error(err))
+			} else if count > 0 {
+				text = fmt.Sprintf("Dead Letters (%d)", count)
+			}
+			row = append(row, gotgbot.InlineKeyboardButton{
+				Text:         text,
+				CallbackData: fmt.Sprintf("bot:deadletters:%s:0", botID.String()),
+			})
+		}
+		buttons = append(buttons, row)
+	}
 	if isManager || isSuperuser {
 		buttons = append(buttons, []gotgbot.InlineKeyboardButton{
 			{
@@ -721,14 +805,20 @@ func (s *Service) handleViewBot(ctx context.Context, b *gotgbot.Bot, update *ext
 }
 
 func (s *Service) handleMyBotsCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
-	userID := update.EffectiveUser.Id
-
 	// Answer callback query first
 	_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{})
 	if err != nil {
 		s.logger.Warn("Failed to answer callback query", zap.Error(err))
 	}
 
+	return s.showMyBots(b, update)
+}
+
+// showMyBots renders the user's /mybots list into the callback's message.
+// The callback query must already be answered by the caller.
+func (s *Service) showMyBots(b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+
 	// Get or create user
 	username := update.EffectiveUser.Username
 	var usernamePtr *string
@@ -1,20 +1,23 @@
 package manager_bot
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 
-	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/i18n"
+	"go-telegram-forwarder-bot/internal/service/statistics"
 	"go-telegram-forwarder-bot/internal/utils"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 )
 
+// botFatherTokenGuidance tells managers where to find a bot's token
+const botFatherTokenGuidance = "To get the token, open @BotFather, send /mybots, choose your bot and tap \"API Token\". Then send:\n/addbot <token>"
+
 func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
 	userID := update.EffectiveUser.Id
 	chatID := update.EffectiveChat.Id
@@ -35,39 +38,29 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 		return err
 	}
 
-	// Send "please wait" message first
-	waitMsg, err := b.SendMessage(update.EffectiveChat.Id,
-		"⏳ Processing, please wait...", nil)
+	// Check the token before contacting Telegram so paste mistakes get a useful answer
+	token := strings.TrimSpace(strings.TrimPrefix(update.EffectiveMessage.Text, parts[0]))
+	tokenBotID, err := utils.ParseBotToken(token)
 	if err != nil {
-		s.logger.Warn("Failed to send wait message", zap.Error(err))
-		// Continue anyway, but we won't be able to update the message
-	}
-	var waitMessageID int64
-	if waitMsg != nil {
-		waitMessageID = waitMsg.MessageId
-		s.logger.Debug("Wait message sent",
+		s.logger.Debug("Malformed bot token",
 			zap.Int64("user_id", userID),
-			zap.Int64("message_id", waitMessageID))
-	}
-
-	// Helper function to update wait message
-	updateWaitMessage := func(text string) {
-		if waitMessageID > 0 {
-			_, _, editErr := b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
-				ChatId:    update.EffectiveChat.Id,
-				MessageId: waitMessageID,
-				ParseMode: "Markdown",
-			})
-			if editErr != nil {
-				s.logger.Warn("Failed to update wait message",
-					zap.Int64("user_id", userID),
-					zap.Int64("message_id", waitMessageID),
-					zap.Error(editErr))
-			}
-		}
+			zap.Error(err))
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("❌ That doesn't look like a bot token: %v.\n\n%s", err, botFatherTokenGuidance), nil)
+		return err
 	}
+	if tokenBotID == b.Id || token == s.config.ManagerBot.Token {
+		s.logger.Debug("ManagerBot token sent to /addbot",
+			zap.Int64("user_id", userID))
+		_, err := b.SendMessage(chatID,
+			"❌ This is the token of this ManagerBot itself. Create a separate bot with @BotFather (/newbot) and register that one.", nil)
+		return err
+	}
+
+	// Keep the manager informed while Telegram and the database are contacted
+	progress := s.startProgress(b, chatID, "Markdown")
+	defer progress.Close()
 
-	token := parts[1]
 	tokenPrefix := token
 	if len(token) > 10 {
 		tokenPrefix = token[:10] + "..."
@@ -83,36 +76,24 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 		zap.Int64("user_id", userID),
 		zap.Bool("proxy_enabled", s.config.Proxy.Enabled))
 
-	var botOpts *gotgbot.BotOpts
 	if s.config.Proxy.Enabled {
 		s.logger.Debug("Creating HTTP client with proxy",
 			zap.Int64("user_id", userID),
 			zap.String("proxy_url", s.config.Proxy.URL))
-		httpClient, err := utils.CreateHTTPClientWithProxy(&s.config.Proxy)
-		if err != nil {
-			// If proxy is enabled but creation fails, return error immediately
-			// Do not fallback to direct connection to avoid timeout issues
-			s.logger.Error("Failed to create proxy HTTP client",
-				zap.Int64("user_id", userID),
-				zap.String("proxy_url", s.config.Proxy.URL),
-				zap.Error(err))
-			updateWaitMessage(fmt.Sprintf("❌ Proxy configuration error: `%s`", utils.EscapeMarkdown(err.Error())))
-			return fmt.Errorf("failed to create proxy HTTP client: %w", err)
-		}
-
-		botClient := &gotgbot.BaseBotClient{
-			Client:             *httpClient,
-			UseTestEnvironment: false,
-			DefaultRequestOpts: nil,
-		}
-		botOpts = &gotgbot.BotOpts{
-			BotClient: botClient,
-		}
-		s.logger.Debug("Proxy HTTP client created successfully",
+	}
+	botOpts, err := s.botOpts()
+	if err != nil {
+		// If proxy is enabled but creation fails, return error immediately
+		// Do not fallback to direct connection to avoid timeout issues
+		s.logger.Error("Failed to create proxy HTTP client",
 			zap.Int64("user_id", userID),
-			zap.String("proxy_url", s.config.Proxy.URL))
+			zap.String("proxy_url", s.config.Proxy.URL),
+			zap.Error(err))
+		progress.Finish(fmt.Sprintf("❌ Proxy configuration error: `%s`", utils.EscapeMarkdown(err.Error())))
+		return fmt.Errorf("failed to create proxy HTTP client: %w", err)
 	}
 
+	progress.Stage("Validating token...")
 	s.logger.Debug("Creating bot instance for validation",
 		zap.Int64("user_id", userID))
 	testBot, err := gotgbot.NewBot(token, botOpts)
@@ -120,7 +101,7 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 		s.logger.Debug("Failed to create bot instance for validation",
 			zap.Int64("user_id", userID),
 			zap.Error(err))
-		updateWaitMessage(fmt.Sprintf("❌ Invalid bot token: `%v`", utils.EscapeMarkdown(fmt.Sprintf("%v", err))))
+		progress.Finish(fmt.Sprintf("❌ Invalid bot token: `%v`", utils.EscapeMarkdown(fmt.Sprintf("%v", err))))
 		return err
 	}
 
@@ -131,7 +112,7 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 		s.logger.Debug("Failed to verify bot token via GetMe",
 			zap.Int64("user_id", userID),
 			zap.Error(err))
-		updateWaitMessage(fmt.Sprintf("❌ Failed to verify bot token: `%s`", utils.EscapeMarkdown(fmt.Sprintf("%v", err))))
+		progress.Finish(fmt.Sprintf("❌ Failed to verify bot token: `%s`", utils.EscapeMarkdown(fmt.Sprintf("%v", err))))
 		return err
 	}
 
@@ -156,43 +137,50 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 		usernamePtr)
 	if err != nil {
 		s.logger.Error("Failed to get or create user", zap.Error(err))
-		updateWaitMessage("❌ An error occurred. Please try again later.")
+		progress.Finish("❌ An error occurred. Please try again later.")
 		return err
 	}
 	s.logger.Debug("User retrieved/created",
 		zap.Int64("user_id", userID),
 		zap.String("user_uuid", user.ID.String()))
 
-	// Check if bot already exists by trying to encrypt and compare
-	// Since tokens are encrypted, we need to check by bot username or ID
-	// For now, we'll check after encryption by comparing all bots
-	// This is not perfect but works for the use case
-	s.logger.Debug("Checking if bot already exists",
-		zap.Int64("user_id", userID),
-		zap.String("bot_username", botInfo.Username))
-	allBots, err := s.botRepo.GetAll()
-	if err == nil {
-		s.logger.Debug("Retrieved all bots for duplicate check",
-			zap.Int64("user_id", userID),
-			zap.Int("total_bots", len(allBots)))
-		for _, existingBot := range allBots {
-			decryptedToken, decryptErr := utils.DecryptToken(existingBot.Token, s.encryptionKey)
-			if decryptErr == nil && decryptedToken == token {
-				s.logger.Debug("Bot already exists",
-					zap.Int64("user_id", userID),
-					zap.String("bot_username", botInfo.Username),
-					zap.String("existing_bot_id", existingBot.ID.String()))
-				updateWaitMessage(fmt.Sprintf("❌ Bot @%s is already registered.", utils.EscapeMarkdown(botInfo.Username)))
-				return fmt.Errorf("bot already exists")
-			}
+	if user.IsSuspended() {
+		s.logger.Debug("Suspended manager attempted to register a bot",
+			zap.Int64("user_id", userID))
+		progress.Finish("❌ Your account is suspended. You cannot register new bots.")
+		return fmt.Errorf("manager is suspended")
+	}
+
+	if s.featureFlags != nil {
+		allowed, limit, err := s.featureFlags.CanAddBot(user)
+		if err != nil {
+			s.logger.Error("Failed to check bot limit", zap.Error(err))
+			progress.Finish("❌ An error occurred. Please try again later.")
+			return err
 		}
-		s.logger.Debug("No duplicate bot found",
-			zap.Int64("user_id", userID),
-			zap.String("bot_username", botInfo.Username))
-	} else {
-		s.logger.Debug("Failed to get all bots for duplicate check, continuing",
-			zap.Int64("user_id", userID),
-			zap.Error(err))
+		if !allowed {
+			s.logger.Debug("Manager reached the bot limit of their tier",
+				zap.Int64("user_id", userID),
+				zap.Int("max_bots", limit))
+			progress.Finish(fmt.Sprintf("❌ Your %s tier allows up to %d bots. Remove a bot or ask an administrator to upgrade your tier.",
+				s.featureFlags.EffectiveTier(user), limit))
+			return fmt.Errorf("bot limit reached")
+		}
+	}
+
+	// Registering the ManagerBot would make two pollers fight over its updates
+	if botInfo.Id == b.Id {
+		s.logger.Debug("ManagerBot token sent to /addbot",
+			zap.Int64("user_id", userID))
+		progress.Finish("❌ This is the token of this ManagerBot itself. Create a separate bot with @BotFather (/newbot) and register that one.")
+		return fmt.Errorf("token belongs to the ManagerBot")
+	}
+
+	progress.Stage("Checking for duplicates...")
+
+	if existingBot := s.findRegisteredBot(userID, botInfo); existingBot != nil {
+		progress.Finish(fmt.Sprintf("❌ Bot @%s is already registered.", utils.EscapeMarkdown(botInfo.Username)))
+		return fmt.Errorf("bot already exists")
 	}
 
 	// Encrypt token
@@ -202,7 +190,7 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 	encryptedToken, err := utils.EncryptToken(token, s.encryptionKey)
 	if err != nil {
 		s.logger.Error("Failed to encrypt token", zap.Error(err))
-		updateWaitMessage("❌ An error occurred. Please try again later.")
+		progress.Finish("❌ An error occurred. Please try again later.")
 		return err
 	}
 	s.logger.Debug("Bot token encrypted successfully",
@@ -210,106 +198,16 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 		zap.String("bot_username", botInfo.Username),
 		zap.Int("encrypted_length", len(encryptedToken)))
 
-	// Create bot with transaction to ensure data consistency
-	forwarderBot := &models.ForwarderBot{
-		Token:     encryptedToken,
-		Name:      botInfo.Username,
-		ManagerID: user.ID,
-	}
-
-	s.logger.Debug("Starting transaction for bot creation",
-		zap.Int64("user_id", userID),
-		zap.String("bot_username", botInfo.Username),
-		zap.String("manager_id", user.ID.String()))
-
-	// Use transaction to ensure atomicity of bot creation, recipient creation, and audit logging
-	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Create transaction-aware repositories
-		txBotRepo := s.botRepo.WithTx(tx)
-		txRecipientRepo := s.recipientRepo.WithTx(tx)
-		txAuditRepo := s.auditLogRepo.WithTx(tx)
+	progress.Stage("Registering bot...")
 
-		// 1. Create bot
-		s.logger.Debug("Creating ForwarderBot record in transaction",
-			zap.Int64("user_id", userID),
-			zap.String("bot_username", botInfo.Username))
-		if err := txBotRepo.Create(forwarderBot); err != nil {
-			s.logger.Error("Failed to create bot in transaction", zap.Error(err))
-			return fmt.Errorf("failed to create bot: %w", err)
-		}
-
-		s.logger.Debug("ForwarderBot created successfully in transaction",
-			zap.Int64("user_id", userID),
-			zap.String("bot_id", forwarderBot.ID.String()),
-			zap.String("bot_username", forwarderBot.Name))
-
-		// 2. Add manager as recipient automatically
-		s.logger.Debug("Adding manager as recipient in transaction",
-			zap.Int64("user_id", userID),
-			zap.String("bot_id", forwarderBot.ID.String()),
-			zap.Int64("manager_telegram_user_id", user.TelegramUserID))
-
-		// Check if recipient already exists (using transaction-aware repo)
-		existingRecipient, err := txRecipientRepo.GetByBotIDAndChatID(forwarderBot.ID, user.TelegramUserID)
-		if err == nil && existingRecipient != nil {
-			s.logger.Debug("Manager is already a recipient, skipping",
-				zap.Int64("user_id", userID),
-				zap.String("bot_id", forwarderBot.ID.String()))
-		} else {
-			// Create recipient for manager
-			recipient := &models.Recipient{
-				BotID:         forwarderBot.ID,
-				RecipientType: models.RecipientTypeUser,
-				ChatID:        user.TelegramUserID,
-			}
-
-			if err := txRecipientRepo.Create(recipient); err != nil {
-				s.logger.Error("Failed to add manager as recipient in transaction",
-					zap.Int64("user_id", userID),
-					zap.String("bot_id", forwarderBot.ID.String()),
-					zap.Error(err))
-				// Return error to rollback bot creation
-				return fmt.Errorf("failed to add manager as recipient: %w", err)
-			}
-
-			s.logger.Debug("Manager added as recipient successfully in transaction",
-				zap.Int64("user_id", userID),
-				zap.String("bot_id", forwarderBot.ID.String()),
-				zap.String("recipient_id", recipient.ID.String()))
-		}
-
-		// 3. Log audit
-		s.logger.Debug("Creating audit log in transaction",
-			zap.Int64("user_id", userID),
-			zap.String("bot_id", forwarderBot.ID.String()))
-		details, _ := json.Marshal(map[string]interface{}{
-			"bot_id":   forwarderBot.ID.String(),
-			"bot_name": forwarderBot.Name,
-		})
-		auditLog := &models.AuditLog{
-			UserID:       &user.ID,
-			ActionType:   models.AuditLogActionAddBot,
-			ResourceType: "bot",
-			ResourceID:   forwarderBot.ID,
-			Details:      string(details),
-		}
-		if err := txAuditRepo.Create(auditLog); err != nil {
-			s.logger.Error("Failed to create audit log in transaction",
-				zap.Int64("user_id", userID),
-				zap.String("bot_id", forwarderBot.ID.String()),
-				zap.Error(err))
-			return fmt.Errorf("failed to create audit log: %w", err)
-		}
-
-		return nil // Transaction committed
-	})
+	forwarderBot, err := s.createBot(userID, user, botInfo, encryptedToken)
 
 	if err != nil {
 		s.logger.Error("Transaction failed for bot creation",
 			zap.Int64("user_id", userID),
 			zap.String("bot_username", botInfo.Username),
 			zap.Error(err))
-		updateWaitMessage("❌ Failed to register bot due to database error. Please try again later.")
+		progress.Finish("❌ Failed to register bot due to database error. Please try again later.")
 		return err
 	}
 
@@ -323,6 +221,7 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 
 	// Start the bot immediately if BotManager is available
 	if s.botManager != nil {
+		progress.Stage("Starting bot...")
 		s.logger.Debug("Starting ForwarderBot immediately",
 			zap.Int64("user_id", userID),
 			zap.String("bot_id", forwarderBot.ID.String()),
@@ -333,7 +232,7 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 				zap.String("bot_id", forwarderBot.ID.String()),
 				zap.Error(startErr))
 			// Continue anyway - bot will be started on next restart
-			updateWaitMessage(fmt.Sprintf("⚠️ Bot @%s has been registered, but failed to start immediately. It will be started on next application restart.", utils.EscapeMarkdown(forwarderBot.Name)))
+			progress.Finish(fmt.Sprintf("⚠️ Bot @%s has been registered, but failed to start immediately. It will be started on next application restart.", utils.EscapeMarkdown(forwarderBot.Name)))
 			return startErr
 		}
 		s.logger.Debug("ForwarderBot started successfully",
@@ -349,7 +248,7 @@ func (s *Service) handleAddBot(ctx context.Context, b *gotgbot.Bot, update *ext.
 	s.logger.Debug("Updating wait message to success message",
 		zap.Int64("user_id", userID),
 		zap.String("bot_username", forwarderBot.Name))
-	updateWaitMessage(fmt.Sprintf("✅ Bot @%s has been successfully registered and started!", utils.EscapeMarkdown(forwarderBot.Name)))
+	progress.Finish(fmt.Sprintf("✅ Bot @%s has been successfully registered and started!", utils.EscapeMarkdown(forwarderBot.Name)))
 	s.logger.Debug("Success message updated",
 		zap.Int64("user_id", userID),
 		zap.String("bot_username", forwarderBot.Name))
@@ -432,8 +331,12 @@ func (s *Service) handleMyBots(ctx context.Context, b *gotgbot.Bot, update *ext.
 		zap.Int64("user_id", userID),
 		zap.Int("button_count", len(buttons)))
 	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons}
+	text := "Select a bot to manage:"
+	if summary := s.tierSummary(user, len(bots)); summary != "" {
+		text = summary + "\n\n" + text
+	}
 	_, err = b.SendMessage(update.EffectiveChat.Id,
-		"Select a bot to manage:", &gotgbot.SendMessageOpts{
+		text, &gotgbot.SendMessageOpts{
 			ReplyMarkup: keyboard,
 		})
 	if err != nil {
@@ -456,9 +359,7 @@ func (s *Service) handleStats(ctx context.Context, b *gotgbot.Bot, update *ext.C
 		zap.Int64("user_id", userID),
 		zap.Int64("chat_id", chatID))
 
-	s.logger.Debug("Retrieving global statistics",
-		zap.Int64("user_id", userID))
-	stats, err := s.statsService.GetGlobalStatistics()
+	message, err := s.globalStatsText(userID)
 	if err != nil {
 		s.logger.Error("Failed to get statistics", zap.Error(err))
 		_, err := b.SendMessage(update.EffectiveChat.Id,
@@ -466,43 +367,64 @@ func (s *Service) handleStats(ctx context.Context, b *gotgbot.Bot, update *ext.C
 		return err
 	}
 
-	s.logger.Debug("Global statistics retrieved",
-		zap.Int64("user_id", userID),
-		zap.Int64("manager_count", stats.ManagerCount),
-		zap.Int64("bot_count", stats.BotCount),
-		zap.Int64("total_inbound", stats.TotalInbound),
-		zap.Int64("total_outbound", stats.TotalOutbound),
-		zap.Int64("total_guests", stats.TotalGuestCount))
-
-	message := fmt.Sprintf(
-		"*Global Statistics*\n\n"+
-			"Managers: %d\n"+
-			"Bots: %d\n"+
-			"Inbound Messages: %d\n"+
-			"Outbound Messages: %d\n"+
-			"Total Guests: %d",
-		stats.ManagerCount,
-		stats.BotCount,
-		stats.TotalInbound,
-		stats.TotalOutbound,
-		stats.TotalGuestCount,
-	)
-
 	s.logger.Debug("Sending statistics message",
 		zap.Int64("user_id", userID),
 		zap.Int64("chat_id", chatID))
 	_, err = b.SendMessage(update.EffectiveChat.Id, message, &gotgbot.SendMessageOpts{
-		ParseMode: "Markdown",
+		ParseMode:   "Markdown",
+		ReplyMarkup: statsDrillDownKeyboard(),
 	})
 	if err != nil {
 		s.logger.Debug("Failed to send statistics message",
 			zap.Int64("user_id", userID),
 			zap.Error(err))
-	} else {
-		s.logger.Debug("Statistics message sent successfully",
-			zap.Int64("user_id", userID))
+		return err
+	}
+	s.logger.Debug("Statistics message sent successfully",
+		zap.Int64("user_id", userID))
+
+	series, err := s.statsService.GetGlobalDailySeries()
+	if err != nil {
+		s.logger.Warn("Failed to get daily statistics", zap.Error(err))
+		return nil
+	}
+	s.sendStatsCharts(b, chatID, series)
+	return nil
+}
+
+// sendStatsCharts sends the daily message and guest charts as one photo album.
+// Charts are best effort: failures are logged and the text statistics stand alone.
+func (s *Service) sendStatsCharts(b *gotgbot.Bot, chatID int64, series *statistics.DailySeries) {
+	if !series.HasData {
+		return
+	}
+
+	messagesChart, err := statistics.RenderMessagesChart(series)
+	if err != nil {
+		s.logger.Warn("Failed to render messages chart", zap.Error(err))
+		return
+	}
+	guestsChart, err := statistics.RenderGuestsChart(series)
+	if err != nil {
+		s.logger.Warn("Failed to render guests chart", zap.Error(err))
+		return
+	}
+
+	media := []gotgbot.InputMedia{
+		gotgbot.InputMediaPhoto{
+			Media:   gotgbot.InputFileByReader("messages.png", bytes.NewReader(messagesChart)),
+			Caption: "Messages per day (last 14 days)",
+		},
+		gotgbot.InputMediaPhoto{
+			Media:   gotgbot.InputFileByReader("guests.png", bytes.NewReader(guestsChart)),
+			Caption: "Guest growth (last 14 days)",
+		},
+	}
+	if _, err := b.SendMediaGroup(chatID, media, nil); err != nil {
+		s.logger.Warn("Failed to send statistics charts",
+			zap.Int64("chat_id", chatID),
+			zap.Error(err))
 	}
-	return err
 }
 
 func (s *Service) handleManage(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
@@ -556,21 +478,26 @@ func (s *Service) handleHelp(ctx context.Context, b *gotgbot.Bot, update *ext.Co
 		zap.Int64("user_id", userID),
 		zap.Bool("is_superuser", isSuperuser))
 
-	helpText := "*ManagerBot Commands*\n\n"
-	helpText += "*/help* - Show this help message\n"
-	helpText += "*/addbot <token>* - Register a new ForwarderBot\n"
-	helpText += "*/mybots* - List all your ForwarderBots\n"
+	lang := s.userLanguage(update.EffectiveUser)
+	helpText := i18n.T(lang, "manager.help.title") + "\n\n"
+	for _, key := range []string{"help", "addbot", "mybots", "settoken", "retryqueue", "replay", "timezone", "language", "settings", "importsettings"} {
+		helpText += i18n.T(lang, "manager.help."+key) + "\n"
+	}
 
 	if isSuperuser {
-		helpText += "\n*Superuser Commands:*\n"
-		helpText += "*/manage* - Open management menu\n"
-		helpText += "*/stats* - View global statistics\n"
+		helpText += "\n" + i18n.T(lang, "manager.help.superuser_title") + "\n"
+		for _, key := range []string{"manage", "stats", "suspendmanager", "unsuspendmanager", "reports", "setquota", "usage_cmd", "registry", "settier", "addsuper", "delsuper", "lockdown"} {
+			helpText += i18n.T(lang, "manager.help."+key) + "\n"
+		}
 	}
 
-	helpText += "\n*Usage:*\n"
-	helpText += "1. Use /addbot to register a ForwarderBot\n"
-	helpText += "2. Use /mybots to manage your bots\n"
-	helpText += "3. Each ForwarderBot can forward messages between Guests and Recipients"
+	helpText += "\n" + i18n.T(lang, "manager.help.usage_title") + "\n"
+	helpText += i18n.T(lang, "manager.help.step1") + "\n"
+	helpText += i18n.T(lang, "manager.help.step2") + "\n"
+	helpText += i18n.T(lang, "manager.help.step3")
+	if update.EffectiveChat.Type != "private" {
+		helpText += "\n\n" + i18n.T(lang, "manager.help.groups")
+	}
 
 	s.logger.Debug("Sending help message",
 		zap.Int64("user_id", userID),
@@ -0,0 +1,310 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// deadLetterPageSize is how many dead letters one page of the list shows
+const deadLetterPageSize = 5
+
+// deadLetterErrorLength is how much of a dead letter's error the list shows
+const deadLetterErrorLength = 100
+
+// showDeadLetters replaces the bot view with a page of the bot's dead letters.
+// parts are the callback parts after the bot ID: [page].
+func (s *Service) showDeadLetters(b *gotgbot.Bot, update *ext.Context, botID uuid.UUID, parts []string) error {
+	if s.deadLetters == nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Dead letters are not available",
+		})
+		return err
+	}
+	page := 0
+	if len(parts) >= 1 {
+		if p, err := strconv.Atoi(parts[0]); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to load bot information",
+		})
+		return err
+	}
+	count, err := s.deadLetters.CountByBotID(botID)
+	if err != nil {
+		s.logger.Error("Failed to count dead letters", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to load dead letters",
+		})
+		return err
+	}
+	page, totalPages, start, _ := pageBounds(int(count), page, deadLetterPageSize)
+	deadLetters, err := s.deadLetters.GetByBotID(botID, start, deadLetterPageSize)
+	if err != nil {
+		s.logger.Error("Failed to load dead letters", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to load dead letters",
+		})
+		return err
+	}
+
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	loc := s.userLocation(update.EffectiveUser.Id)
+	var text strings.Builder
+	fmt.Fprintf(&text, "*Dead Letters of @%s*\nPage %d/%d\n", utils.EscapeMarkdown(bot.Name), page+1, totalPages)
+	if count == 0 {
+		text.WriteString("\nNo undelivered messages. Messages that cannot be delivered to a recipient " +
+			"and are not retried any more are kept here.")
+	}
+
+	var buttons [][]gotgbot.InlineKeyboardButton
+	for i, deadLetter := range deadLetters {
+		fmt.Fprintf(&text, "\n%d. Guest `%d`, message %d to `%d`\n   %s, %s\n   %s",
+			start+i+1,
+			deadLetter.GuestChatID,
+			deadLetter.GuestMessageID,
+			deadLetter.RecipientChatID,
+			utils.EscapeMarkdown(deadLetter.Reason),
+			utils.FormatTimestamp(deadLetter.CreatedAt, loc),
+			utils.EscapeMarkdown(truncateRunes(deadLetter.LastError, deadLetterErrorLength)))
+		buttons = append(buttons, []gotgbot.InlineKeyboardButton{
+			{
+				Text:         fmt.Sprintf("%d. Guest %d, message %d", start+i+1, deadLetter.GuestChatID, deadLetter.GuestMessageID),
+				CallbackData: fmt.Sprintf("deadletter:view:%s", deadLetter.ID),
+			},
+		})
+	}
+
+	if navigation := pageNavigation(page, totalPages, func(page int) string {
+		return fmt.Sprintf("bot:deadletters:%s:%d", botID, page)
+	}); len(navigation) > 0 {
+		buttons = append(buttons, navigation)
+	}
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{{Text: "Back", CallbackData: fmt.Sprintf("bot:view:%s", botID)}})
+	return s.editCallbackMessage(b, update, text.String(), buttons)
+}
+
+// handleDeadLetterCallback shows, requeues or discards one dead letter.
+// parts are the callback parts after "deadletter": [action, dead letter ID].
+// The bot is taken from the dead letter, which keeps the data short enough
+// for Telegram's callback data limit.
+func (s *Service) handleDeadLetterCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	userID := update.EffectiveUser.Id
+
+	if len(parts) < 2 || s.deadLetters == nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+
+	deadLetter, err := s.deadLetters.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "This message was requeued or discarded already.",
+		})
+		return err
+	}
+	if err != nil {
+		s.logger.Error("Failed to get dead letter", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to load the message",
+		})
+		return err
+	}
+
+	if !s.IsSuperuser(userID) {
+		isManager, err := s.IsBotManager(userID, deadLetter.BotID)
+		if err != nil {
+			s.logger.Warn("Failed to check bot manager status", zap.Error(err))
+			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "Failed to verify permissions",
+			})
+			return err
+		}
+		if !isManager {
+			s.logger.Debug("Access denied for dead letter callback",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", deadLetter.BotID.String()))
+			s.accessDenied(ctx, update, "dead letter callback")
+			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "You are not authorized to access this bot.",
+			})
+			return err
+		}
+	}
+
+	switch parts[0] {
+	case "view":
+		return s.showDeadLetter(b, update, deadLetter)
+	case "requeue":
+		if _, err := s.deadLetters.Requeue(deadLetter.BotID, deadLetter.ID, time.Now()); err != nil {
+			s.logger.Error("Failed to requeue dead letter",
+				zap.String("dead_letter_id", deadLetter.ID.String()),
+				zap.Error(err))
+			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "Failed to requeue the message",
+			})
+			return err
+		}
+		s.logger.Info("Dead letter requeued",
+			zap.String("bot_id", deadLetter.BotID.String()),
+			zap.String("dead_letter_id", deadLetter.ID.String()),
+			zap.Int64("user_id", userID))
+		s.recordDeadLetterAudit(update, deadLetter, models.AuditLogActionRequeueDeadLetter)
+		return s.afterDeadLetterAction(b, update, deadLetter.BotID, "Moved to the retry queue")
+	case "discard":
+		if err := s.deadLetters.Delete(deadLetter.BotID, deadLetter.ID); err != nil {
+			s.logger.Error("Failed to discard dead letter",
+				zap.String("dead_letter_id", deadLetter.ID.String()),
+				zap.Error(err))
+			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "Failed to discard the message",
+			})
+			return err
+		}
+		s.logger.Info("Dead letter discarded",
+			zap.String("bot_id", deadLetter.BotID.String()),
+			zap.String("dead_letter_id", deadLetter.ID.String()),
+			zap.Int64("user_id", userID))
+		s.recordDeadLetterAudit(update, deadLetter, models.AuditLogActionDiscardDeadLetter)
+		return s.afterDeadLetterAction(b, update, deadLetter.BotID, "Discarded")
+	default:
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Unknown action",
+		})
+		return err
+	}
+}
+
+// showDeadLetter shows one dead letter with its Requeue and Discard buttons
+func (s *Service) showDeadLetter(b *gotgbot.Bot, update *ext.Context, deadLetter *models.DeadLetter) error {
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	attempts := fmt.Sprintf("%d", deadLetter.Attempts)
+	if deadLetter.QueueAttempts > 0 {
+		attempts = fmt.Sprintf("%d in the retry queue", deadLetter.QueueAttempts)
+	}
+	text := fmt.Sprintf("*Dead Letter*\n\n"+
+		"Guest: `%d`\n"+
+		"Message: %d\n"+
+		"Recipient: `%d`\n"+
+		"Failed: %s\n"+
+		"Reason: %s\n"+
+		"Attempts: %s\n"+
+		"Error: %s\n\n"+
+		"Requeue moves the message to the retry queue, which delivers it on its next run. "+
+		"Discard deletes it for good.",
+		deadLetter.GuestChatID,
+		deadLetter.GuestMessageID,
+		deadLetter.RecipientChatID,
+		utils.FormatTimestamp(deadLetter.CreatedAt, s.userLocation(update.EffectiveUser.Id)),
+		utils.EscapeMarkdown(deadLetter.Reason),
+		attempts,
+		utils.EscapeMarkdown(deadLetter.LastError))
+
+	buttons := [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "Requeue", CallbackData: fmt.Sprintf("deadletter:requeue:%s", deadLetter.ID)},
+			{Text: "Discard", CallbackData: fmt.Sprintf("deadletter:discard:%s", deadLetter.ID)},
+		},
+		{{Text: "Back", CallbackData: fmt.Sprintf("bot:deadletters:%s:0", deadLetter.BotID)}},
+	}
+	return s.editCallbackMessage(b, update, text, buttons)
+}
+
+// afterDeadLetterAction confirms a requeue or discard and returns to the list
+func (s *Service) afterDeadLetterAction(b *gotgbot.Bot, update *ext.Context, botID uuid.UUID, confirmation string) error {
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: confirmation,
+	}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+	// The list answers the callback again, which Telegram ignores
+	return s.showDeadLetters(b, update, botID, nil)
+}
+
+// editCallbackMessage replaces the message of the pressed button, or sends a
+// new message if it cannot be edited
+func (s *Service) editCallbackMessage(b *gotgbot.Bot, update *ext.Context, text string, buttons [][]gotgbot.InlineKeyboardButton) error {
+	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons}
+	messageID, err := getMessageIDFromCallback(update.CallbackQuery.Message)
+	if err == nil {
+		_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+			ChatId:      update.EffectiveChat.Id,
+			MessageId:   messageID,
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+	}
+	if err != nil {
+		_, err = b.SendMessage(update.EffectiveChat.Id, text, &gotgbot.SendMessageOpts{
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+	}
+	return err
+}
+
+// recordDeadLetterAudit logs a dead letter being requeued or discarded
+func (s *Service) recordDeadLetterAudit(update *ext.Context, deadLetter *models.DeadLetter, action models.AuditLogAction) {
+	user, err := s.commandUser(update)
+	if err != nil {
+		s.logger.Warn("Failed to get user for audit log", zap.Error(err))
+		return
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"dead_letter_id":    deadLetter.ID.String(),
+		"guest_chat_id":     deadLetter.GuestChatID,
+		"guest_message_id":  deadLetter.GuestMessageID,
+		"recipient_chat_id": deadLetter.RecipientChatID,
+	})
+	auditLog := &models.AuditLog{
+		UserID:       &user.ID,
+		ActionType:   action,
+		ResourceType: "bot",
+		ResourceID:   deadLetter.BotID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with an ellipsis
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return s
+}
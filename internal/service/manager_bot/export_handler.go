@@ -0,0 +1,187 @@
+package manager_bot
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// exportPeriods are the periods offered by the Export button, in days
+var exportPeriods = []int{7, 30, 90, 365}
+
+// exportAuditLimit is the most audit log entries one export contains
+const exportAuditLimit = 20000
+
+// auditCSVHeader are the columns of the exported audit log
+var auditCSVHeader = []string{"time", "action", "resource_type", "resource_id", "user_telegram_id", "username", "details"}
+
+// handleExportCallback shows the export periods of a bot, or exports the bot's
+// daily statistics and audit log of the chosen period as CSV files
+func (s *Service) handleExportCallback(b *gotgbot.Bot, update *ext.Context, botID uuid.UUID, parts []string) error {
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to load bot information",
+		})
+		return err
+	}
+
+	if len(parts) < 1 {
+		return s.showExportPeriods(b, update, bot)
+	}
+	days, err := strconv.Atoi(parts[0])
+	if err != nil || days < 1 || days > exportPeriods[len(exportPeriods)-1] {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid period",
+		})
+		return err
+	}
+
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: "Preparing export...",
+	}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+	return s.sendExport(b, update, bot, days)
+}
+
+// showExportPeriods replaces the bot view with the export period buttons
+func (s *Service) showExportPeriods(b *gotgbot.Bot, update *ext.Context, bot *models.ForwarderBot) error {
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	var row []gotgbot.InlineKeyboardButton
+	for _, days := range exportPeriods {
+		row = append(row, gotgbot.InlineKeyboardButton{
+			Text:         fmt.Sprintf("%d days", days),
+			CallbackData: fmt.Sprintf("bot:export:%s:%d", bot.ID, days),
+		})
+	}
+	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		row,
+		{{Text: "Back", CallbackData: fmt.Sprintf("bot:view:%s", bot.ID)}},
+	}}
+
+	text := fmt.Sprintf("*Export @%s*\n\n"+
+		"Choose a period. You get two CSV files: the daily statistics of the complete days in it, "+
+		"and the audit log up to now.", utils.EscapeMarkdown(bot.Name))
+	messageID, err := getMessageIDFromCallback(update.CallbackQuery.Message)
+	if err == nil {
+		_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+			ChatId:      update.EffectiveChat.Id,
+			MessageId:   messageID,
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+	}
+	if err != nil {
+		_, err = b.SendMessage(update.EffectiveChat.Id, text, &gotgbot.SendMessageOpts{
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+	}
+	return err
+}
+
+// sendExport builds the CSV files of the last days days and sends them
+func (s *Service) sendExport(b *gotgbot.Bot, update *ext.Context, bot *models.ForwarderBot, days int) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+
+	statsCSV, err := s.statsService.ExportBotDailyCSV(bot.ID, days)
+	if err != nil {
+		s.logger.Error("Failed to export statistics",
+			zap.String("bot_id", bot.ID.String()),
+			zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to export statistics. Please try again later.", nil)
+		return err
+	}
+
+	loc := s.userLocation(userID)
+	now := time.Now().In(loc)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -days)
+	logs, err := s.auditLogRepo.GetByBotIDBetween(bot.ID, start, now, exportAuditLimit+1)
+	if err != nil {
+		s.logger.Error("Failed to export audit log",
+			zap.String("bot_id", bot.ID.String()),
+			zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to export the audit log. Please try again later.", nil)
+		return err
+	}
+	auditCaption := fmt.Sprintf("Audit log of @%s since %s", bot.Name, start.Format("2006-01-02"))
+	if len(logs) > exportAuditLimit {
+		logs = logs[:exportAuditLimit]
+		auditCaption += fmt.Sprintf(" (first %s entries only)", utils.FormatNumber(exportAuditLimit))
+	}
+	auditCSV, err := auditLogCSV(logs, loc)
+	if err != nil {
+		s.logger.Error("Failed to write audit log CSV", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to export the audit log. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Bot data exported",
+		zap.String("bot_id", bot.ID.String()),
+		zap.Int64("user_id", userID),
+		zap.Int("days", days),
+		zap.Int("audit_logs", len(logs)))
+
+	day := now.Format("2006-01-02")
+	err = utils.SendDocuments(b, chatID, []utils.Document{
+		{
+			Name:    fmt.Sprintf("%s-stats-%dd-%s.csv", bot.Name, days, day),
+			Data:    statsCSV,
+			Caption: fmt.Sprintf("Daily statistics of @%s, last %d days", bot.Name, days),
+		},
+		{
+			Name:    fmt.Sprintf("%s-audit-%dd-%s.csv", bot.Name, days, day),
+			Data:    auditCSV,
+			Caption: auditCaption,
+		},
+	})
+	if err != nil {
+		s.logger.Error("Failed to send export files", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to send the export files. Please try again later.", nil)
+		return err
+	}
+	return nil
+}
+
+// auditLogCSV writes audit logs as CSV with times in loc
+func auditLogCSV(logs []*models.AuditLog, loc *time.Location) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(auditCSVHeader)
+	for _, log := range logs {
+		var telegramID, username string
+		if log.User != nil {
+			telegramID = strconv.FormatInt(log.User.TelegramUserID, 10)
+			if log.User.Username != nil {
+				username = *log.User.Username
+			}
+		}
+		w.Write([]string{
+			log.CreatedAt.In(loc).Format(time.RFC3339),
+			string(log.ActionType),
+			log.ResourceType,
+			log.ResourceID.String(),
+			telegramID,
+			username,
+			log.Details,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,141 @@
+package manager_bot
+
+import (
+	"context"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/i18n"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// userLanguage returns the language to talk to a Telegram user in: the one
+// they chose with /language, otherwise the one of their Telegram app
+func (s *Service) userLanguage(telegramUser *gotgbot.User) string {
+	if telegramUser == nil {
+		return i18n.Default
+	}
+	preference := ""
+	if user, err := s.userRepo.GetByTelegramUserID(telegramUser.Id); err == nil {
+		preference = user.Language
+	}
+	return i18n.Resolve(preference, telegramUser.LanguageCode)
+}
+
+// languageKeyboard offers the supported languages and automatic detection
+func languageKeyboard(lang string) gotgbot.InlineKeyboardMarkup {
+	var row []gotgbot.InlineKeyboardButton
+	for _, language := range i18n.Languages() {
+		row = append(row, gotgbot.InlineKeyboardButton{
+			Text:         language.Name,
+			CallbackData: "language:" + language.Code,
+		})
+	}
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		row,
+		{{Text: i18n.T(lang, "language.button_auto"), CallbackData: "language:auto"}},
+	}}
+}
+
+// languageStatus describes the caller's current language
+func (s *Service) languageStatus(telegramUser *gotgbot.User, lang string) string {
+	current := i18n.Name(lang)
+	if user, err := s.userRepo.GetByTelegramUserID(telegramUser.Id); err != nil || user.Language == "" {
+		current = i18n.T(lang, "language.auto", current)
+	}
+	return i18n.T(lang, "language.current", current)
+}
+
+// handleLanguage shows the language menu or sets the caller's language. The
+// choice applies to the ManagerBot and to every ForwarderBot.
+func (s *Service) handleLanguage(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	lang := s.userLanguage(update.EffectiveUser)
+
+	arg := ""
+	if parts := strings.Fields(update.EffectiveMessage.Text); len(parts) >= 2 {
+		arg = parts[1]
+	}
+	if arg == "" {
+		_, err := b.SendMessage(chatID,
+			s.languageStatus(update.EffectiveUser, lang)+"\n\n"+
+				i18n.T(lang, "language.choose")+"\n"+
+				i18n.T(lang, "language.usage"),
+			&gotgbot.SendMessageOpts{ReplyMarkup: languageKeyboard(lang)})
+		return err
+	}
+
+	text, err := s.setLanguage(update.EffectiveUser, arg)
+	if err != nil {
+		return err
+	}
+	_, err = b.SendMessage(chatID, text, nil)
+	return err
+}
+
+// handleLanguageCallback applies a choice from the language menu
+func (s *Service) handleLanguageCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	text, err := s.setLanguage(update.EffectiveUser, parts[0])
+	if err != nil {
+		return err
+	}
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, nil); err != nil {
+		return err
+	}
+	messageID, err := getMessageIDFromCallback(update.CallbackQuery.Message)
+	if err != nil {
+		s.logger.Warn("Failed to get message ID from callback", zap.Error(err))
+		_, err := b.SendMessage(update.EffectiveChat.Id, text, nil)
+		return err
+	}
+	_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+		ChatId:    update.EffectiveChat.Id,
+		MessageId: messageID,
+	})
+	return err
+}
+
+// setLanguage stores a language code, or "auto" to follow the Telegram app,
+// and returns the confirmation to show in the new language
+func (s *Service) setLanguage(telegramUser *gotgbot.User, code string) (string, error) {
+	language := ""
+	if !strings.EqualFold(code, "auto") {
+		matched, ok := i18n.Match(code)
+		if !ok {
+			lang := s.userLanguage(telegramUser)
+			names := make([]string, 0, len(i18n.Languages()))
+			for _, language := range i18n.Languages() {
+				names = append(names, language.Code)
+			}
+			return i18n.T(lang, "language.unknown", code, strings.Join(names, i18n.T(lang, "list.separator"))), nil
+		}
+		language = matched
+	}
+
+	var username *string
+	if telegramUser.Username != "" {
+		username = &telegramUser.Username
+	}
+	user, err := s.userRepo.GetOrCreateByTelegramUserID(telegramUser.Id, username)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.Error(err))
+		return i18n.T(s.userLanguage(telegramUser), "common.error"), nil
+	}
+	user.Language = language
+	if err := s.userRepo.Update(user); err != nil {
+		s.logger.Error("Failed to update user language", zap.Error(err))
+		return i18n.T(s.userLanguage(telegramUser), "language.failed"), nil
+	}
+
+	s.logger.Info("User language updated",
+		zap.Int64("user_id", telegramUser.Id),
+		zap.String("language", language))
+
+	lang := i18n.Resolve(language, telegramUser.LanguageCode)
+	if language == "" {
+		return i18n.T(lang, "language.set_auto", i18n.Name(lang)), nil
+	}
+	return i18n.T(lang, "language.set", i18n.Name(lang)), nil
+}
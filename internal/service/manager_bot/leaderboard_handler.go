@@ -0,0 +1,120 @@
+package manager_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go-telegram-forwarder-bot/internal/service/statistics"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// leaderboardPageSize is how many bots one leaderboard page shows
+const leaderboardPageSize = 10
+
+// handleLeaderboard shows ForwarderBots ranked by 7-day activity.
+// parts are the callback parts after "leaderboard": [sort, page].
+func (s *Service) handleLeaderboard(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	sortBy := statistics.LeaderboardSortVolume
+	page := 0
+	if len(parts) >= 1 && statistics.LeaderboardSort(parts[0]) == statistics.LeaderboardSortFailures {
+		sortBy = statistics.LeaderboardSortFailures
+	}
+	if len(parts) >= 2 {
+		if p, err := strconv.Atoi(parts[1]); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	activities, err := s.statsService.GetBotLeaderboard(sortBy)
+	if err != nil {
+		s.logger.Error("Failed to get bot leaderboard", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to load leaderboard",
+		})
+		return err
+	}
+
+	_, err = b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{})
+	if err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	page, totalPages, start, end := pageBounds(len(activities), page, leaderboardPageSize)
+
+	title := "by message volume"
+	if sortBy == statistics.LeaderboardSortFailures {
+		title = "by failure rate"
+	}
+	text := fmt.Sprintf("*Bot Leaderboard* (last 7 days, %s)\nPage %d/%d\n", title, page+1, totalPages)
+	if len(activities) == 0 {
+		text += "\nNo bots registered."
+	}
+
+	var buttons [][]gotgbot.InlineKeyboardButton
+	for i, activity := range activities[start:end] {
+		text += "\n" + formatBotActivity(start+i+1, activity)
+		buttons = append(buttons, botActivityButton(start+i+1, activity))
+	}
+
+	navigation := pageNavigation(page, totalPages, func(page int) string {
+		return fmt.Sprintf("manage:leaderboard:%s:%d", sortBy, page)
+	})
+	if len(navigation) > 0 {
+		buttons = append(buttons, navigation)
+	}
+
+	toggle := gotgbot.InlineKeyboardButton{Text: "Sort by Failure Rate", CallbackData: "manage:leaderboard:failures:0"}
+	if sortBy == statistics.LeaderboardSortFailures {
+		toggle = gotgbot.InlineKeyboardButton{Text: "Sort by Volume", CallbackData: "manage:leaderboard:volume:0"}
+	}
+	buttons = append(buttons,
+		[]gotgbot.InlineKeyboardButton{toggle},
+		[]gotgbot.InlineKeyboardButton{{Text: "Back", CallbackData: "manage:menu"}},
+	)
+
+	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons}
+	messageID, err := getMessageIDFromCallback(update.CallbackQuery.Message)
+	if err != nil {
+		s.logger.Warn("Failed to get message ID from callback", zap.Error(err))
+		_, err := b.SendMessage(update.EffectiveChat.Id, text, &gotgbot.SendMessageOpts{
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+		return err
+	}
+	_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+		ChatId:      update.EffectiveChat.Id,
+		MessageId:   messageID,
+		ParseMode:   "Markdown",
+		ReplyMarkup: keyboard,
+	})
+	return err
+}
+
+// formatBotActivity describes the rank-th bot of a ranking as Markdown
+func formatBotActivity(rank int, activity statistics.BotActivity) string {
+	return fmt.Sprintf("%d. @%s\n   Messages: %s (in %s / out %s), Failure rate: %s%% (%s/%s)",
+		rank,
+		utils.EscapeMarkdown(activity.BotName),
+		utils.FormatNumber(activity.MessageCount()),
+		utils.FormatNumber(activity.InboundCount),
+		utils.FormatNumber(activity.OutboundCount),
+		utils.FormatDecimal(activity.FailureRate()*100, 1),
+		utils.FormatNumber(activity.FailureCount),
+		utils.FormatNumber(activity.CopyCount))
+}
+
+// botActivityButton opens the rank-th bot of a ranking
+func botActivityButton(rank int, activity statistics.BotActivity) []gotgbot.InlineKeyboardButton {
+	return []gotgbot.InlineKeyboardButton{
+		{
+			Text:         fmt.Sprintf("%d. @%s", rank, activity.BotName),
+			CallbackData: fmt.Sprintf("bot:view:%s", activity.BotID.String()),
+		},
+	}
+}
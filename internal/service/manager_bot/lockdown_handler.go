@@ -0,0 +1,135 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const lockdownUsage = "Usage:\n" +
+	"/lockdown on [note] - Disable commands for everyone but superusers until lifted\n" +
+	"/lockdown <duration> [note] - Same, lifted automatically, e.g. /lockdown 30m\n" +
+	"/lockdown off - Lift the lockdown"
+
+// handleLockdown turns the break-glass lockdown on or off
+func (s *Service) handleLockdown(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	if s.lockdown == nil {
+		_, err := b.SendMessage(chatID, "Lockdown is not available.", nil)
+		return err
+	}
+
+	parts := strings.Fields(update.EffectiveMessage.Text)
+	if len(parts) < 2 {
+		_, err := b.SendMessage(chatID, s.lockdownStatus(userID)+"\n\n"+lockdownUsage, nil)
+		return err
+	}
+
+	if strings.EqualFold(parts[1], "off") {
+		if !s.lockdown.Lift() {
+			_, err := b.SendMessage(chatID, "No lockdown is active.", nil)
+			return err
+		}
+		s.logger.Warn("Lockdown lifted", zap.Int64("superuser_id", userID))
+		s.recordLockdownAudit(update, models.AuditLogActionLiftLockdown, map[string]interface{}{})
+		_, err := b.SendMessage(chatID, "✅ Lockdown lifted. Commands are available again.", nil)
+		return err
+	}
+
+	var duration time.Duration
+	if !strings.EqualFold(parts[1], "on") {
+		parsed, err := time.ParseDuration(parts[1])
+		if err != nil || parsed <= 0 {
+			_, err := b.SendMessage(chatID, lockdownUsage, nil)
+			return err
+		}
+		duration = parsed
+	}
+	note := strings.TrimSpace(strings.Join(parts[2:], " "))
+
+	state := s.lockdown.Start(userID, note, duration)
+	s.logger.Warn("Lockdown started",
+		zap.Int64("superuser_id", userID),
+		zap.Duration("duration", duration),
+		zap.String("note", note))
+	s.recordLockdownAudit(update, models.AuditLogActionLockdown, map[string]interface{}{
+		"note":     note,
+		"duration": duration.String(),
+	})
+
+	text := "🚧 Lockdown active. Only superusers can use commands in the ManagerBot, the ForwarderBots and the API; guest messages are still forwarded."
+	if !state.Until.IsZero() {
+		text += fmt.Sprintf("\nIt ends at %s.", utils.FormatTimestamp(state.Until, s.userLocation(userID)))
+	}
+	text += "\nUse /lockdown off to lift it."
+	text += "\n\nThe lockdown is held in memory: with Redis it is kept across a clean restart, otherwise a restart lifts it."
+	_, err := b.SendMessage(chatID, text, nil)
+	return err
+}
+
+// lockdownStatus describes the current lockdown in the viewer's timezone
+func (s *Service) lockdownStatus(viewerID int64) string {
+	state, active := s.lockdown.State()
+	if !active {
+		return "No lockdown is active."
+	}
+	loc := s.userLocation(viewerID)
+	text := fmt.Sprintf("🚧 Lockdown active since %s, started by %d.",
+		utils.FormatTimestamp(state.StartedAt, loc), state.StartedBy)
+	if !state.Until.IsZero() {
+		text += fmt.Sprintf("\nEnds at %s.", utils.FormatTimestamp(state.Until, loc))
+	}
+	if state.Note != "" {
+		text += "\nNote: " + state.Note
+	}
+	return text
+}
+
+// lockedOut reports whether the lockdown keeps the user out, telling them so
+func (s *Service) lockedOut(b *gotgbot.Bot, update *ext.Context) bool {
+	if s.lockdown == nil {
+		return false
+	}
+	blocked, err := s.lockdown.Reject(b, update)
+	if !blocked {
+		return false
+	}
+
+	s.logger.Debug("Update rejected during lockdown",
+		zap.Int64("user_id", update.EffectiveUser.Id))
+	if err != nil {
+		s.logger.Debug("Failed to send lockdown notice", zap.Error(err))
+	}
+	return true
+}
+
+// recordLockdownAudit logs a lockdown being started or lifted
+func (s *Service) recordLockdownAudit(update *ext.Context, action models.AuditLogAction, details map[string]interface{}) {
+	superuser, err := s.commandUser(update)
+	if err != nil {
+		s.logger.Warn("Failed to get superuser for audit log", zap.Error(err))
+		return
+	}
+
+	encoded, _ := json.Marshal(details)
+	auditLog := &models.AuditLog{
+		UserID:       &superuser.ID,
+		ActionType:   action,
+		ResourceType: "system",
+		ResourceID:   uuid.Nil,
+		Details:      string(encoded),
+	}
+	s.auditLogRepo.Create(auditLog)
+}
@@ -0,0 +1,137 @@
+package manager_bot
+
+import (
+	"errors"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// menuMaxAge is how long an inline menu stays usable after it was last
+// rendered. Older menus are refreshed instead of acting on outdated data.
+const menuMaxAge = 24 * time.Hour
+
+// menuCallbackActions are the callback prefixes of navigation menus. Action
+// buttons in notifications, such as report reviews, are not subject to expiry.
+var menuCallbackActions = map[string]bool{
+	"manage":     true,
+	"manager":    true,
+	"bot":        true,
+	"settings":   true,
+	"delete_bot": true,
+	"mybots":     true,
+	"deadletter": true,
+}
+
+// menuExpiredText is shown when a button of an expired menu is pressed
+const menuExpiredText = "This menu expired. It has been refreshed with current data."
+
+// menuRenderedAt returns when the menu message was sent or last edited. It
+// reports false if Telegram no longer gives access to the message.
+func menuRenderedAt(msg gotgbot.MaybeInaccessibleMessage) (time.Time, bool) {
+	m, ok := msg.(gotgbot.Message)
+	if !ok || m.Date == 0 {
+		return time.Time{}, false
+	}
+	renderedAt := m.Date
+	if m.EditDate > renderedAt {
+		renderedAt = m.EditDate
+	}
+	return time.Unix(renderedAt, 0), true
+}
+
+// callbackBotID returns the ForwarderBot a callback refers to, if any
+func callbackBotID(parts []string) (uuid.UUID, bool) {
+	var arg string
+	switch {
+	case parts[0] == "settings" && len(parts) > 1:
+		arg = parts[1]
+	case (parts[0] == "bot" || parts[0] == "delete_bot") && len(parts) > 2:
+		arg = parts[2]
+	case parts[0] == "manage" && len(parts) > 2 && parts[1] == "bot":
+		arg = parts[2]
+	default:
+		return uuid.Nil, false
+	}
+	botID, err := uuid.Parse(arg)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return botID, true
+}
+
+// guardExpiredMenu stops callbacks from menus that are too old or refer to a
+// bot that no longer exists. The menu is answered with menuExpiredText and
+// re-rendered with current data. It reports whether the callback was handled.
+func (s *Service) guardExpiredMenu(b *gotgbot.Bot, update *ext.Context, parts []string) (bool, error) {
+	if !menuCallbackActions[parts[0]] {
+		return false, nil
+	}
+	userID := update.EffectiveUser.Id
+
+	reason := ""
+	renderedAt, accessible := menuRenderedAt(update.CallbackQuery.Message)
+	if !accessible || time.Since(renderedAt) > menuMaxAge {
+		reason = "too old"
+	}
+
+	var bot *models.ForwarderBot
+	if botID, ok := callbackBotID(parts); ok {
+		var err error
+		bot, err = s.botRepo.GetByID(botID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			reason = "bot deleted"
+			bot = nil
+		case err != nil:
+			// Let the handler report the failure
+			bot = nil
+		}
+	}
+
+	if reason == "" {
+		return false, nil
+	}
+
+	s.logger.Debug("Callback from expired menu",
+		zap.Int64("user_id", userID),
+		zap.String("callback_data", update.CallbackQuery.Data),
+		zap.String("reason", reason))
+
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: menuExpiredText,
+	}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	// Inaccessible messages cannot be edited; the views then send a new message instead
+	return true, s.refreshMenu(b, update, bot)
+}
+
+// refreshMenu replaces an expired menu with the current view of its bot, or
+// with the bot list if the bot is gone or no longer accessible to the user
+func (s *Service) refreshMenu(b *gotgbot.Bot, update *ext.Context, bot *models.ForwarderBot) error {
+	userID := update.EffectiveUser.Id
+	isSuperuser := s.IsSuperuser(userID)
+
+	if bot != nil {
+		isManager := false
+		if !isSuperuser {
+			isManager, _ = s.IsBotManager(userID, bot.ID)
+		}
+		if isManager || isSuperuser {
+			return s.showBotView(b, update, bot.ID, isManager, isSuperuser)
+		}
+	}
+
+	if isSuperuser {
+		return s.showAllBots(b, update)
+	}
+	return s.showMyBots(b, update)
+}
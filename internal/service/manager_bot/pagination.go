@@ -0,0 +1,38 @@
+package manager_bot
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// pageBounds clamps page to the pages of total items and returns it with the
+// number of pages and the bounds of the page's items. An empty list has one
+// empty page.
+func pageBounds(total, page, pageSize int) (current, totalPages, start, end int) {
+	totalPages = (total + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	current = max(min(page, totalPages-1), 0)
+	start = current * pageSize
+	end = min(start+pageSize, total)
+	return current, totalPages, start, end
+}
+
+// pageNavigation returns the « Prev / Next » row of a page, or nil when there
+// is no other page. callbackData formats the callback data of another page.
+func pageNavigation(page, totalPages int, callbackData func(page int) string) []gotgbot.InlineKeyboardButton {
+	var navigation []gotgbot.InlineKeyboardButton
+	if page > 0 {
+		navigation = append(navigation, gotgbot.InlineKeyboardButton{
+			Text:         "« Prev",
+			CallbackData: callbackData(page - 1),
+		})
+	}
+	if page < totalPages-1 {
+		navigation = append(navigation, gotgbot.InlineKeyboardButton{
+			Text:         "Next »",
+			CallbackData: callbackData(page + 1),
+		})
+	}
+	return navigation
+}
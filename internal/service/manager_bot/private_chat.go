@@ -0,0 +1,90 @@
+package manager_bot
+
+import (
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// privateChatNotice answers management commands sent in groups
+const privateChatNotice = "🔒 Bots are managed in a private chat with me, so tokens and bot details stay out of groups. Please send the command to me directly."
+
+// leakedTokenNotice answers commands that pasted a bot token into a group
+const leakedTokenNotice = "⚠️ Your message contained a bot token and was sent to a group, where others may have seen it. " +
+	"Revoke the token in @BotFather (/revoke), then register the new one in a private chat with me."
+
+// outsidePrivateChat keeps bot management out of groups and channels. Only
+// /help is answered there; other commands and buttons get a pointer to the
+// private chat, and messages with a bot token in them are deleted. It reports
+// whether the update was refused.
+func (s *Service) outsidePrivateChat(b *gotgbot.Bot, update *ext.Context) bool {
+	chat := update.EffectiveChat
+	if chat == nil || chat.Type == "private" {
+		return false
+	}
+
+	if update.CallbackQuery != nil {
+		s.logger.Debug("Callback refused outside a private chat",
+			zap.Int64("user_id", update.EffectiveUser.Id),
+			zap.Int64("chat_id", chat.Id))
+		if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text:      "Please use me in a private chat.",
+			ShowAlert: true,
+		}); err != nil {
+			s.logger.Debug("Failed to answer callback", zap.Error(err))
+		}
+		return true
+	}
+
+	message := update.EffectiveMessage
+	if strings.HasPrefix(message.Text, "/help") {
+		return false
+	}
+
+	s.logger.Debug("Command refused outside a private chat",
+		zap.Int64("user_id", update.EffectiveUser.Id),
+		zap.Int64("chat_id", chat.Id),
+		zap.String("chat_type", chat.Type))
+
+	text := privateChatNotice
+	if containsBotToken(message.Text) {
+		s.logger.Warn("Bot token sent to ManagerBot in a group",
+			zap.Int64("user_id", update.EffectiveUser.Id),
+			zap.Int64("chat_id", chat.Id))
+		text = leakedTokenNotice
+		if _, err := b.DeleteMessage(chat.Id, message.MessageId, nil); err != nil {
+			s.logger.Debug("Failed to delete message with a bot token",
+				zap.Int64("chat_id", chat.Id),
+				zap.Error(err))
+		}
+	}
+
+	opts := &gotgbot.SendMessageOpts{}
+	if b.Username != "" {
+		opts.ReplyMarkup = gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+				{Text: "Open private chat", Url: "https://t.me/" + b.Username},
+			}},
+		}
+	}
+	if _, err := b.SendMessage(chat.Id, text, opts); err != nil {
+		s.logger.Debug("Failed to send private chat notice",
+			zap.Int64("chat_id", chat.Id),
+			zap.Error(err))
+	}
+	return true
+}
+
+// containsBotToken reports whether any word of text is a bot token
+func containsBotToken(text string) bool {
+	for _, field := range strings.Fields(text) {
+		if _, err := utils.ParseBotToken(field); err == nil {
+			return true
+		}
+	}
+	return false
+}
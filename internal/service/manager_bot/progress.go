@@ -0,0 +1,135 @@
+package manager_bot
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+// progressCountInterval limits how often Count edits the progress message, so
+// long loops stay within Telegram's edit rate limits
+const progressCountInterval = 3 * time.Second
+
+// progressInterruptedText replaces the progress message if an operation returns
+// without reporting its outcome
+const progressInterruptedText = "❌ The operation was interrupted. Please try again later."
+
+// progressReporter keeps a single "please wait" message up to date while a long
+// operation runs. Callers report stages with Stage, the outcome with Finish, and
+// defer Close so the message never stays at "⏳" after a failure.
+type progressReporter struct {
+	bot       *gotgbot.Bot
+	chatID    int64
+	messageID int64
+	parseMode string
+	logger    *zap.Logger
+
+	mu         sync.Mutex
+	lastText   string
+	lastUpdate time.Time
+	finished   bool
+}
+
+// startProgress sends the initial wait message. If sending fails the reporter
+// still works; Finish then sends the outcome as a new message.
+func (s *Service) startProgress(b *gotgbot.Bot, chatID int64, parseMode string) *progressReporter {
+	p := &progressReporter{
+		bot:       b,
+		chatID:    chatID,
+		parseMode: parseMode,
+		logger:    s.logger,
+	}
+
+	text := "⏳ Processing, please wait..."
+	msg, err := b.SendMessage(chatID, text, nil)
+	if err != nil {
+		s.logger.Warn("Failed to send wait message", zap.Error(err))
+		return p
+	}
+	p.messageID = msg.MessageId
+	p.lastText = text
+	p.lastUpdate = time.Now()
+	return p
+}
+
+// Stage shows the step the operation is currently at, e.g. "Validating token..."
+func (p *progressReporter) Stage(text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished {
+		return
+	}
+	p.edit("⏳ " + text)
+}
+
+// Count shows how far a loop has got, e.g. "Sending... 40/120". Updates are
+// throttled, except for the last one.
+func (p *progressReporter) Count(text string, done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished {
+		return
+	}
+	if done < total && time.Since(p.lastUpdate) < progressCountInterval {
+		return
+	}
+	p.edit(fmt.Sprintf("⏳ %s %s/%s", text, utils.FormatNumber(int64(done)), utils.FormatNumber(int64(total))))
+}
+
+// Finish replaces the wait message with the outcome of the operation. Later
+// calls to Stage, Count and Finish are ignored.
+func (p *progressReporter) Finish(text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished {
+		return
+	}
+	p.finished = true
+	if p.edit(text) {
+		return
+	}
+
+	// The wait message is gone or could not be edited; make sure the outcome arrives
+	opts := &gotgbot.SendMessageOpts{ParseMode: p.parseMode}
+	if _, err := p.bot.SendMessage(p.chatID, text, opts); err != nil {
+		p.logger.Warn("Failed to send progress result",
+			zap.Int64("chat_id", p.chatID),
+			zap.Error(err))
+	}
+}
+
+// Close reports a generic failure if the operation did not call Finish
+func (p *progressReporter) Close() {
+	p.Finish(progressInterruptedText)
+}
+
+// edit updates the wait message and reports whether it now shows text
+func (p *progressReporter) edit(text string) bool {
+	if p.messageID == 0 {
+		return false
+	}
+	if text == p.lastText {
+		return true
+	}
+
+	_, _, err := p.bot.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+		ChatId:    p.chatID,
+		MessageId: p.messageID,
+		ParseMode: p.parseMode,
+	})
+	if err != nil {
+		p.logger.Warn("Failed to update wait message",
+			zap.Int64("chat_id", p.chatID),
+			zap.Int64("message_id", p.messageID),
+			zap.Error(err))
+		return false
+	}
+	p.lastText = text
+	p.lastUpdate = time.Now()
+	return true
+}
@@ -0,0 +1,146 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const setQuotaUsage = "Usage: /setquota <bot_id|@bot_username> <messages_per_month|off>"
+
+// findBotByReference resolves a bot by UUID or @username
+func (s *Service) findBotByReference(reference string) (*models.ForwarderBot, error) {
+	if botID, err := uuid.Parse(reference); err == nil {
+		return s.botRepo.GetByID(botID)
+	}
+
+	name := strings.TrimPrefix(reference, "@")
+	bots, err := s.botRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, bot := range bots {
+		if strings.EqualFold(bot.Name, name) {
+			return bot, nil
+		}
+	}
+	return nil, fmt.Errorf("bot %s not found", reference)
+}
+
+func (s *Service) handleSetQuota(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /setquota command",
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	parts := strings.Fields(update.EffectiveMessage.Text)
+	if len(parts) < 3 {
+		_, err := b.SendMessage(chatID, setQuotaUsage, nil)
+		return err
+	}
+
+	var quota int64
+	if !strings.EqualFold(parts[2], "off") {
+		value, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || value <= 0 {
+			_, err := b.SendMessage(chatID, setQuotaUsage, nil)
+			return err
+		}
+		quota = value
+	}
+
+	bot, err := s.findBotByReference(parts[1])
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+		return err
+	}
+
+	previous := bot.MonthlyQuota
+	bot.MonthlyQuota = quota
+	if err := s.botRepo.Update(bot); err != nil {
+		s.logger.Error("Failed to update bot quota", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update quota. Please try again later.", nil)
+		return err
+	}
+
+	if s.quotaEnforcer != nil {
+		if err := s.quotaEnforcer.QuotaChanged(bot.ID); err != nil {
+			s.logger.Warn("Failed to reset quota notifications", zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Bot quota updated",
+		zap.Int64("superuser_id", userID),
+		zap.String("bot_id", bot.ID.String()),
+		zap.Int64("previous_quota", previous),
+		zap.Int64("quota", quota))
+	s.recordQuotaAudit(update, bot, previous)
+
+	text := fmt.Sprintf("Monthly quota of @%s removed.", bot.Name)
+	if quota > 0 {
+		text = fmt.Sprintf("Monthly quota of @%s set to %d messages.", bot.Name, quota)
+	}
+	if usage := s.quotaUsageLine(bot); usage != "" {
+		text += "\n" + usage
+	}
+	_, err = b.SendMessage(chatID, text, nil)
+	return err
+}
+
+// quotaUsageLine describes the bot's usage this month, or "" if usage is unavailable
+func (s *Service) quotaUsageLine(bot *models.ForwarderBot) string {
+	if s.quotaEnforcer == nil {
+		return ""
+	}
+	usage, err := s.quotaEnforcer.GetUsage(bot.ID)
+	if err != nil {
+		s.logger.Warn("Failed to get bot usage", zap.Error(err))
+		return ""
+	}
+	if bot.MonthlyQuota <= 0 {
+		return fmt.Sprintf("Usage this month: %d messages (no quota)", usage.MessageCount)
+	}
+	line := fmt.Sprintf("Usage this month: %d/%d messages (%d%%)",
+		usage.MessageCount, bot.MonthlyQuota, usage.MessageCount*100/bot.MonthlyQuota)
+	if usage.MessageCount >= bot.MonthlyQuota {
+		line += ", forwarding paused"
+	}
+	return line
+}
+
+func (s *Service) recordQuotaAudit(update *ext.Context, bot *models.ForwarderBot, previous int64) {
+	username := update.EffectiveUser.Username
+	var usernamePtr *string
+	if username != "" {
+		usernamePtr = &username
+	}
+	superuser, err := s.userRepo.GetOrCreateByTelegramUserID(update.EffectiveUser.Id, usernamePtr)
+	if err != nil {
+		s.logger.Warn("Failed to get superuser for audit log", zap.Error(err))
+		return
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"previous_quota": previous,
+		"quota":          bot.MonthlyQuota,
+	})
+	auditLog := &models.AuditLog{
+		UserID:       &superuser.ID,
+		ActionType:   models.AuditLogActionSetQuota,
+		ResourceType: "bot",
+		ResourceID:   bot.ID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+}
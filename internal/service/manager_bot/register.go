@@ -0,0 +1,393 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Errors returned by RegisterBot
+var (
+	ErrManagerBotToken      = errors.New("token belongs to the ManagerBot")
+	ErrBotAlreadyRegistered = errors.New("bot is already registered")
+	ErrManagerSuspended     = errors.New("manager is suspended")
+	ErrBotLimitReached      = errors.New("bot limit of the manager's tier reached")
+)
+
+// Errors for tokens that cannot be used, returned by RegisterBot and
+// SetBotToken. Both are the caller's fault rather than the instance's.
+var (
+	ErrInvalidToken      = errors.New("invalid bot token")
+	ErrTokenVerification = errors.New("failed to verify bot token")
+)
+
+// Errors returned by SetBotToken
+var (
+	ErrTokenOfOtherBot = errors.New("token belongs to a different bot")
+	ErrTokenUnchanged  = errors.New("bot already uses this token")
+	ErrBotNotRestarted = errors.New("token updated but the bot failed to restart")
+)
+
+// RegisterBot registers a ForwarderBot for the manager with the given Telegram
+// user ID and starts it, like /addbot without a chat. Tier limits are not
+// applied; it is meant for the operator of this instance, such as through the
+// embedding API. Use RegisterBotWithinLimit on behalf of a manager.
+func (s *Service) RegisterBot(ctx context.Context, token string, managerTelegramUserID int64) (*models.ForwarderBot, error) {
+	return s.registerBot(ctx, token, managerTelegramUserID, false)
+}
+
+// RegisterBotWithinLimit is RegisterBot for a manager registering a bot for
+// themselves. It returns ErrBotLimitReached once the manager has as many bots
+// as their tier allows, like /addbot.
+func (s *Service) RegisterBotWithinLimit(ctx context.Context, token string, managerTelegramUserID int64) (*models.ForwarderBot, error) {
+	return s.registerBot(ctx, token, managerTelegramUserID, true)
+}
+
+func (s *Service) registerBot(ctx context.Context, token string, managerTelegramUserID int64, checkLimit bool) (*models.ForwarderBot, error) {
+	token = strings.TrimSpace(token)
+	tokenBotID, err := utils.ParseBotToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if managerBotID, err := utils.ParseBotToken(s.config.ManagerBot.Token); token == s.config.ManagerBot.Token || (err == nil && managerBotID == tokenBotID) {
+		return nil, ErrManagerBotToken
+	}
+
+	botInfo, err := s.verifyToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetOrCreateByTelegramUserID(managerTelegramUserID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manager: %w", err)
+	}
+	if user.IsSuspended() {
+		return nil, ErrManagerSuspended
+	}
+	if checkLimit && s.featureFlags != nil {
+		allowed, limit, err := s.featureFlags.CanAddBot(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check bot limit: %w", err)
+		}
+		if !allowed {
+			return nil, fmt.Errorf("%w: the %s tier allows up to %d bots", ErrBotLimitReached, s.featureFlags.EffectiveTier(user), limit)
+		}
+	}
+
+	if s.findRegisteredBot(managerTelegramUserID, botInfo) != nil {
+		return nil, ErrBotAlreadyRegistered
+	}
+
+	encryptedToken, err := utils.EncryptToken(token, s.encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt token: %w", err)
+	}
+	forwarderBot, err := s.createBot(managerTelegramUserID, user, botInfo, encryptedToken)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("ForwarderBot registered",
+		zap.String("bot_id", forwarderBot.ID.String()),
+		zap.String("bot_username", forwarderBot.Name),
+		zap.Int64("manager_user_id", managerTelegramUserID))
+
+	if s.botManager != nil {
+		if err := s.botManager.StartBot(forwarderBot.ID); err != nil {
+			return forwarderBot, fmt.Errorf("bot registered but failed to start: %w", err)
+		}
+	}
+	return forwarderBot, nil
+}
+
+// verifyToken asks Telegram which bot the token belongs to
+func (s *Service) verifyToken(ctx context.Context, token string) (*gotgbot.User, error) {
+	opts, err := s.botOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proxy HTTP client: %w", err)
+	}
+	if opts == nil {
+		opts = &gotgbot.BotOpts{}
+	}
+	opts.DisableTokenCheck = true
+	testBot, err := gotgbot.NewBot(token, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bot client: %w", err)
+	}
+	botInfo, err := testBot.GetMeWithContext(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenVerification, err)
+	}
+	return botInfo, nil
+}
+
+// SetBotToken replaces the token of a ForwarderBot, e.g. after it was revoked
+// in @BotFather, and restarts the bot with it. Recipients, admins and
+// statistics are kept, so the token must belong to the same Telegram bot.
+// The update and its audit log are written in one transaction.
+func (s *Service) SetBotToken(ctx context.Context, bot *models.ForwarderBot, token string, user *models.User) error {
+	token = strings.TrimSpace(token)
+	tokenBotID, err := utils.ParseBotToken(token)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if token == s.config.ManagerBot.Token {
+		return ErrManagerBotToken
+	}
+
+	currentToken, err := utils.DecryptToken(bot.Token, s.encryptionKey)
+	if err != nil {
+		// The stored token is unreadable, so only the Telegram bot ID can be compared
+		s.logger.Warn("Failed to decrypt current bot token",
+			zap.String("bot_id", bot.ID.String()),
+			zap.Error(err))
+		currentToken = ""
+	}
+	if token == currentToken {
+		return ErrTokenUnchanged
+	}
+	telegramBotID := bot.TelegramID(currentToken)
+	if telegramBotID != 0 && tokenBotID != telegramBotID {
+		return ErrTokenOfOtherBot
+	}
+
+	botInfo, err := s.verifyToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	if telegramBotID != 0 && botInfo.Id != telegramBotID {
+		return ErrTokenOfOtherBot
+	}
+
+	encryptedToken, err := utils.EncryptToken(token, s.encryptionKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt token: %w", err)
+	}
+
+	previousName := bot.Name
+	bot.Token = encryptedToken
+	bot.Name = botInfo.Username
+	bot.TelegramBotID = botInfo.Id
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.botRepo.WithTx(tx).Update(bot); err != nil {
+			return fmt.Errorf("failed to update bot: %w", err)
+		}
+
+		// The token itself is never written to the audit log
+		details, _ := json.Marshal(map[string]interface{}{
+			"bot_id":        bot.ID.String(),
+			"bot_name":      bot.Name,
+			"previous_name": previousName,
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &user.ID,
+			ActionType:   models.AuditLogActionSetToken,
+			ResourceType: "bot",
+			ResourceID:   bot.ID,
+			Details:      string(details),
+		}
+		if err := s.auditLogRepo.WithTx(tx).Create(auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("ForwarderBot token replaced",
+		zap.String("bot_id", bot.ID.String()),
+		zap.String("bot_username", bot.Name),
+		zap.Int64("user_id", user.TelegramUserID))
+
+	// Bots of suspended managers stay stopped until the suspension is lifted
+	if s.botManager == nil || bot.Manager.IsSuspended() {
+		return nil
+	}
+	if err := s.botManager.RestartBot(bot.ID); err != nil {
+		return fmt.Errorf("%w: %v", ErrBotNotRestarted, err)
+	}
+	return nil
+}
+
+// DeleteBot stops a ForwarderBot and deletes it. The caller records who
+// deleted it in the audit log.
+func (s *Service) DeleteBot(botID uuid.UUID) (*models.ForwarderBot, error) {
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bot: %w", err)
+	}
+
+	// Stop the bot immediately if BotManager is available
+	if s.botManager != nil {
+		s.logger.Debug("Stopping ForwarderBot immediately",
+			zap.String("bot_id", botID.String()),
+			zap.String("bot_name", bot.Name))
+		if stopErr := s.botManager.StopBot(botID); stopErr != nil {
+			s.logger.Warn("Failed to stop ForwarderBot immediately",
+				zap.String("bot_id", botID.String()),
+				zap.Error(stopErr))
+			// Continue with deletion anyway
+		} else {
+			s.logger.Debug("ForwarderBot stopped successfully",
+				zap.String("bot_id", botID.String()),
+				zap.String("bot_name", bot.Name))
+		}
+	}
+
+	if err := s.botRepo.Delete(botID); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// findRegisteredBot returns the registered ForwarderBot for botInfo, or nil.
+// A failed lookup is logged and treated as not registered.
+func (s *Service) findRegisteredBot(userID int64, botInfo *gotgbot.User) *models.ForwarderBot {
+	// Check if the bot is already registered. Bots are compared by Telegram ID
+	// rather than token, so a regenerated token of a registered bot is caught too.
+	s.logger.Debug("Checking if bot already exists",
+		zap.Int64("user_id", userID),
+		zap.String("bot_username", botInfo.Username))
+	allBots, err := s.botRepo.GetAll()
+	if err == nil {
+		s.logger.Debug("Retrieved all bots for duplicate check",
+			zap.Int64("user_id", userID),
+			zap.Int("total_bots", len(allBots)))
+		for _, existingBot := range allBots {
+			existingToken := ""
+			if existingBot.TelegramBotID == 0 {
+				existingToken, _ = utils.DecryptToken(existingBot.Token, s.encryptionKey)
+			}
+			if existingBot.TelegramID(existingToken) == botInfo.Id {
+				s.logger.Debug("Bot already exists",
+					zap.Int64("user_id", userID),
+					zap.String("bot_username", botInfo.Username),
+					zap.String("existing_bot_id", existingBot.ID.String()))
+				return existingBot
+			}
+		}
+		s.logger.Debug("No duplicate bot found",
+			zap.Int64("user_id", userID),
+			zap.String("bot_username", botInfo.Username))
+	} else {
+		s.logger.Debug("Failed to get all bots for duplicate check, continuing",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+	return nil
+}
+
+// createBot stores a new ForwarderBot, adds its manager as the first recipient
+// and records the audit log, all in one transaction
+func (s *Service) createBot(userID int64, user *models.User, botInfo *gotgbot.User, encryptedToken string) (*models.ForwarderBot, error) {
+	// Create bot with transaction to ensure data consistency
+	forwarderBot := &models.ForwarderBot{
+		Token:         encryptedToken,
+		Name:          botInfo.Username,
+		TelegramBotID: botInfo.Id,
+		ManagerID:     user.ID,
+	}
+
+	s.logger.Debug("Starting transaction for bot creation",
+		zap.Int64("user_id", userID),
+		zap.String("bot_username", botInfo.Username),
+		zap.String("manager_id", user.ID.String()))
+
+	// Use transaction to ensure atomicity of bot creation, recipient creation, and audit logging
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Create transaction-aware repositories
+		txBotRepo := s.botRepo.WithTx(tx)
+		txRecipientRepo := s.recipientRepo.WithTx(tx)
+		txAuditRepo := s.auditLogRepo.WithTx(tx)
+
+		// 1. Create bot
+		s.logger.Debug("Creating ForwarderBot record in transaction",
+			zap.Int64("user_id", userID),
+			zap.String("bot_username", botInfo.Username))
+		if err := txBotRepo.Create(forwarderBot); err != nil {
+			s.logger.Error("Failed to create bot in transaction", zap.Error(err))
+			return fmt.Errorf("failed to create bot: %w", err)
+		}
+
+		s.logger.Debug("ForwarderBot created successfully in transaction",
+			zap.Int64("user_id", userID),
+			zap.String("bot_id", forwarderBot.ID.String()),
+			zap.String("bot_username", forwarderBot.Name))
+
+		// 2. Add manager as recipient automatically
+		s.logger.Debug("Adding manager as recipient in transaction",
+			zap.Int64("user_id", userID),
+			zap.String("bot_id", forwarderBot.ID.String()),
+			zap.Int64("manager_telegram_user_id", user.TelegramUserID))
+
+		// Check if recipient already exists (using transaction-aware repo)
+		existingRecipient, err := txRecipientRepo.GetByBotIDAndChatID(forwarderBot.ID, user.TelegramUserID)
+		if err == nil && existingRecipient != nil {
+			s.logger.Debug("Manager is already a recipient, skipping",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", forwarderBot.ID.String()))
+		} else {
+			// Create recipient for manager
+			recipient := &models.Recipient{
+				BotID:         forwarderBot.ID,
+				RecipientType: models.RecipientTypeUser,
+				ChatID:        user.TelegramUserID,
+			}
+
+			if err := txRecipientRepo.Create(recipient); err != nil {
+				s.logger.Error("Failed to add manager as recipient in transaction",
+					zap.Int64("user_id", userID),
+					zap.String("bot_id", forwarderBot.ID.String()),
+					zap.Error(err))
+				// Return error to rollback bot creation
+				return fmt.Errorf("failed to add manager as recipient: %w", err)
+			}
+
+			s.logger.Debug("Manager added as recipient successfully in transaction",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", forwarderBot.ID.String()),
+				zap.String("recipient_id", recipient.ID.String()))
+		}
+
+		// 3. Log audit
+		s.logger.Debug("Creating audit log in transaction",
+			zap.Int64("user_id", userID),
+			zap.String("bot_id", forwarderBot.ID.String()))
+		details, _ := json.Marshal(map[string]interface{}{
+			"bot_id":   forwarderBot.ID.String(),
+			"bot_name": forwarderBot.Name,
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &user.ID,
+			ActionType:   models.AuditLogActionAddBot,
+			ResourceType: "bot",
+			ResourceID:   forwarderBot.ID,
+			Details:      string(details),
+		}
+		if err := txAuditRepo.Create(auditLog); err != nil {
+			s.logger.Error("Failed to create audit log in transaction",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", forwarderBot.ID.String()),
+				zap.Error(err))
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+
+		return nil // Transaction committed
+	})
+	if err != nil {
+		return nil, err
+	}
+	return forwarderBot, nil
+}
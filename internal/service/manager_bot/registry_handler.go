@@ -0,0 +1,83 @@
+package manager_bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+const registryUsage = "Usage: /registry [csv|json]"
+
+// handleRegistry exports all registered ForwarderBots as a CSV or JSON document
+func (s *Service) handleRegistry(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /registry command",
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	format := "csv"
+	args := strings.Fields(update.EffectiveMessage.Text)[1:]
+	if len(args) > 1 {
+		_, err := b.SendMessage(chatID, registryUsage, nil)
+		return err
+	}
+	if len(args) == 1 {
+		format = strings.ToLower(args[0])
+		if format != "csv" && format != "json" {
+			_, err := b.SendMessage(chatID, registryUsage, nil)
+			return err
+		}
+	}
+
+	registry, err := s.statsService.GetBotRegistry()
+	if err != nil {
+		s.logger.Error("Failed to build bot registry", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to build the bot registry. Please try again later.", nil)
+		return err
+	}
+
+	var buf bytes.Buffer
+	if format == "json" {
+		err = registry.WriteJSON(&buf)
+	} else {
+		err = registry.WriteCSV(&buf)
+	}
+	if err != nil {
+		s.logger.Error("Failed to encode bot registry", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to build the bot registry. Please try again later.", nil)
+		return err
+	}
+
+	managers := make(map[string]bool)
+	idle := 0
+	for _, bot := range registry.Bots {
+		managers[bot.ManagerID.String()] = true
+		if bot.LastActivityAt == nil {
+			idle++
+		}
+	}
+	caption := fmt.Sprintf("Bot registry: %d bot(s) of %d manager(s), %d never used",
+		len(registry.Bots), len(managers), idle)
+
+	s.logger.Info("Bot registry exported",
+		zap.Int64("user_id", userID),
+		zap.String("format", format),
+		zap.Int("bots", len(registry.Bots)))
+
+	fileName := fmt.Sprintf("bots-%s.%s", time.Now().In(s.userLocation(userID)).Format("2006-01-02"), format)
+	_, err = b.SendDocument(chatID, gotgbot.InputFileByReader(fileName, &buf), &gotgbot.SendDocumentOpts{
+		Caption: caption,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send bot registry", zap.Error(err))
+	}
+	return err
+}
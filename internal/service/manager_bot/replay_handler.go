@@ -0,0 +1,273 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service"
+	"go-telegram-forwarder-bot/internal/service/message"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+const replayUsage = "Usage:\n" +
+	"/replay <bot> queue - Deliver every message in the retry queue now\n" +
+	"/replay <bot> queue <n,n,...> - Deliver only these messages, numbered as in /retryqueue\n" +
+	"/replay <bot> archive <recipient_chat_id> <from> [to] - Re-deliver the guest messages received in a time range to a recipient\n\n" +
+	"Times are YYYY-MM-DD or YYYY-MM-DDTHH:MM in your timezone; a date alone as the end includes that whole day. Without an end, messages up to now are replayed."
+
+// replayQueueLimit is the most queued messages one replay delivers
+const replayQueueLimit = 500
+
+// replayTimeLayouts are the accepted formats of /replay archive times
+var replayTimeLayouts = []string{"2006-01-02T15:04", "2006-01-02"}
+
+// handleReplay re-delivers selected messages of the retry queue, or the
+// messages a bot received in a time range to one recipient. The replay runs in
+// the background, keeping a progress message up to date.
+func (s *Service) handleReplay(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /replay command",
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	if s.botManager == nil {
+		_, err := b.SendMessage(chatID, "Bot manager is not available.", nil)
+		return err
+	}
+
+	parts := strings.Fields(update.EffectiveMessage.Text)
+	if len(parts) < 3 {
+		_, err := b.SendMessage(chatID, replayUsage, nil)
+		return err
+	}
+
+	bot, err := s.findBotByReference(parts[1])
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+		return err
+	}
+
+	if !s.IsSuperuser(userID) {
+		isManager, err := s.IsBotManager(userID, bot.ID)
+		if err != nil {
+			s.logger.Warn("Failed to check bot manager status", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to verify permissions. Please try again later.", nil)
+			return err
+		}
+		if !isManager {
+			s.logger.Debug("Access denied for /replay",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", bot.ID.String()))
+			s.accessDenied(ctx, update, "/replay")
+			_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+			return err
+		}
+	}
+
+	var run func(progress func(message.ReplayReport)) (message.ReplayReport, error)
+	details := map[string]interface{}{"bot_name": bot.Name, "mode": strings.ToLower(parts[2])}
+	switch strings.ToLower(parts[2]) {
+	case "queue":
+		if s.deliveryQueue == nil {
+			_, err := b.SendMessage(chatID, "The retry queue is not available.", nil)
+			return err
+		}
+		deliveries, err := s.deliveryQueue.GetByBotID(bot.ID, replayQueueLimit)
+		if err != nil {
+			s.logger.Error("Failed to load retry queue", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to load the retry queue. Please try again later.", nil)
+			return err
+		}
+		if len(parts) > 3 {
+			deliveries, err = selectDeliveries(deliveries, strings.Join(parts[3:], ""))
+			if err != nil {
+				_, err := b.SendMessage(chatID, err.Error(), nil)
+				return err
+			}
+		}
+		if len(deliveries) == 0 {
+			_, err := b.SendMessage(chatID, fmt.Sprintf("No messages of @%s are waiting to be delivered again.", bot.Name), nil)
+			return err
+		}
+		details["selected"] = len(deliveries)
+		run = func(progress func(message.ReplayReport)) (message.ReplayReport, error) {
+			return s.botManager.ReplayQueued(ctx, bot.ID, deliveries, progress)
+		}
+	case "archive":
+		if s.featureFlags != nil {
+			enabled, err := s.featureFlags.IsEnabledForBot(bot.ID, service.FeatureArchive)
+			if err != nil {
+				s.logger.Error("Failed to check archive feature", zap.Error(err))
+				_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+				return err
+			}
+			if !enabled {
+				_, err := b.SendMessage(chatID, "Archive replays are not included in the plan of this bot's manager.", nil)
+				return err
+			}
+		}
+		if len(parts) < 5 || len(parts) > 6 {
+			_, err := b.SendMessage(chatID, replayUsage, nil)
+			return err
+		}
+		recipientChatID, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			_, err := b.SendMessage(chatID, fmt.Sprintf("Invalid recipient chat ID %q.", parts[3]), nil)
+			return err
+		}
+		recipient, err := s.recipientRepo.GetByBotIDAndChatID(bot.ID, recipientChatID)
+		if err != nil {
+			_, err := b.SendMessage(chatID,
+				fmt.Sprintf("%d is not a recipient of @%s. Add it with /addrecipient in the bot first.", recipientChatID, bot.Name), nil)
+			return err
+		}
+
+		loc := s.userLocation(userID)
+		start, _, err := parseReplayTime(parts[4], loc)
+		if err != nil {
+			_, err := b.SendMessage(chatID, err.Error(), nil)
+			return err
+		}
+		end := time.Now()
+		if len(parts) == 6 {
+			var dateOnly bool
+			end, dateOnly, err = parseReplayTime(parts[5], loc)
+			if err != nil {
+				_, err := b.SendMessage(chatID, err.Error(), nil)
+				return err
+			}
+			if dateOnly {
+				end = end.AddDate(0, 0, 1)
+			}
+		}
+		if !end.After(start) {
+			_, err := b.SendMessage(chatID, "The end of the time range must be after its start.", nil)
+			return err
+		}
+		details["recipient_chat_id"] = recipientChatID
+		details["start"] = start
+		details["end"] = end
+		run = func(progress func(message.ReplayReport)) (message.ReplayReport, error) {
+			return s.botManager.ReplayArchived(ctx, bot.ID, recipient, start, end, progress)
+		}
+	default:
+		_, err := b.SendMessage(chatID, replayUsage, nil)
+		return err
+	}
+
+	if _, running := s.replays.LoadOrStore(bot.ID, true); running {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("A replay of @%s is already running. Wait for its report before starting another.", bot.Name), nil)
+		return err
+	}
+
+	progress := s.startProgress(b, chatID, "")
+	// Replays are paced to stay within Telegram's limits, so run them outside the update
+	go func() {
+		defer s.replays.Delete(bot.ID)
+		defer progress.Close()
+
+		report, err := run(func(report message.ReplayReport) {
+			progress.Count("Replaying...", report.Done(), report.Total)
+		})
+		switch {
+		case errors.Is(err, message.ErrReplayTooLarge):
+			progress.Finish(fmt.Sprintf("❌ The time range holds more than %d messages. Please replay it in smaller parts.", message.ReplayArchiveLimit))
+			return
+		case err != nil:
+			s.logger.Warn("Failed to replay messages",
+				zap.String("bot_id", bot.ID.String()),
+				zap.Error(err))
+			progress.Finish(fmt.Sprintf("❌ Failed to replay messages of @%s: %v", bot.Name, err))
+			return
+		}
+		progress.Finish(formatReplayReport(bot, report))
+
+		details["delivered"] = report.Delivered
+		details["skipped"] = report.Skipped
+		details["failed"] = report.Failed
+		details["cancelled"] = report.Cancelled
+		s.recordReplayAudit(update, bot, details)
+	}()
+	return nil
+}
+
+// selectDeliveries picks the deliveries numbered in list, e.g. "1,3,5", where
+// 1 is the oldest as listed by /retryqueue
+func selectDeliveries(deliveries []*models.PendingDelivery, list string) ([]*models.PendingDelivery, error) {
+	var selected []*models.PendingDelivery
+	seen := make(map[int]bool)
+	for _, field := range strings.Split(list, ",") {
+		if field == "" {
+			continue
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > len(deliveries) {
+			return nil, fmt.Errorf("No queued message number %s. Use the numbers shown by /retryqueue.", field)
+		}
+		if !seen[n] {
+			seen[n] = true
+			selected = append(selected, deliveries[n-1])
+		}
+	}
+	return selected, nil
+}
+
+// parseReplayTime parses a /replay archive time in loc and reports whether it
+// was a date without a time
+func parseReplayTime(value string, loc *time.Location) (time.Time, bool, error) {
+	for _, layout := range replayTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, !strings.Contains(layout, "T"), nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("Invalid time %q. Use YYYY-MM-DD or YYYY-MM-DDTHH:MM.", value)
+}
+
+// formatReplayReport describes the outcome of a replay
+func formatReplayReport(bot *models.ForwarderBot, report message.ReplayReport) string {
+	var text strings.Builder
+	if report.Cancelled {
+		fmt.Fprintf(&text, "🔁 Replay of @%s stopped after %s of %s messages because the bot was stopped.\n\n",
+			bot.Name, utils.FormatNumber(int64(report.Done())), utils.FormatNumber(int64(report.Total)))
+	} else {
+		fmt.Fprintf(&text, "🔁 Replay of @%s finished: %s messages\n\n", bot.Name, utils.FormatNumber(int64(report.Total)))
+	}
+	fmt.Fprintf(&text, "Delivered: %s\n", utils.FormatNumber(int64(report.Delivered)))
+	if report.Skipped > 0 {
+		fmt.Fprintf(&text, "Already delivered: %s\n", utils.FormatNumber(int64(report.Skipped)))
+	}
+	if report.Failed > 0 {
+		fmt.Fprintf(&text, "Failed: %s (see /retryqueue and the logs)\n", utils.FormatNumber(int64(report.Failed)))
+	}
+	return text.String()
+}
+
+// recordReplayAudit logs a replay and its outcome
+func (s *Service) recordReplayAudit(update *ext.Context, bot *models.ForwarderBot, details map[string]interface{}) {
+	user, err := s.commandUser(update)
+	if err != nil {
+		s.logger.Warn("Failed to get user for audit log", zap.Error(err))
+		return
+	}
+
+	data, _ := json.Marshal(details)
+	s.auditLogRepo.Create(&models.AuditLog{
+		UserID:       &user.ID,
+		ActionType:   models.AuditLogActionReplay,
+		ResourceType: "bot",
+		ResourceID:   bot.ID,
+		Details:      string(data),
+	})
+}
@@ -0,0 +1,202 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxReportsListed limits how many open reports /reports shows at once
+const maxReportsListed = 10
+
+func (s *Service) handleReports(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /reports command",
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	reports, err := s.reportRepo.GetByStatus(models.ReportStatusOpen, maxReportsListed)
+	if err != nil {
+		s.logger.Error("Failed to get open reports", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to retrieve reports. Please try again later.", nil)
+		return err
+	}
+
+	if len(reports) == 0 {
+		_, err := b.SendMessage(chatID, "There are no open reports.", nil)
+		return err
+	}
+
+	loc := s.userLocation(update.EffectiveUser.Id)
+	for _, report := range reports {
+		keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{
+				{Text: "Resolve", CallbackData: fmt.Sprintf("report:resolve:%s", report.ID.String())},
+				{Text: "Dismiss", CallbackData: fmt.Sprintf("report:dismiss:%s", report.ID.String())},
+			},
+			{
+				{Text: "View Bot", CallbackData: fmt.Sprintf("manage:bot:%s", report.BotID.String())},
+			},
+		}}
+		_, err := b.SendMessage(chatID, formatReport(report, loc), &gotgbot.SendMessageOpts{
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+		if err != nil {
+			s.logger.Warn("Failed to send report entry",
+				zap.String("report_id", report.ID.String()),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// formatReport renders a report for display in the ManagerBot
+func formatReport(report *models.Report, loc *time.Location) string {
+	reporter := fmt.Sprintf("%d", report.ReporterUserID)
+	if report.ReporterUsername != nil {
+		reporter = fmt.Sprintf("@%s (%d)", *report.ReporterUsername, report.ReporterUserID)
+	}
+
+	message := fmt.Sprintf(
+		"*Report*\n\n"+
+			"Bot: @%s\n"+
+			"Manager ID: %d\n"+
+			"Reporter: %s\n"+
+			"Reason: %s\n"+
+			"Status: %s\n"+
+			"Created: %s",
+		utils.EscapeMarkdown(report.Bot.Name),
+		report.Bot.Manager.TelegramUserID,
+		utils.EscapeMarkdown(reporter),
+		utils.EscapeMarkdown(report.Reason),
+		report.Status,
+		utils.FormatTimestamp(report.CreatedAt, loc),
+	)
+	if report.ResolvedAt != nil {
+		message += fmt.Sprintf("\nClosed: %s", utils.FormatTimestamp(*report.ResolvedAt, loc))
+	}
+	return message
+}
+
+func (s *Service) handleReportCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	userID := update.EffectiveUser.Id
+
+	if len(parts) < 2 {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+
+	var status models.ReportStatus
+	switch parts[0] {
+	case "resolve":
+		status = models.ReportStatusResolved
+	case "dismiss":
+		status = models.ReportStatusDismissed
+	default:
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Unknown action",
+		})
+		return err
+	}
+
+	reportID, err := uuid.Parse(parts[1])
+	if err != nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid report ID",
+		})
+		return err
+	}
+
+	username := update.EffectiveUser.Username
+	var usernamePtr *string
+	if username != "" {
+		usernamePtr = &username
+	}
+	superuser, err := s.userRepo.GetOrCreateByTelegramUserID(userID, usernamePtr)
+	if err != nil {
+		s.logger.Error("Failed to get or create user", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "An error occurred. Please try again later.",
+		})
+		return err
+	}
+
+	closed, err := s.reportRepo.CloseOpen(reportID, status, superuser.ID)
+	if err != nil {
+		s.logger.Error("Failed to close report",
+			zap.String("report_id", reportID.String()),
+			zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to update report",
+		})
+		return err
+	}
+
+	report, err := s.reportRepo.GetByID(reportID)
+	if err != nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to load report",
+		})
+		return err
+	}
+
+	answer := fmt.Sprintf("Report %s", status)
+	if !closed {
+		answer = fmt.Sprintf("Report was already %s", report.Status)
+	} else {
+		details, _ := json.Marshal(map[string]interface{}{
+			"report_id": reportID.String(),
+			"bot_id":    report.BotID.String(),
+			"status":    string(status),
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &superuser.ID,
+			ActionType:   models.AuditLogActionCloseReport,
+			ResourceType: "report",
+			ResourceID:   reportID,
+			Details:      string(details),
+		}
+		s.auditLogRepo.Create(auditLog)
+
+		s.logger.Info("Report closed",
+			zap.String("report_id", reportID.String()),
+			zap.String("status", string(status)),
+			zap.Int64("superuser_id", userID))
+	}
+
+	_, err = b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: answer,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	messageID, err := getMessageIDFromCallback(update.CallbackQuery.Message)
+	if err != nil {
+		s.logger.Warn("Failed to get message ID from callback", zap.Error(err))
+		return nil
+	}
+	_, _, err = b.EditMessageText(formatReport(report, s.userLocation(update.EffectiveUser.Id)), &gotgbot.EditMessageTextOpts{
+		ChatId:    update.EffectiveChat.Id,
+		MessageId: messageID,
+		ParseMode: "Markdown",
+	})
+	if err != nil {
+		s.logger.Warn("Failed to edit report message", zap.Error(err))
+	}
+	return nil
+}
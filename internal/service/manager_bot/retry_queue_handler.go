@@ -0,0 +1,185 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+const retryQueueUsage = "Usage:\n" +
+	"/retryqueue <bot_id|@bot_username> - Show messages waiting to be delivered again\n" +
+	"/retryqueue <bot> flush - Try to deliver them now\n" +
+	"/retryqueue <bot> clear - Drop them without delivering"
+
+// retryQueueListLimit is the most queued messages /retryqueue lists
+const retryQueueListLimit = 10
+
+// handleRetryQueue shows, flushes or clears a bot's retry queue
+func (s *Service) handleRetryQueue(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /retryqueue command",
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	if s.deliveryQueue == nil {
+		_, err := b.SendMessage(chatID, "The retry queue is not available.", nil)
+		return err
+	}
+
+	parts := strings.Fields(update.EffectiveMessage.Text)
+	if len(parts) < 2 || len(parts) > 3 {
+		_, err := b.SendMessage(chatID, retryQueueUsage, nil)
+		return err
+	}
+
+	bot, err := s.findBotByReference(parts[1])
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+		return err
+	}
+
+	if !s.IsSuperuser(userID) {
+		isManager, err := s.IsBotManager(userID, bot.ID)
+		if err != nil {
+			s.logger.Warn("Failed to check bot manager status", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to verify permissions. Please try again later.", nil)
+			return err
+		}
+		if !isManager {
+			s.logger.Debug("Access denied for /retryqueue",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", bot.ID.String()))
+			s.accessDenied(ctx, update, "/retryqueue")
+			_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+			return err
+		}
+	}
+
+	action := ""
+	if len(parts) == 3 {
+		action = strings.ToLower(parts[2])
+	}
+	switch action {
+	case "":
+		return s.showRetryQueue(b, chatID, userID, bot)
+	case "flush":
+		if s.botManager == nil {
+			_, err := b.SendMessage(chatID, "Bot manager is not available.", nil)
+			return err
+		}
+		delivered, err := s.botManager.FlushDeliveryQueue(ctx, bot.ID)
+		if err != nil {
+			s.logger.Warn("Failed to flush retry queue",
+				zap.String("bot_id", bot.ID.String()),
+				zap.Error(err))
+			_, err := b.SendMessage(chatID, fmt.Sprintf("❌ Failed to flush the retry queue of @%s: %v", bot.Name, err), nil)
+			return err
+		}
+		remaining, err := s.deliveryQueue.CountByBotID(bot.ID)
+		if err != nil {
+			s.logger.Warn("Failed to count retry queue", zap.Error(err))
+		}
+		_, err = b.SendMessage(chatID, fmt.Sprintf("✅ Delivered %d queued message(s) of @%s, %d still waiting.",
+			delivered, bot.Name, remaining), nil)
+		return err
+	case "clear":
+		cleared, err := s.deliveryQueue.DeleteByBotID(bot.ID)
+		if err != nil {
+			s.logger.Error("Failed to clear retry queue",
+				zap.String("bot_id", bot.ID.String()),
+				zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to clear the retry queue. Please try again later.", nil)
+			return err
+		}
+		s.logger.Info("Retry queue cleared",
+			zap.String("bot_id", bot.ID.String()),
+			zap.Int64("user_id", userID),
+			zap.Int64("cleared", cleared))
+		s.recordRetryQueueAudit(update, bot, cleared)
+		_, err = b.SendMessage(chatID, fmt.Sprintf("🗑 Dropped %d queued message(s) of @%s.", cleared, bot.Name), nil)
+		return err
+	default:
+		_, err := b.SendMessage(chatID, retryQueueUsage, nil)
+		return err
+	}
+}
+
+// showRetryQueue lists the oldest messages in the bot's retry queue
+func (s *Service) showRetryQueue(b *gotgbot.Bot, chatID int64, viewerID int64, bot *models.ForwarderBot) error {
+	count, err := s.deliveryQueue.CountByBotID(bot.ID)
+	if err != nil {
+		s.logger.Error("Failed to count retry queue", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to load the retry queue. Please try again later.", nil)
+		return err
+	}
+	if count == 0 {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("No messages of @%s are waiting to be delivered again.", bot.Name), nil)
+		return err
+	}
+	deliveries, err := s.deliveryQueue.GetByBotID(bot.ID, retryQueueListLimit)
+	if err != nil {
+		s.logger.Error("Failed to load retry queue", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to load the retry queue. Please try again later.", nil)
+		return err
+	}
+
+	loc := s.userLocation(viewerID)
+	var text strings.Builder
+	fmt.Fprintf(&text, "📮 %d message(s) of @%s are waiting to be delivered again:\n", count, bot.Name)
+	for i, delivery := range deliveries {
+		fmt.Fprintf(&text, "\n%d. Guest %d, message %d - queued %s, %d attempt(s), next at %s",
+			i+1,
+			delivery.GuestChatID,
+			delivery.GuestMessageID,
+			utils.FormatTimestamp(delivery.CreatedAt, loc),
+			delivery.Attempts,
+			utils.FormatTimestamp(delivery.NextAttemptAt, loc))
+		if delivery.LastError != "" {
+			lastError := delivery.LastError
+			if runes := []rune(lastError); len(runes) > 100 {
+				lastError = string(runes[:99]) + "…"
+			}
+			fmt.Fprintf(&text, "\n  Last error: %s", lastError)
+		}
+	}
+	if count > int64(len(deliveries)) {
+		fmt.Fprintf(&text, "\n\n…and %d more.", count-int64(len(deliveries)))
+	}
+	text.WriteString("\n\nUse /retryqueue " + bot.ID.String() + " flush to try them now, or clear to drop them. " +
+		"To deliver only some of them, use /replay " + bot.ID.String() + " queue <n,n,...>.")
+
+	_, err = b.SendMessage(chatID, text.String(), nil)
+	return err
+}
+
+// recordRetryQueueAudit logs a bot's retry queue being cleared
+func (s *Service) recordRetryQueueAudit(update *ext.Context, bot *models.ForwarderBot, cleared int64) {
+	user, err := s.commandUser(update)
+	if err != nil {
+		s.logger.Warn("Failed to get user for audit log", zap.Error(err))
+		return
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"bot_name": bot.Name,
+		"cleared":  cleared,
+	})
+	auditLog := &models.AuditLog{
+		UserID:       &user.ID,
+		ActionType:   models.AuditLogActionClearRetryQueue,
+		ResourceType: "bot",
+		ResourceID:   bot.ID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+}
@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
 	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/service"
+	"go-telegram-forwarder-bot/internal/service/message"
+	"go-telegram-forwarder-bot/internal/service/settings"
 	"go-telegram-forwarder-bot/internal/service/statistics"
 	"go-telegram-forwarder-bot/internal/utils"
 
@@ -22,6 +27,62 @@ import (
 type BotManagerInterface interface {
 	StartBot(botID interface{}) error
 	StopBot(botID interface{}) error
+	RestartBot(botID interface{}) error
+	// FlushDeliveryQueue tries the queued deliveries of a running bot now and
+	// returns how many were delivered
+	FlushDeliveryQueue(ctx context.Context, botID uuid.UUID) (int, error)
+	// ReplayQueued delivers the given queued deliveries of a running bot now
+	ReplayQueued(ctx context.Context, botID uuid.UUID, deliveries []*models.PendingDelivery, progress func(message.ReplayReport)) (message.ReplayReport, error)
+	// ReplayArchived re-delivers the messages a running bot received in
+	// [start, end) to one of its recipients
+	ReplayArchived(ctx context.Context, botID uuid.UUID, recipient *models.Recipient, start time.Time, end time.Time, progress func(message.ReplayReport)) (message.ReplayReport, error)
+}
+
+// QuotaEnforcerInterface exposes monthly quota usage to superuser commands
+type QuotaEnforcerInterface interface {
+	GetUsage(botID uuid.UUID) (*models.BotUsage, error)
+	QuotaChanged(botID uuid.UUID) error
+}
+
+// FeatureFlagsInterface decides which features a manager's tier includes
+type FeatureFlagsInterface interface {
+	EffectiveTier(user *models.User) models.UserTier
+	MaxBots(user *models.User) int
+	CanAddBot(user *models.User) (bool, int, error)
+	IsEnabledForBot(botID uuid.UUID, feature service.Feature) (bool, error)
+}
+
+// BotInfoRefresherInterface re-reads a ForwarderBot's username and Telegram ID via GetMe
+type BotInfoRefresherInterface interface {
+	Refresh(ctx context.Context, b *gotgbot.Bot, botID uuid.UUID) (*models.ForwarderBot, error)
+}
+
+// AuditLogMonitorInterface reports how the background audit log writer is doing
+type AuditLogMonitorInterface interface {
+	Pending() int
+	Overflowed() int64
+	Failed() int64
+}
+
+// MappingPrunerInterface reports what the mapping_prune worker deleted on this instance
+type MappingPrunerInterface interface {
+	Pruned() int64
+	LastRun() (time.Time, int64)
+}
+
+// SuperusersInterface is the list of superusers, which can be changed at runtime
+type SuperusersInterface interface {
+	IsSuperuser(telegramUserID int64) bool
+	IsConfigured(telegramUserID int64) bool
+	List() []int64
+	Add(telegramUserID int64, addedBy uuid.UUID) (bool, error)
+	Remove(telegramUserID int64) (bool, error)
+}
+
+// AccessMonitorInterface audits commands and buttons refused for lack of
+// permission and alerts superusers about users who keep trying
+type AccessMonitorInterface interface {
+	Denied(ctx context.Context, telegramUserID int64, username string, botID uuid.UUID, action string)
 }
 
 type Service struct {
@@ -30,12 +91,25 @@ type Service struct {
 	userRepo      repository.UserRepository
 	auditLogRepo  repository.AuditLogRepository
 	recipientRepo repository.RecipientRepository
+	reportRepo    repository.ReportRepository
 	statsService  *statistics.Service
 	config        *config.Config
 	logger        *zap.Logger
 	encryptionKey []byte
 	botManager    BotManagerInterface
+	quotaEnforcer QuotaEnforcerInterface
+	featureFlags  FeatureFlagsInterface
+	settings      *settings.Service
+	botInfo       BotInfoRefresherInterface
+	auditMonitor  AuditLogMonitorInterface
+	mappingPruner MappingPrunerInterface
+	superusers    SuperusersInterface
+	lockdown      *service.Lockdown
+	deliveryQueue repository.PendingDeliveryRepository
+	deadLetters   repository.DeadLetterRepository
+	accessMonitor AccessMonitorInterface
 	commandsCache sync.Map // Cache to track users whose commands have been updated
+	replays       sync.Map // Bots with a /replay running
 }
 
 func NewService(
@@ -44,6 +118,7 @@ func NewService(
 	userRepo repository.UserRepository,
 	auditLogRepo repository.AuditLogRepository,
 	recipientRepo repository.RecipientRepository,
+	reportRepo repository.ReportRepository,
 	statsService *statistics.Service,
 	cfg *config.Config,
 	logger *zap.Logger,
@@ -59,6 +134,7 @@ func NewService(
 		userRepo:      userRepo,
 		auditLogRepo:  auditLogRepo,
 		recipientRepo: recipientRepo,
+		reportRepo:    reportRepo,
 		statsService:  statsService,
 		config:        cfg,
 		logger:        logger,
@@ -72,6 +148,70 @@ func (s *Service) SetBotManager(botManager BotManagerInterface) {
 	s.botManager = botManager
 }
 
+// SetQuotaEnforcer sets the quota enforcer used by /setquota and the bot view
+func (s *Service) SetQuotaEnforcer(enforcer QuotaEnforcerInterface) {
+	s.quotaEnforcer = enforcer
+}
+
+// SetFeatureFlags sets the tier feature flags consulted by /addbot and /mybots
+func (s *Service) SetFeatureFlags(flags FeatureFlagsInterface) {
+	s.featureFlags = flags
+}
+
+// SetSettings sets the per-bot settings service behind the settings menu
+func (s *Service) SetSettings(settingsService *settings.Service) {
+	s.settings = settingsService
+}
+
+// SetBotInfoRefresher sets the refresher behind the "Refresh info" button in the bot view
+func (s *Service) SetBotInfoRefresher(refresher BotInfoRefresherInterface) {
+	s.botInfo = refresher
+}
+
+// SetAuditLogMonitor sets the audit log writer whose health /stats shows
+func (s *Service) SetAuditLogMonitor(monitor AuditLogMonitorInterface) {
+	s.auditMonitor = monitor
+}
+
+// SetMappingPruner sets the message mapping pruner whose counters /stats shows
+func (s *Service) SetMappingPruner(pruner MappingPrunerInterface) {
+	s.mappingPruner = pruner
+}
+
+// SetSuperusers sets the superuser list consulted by IsSuperuser and changed
+// by /addsuper and /delsuper
+func (s *Service) SetSuperusers(superusers SuperusersInterface) {
+	s.superusers = superusers
+}
+
+// SetLockdown sets the lockdown switched by /lockdown
+func (s *Service) SetLockdown(lockdown *service.Lockdown) {
+	s.lockdown = lockdown
+}
+
+// SetDeliveryQueue sets the retry queue shown and flushed by /retryqueue
+func (s *Service) SetDeliveryQueue(queue repository.PendingDeliveryRepository) {
+	s.deliveryQueue = queue
+}
+
+// SetDeadLetters sets the dead letters shown, requeued and discarded from the bot view
+func (s *Service) SetDeadLetters(deadLetters repository.DeadLetterRepository) {
+	s.deadLetters = deadLetters
+}
+
+// SetAccessMonitor sets the monitor told about refused commands and buttons
+func (s *Service) SetAccessMonitor(monitor AccessMonitorInterface) {
+	s.accessMonitor = monitor
+}
+
+// accessDenied records that the user of the update was refused the action
+func (s *Service) accessDenied(ctx context.Context, update *ext.Context, action string) {
+	if s.accessMonitor == nil || update.EffectiveUser == nil {
+		return
+	}
+	s.accessMonitor.Denied(ctx, update.EffectiveUser.Id, update.EffectiveUser.Username, uuid.Nil, action)
+}
+
 // updateCommands updates the command menu for all users (global commands)
 func (s *Service) updateCommands(_ context.Context, b *gotgbot.Bot) {
 	// Check cache to avoid frequent API calls
@@ -93,6 +233,34 @@ func (s *Service) updateCommands(_ context.Context, b *gotgbot.Bot) {
 		Command:     "mybots",
 		Description: "List all your ForwarderBots",
 	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "settoken",
+		Description: "Replace the token of one of your bots",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "retryqueue",
+		Description: "Show messages waiting to be delivered again",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "replay",
+		Description: "Replay failed or archived messages of a bot",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "timezone",
+		Description: "Set your timezone for displayed times",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "language",
+		Description: "Choose the language the bots use with you",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "settings",
+		Description: "Open the settings of one of your bots",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "importsettings",
+		Description: "Import bot settings from a file (reply to it)",
+	})
 	commands = append(commands, gotgbot.BotCommand{
 		Command:     "manage",
 		Description: "Open management menu",
@@ -101,6 +269,46 @@ func (s *Service) updateCommands(_ context.Context, b *gotgbot.Bot) {
 		Command:     "stats",
 		Description: "View global statistics",
 	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "suspendmanager",
+		Description: "Suspend a manager and pause their bots",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "unsuspendmanager",
+		Description: "Lift a manager suspension",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "reports",
+		Description: "Review open abuse reports",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "setquota",
+		Description: "Set a bot's monthly message quota",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "usage",
+		Description: "Export monthly usage per manager",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "registry",
+		Description: "Export all registered bots",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "settier",
+		Description: "Set a manager's subscription tier",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "addsuper",
+		Description: "Make a user a superuser",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "delsuper",
+		Description: "Remove a superuser",
+	})
+	commands = append(commands, gotgbot.BotCommand{
+		Command:     "lockdown",
+		Description: "Disable commands for everyone but superusers",
+	})
 
 	// Set commands for private chats (default scope)
 	scope := gotgbot.BotCommandScopeDefault{}
@@ -115,13 +323,17 @@ func (s *Service) updateCommands(_ context.Context, b *gotgbot.Bot) {
 		return
 	}
 
-	// Set commands for group chats
+	// Set commands for group chats, where only /help is answered
 	groupScope := gotgbot.BotCommandScopeAllGroupChats{}
 	groupOpts := &gotgbot.SetMyCommandsOpts{
 		Scope: groupScope,
 	}
 
-	_, err = b.SetMyCommands(commands, groupOpts)
+	groupCommands := []gotgbot.BotCommand{{
+		Command:     "help",
+		Description: "Show help message",
+	}}
+	_, err = b.SetMyCommands(groupCommands, groupOpts)
 	if err != nil {
 		s.logger.Warn("Failed to set commands for group chats",
 			zap.Error(err))
@@ -146,10 +358,14 @@ func (s *Service) updateCommands(_ context.Context, b *gotgbot.Bot) {
 }
 
 func (s *Service) IsSuperuser(userID int64) bool {
+	superusers := s.config.ManagerBot.Superusers
+	if s.superusers != nil {
+		superusers = s.superusers.List()
+	}
 	s.logger.Debug("Checking superuser status",
 		zap.Int64("user_id", userID),
-		zap.Int64s("superusers", s.config.ManagerBot.Superusers))
-	for _, superuserID := range s.config.ManagerBot.Superusers {
+		zap.Int64s("superusers", superusers))
+	for _, superuserID := range superusers {
 		if superuserID == userID {
 			s.logger.Debug("User is superuser",
 				zap.Int64("user_id", userID))
@@ -208,6 +424,14 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 		zap.Int64("chat_id", chatID),
 		zap.String("command", command))
 
+	if s.lockedOut(b, update) {
+		return nil
+	}
+
+	if s.outsidePrivateChat(b, update) {
+		return nil
+	}
+
 	switch {
 	case strings.HasPrefix(command, "/help"):
 		s.logger.Debug("Handling /help command",
@@ -237,6 +461,48 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 				zap.Int64("user_id", userID))
 		}
 		return err
+	case strings.HasPrefix(command, "/settoken"):
+		s.logger.Debug("Handling /settoken command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		err := s.handleSetToken(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/settoken command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/settoken command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/retryqueue"):
+		s.logger.Debug("Handling /retryqueue command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		err := s.handleRetryQueue(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/retryqueue command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/retryqueue command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/replay"):
+		s.logger.Debug("Handling /replay command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		err := s.handleReplay(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/replay command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/replay command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
 	case strings.HasPrefix(command, "/mybots"):
 		s.logger.Debug("Handling /mybots command",
 			zap.Int64("user_id", userID),
@@ -251,6 +517,62 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 				zap.Int64("user_id", userID))
 		}
 		return err
+	case strings.HasPrefix(command, "/timezone"):
+		s.logger.Debug("Handling /timezone command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		err := s.handleTimezone(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/timezone command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/timezone command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/language"):
+		s.logger.Debug("Handling /language command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		err := s.handleLanguage(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/language command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/language command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/settings"):
+		s.logger.Debug("Handling /settings command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		err := s.handleSettings(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/settings command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/settings command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/importsettings"):
+		s.logger.Debug("Handling /importsettings command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		err := s.handleImportSettings(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/importsettings command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/importsettings command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
 	case strings.HasPrefix(command, "/manage"):
 		s.logger.Debug("Handling /manage command",
 			zap.Int64("user_id", userID),
@@ -258,6 +580,7 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 		if !s.IsSuperuser(userID) {
 			s.logger.Debug("Access denied for /manage command",
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/manage command")
 			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
 			return err
 		}
@@ -278,6 +601,7 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 		if !s.IsSuperuser(userID) {
 			s.logger.Debug("Access denied for /stats command",
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/stats command")
 			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
 			return err
 		}
@@ -291,6 +615,216 @@ func (s *Service) HandleCommand(ctx context.Context, b *gotgbot.Bot, update *ext
 				zap.Int64("user_id", userID))
 		}
 		return err
+	case strings.HasPrefix(command, "/suspendmanager"):
+		s.logger.Debug("Handling /suspendmanager command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /suspendmanager command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/suspendmanager command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleSuspendManager(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/suspendmanager command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/suspendmanager command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/unsuspendmanager"):
+		s.logger.Debug("Handling /unsuspendmanager command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /unsuspendmanager command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/unsuspendmanager command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleUnsuspendManager(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/unsuspendmanager command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/unsuspendmanager command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/setquota"):
+		s.logger.Debug("Handling /setquota command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /setquota command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/setquota command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleSetQuota(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/setquota command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/setquota command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/usage"):
+		s.logger.Debug("Handling /usage command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /usage command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/usage command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleUsage(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/usage command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/usage command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/registry"):
+		s.logger.Debug("Handling /registry command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /registry command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/registry command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleRegistry(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/registry command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/registry command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/settier"):
+		s.logger.Debug("Handling /settier command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /settier command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/settier command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleSetTier(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/settier command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/settier command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/addsuper"):
+		s.logger.Debug("Handling /addsuper command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /addsuper command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/addsuper command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleAddSuperuser(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/addsuper command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/addsuper command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/delsuper"):
+		s.logger.Debug("Handling /delsuper command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /delsuper command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/delsuper command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleDelSuperuser(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/delsuper command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/delsuper command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/lockdown"):
+		s.logger.Debug("Handling /lockdown command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /lockdown command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/lockdown command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleLockdown(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/lockdown command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/lockdown command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
+	case strings.HasPrefix(command, "/reports"):
+		s.logger.Debug("Handling /reports command",
+			zap.Int64("user_id", userID),
+			zap.Int64("chat_id", chatID))
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for /reports command",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "/reports command")
+			_, err := b.SendMessage(update.EffectiveChat.Id, "You are not authorized to use this command.", nil)
+			return err
+		}
+		err := s.handleReports(ctx, b, update)
+		if err != nil {
+			s.logger.Debug("/reports command failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		} else {
+			s.logger.Debug("/reports command succeeded",
+				zap.Int64("user_id", userID))
+		}
+		return err
 	default:
 		s.logger.Debug("Unknown command received",
 			zap.Int64("user_id", userID),
@@ -322,6 +856,18 @@ func (s *Service) HandleCallback(ctx context.Context, b *gotgbot.Bot, update *ex
 		return fmt.Errorf("invalid callback data: %s", data)
 	}
 
+	if s.lockedOut(b, update) {
+		return nil
+	}
+
+	if s.outsidePrivateChat(b, update) {
+		return nil
+	}
+
+	if handled, err := s.guardExpiredMenu(b, update, parts); handled {
+		return err
+	}
+
 	action := parts[0]
 	s.logger.Debug("Processing callback action",
 		zap.Int64("user_id", userID),
@@ -335,6 +881,7 @@ func (s *Service) HandleCallback(ctx context.Context, b *gotgbot.Bot, update *ex
 		if !s.IsSuperuser(userID) {
 			s.logger.Debug("Access denied for manage callback",
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "manage callback")
 			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 				Text: "You are not authorized to access this.",
 			})
@@ -354,6 +901,7 @@ func (s *Service) HandleCallback(ctx context.Context, b *gotgbot.Bot, update *ex
 		if !s.IsSuperuser(userID) {
 			s.logger.Debug("Access denied for manager callback",
 				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "manager callback")
 			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
 				Text: "You are not authorized to access this.",
 			})
@@ -363,6 +911,36 @@ func (s *Service) HandleCallback(ctx context.Context, b *gotgbot.Bot, update *ex
 			zap.Int64("user_id", userID),
 			zap.Strings("sub_parts", parts[1:]))
 		err = s.handleManagerCallback(ctx, b, update, parts[1:])
+	case "report":
+		// Only superusers can review reports
+		if !s.IsSuperuser(userID) {
+			s.logger.Debug("Access denied for report callback",
+				zap.Int64("user_id", userID))
+			s.accessDenied(ctx, update, "report callback")
+			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "You are not authorized to access this.",
+			})
+			return err
+		}
+		s.logger.Debug("Handling report callback",
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleReportCallback(ctx, b, update, parts[1:])
+	case "deadletter":
+		s.logger.Debug("Handling dead letter callback",
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleDeadLetterCallback(ctx, b, update, parts[1:])
+	case "settings":
+		s.logger.Debug("Handling settings callback",
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleSettingsCallback(ctx, b, update, parts[1:])
+	case "language":
+		s.logger.Debug("Handling language callback",
+			zap.Int64("user_id", userID),
+			zap.Strings("sub_parts", parts[1:]))
+		err = s.handleLanguageCallback(ctx, b, update, parts[1:])
 	case "delete_bot":
 		s.logger.Debug("Handling delete_bot callback",
 			zap.Int64("user_id", userID),
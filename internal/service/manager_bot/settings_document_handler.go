@@ -0,0 +1,165 @@
+package manager_bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxSettingsDocumentSize limits the size of settings files accepted by /importsettings
+const maxSettingsDocumentSize = 64 * 1024
+
+const importSettingsUsage = "Usage: reply to an exported settings file with /importsettings <bot_id|@bot_username>\n" +
+	"Export a bot's settings from its Settings menu in /mybots."
+
+// exportSettingsFromCallback sends the bot's settings as a YAML file
+func (s *Service) exportSettingsFromCallback(b *gotgbot.Bot, update *ext.Context, botID uuid.UUID) error {
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		return err
+	}
+	data, err := s.settings.Export(botID)
+	if err != nil {
+		s.logger.Error("Failed to export bot settings",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		_, err := b.SendMessage(update.EffectiveChat.Id, "Failed to export settings. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Bot settings exported",
+		zap.String("bot_id", botID.String()),
+		zap.Int64("user_id", update.EffectiveUser.Id))
+
+	fileName := fmt.Sprintf("%s-settings.yaml", bot.Name)
+	_, err = b.SendDocument(update.EffectiveChat.Id, gotgbot.InputFileByReader(fileName, bytes.NewReader(data)),
+		&gotgbot.SendDocumentOpts{
+			Caption: fmt.Sprintf("Settings of @%s. To apply them to a bot, reply to this file with /importsettings @bot_username.", bot.Name),
+		})
+	if err != nil {
+		s.logger.Error("Failed to send settings file", zap.Error(err))
+	}
+	return err
+}
+
+// handleImportSettings applies a settings file to a bot. The command is sent
+// as a reply to the file; only the bot's manager or a superuser may import.
+func (s *Service) handleImportSettings(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	if s.settings == nil {
+		_, err := b.SendMessage(chatID, "Settings are not available.", nil)
+		return err
+	}
+
+	parts := strings.Fields(update.EffectiveMessage.Text)
+	reply := update.EffectiveMessage.ReplyToMessage
+	if len(parts) < 2 || reply == nil || reply.Document == nil {
+		_, err := b.SendMessage(chatID, importSettingsUsage, nil)
+		return err
+	}
+
+	bot, err := s.findBotByReference(parts[1])
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+		return err
+	}
+
+	if !s.IsSuperuser(userID) {
+		isManager, err := s.IsBotManager(userID, bot.ID)
+		if err != nil {
+			s.logger.Warn("Failed to check bot manager status", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to verify permissions. Please try again later.", nil)
+			return err
+		}
+		if !isManager {
+			s.logger.Debug("Access denied for settings import",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", bot.ID.String()))
+			s.accessDenied(ctx, update, "settings import")
+			_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+			return err
+		}
+	}
+
+	progress := s.startProgress(b, chatID, "")
+	defer progress.Close()
+
+	progress.Stage("Downloading file...")
+	data, err := utils.DownloadFile(ctx, b, reply.Document.FileId, maxSettingsDocumentSize)
+	if err != nil {
+		s.logger.Warn("Failed to download settings file", zap.Error(err))
+		progress.Finish(fmt.Sprintf("Could not read the file: %v", err))
+		return nil
+	}
+
+	progress.Stage("Validating settings...")
+	values, err := settings.ParseDocument(data)
+	if err != nil {
+		progress.Finish(fmt.Sprintf("The file is not a valid settings file: %v", err))
+		return nil
+	}
+
+	progress.Stage("Applying settings...")
+	changed, err := s.settings.Import(bot.ID, values)
+	if err != nil {
+		s.logger.Error("Failed to import bot settings",
+			zap.String("bot_id", bot.ID.String()),
+			zap.Error(err))
+		progress.Finish("Failed to import settings. Please try again later.")
+		return nil
+	}
+
+	s.logger.Info("Bot settings imported",
+		zap.String("bot_id", bot.ID.String()),
+		zap.Int64("user_id", userID),
+		zap.Strings("changed", changed))
+
+	if len(changed) == 0 {
+		progress.Finish(fmt.Sprintf("@%s already has these settings. Nothing changed.", bot.Name))
+		return nil
+	}
+
+	if user, _ := s.userRepo.GetByTelegramUserID(userID); user != nil {
+		details, _ := json.Marshal(map[string]interface{}{
+			"changed": changed,
+			"source":  "import",
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &user.ID,
+			ActionType:   models.AuditLogActionUpdateSetting,
+			ResourceType: "bot",
+			ResourceID:   bot.ID,
+			Details:      string(details),
+		}
+		if err := s.auditLogRepo.Create(auditLog); err != nil {
+			s.logger.Warn("Failed to create audit log", zap.Error(err))
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Imported settings into @%s. Changed:", bot.Name))
+	for _, key := range changed {
+		def, _ := settings.Lookup(key)
+		text.WriteString(fmt.Sprintf("\n- %s", def.Label))
+	}
+	progress.Finish(text.String())
+	return nil
+}
@@ -0,0 +1,358 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/settings"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxSettingPreview is how much of a text setting is shown in the settings menu
+const maxSettingPreview = 60
+
+// handleSettingsCallback handles the per-bot settings menu:
+//
+//	settings:<bot_id>                  category list
+//	settings:<bot_id>:<category>       settings of one category
+//	settings:<bot_id>:export           send the settings as a YAML file
+//	settings:<bot_id>:set:<n>:<value>  set the nth setting and show its category
+func (s *Service) handleSettingsCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	userID := update.EffectiveUser.Id
+
+	if s.settings == nil || len(parts) < 1 {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+
+	botID, err := uuid.Parse(parts[0])
+	if err != nil {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid bot ID",
+		})
+		return err
+	}
+
+	// Superusers have all permissions, so check that first
+	if !s.IsSuperuser(userID) {
+		isManager, err := s.IsBotManager(userID, botID)
+		if err != nil {
+			s.logger.Warn("Failed to check bot manager status", zap.Error(err))
+			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "Failed to verify permissions",
+			})
+			return err
+		}
+		if !isManager {
+			s.logger.Debug("Access denied for bot settings",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", botID.String()))
+			s.accessDenied(ctx, update, "bot settings")
+			_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "You are not authorized to change this bot.",
+			})
+			return err
+		}
+	}
+
+	switch {
+	case len(parts) == 1:
+		return s.showSettingsCategories(b, update, botID)
+	case parts[1] == "export":
+		return s.exportSettingsFromCallback(b, update, botID)
+	case parts[1] == "set" && len(parts) == 4:
+		return s.setSettingFromCallback(b, update, botID, parts[2], parts[3])
+	default:
+		return s.showSettingsCategory(b, update, botID, parts[1], "")
+	}
+}
+
+// showSettingsCategories shows the top level of the settings menu
+func (s *Service) showSettingsCategories(b *gotgbot.Bot, update *ext.Context, botID uuid.UUID) error {
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		return err
+	}
+
+	text, buttons := settingsCategoriesMenu(bot)
+	return s.editSettingsMessage(b, update, text, buttons)
+}
+
+// handleSettings opens the settings menu of a bot directly, without going
+// through /mybots
+func (s *Service) handleSettings(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	if s.settings == nil {
+		_, err := b.SendMessage(chatID, "Settings are not available.", nil)
+		return err
+	}
+
+	parts := strings.Fields(update.EffectiveMessage.Text)
+	if len(parts) < 2 {
+		_, err := b.SendMessage(chatID, "Usage: /settings <bot_id|@bot_username>", nil)
+		return err
+	}
+
+	bot, err := s.findBotByReference(parts[1])
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+		return err
+	}
+
+	if !s.IsSuperuser(userID) {
+		isManager, err := s.IsBotManager(userID, bot.ID)
+		if err != nil {
+			s.logger.Warn("Failed to check bot manager status", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to verify permissions. Please try again later.", nil)
+			return err
+		}
+		if !isManager {
+			s.logger.Debug("Access denied for bot settings",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", bot.ID.String()))
+			s.accessDenied(ctx, update, "bot settings")
+			_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+			return err
+		}
+	}
+
+	text, buttons := settingsCategoriesMenu(bot)
+	_, err = b.SendMessage(chatID, text, &gotgbot.SendMessageOpts{
+		ParseMode:   "Markdown",
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
+	})
+	return err
+}
+
+// settingsCategoriesMenu returns the top level of a bot's settings menu
+func settingsCategoriesMenu(bot *models.ForwarderBot) (string, [][]gotgbot.InlineKeyboardButton) {
+	botID := bot.ID
+	var buttons [][]gotgbot.InlineKeyboardButton
+	for i := 0; i < len(settings.Categories); i += 2 {
+		var row []gotgbot.InlineKeyboardButton
+		for _, category := range settings.Categories[i:min(i+2, len(settings.Categories))] {
+			row = append(row, gotgbot.InlineKeyboardButton{
+				Text:         category.Title,
+				CallbackData: fmt.Sprintf("settings:%s:%s", botID.String(), category.ID),
+			})
+		}
+		buttons = append(buttons, row)
+	}
+	buttons = append(buttons,
+		[]gotgbot.InlineKeyboardButton{
+			{Text: "Export as YAML", CallbackData: fmt.Sprintf("settings:%s:export", botID.String())},
+		},
+		[]gotgbot.InlineKeyboardButton{
+			{Text: "Back", CallbackData: fmt.Sprintf("bot:view:%s", botID.String())},
+		},
+	)
+
+	text := fmt.Sprintf("*Settings for @%s*\n\nChoose a category:\n\n"+
+		"_To copy settings from another bot, reply to its exported file with /importsettings @%s_",
+		utils.EscapeMarkdown(bot.Name), utils.EscapeMarkdown(bot.Name))
+	return text, buttons
+}
+
+// showSettingsCategory shows the settings of one category with buttons to change them.
+// notice is shown above the settings, e.g. to confirm a change.
+func (s *Service) showSettingsCategory(b *gotgbot.Bot, update *ext.Context, botID uuid.UUID, categoryID string, notice string) error {
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: notice,
+	}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	category, ok := settings.LookupCategory(categoryID)
+	if !ok {
+		return fmt.Errorf("unknown settings category: %s", categoryID)
+	}
+
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot", zap.Error(err))
+		return err
+	}
+	values, err := s.settings.Values(botID)
+	if err != nil {
+		s.logger.Error("Failed to get bot settings", zap.Error(err))
+		return err
+	}
+
+	text := fmt.Sprintf("*Settings for @%s: %s*\n", utils.EscapeMarkdown(bot.Name), category.Title)
+	var buttons [][]gotgbot.InlineKeyboardButton
+	for _, def := range settings.ByCategory(categoryID) {
+		value := values[def.Key]
+		text += fmt.Sprintf("\n*%s*: %s\n_%s_", def.Label, settingDisplayValue(def, value), def.Description)
+
+		index := settingIndex(def)
+		switch def.Kind {
+		case settings.KindBool:
+			next, label := "true", "Turn on"
+			if value == "true" {
+				next, label = "false", "Turn off"
+			}
+			buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+				Text:         fmt.Sprintf("%s: %s", def.Label, label),
+				CallbackData: fmt.Sprintf("settings:%s:set:%d:%s", botID.String(), index, next),
+			}})
+		case settings.KindChoice:
+			var row []gotgbot.InlineKeyboardButton
+			for _, choice := range def.Choices {
+				label := choice.Label
+				if choice.Value == value {
+					label = "• " + label
+				}
+				row = append(row, gotgbot.InlineKeyboardButton{
+					Text:         label,
+					CallbackData: fmt.Sprintf("settings:%s:set:%d:%s", botID.String(), index, choice.Value),
+				})
+			}
+			buttons = append(buttons, row)
+		case settings.KindText:
+			text += fmt.Sprintf("\nChange it with %s in the bot.", utils.EscapeMarkdown(def.Command))
+		}
+		text += "\n"
+	}
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{
+		{Text: "Back", CallbackData: fmt.Sprintf("settings:%s", botID.String())},
+	})
+
+	return s.editSettingsMessage(b, update, text, buttons)
+}
+
+// setSettingFromCallback changes a setting from a settings menu button
+func (s *Service) setSettingFromCallback(b *gotgbot.Bot, update *ext.Context, botID uuid.UUID, indexArg string, value string) error {
+	index, err := strconv.Atoi(indexArg)
+	defs := settings.Definitions()
+	if err != nil || index < 0 || index >= len(defs) || defs[index].Kind == settings.KindText {
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Invalid callback data",
+		})
+		return err
+	}
+	def := defs[index]
+
+	oldValue, err := s.settings.Get(botID, def.Key)
+	if err != nil {
+		s.logger.Error("Failed to get bot setting", zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to load settings",
+		})
+		return err
+	}
+	if oldValue == value {
+		// The menu was rendered before someone else changed the setting
+		return s.showSettingsCategory(b, update, botID, def.Category, menuExpiredText)
+	}
+
+	if err := s.settings.Set(botID, def.Key, value); err != nil {
+		s.logger.Error("Failed to update bot setting",
+			zap.String("bot_id", botID.String()),
+			zap.String("key", def.Key),
+			zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to update setting",
+		})
+		return err
+	}
+
+	userID := update.EffectiveUser.Id
+	s.logger.Info("Bot setting updated",
+		zap.String("bot_id", botID.String()),
+		zap.Int64("user_id", userID),
+		zap.String("key", def.Key),
+		zap.String("value", value))
+
+	if user, _ := s.userRepo.GetByTelegramUserID(userID); user != nil {
+		details, _ := json.Marshal(map[string]interface{}{
+			"key":       def.Key,
+			"old_value": oldValue,
+			"new_value": value,
+		})
+		auditLog := &models.AuditLog{
+			UserID:       &user.ID,
+			ActionType:   models.AuditLogActionUpdateSetting,
+			ResourceType: "bot",
+			ResourceID:   botID,
+			Details:      string(details),
+		}
+		if err := s.auditLogRepo.Create(auditLog); err != nil {
+			s.logger.Warn("Failed to create audit log", zap.Error(err))
+		}
+	}
+
+	return s.showSettingsCategory(b, update, botID, def.Category,
+		fmt.Sprintf("%s: %s", def.Label, settingDisplayValue(def, value)))
+}
+
+// settingIndex returns the position of a definition in settings.Definitions, used
+// in callback data instead of the key to stay within Telegram's 64 byte limit
+func settingIndex(def *settings.Definition) int {
+	for i, d := range settings.Definitions() {
+		if d == def {
+			return i
+		}
+	}
+	return -1
+}
+
+// settingDisplayValue formats a setting value for the settings menu
+func settingDisplayValue(def *settings.Definition, value string) string {
+	switch def.Kind {
+	case settings.KindBool:
+		if value == "true" {
+			return "On"
+		}
+		return "Off"
+	case settings.KindChoice:
+		return utils.EscapeMarkdown(def.ChoiceLabel(value))
+	default:
+		if value == "" {
+			return "Not set"
+		}
+		runes := []rune(value)
+		if len(runes) > maxSettingPreview {
+			value = string(runes[:maxSettingPreview]) + "…"
+		}
+		return utils.EscapeMarkdown(value)
+	}
+}
+
+// editSettingsMessage replaces the settings menu message, or sends a new one if it cannot be edited
+func (s *Service) editSettingsMessage(b *gotgbot.Bot, update *ext.Context, text string, buttons [][]gotgbot.InlineKeyboardButton) error {
+	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons}
+	messageID, err := getMessageIDFromCallback(update.CallbackQuery.Message)
+	if err != nil {
+		s.logger.Warn("Failed to get message ID from callback", zap.Error(err))
+		_, err := b.SendMessage(update.EffectiveChat.Id, text, &gotgbot.SendMessageOpts{
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+		return err
+	}
+	_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+		ChatId:      update.EffectiveChat.Id,
+		MessageId:   messageID,
+		ParseMode:   "Markdown",
+		ReplyMarkup: keyboard,
+	})
+	return err
+}
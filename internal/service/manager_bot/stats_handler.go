@@ -0,0 +1,228 @@
+package manager_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go-telegram-forwarder-bot/internal/service/statistics"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// statsPageSize is how many entries one statistics drill-down page shows
+const statsPageSize = 10
+
+// statsDrillDownKeyboard holds the drill-down buttons of the global statistics
+func statsDrillDownKeyboard() gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "Top Managers", CallbackData: "manage:stats:managers:0"},
+			{Text: "Top Bots", CallbackData: "manage:stats:bots:0"},
+		},
+		{{Text: "Failure Hotspots", CallbackData: "manage:stats:failures:0"}},
+	}}
+}
+
+// globalStatsText describes the global statistics as Markdown, with
+// timestamps in the timezone of the user
+func (s *Service) globalStatsText(userID int64) (string, error) {
+	s.logger.Debug("Retrieving global statistics",
+		zap.Int64("user_id", userID))
+	stats, err := s.statsService.GetGlobalStatistics()
+	if err != nil {
+		return "", err
+	}
+
+	s.logger.Debug("Global statistics retrieved",
+		zap.Int64("user_id", userID),
+		zap.Int64("manager_count", stats.ManagerCount),
+		zap.Int64("bot_count", stats.BotCount),
+		zap.Int64("total_inbound", stats.TotalInbound),
+		zap.Int64("total_outbound", stats.TotalOutbound),
+		zap.Int64("total_guests", stats.TotalGuestCount))
+
+	message := fmt.Sprintf(
+		"*Global Statistics*\n\n"+
+			"Managers: %s\n"+
+			"Bots: %s\n"+
+			"Inbound Messages: %s\n"+
+			"Outbound Messages: %s\n"+
+			"Total Guests: %s",
+		utils.FormatNumber(stats.ManagerCount),
+		utils.FormatNumber(stats.BotCount),
+		utils.FormatNumber(stats.TotalInbound),
+		utils.FormatNumber(stats.TotalOutbound),
+		utils.FormatNumber(stats.TotalGuestCount),
+	)
+	if s.auditMonitor != nil {
+		message += fmt.Sprintf("\nAudit Log: %s pending, %s written inline, %s failed",
+			utils.FormatNumber(int64(s.auditMonitor.Pending())),
+			utils.FormatNumber(s.auditMonitor.Overflowed()),
+			utils.FormatNumber(s.auditMonitor.Failed()))
+	}
+	if s.mappingPruner != nil {
+		if lastRun, lastPruned := s.mappingPruner.LastRun(); lastRun.IsZero() {
+			message += "\nMapping Pruning: not run on this instance yet"
+		} else {
+			message += fmt.Sprintf("\nMapping Pruning: %s deleted since start, %s by the last run at %s",
+				utils.FormatNumber(s.mappingPruner.Pruned()),
+				utils.FormatNumber(lastPruned),
+				utils.FormatTimestamp(lastRun, s.userLocation(userID)))
+		}
+	}
+	return message, nil
+}
+
+// handleStatsCallback drills into the global statistics.
+// parts are the callback parts after "stats": [view, page], or none to
+// return to the global statistics.
+func (s *Service) handleStatsCallback(ctx context.Context, b *gotgbot.Bot, update *ext.Context, parts []string) error {
+	page := 0
+	if len(parts) >= 2 {
+		if p, err := strconv.Atoi(parts[1]); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	var (
+		text    string
+		buttons [][]gotgbot.InlineKeyboardButton
+		err     error
+	)
+	view := ""
+	if len(parts) >= 1 {
+		view = parts[0]
+	}
+	switch view {
+	case "":
+		text, err = s.globalStatsText(update.EffectiveUser.Id)
+		if err == nil {
+			buttons = statsDrillDownKeyboard().InlineKeyboard
+		}
+	case "managers":
+		text, buttons, err = s.topManagersPage(page)
+	case "bots", "failures":
+		text, buttons, err = s.topBotsPage(view, page)
+	default:
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Unknown action",
+		})
+		return err
+	}
+	if err != nil {
+		s.logger.Error("Failed to get statistics",
+			zap.String("view", view),
+			zap.Error(err))
+		_, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "Failed to load statistics",
+		})
+		return err
+	}
+
+	if _, err := b.AnswerCallbackQuery(update.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		s.logger.Warn("Failed to answer callback query", zap.Error(err))
+	}
+
+	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons}
+	messageID, err := getMessageIDFromCallback(update.CallbackQuery.Message)
+	if err == nil {
+		_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+			ChatId:      update.EffectiveChat.Id,
+			MessageId:   messageID,
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+	}
+	if err != nil {
+		_, err = b.SendMessage(update.EffectiveChat.Id, text, &gotgbot.SendMessageOpts{
+			ParseMode:   "Markdown",
+			ReplyMarkup: keyboard,
+		})
+	}
+	return err
+}
+
+// topManagersPage renders a page of the managers ranked by the 7-day message
+// volume of their bots
+func (s *Service) topManagersPage(page int) (string, [][]gotgbot.InlineKeyboardButton, error) {
+	managers, err := s.statsService.GetManagerLeaderboard()
+	if err != nil {
+		return "", nil, err
+	}
+
+	page, totalPages, start, end := pageBounds(len(managers), page, statsPageSize)
+	text := fmt.Sprintf("*Top Managers* (last 7 days)\nPage %d/%d\n", page+1, totalPages)
+	if len(managers) == 0 {
+		text += "\nNo manager owns a bot."
+	}
+
+	var buttons [][]gotgbot.InlineKeyboardButton
+	for i, manager := range managers[start:end] {
+		name := strconv.FormatInt(manager.TelegramUserID, 10)
+		if manager.Username != "" {
+			name = "@" + manager.Username
+		}
+		text += fmt.Sprintf("\n%d. %s (%d bots)\n   Messages: %s (in %s / out %s), Failure rate: %s%% (%s/%s)",
+			start+i+1,
+			utils.EscapeMarkdown(name),
+			manager.BotCount,
+			utils.FormatNumber(manager.MessageCount()),
+			utils.FormatNumber(manager.InboundCount),
+			utils.FormatNumber(manager.OutboundCount),
+			utils.FormatDecimal(manager.FailureRate()*100, 1),
+			utils.FormatNumber(manager.FailureCount),
+			utils.FormatNumber(manager.CopyCount))
+		buttons = append(buttons, []gotgbot.InlineKeyboardButton{
+			{
+				Text:         fmt.Sprintf("%d. %s", start+i+1, name),
+				CallbackData: fmt.Sprintf("manager:view:%s", manager.ManagerID.String()),
+			},
+		})
+	}
+
+	if navigation := pageNavigation(page, totalPages, func(page int) string {
+		return fmt.Sprintf("manage:stats:managers:%d", page)
+	}); len(navigation) > 0 {
+		buttons = append(buttons, navigation)
+	}
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{{Text: "Back", CallbackData: "manage:stats"}})
+	return text, buttons, nil
+}
+
+// topBotsPage renders a page of the bots ranked by 7-day message volume, or
+// of the failure hotspots: the bots with the most failed recipient copies
+func (s *Service) topBotsPage(view string, page int) (string, [][]gotgbot.InlineKeyboardButton, error) {
+	title, empty := "Top Bots", "No bots registered."
+	activities, err := s.statsService.GetBotLeaderboard(statistics.LeaderboardSortVolume)
+	if view == "failures" {
+		title, empty = "Failure Hotspots", "No failed deliveries."
+		activities, err = s.statsService.GetFailureHotspots()
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	page, totalPages, start, end := pageBounds(len(activities), page, statsPageSize)
+	text := fmt.Sprintf("*%s* (last 7 days)\nPage %d/%d\n", title, page+1, totalPages)
+	if len(activities) == 0 {
+		text += "\n" + empty
+	}
+
+	var buttons [][]gotgbot.InlineKeyboardButton
+	for i, activity := range activities[start:end] {
+		text += "\n" + formatBotActivity(start+i+1, activity)
+		buttons = append(buttons, botActivityButton(start+i+1, activity))
+	}
+
+	if navigation := pageNavigation(page, totalPages, func(page int) string {
+		return fmt.Sprintf("manage:stats:%s:%d", view, page)
+	}); len(navigation) > 0 {
+		buttons = append(buttons, navigation)
+	}
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{{Text: "Back", CallbackData: "manage:stats"}})
+	return text, buttons, nil
+}
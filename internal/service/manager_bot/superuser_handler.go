@@ -0,0 +1,153 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// handleAddSuperuser makes a user a superuser without a restart
+func (s *Service) handleAddSuperuser(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	targetID, err := parseManagerArgument(update.EffectiveMessage.Text)
+	if err != nil {
+		_, err := b.SendMessage(chatID, "Usage: /addsuper <user_id>\n\n"+s.superuserList(), nil)
+		return err
+	}
+	if s.superusers == nil {
+		_, err := b.SendMessage(chatID, "Superusers can only be changed in the config.", nil)
+		return err
+	}
+
+	actor, err := s.commandUser(update)
+	if err != nil {
+		s.logger.Error("Failed to get superuser", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	target, err := s.userRepo.GetOrCreateByTelegramUserID(targetID, nil)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	if target.IsSuspended() {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("User %d is suspended. Lift the suspension with /unsuspendmanager first.", targetID), nil)
+		return err
+	}
+
+	added, err := s.superusers.Add(targetID, actor.ID)
+	if err != nil {
+		s.logger.Error("Failed to add superuser", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to add superuser. Please try again later.", nil)
+		return err
+	}
+	if !added {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("User %d is already a superuser.", targetID), nil)
+		return err
+	}
+
+	s.logger.Info("Superuser added",
+		zap.Int64("superuser_id", update.EffectiveUser.Id),
+		zap.Int64("target_user_id", targetID))
+	s.recordSuperuserAudit(actor, target, models.AuditLogActionAddSuperuser)
+
+	_, err = b.SendMessage(chatID, fmt.Sprintf("User %d is now a superuser.", targetID), nil)
+	return err
+}
+
+// handleDelSuperuser takes superuser rights from a user added with /addsuper
+func (s *Service) handleDelSuperuser(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+
+	targetID, err := parseManagerArgument(update.EffectiveMessage.Text)
+	if err != nil {
+		_, err := b.SendMessage(chatID, "Usage: /delsuper <user_id>\n\n"+s.superuserList(), nil)
+		return err
+	}
+	if s.superusers == nil {
+		_, err := b.SendMessage(chatID, "Superusers can only be changed in the config.", nil)
+		return err
+	}
+
+	// Superusers from the config would be added again on the next start
+	if s.superusers.IsConfigured(targetID) {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("User %d is a superuser in the config file. Remove them there and restart instead.", targetID), nil)
+		return err
+	}
+
+	removed, err := s.superusers.Remove(targetID)
+	if err != nil {
+		s.logger.Error("Failed to remove superuser", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to remove superuser. Please try again later.", nil)
+		return err
+	}
+	if !removed {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("User %d is not a superuser.", targetID), nil)
+		return err
+	}
+
+	s.logger.Info("Superuser removed",
+		zap.Int64("superuser_id", update.EffectiveUser.Id),
+		zap.Int64("target_user_id", targetID))
+	actor, err := s.commandUser(update)
+	if err != nil {
+		s.logger.Warn("Failed to get superuser for audit log", zap.Error(err))
+	} else if target, err := s.userRepo.GetOrCreateByTelegramUserID(targetID, nil); err != nil {
+		s.logger.Warn("Failed to get user for audit log", zap.Error(err))
+	} else {
+		s.recordSuperuserAudit(actor, target, models.AuditLogActionDelSuperuser)
+	}
+
+	_, err = b.SendMessage(chatID, fmt.Sprintf("User %d is no longer a superuser.", targetID), nil)
+	return err
+}
+
+// superuserList lists the current superusers, marking those from the config
+func (s *Service) superuserList() string {
+	if s.superusers == nil {
+		return ""
+	}
+	lines := []string{"Superusers:"}
+	for _, id := range s.superusers.List() {
+		line := fmt.Sprintf("• %d", id)
+		if s.superusers.IsConfigured(id) {
+			line += " (config)"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commandUser returns the user who sent the command
+func (s *Service) commandUser(update *ext.Context) (*models.User, error) {
+	username := update.EffectiveUser.Username
+	var usernamePtr *string
+	if username != "" {
+		usernamePtr = &username
+	}
+	return s.userRepo.GetOrCreateByTelegramUserID(update.EffectiveUser.Id, usernamePtr)
+}
+
+// recordSuperuserAudit logs a superuser being added or removed
+func (s *Service) recordSuperuserAudit(actor, target *models.User, action models.AuditLogAction) {
+	details, _ := json.Marshal(map[string]interface{}{
+		"target_telegram_id": target.TelegramUserID,
+	})
+	auditLog := &models.AuditLog{
+		UserID:       &actor.ID,
+		ActionType:   action,
+		ResourceType: "user",
+		ResourceID:   target.ID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+}
@@ -0,0 +1,211 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// parseManagerArgument extracts the target Telegram user ID from a command
+func parseManagerArgument(text string) (int64, error) {
+	parts := strings.Fields(text)
+	if len(parts) < 2 {
+		return 0, fmt.Errorf("missing user ID")
+	}
+	return strconv.ParseInt(parts[1], 10, 64)
+}
+
+func (s *Service) handleSuspendManager(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /suspendmanager command",
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	targetID, err := parseManagerArgument(update.EffectiveMessage.Text)
+	if err != nil {
+		_, err := b.SendMessage(chatID, "Usage: /suspendmanager <user_id>", nil)
+		return err
+	}
+
+	if s.IsSuperuser(targetID) {
+		_, err := b.SendMessage(chatID, "Superusers cannot be suspended.", nil)
+		return err
+	}
+
+	manager, err := s.userRepo.GetByTelegramUserID(targetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_, err := b.SendMessage(chatID, fmt.Sprintf("User %d is not a known manager.", targetID), nil)
+			return err
+		}
+		s.logger.Error("Failed to get manager", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	if manager.IsSuspended() {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Manager %d is already suspended.", targetID), nil)
+		return err
+	}
+
+	now := time.Now()
+	manager.SuspendedAt = &now
+	if err := s.userRepo.Update(manager); err != nil {
+		s.logger.Error("Failed to suspend manager", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to suspend manager. Please try again later.", nil)
+		return err
+	}
+
+	bots, err := s.botRepo.GetByManagerID(manager.ID)
+	if err != nil {
+		s.logger.Error("Failed to get manager's bots", zap.Error(err))
+	}
+
+	stopped := 0
+	if s.botManager != nil {
+		for _, bot := range bots {
+			if stopErr := s.botManager.StopBot(bot.ID); stopErr != nil {
+				s.logger.Warn("Failed to stop bot of suspended manager",
+					zap.String("bot_id", bot.ID.String()),
+					zap.Error(stopErr))
+				continue
+			}
+			stopped++
+		}
+	}
+
+	s.logger.Info("Manager suspended",
+		zap.Int64("superuser_id", userID),
+		zap.Int64("manager_telegram_id", targetID),
+		zap.Int("bots_stopped", stopped))
+
+	s.recordSuspensionAudit(update, manager, models.AuditLogActionSuspend, len(bots))
+
+	_, notifyErr := b.SendMessage(manager.TelegramUserID,
+		"⚠️ Your account has been suspended by an administrator.\n\n"+
+			"All of your ForwarderBots have been paused and you cannot register new bots until the suspension is lifted.", nil)
+	if notifyErr != nil {
+		s.logger.Warn("Failed to notify suspended manager",
+			zap.Int64("manager_telegram_id", targetID),
+			zap.Error(notifyErr))
+	}
+
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Manager %d has been suspended. %d of %d bot(s) paused.", targetID, stopped, len(bots)), nil)
+	return err
+}
+
+func (s *Service) handleUnsuspendManager(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /unsuspendmanager command",
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	targetID, err := parseManagerArgument(update.EffectiveMessage.Text)
+	if err != nil {
+		_, err := b.SendMessage(chatID, "Usage: /unsuspendmanager <user_id>", nil)
+		return err
+	}
+
+	manager, err := s.userRepo.GetByTelegramUserID(targetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_, err := b.SendMessage(chatID, fmt.Sprintf("User %d is not a known manager.", targetID), nil)
+			return err
+		}
+		s.logger.Error("Failed to get manager", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	if !manager.IsSuspended() {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Manager %d is not suspended.", targetID), nil)
+		return err
+	}
+
+	manager.SuspendedAt = nil
+	if err := s.userRepo.Update(manager); err != nil {
+		s.logger.Error("Failed to unsuspend manager", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to unsuspend manager. Please try again later.", nil)
+		return err
+	}
+
+	bots, err := s.botRepo.GetByManagerID(manager.ID)
+	if err != nil {
+		s.logger.Error("Failed to get manager's bots", zap.Error(err))
+	}
+
+	started := 0
+	if s.botManager != nil {
+		for _, bot := range bots {
+			if startErr := s.botManager.StartBot(bot.ID); startErr != nil {
+				s.logger.Warn("Failed to restart bot of unsuspended manager",
+					zap.String("bot_id", bot.ID.String()),
+					zap.Error(startErr))
+				continue
+			}
+			started++
+		}
+	}
+
+	s.logger.Info("Manager unsuspended",
+		zap.Int64("superuser_id", userID),
+		zap.Int64("manager_telegram_id", targetID),
+		zap.Int("bots_started", started))
+
+	s.recordSuspensionAudit(update, manager, models.AuditLogActionUnsuspend, len(bots))
+
+	_, notifyErr := b.SendMessage(manager.TelegramUserID,
+		"✅ Your account suspension has been lifted. Your ForwarderBots have been resumed.", nil)
+	if notifyErr != nil {
+		s.logger.Warn("Failed to notify unsuspended manager",
+			zap.Int64("manager_telegram_id", targetID),
+			zap.Error(notifyErr))
+	}
+
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Manager %d has been unsuspended. %d of %d bot(s) resumed.", targetID, started, len(bots)), nil)
+	return err
+}
+
+// recordSuspensionAudit logs a suspend/unsuspend action performed by a superuser
+func (s *Service) recordSuspensionAudit(update *ext.Context, manager *models.User, action models.AuditLogAction, botCount int) {
+	username := update.EffectiveUser.Username
+	var usernamePtr *string
+	if username != "" {
+		usernamePtr = &username
+	}
+	superuser, err := s.userRepo.GetOrCreateByTelegramUserID(update.EffectiveUser.Id, usernamePtr)
+	if err != nil {
+		s.logger.Warn("Failed to get superuser for audit log", zap.Error(err))
+		return
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"manager_telegram_id": manager.TelegramUserID,
+		"bot_count":           botCount,
+	})
+	auditLog := &models.AuditLog{
+		UserID:       &superuser.ID,
+		ActionType:   action,
+		ResourceType: "user",
+		ResourceID:   manager.ID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+}
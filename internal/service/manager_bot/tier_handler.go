@@ -0,0 +1,109 @@
+package manager_bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// handleSetTier sets a manager's subscription tier
+func (s *Service) handleSetTier(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	parts := strings.Fields(update.EffectiveMessage.Text)
+
+	targetID, err := parseManagerArgument(update.EffectiveMessage.Text)
+	if err != nil || len(parts) < 3 {
+		_, err := b.SendMessage(chatID, "Usage: /settier <user_id> <free|pro>", nil)
+		return err
+	}
+
+	tier := models.UserTier(strings.ToLower(parts[2]))
+	if tier != models.UserTierFree && tier != models.UserTierPro {
+		_, err := b.SendMessage(chatID, "Tier must be free or pro.", nil)
+		return err
+	}
+
+	manager, err := s.userRepo.GetByTelegramUserID(targetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_, err := b.SendMessage(chatID, fmt.Sprintf("User %d is not a known manager.", targetID), nil)
+			return err
+		}
+		s.logger.Error("Failed to get manager", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	previous := manager.Tier
+	manager.Tier = tier
+	if err := s.userRepo.Update(manager); err != nil {
+		s.logger.Error("Failed to update manager tier", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update tier. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("Manager tier updated",
+		zap.Int64("superuser_id", update.EffectiveUser.Id),
+		zap.Int64("manager_telegram_id", targetID),
+		zap.String("previous_tier", string(previous)),
+		zap.String("tier", string(tier)))
+
+	s.recordTierAudit(update, manager, previous)
+
+	text := fmt.Sprintf("Manager %d is now on the %s tier.", targetID, tier)
+	if s.featureFlags != nil && s.featureFlags.EffectiveTier(manager) != tier {
+		text += " As a superuser, they keep pro features regardless."
+	}
+	_, err = b.SendMessage(chatID, text, nil)
+	return err
+}
+
+// recordTierAudit logs a tier change performed by a superuser
+func (s *Service) recordTierAudit(update *ext.Context, manager *models.User, previous models.UserTier) {
+	username := update.EffectiveUser.Username
+	var usernamePtr *string
+	if username != "" {
+		usernamePtr = &username
+	}
+	superuser, err := s.userRepo.GetOrCreateByTelegramUserID(update.EffectiveUser.Id, usernamePtr)
+	if err != nil {
+		s.logger.Warn("Failed to get superuser for audit log", zap.Error(err))
+		return
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"manager_telegram_id": manager.TelegramUserID,
+		"previous_tier":       previous,
+		"tier":                manager.Tier,
+	})
+	auditLog := &models.AuditLog{
+		UserID:       &superuser.ID,
+		ActionType:   models.AuditLogActionSetTier,
+		ResourceType: "user",
+		ResourceID:   manager.ID,
+		Details:      string(details),
+	}
+	s.auditLogRepo.Create(auditLog)
+}
+
+// tierSummary describes the user's tier and bot allowance for /mybots
+func (s *Service) tierSummary(user *models.User, botCount int) string {
+	if s.featureFlags == nil {
+		return ""
+	}
+	tier := s.featureFlags.EffectiveTier(user)
+	limit := s.featureFlags.MaxBots(user)
+	if limit == 0 {
+		return fmt.Sprintf("Tier: %s (%d bots)", tier, botCount)
+	}
+	return fmt.Sprintf("Tier: %s (%d of %d bots)", tier, botCount, limit)
+}
@@ -0,0 +1,81 @@
+package manager_bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+// userLocation returns the timezone times are shown in for a Telegram user
+func (s *Service) userLocation(telegramUserID int64) *time.Location {
+	user, err := s.userRepo.GetByTelegramUserID(telegramUserID)
+	if err != nil {
+		return time.Local
+	}
+	return user.Location()
+}
+
+// handleTimezone shows or sets the caller's timezone. Their bots use it unless
+// a bot has its own timezone set with /settimezone.
+func (s *Service) handleTimezone(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	chatID := update.EffectiveChat.Id
+	userID := update.EffectiveUser.Id
+
+	arg := ""
+	if parts := strings.Fields(update.EffectiveMessage.Text); len(parts) >= 2 {
+		arg = parts[1]
+	}
+	if arg == "" {
+		_, err := b.SendMessage(chatID,
+			fmt.Sprintf("Your timezone: %s\n\n"+
+				"Usage: /timezone <timezone>\n"+
+				"Example: /timezone Asia/Shanghai\n"+
+				"Use /timezone default to use the server's timezone.",
+				utils.TimezoneLabel(s.userLocation(userID))), nil)
+		return err
+	}
+
+	timezone := ""
+	if !strings.EqualFold(arg, "default") {
+		loc, err := utils.LoadTimezone(arg)
+		if err != nil {
+			_, err := b.SendMessage(chatID,
+				fmt.Sprintf("Unknown timezone %q. Use an IANA name such as Europe/Berlin, Asia/Shanghai or UTC.", arg), nil)
+			return err
+		}
+		timezone = loc.String()
+	}
+
+	var username *string
+	if update.EffectiveUser.Username != "" {
+		username = &update.EffectiveUser.Username
+	}
+	user, err := s.userRepo.GetOrCreateByTelegramUserID(userID, username)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+	user.Timezone = timezone
+	if err := s.userRepo.Update(user); err != nil {
+		s.logger.Error("Failed to update user timezone", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to update your timezone. Please try again later.", nil)
+		return err
+	}
+
+	s.logger.Info("User timezone updated",
+		zap.Int64("user_id", userID),
+		zap.String("timezone", timezone))
+
+	_, err = b.SendMessage(chatID,
+		fmt.Sprintf("Timezone set to %s. Your bots show times in it unless they have their own timezone.",
+			utils.TimezoneLabel(user.Location())), nil)
+	return err
+}
@@ -0,0 +1,112 @@
+package manager_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+const setTokenUsage = "Usage: /settoken <bot_id|@bot_username> <new_token>\nUse it after revoking a token in @BotFather; recipients, admins and statistics are kept."
+
+// handleSetToken replaces the token of a ForwarderBot without deleting it
+func (s *Service) handleSetToken(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /settoken command",
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	parts := strings.Fields(update.EffectiveMessage.Text)
+	if len(parts) < 3 {
+		_, err := b.SendMessage(chatID, setTokenUsage, nil)
+		return err
+	}
+
+	// The message contains a working token, so it should not stay in the chat
+	if _, err := b.DeleteMessage(chatID, update.EffectiveMessage.MessageId, nil); err != nil {
+		s.logger.Debug("Failed to delete /settoken message",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+
+	bot, err := s.findBotByReference(parts[1])
+	if err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+		return err
+	}
+
+	if !s.IsSuperuser(userID) {
+		isManager, err := s.IsBotManager(userID, bot.ID)
+		if err != nil {
+			s.logger.Warn("Failed to check bot manager status", zap.Error(err))
+			_, err := b.SendMessage(chatID, "Failed to verify permissions. Please try again later.", nil)
+			return err
+		}
+		if !isManager {
+			s.logger.Debug("Access denied for /settoken",
+				zap.Int64("user_id", userID),
+				zap.String("bot_id", bot.ID.String()))
+			s.accessDenied(ctx, update, "/settoken")
+			_, err := b.SendMessage(chatID, fmt.Sprintf("Bot %s not found.", parts[1]), nil)
+			return err
+		}
+	}
+
+	// Check the token before contacting Telegram so paste mistakes get a useful answer
+	if _, err := utils.ParseBotToken(parts[2]); err != nil {
+		_, err := b.SendMessage(chatID, fmt.Sprintf("❌ That doesn't look like a bot token: %v.\n\n%s", err, setTokenUsage), nil)
+		return err
+	}
+
+	username := update.EffectiveUser.Username
+	var usernamePtr *string
+	if username != "" {
+		usernamePtr = &username
+	}
+	user, err := s.userRepo.GetOrCreateByTelegramUserID(userID, usernamePtr)
+	if err != nil {
+		s.logger.Error("Failed to get or create user", zap.Error(err))
+		_, err := b.SendMessage(chatID, "An error occurred. Please try again later.", nil)
+		return err
+	}
+
+	progress := s.startProgress(b, chatID, "")
+	defer progress.Close()
+	progress.Stage("Validating token...")
+
+	err = s.SetBotToken(ctx, bot, parts[2], user)
+	switch {
+	case err == nil:
+		progress.Finish(fmt.Sprintf("✅ Token of @%s replaced. The bot is running with the new token.", bot.Name))
+		return nil
+	case errors.Is(err, ErrBotNotRestarted):
+		s.logger.Error("Failed to restart ForwarderBot with new token",
+			zap.String("bot_id", bot.ID.String()),
+			zap.Error(err))
+		progress.Finish(fmt.Sprintf("⚠️ Token of @%s replaced, but the bot failed to restart. It will be started on next application restart.", bot.Name))
+		return err
+	case errors.Is(err, ErrTokenUnchanged):
+		progress.Finish(fmt.Sprintf("@%s already uses this token.", bot.Name))
+		return nil
+	case errors.Is(err, ErrTokenOfOtherBot):
+		progress.Finish(fmt.Sprintf("❌ This token belongs to a different bot. Use the token of @%s, or register the other bot with /addbot.", bot.Name))
+		return err
+	case errors.Is(err, ErrManagerBotToken):
+		progress.Finish("❌ This is the token of this ManagerBot itself.")
+		return err
+	default:
+		s.logger.Error("Failed to replace bot token",
+			zap.String("bot_id", bot.ID.String()),
+			zap.Error(err))
+		progress.Finish(fmt.Sprintf("❌ Failed to replace token: %v", err))
+		return err
+	}
+}
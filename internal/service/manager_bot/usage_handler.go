@@ -0,0 +1,80 @@
+package manager_bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"go.uber.org/zap"
+)
+
+const usageReportUsage = "Usage: /usage [YYYY-MM] [csv|json]"
+
+// handleUsage exports the per-manager usage report of a month as a CSV or JSON document
+func (s *Service) handleUsage(ctx context.Context, b *gotgbot.Bot, update *ext.Context) error {
+	userID := update.EffectiveUser.Id
+	chatID := update.EffectiveChat.Id
+
+	s.logger.Debug("Processing /usage command",
+		zap.Int64("user_id", userID),
+		zap.Int64("chat_id", chatID))
+
+	month := time.Now()
+	format := "csv"
+	for _, arg := range strings.Fields(update.EffectiveMessage.Text)[1:] {
+		switch strings.ToLower(arg) {
+		case "csv", "json":
+			format = strings.ToLower(arg)
+		default:
+			parsed, err := time.ParseInLocation(models.BotUsageMonthFormat, arg, time.Local)
+			if err != nil {
+				_, err := b.SendMessage(chatID, usageReportUsage, nil)
+				return err
+			}
+			month = parsed
+		}
+	}
+
+	report, err := s.statsService.GetUsageReport(month)
+	if err != nil {
+		s.logger.Error("Failed to build usage report", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to build usage report. Please try again later.", nil)
+		return err
+	}
+
+	var buf bytes.Buffer
+	if format == "json" {
+		err = report.WriteJSON(&buf)
+	} else {
+		err = report.WriteCSV(&buf)
+	}
+	if err != nil {
+		s.logger.Error("Failed to encode usage report", zap.Error(err))
+		_, err := b.SendMessage(chatID, "Failed to build usage report. Please try again later.", nil)
+		return err
+	}
+
+	var bots int
+	var messages int64
+	for _, usage := range report.Managers {
+		bots += usage.BotCount
+		messages += usage.MessageCount()
+	}
+	caption := fmt.Sprintf("Usage report %s: %d manager(s), %d bot(s), %d message(s)",
+		report.Month, len(report.Managers), bots, messages)
+
+	fileName := fmt.Sprintf("usage-%s.%s", report.Month, format)
+	_, err = b.SendDocument(chatID, gotgbot.InputFileByReader(fileName, &buf), &gotgbot.SendDocumentOpts{
+		Caption: caption,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send usage report", zap.Error(err))
+	}
+	return err
+}
@@ -2,19 +2,46 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/google/uuid"
 	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/service/settings"
+	"go-telegram-forwarder-bot/internal/utils"
 	"go.uber.org/zap"
 )
 
+const (
+	// notificationCoalesceWindow is the minimum time between two messages to the same
+	// manager about the same bot. Notifications arriving inside the window are
+	// collected and sent as a single digest when it ends.
+	notificationCoalesceWindow = 1 * time.Minute
+
+	// maxDigestLength keeps digests below Telegram's 4096 character message limit
+	maxDigestLength = 3800
+)
+
 type ManagerNotifier struct {
 	managerBot *gotgbot.Bot
 	botRepo    repository.BotRepository
 	userRepo   repository.UserRepository
+	settings   *settings.Service
 	logger     *zap.Logger
+	window     time.Duration
+	pending    map[uuid.UUID]*pendingNotifications
+	mutex      sync.Mutex
+}
+
+// pendingNotifications holds the coalescing state for one bot
+type pendingNotifications struct {
+	lastSent time.Time
+	messages []string
+	timer    *time.Timer
 }
 
 func NewManagerNotifier(
@@ -28,10 +55,164 @@ func NewManagerNotifier(
 		botRepo:    botRepo,
 		userRepo:   userRepo,
 		logger:     logger,
+		window:     notificationCoalesceWindow,
+		pending:    make(map[uuid.UUID]*pendingNotifications),
 	}
 }
 
+// SetSettings sets the per-bot settings used to turn digests off
+func (mn *ManagerNotifier) SetSettings(settingsService *settings.Service) {
+	mn.settings = settingsService
+}
+
+// NotifyManager notifies the bot's manager. The first notification in a window is
+// sent immediately; later ones are buffered and delivered together as a digest,
+// unless the bot has digests turned off.
 func (mn *ManagerNotifier) NotifyManager(ctx context.Context, botID uuid.UUID, message string) error {
+	if mn.settings != nil && !mn.settings.GetBool(botID, settings.KeyDigest) {
+		return mn.send(botID, message)
+	}
+
+	mn.mutex.Lock()
+	state, exists := mn.pending[botID]
+	if !exists {
+		state = &pendingNotifications{}
+		mn.pending[botID] = state
+	}
+
+	if time.Since(state.lastSent) >= mn.window && len(state.messages) == 0 {
+		state.lastSent = time.Now()
+		mn.mutex.Unlock()
+		return mn.send(botID, message)
+	}
+
+	state.messages = append(state.messages, message)
+	if state.timer == nil {
+		delay := mn.window - time.Since(state.lastSent)
+		if delay < 0 {
+			delay = 0
+		}
+		state.timer = time.AfterFunc(delay, func() {
+			mn.flush(botID)
+		})
+	}
+	queued := len(state.messages)
+	mn.mutex.Unlock()
+
+	mn.logger.Debug("Manager notification buffered for digest",
+		zap.String("bot_id", botID.String()),
+		zap.Int("queued", queued))
+	return nil
+}
+
+// Flush sends all buffered digests immediately. Called on shutdown.
+func (mn *ManagerNotifier) Flush() {
+	mn.mutex.Lock()
+	botIDs := make([]uuid.UUID, 0, len(mn.pending))
+	for botID, state := range mn.pending {
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+		if len(state.messages) > 0 {
+			botIDs = append(botIDs, botID)
+		}
+	}
+	mn.mutex.Unlock()
+
+	for _, botID := range botIDs {
+		mn.flush(botID)
+	}
+}
+
+// Snapshot encodes when each bot's manager was last notified, so the digest
+// window carries over a restart. Call it after Flush.
+func (mn *ManagerNotifier) Snapshot() ([]byte, error) {
+	mn.mutex.Lock()
+	defer mn.mutex.Unlock()
+
+	lastSent := make(map[uuid.UUID]time.Time, len(mn.pending))
+	for botID, state := range mn.pending {
+		if time.Since(state.lastSent) < mn.window {
+			lastSent[botID] = state.lastSent
+		}
+	}
+	return json.Marshal(lastSent)
+}
+
+// Restore merges saved notification times, keeping the later of two
+func (mn *ManagerNotifier) Restore(data []byte) error {
+	var lastSent map[uuid.UUID]time.Time
+	if err := json.Unmarshal(data, &lastSent); err != nil {
+		return fmt.Errorf("failed to decode manager notifier state: %w", err)
+	}
+
+	mn.mutex.Lock()
+	defer mn.mutex.Unlock()
+	for botID, sent := range lastSent {
+		state, exists := mn.pending[botID]
+		if !exists {
+			state = &pendingNotifications{}
+			mn.pending[botID] = state
+		}
+		if sent.After(state.lastSent) {
+			state.lastSent = sent
+		}
+	}
+	return nil
+}
+
+func (mn *ManagerNotifier) flush(botID uuid.UUID) {
+	mn.mutex.Lock()
+	state, exists := mn.pending[botID]
+	if !exists || len(state.messages) == 0 {
+		if exists {
+			state.timer = nil
+		}
+		mn.mutex.Unlock()
+		return
+	}
+	messages := state.messages
+	state.messages = nil
+	state.timer = nil
+	state.lastSent = time.Now()
+	mn.mutex.Unlock()
+
+	digest := buildNotificationDigest(messages, mn.window)
+	if err := mn.send(botID, digest); err != nil {
+		mn.logger.Warn("Failed to send manager notification digest",
+			zap.String("bot_id", botID.String()),
+			zap.Int("notification_count", len(messages)),
+			zap.Error(err))
+	}
+}
+
+// buildNotificationDigest joins buffered notifications into one message,
+// dropping the oldest entries that do not fit
+func buildNotificationDigest(messages []string, window time.Duration) string {
+	header := fmt.Sprintf("*Notification Digest*\n\n%s notifications within %s:\n\n",
+		utils.FormatNumber(int64(len(messages))), window)
+	separator := "\n\n—————\n\n"
+
+	// Keep the most recent notifications
+	included := make([]string, 0, len(messages))
+	length := len(header)
+	for i := len(messages) - 1; i >= 0; i-- {
+		entryLength := len(messages[i]) + len(separator)
+		if len(included) > 0 && length+entryLength > maxDigestLength {
+			break
+		}
+		included = append([]string{messages[i]}, included...)
+		length += entryLength
+	}
+
+	digest := header
+	if omitted := len(messages) - len(included); omitted > 0 {
+		digest += fmt.Sprintf("_%s older notifications omitted_\n\n", utils.FormatNumber(int64(omitted)))
+	}
+	return digest + strings.Join(included, separator)
+}
+
+func (mn *ManagerNotifier) send(botID uuid.UUID, message string) error {
 	// Get bot to find manager
 	bot, err := mn.botRepo.GetByID(botID)
 	if err != nil {
@@ -56,7 +237,7 @@ func (mn *ManagerNotifier) NotifyManager(ctx context.Context, botID uuid.UUID, m
 		return fmt.Errorf("failed to send notification: %w", sendErr)
 	}
 
-	mn.logger.Info("Manager notified about batch forwarding failure",
+	mn.logger.Info("Manager notified",
 		zap.String("bot_id", botID.String()),
 		zap.Int64("manager_telegram_id", manager.TelegramUserID))
 
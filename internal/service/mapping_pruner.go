@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/service/settings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// mappingPruneBatchPause is the wait between two delete batches, so pruning a
+// large backlog does not hold the database for long at a time
+const mappingPruneBatchPause = 100 * time.Millisecond
+
+// MappingPruner deletes message mappings older than each bot's retention. A
+// mapping is what lets a recipient's reply reach the guest, so once it is
+// pruned, replies to that forwarded message are no longer delivered.
+type MappingPruner struct {
+	botRepo            repository.BotRepository
+	messageMappingRepo repository.MessageMappingRepository
+	settings           *settings.Service
+	config             *config.Config
+	logger             *zap.Logger
+
+	pruned     atomic.Int64
+	lastPruned atomic.Int64
+	lastRun    atomic.Int64 // Unix time of the last completed run, 0 before the first
+}
+
+func NewMappingPruner(
+	botRepo repository.BotRepository,
+	messageMappingRepo repository.MessageMappingRepository,
+	settingsService *settings.Service,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *MappingPruner {
+	return &MappingPruner{
+		botRepo:            botRepo,
+		messageMappingRepo: messageMappingRepo,
+		settings:           settingsService,
+		config:             cfg,
+		logger:             logger,
+	}
+}
+
+// Prune deletes the expired mappings of every bot. It is run by the
+// mapping_prune worker.
+func (p *MappingPruner) Prune(ctx context.Context) error {
+	bots, err := p.botRepo.GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to get bots: %w", err)
+	}
+
+	var total int64
+	now := time.Now()
+	for _, bot := range bots {
+		days, err := p.retentionDays(bot.ID)
+		if err != nil {
+			p.logger.Warn("Failed to read mapping retention",
+				zap.String("bot_id", bot.ID.String()),
+				zap.Error(err))
+			continue
+		}
+		if days == 0 {
+			continue
+		}
+
+		deleted, err := p.pruneBot(ctx, bot.ID, now.AddDate(0, 0, -days))
+		total += deleted
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			p.logger.Warn("Failed to prune message mappings",
+				zap.String("bot_id", bot.ID.String()),
+				zap.Int64("deleted", deleted),
+				zap.Error(err))
+			continue
+		}
+		if deleted > 0 {
+			p.logger.Debug("Pruned message mappings",
+				zap.String("bot_id", bot.ID.String()),
+				zap.Int("retention_days", days),
+				zap.Int64("deleted", deleted))
+		}
+	}
+
+	p.pruned.Add(total)
+	p.lastPruned.Store(total)
+	p.lastRun.Store(time.Now().Unix())
+	p.logger.Info("Message mappings pruned",
+		zap.Int("bots", len(bots)),
+		zap.Int64("deleted", total))
+	return ctx.Err()
+}
+
+// pruneBot deletes the bot's mappings created before the cutoff in batches
+// and returns how many were deleted
+func (p *MappingPruner) pruneBot(ctx context.Context, botID uuid.UUID, before time.Time) (int64, error) {
+	batchSize := p.config.Retention.PruneBatchSize
+	var total int64
+	for {
+		deleted, err := p.messageMappingRepo.DeleteOlderThan(botID, before, batchSize)
+		total += deleted
+		if err != nil || deleted < int64(batchSize) {
+			return total, err
+		}
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(mappingPruneBatchPause):
+		}
+	}
+}
+
+// retentionDays returns how many days the bot's mappings are kept, 0 for forever
+func (p *MappingPruner) retentionDays(botID uuid.UUID) (int, error) {
+	value, err := p.settings.Get(botID, settings.KeyMappingRetention)
+	if err != nil {
+		return 0, err
+	}
+	switch value {
+	case settings.RetentionDefault:
+		return p.config.Retention.MessageMappingDays, nil
+	case settings.RetentionForever:
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// Pruned returns how many mappings were deleted since the start
+func (p *MappingPruner) Pruned() int64 {
+	return p.pruned.Load()
+}
+
+// LastRun returns when the last run finished and how many mappings it deleted;
+// the time is zero before the first run
+func (p *MappingPruner) LastRun() (time.Time, int64) {
+	unix := p.lastRun.Load()
+	if unix == 0 {
+		return time.Time{}, 0
+	}
+	return time.Unix(unix, 0), p.lastPruned.Load()
+}
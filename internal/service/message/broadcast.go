@@ -0,0 +1,136 @@
+package message
+
+import (
+	"context"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// broadcastBatchSize is how many guests a broadcast sends to before it
+	// reports progress and pauses, keeping well under Telegram's limit of about
+	// 30 messages per second per bot
+	broadcastBatchSize  = 25
+	broadcastBatchPause = time.Second
+)
+
+// BroadcastReport counts the outcomes of a broadcast so far
+type BroadcastReport struct {
+	Total       int // Guests the broadcast is for
+	Sent        int
+	Blocked     int // Guests who blocked the bot
+	Unreachable int // Deleted accounts and chats Telegram cannot find
+	Failed      int // Other errors, e.g. Telegram being unavailable
+	Cancelled   bool
+}
+
+// Done returns how many guests the broadcast has dealt with
+func (r BroadcastReport) Done() int {
+	return r.Sent + r.Blocked + r.Unreachable + r.Failed
+}
+
+// record counts the outcome of sending to one guest
+func (r *BroadcastReport) record(reason FailureReason) {
+	switch reason {
+	case FailureReasonNone:
+		r.Sent++
+	case FailureReasonForbidden:
+		r.Blocked++
+	case FailureReasonChatNotFound:
+		r.Unreachable++
+	default:
+		r.Failed++
+	}
+}
+
+// Broadcast sends a message to each guest with send, taking every message
+// from the Telegram API rate limiter and retrying transient errors. Progress
+// is reported after each batch. Guests who blocked the bot are marked as such,
+// and guests reached again are unmarked. The broadcast stops early when ctx
+// is cancelled.
+func (f *Forwarder) Broadcast(
+	ctx context.Context,
+	botID uuid.UUID,
+	guests []*models.Guest,
+	send func(chatID int64) error,
+	progress func(BroadcastReport),
+) BroadcastReport {
+	report := BroadcastReport{Total: len(guests)}
+	for i, guest := range guests {
+		if i > 0 && i%broadcastBatchSize == 0 {
+			if progress != nil {
+				progress(report)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(broadcastBatchPause):
+			}
+		}
+		if err := f.waitTelegramAPIUntil(ctx, botID); err != nil {
+			report.Cancelled = true
+			break
+		}
+
+		err := f.retryHandler.Retry(ctx, func() error {
+			return send(guest.GuestUserID)
+		})
+		reason := ClassifyError(err)
+		if reason == FailureReasonCancelled {
+			report.Cancelled = true
+			break
+		}
+		report.record(reason)
+
+		switch {
+		case reason == FailureReasonForbidden:
+			f.setGuestBlocked(botID, guest.GuestUserID, true)
+		case err == nil && guest.BlockedBotAt != nil:
+			f.setGuestBlocked(botID, guest.GuestUserID, false)
+		case err != nil:
+			f.logger.Debug("Failed to send broadcast to guest",
+				zap.String("bot_id", botID.String()),
+				zap.Int64("guest_chat_id", guest.GuestUserID),
+				zap.String("reason", string(reason)),
+				zap.Error(err))
+		}
+	}
+
+	f.logger.Info("Broadcast finished",
+		zap.String("bot_id", botID.String()),
+		zap.Int("total", report.Total),
+		zap.Int("sent", report.Sent),
+		zap.Int("blocked", report.Blocked),
+		zap.Int("unreachable", report.Unreachable),
+		zap.Int("failed", report.Failed),
+		zap.Bool("cancelled", report.Cancelled))
+	return report
+}
+
+// waitTelegramAPIUntil blocks until the Telegram API rate limiter allows a
+// request. Unlike waitTelegramAPI it never sends anyway: a broadcast can wait,
+// while guest messages forwarded in the meantime should not.
+func (f *Forwarder) waitTelegramAPIUntil(ctx context.Context, botID uuid.UUID) error {
+	for !f.rateLimiter.AllowBotTelegramAPI(ctx, botID) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return ctx.Err()
+}
+
+// setGuestBlocked records whether the guest blocked the bot
+func (f *Forwarder) setGuestBlocked(botID uuid.UUID, guestChatID int64, blocked bool) {
+	if err := f.guestRepo.SetBlockedBot(botID, guestChatID, blocked); err != nil {
+		f.logger.Warn("Failed to record whether guest blocked the bot",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Bool("blocked", blocked),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,109 @@
+package message
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// blockedGuestRepo records SetBlockedBot calls; other methods are not used
+type blockedGuestRepo struct {
+	repository.GuestRepository
+	blocked map[int64]bool
+}
+
+func (r *blockedGuestRepo) SetBlockedBot(botID uuid.UUID, userID int64, blocked bool) error {
+	r.blocked[userID] = blocked
+	return nil
+}
+
+func TestBroadcast(t *testing.T) {
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{TelegramAPI: 1000},
+		Retry:     config.RetryConfig{MaxAttempts: 1},
+	}
+	guestRepo := &blockedGuestRepo{blocked: make(map[int64]bool)}
+	f := &Forwarder{
+		guestRepo:    guestRepo,
+		rateLimiter:  NewRateLimiter(nil, cfg, zap.NewNop()),
+		retryHandler: NewRetryHandler(cfg, zap.NewNop()),
+		config:       cfg,
+		logger:       zap.NewNop(),
+	}
+
+	blockedBefore := time.Now()
+	guests := []*models.Guest{
+		{GuestUserID: 1},
+		{GuestUserID: 2},
+		{GuestUserID: 3},
+		{GuestUserID: 4, BlockedBotAt: &blockedBefore},
+	}
+	errs := map[int64]error{
+		2: &gotgbot.TelegramError{Code: 403, Description: "Forbidden: bot was blocked by the user"},
+		3: &gotgbot.TelegramError{Code: 400, Description: "Bad Request: chat not found"},
+	}
+	var sent []int64
+	report := f.Broadcast(context.Background(), uuid.New(), guests, func(chatID int64) error {
+		if err := errs[chatID]; err != nil {
+			return err
+		}
+		sent = append(sent, chatID)
+		return nil
+	}, nil)
+
+	want := BroadcastReport{Total: 4, Sent: 2, Blocked: 1, Unreachable: 1}
+	if report != want {
+		t.Fatalf("report = %+v, want %+v", report, want)
+	}
+	if len(sent) != 2 || sent[0] != 1 || sent[1] != 4 {
+		t.Fatalf("sent to %v, want guests 1 and 4", sent)
+	}
+	if blocked, ok := guestRepo.blocked[2]; !ok || !blocked {
+		t.Error("guest 2 blocked the bot but was not marked")
+	}
+	if blocked, ok := guestRepo.blocked[4]; !ok || blocked {
+		t.Error("guest 4 was reached but is still marked as blocking the bot")
+	}
+	if _, ok := guestRepo.blocked[1]; ok {
+		t.Error("guest 1 was updated although nothing changed")
+	}
+}
+
+func TestBroadcastStopsWhenCancelled(t *testing.T) {
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{TelegramAPI: 1000},
+		Retry:     config.RetryConfig{MaxAttempts: 1},
+	}
+	f := &Forwarder{
+		guestRepo:    &blockedGuestRepo{blocked: make(map[int64]bool)},
+		rateLimiter:  NewRateLimiter(nil, cfg, zap.NewNop()),
+		retryHandler: NewRetryHandler(cfg, zap.NewNop()),
+		config:       cfg,
+		logger:       zap.NewNop(),
+	}
+
+	guests := make([]*models.Guest, broadcastBatchSize*2)
+	for i := range guests {
+		guests[i] = &models.Guest{GuestUserID: int64(i + 1)}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	progressCalls := 0
+	report := f.Broadcast(ctx, uuid.New(), guests, func(chatID int64) error {
+		return nil
+	}, func(progress BroadcastReport) {
+		progressCalls++
+		cancel()
+	})
+
+	if !report.Cancelled || report.Sent != broadcastBatchSize || progressCalls != 1 {
+		t.Fatalf("report = %+v after %d progress calls, want the first batch sent and then cancelled", report, progressCalls)
+	}
+}
@@ -0,0 +1,196 @@
+package message
+
+import (
+	"fmt"
+	"unicode/utf16"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+const (
+	maxTextLength    = 4096 // Telegram's limit for message text
+	maxCaptionLength = 1024 // Telegram's limit for media captions
+)
+
+// copyPayload is a guest message prepared to be re-sent without the "Forwarded
+// from" header. Text messages are re-sent with SendMessage and everything else
+// with CopyMessage. The text or caption and its entities are always passed
+// explicitly, so bold, spoilers, links and custom emoji come through exactly as
+// the guest wrote them even when the text is changed, e.g. by a header line.
+// The copy is sent by our bot, so a via_bot attribution never carries over, and
+// inline keyboards are reduced to the buttons that still work for the recipient.
+type copyPayload struct {
+	IsText                bool   // Re-sent with SendMessage; otherwise copied with CopyMessage
+	CanCaption            bool   // Whether the message type takes a caption
+	Text                  string // Message text or media caption
+	Entities              []gotgbot.MessageEntity
+	LinkPreviewOptions    *gotgbot.LinkPreviewOptions
+	ShowCaptionAboveMedia bool
+	ReplyMarkup           *gotgbot.InlineKeyboardMarkup // Buttons kept from the original keyboard, nil if none
+	StripMarkup           bool                          // The original keyboard must be cleared from the copy
+}
+
+// newCopyPayload prepares msg to be copied
+func newCopyPayload(msg *gotgbot.Message) *copyPayload {
+	var p *copyPayload
+	if msg.Text != "" {
+		p = &copyPayload{
+			IsText:             true,
+			Text:               msg.Text,
+			Entities:           cloneEntities(msg.Entities),
+			LinkPreviewOptions: msg.LinkPreviewOptions,
+		}
+	} else {
+		p = &copyPayload{
+			CanCaption: msg.Animation != nil || msg.Audio != nil || msg.Document != nil ||
+				msg.PaidMedia != nil || msg.Photo != nil || msg.Video != nil || msg.Voice != nil,
+			Text:                  msg.Caption,
+			Entities:              cloneEntities(msg.CaptionEntities),
+			ShowCaptionAboveMedia: msg.ShowCaptionAboveMedia,
+		}
+	}
+	if msg.ReplyMarkup != nil {
+		p.ReplyMarkup = normalizeReplyMarkup(msg.ReplyMarkup)
+		p.StripMarkup = p.ReplyMarkup == nil
+	}
+	return p
+}
+
+// normalizeReplyMarkup keeps only the buttons that work outside the bot that
+// attached them: URL and copy-text buttons. Callback, game, pay, inline-query,
+// login and web app buttons are answered by the original bot, so they would be
+// dead in the copy. Returns nil if no buttons are left.
+func normalizeReplyMarkup(markup *gotgbot.InlineKeyboardMarkup) *gotgbot.InlineKeyboardMarkup {
+	var rows [][]gotgbot.InlineKeyboardButton
+	for _, row := range markup.InlineKeyboard {
+		var kept []gotgbot.InlineKeyboardButton
+		for _, button := range row {
+			if button.Url != "" {
+				kept = append(kept, gotgbot.InlineKeyboardButton{Text: button.Text, Url: button.Url})
+			} else if button.CopyText != nil {
+				kept = append(kept, gotgbot.InlineKeyboardButton{Text: button.Text, CopyText: button.CopyText})
+			}
+		}
+		if len(kept) > 0 {
+			rows = append(rows, kept)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return &gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func cloneEntities(entities []gotgbot.MessageEntity) []gotgbot.MessageEntity {
+	if len(entities) == 0 {
+		return nil
+	}
+	cloned := make([]gotgbot.MessageEntity, len(entities))
+	copy(cloned, entities)
+	return cloned
+}
+
+// utf16Len returns the length of s in UTF-16 code units, the unit of entity offsets
+func utf16Len(s string) int64 {
+	return int64(len(utf16.Encode([]rune(s))))
+}
+
+func (p *copyPayload) maxLength() int64 {
+	if p.IsText {
+		return maxTextLength
+	}
+	return maxCaptionLength
+}
+
+// prependHeader puts a bold header line above the text and shifts the existing
+// entities to match. It reports false, leaving the payload unchanged, if the
+// message type has no caption or the result would be too long.
+func (p *copyPayload) prependHeader(header string) bool {
+	if header == "" {
+		return true
+	}
+	if !p.IsText && !p.CanCaption {
+		return false
+	}
+
+	prefix := header
+	if p.Text != "" {
+		prefix += "\n"
+	}
+	shift := utf16Len(prefix)
+	if shift+utf16Len(p.Text) > p.maxLength() {
+		return false
+	}
+
+	entities := make([]gotgbot.MessageEntity, 0, len(p.Entities)+1)
+	entities = append(entities, gotgbot.MessageEntity{Type: "bold", Offset: 0, Length: utf16Len(header)})
+	for _, entity := range p.Entities {
+		entity.Offset += shift
+		entities = append(entities, entity)
+	}
+	p.Text = prefix + p.Text
+	p.Entities = entities
+	return true
+}
+
+func (p *copyPayload) sendMessageOpts() *gotgbot.SendMessageOpts {
+	opts := &gotgbot.SendMessageOpts{
+		Entities:           p.Entities,
+		LinkPreviewOptions: p.LinkPreviewOptions,
+	}
+	if p.ReplyMarkup != nil {
+		opts.ReplyMarkup = *p.ReplyMarkup
+	}
+	return opts
+}
+
+func (p *copyPayload) copyMessageOpts() *gotgbot.CopyMessageOpts {
+	opts := &gotgbot.CopyMessageOpts{}
+	if p.CanCaption {
+		caption := p.Text
+		opts.Caption = &caption
+		opts.CaptionEntities = p.Entities
+		opts.ShowCaptionAboveMedia = p.ShowCaptionAboveMedia
+	}
+	// CopyMessage carries the original inline keyboard over unless told otherwise,
+	// and an empty inline keyboard is rejected, so clear it with a keyboard removal
+	if p.ReplyMarkup != nil {
+		opts.ReplyMarkup = *p.ReplyMarkup
+	} else if p.StripMarkup {
+		opts.ReplyMarkup = gotgbot.ReplyKeyboardRemove{RemoveKeyboard: true}
+	}
+	return opts
+}
+
+// copyMessage sends a copy of a guest message to chatID, in the topic threadID
+// if it is not 0, and returns the ID of the copy. A header that cannot be
+// attached to the message is sent as a separate line before it.
+func (f *Forwarder) copyMessage(bot *gotgbot.Bot, chatID int64, threadID int64, msg *gotgbot.Message, header string) (int64, error) {
+	payload := newCopyPayload(msg)
+	if !payload.prependHeader(header) {
+		if _, err := bot.SendMessage(chatID, header, &gotgbot.SendMessageOpts{
+			MessageThreadId: threadID,
+			Entities:        []gotgbot.MessageEntity{{Type: "bold", Offset: 0, Length: utf16Len(header)}},
+		}); err != nil {
+			return 0, fmt.Errorf("failed to send copy header: %w", err)
+		}
+	}
+
+	if payload.IsText {
+		opts := payload.sendMessageOpts()
+		opts.MessageThreadId = threadID
+		sent, err := bot.SendMessage(chatID, payload.Text, opts)
+		if err != nil {
+			return 0, fmt.Errorf("failed to copy message: %w", err)
+		}
+		return sent.MessageId, nil
+	}
+
+	opts := payload.copyMessageOpts()
+	opts.MessageThreadId = threadID
+	copied, err := bot.CopyMessage(chatID, msg.Chat.Id, msg.MessageId, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy message: %w", err)
+	}
+	return copied.MessageId, nil
+}
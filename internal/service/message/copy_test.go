@@ -0,0 +1,243 @@
+package message
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// mixedEntityMessage is "Hi 👋 secret ✨ docs" with bold, spoiler, custom emoji
+// and a text link. The emoji are surrogate pairs, so offsets differ from byte
+// and rune positions.
+func mixedEntityMessage() *gotgbot.Message {
+	return &gotgbot.Message{
+		MessageId: 10,
+		Chat:      gotgbot.Chat{Id: 42, Type: "private"},
+		Text:      "Hi 👋 secret ✨ docs",
+		Entities: []gotgbot.MessageEntity{
+			{Type: "bold", Offset: 0, Length: 2},
+			{Type: "spoiler", Offset: 6, Length: 6},
+			{Type: "custom_emoji", Offset: 13, Length: 1, CustomEmojiId: "5368324170671202286"},
+			{Type: "text_link", Offset: 15, Length: 4, Url: "https://example.com/docs"},
+		},
+		LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true},
+	}
+}
+
+func TestUTF16Len(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"привет", 6},
+		{"👋", 2},
+		{"Hi 👋 secret ✨ docs", 19},
+	}
+
+	for _, tt := range tests {
+		if got := utf16Len(tt.in); got != tt.want {
+			t.Fatalf("utf16Len(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewCopyPayload(t *testing.T) {
+	t.Run("text keeps entities", func(t *testing.T) {
+		msg := mixedEntityMessage()
+		p := newCopyPayload(msg)
+		if !p.IsText || p.Text != msg.Text {
+			t.Fatalf("got IsText=%v Text=%q, want text payload %q", p.IsText, p.Text, msg.Text)
+		}
+		if !reflect.DeepEqual(p.Entities, msg.Entities) {
+			t.Fatalf("entities = %+v, want %+v", p.Entities, msg.Entities)
+		}
+		opts := p.sendMessageOpts()
+		if !reflect.DeepEqual(opts.Entities, msg.Entities) {
+			t.Fatalf("send opts entities = %+v, want %+v", opts.Entities, msg.Entities)
+		}
+		if opts.LinkPreviewOptions != msg.LinkPreviewOptions {
+			t.Fatalf("link preview options not passed through")
+		}
+	})
+
+	t.Run("photo caption keeps entities", func(t *testing.T) {
+		msg := &gotgbot.Message{
+			Photo:   []gotgbot.PhotoSize{{FileId: "photo"}},
+			Caption: "look ✨ here",
+			CaptionEntities: []gotgbot.MessageEntity{
+				{Type: "custom_emoji", Offset: 5, Length: 1, CustomEmojiId: "5368324170671202286"},
+				{Type: "spoiler", Offset: 7, Length: 4},
+			},
+			ShowCaptionAboveMedia: true,
+			HasMediaSpoiler:       true,
+		}
+		p := newCopyPayload(msg)
+		if p.IsText || !p.CanCaption {
+			t.Fatalf("got IsText=%v CanCaption=%v, want captioned media", p.IsText, p.CanCaption)
+		}
+		opts := p.copyMessageOpts()
+		if opts.Caption == nil || *opts.Caption != msg.Caption {
+			t.Fatalf("caption = %v, want %q", opts.Caption, msg.Caption)
+		}
+		if !reflect.DeepEqual(opts.CaptionEntities, msg.CaptionEntities) {
+			t.Fatalf("caption entities = %+v, want %+v", opts.CaptionEntities, msg.CaptionEntities)
+		}
+		if !opts.ShowCaptionAboveMedia {
+			t.Fatalf("ShowCaptionAboveMedia not passed through")
+		}
+	})
+
+	t.Run("sticker has no caption", func(t *testing.T) {
+		p := newCopyPayload(&gotgbot.Message{Sticker: &gotgbot.Sticker{FileId: "sticker"}})
+		if p.IsText || p.CanCaption {
+			t.Fatalf("got IsText=%v CanCaption=%v, want uncaptioned media", p.IsText, p.CanCaption)
+		}
+		if opts := p.copyMessageOpts(); opts.Caption != nil || opts.CaptionEntities != nil {
+			t.Fatalf("sticker copy opts should not set a caption: %+v", opts)
+		}
+	})
+
+	t.Run("payload does not alias message entities", func(t *testing.T) {
+		msg := mixedEntityMessage()
+		p := newCopyPayload(msg)
+		p.prependHeader("From guest")
+		if msg.Entities[0].Offset != 0 || msg.Entities[1].Offset != 6 {
+			t.Fatalf("original entities were modified: %+v", msg.Entities)
+		}
+	})
+}
+
+func TestCopyPayloadPrependHeader(t *testing.T) {
+	t.Run("shifts mixed entities", func(t *testing.T) {
+		msg := mixedEntityMessage()
+		p := newCopyPayload(msg)
+		header := "👤 Alice"
+		if !p.prependHeader(header) {
+			t.Fatalf("prependHeader rejected a short header")
+		}
+		if want := header + "\n" + msg.Text; p.Text != want {
+			t.Fatalf("text = %q, want %q", p.Text, want)
+		}
+
+		shift := int64(9) // "👤 Alice\n" is 9 UTF-16 code units
+		want := []gotgbot.MessageEntity{
+			{Type: "bold", Offset: 0, Length: 8},
+			{Type: "bold", Offset: 0 + shift, Length: 2},
+			{Type: "spoiler", Offset: 6 + shift, Length: 6},
+			{Type: "custom_emoji", Offset: 13 + shift, Length: 1, CustomEmojiId: "5368324170671202286"},
+			{Type: "text_link", Offset: 15 + shift, Length: 4, Url: "https://example.com/docs"},
+		}
+		if !reflect.DeepEqual(p.Entities, want) {
+			t.Fatalf("entities = %+v, want %+v", p.Entities, want)
+		}
+	})
+
+	t.Run("captionless photo gets header alone", func(t *testing.T) {
+		p := newCopyPayload(&gotgbot.Message{Photo: []gotgbot.PhotoSize{{FileId: "photo"}}})
+		if !p.prependHeader("Alice") {
+			t.Fatalf("prependHeader rejected a header on a photo")
+		}
+		if p.Text != "Alice" {
+			t.Fatalf("text = %q, want %q", p.Text, "Alice")
+		}
+		want := []gotgbot.MessageEntity{{Type: "bold", Offset: 0, Length: 5}}
+		if !reflect.DeepEqual(p.Entities, want) {
+			t.Fatalf("entities = %+v, want %+v", p.Entities, want)
+		}
+	})
+
+	tests := []struct {
+		name string
+		msg  *gotgbot.Message
+	}{
+		{"sticker", &gotgbot.Message{Sticker: &gotgbot.Sticker{FileId: "sticker"}}},
+		{"text too long", &gotgbot.Message{Text: strings.Repeat("a", maxTextLength-3)}},
+		{"caption too long", &gotgbot.Message{
+			Document: &gotgbot.Document{FileId: "doc"},
+			Caption:  strings.Repeat("a", maxCaptionLength-3),
+			CaptionEntities: []gotgbot.MessageEntity{
+				{Type: "italic", Offset: 0, Length: 5},
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			p := newCopyPayload(tt.msg)
+			before := *p
+			if p.prependHeader("Alice") {
+				t.Fatalf("prependHeader accepted a header it cannot attach")
+			}
+			if !reflect.DeepEqual(*p, before) {
+				t.Fatalf("payload changed after rejection: %+v", p)
+			}
+		})
+	}
+}
+
+func TestNormalizeReplyMarkup(t *testing.T) {
+	query := "search"
+	markup := &gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{{Text: "Open", Url: "https://example.com"}, {Text: "Vote", CallbackData: "vote:1"}},
+		{{Text: "Search", SwitchInlineQuery: &query}, {Text: "Play", CallbackGame: &gotgbot.CallbackGame{}}},
+		{{Text: "Code", CopyText: &gotgbot.CopyTextButton{Text: "ABC-123"}}, {Text: "Pay", Pay: true}},
+	}}
+
+	got := normalizeReplyMarkup(markup)
+	want := &gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{{Text: "Open", Url: "https://example.com"}},
+		{{Text: "Code", CopyText: &gotgbot.CopyTextButton{Text: "ABC-123"}}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizeReplyMarkup() = %+v, want %+v", got, want)
+	}
+
+	dead := &gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{{Text: "Vote", CallbackData: "vote:1"}},
+	}}
+	if got := normalizeReplyMarkup(dead); got != nil {
+		t.Fatalf("normalizeReplyMarkup() = %+v, want nil", got)
+	}
+}
+
+func TestCopyPayloadReplyMarkup(t *testing.T) {
+	via := &gotgbot.User{Id: 1, IsBot: true, Username: "somebot"}
+	deadKeyboard := &gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{{Text: "Vote", CallbackData: "vote:1"}},
+	}}
+	linkKeyboard := &gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{{Text: "Open", Url: "https://example.com"}, {Text: "Vote", CallbackData: "vote:1"}},
+	}}
+	kept := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{{Text: "Open", Url: "https://example.com"}},
+	}}
+
+	tests := []struct {
+		name     string
+		msg      *gotgbot.Message
+		wantSend gotgbot.ReplyMarkup
+		wantCopy gotgbot.ReplyMarkup
+	}{
+		{"no keyboard", &gotgbot.Message{Text: "hi"}, nil, nil},
+		{"text with dead buttons", &gotgbot.Message{Text: "hi", ViaBot: via, ReplyMarkup: deadKeyboard}, nil, gotgbot.ReplyKeyboardRemove{RemoveKeyboard: true}},
+		{"text with link button", &gotgbot.Message{Text: "hi", ViaBot: via, ReplyMarkup: linkKeyboard}, kept, kept},
+		{"photo with dead buttons", &gotgbot.Message{Photo: []gotgbot.PhotoSize{{FileId: "photo"}}, ViaBot: via, ReplyMarkup: deadKeyboard}, nil, gotgbot.ReplyKeyboardRemove{RemoveKeyboard: true}},
+		{"photo with link button", &gotgbot.Message{Photo: []gotgbot.PhotoSize{{FileId: "photo"}}, ViaBot: via, ReplyMarkup: linkKeyboard}, kept, kept},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newCopyPayload(tt.msg)
+			if got := p.sendMessageOpts().ReplyMarkup; !reflect.DeepEqual(got, tt.wantSend) {
+				t.Fatalf("send reply markup = %+v, want %+v", got, tt.wantSend)
+			}
+			if got := p.copyMessageOpts().ReplyMarkup; !reflect.DeepEqual(got, tt.wantCopy) {
+				t.Fatalf("copy reply markup = %+v, want %+v", got, tt.wantCopy)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package message
+
+import (
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// SetDeadLetters makes the forwarder save deliveries it gives up on as dead
+// letters, instead of only notifying the manager
+func (f *Forwarder) SetDeadLetters(deadLetters repository.DeadLetterRepository) {
+	f.deadLetters = deadLetters
+}
+
+// deadLetter saves a delivery that was given up on and reports whether it was saved
+func (f *Forwarder) deadLetter(deadLetter *models.DeadLetter) bool {
+	if f.deadLetters == nil {
+		return false
+	}
+	if _, err := f.deadLetters.Create(deadLetter); err != nil {
+		f.logger.Warn("Failed to save dead letter",
+			zap.String("bot_id", deadLetter.BotID.String()),
+			zap.Int64("guest_chat_id", deadLetter.GuestChatID),
+			zap.Int64("guest_message_id", deadLetter.GuestMessageID),
+			zap.Error(err))
+		return false
+	}
+	f.logger.Info("Delivery saved as dead letter",
+		zap.String("bot_id", deadLetter.BotID.String()),
+		zap.Int64("guest_chat_id", deadLetter.GuestChatID),
+		zap.Int64("guest_message_id", deadLetter.GuestMessageID),
+		zap.String("reason", deadLetter.Reason))
+	return true
+}
+
+// deadLetterQueued saves a queued delivery the retry queue gave up on
+func (f *Forwarder) deadLetterQueued(delivery *models.PendingDelivery, reason FailureReason) bool {
+	var recipientChatID int64
+	if recipient, err := f.recipientRepo.GetByID(delivery.RecipientID); err == nil {
+		recipientChatID = recipient.ChatID
+	}
+	return f.deadLetter(&models.DeadLetter{
+		BotID:           delivery.BotID,
+		RecipientID:     delivery.RecipientID,
+		RecipientChatID: recipientChatID,
+		GuestChatID:     delivery.GuestChatID,
+		GuestMessageID:  delivery.GuestMessageID,
+		Message:         delivery.Message,
+		Reason:          string(reason),
+		LastError:       delivery.LastError,
+		QueueAttempts:   delivery.Attempts,
+	})
+}
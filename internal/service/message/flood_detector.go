@@ -0,0 +1,82 @@
+package message
+
+import (
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+
+	"github.com/google/uuid"
+)
+
+// floodDetectorSweepSize is how many guests may be tracked before guests
+// without recent messages are forgotten
+const floodDetectorSweepSize = 10000
+
+// floodKey is a guest of a bot
+type floodKey struct {
+	botID  uuid.UUID
+	userID int64
+}
+
+// FloodDetector finds guests who send more than the allowed number of messages
+// within the window, so they can be muted. It only counts; muting is up to the
+// caller, which records it in the blacklist.
+//
+// One detector is shared by all bots. It is kept in memory, so each instance
+// counts the messages it receives.
+type FloodDetector struct {
+	maxMessages int
+	window      time.Duration
+
+	mu       sync.Mutex
+	messages map[floodKey][]time.Time // Recent messages per guest
+}
+
+func NewFloodDetector(cfg *config.Config) *FloodDetector {
+	return &FloodDetector{
+		maxMessages: cfg.AntiFlood.MaxMessages,
+		window:      time.Duration(cfg.AntiFlood.WindowSeconds) * time.Second,
+		messages:    make(map[floodKey][]time.Time),
+	}
+}
+
+// Message records a message from a guest and reports whether it takes the
+// guest over the limit. The guest's count starts over once it does, so a
+// flood is reported once.
+func (d *FloodDetector) Message(botID uuid.UUID, userID int64, now time.Time) bool {
+	if d.maxMessages <= 0 {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.messages) > floodDetectorSweepSize {
+		d.sweep(now)
+	}
+
+	key := floodKey{botID: botID, userID: userID}
+	recent := d.messages[key][:0]
+	for _, at := range d.messages[key] {
+		if now.Sub(at) < d.window {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, now)
+	if len(recent) > d.maxMessages {
+		delete(d.messages, key)
+		return true
+	}
+	d.messages[key] = recent
+	return false
+}
+
+// sweep forgets guests without messages within the window
+func (d *FloodDetector) sweep(now time.Time) {
+	for key, messages := range d.messages {
+		if len(messages) == 0 || now.Sub(messages[len(messages)-1]) >= d.window {
+			delete(d.messages, key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+
+	"github.com/google/uuid"
+)
+
+func newTestFloodDetector(maxMessages int) *FloodDetector {
+	return NewFloodDetector(&config.Config{
+		AntiFlood: config.AntiFloodConfig{
+			MaxMessages:   maxMessages,
+			WindowSeconds: 60,
+			MuteSeconds:   600,
+		},
+	})
+}
+
+func TestFloodDetectorReportsOnceOverTheLimit(t *testing.T) {
+	detector := newTestFloodDetector(3)
+	botID := uuid.New()
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if detector.Message(botID, 1, now) {
+			t.Fatalf("message %d reported as flooding within the limit", i+1)
+		}
+	}
+	// Another guest, or the same guest of another bot, is counted separately
+	if detector.Message(botID, 2, now) || detector.Message(uuid.New(), 1, now) {
+		t.Fatal("a different guest was reported as flooding")
+	}
+	if !detector.Message(botID, 1, now) {
+		t.Fatal("fourth message within the window was not reported")
+	}
+	// The count starts over after a report
+	if detector.Message(botID, 1, now) {
+		t.Fatal("flood reported again right after the first report")
+	}
+}
+
+func TestFloodDetectorForgetsMessagesOutsideTheWindow(t *testing.T) {
+	detector := newTestFloodDetector(2)
+	botID := uuid.New()
+	now := time.Now()
+
+	detector.Message(botID, 1, now)
+	detector.Message(botID, 1, now.Add(30*time.Second))
+	if detector.Message(botID, 1, now.Add(61*time.Second)) {
+		t.Fatal("message outside the window counted towards the limit")
+	}
+	if !detector.Message(botID, 1, now.Add(62*time.Second)) {
+		t.Fatal("third message within the window was not reported")
+	}
+}
+
+func TestFloodDetectorDisabled(t *testing.T) {
+	detector := newTestFloodDetector(0)
+	botID := uuid.New()
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		if detector.Message(botID, 1, now) {
+			t.Fatal("flood reported with anti-flood disabled")
+		}
+	}
+}
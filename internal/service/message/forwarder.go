@@ -2,6 +2,7 @@ package message
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -23,6 +24,8 @@ type Forwarder struct {
 	recipientRepo      repository.RecipientRepository
 	guestRepo          repository.GuestRepository
 	messageMappingRepo repository.MessageMappingRepository
+	inboundMessageRepo repository.InboundMessageRepository
+	guestTopicRepo     repository.GuestTopicRepository
 	rateLimiter        *RateLimiter
 	retryHandler       *RetryHandler
 	config             *config.Config
@@ -30,8 +33,30 @@ type Forwarder struct {
 	groupMonitor       GroupMonitorInterface
 	errorNotifier      ErrorNotifierInterface
 	managerNotifier    ManagerNotifierInterface
+	quotaEnforcer      QuotaEnforcerInterface
+	hooks              HooksInterface
+	deliveryQueue      repository.PendingDeliveryRepository
+	deadLetters        repository.DeadLetterRepository
+	loopDetector       *LoopDetector
+	routingRuleRepo    repository.RoutingRuleRepository
+	profileCards       ProfileCardsInterface
 }
 
+// HooksInterface is told about delivered messages, e.g. by programs embedding
+// the forwarder. Hooks run on the update's goroutine and should return quickly.
+type HooksInterface interface {
+	GuestMessageForwarded(ctx context.Context, botID uuid.UUID, guestChatID int64, message *gotgbot.Message, result *ForwardResult)
+	ReplyForwarded(ctx context.Context, botID uuid.UUID, guestChatID int64, recipientChatID int64, reply *gotgbot.Message)
+}
+
+// QuotaEnforcerInterface counts forwarded messages against the bot's monthly quota
+type QuotaEnforcerInterface interface {
+	Consume(ctx context.Context, botID uuid.UUID) (bool, error)
+}
+
+// ErrQuotaExceeded is returned when the bot's monthly message quota is used up
+var ErrQuotaExceeded = errors.New("monthly message quota exceeded")
+
 type ManagerNotifierInterface interface {
 	NotifyManager(ctx context.Context, botID uuid.UUID, message string) error
 }
@@ -48,6 +73,29 @@ type ForwardResult struct {
 	SuccessCount int
 	FailureCount int
 	Errors       []error
+	Outcomes     []RecipientOutcome // One entry per recipient
+	// Queued is how many of the failed recipients will get the message from the retry queue
+	Queued int
+	// DeadLettered is how many of the failed deliveries were saved as dead
+	// letters for the manager to requeue or discard
+	DeadLettered int
+	// WaitStarted is true when the message was delivered and the guest was not
+	// already waiting for a reply, i.e. it opened a new conversation turn
+	WaitStarted bool
+	// DryRun is true when the bot is in dry-run mode and the message was not
+	// sent; Outcomes list the recipients that would have received it
+	DryRun bool
+}
+
+// GuestThrottledError is returned by ForwardToRecipients when the guest is
+// sending messages faster than the bot's rate limit allows
+type GuestThrottledError struct {
+	RetryAfter time.Duration
+	Notify     bool // Whether the guest should be told (first rejection of the episode)
+}
+
+func (e *GuestThrottledError) Error() string {
+	return fmt.Sprintf("guest rate limit exceeded, retry after %s", e.RetryAfter)
 }
 
 func NewForwarder(
@@ -55,6 +103,8 @@ func NewForwarder(
 	recipientRepo repository.RecipientRepository,
 	guestRepo repository.GuestRepository,
 	messageMappingRepo repository.MessageMappingRepository,
+	inboundMessageRepo repository.InboundMessageRepository,
+	guestTopicRepo repository.GuestTopicRepository,
 	rateLimiter *RateLimiter,
 	retryHandler *RetryHandler,
 	cfg *config.Config,
@@ -65,6 +115,8 @@ func NewForwarder(
 		recipientRepo:      recipientRepo,
 		guestRepo:          guestRepo,
 		messageMappingRepo: messageMappingRepo,
+		inboundMessageRepo: inboundMessageRepo,
+		guestTopicRepo:     guestTopicRepo,
 		rateLimiter:        rateLimiter,
 		retryHandler:       retryHandler,
 		config:             cfg,
@@ -84,12 +136,91 @@ func (f *Forwarder) SetManagerNotifier(notifier ManagerNotifierInterface) {
 	f.managerNotifier = notifier
 }
 
+func (f *Forwarder) SetQuotaEnforcer(enforcer QuotaEnforcerInterface) {
+	f.quotaEnforcer = enforcer
+}
+
+func (f *Forwarder) SetHooks(hooks HooksInterface) {
+	f.hooks = hooks
+}
+
+// ConsumeQuota counts one message against the bot's quota and returns
+// ErrQuotaExceeded when it is used up. Quota lookup errors do not block forwarding.
+// ForwardToRecipients and ForwardReplyToGuest call it themselves; callers of
+// ForwardGuestReplyToRecipient call it once per guest reply.
+func (f *Forwarder) ConsumeQuota(ctx context.Context, botID uuid.UUID) error {
+	if f.quotaEnforcer == nil {
+		return nil
+	}
+	allowed, err := f.quotaEnforcer.Consume(ctx, botID)
+	if err != nil {
+		f.logger.Warn("Failed to check message quota, allowing message",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return nil
+	}
+	if !allowed {
+		f.logger.Info("Monthly message quota exceeded, not forwarding",
+			zap.String("bot_id", botID.String()))
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// fallbackRateLimitWait bounds how long delivery to the fallback recipient
+// waits for the Telegram API rate limiter before sending anyway
+const fallbackRateLimitWait = 5 * time.Second
+
+// waitTelegramAPI blocks until the Telegram API rate limiter allows a request,
+// or fallbackRateLimitWait has passed
+func (f *Forwarder) waitTelegramAPI(ctx context.Context, botID uuid.UUID) error {
+	deadline := time.Now().Add(fallbackRateLimitWait)
+	for !f.rateLimiter.AllowBotTelegramAPI(ctx, botID) {
+		if time.Now().After(deadline) {
+			f.logger.Warn("Telegram API still rate limited, sending to fallback recipient anyway")
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return nil
+}
+
 func (f *Forwarder) ForwardToRecipients(
 	ctx context.Context,
 	bot *gotgbot.Bot,
 	botID uuid.UUID,
 	guestChatID int64,
 	message *gotgbot.Message,
+) (*ForwardResult, error) {
+	return f.forwardToRecipients(ctx, bot, botID, guestChatID, message, "")
+}
+
+// ForwardHeld delivers a message a guest asked to hold until later. It is
+// copied under heldHeader, which names the guest, since forwarding would show
+// the command it was sent with. Failed deliveries are not queued for retry;
+// the caller keeps the held message and tries it again.
+func (f *Forwarder) ForwardHeld(
+	ctx context.Context,
+	bot *gotgbot.Bot,
+	botID uuid.UUID,
+	guestChatID int64,
+	message *gotgbot.Message,
+	heldHeader string,
+) (*ForwardResult, error) {
+	return f.forwardToRecipients(ctx, bot, botID, guestChatID, message, heldHeader)
+}
+
+func (f *Forwarder) forwardToRecipients(
+	ctx context.Context,
+	bot *gotgbot.Bot,
+	botID uuid.UUID,
+	guestChatID int64,
+	message *gotgbot.Message,
+	heldHeader string,
 ) (*ForwardResult, error) {
 	messageID := message.MessageId
 
@@ -112,6 +243,9 @@ func (f *Forwarder) ForwardToRecipients(
 		zap.String("bot_id", botID.String()),
 		zap.Int("recipient_count", len(recipients)))
 
+	recipients = f.applyRoutingRules(botID, message, recipients)
+	recipients = f.skipGuestChat(botID, guestChatID, recipients)
+	recipients = f.skipLooping(ctx, botID, guestChatID, recipients)
 	if len(recipients) == 0 {
 		f.logger.Debug("No recipients found, skipping forwarding",
 			zap.String("bot_id", botID.String()),
@@ -122,7 +256,7 @@ func (f *Forwarder) ForwardToRecipients(
 	f.logger.Debug("Getting or creating guest record",
 		zap.String("bot_id", botID.String()),
 		zap.Int64("guest_chat_id", guestChatID))
-	_, err = f.guestRepo.GetOrCreateByBotIDAndUserID(botID, guestChatID)
+	guest, err := f.guestRepo.GetOrCreateByBotIDAndUserID(botID, guestChatID)
 	if err != nil {
 		f.logger.Debug("Failed to get or create guest",
 			zap.String("bot_id", botID.String()),
@@ -133,48 +267,49 @@ func (f *Forwarder) ForwardToRecipients(
 	f.logger.Debug("Guest record retrieved/created",
 		zap.String("bot_id", botID.String()),
 		zap.Int64("guest_chat_id", guestChatID))
+	if guest.BlockedBotAt != nil {
+		// Guests can only write to the bot after unblocking it
+		f.setGuestBlocked(botID, guestChatID, false)
+	}
+
+	header, private, err := f.guestHeader(botID, guest)
+	if err != nil {
+		return nil, err
+	}
+	if heldHeader != "" {
+		header, private = heldHeader, true
+	}
 
 	// Check guest message rate limit
-	// If rate limit exceeded, delay sending by waiting
+	// Throttled messages are rejected so the guest can be told to slow down
 	f.logger.Debug("Checking guest message rate limit",
 		zap.String("bot_id", botID.String()),
 		zap.Int64("guest_chat_id", guestChatID))
-	if !f.rateLimiter.AllowGuestMessage(ctx, botID, guestChatID) {
-		f.logger.Warn("Guest message rate limit exceeded, delaying send",
-			zap.String("bot_id", botID.String()),
-			zap.Int64("guest_chat_id", guestChatID))
-		// Delay sending: wait for 1 second (rate limit window)
-		f.logger.Debug("Waiting 1 second for rate limit window",
+	throttle := f.rateLimiter.CheckBotGuestMessage(ctx, botID, guestChatID)
+	if !throttle.Allowed {
+		f.logger.Warn("Guest message rate limit exceeded, rejecting message",
 			zap.String("bot_id", botID.String()),
-			zap.Int64("guest_chat_id", guestChatID))
-		select {
-		case <-ctx.Done():
-			f.logger.Debug("Context cancelled during rate limit delay",
-				zap.String("bot_id", botID.String()),
-				zap.Int64("guest_chat_id", guestChatID))
-			return nil, ctx.Err()
-		case <-time.After(1 * time.Second):
-			// Retry rate limit check after delay
-			f.logger.Debug("Rechecking rate limit after delay",
-				zap.String("bot_id", botID.String()),
-				zap.Int64("guest_chat_id", guestChatID))
-			if !f.rateLimiter.AllowGuestMessage(ctx, botID, guestChatID) {
-				f.logger.Warn("Guest message still rate limited after delay",
-					zap.String("bot_id", botID.String()),
-					zap.Int64("guest_chat_id", guestChatID))
-				// Continue anyway to avoid blocking indefinitely
-			} else {
-				f.logger.Debug("Rate limit cleared after delay",
-					zap.String("bot_id", botID.String()),
-					zap.Int64("guest_chat_id", guestChatID))
-			}
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Duration("retry_after", throttle.RetryAfter),
+			zap.Bool("notify", throttle.Notify))
+		return nil, &GuestThrottledError{
+			RetryAfter: throttle.RetryAfter,
+			Notify:     throttle.Notify,
 		}
-	} else {
-		f.logger.Debug("Guest message rate limit check passed",
-			zap.String("bot_id", botID.String()),
-			zap.Int64("guest_chat_id", guestChatID))
+	}
+	f.logger.Debug("Guest message rate limit check passed",
+		zap.String("bot_id", botID.String()),
+		zap.Int64("guest_chat_id", guestChatID))
+
+	if f.IsDryRun(botID) {
+		return f.dryRun(ctx, botID, guestChatID, message, recipients), nil
 	}
 
+	if err := f.ConsumeQuota(ctx, botID); err != nil {
+		return nil, err
+	}
+	card := f.profileCard(ctx, botID, guest, message, private)
+
 	f.logger.Debug("Starting concurrent forwarding to recipients",
 		zap.String("bot_id", botID.String()),
 		zap.Int64("message_id", messageID),
@@ -183,7 +318,8 @@ func (f *Forwarder) ForwardToRecipients(
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	result := &ForwardResult{
-		Errors: make([]error, 0),
+		Errors:   make([]error, 0),
+		Outcomes: make([]RecipientOutcome, 0, len(recipients)),
 	}
 
 	for i, recipient := range recipients {
@@ -198,16 +334,40 @@ func (f *Forwarder) ForwardToRecipients(
 				zap.String("recipient_type", string(rec.RecipientType)),
 				zap.Int("recipient_index", index))
 
+			outcome := RecipientOutcome{
+				RecipientID:   rec.ID,
+				ChatID:        rec.ChatID,
+				RecipientType: rec.RecipientType,
+			}
+
 			f.logger.Debug("Checking Telegram API rate limit",
 				zap.String("bot_id", botID.String()),
 				zap.Int64("recipient_chat_id", rec.ChatID))
-			if !f.rateLimiter.AllowTelegramAPI(ctx) {
+			if rec.IsFallback {
+				// The fallback recipient must not lose messages, so wait for the limiter instead of skipping
+				if err := f.waitTelegramAPI(ctx, botID); err != nil {
+					outcome.Status = DeliveryStatusSkipped
+					outcome.Reason = ClassifyError(err)
+					outcome.Err = err
+					mu.Lock()
+					result.FailureCount++
+					result.Errors = append(result.Errors, err)
+					result.Outcomes = append(result.Outcomes, outcome)
+					mu.Unlock()
+					return
+				}
+			} else if !f.rateLimiter.AllowBotTelegramAPI(ctx, botID) {
 				f.logger.Warn("Rate limit exceeded for Telegram API",
 					zap.String("bot_id", botID.String()),
 					zap.Int64("recipient_chat_id", rec.ChatID))
+				rateLimitErr := fmt.Errorf("rate limit exceeded")
+				outcome.Status = DeliveryStatusSkipped
+				outcome.Reason = FailureReasonRateLimited
+				outcome.Err = rateLimitErr
 				mu.Lock()
 				result.FailureCount++
-				result.Errors = append(result.Errors, fmt.Errorf("rate limit exceeded"))
+				result.Errors = append(result.Errors, rateLimitErr)
+				result.Outcomes = append(result.Outcomes, outcome)
 				mu.Unlock()
 				f.logger.Debug("Skipping forwarding due to rate limit",
 					zap.String("bot_id", botID.String()),
@@ -215,39 +375,48 @@ func (f *Forwarder) ForwardToRecipients(
 				return
 			}
 
+			if card != "" {
+				f.sendProfileCard(bot, botID, guest, rec, message, card)
+			}
+
 			f.logger.Debug("Rate limit check passed, starting retry handler",
 				zap.String("bot_id", botID.String()),
 				zap.Int64("recipient_chat_id", rec.ChatID),
 				zap.Int("max_attempts", f.config.Retry.MaxAttempts))
 			err := f.retryHandler.Retry(ctx, func() error {
+				outcome.Attempts++
 				f.logger.Debug("Attempting to forward message",
 					zap.String("bot_id", botID.String()),
 					zap.Int64("message_id", messageID),
 					zap.Int64("guest_chat_id", guestChatID),
 					zap.Int64("recipient_chat_id", rec.ChatID))
-				return f.forwardMessage(ctx, bot, botID, guestChatID, message.MessageId, rec.ChatID, rec)
+				return f.forwardMessage(ctx, bot, botID, guest, message, rec, header, private)
 			})
 
 			mu.Lock()
 			if err != nil {
+				outcome.Status = DeliveryStatusFailed
+				outcome.Reason = ClassifyError(err)
+				outcome.Err = err
 				result.FailureCount++
 				result.Errors = append(result.Errors, err)
+				result.Outcomes = append(result.Outcomes, outcome)
 				f.logger.Warn("Failed to forward message after retries",
 					zap.String("bot_id", botID.String()),
 					zap.Int64("message_id", messageID),
 					zap.Int64("recipient_chat_id", rec.ChatID),
-					zap.Int("max_attempts", f.config.Retry.MaxAttempts),
+					zap.Int("attempts", outcome.Attempts),
+					zap.String("reason", string(outcome.Reason)),
 					zap.Error(err))
 
 				// Send failure notification to recipient
 				f.logger.Debug("Sending failure notification to recipient",
 					zap.String("bot_id", botID.String()),
 					zap.Int64("recipient_chat_id", rec.ChatID))
-				f.sendFailureNotification(ctx, bot, rec.ChatID, err, f.config.Retry.MaxAttempts)
+				f.sendFailureNotification(ctx, bot, botID, rec.ChatID, err, f.config.Retry.MaxAttempts)
 
 				// Check if it's a 401 error (Bot Token invalid)
-				errStr := err.Error()
-				if strings.Contains(errStr, "401") || strings.Contains(errStr, "Unauthorized") {
+				if outcome.Reason == FailureReasonUnauthorized {
 					f.logger.Debug("Detected 401 error, notifying critical error",
 						zap.String("bot_id", botID.String()),
 						zap.Int64("recipient_chat_id", rec.ChatID))
@@ -269,7 +438,9 @@ func (f *Forwarder) ForwardToRecipients(
 					}
 				}
 			} else {
+				outcome.Status = DeliveryStatusDelivered
 				result.SuccessCount++
+				result.Outcomes = append(result.Outcomes, outcome)
 				f.logger.Debug("Message forwarded successfully",
 					zap.String("bot_id", botID.String()),
 					zap.Int64("message_id", messageID),
@@ -290,6 +461,14 @@ func (f *Forwarder) ForwardToRecipients(
 		zap.Int("success_count", result.SuccessCount),
 		zap.Int("failure_count", result.FailureCount))
 
+	if heldHeader == "" {
+		result.Queued, result.DeadLettered = f.parkFailed(botID, guestChatID, message, result)
+	}
+	f.recordInboundMessage(botID, guestChatID, messageID, len(recipients), result.SuccessCount)
+	if result.SuccessCount > 0 {
+		result.WaitStarted = f.markAwaitingReply(botID, guestChatID)
+	}
+
 	// If there are failures after all retries, notify Manager
 	// According to requirements: "重试到最后失败则无需执行任何动作，通知 Manager 发生失败了"
 	if result.FailureCount > 0 && f.managerNotifier != nil {
@@ -297,24 +476,31 @@ func (f *Forwarder) ForwardToRecipients(
 			zap.String("bot_id", botID.String()),
 			zap.Int64("message_id", messageID),
 			zap.Int("failure_count", result.FailureCount))
-		errorSummary := make([]string, 0, len(result.Errors))
-		for _, err := range result.Errors {
-			errorSummary = append(errorSummary, utils.EscapeMarkdown(err.Error()))
+		failureSummary := make([]string, 0, result.FailureCount)
+		for _, outcome := range result.Failed() {
+			failureSummary = append(failureSummary, fmt.Sprintf("• `%d` (%s): %s after %d attempt(s) - %s",
+				outcome.ChatID,
+				outcome.RecipientType,
+				utils.EscapeMarkdown(string(outcome.Reason)),
+				outcome.Attempts,
+				utils.EscapeMarkdown(outcome.Err.Error())))
 		}
 		notificationMsg := fmt.Sprintf(
 			"*Batch Forwarding Failed*\n\n"+
 				"Bot ID: `%s`\n"+
 				"Success: %d\n"+
 				"Failures: %d\n"+
-				"Retry Attempts: %d\n"+
-				"Errors:\n%s\n"+
+				"Failed Recipients:\n%s\n"+
+				"Queued for retry: %d\n"+
+				"Saved as dead letters: %d\n"+
 				"Time: %s",
 			botID.String(),
 			result.SuccessCount,
 			result.FailureCount,
-			f.config.Retry.MaxAttempts,
-			strings.Join(errorSummary, "\n"),
-			time.Now().Format("2006-01-02 15:04:05"),
+			strings.Join(failureSummary, "\n"),
+			result.Queued,
+			result.DeadLettered,
+			utils.FormatTimestamp(time.Now(), f.location(botID)),
 		)
 		if notifyErr := f.managerNotifier.NotifyManager(ctx, botID, notificationMsg); notifyErr != nil {
 			f.logger.Warn("Failed to notify manager about batch forwarding failure",
@@ -332,71 +518,194 @@ func (f *Forwarder) ForwardToRecipients(
 		zap.Int64("message_id", messageID),
 		zap.Int("success_count", result.SuccessCount),
 		zap.Int("failure_count", result.FailureCount))
+
+	if f.hooks != nil {
+		f.hooks.GuestMessageForwarded(ctx, botID, guestChatID, message, result)
+	}
 	return result, nil
 }
 
+// IsDryRun reports whether the bot only logs guest messages instead of delivering them
+func (f *Forwarder) IsDryRun(botID uuid.UUID) bool {
+	bot, err := f.botRepo.GetByID(botID)
+	if err != nil {
+		f.logger.Debug("Failed to load bot dry-run setting, delivering",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return false
+	}
+	return bot.DryRun
+}
+
+// dryRun logs where a guest message would have been delivered without sending
+// it. Nothing counts against the quota, is queued or mapped, and no conversation
+// is opened, so recipients and the bot's statistics are not disturbed.
+func (f *Forwarder) dryRun(ctx context.Context, botID uuid.UUID, guestChatID int64, message *gotgbot.Message, recipients []*models.Recipient) *ForwardResult {
+	result := &ForwardResult{
+		DryRun:   true,
+		Outcomes: make([]RecipientOutcome, 0, len(recipients)),
+	}
+	for _, recipient := range recipients {
+		result.Outcomes = append(result.Outcomes, RecipientOutcome{
+			RecipientID:   recipient.ID,
+			ChatID:        recipient.ChatID,
+			RecipientType: recipient.RecipientType,
+			Status:        DeliveryStatusDryRun,
+		})
+		f.logger.Info("Dry run: guest message not delivered",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Int64("message_id", message.MessageId),
+			zap.Int64("recipient_chat_id", recipient.ChatID),
+			zap.String("recipient_type", string(recipient.RecipientType)),
+			zap.String("media_type", MediaType(message)))
+	}
+
+	if f.hooks != nil {
+		f.hooks.GuestMessageForwarded(ctx, botID, guestChatID, message, result)
+	}
+	return result
+}
+
+// senderID returns who wrote a guest message: the user, or the chat for
+// messages sent on behalf of a chat such as anonymous group admins. In a
+// private chat it equals the chat ID.
+func senderID(message *gotgbot.Message) int64 {
+	if message.SenderChat != nil {
+		return message.SenderChat.Id
+	}
+	if message.From != nil {
+		return message.From.Id
+	}
+	return message.Chat.Id
+}
+
+// skipGuestChat leaves out recipients that are the guest chat itself, e.g. a
+// manager writing to their own bot or a group added both as recipient and as
+// guest, so nobody gets their own messages back
+func (f *Forwarder) skipGuestChat(botID uuid.UUID, guestChatID int64, recipients []*models.Recipient) []*models.Recipient {
+	kept := recipients[:0:0]
+	for _, recipient := range recipients {
+		if recipient.ChatID == guestChatID {
+			f.logger.Debug("Not forwarding a message back to its own chat",
+				zap.String("bot_id", botID.String()),
+				zap.Int64("chat_id", guestChatID))
+			continue
+		}
+		kept = append(kept, recipient)
+	}
+	return kept
+}
+
 func (f *Forwarder) forwardMessage(
 	_ context.Context,
 	bot *gotgbot.Bot,
 	botID uuid.UUID,
-	guestChatID int64,
-	guestMessageID int64,
-	recipientChatID int64,
-	_ *models.Recipient,
+	guest *models.Guest,
+	message *gotgbot.Message,
+	recipient *models.Recipient,
+	header string,
+	private bool,
 ) error {
+	guestChatID := guest.GuestUserID
+	recipientChatID := recipient.ChatID
+	guestMessageID := message.MessageId
 	f.logger.Debug("Calling Telegram API to forward message",
 		zap.String("bot_id", botID.String()),
 		zap.Int64("guest_chat_id", guestChatID),
 		zap.Int64("guest_message_id", guestMessageID),
-		zap.Int64("recipient_chat_id", recipientChatID))
-	forwardedMsg, err := bot.ForwardMessage(recipientChatID, guestChatID, guestMessageID, nil)
+		zap.Int64("recipient_chat_id", recipientChatID),
+		zap.Bool("private", private))
+	forwardedMessageID, err := f.sendToGuestTopic(bot, botID, guest, recipient, message, header, private)
 	if err != nil {
 		f.logger.Debug("Telegram API forward message failed",
 			zap.String("bot_id", botID.String()),
 			zap.Int64("guest_message_id", guestMessageID),
 			zap.Int64("recipient_chat_id", recipientChatID),
 			zap.Error(err))
-		return fmt.Errorf("failed to forward message: %w", err)
+		return err
 	}
 
 	f.logger.Debug("Message forwarded successfully via Telegram API",
 		zap.String("bot_id", botID.String()),
 		zap.Int64("guest_message_id", guestMessageID),
 		zap.Int64("recipient_chat_id", recipientChatID),
-		zap.Int64("forwarded_message_id", forwardedMsg.MessageId))
+		zap.Int64("forwarded_message_id", forwardedMessageID))
 
 	mapping := &models.MessageMapping{
 		BotID:              botID,
 		GuestChatID:        guestChatID,
 		GuestMessageID:     guestMessageID,
+		GuestUserID:        senderID(message),
 		RecipientChatID:    recipientChatID,
-		RecipientMessageID: forwardedMsg.MessageId,
+		RecipientMessageID: forwardedMessageID,
 		Direction:          models.MessageDirectionInbound,
 	}
 
 	f.logger.Debug("Creating message mapping record",
 		zap.String("bot_id", botID.String()),
 		zap.Int64("guest_message_id", guestMessageID),
-		zap.Int64("recipient_message_id", forwardedMsg.MessageId))
+		zap.Int64("recipient_message_id", forwardedMessageID))
 	if err := f.messageMappingRepo.Create(mapping); err != nil {
 		f.logger.Warn("Failed to create message mapping",
 			zap.String("bot_id", botID.String()),
 			zap.Int64("guest_message_id", guestMessageID),
-			zap.Int64("recipient_message_id", forwardedMsg.MessageId),
+			zap.Int64("recipient_message_id", forwardedMessageID),
 			zap.Error(err))
 	} else {
 		f.logger.Debug("Message mapping created successfully",
 			zap.String("bot_id", botID.String()),
 			zap.Int64("guest_message_id", guestMessageID),
-			zap.Int64("recipient_message_id", forwardedMsg.MessageId))
+			zap.Int64("recipient_message_id", forwardedMessageID))
 	}
 
 	return nil
 }
 
+// guestHeader returns the header shown above the guest's messages and whether
+// the bot hides guest names. If the bot cannot be loaded an error is returned
+// rather than falling back to forwarding, which would show the guest's name.
+func (f *Forwarder) guestHeader(botID uuid.UUID, guest *models.Guest) (string, bool, error) {
+	bot, err := f.botRepo.GetByID(botID)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get bot: %w", err)
+	}
+	if !bot.PrivacyMode {
+		return "", false, nil
+	}
+	return bot.GuestHeader(guest), true, nil
+}
+
+// sendGuestMessage sends a guest message to a recipient chat, in the topic
+// threadID if it is not 0, and returns the ID of the recipient's copy. Messages
+// are forwarded, or copied under header when the bot hides guest names, since
+// forwards show who sent them.
+func (f *Forwarder) sendGuestMessage(bot *gotgbot.Bot, recipientChatID int64, threadID int64, message *gotgbot.Message, header string, private bool) (int64, error) {
+	if private {
+		return f.copyMessage(bot, recipientChatID, threadID, message, header)
+	}
+	forwarded, err := bot.ForwardMessage(recipientChatID, message.Chat.Id, message.MessageId, &gotgbot.ForwardMessageOpts{
+		MessageThreadId: threadID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to forward message: %w", err)
+	}
+	return forwarded.MessageId, nil
+}
+
+// location returns the timezone the bot shows times in
+func (f *Forwarder) location(botID uuid.UUID) *time.Location {
+	bot, err := f.botRepo.GetByID(botID)
+	if err != nil {
+		return time.Local
+	}
+	return bot.Location()
+}
+
 func (f *Forwarder) sendFailureNotification(
 	_ context.Context,
 	bot *gotgbot.Bot,
+	botID uuid.UUID,
 	recipientChatID int64,
 	err error,
 	retryAttempts int,
@@ -406,7 +715,7 @@ func (f *Forwarder) sendFailureNotification(
 			"Error: `%s`\n"+
 			"Retry Attempts: %d\n"+
 			"Time: %s",
-		utils.EscapeMarkdown(fmt.Sprintf("%v", err)), retryAttempts, time.Now().Format("2006-01-02 15:04:05"),
+		utils.EscapeMarkdown(fmt.Sprintf("%v", err)), retryAttempts, utils.FormatTimestamp(time.Now(), f.location(botID)),
 	)
 
 	_, sendErr := bot.SendMessage(recipientChatID, message, &gotgbot.SendMessageOpts{
@@ -430,21 +739,22 @@ func (f *Forwarder) ForwardReplyToGuest(
 		return fmt.Errorf("message is not a reply")
 	}
 
-	recipientMessageID := replyMessage.ReplyToMessage.MessageId
-
-	mapping, err := f.messageMappingRepo.GetByRecipientMessage(
-		botID, recipientChatID, recipientMessageID)
+	guestChatID, err := f.replyTarget(botID, recipientChatID, replyMessage)
 	if err != nil {
-		return fmt.Errorf("failed to find message mapping: %w", err)
+		return err
 	}
 
-	if !f.rateLimiter.AllowTelegramAPI(ctx) {
+	if err := f.ConsumeQuota(ctx, botID); err != nil {
+		return err
+	}
+
+	if !f.rateLimiter.AllowBotTelegramAPI(ctx, botID) {
 		return fmt.Errorf("rate limit exceeded")
 	}
 
-	return f.retryHandler.Retry(ctx, func() error {
+	err = f.retryHandler.Retry(ctx, func() error {
 		forwardedMsg, err := bot.ForwardMessage(
-			mapping.GuestChatID,
+			guestChatID,
 			recipientChatID,
 			replyMessage.MessageId,
 			nil)
@@ -457,7 +767,7 @@ func (f *Forwarder) ForwardReplyToGuest(
 		// the mapping using the message ID that bot sent to guest
 		replyMapping := &models.MessageMapping{
 			BotID:              botID,
-			GuestChatID:        mapping.GuestChatID,
+			GuestChatID:        guestChatID,
 			GuestMessageID:     forwardedMsg.MessageId, // Use the message ID that bot sent to guest
 			RecipientChatID:    recipientChatID,
 			RecipientMessageID: replyMessage.MessageId,
@@ -466,7 +776,7 @@ func (f *Forwarder) ForwardReplyToGuest(
 
 		f.logger.Debug("Creating reply mapping for recipient reply to guest",
 			zap.String("bot_id", botID.String()),
-			zap.Int64("guest_chat_id", mapping.GuestChatID),
+			zap.Int64("guest_chat_id", guestChatID),
 			zap.Int64("guest_message_id", forwardedMsg.MessageId),
 			zap.Int64("recipient_chat_id", recipientChatID),
 			zap.Int64("recipient_message_id", replyMessage.MessageId))
@@ -484,6 +794,74 @@ func (f *Forwarder) ForwardReplyToGuest(
 
 		return nil
 	})
+	if err != nil {
+		if ClassifyError(err) == FailureReasonForbidden {
+			f.setGuestBlocked(botID, guestChatID, true)
+		}
+		return err
+	}
+
+	if err := f.guestRepo.MarkReplied(botID, guestChatID, time.Now()); err != nil {
+		f.logger.Warn("Failed to record conversation status",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Error(err))
+	}
+
+	if f.hooks != nil {
+		f.hooks.ReplyForwarded(ctx, botID, guestChatID, recipientChatID, replyMessage)
+	}
+	return nil
+}
+
+// replyTarget returns the chat of the guest a staff reply is for: the guest
+// whose message was replied to or, for a message written in a guest's topic
+// without replying to anything in it, the topic's guest
+func (f *Forwarder) replyTarget(botID uuid.UUID, recipientChatID int64, replyMessage *gotgbot.Message) (int64, error) {
+	mapping, err := f.messageMappingRepo.GetByRecipientMessage(
+		botID, recipientChatID, replyMessage.ReplyToMessage.MessageId)
+	if err == nil {
+		return mapping.GuestChatID, nil
+	}
+	// Messages in a topic reply to the message that created it
+	if replyMessage.IsTopicMessage && replyMessage.ReplyToMessage.MessageId == replyMessage.MessageThreadId {
+		if topic, topicErr := f.guestTopicRepo.GetByThread(botID, recipientChatID, replyMessage.MessageThreadId); topicErr == nil {
+			return topic.Guest.GuestUserID, nil
+		}
+	}
+	return 0, fmt.Errorf("failed to find message mapping: %w", err)
+}
+
+// recordInboundMessage counts a guest message once for statistics, regardless
+// of how many recipient copies were created
+func (f *Forwarder) recordInboundMessage(botID uuid.UUID, guestChatID int64, guestMessageID int64, recipientCount int, deliveredCount int) {
+	inbound := &models.InboundMessage{
+		BotID:          botID,
+		GuestChatID:    guestChatID,
+		GuestMessageID: guestMessageID,
+		RecipientCount: recipientCount,
+		DeliveredCount: deliveredCount,
+	}
+	if err := f.inboundMessageRepo.Record(inbound); err != nil {
+		f.logger.Warn("Failed to record inbound message",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("guest_message_id", guestMessageID),
+			zap.Error(err))
+	}
+}
+
+// markAwaitingReply records that the guest is waiting for a reply and reports
+// whether this started a new wait
+func (f *Forwarder) markAwaitingReply(botID uuid.UUID, guestChatID int64) bool {
+	started, err := f.guestRepo.MarkAwaitingReply(botID, guestChatID, time.Now())
+	if err != nil {
+		f.logger.Warn("Failed to record conversation status",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Error(err))
+		return false
+	}
+	return started
 }
 
 // ForwardGuestReplyToRecipient forwards a guest's reply message to a specific recipient
@@ -492,20 +870,43 @@ func (f *Forwarder) ForwardGuestReplyToRecipient(
 	bot *gotgbot.Bot,
 	botID uuid.UUID,
 	guestChatID int64,
-	guestReplyMessageID int64,
+	guestReply *gotgbot.Message,
 	guestReplyToMessageID int64,
 	recipientChatID int64,
 ) error {
-	if !f.rateLimiter.AllowTelegramAPI(ctx) {
+	guestReplyMessageID := guestReply.MessageId
+
+	guest, err := f.guestRepo.GetOrCreateByBotIDAndUserID(botID, guestChatID)
+	if err != nil {
+		return fmt.Errorf("failed to get or create guest: %w", err)
+	}
+	header, private, err := f.guestHeader(botID, guest)
+	if err != nil {
+		return err
+	}
+	recipient, err := f.recipientRepo.GetByBotIDAndChatID(botID, recipientChatID)
+	if err != nil {
+		return fmt.Errorf("failed to get recipient: %w", err)
+	}
+
+	// Like other guest messages, replies are only logged in dry-run mode
+	if f.IsDryRun(botID) {
+		f.logger.Info("Dry run: guest reply not delivered",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Int64("message_id", guestReplyMessageID),
+			zap.Int64("recipient_chat_id", recipient.ChatID),
+			zap.String("recipient_type", string(recipient.RecipientType)),
+			zap.String("media_type", MediaType(guestReply)))
+		return nil
+	}
+
+	if !f.rateLimiter.AllowBotTelegramAPI(ctx, botID) {
 		return fmt.Errorf("rate limit exceeded")
 	}
 
-	return f.retryHandler.Retry(ctx, func() error {
-		forwardedMsg, err := bot.ForwardMessage(
-			recipientChatID,
-			guestChatID,
-			guestReplyMessageID,
-			nil)
+	err = f.retryHandler.Retry(ctx, func() error {
+		forwardedMessageID, err := f.sendToGuestTopic(bot, botID, guest, recipient, guestReply, header, private)
 		if err != nil {
 			return fmt.Errorf("failed to forward guest reply: %w", err)
 		}
@@ -517,7 +918,7 @@ func (f *Forwarder) ForwardGuestReplyToRecipient(
 			GuestChatID:        guestChatID,
 			GuestMessageID:     guestReplyMessageID, // Guest's reply message ID
 			RecipientChatID:    recipientChatID,
-			RecipientMessageID: forwardedMsg.MessageId, // Bot's message ID sent to recipient
+			RecipientMessageID: forwardedMessageID, // Bot's message ID sent to recipient
 			Direction:          models.MessageDirectionInbound,
 		}
 
@@ -526,7 +927,7 @@ func (f *Forwarder) ForwardGuestReplyToRecipient(
 			zap.Int64("guest_chat_id", guestChatID),
 			zap.Int64("guest_message_id", guestReplyMessageID),
 			zap.Int64("recipient_chat_id", recipientChatID),
-			zap.Int64("recipient_message_id", forwardedMsg.MessageId))
+			zap.Int64("recipient_message_id", forwardedMessageID))
 
 		if err := f.messageMappingRepo.Create(replyMapping); err != nil {
 			f.logger.Warn("Failed to create reply mapping",
@@ -536,9 +937,16 @@ func (f *Forwarder) ForwardGuestReplyToRecipient(
 			f.logger.Debug("Reply mapping created successfully",
 				zap.String("bot_id", botID.String()),
 				zap.Int64("guest_message_id", guestReplyMessageID),
-				zap.Int64("recipient_message_id", forwardedMsg.MessageId))
+				zap.Int64("recipient_message_id", forwardedMessageID))
 		}
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	f.recordInboundMessage(botID, guestChatID, guestReplyMessageID, 1, 1)
+	f.markAwaitingReply(botID, guestChatID)
+	return nil
 }
@@ -0,0 +1,104 @@
+package message
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestSkipGuestChat(t *testing.T) {
+	f := &Forwarder{logger: zap.NewNop()}
+	manager := &models.Recipient{ChatID: 42}
+	group := &models.Recipient{ChatID: -100}
+	recipients := []*models.Recipient{manager, group}
+
+	kept := f.skipGuestChat(uuid.New(), 42, recipients)
+	if len(kept) != 1 || kept[0] != group {
+		t.Fatalf("skipGuestChat left %d recipients, want only the group", len(kept))
+	}
+	if recipients[0] != manager {
+		t.Fatal("skipGuestChat modified the recipients it was given")
+	}
+
+	if kept := f.skipGuestChat(uuid.New(), 7, recipients); len(kept) != 2 {
+		t.Fatalf("skipGuestChat left %d recipients for an unrelated guest, want 2", len(kept))
+	}
+}
+
+func TestSenderID(t *testing.T) {
+	private := &gotgbot.Message{Chat: gotgbot.Chat{Id: 42, Type: "private"}, From: &gotgbot.User{Id: 42}}
+	if got := senderID(private); got != 42 {
+		t.Fatalf("senderID of a private message = %d, want 42", got)
+	}
+
+	group := &gotgbot.Message{Chat: gotgbot.Chat{Id: -100, Type: "supergroup"}, From: &gotgbot.User{Id: 7}}
+	if got := senderID(group); got != 7 {
+		t.Fatalf("senderID of a group message = %d, want the member 7", got)
+	}
+
+	anonymous := &gotgbot.Message{
+		Chat:       gotgbot.Chat{Id: -100, Type: "supergroup"},
+		From:       &gotgbot.User{Id: 1087968824},
+		SenderChat: &gotgbot.Chat{Id: -100, Type: "supergroup"},
+	}
+	if got := senderID(anonymous); got != -100 {
+		t.Fatalf("senderID of an anonymous admin message = %d, want the chat -100", got)
+	}
+}
+
+func TestDryRunDeliversNothing(t *testing.T) {
+	f := &Forwarder{logger: zap.NewNop()}
+	recipients := []*models.Recipient{
+		{ChatID: 42, RecipientType: models.RecipientTypeUser},
+		{ChatID: -100, RecipientType: models.RecipientTypeGroup},
+	}
+
+	result := f.dryRun(context.Background(), uuid.New(), 7, &gotgbot.Message{MessageId: 1, Text: "hi"}, recipients)
+	if !result.DryRun || result.SuccessCount != 0 || result.FailureCount != 0 {
+		t.Fatalf("dry run result = %+v, want nothing delivered or failed", result)
+	}
+	if len(result.Outcomes) != 2 {
+		t.Fatalf("dry run has %d outcomes, want one per recipient", len(result.Outcomes))
+	}
+	for _, outcome := range result.Outcomes {
+		if outcome.Status != DeliveryStatusDryRun {
+			t.Errorf("outcome for %d has status %q, want %q", outcome.ChatID, outcome.Status, DeliveryStatusDryRun)
+		}
+	}
+	if failed := result.Failed(); len(failed) != 0 {
+		t.Fatalf("dry run reports %d failed recipients, want none so nothing is queued", len(failed))
+	}
+}
+
+type fixedProfileCards string
+
+func (c fixedProfileCards) ProfileCard(context.Context, uuid.UUID, *models.Guest, *gotgbot.Message, bool) string {
+	return string(c)
+}
+
+func TestProfileCardOnlyStartsConversations(t *testing.T) {
+	f := &Forwarder{logger: zap.NewNop()}
+	guest := &models.Guest{GuestUserID: 7}
+	msg := &gotgbot.Message{MessageId: 1, Text: "hi"}
+
+	if card := f.profileCard(context.Background(), uuid.New(), guest, msg, false); card != "" {
+		t.Fatalf("card without profile cards set = %q, want none", card)
+	}
+
+	f.SetProfileCards(fixedProfileCards("card"))
+	if card := f.profileCard(context.Background(), uuid.New(), guest, msg, false); card != "card" {
+		t.Fatalf("card for a new conversation = %q, want %q", card, "card")
+	}
+
+	waitingSince := time.Now()
+	guest.AwaitingReplySince = &waitingSince
+	if card := f.profileCard(context.Background(), uuid.New(), guest, msg, false); card != "" {
+		t.Fatalf("card while the guest awaits a reply = %q, want none", card)
+	}
+}
@@ -0,0 +1,256 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// loopDetectorSweepSize is how many chat pairs may be tracked before pairs
+// without recent forwards are forgotten
+const loopDetectorSweepSize = 1000
+
+// chatPair is a forward from one chat to another
+type chatPair struct {
+	from int64
+	to   int64
+}
+
+// LoopDetector finds forwarding loops between group chats, where messages
+// forwarded from one chat to another come straight back, e.g. because two
+// ForwarderBots each have the other's guest group as a recipient, or another
+// bot relays them. Once forwards in both directions reach the threshold within
+// the window, forwarding between the two chats is paused. Private chats are
+// left out, since only people write in them.
+//
+// One detector is shared by all bots, as loops usually span more than one. It
+// is kept in memory, so each instance detects the loops it forwards.
+type LoopDetector struct {
+	threshold int
+	window    time.Duration
+	pause     time.Duration
+
+	mu       sync.Mutex
+	forwards map[chatPair][]time.Time // Recent forwards per direction
+	paused   map[chatPair]time.Time   // End of the pause per pair of chats, lower chat ID first
+}
+
+// LoopCheck is the outcome of LoopDetector.Forward
+type LoopCheck struct {
+	Paused      bool      // The forward belongs to a loop and must not be made
+	Detected    bool      // This forward revealed the loop, so it should be reported
+	PausedUntil time.Time // When forwarding between the two chats resumes
+}
+
+func NewLoopDetector(cfg *config.Config) *LoopDetector {
+	return &LoopDetector{
+		threshold: cfg.LoopDetection.Threshold,
+		window:    time.Duration(cfg.LoopDetection.WindowSeconds) * time.Second,
+		pause:     time.Duration(cfg.LoopDetection.PauseSeconds) * time.Second,
+		forwards:  make(map[chatPair][]time.Time),
+		paused:    make(map[chatPair]time.Time),
+	}
+}
+
+// Forward records a forward from one chat to another and reports whether it
+// may be made
+func (d *LoopDetector) Forward(from, to int64, now time.Time) LoopCheck {
+	// Group and channel IDs are negative
+	if d.threshold <= 0 || from >= 0 || to >= 0 || from == to {
+		return LoopCheck{}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pair := chatPair{from: from, to: to}
+	if to < from {
+		pair = chatPair{from: to, to: from}
+	}
+	if until, ok := d.paused[pair]; ok {
+		if now.Before(until) {
+			return LoopCheck{Paused: true, PausedUntil: until}
+		}
+		delete(d.paused, pair)
+	}
+
+	if len(d.forwards) > loopDetectorSweepSize {
+		d.sweep(now)
+	}
+
+	there := chatPair{from: from, to: to}
+	back := chatPair{from: to, to: from}
+	d.forwards[there] = append(d.recent(there, now), now)
+	d.forwards[back] = d.recent(back, now)
+	if len(d.forwards[back]) == 0 {
+		delete(d.forwards, back)
+	}
+	if len(d.forwards[there]) < d.threshold || len(d.forwards[back]) < d.threshold {
+		return LoopCheck{}
+	}
+
+	until := now.Add(d.pause)
+	d.paused[pair] = until
+	delete(d.forwards, there)
+	delete(d.forwards, back)
+	return LoopCheck{Paused: true, Detected: true, PausedUntil: until}
+}
+
+// recent returns the forwards in one direction within the window
+func (d *LoopDetector) recent(direction chatPair, now time.Time) []time.Time {
+	kept := d.forwards[direction][:0]
+	for _, at := range d.forwards[direction] {
+		if now.Sub(at) < d.window {
+			kept = append(kept, at)
+		}
+	}
+	return kept
+}
+
+// sweep forgets directions without forwards within the window and ended pauses
+func (d *LoopDetector) sweep(now time.Time) {
+	for direction, forwards := range d.forwards {
+		if len(forwards) == 0 || now.Sub(forwards[len(forwards)-1]) >= d.window {
+			delete(d.forwards, direction)
+		}
+	}
+	for pair, until := range d.paused {
+		if !now.Before(until) {
+			delete(d.paused, pair)
+		}
+	}
+}
+
+// savedChatPair is a chat pair with its recent forwards or the end of its pause
+type savedChatPair struct {
+	From     int64       `json:"from"`
+	To       int64       `json:"to"`
+	Forwards []time.Time `json:"forwards,omitempty"`
+	Until    time.Time   `json:"until,omitempty"`
+}
+
+// loopDetectorState is the saved state of a LoopDetector
+type loopDetectorState struct {
+	Forwards []savedChatPair `json:"forwards"`
+	Paused   []savedChatPair `json:"paused"`
+}
+
+// Snapshot encodes the recent forwards and the pauses, so a restart neither
+// lifts a pause nor forgets a loop that is building up
+func (d *LoopDetector) Snapshot() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sweep(time.Now())
+	var state loopDetectorState
+	for direction, forwards := range d.forwards {
+		state.Forwards = append(state.Forwards, savedChatPair{From: direction.from, To: direction.to, Forwards: forwards})
+	}
+	for pair, until := range d.paused {
+		state.Paused = append(state.Paused, savedChatPair{From: pair.from, To: pair.to, Until: until})
+	}
+	return json.Marshal(state)
+}
+
+// Restore merges saved state into the detector, keeping the later end of a
+// pause. Pauses that have ended and forwards outside the window are left out.
+func (d *LoopDetector) Restore(data []byte) error {
+	var state loopDetectorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to decode loop detector state: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for _, saved := range state.Paused {
+		pair := chatPair{from: saved.From, to: saved.To}
+		if now.Before(saved.Until) && saved.Until.After(d.paused[pair]) {
+			d.paused[pair] = saved.Until
+		}
+	}
+	for _, saved := range state.Forwards {
+		direction := chatPair{from: saved.From, to: saved.To}
+		if _, ok := d.forwards[direction]; ok {
+			continue // Forwards seen since the start are newer
+		}
+		for _, at := range saved.Forwards {
+			if now.Sub(at) < d.window {
+				d.forwards[direction] = append(d.forwards[direction], at)
+			}
+		}
+	}
+	return nil
+}
+
+// SetLoopDetector makes the forwarder stop forwarding between group chats
+// that forward to each other in a loop
+func (f *Forwarder) SetLoopDetector(detector *LoopDetector) {
+	f.loopDetector = detector
+}
+
+// skipLooping leaves out the recipients the guest chat is in a forwarding loop
+// with, and tells the manager when a loop is found
+func (f *Forwarder) skipLooping(ctx context.Context, botID uuid.UUID, guestChatID int64, recipients []*models.Recipient) []*models.Recipient {
+	if f.loopDetector == nil {
+		return recipients
+	}
+
+	now := time.Now()
+	kept := make([]*models.Recipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		check := f.loopDetector.Forward(guestChatID, recipient.ChatID, now)
+		if !check.Paused {
+			kept = append(kept, recipient)
+			continue
+		}
+		f.logger.Debug("Not forwarding within a forwarding loop",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("guest_chat_id", guestChatID),
+			zap.Int64("recipient_chat_id", recipient.ChatID),
+			zap.Time("paused_until", check.PausedUntil))
+		if check.Detected {
+			f.notifyLoop(ctx, botID, guestChatID, recipient.ChatID, check.PausedUntil)
+		}
+	}
+	return kept
+}
+
+func (f *Forwarder) notifyLoop(ctx context.Context, botID uuid.UUID, guestChatID int64, recipientChatID int64, pausedUntil time.Time) {
+	f.logger.Warn("Forwarding loop detected",
+		zap.String("bot_id", botID.String()),
+		zap.Int64("guest_chat_id", guestChatID),
+		zap.Int64("recipient_chat_id", recipientChatID),
+		zap.Time("paused_until", pausedUntil))
+	if f.managerNotifier == nil {
+		return
+	}
+
+	notificationMsg := fmt.Sprintf(
+		"*Forwarding Loop Detected*\n\n"+
+			"Bot ID: `%s`\n"+
+			"Messages are going back and forth between the guest chat `%d` and the recipient `%d`, "+
+			"probably because the recipient is also forwarded by another bot, or one of them forwards back to the other.\n\n"+
+			"Forwarding between the two chats is paused until %s. "+
+			"Remove the recipient, or stop forwarding messages from that group, to end the loop for good.",
+		botID.String(),
+		guestChatID,
+		recipientChatID,
+		utils.FormatTimestamp(pausedUntil, f.location(botID)),
+	)
+	if err := f.managerNotifier.NotifyManager(ctx, botID, notificationMsg); err != nil {
+		f.logger.Warn("Failed to notify manager about forwarding loop",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+	}
+}
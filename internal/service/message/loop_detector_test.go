@@ -0,0 +1,122 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+)
+
+func newTestLoopDetector(threshold int) *LoopDetector {
+	return NewLoopDetector(&config.Config{
+		LoopDetection: config.LoopDetectionConfig{
+			Threshold:     threshold,
+			WindowSeconds: 60,
+			PauseSeconds:  600,
+		},
+	})
+}
+
+func TestLoopDetectorFindsPingPong(t *testing.T) {
+	detector := newTestLoopDetector(3)
+	groupA, groupB := int64(-100), int64(-200)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if check := detector.Forward(groupA, groupB, now); check.Paused {
+			t.Fatalf("forward %d A->B paused before the threshold", i)
+		}
+		if check := detector.Forward(groupB, groupA, now); check.Paused {
+			t.Fatalf("forward %d B->A paused before the threshold", i)
+		}
+	}
+	if check := detector.Forward(groupA, groupB, now); check.Paused {
+		t.Fatal("third A->B forward paused while B->A is below the threshold")
+	}
+
+	check := detector.Forward(groupB, groupA, now)
+	if !check.Paused || !check.Detected {
+		t.Fatalf("third B->A forward = %+v, want a detected loop", check)
+	}
+	if want := now.Add(600 * time.Second); !check.PausedUntil.Equal(want) {
+		t.Fatalf("paused until %s, want %s", check.PausedUntil, want)
+	}
+
+	// Both directions stay paused, and the loop is reported only once
+	if check := detector.Forward(groupA, groupB, now.Add(time.Minute)); !check.Paused || check.Detected {
+		t.Fatalf("A->B during the pause = %+v, want paused without a new report", check)
+	}
+	if check := detector.Forward(groupA, groupB, now.Add(601*time.Second)); check.Paused {
+		t.Fatal("A->B still paused after the pause ended")
+	}
+}
+
+func TestLoopDetectorIgnoresOneWayAndSlowTraffic(t *testing.T) {
+	detector := newTestLoopDetector(3)
+	groupA, groupB := int64(-100), int64(-200)
+	now := time.Now()
+
+	// Busy traffic in one direction is not a loop
+	for i := 0; i < 10; i++ {
+		if check := detector.Forward(groupA, groupB, now); check.Paused {
+			t.Fatal("one-way forwards paused")
+		}
+	}
+
+	// Forwards back spread out beyond the window are not a loop either
+	slow := newTestLoopDetector(3)
+	for i := 0; i < 10; i++ {
+		at := now.Add(time.Duration(i) * time.Minute)
+		if slow.Forward(groupA, groupB, at).Paused || slow.Forward(groupB, groupA, at.Add(30*time.Second)).Paused {
+			t.Fatal("forwards spread over several windows paused")
+		}
+	}
+}
+
+func TestLoopDetectorSkipsPrivateChatsAndDisabled(t *testing.T) {
+	now := time.Now()
+
+	detector := newTestLoopDetector(1)
+	// Private chats have positive IDs; people write in them, not bots
+	detector.Forward(42, -200, now)
+	if check := detector.Forward(-200, 42, now); check.Paused {
+		t.Fatal("forwards with a private chat paused")
+	}
+
+	disabled := newTestLoopDetector(0)
+	disabled.Forward(-100, -200, now)
+	if check := disabled.Forward(-200, -100, now); check.Paused {
+		t.Fatal("disabled detector paused a forward")
+	}
+}
+
+func TestLoopDetectorSurvivesRestart(t *testing.T) {
+	detector := newTestLoopDetector(2)
+	groupA, groupB, groupC := int64(-100), int64(-200), int64(-300)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		detector.Forward(groupA, groupB, now)
+		detector.Forward(groupB, groupA, now)
+	}
+	// A loop between A and C is building up
+	detector.Forward(groupA, groupC, now)
+	detector.Forward(groupC, groupA, now)
+
+	data, err := detector.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	restarted := newTestLoopDetector(2)
+	if err := restarted.Restore(data); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	if check := restarted.Forward(groupB, groupA, now.Add(time.Minute)); !check.Paused || check.Detected {
+		t.Fatalf("B->A after a restart = %+v, want still paused", check)
+	}
+	restarted.Forward(groupA, groupC, now.Add(time.Second))
+	if check := restarted.Forward(groupC, groupA, now.Add(time.Second)); !check.Paused || !check.Detected {
+		t.Fatalf("C->A after a restart = %+v, want the loop detected", check)
+	}
+}
@@ -0,0 +1,128 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+)
+
+// DeliveryStatus is the final status of a delivery to a single recipient
+type DeliveryStatus string
+
+const (
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+	DeliveryStatusSkipped   DeliveryStatus = "skipped" // Not attempted, e.g. rate limited
+	DeliveryStatusDryRun    DeliveryStatus = "dry_run" // Not sent because the bot is in dry-run mode
+)
+
+// FailureReason classifies why a delivery failed
+type FailureReason string
+
+const (
+	FailureReasonNone         FailureReason = ""
+	FailureReasonRateLimited  FailureReason = "rate_limited"   // Local limiter or Telegram 429
+	FailureReasonUnauthorized FailureReason = "unauthorized"   // Bot token revoked (401)
+	FailureReasonForbidden    FailureReason = "forbidden"      // Bot blocked or removed from chat (403)
+	FailureReasonChatNotFound FailureReason = "chat_not_found" // Chat deleted or never existed
+	FailureReasonBadRequest   FailureReason = "bad_request"    // Other 400 errors
+	FailureReasonServerError  FailureReason = "server_error"   // Telegram 5xx
+	FailureReasonNetwork      FailureReason = "network"        // Timeouts and connection errors
+	FailureReasonCancelled    FailureReason = "cancelled"      // Context cancelled during delivery
+	FailureReasonUnknown      FailureReason = "unknown"
+)
+
+// Transient reports whether a delivery that failed for this reason may succeed
+// later without anyone changing anything, so it is worth retrying from the queue
+func (r FailureReason) Transient() bool {
+	switch r {
+	case FailureReasonForbidden, FailureReasonChatNotFound, FailureReasonBadRequest:
+		return false
+	}
+	return true
+}
+
+// RecipientOutcome records what happened when forwarding to one recipient
+type RecipientOutcome struct {
+	RecipientID   uuid.UUID
+	ChatID        int64
+	RecipientType models.RecipientType
+	Attempts      int
+	Status        DeliveryStatus
+	Reason        FailureReason
+	Err           error
+}
+
+// Failed returns the outcomes of recipients that did not receive the message
+func (r *ForwardResult) Failed() []RecipientOutcome {
+	failed := make([]RecipientOutcome, 0, r.FailureCount)
+	for _, outcome := range r.Outcomes {
+		if outcome.Status != DeliveryStatusDelivered && outcome.Status != DeliveryStatusDryRun {
+			failed = append(failed, outcome)
+		}
+	}
+	return failed
+}
+
+// AllFailed reports whether there were recipients and none of them received the message
+func (r *ForwardResult) AllFailed() bool {
+	return r.FailureCount > 0 && r.SuccessCount == 0
+}
+
+// ClassifyError maps a delivery error to a FailureReason
+func ClassifyError(err error) FailureReason {
+	if err == nil {
+		return FailureReasonNone
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return FailureReasonCancelled
+	}
+
+	var tgErr *gotgbot.TelegramError
+	if errors.As(err, &tgErr) {
+		switch {
+		case tgErr.Code == 429:
+			return FailureReasonRateLimited
+		case tgErr.Code == 401:
+			return FailureReasonUnauthorized
+		case tgErr.Code == 403:
+			return FailureReasonForbidden
+		case tgErr.Code == 400 && strings.Contains(strings.ToLower(tgErr.Description), "chat not found"):
+			return FailureReasonChatNotFound
+		case tgErr.Code == 400:
+			return FailureReasonBadRequest
+		case tgErr.Code >= 500:
+			return FailureReasonServerError
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return FailureReasonNetwork
+	}
+
+	// Fall back to matching the error text for wrapped or non-Telegram errors
+	errStr := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(errStr, "rate limit") || strings.Contains(errStr, "429") || strings.Contains(errStr, "too many requests"):
+		return FailureReasonRateLimited
+	case strings.Contains(errStr, "401") || strings.Contains(errStr, "unauthorized"):
+		return FailureReasonUnauthorized
+	case strings.Contains(errStr, "403") || strings.Contains(errStr, "forbidden") || strings.Contains(errStr, "bot was blocked"):
+		return FailureReasonForbidden
+	case strings.Contains(errStr, "chat not found"):
+		return FailureReasonChatNotFound
+	case strings.Contains(errStr, "500") || strings.Contains(errStr, "502") ||
+		strings.Contains(errStr, "503") || strings.Contains(errStr, "504"):
+		return FailureReasonServerError
+	}
+
+	return FailureReasonUnknown
+}
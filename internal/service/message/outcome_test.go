@@ -0,0 +1,81 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want FailureReason
+	}{
+		{"nil", nil, FailureReasonNone},
+		{"telegram 429", &gotgbot.TelegramError{Code: 429, Description: "Too Many Requests: retry after 5"}, FailureReasonRateLimited},
+		{"telegram 401", &gotgbot.TelegramError{Code: 401, Description: "Unauthorized"}, FailureReasonUnauthorized},
+		{"telegram 403", &gotgbot.TelegramError{Code: 403, Description: "Forbidden: bot was blocked by the user"}, FailureReasonForbidden},
+		{"telegram chat not found", &gotgbot.TelegramError{Code: 400, Description: "Bad Request: chat not found"}, FailureReasonChatNotFound},
+		{"telegram other 400", &gotgbot.TelegramError{Code: 400, Description: "Bad Request: message to copy not found"}, FailureReasonBadRequest},
+		{"telegram 502", &gotgbot.TelegramError{Code: 502, Description: "Bad Gateway"}, FailureReasonServerError},
+		{"wrapped telegram error", fmt.Errorf("max retries exceeded: %w", &gotgbot.TelegramError{Code: 403}), FailureReasonForbidden},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, FailureReasonNetwork},
+		{"cancelled", fmt.Errorf("forward: %w", context.Canceled), FailureReasonCancelled},
+		{"local rate limit", errors.New("rate limit exceeded"), FailureReasonRateLimited},
+		{"text unauthorized", errors.New("unable to forwardMessage: Unauthorized"), FailureReasonUnauthorized},
+		{"unknown", errors.New("something odd"), FailureReasonUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Fatalf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForwardResult_Failed(t *testing.T) {
+	result := &ForwardResult{
+		SuccessCount: 1,
+		FailureCount: 2,
+		Outcomes: []RecipientOutcome{
+			{ChatID: 1, Status: DeliveryStatusDelivered},
+			{ChatID: 2, Status: DeliveryStatusFailed, Reason: FailureReasonForbidden},
+			{ChatID: 3, Status: DeliveryStatusSkipped, Reason: FailureReasonRateLimited},
+		},
+	}
+
+	failed := result.Failed()
+	if len(failed) != 2 {
+		t.Fatalf("Expected 2 failed outcomes, got %d", len(failed))
+	}
+	if failed[0].ChatID != 2 || failed[1].ChatID != 3 {
+		t.Fatalf("Unexpected failed outcomes: %+v", failed)
+	}
+	if result.AllFailed() {
+		t.Fatal("AllFailed should be false when a recipient succeeded")
+	}
+}
+
+func TestFailureReason_Transient(t *testing.T) {
+	transient := []FailureReason{FailureReasonRateLimited, FailureReasonUnauthorized, FailureReasonServerError,
+		FailureReasonNetwork, FailureReasonCancelled, FailureReasonUnknown}
+	for _, reason := range transient {
+		if !reason.Transient() {
+			t.Errorf("%q should be transient", reason)
+		}
+	}
+	// The recipient has to change something before these can succeed
+	permanent := []FailureReason{FailureReasonForbidden, FailureReasonChatNotFound, FailureReasonBadRequest}
+	for _, reason := range permanent {
+		if reason.Transient() {
+			t.Errorf("%q should not be transient", reason)
+		}
+	}
+}
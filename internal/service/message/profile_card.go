@@ -0,0 +1,47 @@
+package message
+
+import (
+	"context"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ProfileCardsInterface describes a guest to recipients at the start of a conversation
+type ProfileCardsInterface interface {
+	// ProfileCard returns the card to send before the guest's message, or ""
+	// when the bot does not send cards. private is true when the bot hides
+	// guest names, so the card must not reveal who the guest is.
+	ProfileCard(ctx context.Context, botID uuid.UUID, guest *models.Guest, message *gotgbot.Message, private bool) string
+}
+
+// SetProfileCards makes the forwarder send a profile card to recipients before
+// the first message of each conversation
+func (f *Forwarder) SetProfileCards(cards ProfileCardsInterface) {
+	f.profileCards = cards
+}
+
+// profileCard returns the card to send before the guest's message, or "" when
+// the guest is already waiting for a reply, i.e. the conversation is ongoing
+func (f *Forwarder) profileCard(ctx context.Context, botID uuid.UUID, guest *models.Guest, message *gotgbot.Message, private bool) string {
+	if f.profileCards == nil || guest.AwaitingReplySince != nil {
+		return ""
+	}
+	return f.profileCards.ProfileCard(ctx, botID, guest, message, private)
+}
+
+// sendProfileCard sends the card to the recipient, in the guest's topic when
+// the recipient group uses them. The card is best effort: the guest's message
+// is forwarded whether or not it arrives.
+func (f *Forwarder) sendProfileCard(bot *gotgbot.Bot, botID uuid.UUID, guest *models.Guest, recipient *models.Recipient, message *gotgbot.Message, card string) {
+	threadID := f.guestThread(bot, botID, guest, recipient, message.From)
+	if _, err := bot.SendMessage(recipient.ChatID, card, &gotgbot.SendMessageOpts{MessageThreadId: threadID}); err != nil {
+		f.logger.Debug("Failed to send guest profile card",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("recipient_chat_id", recipient.ChatID),
+			zap.Error(err))
+	}
+}
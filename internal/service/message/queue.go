@@ -0,0 +1,285 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// queueBatchSize is the most queued deliveries of one bot tried per run
+	queueBatchSize = 50
+
+	// queueMaxBackoff caps the wait between attempts of a queued delivery
+	queueMaxBackoff = time.Hour
+)
+
+// errUndeliverable marks queued deliveries that can never succeed, so they are
+// dropped instead of retried
+var errUndeliverable = errors.New("undeliverable")
+
+// QueueRunResult summarizes one pass over a bot's retry queue
+type QueueRunResult struct {
+	Delivered int
+	Retrying  int // Failed again and rescheduled
+	Dropped   int // Given up on, either permanently failing or out of attempts
+	// DeadLettered is how many of the dropped deliveries were saved as dead letters
+	DeadLettered int
+}
+
+// SetDeliveryQueue makes the forwarder park deliveries that fail all retries
+// for a transient reason, instead of dropping them
+func (f *Forwarder) SetDeliveryQueue(queue repository.PendingDeliveryRepository) {
+	f.deliveryQueue = queue
+}
+
+// queueEnabled reports whether failed deliveries are parked
+func (f *Forwarder) queueEnabled() bool {
+	return f.deliveryQueue != nil && f.config.Retry.QueueMaxAttempts > 0
+}
+
+// queueBackoff returns the wait before the next attempt of a queued delivery
+// that has been attempted the given number of times. It starts at the given
+// interval and doubles after every attempt, up to queueMaxBackoff.
+func queueBackoff(interval time.Duration, attempts int) time.Duration {
+	backoff := interval
+	for i := 0; i < attempts && backoff < queueMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > queueMaxBackoff {
+		return queueMaxBackoff
+	}
+	return backoff
+}
+
+// queueInterval is the wait before the first attempt of a queued delivery:
+// the longest wait between immediate retries
+func (f *Forwarder) queueInterval() time.Duration {
+	return time.Duration(max(f.config.Retry.MaxIntervalSeconds, f.config.Retry.IntervalSeconds)) * time.Second
+}
+
+// parkFailed keeps the failures of a forwarded message from being lost:
+// transient failures go to the retry queue, the others to the dead letters.
+// It returns how many were queued and how many dead-lettered.
+func (f *Forwarder) parkFailed(botID uuid.UUID, guestChatID int64, message *gotgbot.Message, result *ForwardResult) (queued int, deadLettered int) {
+	if result.FailureCount == 0 || (!f.queueEnabled() && f.deadLetters == nil) {
+		return 0, 0
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		f.logger.Warn("Failed to encode failed message",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("message_id", message.MessageId),
+			zap.Error(err))
+		return 0, 0
+	}
+
+	nextAttempt := time.Now().Add(queueBackoff(f.queueInterval(), 0))
+	for _, outcome := range result.Failed() {
+		if f.queueEnabled() && outcome.Reason.Transient() {
+			delivery := &models.PendingDelivery{
+				BotID:          botID,
+				RecipientID:    outcome.RecipientID,
+				GuestChatID:    guestChatID,
+				GuestMessageID: message.MessageId,
+				Message:        string(data),
+				LastError:      outcome.Err.Error(),
+				NextAttemptAt:  nextAttempt,
+			}
+			_, err := f.deliveryQueue.Create(delivery)
+			if err == nil {
+				queued++
+				continue
+			}
+			// Rather a dead letter than a lost message
+			f.logger.Warn("Failed to queue delivery for retry",
+				zap.String("bot_id", botID.String()),
+				zap.Int64("message_id", message.MessageId),
+				zap.Int64("recipient_chat_id", outcome.ChatID),
+				zap.Error(err))
+		}
+		if f.deadLetter(&models.DeadLetter{
+			BotID:           botID,
+			RecipientID:     outcome.RecipientID,
+			RecipientChatID: outcome.ChatID,
+			GuestChatID:     guestChatID,
+			GuestMessageID:  message.MessageId,
+			Message:         string(data),
+			Reason:          string(outcome.Reason),
+			LastError:       outcome.Err.Error(),
+			Attempts:        outcome.Attempts,
+		}) {
+			deadLettered++
+		}
+	}
+	if queued > 0 {
+		f.logger.Info("Queued failed deliveries for retry",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("message_id", message.MessageId),
+			zap.Int("queued", queued))
+	}
+	return queued, deadLettered
+}
+
+// DeliverQueued tries the bot's queued deliveries that are due, or all of them
+// up to queueBatchSize if all is set. Delivered messages leave the queue; the
+// manager is told about the ones given up on.
+func (f *Forwarder) DeliverQueued(ctx context.Context, bot *gotgbot.Bot, botID uuid.UUID, all bool) (*QueueRunResult, error) {
+	result := &QueueRunResult{}
+	if f.deliveryQueue == nil {
+		return result, nil
+	}
+
+	var deliveries []*models.PendingDelivery
+	var err error
+	if all {
+		deliveries, err = f.deliveryQueue.GetByBotID(botID, queueBatchSize)
+	} else {
+		deliveries, err = f.deliveryQueue.GetDue(botID, time.Now(), queueBatchSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queued deliveries: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return result, nil
+	}
+
+	f.logger.Debug("Retrying queued deliveries",
+		zap.String("bot_id", botID.String()),
+		zap.Int("count", len(deliveries)),
+		zap.Bool("all", all))
+
+	dropped := make([]string, 0)
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+		// Leave the rest for the next run rather than waiting for the limiter
+		if !f.rateLimiter.AllowBotTelegramAPI(ctx, botID) {
+			break
+		}
+
+		err := f.deliverQueued(ctx, bot, botID, delivery)
+		if err == nil {
+			result.Delivered++
+			f.removeQueued(botID, delivery)
+			continue
+		}
+
+		delivery.Attempts++
+		delivery.LastError = err.Error()
+		reason := ClassifyError(err)
+		if errors.Is(err, errUndeliverable) || !reason.Transient() || delivery.Attempts >= f.config.Retry.QueueMaxAttempts {
+			result.Dropped++
+			f.logger.Warn("Dropping queued delivery",
+				zap.String("bot_id", botID.String()),
+				zap.Int64("guest_chat_id", delivery.GuestChatID),
+				zap.Int64("guest_message_id", delivery.GuestMessageID),
+				zap.Int("attempts", delivery.Attempts),
+				zap.String("reason", string(reason)),
+				zap.Error(err))
+			dropped = append(dropped, fmt.Sprintf("• guest `%d`, message %d: %s after %d queued attempt(s) - %s",
+				delivery.GuestChatID,
+				delivery.GuestMessageID,
+				utils.EscapeMarkdown(string(reason)),
+				delivery.Attempts,
+				utils.EscapeMarkdown(err.Error())))
+			// Undeliverable messages, e.g. for a removed recipient, are of no use to keep
+			if !errors.Is(err, errUndeliverable) && f.deadLetterQueued(delivery, reason) {
+				result.DeadLettered++
+			}
+			f.removeQueued(botID, delivery)
+			continue
+		}
+
+		result.Retrying++
+		delivery.NextAttemptAt = time.Now().Add(queueBackoff(f.queueInterval(), delivery.Attempts))
+		if err := f.deliveryQueue.Update(delivery); err != nil {
+			f.logger.Warn("Failed to reschedule queued delivery",
+				zap.String("bot_id", botID.String()),
+				zap.String("delivery_id", delivery.ID.String()),
+				zap.Error(err))
+		}
+		// A revoked token fails every delivery, so stop until it is replaced
+		if reason == FailureReasonUnauthorized {
+			break
+		}
+	}
+
+	if len(dropped) > 0 && f.managerNotifier != nil {
+		notificationMsg := fmt.Sprintf(
+			"*Queued Delivery Dropped*\n\n"+
+				"Bot ID: `%s`\n"+
+				"These messages could not be delivered and were removed from the retry queue:\n%s\n"+
+				"Saved as dead letters: %d\n"+
+				"Time: %s",
+			botID.String(),
+			strings.Join(dropped, "\n"),
+			result.DeadLettered,
+			utils.FormatTimestamp(time.Now(), f.location(botID)),
+		)
+		if notifyErr := f.managerNotifier.NotifyManager(ctx, botID, notificationMsg); notifyErr != nil {
+			f.logger.Warn("Failed to notify manager about dropped deliveries",
+				zap.String("bot_id", botID.String()),
+				zap.Error(notifyErr))
+		}
+	}
+
+	f.logger.Debug("Queued deliveries retried",
+		zap.String("bot_id", botID.String()),
+		zap.Int("delivered", result.Delivered),
+		zap.Int("retrying", result.Retrying),
+		zap.Int("dropped", result.Dropped),
+		zap.Int("dead_lettered", result.DeadLettered))
+	return result, nil
+}
+
+// deliverQueued makes one attempt at a queued delivery
+func (f *Forwarder) deliverQueued(ctx context.Context, bot *gotgbot.Bot, botID uuid.UUID, delivery *models.PendingDelivery) error {
+	recipient, err := f.recipientRepo.GetByID(delivery.RecipientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && recipient.BotID != botID) {
+		return fmt.Errorf("%w: recipient was removed", errUndeliverable)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get recipient: %w", err)
+	}
+	guest, err := f.guestRepo.GetOrCreateByBotIDAndUserID(botID, delivery.GuestChatID)
+	if err != nil {
+		return fmt.Errorf("failed to get or create guest: %w", err)
+	}
+	header, private, err := f.guestHeader(botID, guest)
+	if err != nil {
+		return err
+	}
+	var message gotgbot.Message
+	if err := json.Unmarshal([]byte(delivery.Message), &message); err != nil {
+		return fmt.Errorf("%w: failed to decode message: %v", errUndeliverable, err)
+	}
+
+	if err := f.forwardMessage(ctx, bot, botID, guest, &message, recipient, header, private); err != nil {
+		return err
+	}
+	f.markAwaitingReply(botID, delivery.GuestChatID)
+	return nil
+}
+
+func (f *Forwarder) removeQueued(botID uuid.UUID, delivery *models.PendingDelivery) {
+	if err := f.deliveryQueue.Delete(botID, delivery.ID); err != nil {
+		f.logger.Warn("Failed to remove queued delivery",
+			zap.String("bot_id", botID.String()),
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,100 @@
+package message
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+)
+
+func TestQueueBackoff(t *testing.T) {
+	interval := 30 * time.Second
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{3, 4 * time.Minute},
+		{7, queueMaxBackoff}, // 64m, capped at an hour
+		{100, queueMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := queueBackoff(interval, tt.attempts); got != tt.want {
+			t.Errorf("queueBackoff(%s, %d) = %s, want %s", interval, tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// fakeDeliveryQueue records queued deliveries
+type fakeDeliveryQueue struct {
+	repository.PendingDeliveryRepository
+	queued []*models.PendingDelivery
+}
+
+func (q *fakeDeliveryQueue) Create(delivery *models.PendingDelivery) (bool, error) {
+	q.queued = append(q.queued, delivery)
+	return true, nil
+}
+
+// fakeDeadLetters records dead letters
+type fakeDeadLetters struct {
+	repository.DeadLetterRepository
+	created []*models.DeadLetter
+}
+
+func (d *fakeDeadLetters) Create(deadLetter *models.DeadLetter) (bool, error) {
+	d.created = append(d.created, deadLetter)
+	return true, nil
+}
+
+func TestParkFailed(t *testing.T) {
+	failures := &ForwardResult{
+		FailureCount: 2,
+		Outcomes: []RecipientOutcome{
+			{RecipientID: uuid.New(), ChatID: 1, Status: DeliveryStatusFailed, Reason: FailureReasonNetwork, Attempts: 3, Err: errors.New("timeout")},
+			{RecipientID: uuid.New(), ChatID: 2, Status: DeliveryStatusFailed, Reason: FailureReasonForbidden, Attempts: 1, Err: errors.New("Forbidden: bot was blocked by the user")},
+			{RecipientID: uuid.New(), ChatID: 3, Status: DeliveryStatusDelivered},
+		},
+	}
+	message := &gotgbot.Message{MessageId: 10}
+
+	tests := []struct {
+		name             string
+		queueMaxAttempts int
+		wantQueued       int
+		wantDeadLettered []int64 // Recipient chats
+	}{
+		// Transient failures are queued, the others dead-lettered
+		{"queue enabled", 20, 1, []int64{2}},
+		// Without the queue nothing is retried, so every failure is dead-lettered
+		{"queue disabled", 0, 0, []int64{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue, deadLetters := &fakeDeliveryQueue{}, &fakeDeadLetters{}
+			f := newReplayForwarder()
+			f.config.Retry.QueueMaxAttempts = tt.queueMaxAttempts
+			f.SetDeliveryQueue(queue)
+			f.SetDeadLetters(deadLetters)
+
+			queued, deadLettered := f.parkFailed(uuid.New(), 42, message, failures)
+			if queued != tt.wantQueued || len(queue.queued) != tt.wantQueued {
+				t.Fatalf("queued %d (%d stored), want %d", queued, len(queue.queued), tt.wantQueued)
+			}
+			if deadLettered != len(tt.wantDeadLettered) || len(deadLetters.created) != len(tt.wantDeadLettered) {
+				t.Fatalf("dead-lettered %d (%d stored), want %d", deadLettered, len(deadLetters.created), len(tt.wantDeadLettered))
+			}
+			for i, deadLetter := range deadLetters.created {
+				if deadLetter.RecipientChatID != tt.wantDeadLettered[i] || deadLetter.GuestChatID != 42 || deadLetter.GuestMessageID != 10 {
+					t.Fatalf("dead letter %d = %+v, want recipient chat %d, guest 42, message 10", i, deadLetter, tt.wantDeadLettered[i])
+				}
+			}
+		})
+	}
+}
@@ -9,81 +9,280 @@ import (
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/repository"
 	"go.uber.org/zap"
 )
 
+// limitCacheTTL is how long the limits of a bot are cached. Changes made
+// elsewhere, e.g. by another instance, take effect within this time.
+const limitCacheTTL = 30 * time.Second
+
 type RateLimiter struct {
-	redisClient *redis.Client
+	redisClient redis.UniversalClient
+	redisMutex  sync.RWMutex
 	memoryStore map[string]*tokenBucket
 	mutex       sync.RWMutex
+	limits      LimitSource
+	limitCache  map[uuid.UUID]cachedLimits
+	limitMutex  sync.Mutex
 	config      *config.Config
 	logger      *zap.Logger
 }
 
+// BotLimits are the rate limits of one ForwarderBot. Zero values fall back to
+// the global rate_limit settings.
+type BotLimits struct {
+	TelegramAPI int // Telegram API requests per second
+	Guest       GuestLimit
+}
+
+// LimitSource looks up the rate limits of a ForwarderBot
+type LimitSource interface {
+	BotLimits(botID uuid.UUID) (BotLimits, error)
+}
+
+// botLimitSource reads the limits stored on ForwarderBot
+type botLimitSource struct {
+	botRepo repository.BotRepository
+}
+
+// NewBotLimitSource returns a LimitSource reading the limits set with
+// /setratelimit and the settings menu
+func NewBotLimitSource(botRepo repository.BotRepository) LimitSource {
+	return &botLimitSource{botRepo: botRepo}
+}
+
+func (s *botLimitSource) BotLimits(botID uuid.UUID) (BotLimits, error) {
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		return BotLimits{}, err
+	}
+	return BotLimits{
+		TelegramAPI: bot.APIRateLimit,
+		Guest: GuestLimit{
+			RatePerSecond: bot.GuestRateLimit,
+			Burst:         bot.GuestRateBurst,
+		},
+	}, nil
+}
+
+type cachedLimits struct {
+	limits  BotLimits
+	expires time.Time
+}
+
 type tokenBucket struct {
 	tokens     float64
 	lastUpdate time.Time
 	capacity   float64
 	rate       float64
+	throttled  bool // Set on the first rejection, cleared when a request is allowed again
+}
+
+// bucketResult is the outcome of taking a token from a bucket
+type bucketResult struct {
+	allowed        bool
+	retryAfter     time.Duration // Time until the next token is available when not allowed
+	firstRejection bool          // First rejection since the last allowed request
+}
+
+// GuestThrottle is the outcome of a guest rate limit check
+type GuestThrottle struct {
+	Allowed    bool
+	RetryAfter time.Duration
+	// Notify is true only for the first rejected message of a throttling episode,
+	// so the guest is told once instead of on every message
+	Notify bool
 }
 
-func NewRateLimiter(redisClient *redis.Client, cfg *config.Config, logger *zap.Logger) *RateLimiter {
+func NewRateLimiter(redisClient redis.UniversalClient, cfg *config.Config, logger *zap.Logger) *RateLimiter {
 	return &RateLimiter{
 		redisClient: redisClient,
 		memoryStore: make(map[string]*tokenBucket),
+		limitCache:  make(map[uuid.UUID]cachedLimits),
 		config:      cfg,
 		logger:      logger,
 	}
 }
 
+// SetRedisClient replaces the Redis client after a reconnect. A nil client
+// makes the limiter fall back to in-memory buckets.
+func (rl *RateLimiter) SetRedisClient(client redis.UniversalClient) {
+	rl.redisMutex.Lock()
+	defer rl.redisMutex.Unlock()
+	rl.redisClient = client
+}
+
+func (rl *RateLimiter) getRedisClient() redis.UniversalClient {
+	rl.redisMutex.RLock()
+	defer rl.redisMutex.RUnlock()
+	return rl.redisClient
+}
+
+// SetLimitSource sets where per-bot limits are looked up. Without one, every
+// bot uses the global limits.
+func (rl *RateLimiter) SetLimitSource(source LimitSource) {
+	rl.limits = source
+}
+
+// limitsForBot returns the bot's limits, cached for limitCacheTTL. Lookup
+// failures fall back to the global limits without being cached.
+func (rl *RateLimiter) limitsForBot(botID uuid.UUID) BotLimits {
+	if rl.limits == nil {
+		return BotLimits{}
+	}
+
+	rl.limitMutex.Lock()
+	cached, ok := rl.limitCache[botID]
+	rl.limitMutex.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.limits
+	}
+
+	limits, err := rl.limits.BotLimits(botID)
+	if err != nil {
+		rl.logger.Debug("Failed to load bot rate limits, using defaults",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return BotLimits{}
+	}
+	rl.limitMutex.Lock()
+	rl.limitCache[botID] = cachedLimits{limits: limits, expires: time.Now().Add(limitCacheTTL)}
+	rl.limitMutex.Unlock()
+	return limits
+}
+
+// InvalidateLimits drops the cached limits of a bot after they were changed
+func (rl *RateLimiter) InvalidateLimits(botID uuid.UUID) {
+	rl.limitMutex.Lock()
+	delete(rl.limitCache, botID)
+	rl.limitMutex.Unlock()
+}
+
+// AllowTelegramAPI takes a token from the Telegram API bucket shared by all
+// bots without a limit of their own
 func (rl *RateLimiter) AllowTelegramAPI(ctx context.Context) bool {
 	key := "rate_limit:telegram_api"
-	return rl.allow(ctx, key, rl.config.RateLimit.TelegramAPI)
+	limit := rl.config.RateLimit.TelegramAPI
+	return rl.take(ctx, key, limit, limit).allowed
+}
+
+// AllowBotTelegramAPI takes a token for a Telegram API request of the bot. A
+// bot with its own API limit has a bucket of its own; the others share the
+// global bucket.
+func (rl *RateLimiter) AllowBotTelegramAPI(ctx context.Context, botID uuid.UUID) bool {
+	limit := rl.limitsForBot(botID).TelegramAPI
+	if limit <= 0 {
+		return rl.AllowTelegramAPI(ctx)
+	}
+	return rl.take(ctx, "rate_limit:telegram_api:"+botID.String(), limit, limit).allowed
+}
+
+// GuestLimit is a per-bot guest message limit. Zero values fall back to the
+// global rate_limit.guest_message setting.
+type GuestLimit struct {
+	RatePerSecond int // Sustained messages per second
+	Burst         int // Messages allowed at once before the rate applies; 0 means same as the rate
+}
+
+// resolve fills zero values from the global configuration
+func (gl GuestLimit) resolve(cfg *config.Config) (rate int, burst int) {
+	rate = gl.RatePerSecond
+	if rate <= 0 {
+		rate = cfg.RateLimit.GuestMessage
+	}
+	burst = gl.Burst
+	if burst <= 0 {
+		burst = rate
+	}
+	return rate, burst
 }
 
 func (rl *RateLimiter) AllowGuestMessage(ctx context.Context, botID uuid.UUID, guestUserID int64) bool {
-	key := fmt.Sprintf("rate_limit:guest:%s:%d", botID.String(), guestUserID)
-	return rl.allow(ctx, key, rl.config.RateLimit.GuestMessage)
+	return rl.AllowGuestMessageWithLimit(ctx, botID, guestUserID, GuestLimit{})
+}
+
+// AllowGuestMessageWithLimit applies a bot-specific guest rate and burst
+func (rl *RateLimiter) AllowGuestMessageWithLimit(ctx context.Context, botID uuid.UUID, guestUserID int64, limit GuestLimit) bool {
+	return rl.CheckGuestMessage(ctx, botID, guestUserID, limit).Allowed
 }
 
-func (rl *RateLimiter) allow(ctx context.Context, key string, limitPerSecond int) bool {
-	if rl.redisClient != nil {
-		return rl.allowWithRedis(ctx, key, limitPerSecond)
+// CheckBotGuestMessage is CheckGuestMessage with the bot's own guest limit,
+// looked up through the limit source
+func (rl *RateLimiter) CheckBotGuestMessage(ctx context.Context, botID uuid.UUID, guestUserID int64) GuestThrottle {
+	return rl.CheckGuestMessage(ctx, botID, guestUserID, rl.limitsForBot(botID).Guest)
+}
+
+// CheckGuestMessage applies a bot-specific guest rate and burst and reports
+// how long the guest has to wait when throttled
+func (rl *RateLimiter) CheckGuestMessage(ctx context.Context, botID uuid.UUID, guestUserID int64, limit GuestLimit) GuestThrottle {
+	key := fmt.Sprintf("rate_limit:guest:%s:%d", botID.String(), guestUserID)
+	rate, burst := limit.resolve(rl.config)
+	result := rl.take(ctx, key, rate, burst)
+	return GuestThrottle{
+		Allowed:    result.allowed,
+		RetryAfter: result.retryAfter,
+		Notify:     result.firstRejection,
 	}
-	return rl.allowWithMemory(key, limitPerSecond)
 }
 
-func (rl *RateLimiter) allowWithRedis(ctx context.Context, key string, limitPerSecond int) bool {
-	now := time.Now()
-	window := time.Second
-
-	pipe := rl.redisClient.Pipeline()
-	pipe.ZRemRangeByScore(ctx, key, "0", fmt.Sprintf("%d", now.Add(-window).UnixNano()))
-	pipe.ZCard(ctx, key)
-	pipe.ZAdd(ctx, key, redis.Z{
-		Score:  float64(now.UnixNano()),
-		Member: fmt.Sprintf("%d", now.UnixNano()),
-	})
-	pipe.Expire(ctx, key, window)
-
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		rl.logger.Warn("Redis rate limit check failed, falling back to memory",
-			zap.Error(err))
-		return rl.allowWithMemory(key, limitPerSecond)
+func (rl *RateLimiter) take(ctx context.Context, key string, ratePerSecond int, burst int) bucketResult {
+	if client := rl.getRedisClient(); client != nil {
+		return rl.takeWithRedis(ctx, client, key, ratePerSecond, burst)
 	}
+	return rl.takeWithMemory(key, ratePerSecond, burst)
+}
 
-	count, err := rl.redisClient.ZCard(ctx, key).Result()
-	if err != nil {
+// tokenBucketScript implements the same token bucket as allowWithMemory atomically in Redis.
+// KEYS[1] = bucket key; ARGV = rate per second, capacity, now in milliseconds.
+// Returns {allowed, retry_after_ms, first_rejection}.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local data = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+local elapsed = math.max(0, now - ts) / 1000
+tokens = math.min(capacity, tokens + elapsed * rate)
+local allowed = 0
+local retry_after = 0
+local first_rejection = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+	redis.call("HDEL", KEYS[1], "throttled")
+else
+	retry_after = math.ceil((1 - tokens) / rate * 1000)
+	if redis.call("HSETNX", KEYS[1], "throttled", "1") == 1 then
+		first_rejection = 1
+	end
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(capacity / rate * 1000) + 1000)
+return {allowed, retry_after, first_rejection}
+`)
+
+func (rl *RateLimiter) takeWithRedis(ctx context.Context, client redis.UniversalClient, key string, ratePerSecond int, burst int) bucketResult {
+	values, err := tokenBucketScript.Run(ctx, client, []string{key},
+		ratePerSecond, burst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil || len(values) != 3 {
 		rl.logger.Warn("Redis rate limit check failed, falling back to memory",
 			zap.Error(err))
-		return rl.allowWithMemory(key, limitPerSecond)
+		return rl.takeWithMemory(key, ratePerSecond, burst)
+	}
+	return bucketResult{
+		allowed:        values[0] == 1,
+		retryAfter:     time.Duration(values[1]) * time.Millisecond,
+		firstRejection: values[2] == 1,
 	}
-
-	return int64(count) <= int64(limitPerSecond)
 }
 
-func (rl *RateLimiter) allowWithMemory(key string, limitPerSecond int) bool {
+func (rl *RateLimiter) takeWithMemory(key string, ratePerSecond int, burst int) bucketResult {
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
 
@@ -92,13 +291,14 @@ func (rl *RateLimiter) allowWithMemory(key string, limitPerSecond int) bool {
 
 	if !exists {
 		bucket = &tokenBucket{
-			tokens:     float64(limitPerSecond),
+			tokens:     float64(burst),
 			lastUpdate: now,
-			capacity:   float64(limitPerSecond),
-			rate:       float64(limitPerSecond),
 		}
 		rl.memoryStore[key] = bucket
 	}
+	// Limits can change at runtime (per-bot settings), so always apply the current ones
+	bucket.capacity = float64(burst)
+	bucket.rate = float64(ratePerSecond)
 
 	elapsed := now.Sub(bucket.lastUpdate).Seconds()
 	tokensToAdd := elapsed * bucket.rate
@@ -107,10 +307,80 @@ func (rl *RateLimiter) allowWithMemory(key string, limitPerSecond int) bool {
 
 	if bucket.tokens >= 1.0 {
 		bucket.tokens -= 1.0
-		return true
+		bucket.throttled = false
+		return bucketResult{allowed: true}
+	}
+
+	firstRejection := !bucket.throttled
+	bucket.throttled = true
+	return bucketResult{
+		retryAfter:     time.Duration((1.0 - bucket.tokens) / bucket.rate * float64(time.Second)),
+		firstRejection: firstRejection,
 	}
+}
+
+// saveBucketScript writes a bucket in the format of tokenBucketScript unless
+// the key already exists, since a bucket in Redis is newer than one from memory.
+// KEYS[1] = bucket key; ARGV = tokens, time in milliseconds, throttled ("1" or
+// "0"), time to live in milliseconds. Returns 1 when the bucket was written.
+var saveBucketScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+redis.call("HSET", KEYS[1], "tokens", ARGV[1], "ts", ARGV[2])
+if ARGV[3] == "1" then
+	redis.call("HSET", KEYS[1], "throttled", "1")
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return 1
+`)
 
-	return false
+// SaveToRedis copies the in-memory buckets, used while Redis was unavailable,
+// to Redis, so the limits still apply after a restart instead of starting
+// from full buckets. Called on shutdown; returns how many buckets were saved.
+func (rl *RateLimiter) SaveToRedis(ctx context.Context) (int, error) {
+	client := rl.getRedisClient()
+	if client == nil {
+		return 0, fmt.Errorf("redis is unavailable")
+	}
+
+	now := time.Now()
+	saved := 0
+	for key, bucket := range rl.partialBuckets(now) {
+		throttled := "0"
+		if bucket.throttled {
+			throttled = "1"
+		}
+		ttl := int64(bucket.capacity/bucket.rate*1000) + 1000
+		written, err := saveBucketScript.Run(ctx, client, []string{key},
+			fmt.Sprintf("%g", bucket.tokens), now.UnixMilli(), throttled, ttl).Int()
+		if err != nil {
+			return saved, fmt.Errorf("failed to save rate limit bucket: %w", err)
+		}
+		saved += written
+	}
+	return saved, nil
+}
+
+// partialBuckets returns copies of the in-memory buckets refilled up to now,
+// leaving out full ones, which are no different from a new bucket
+func (rl *RateLimiter) partialBuckets(now time.Time) map[string]tokenBucket {
+	rl.mutex.RLock()
+	defer rl.mutex.RUnlock()
+
+	buckets := make(map[string]tokenBucket)
+	for key, bucket := range rl.memoryStore {
+		if bucket.rate <= 0 {
+			continue
+		}
+		refilled := *bucket
+		refilled.tokens = min(bucket.capacity, bucket.tokens+now.Sub(bucket.lastUpdate).Seconds()*bucket.rate)
+		refilled.lastUpdate = now
+		if refilled.tokens < refilled.capacity || refilled.throttled {
+			buckets[key] = refilled
+		}
+	}
+	return buckets
 }
 
 func min(a, b float64) float64 {
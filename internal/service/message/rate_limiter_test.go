@@ -100,3 +100,168 @@ func TestRateLimiter_DifferentBots(t *testing.T) {
 		t.Fatal("Should allow message for bot 2")
 	}
 }
+
+func TestRateLimiter_GuestBurst(t *testing.T) {
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{
+			TelegramAPI:  25,
+			GuestMessage: 1,
+		},
+	}
+	logger := zap.NewNop()
+	limiter := NewRateLimiter(nil, cfg, logger)
+
+	ctx := context.Background()
+	botID := uuid.New()
+	guestID := int64(123456)
+	limit := GuestLimit{RatePerSecond: 1, Burst: 3}
+
+	// Burst of 3 should be allowed
+	for i := 0; i < 3; i++ {
+		if !limiter.AllowGuestMessageWithLimit(ctx, botID, guestID, limit) {
+			t.Fatalf("Should allow burst message %d", i+1)
+		}
+	}
+
+	// 4th message should be rate limited
+	if limiter.AllowGuestMessageWithLimit(ctx, botID, guestID, limit) {
+		t.Fatal("Should rate limit message after burst")
+	}
+
+	// After one second, one more token is available
+	time.Sleep(1100 * time.Millisecond)
+	if !limiter.AllowGuestMessageWithLimit(ctx, botID, guestID, limit) {
+		t.Fatal("Should allow message after refill")
+	}
+	if limiter.AllowGuestMessageWithLimit(ctx, botID, guestID, limit) {
+		t.Fatal("Should only refill at the sustained rate")
+	}
+}
+
+func TestRateLimiter_GuestThrottleNotifiesOncePerEpisode(t *testing.T) {
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{
+			TelegramAPI:  25,
+			GuestMessage: 1,
+		},
+	}
+	logger := zap.NewNop()
+	limiter := NewRateLimiter(nil, cfg, logger)
+
+	ctx := context.Background()
+	botID := uuid.New()
+	guestID := int64(123456)
+	limit := GuestLimit{RatePerSecond: 1, Burst: 1}
+
+	if !limiter.CheckGuestMessage(ctx, botID, guestID, limit).Allowed {
+		t.Fatal("First message should be allowed")
+	}
+
+	// First rejection of the episode should notify and report a wait
+	throttle := limiter.CheckGuestMessage(ctx, botID, guestID, limit)
+	if throttle.Allowed || !throttle.Notify {
+		t.Fatalf("Expected throttled with notify, got %+v", throttle)
+	}
+	if throttle.RetryAfter <= 0 || throttle.RetryAfter > time.Second {
+		t.Fatalf("Unexpected retry after %s", throttle.RetryAfter)
+	}
+
+	// Further rejections in the same episode should not notify again
+	throttle = limiter.CheckGuestMessage(ctx, botID, guestID, limit)
+	if throttle.Allowed || throttle.Notify {
+		t.Fatalf("Expected throttled without notify, got %+v", throttle)
+	}
+
+	// An allowed message ends the episode, so the next rejection notifies again
+	time.Sleep(1100 * time.Millisecond)
+	if !limiter.CheckGuestMessage(ctx, botID, guestID, limit).Allowed {
+		t.Fatal("Should allow message after refill")
+	}
+	throttle = limiter.CheckGuestMessage(ctx, botID, guestID, limit)
+	if throttle.Allowed || !throttle.Notify {
+		t.Fatalf("Expected new episode to notify, got %+v", throttle)
+	}
+}
+
+func TestRateLimiter_PartialBucketsAreSaved(t *testing.T) {
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{
+			TelegramAPI:  10,
+			GuestMessage: 1,
+		},
+	}
+	limiter := NewRateLimiter(nil, cfg, zap.NewNop())
+
+	ctx := context.Background()
+	botID := uuid.New()
+	limiter.CheckGuestMessage(ctx, botID, 1, GuestLimit{RatePerSecond: 1, Burst: 1})
+	limiter.CheckGuestMessage(ctx, botID, 1, GuestLimit{RatePerSecond: 1, Burst: 1})
+	for i := 0; i < 4; i++ {
+		limiter.AllowTelegramAPI(ctx)
+	}
+
+	now := time.Now()
+	buckets := limiter.partialBuckets(now)
+	guest, ok := buckets["rate_limit:guest:"+botID.String()+":1"]
+	if !ok || !guest.throttled || guest.tokens >= 1 {
+		t.Fatalf("guest bucket = %+v, %v; want a throttled empty bucket", guest, ok)
+	}
+	api, ok := buckets["rate_limit:telegram_api"]
+	if !ok || api.tokens < 6 || api.tokens > 7 {
+		t.Fatalf("Telegram API bucket = %+v, %v; want about 6 tokens left", api, ok)
+	}
+
+	// Buckets that have refilled are not worth saving
+	if buckets := limiter.partialBuckets(now.Add(2 * time.Second)); len(buckets) != 1 {
+		t.Fatalf("got %d buckets after a refill, want only the throttled one", len(buckets))
+	}
+}
+
+type fakeLimitSource map[uuid.UUID]BotLimits
+
+func (s fakeLimitSource) BotLimits(botID uuid.UUID) (BotLimits, error) {
+	return s[botID], nil
+}
+
+func TestRateLimiter_BotTelegramAPILimit(t *testing.T) {
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{
+			TelegramAPI:  5,
+			GuestMessage: 1,
+		},
+	}
+	limiter := NewRateLimiter(nil, cfg, zap.NewNop())
+	busyBot, smallBot := uuid.New(), uuid.New()
+	source := fakeLimitSource{busyBot: {TelegramAPI: 2}}
+	limiter.SetLimitSource(source)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if !limiter.AllowBotTelegramAPI(ctx, busyBot) {
+			t.Fatalf("Should allow request %d of the bot with its own limit", i+1)
+		}
+	}
+	if limiter.AllowBotTelegramAPI(ctx, busyBot) {
+		t.Fatal("Should apply the bot's own limit")
+	}
+
+	// Bots without a limit of their own share the global bucket
+	for i := 0; i < 5; i++ {
+		if !limiter.AllowBotTelegramAPI(ctx, smallBot) {
+			t.Fatalf("Should allow request %d under the global limit", i+1)
+		}
+	}
+	if limiter.AllowTelegramAPI(ctx) {
+		t.Fatal("Bots without their own limit should use the global bucket")
+	}
+
+	// Changed limits are cached until invalidated
+	source[smallBot] = BotLimits{TelegramAPI: 1}
+	if limiter.AllowBotTelegramAPI(ctx, smallBot) {
+		t.Fatal("Should keep using the cached global limit")
+	}
+	limiter.InvalidateLimits(smallBot)
+	if !limiter.AllowBotTelegramAPI(ctx, smallBot) {
+		t.Fatal("Should use the bot's new limit after invalidation")
+	}
+}
@@ -0,0 +1,193 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ReplayArchiveLimit is the most archived messages one replay re-delivers
+const ReplayArchiveLimit = 1000
+
+// ErrReplayTooLarge is returned by ReplayArchived when the time range holds
+// more than ReplayArchiveLimit messages
+var ErrReplayTooLarge = fmt.Errorf("more than %d messages in the time range", ReplayArchiveLimit)
+
+// ReplayReport counts the outcomes of a replay so far
+type ReplayReport struct {
+	Total     int // Messages the replay is for
+	Delivered int
+	Skipped   int // Already delivered to the recipient
+	Failed    int
+	Cancelled bool
+}
+
+// Done returns how many messages the replay has dealt with
+func (r ReplayReport) Done() int {
+	return r.Delivered + r.Skipped + r.Failed
+}
+
+// ReplayQueued delivers the given queued deliveries now, whether they are due
+// or not. Unlike the retry queue run it waits for the Telegram API rate
+// limiter instead of leaving the rest for later. Delivered and undeliverable
+// messages leave the queue; the others stay queued with their new error.
+func (f *Forwarder) ReplayQueued(
+	ctx context.Context,
+	bot *gotgbot.Bot,
+	botID uuid.UUID,
+	deliveries []*models.PendingDelivery,
+	progress func(ReplayReport),
+) ReplayReport {
+	report := f.replay(ctx, botID, len(deliveries), func(i int) (bool, error) {
+		delivery := deliveries[i]
+		err := f.retryHandler.Retry(ctx, func() error {
+			return f.deliverQueued(ctx, bot, botID, delivery)
+		})
+		switch {
+		case err == nil, errors.Is(err, errUndeliverable):
+			f.removeQueued(botID, delivery)
+		case ClassifyError(err) != FailureReasonCancelled:
+			delivery.LastError = err.Error()
+			if updateErr := f.deliveryQueue.Update(delivery); updateErr != nil {
+				f.logger.Warn("Failed to update queued delivery",
+					zap.String("bot_id", botID.String()),
+					zap.String("delivery_id", delivery.ID.String()),
+					zap.Error(updateErr))
+			}
+		}
+		return false, err
+	}, progress)
+
+	f.logger.Info("Queued deliveries replayed",
+		zap.String("bot_id", botID.String()),
+		zap.Int("total", report.Total),
+		zap.Int("delivered", report.Delivered),
+		zap.Int("failed", report.Failed),
+		zap.Bool("cancelled", report.Cancelled))
+	return report
+}
+
+// ReplayArchived re-delivers the guest messages the bot received in
+// [start, end) to one recipient, e.g. one added after them. Messages are
+// copied from the guest chats, so ones the guest has since deleted fail.
+// Messages the recipient already has are skipped, and replies to the copies
+// reach the guests like replies to any forwarded message. Replays do not count
+// against the bot's quota.
+func (f *Forwarder) ReplayArchived(
+	ctx context.Context,
+	bot *gotgbot.Bot,
+	botID uuid.UUID,
+	recipient *models.Recipient,
+	start time.Time,
+	end time.Time,
+	progress func(ReplayReport),
+) (ReplayReport, error) {
+	messages, err := f.inboundMessageRepo.GetByBotIDBetween(botID, start, end, ReplayArchiveLimit+1)
+	if err != nil {
+		return ReplayReport{}, fmt.Errorf("failed to get archived messages: %w", err)
+	}
+	if len(messages) > ReplayArchiveLimit {
+		return ReplayReport{}, ErrReplayTooLarge
+	}
+
+	report := f.replay(ctx, botID, len(messages), func(i int) (bool, error) {
+		archived := messages[i]
+		if f.hasCopy(botID, archived, recipient.ChatID) {
+			return true, nil
+		}
+		guest, err := f.guestRepo.GetOrCreateByBotIDAndUserID(botID, archived.GuestChatID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get or create guest: %w", err)
+		}
+		header, private, err := f.guestHeader(botID, guest)
+		if err != nil {
+			return false, err
+		}
+		// Only the IDs are stored; Telegram copies the content from the guest chat
+		message := &gotgbot.Message{
+			MessageId: archived.GuestMessageID,
+			Chat:      gotgbot.Chat{Id: archived.GuestChatID, Type: "private"},
+		}
+		return false, f.retryHandler.Retry(ctx, func() error {
+			return f.forwardMessage(ctx, bot, botID, guest, message, recipient, header, private)
+		})
+	}, progress)
+
+	f.logger.Info("Archived messages replayed",
+		zap.String("bot_id", botID.String()),
+		zap.Int64("recipient_chat_id", recipient.ChatID),
+		zap.Time("start", start),
+		zap.Time("end", end),
+		zap.Int("total", report.Total),
+		zap.Int("delivered", report.Delivered),
+		zap.Int("skipped", report.Skipped),
+		zap.Int("failed", report.Failed),
+		zap.Bool("cancelled", report.Cancelled))
+	return report, nil
+}
+
+// hasCopy reports whether the recipient chat already received the archived message
+func (f *Forwarder) hasCopy(botID uuid.UUID, archived *models.InboundMessage, recipientChatID int64) bool {
+	mappings, err := f.messageMappingRepo.GetAllByGuestMessage(botID, archived.GuestChatID, archived.GuestMessageID)
+	if err != nil {
+		return false
+	}
+	for _, mapping := range mappings {
+		if mapping.Direction == models.MessageDirectionInbound && mapping.RecipientChatID == recipientChatID {
+			return true
+		}
+	}
+	return false
+}
+
+// replay runs deliver for each of n messages, paced like a broadcast: every
+// message waits for the Telegram API rate limiter, and batches are separated
+// by a pause, after which progress is reported. deliver reports whether the
+// message was skipped. The replay stops early when ctx is cancelled.
+func (f *Forwarder) replay(ctx context.Context, botID uuid.UUID, n int, deliver func(i int) (bool, error), progress func(ReplayReport)) ReplayReport {
+	report := ReplayReport{Total: n}
+	for i := 0; i < n; i++ {
+		if i > 0 && i%broadcastBatchSize == 0 {
+			if progress != nil {
+				progress(report)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(broadcastBatchPause):
+			}
+		}
+		if err := f.waitTelegramAPIUntil(ctx, botID); err != nil {
+			report.Cancelled = true
+			break
+		}
+
+		skipped, err := deliver(i)
+		switch {
+		case skipped:
+			report.Skipped++
+		case err == nil:
+			report.Delivered++
+		case ClassifyError(err) == FailureReasonCancelled:
+			report.Cancelled = true
+		default:
+			report.Failed++
+			f.logger.Debug("Failed to replay message",
+				zap.Int("index", i),
+				zap.Error(err))
+		}
+		if report.Cancelled {
+			break
+		}
+	}
+	if progress != nil {
+		progress(report)
+	}
+	return report
+}
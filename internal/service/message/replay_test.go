@@ -0,0 +1,69 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-telegram-forwarder-bot/internal/config"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func newReplayForwarder() *Forwarder {
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{TelegramAPI: 1000},
+		Retry:     config.RetryConfig{MaxAttempts: 1},
+	}
+	return &Forwarder{
+		rateLimiter:  NewRateLimiter(nil, cfg, zap.NewNop()),
+		retryHandler: NewRetryHandler(cfg, zap.NewNop()),
+		config:       cfg,
+		logger:       zap.NewNop(),
+	}
+}
+
+func TestReplayCountsOutcomes(t *testing.T) {
+	f := newReplayForwarder()
+
+	var reports []ReplayReport
+	report := f.replay(context.Background(), uuid.New(), 4, func(i int) (bool, error) {
+		switch i {
+		case 1:
+			return true, nil
+		case 2:
+			return false, errors.New("Bad Request: message to copy not found")
+		}
+		return false, nil
+	}, func(progress ReplayReport) {
+		reports = append(reports, progress)
+	})
+
+	want := ReplayReport{Total: 4, Delivered: 2, Skipped: 1, Failed: 1}
+	if report != want {
+		t.Fatalf("replay = %+v, want %+v", report, want)
+	}
+	if len(reports) == 0 || reports[len(reports)-1] != want {
+		t.Fatalf("last progress = %+v, want the final report", reports)
+	}
+}
+
+func TestReplayStopsWhenCancelled(t *testing.T) {
+	f := newReplayForwarder()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	report := f.replay(ctx, uuid.New(), 10, func(i int) (bool, error) {
+		calls++
+		if i == 2 {
+			cancel()
+			return false, ctx.Err()
+		}
+		return false, nil
+	}, nil)
+
+	if !report.Cancelled || report.Delivered != 2 || calls != 3 {
+		t.Fatalf("replay = %+v after %d calls, want cancelled after 2 delivered", report, calls)
+	}
+}
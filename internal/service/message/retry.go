@@ -4,23 +4,36 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"math/rand/v2"
 	"net"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"go-telegram-forwarder-bot/internal/config"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
 	"go.uber.org/zap"
 )
 
+// retryAfterPattern finds the flood wait in the text of a 429 error, e.g.
+// "Too Many Requests: retry after 5"
+var retryAfterPattern = regexp.MustCompile(`(?i)retry after (\d+)`)
+
 type RetryHandler struct {
 	config *config.Config
 	logger *zap.Logger
+	// jitter returns a random number in [0, 1); replaced in tests
+	jitter func() float64
 }
 
 func NewRetryHandler(cfg *config.Config, logger *zap.Logger) *RetryHandler {
 	return &RetryHandler{
 		config: cfg,
 		logger: logger,
+		jitter: rand.Float64,
 	}
 }
 
@@ -45,15 +58,17 @@ func (rh *RetryHandler) Retry(ctx context.Context, fn func() error) error {
 		}
 
 		if i < rh.config.Retry.MaxAttempts-1 {
+			wait := rh.backoff(i, err)
 			rh.logger.Debug("Retrying operation",
 				zap.Int("attempt", i+1),
 				zap.Int("max_attempts", rh.config.Retry.MaxAttempts),
+				zap.Duration("backoff", wait),
 				zap.Error(err))
 
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-time.After(time.Duration(rh.config.Retry.IntervalSeconds) * time.Second):
+			case <-time.After(wait):
 			}
 		}
 	}
@@ -64,11 +79,57 @@ func (rh *RetryHandler) Retry(ctx context.Context, fn func() error) error {
 	return fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
+// backoff returns how long to wait after the given failed attempt, counted
+// from 0. A flood wait sent by Telegram is honored as is; otherwise the
+// interval grows by the multiplier per attempt up to the maximum, and a random
+// jitter of up to half the interval keeps concurrent retries apart.
+func (rh *RetryHandler) backoff(attempt int, err error) time.Duration {
+	if retryAfter, ok := parseRetryAfter(err); ok {
+		return retryAfter
+	}
+
+	cfg := rh.config.Retry
+	interval := float64(cfg.IntervalSeconds) * math.Pow(max(cfg.Multiplier, 1), float64(attempt))
+	if cfg.MaxIntervalSeconds > 0 {
+		interval = min(interval, float64(cfg.MaxIntervalSeconds))
+	}
+	interval *= 1 - rh.jitter()/2
+	return time.Duration(interval * float64(time.Second))
+}
+
+// parseRetryAfter returns the wait Telegram asks for in a 429 error, from the
+// response parameters or, for wrapped errors, from the error text
+func parseRetryAfter(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	var tgErr *gotgbot.TelegramError
+	if errors.As(err, &tgErr) && tgErr.ResponseParams != nil && tgErr.ResponseParams.RetryAfter > 0 {
+		return time.Duration(tgErr.ResponseParams.RetryAfter) * time.Second, true
+	}
+
+	match := retryAfterPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, false
+	}
+	seconds, convErr := strconv.Atoi(match[1])
+	if convErr != nil || seconds <= 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
 func (rh *RetryHandler) isRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
 
+	var tgErr *gotgbot.TelegramError
+	if errors.As(err, &tgErr) && (tgErr.Code == 429 || tgErr.Code >= 500) {
+		return true
+	}
+
 	var netErr net.Error
 	if errors.As(err, &netErr) {
 		return netErr.Timeout() || netErr.Temporary()
@@ -3,11 +3,14 @@ package message
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"testing"
 	"time"
 
 	"go-telegram-forwarder-bot/internal/config"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
 	"go.uber.org/zap"
 )
 
@@ -223,3 +226,116 @@ func TestRetryHandler_ContextCancellation(t *testing.T) {
 		t.Fatalf("Should return context.Canceled error, got: %v", err)
 	}
 }
+
+func TestRetryHandler_ExponentialBackoff(t *testing.T) {
+	cfg := &config.Config{
+		Retry: config.RetryConfig{
+			MaxAttempts:        10,
+			IntervalSeconds:    1,
+			Multiplier:         2,
+			MaxIntervalSeconds: 5,
+		},
+	}
+	logger := zap.NewNop()
+	handler := NewRetryHandler(cfg, logger)
+	handler.jitter = func() float64 { return 0 }
+
+	err := errors.New("500 Internal Server Error")
+	want := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for attempt, expected := range want {
+		if got := handler.backoff(attempt, err); got != expected {
+			t.Fatalf("Backoff after attempt %d should be %s, got %s", attempt+1, expected, got)
+		}
+	}
+}
+
+func TestRetryHandler_BackoffJitter(t *testing.T) {
+	cfg := &config.Config{
+		Retry: config.RetryConfig{
+			MaxAttempts:        3,
+			IntervalSeconds:    4,
+			Multiplier:         2,
+			MaxIntervalSeconds: 60,
+		},
+	}
+	logger := zap.NewNop()
+	handler := NewRetryHandler(cfg, logger)
+	handler.jitter = func() float64 { return 0.5 }
+
+	// A jitter of 0.5 takes a quarter off the 8s interval
+	if got := handler.backoff(1, errors.New("502 Bad Gateway")); got != 6*time.Second {
+		t.Fatalf("Backoff with jitter should be 6s, got %s", got)
+	}
+
+	handler = NewRetryHandler(cfg, logger)
+	for i := 0; i < 100; i++ {
+		got := handler.backoff(1, errors.New("502 Bad Gateway"))
+		if got <= 4*time.Second || got > 8*time.Second {
+			t.Fatalf("Backoff should stay within (4s, 8s], got %s", got)
+		}
+	}
+}
+
+func TestRetryHandler_RetryAfterFromTelegramError(t *testing.T) {
+	cfg := &config.Config{
+		Retry: config.RetryConfig{
+			MaxAttempts:        3,
+			IntervalSeconds:    1,
+			Multiplier:         2,
+			MaxIntervalSeconds: 5,
+		},
+	}
+	logger := zap.NewNop()
+	handler := NewRetryHandler(cfg, logger)
+	handler.jitter = func() float64 { return 0 }
+
+	err := fmt.Errorf("failed to forward message: %w", &gotgbot.TelegramError{
+		Method:         "forwardMessage",
+		Code:           429,
+		Description:    "Too Many Requests: retry after 7",
+		ResponseParams: &gotgbot.ResponseParameters{RetryAfter: 7},
+	})
+
+	// retry_after is honored even above the maximum interval
+	if got := handler.backoff(0, err); got != 7*time.Second {
+		t.Fatalf("Backoff should honor retry_after of 7s, got %s", got)
+	}
+}
+
+func TestRetryHandler_RetryAfterFromErrorText(t *testing.T) {
+	cfg := &config.Config{
+		Retry: config.RetryConfig{
+			MaxAttempts:        3,
+			IntervalSeconds:    1,
+			Multiplier:         2,
+			MaxIntervalSeconds: 60,
+		},
+	}
+	logger := zap.NewNop()
+	handler := NewRetryHandler(cfg, logger)
+	handler.jitter = func() float64 { return 0 }
+
+	err := errors.New("unable to sendMessage: Too Many Requests: retry after 3")
+	if got := handler.backoff(4, err); got != 3*time.Second {
+		t.Fatalf("Backoff should use retry_after of 3s from the error text, got %s", got)
+	}
+
+	// Without retry_after the exponential backoff applies
+	if got := handler.backoff(4, errors.New("429 Too Many Requests")); got != 16*time.Second {
+		t.Fatalf("Backoff without retry_after should be 16s, got %s", got)
+	}
+}
+
+func TestRetryHandler_ServerErrorIsRetryable(t *testing.T) {
+	handler := NewRetryHandler(&config.Config{}, zap.NewNop())
+
+	// Telegram describes 5xx errors without the status code
+	err := &gotgbot.TelegramError{Method: "sendMessage", Code: 500, Description: "Internal Server Error"}
+	if !handler.isRetryableError(err) {
+		t.Fatal("A Telegram 500 error should be retryable")
+	}
+	err = &gotgbot.TelegramError{Method: "sendMessage", Code: 400, Description: "Bad Request: message text is empty"}
+	if handler.isRetryableError(err) {
+		t.Fatal("A Telegram 400 error should not be retryable")
+	}
+}
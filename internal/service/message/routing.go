@@ -0,0 +1,184 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxRoutingRules limits the number of routing rules per bot
+	MaxRoutingRules = 100
+	// maxRoutingValueLength matches the RoutingRule.Value column size
+	maxRoutingValueLength = 255
+)
+
+// MediaTypes are the message types media routing rules can match, in the
+// order MediaType checks them
+var MediaTypes = []string{
+	"text", "photo", "video", "animation", "audio", "voice", "video_note",
+	"document", "sticker", "location", "contact", "poll", "other",
+}
+
+// MediaType names the type of a message as used by media routing rules
+func MediaType(msg *gotgbot.Message) string {
+	switch {
+	case msg.Text != "":
+		return "text"
+	case len(msg.Photo) > 0:
+		return "photo"
+	case msg.Video != nil:
+		return "video"
+	case msg.Animation != nil: // Animations also set Document, so check them first
+		return "animation"
+	case msg.Audio != nil:
+		return "audio"
+	case msg.Voice != nil:
+		return "voice"
+	case msg.VideoNote != nil:
+		return "video_note"
+	case msg.Document != nil:
+		return "document"
+	case msg.Sticker != nil:
+		return "sticker"
+	case msg.Location != nil:
+		return "location"
+	case msg.Contact != nil:
+		return "contact"
+	case msg.Poll != nil:
+		return "poll"
+	default:
+		return "other"
+	}
+}
+
+// ParseRoutingRule checks the kind and value of a routing rule as a manager
+// typed them and returns them normalized for storage. Kinds may be abbreviated
+// to "lang".
+func ParseRoutingRule(kind, value string) (models.RoutingRuleKind, string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", "", fmt.Errorf("the value is empty")
+	}
+	if utf8.RuneCountInString(value) > maxRoutingValueLength {
+		return "", "", fmt.Errorf("the value is longer than %d characters", maxRoutingValueLength)
+	}
+
+	switch strings.ToLower(kind) {
+	case string(models.RoutingRuleKeyword):
+		return models.RoutingRuleKeyword, value, nil
+	case string(models.RoutingRuleMedia):
+		value = strings.ToLower(value)
+		for _, mediaType := range MediaTypes {
+			if value == mediaType {
+				return models.RoutingRuleMedia, value, nil
+			}
+		}
+		return "", "", fmt.Errorf("unknown media type %q, expected one of %s", value, strings.Join(MediaTypes, ", "))
+	case string(models.RoutingRuleLanguage), "lang":
+		value = strings.ToLower(strings.ReplaceAll(value, "_", "-"))
+		for _, r := range value {
+			if (r < 'a' || r > 'z') && r != '-' {
+				return "", "", fmt.Errorf("invalid language code %q, expected a code like en or pt-br", value)
+			}
+		}
+		return models.RoutingRuleLanguage, value, nil
+	default:
+		return "", "", fmt.Errorf("unknown rule type %q, expected keyword, media or lang", kind)
+	}
+}
+
+// RoutingRuleMatches reports whether a guest message matches the rule
+func RoutingRuleMatches(rule *models.RoutingRule, msg *gotgbot.Message) bool {
+	switch rule.Kind {
+	case models.RoutingRuleKeyword:
+		keyword := strings.ToLower(rule.Value)
+		for _, text := range []string{msg.Text, msg.Caption} {
+			if text != "" && strings.Contains(strings.ToLower(text), keyword) {
+				return true
+			}
+		}
+		return false
+	case models.RoutingRuleMedia:
+		return MediaType(msg) == rule.Value
+	case models.RoutingRuleLanguage:
+		if msg.From == nil || msg.From.LanguageCode == "" {
+			return false
+		}
+		language := strings.ToLower(msg.From.LanguageCode)
+		return language == rule.Value || strings.HasPrefix(language, rule.Value+"-")
+	default:
+		return false
+	}
+}
+
+// routeRecipients returns the recipients that get the message under rules.
+// Recipients without rules and the fallback recipient get every message. If
+// the rules leave no recipient, every recipient gets the message so it is not
+// lost.
+func routeRecipients(rules []*models.RoutingRule, recipients []*models.Recipient, msg *gotgbot.Message) []*models.Recipient {
+	if len(rules) == 0 {
+		return recipients
+	}
+	byRecipient := make(map[uuid.UUID][]*models.RoutingRule)
+	for _, rule := range rules {
+		byRecipient[rule.RecipientID] = append(byRecipient[rule.RecipientID], rule)
+	}
+
+	routed := make([]*models.Recipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		recipientRules := byRecipient[recipient.ID]
+		if recipient.IsFallback || len(recipientRules) == 0 {
+			routed = append(routed, recipient)
+			continue
+		}
+		for _, rule := range recipientRules {
+			if RoutingRuleMatches(rule, msg) {
+				routed = append(routed, recipient)
+				break
+			}
+		}
+	}
+	if len(routed) == 0 {
+		return recipients
+	}
+	return routed
+}
+
+// SetRoutingRules makes the forwarder send guest messages only to the
+// recipients whose routing rules they match
+func (f *Forwarder) SetRoutingRules(repo repository.RoutingRuleRepository) {
+	f.routingRuleRepo = repo
+}
+
+// applyRoutingRules leaves out the recipients whose routing rules do not match
+// the guest message. Rules that cannot be loaded do not block forwarding.
+func (f *Forwarder) applyRoutingRules(botID uuid.UUID, msg *gotgbot.Message, recipients []*models.Recipient) []*models.Recipient {
+	if f.routingRuleRepo == nil {
+		return recipients
+	}
+	rules, err := f.routingRuleRepo.GetByBotID(botID)
+	if err != nil {
+		f.logger.Warn("Failed to get routing rules, forwarding to every recipient",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return recipients
+	}
+
+	routed := routeRecipients(rules, recipients, msg)
+	if len(routed) < len(recipients) {
+		f.logger.Debug("Routing rules applied",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("message_id", msg.MessageId),
+			zap.Int("recipient_count", len(recipients)),
+			zap.Int("routed_count", len(routed)))
+	}
+	return routed
+}
@@ -0,0 +1,118 @@
+package message
+
+import (
+	"testing"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+)
+
+func TestParseRoutingRule(t *testing.T) {
+	tests := []struct {
+		kind, value string
+		wantKind    models.RoutingRuleKind
+		wantValue   string
+	}{
+		{"keyword", " Refund ", models.RoutingRuleKeyword, "Refund"},
+		{"MEDIA", "Photo", models.RoutingRuleMedia, "photo"},
+		{"lang", "pt_BR", models.RoutingRuleLanguage, "pt-br"},
+		{"language", "en", models.RoutingRuleLanguage, "en"},
+	}
+	for _, tt := range tests {
+		kind, value, err := ParseRoutingRule(tt.kind, tt.value)
+		if err != nil {
+			t.Errorf("ParseRoutingRule(%q, %q): %v", tt.kind, tt.value, err)
+			continue
+		}
+		if kind != tt.wantKind || value != tt.wantValue {
+			t.Errorf("ParseRoutingRule(%q, %q) = %q, %q, want %q, %q", tt.kind, tt.value, kind, value, tt.wantKind, tt.wantValue)
+		}
+	}
+
+	for _, invalid := range [][2]string{
+		{"keyword", "  "},
+		{"media", "hologram"},
+		{"lang", "en1"},
+		{"sender", "alice"},
+	} {
+		if _, _, err := ParseRoutingRule(invalid[0], invalid[1]); err == nil {
+			t.Errorf("ParseRoutingRule(%q, %q) accepted an invalid rule", invalid[0], invalid[1])
+		}
+	}
+}
+
+func TestRoutingRuleMatches(t *testing.T) {
+	photo := &gotgbot.Message{
+		Photo:   []gotgbot.PhotoSize{{FileId: "photo"}},
+		Caption: "Where is my REFUND?",
+		From:    &gotgbot.User{LanguageCode: "en-GB"},
+	}
+	tests := []struct {
+		rule models.RoutingRule
+		want bool
+	}{
+		{models.RoutingRule{Kind: models.RoutingRuleKeyword, Value: "refund"}, true},
+		{models.RoutingRule{Kind: models.RoutingRuleKeyword, Value: "invoice"}, false},
+		{models.RoutingRule{Kind: models.RoutingRuleMedia, Value: "photo"}, true},
+		{models.RoutingRule{Kind: models.RoutingRuleMedia, Value: "text"}, false},
+		{models.RoutingRule{Kind: models.RoutingRuleLanguage, Value: "en"}, true},
+		{models.RoutingRule{Kind: models.RoutingRuleLanguage, Value: "en-gb"}, true},
+		{models.RoutingRule{Kind: models.RoutingRuleLanguage, Value: "e"}, false},
+		{models.RoutingRule{Kind: models.RoutingRuleLanguage, Value: "de"}, false},
+	}
+	for _, tt := range tests {
+		if got := RoutingRuleMatches(&tt.rule, photo); got != tt.want {
+			t.Errorf("%s %q matches = %v, want %v", tt.rule.Kind, tt.rule.Value, got, tt.want)
+		}
+	}
+
+	// Messages without a sender, e.g. released from quarantine, have no language
+	if RoutingRuleMatches(&models.RoutingRule{Kind: models.RoutingRuleLanguage, Value: "en"}, &gotgbot.Message{Text: "hi"}) {
+		t.Error("language rule matched a message without a sender")
+	}
+}
+
+func TestRouteRecipients(t *testing.T) {
+	sales := &models.Recipient{ID: uuid.New(), ChatID: -1}
+	support := &models.Recipient{ID: uuid.New(), ChatID: -2}
+	everything := &models.Recipient{ID: uuid.New(), ChatID: -3}
+	rules := []*models.RoutingRule{
+		{RecipientID: sales.ID, Kind: models.RoutingRuleKeyword, Value: "price"},
+		{RecipientID: support.ID, Kind: models.RoutingRuleKeyword, Value: "refund"},
+		{RecipientID: support.ID, Kind: models.RoutingRuleMedia, Value: "photo"},
+	}
+	recipients := []*models.Recipient{sales, support, everything}
+
+	routed := routeRecipients(rules, recipients, &gotgbot.Message{Text: "What is the price?"})
+	if len(routed) != 2 || routed[0] != sales || routed[1] != everything {
+		t.Fatalf("price question routed to %v, want sales and the recipient without rules", chatIDs(routed))
+	}
+
+	routed = routeRecipients(rules, recipients, &gotgbot.Message{Photo: []gotgbot.PhotoSize{{FileId: "photo"}}})
+	if len(routed) != 2 || routed[0] != support || routed[1] != everything {
+		t.Fatalf("photo routed to %v, want support and the recipient without rules", chatIDs(routed))
+	}
+
+	// Without a matching recipient every recipient gets the message
+	routed = routeRecipients(rules, []*models.Recipient{sales, support}, &gotgbot.Message{Text: "hello"})
+	if len(routed) != 2 {
+		t.Fatalf("unmatched message routed to %v, want every recipient", chatIDs(routed))
+	}
+
+	// The fallback recipient ignores its rules
+	fallback := &models.Recipient{ID: sales.ID, ChatID: -1, IsFallback: true}
+	routed = routeRecipients(rules, []*models.Recipient{fallback, support}, &gotgbot.Message{Text: "refund please"})
+	if len(routed) != 2 {
+		t.Fatalf("refund request routed to %v, want the fallback and support", chatIDs(routed))
+	}
+}
+
+func chatIDs(recipients []*models.Recipient) []int64 {
+	ids := make([]int64, 0, len(recipients))
+	for _, recipient := range recipients {
+		ids = append(ids, recipient.ChatID)
+	}
+	return ids
+}
@@ -0,0 +1,151 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxTopicNameLength is the longest forum topic name Telegram accepts
+const maxTopicNameLength = 128
+
+// sendToGuestTopic sends a guest message to a recipient, in the guest's topic
+// when the recipient group uses them. A topic staff closed is reopened, and one
+// they deleted is replaced, since the guest is writing again.
+func (f *Forwarder) sendToGuestTopic(
+	bot *gotgbot.Bot,
+	botID uuid.UUID,
+	guest *models.Guest,
+	recipient *models.Recipient,
+	message *gotgbot.Message,
+	header string,
+	private bool,
+) (int64, error) {
+	threadID := f.guestThread(bot, botID, guest, recipient, message.From)
+	messageID, err := f.sendGuestMessage(bot, recipient.ChatID, threadID, message, header, private)
+	if err == nil || threadID == 0 {
+		return messageID, err
+	}
+
+	switch errStr := err.Error(); {
+	case strings.Contains(errStr, "TOPIC_CLOSED"):
+		if _, reopenErr := bot.ReopenForumTopic(recipient.ChatID, threadID, nil); reopenErr != nil {
+			return 0, err
+		}
+	case strings.Contains(errStr, "message thread not found") || strings.Contains(errStr, "TOPIC_DELETED"):
+		f.forgetGuestThread(botID, guest, recipient.ChatID)
+		threadID = f.guestThread(bot, botID, guest, recipient, message.From)
+	default:
+		return 0, err
+	}
+	return f.sendGuestMessage(bot, recipient.ChatID, threadID, message, header, private)
+}
+
+// guestThread returns the guest's topic in the recipient group, creating it on
+// first use. It returns 0, the group's general topic, when the group has no
+// topics, the bot does not use them, or the topic could not be created.
+func (f *Forwarder) guestThread(bot *gotgbot.Bot, botID uuid.UUID, guest *models.Guest, recipient *models.Recipient, from *gotgbot.User) int64 {
+	if !recipient.IsForum {
+		return 0
+	}
+	botModel, err := f.botRepo.GetByID(botID)
+	if err != nil {
+		f.logger.Warn("Failed to get bot for guest topic",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return 0
+	}
+	if !botModel.GuestTopics {
+		return 0
+	}
+
+	topic, err := f.guestTopicRepo.GetByGuestAndChat(guest.ID, recipient.ChatID)
+	if err == nil {
+		return topic.ThreadID
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		f.logger.Warn("Failed to get guest topic",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("recipient_chat_id", recipient.ChatID),
+			zap.Error(err))
+		return 0
+	}
+
+	created, err := bot.CreateForumTopic(recipient.ChatID, topicName(botModel, guest, from), nil)
+	if err != nil {
+		f.logger.Warn("Failed to create guest topic",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("recipient_chat_id", recipient.ChatID),
+			zap.Error(err))
+		return 0
+	}
+	topic = &models.GuestTopic{
+		BotID:           botID,
+		GuestID:         guest.ID,
+		RecipientChatID: recipient.ChatID,
+		ThreadID:        created.MessageThreadId,
+	}
+	if err := f.guestTopicRepo.Create(topic); err != nil {
+		// Another message from the guest created the topic first; keep that one
+		if existing, getErr := f.guestTopicRepo.GetByGuestAndChat(guest.ID, recipient.ChatID); getErr == nil {
+			if _, err := bot.DeleteForumTopic(recipient.ChatID, created.MessageThreadId, nil); err != nil {
+				f.logger.Debug("Failed to delete duplicate guest topic", zap.Error(err))
+			}
+			return existing.ThreadID
+		}
+		f.logger.Warn("Failed to save guest topic",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("recipient_chat_id", recipient.ChatID),
+			zap.Error(err))
+	}
+
+	f.logger.Debug("Guest topic created",
+		zap.String("bot_id", botID.String()),
+		zap.Int64("recipient_chat_id", recipient.ChatID),
+		zap.Int64("thread_id", created.MessageThreadId))
+	return created.MessageThreadId
+}
+
+// forgetGuestThread drops the guest's topic in the recipient group so the next
+// message creates a new one
+func (f *Forwarder) forgetGuestThread(botID uuid.UUID, guest *models.Guest, recipientChatID int64) {
+	topic, err := f.guestTopicRepo.GetByGuestAndChat(guest.ID, recipientChatID)
+	if err != nil {
+		return
+	}
+	if err := f.guestTopicRepo.Delete(botID, topic.ID); err != nil {
+		f.logger.Warn("Failed to delete guest topic",
+			zap.String("bot_id", botID.String()),
+			zap.Int64("recipient_chat_id", recipientChatID),
+			zap.Error(err))
+	}
+}
+
+// topicName names a guest's topic the way their messages are labelled: by the
+// privacy header when the bot hides guest names, otherwise by name
+func topicName(bot *models.ForwarderBot, guest *models.Guest, from *gotgbot.User) string {
+	var name string
+	switch {
+	case bot.PrivacyMode:
+		name = bot.GuestHeader(guest)
+	case from != nil:
+		name = strings.TrimSpace(from.FirstName + " " + from.LastName)
+		if from.Username != "" {
+			name += " (@" + from.Username + ")"
+		}
+	}
+	if strings.TrimSpace(name) == "" {
+		name = fmt.Sprintf("Guest %d", guest.GuestUserID)
+	}
+	if runes := []rune(name); len(runes) > maxTopicNameLength {
+		name = string(runes[:maxTopicNameLength])
+	}
+	return name
+}
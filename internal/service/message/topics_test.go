@@ -0,0 +1,59 @@
+package message
+
+import (
+	"strings"
+	"testing"
+
+	"go-telegram-forwarder-bot/internal/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func TestTopicName(t *testing.T) {
+	guest := &models.Guest{GuestUserID: 42}
+	tests := []struct {
+		name string
+		bot  *models.ForwarderBot
+		from *gotgbot.User
+		want string
+	}{
+		{
+			name: "name and username",
+			bot:  &models.ForwarderBot{},
+			from: &gotgbot.User{Id: 42, FirstName: "Ada", LastName: "Lovelace", Username: "ada"},
+			want: "Ada Lovelace (@ada)",
+		},
+		{
+			name: "first name only",
+			bot:  &models.ForwarderBot{},
+			from: &gotgbot.User{Id: 42, FirstName: "Ada"},
+			want: "Ada",
+		},
+		{
+			name: "no sender",
+			bot:  &models.ForwarderBot{},
+			want: "Guest 42",
+		},
+		{
+			name: "privacy mode hides the name",
+			bot:  &models.ForwarderBot{PrivacyMode: true, PrivacyHeader: "Customer"},
+			from: &gotgbot.User{Id: 42, FirstName: "Ada", Username: "ada"},
+			want: "Customer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topicName(tt.bot, guest, tt.from); got != tt.want {
+				t.Errorf("topicName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopicNameIsTruncated(t *testing.T) {
+	from := &gotgbot.User{Id: 42, FirstName: strings.Repeat("é", 200)}
+	name := topicName(&models.ForwarderBot{}, &models.Guest{GuestUserID: 42}, from)
+	if n := len([]rune(name)); n != maxTopicNameLength {
+		t.Errorf("topic name has %d characters, want %d", n, maxTopicNameLength)
+	}
+}
@@ -0,0 +1,115 @@
+// Package ocr reads text in images guests send, such as screenshots, so the
+// text can be checked by the moderation rules and shown to recipients. It is
+// only used when enabled in the config.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// Provider recognizes the text in an image
+type Provider interface {
+	Recognize(ctx context.Context, image []byte, filename string) (string, error)
+}
+
+// tesseractProvider runs the local tesseract binary, so images never leave the server
+type tesseractProvider struct {
+	path     string
+	language string
+}
+
+func (p *tesseractProvider) Recognize(ctx context.Context, image []byte, _ string) (string, error) {
+	args := []string{"stdin", "stdout"}
+	if p.language != "" {
+		args = append(args, "-l", p.language)
+	}
+	cmd := exec.CommandContext(ctx, p.path, args...)
+	cmd.Stdin = bytes.NewReader(image)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("tesseract failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("tesseract failed: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// httpProvider posts the image as the multipart field "file" to an OCR service
+// that answers with {"text": "..."}
+type httpProvider struct {
+	endpoint   string
+	apiKey     string
+	language   string
+	httpClient *http.Client
+}
+
+type recognizeResponse struct {
+	Text  string `json:"text"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *httpProvider) Recognize(ctx context.Context, image []byte, filename string) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+	if _, err := file.Write(image); err != nil {
+		return "", fmt.Errorf("failed to write image: %w", err)
+	}
+	if p.language != "" {
+		if err := form.WriteField("language", p.language); err != nil {
+			return "", fmt.Errorf("failed to write form field: %w", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var response recognizeResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("failed to decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if response.Error != nil {
+		return "", fmt.Errorf("provider error (status %d): %s", resp.StatusCode, response.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return strings.TrimSpace(response.Text), nil
+}
@@ -0,0 +1,113 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+// maxImageSize is the largest image that is downloaded for recognition
+const maxImageSize = 10 << 20
+
+// errNoTesseract explains a missing binary better than exec's "file not found"
+var errNoTesseract = errors.New("tesseract binary not found; install tesseract-ocr or set ocr.tesseract_path")
+
+// Service downloads images from Telegram and recognizes the text in them
+type Service struct {
+	provider Provider
+	timeout  time.Duration
+	logger   *zap.Logger
+}
+
+// NewService creates the service with the provider selected by ocr.provider
+func NewService(cfg *config.OCRConfig, proxy *config.ProxyConfig, logger *zap.Logger) (*Service, error) {
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+
+	var provider Provider
+	switch cfg.Provider {
+	case "", "tesseract":
+		path, err := exec.LookPath(cfg.TesseractPath)
+		if err != nil {
+			return nil, errNoTesseract
+		}
+		provider = &tesseractProvider{
+			path:     path,
+			language: cfg.Language,
+		}
+	case "http":
+		httpClient, err := utils.CreateHTTPClientWithProxy(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		httpClient.Timeout = timeout
+		provider = &httpProvider{
+			endpoint:   cfg.Endpoint,
+			apiKey:     cfg.APIKey,
+			language:   cfg.Language,
+			httpClient: httpClient,
+		}
+	default:
+		return nil, fmt.Errorf("unknown OCR provider: %s", cfg.Provider)
+	}
+
+	return &Service{
+		provider: provider,
+		timeout:  timeout,
+		logger:   logger,
+	}, nil
+}
+
+// RecognizeImage returns the text in a photo or image file. Messages without
+// an image, and images too large to download, give no text.
+func (s *Service) RecognizeImage(ctx context.Context, bot *gotgbot.Bot, msg *gotgbot.Message) (string, error) {
+	fileID, filename := imageFile(msg)
+	if fileID == "" {
+		return "", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	image, err := utils.DownloadFile(ctx, bot, fileID, maxImageSize)
+	if err != nil {
+		return "", err
+	}
+
+	text, err := s.provider.Recognize(ctx, image, filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to recognize text: %w", err)
+	}
+	s.logger.Debug("Image text recognized",
+		zap.Int("image_bytes", len(image)),
+		zap.Int("text_length", len(text)),
+		zap.Duration("took", time.Since(start)))
+	return text, nil
+}
+
+// imageFile returns the file to recognize: the largest size of a photo that can
+// be downloaded, or a document sent as an image file
+func imageFile(msg *gotgbot.Message) (fileID string, filename string) {
+	for i := len(msg.Photo) - 1; i >= 0; i-- {
+		if msg.Photo[i].FileSize <= maxImageSize {
+			return msg.Photo[i].FileId, "photo.jpg"
+		}
+	}
+	if doc := msg.Document; doc != nil && strings.HasPrefix(doc.MimeType, "image/") && doc.FileSize <= maxImageSize {
+		filename := doc.FileName
+		if filename == "" {
+			filename = "image"
+		}
+		return doc.FileId, filename
+	}
+	return "", ""
+}
@@ -0,0 +1,235 @@
+// Package officehours evaluates the weekly office hours of a ForwarderBot
+package officehours
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/utils"
+)
+
+// dayNames are the day abbreviations used in schedules, indexed by time.Weekday
+var dayNames = [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+var everyDay = [7]bool{true, true, true, true, true, true, true}
+
+// Schedule is a daily opening window on some days of the week, such as
+// "09:00-18:00 Mon-Fri". A window that ends at or before its start runs past
+// midnight and belongs to the day it starts on.
+type Schedule struct {
+	start    int     // Minutes after midnight
+	end      int     // Minutes after midnight, up to 24:00
+	days     [7]bool // Indexed by time.Weekday
+	location *time.Location
+}
+
+// Parse reads a schedule of the form "HH:MM-HH:MM [days] [timezone]". Days
+// are ranges or lists of English day names such as "Mon-Fri", "Sat,Sun" or
+// "Fri-Mon", or "daily", the default. The timezone is an IANA name; without
+// one the caller's timezone is used.
+func Parse(spec string) (*Schedule, error) {
+	fields := strings.Fields(spec)
+	for i, field := range fields {
+		fields[i] = strings.TrimSuffix(field, ",")
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("office hours are empty")
+	}
+
+	schedule := &Schedule{}
+	window := strings.SplitN(fields[0], "-", 2)
+	if len(window) != 2 {
+		return nil, fmt.Errorf("expected hours like 09:00-18:00, got %q", fields[0])
+	}
+	var err error
+	if schedule.start, err = parseClock(window[0], false); err != nil {
+		return nil, err
+	}
+	if schedule.end, err = parseClock(window[1], true); err != nil {
+		return nil, err
+	}
+	if schedule.start == schedule.end {
+		return nil, fmt.Errorf("office hours must not start and end at the same time")
+	}
+
+	schedule.days = everyDay
+	rest := fields[1:]
+	if len(rest) > 0 {
+		if days, ok := parseDays(rest[0]); ok {
+			schedule.days = days
+			rest = rest[1:]
+		}
+	}
+	switch len(rest) {
+	case 0:
+	case 1:
+		loc, err := utils.LoadTimezone(rest[0])
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither days like Mon-Fri nor a timezone", rest[0])
+		}
+		schedule.location = loc
+	default:
+		return nil, fmt.Errorf("unexpected %q after the office hours", strings.Join(rest[1:], " "))
+	}
+	return schedule, nil
+}
+
+// parseClock reads "HH:MM"; 24:00 is only allowed as the end of a window
+func parseClock(value string, end bool) (int, error) {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(value), ":")
+	h, hErr := strconv.Atoi(hours)
+	m, mErr := strconv.Atoi(minutes)
+	if !ok || hErr != nil || mErr != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && (m != 0 || !end)) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return h*60 + m, nil
+}
+
+// parseDays reads "daily" or a comma-separated list of days and day ranges
+func parseDays(value string) ([7]bool, bool) {
+	var days [7]bool
+	if strings.EqualFold(value, "daily") {
+		return everyDay, true
+	}
+	for _, part := range strings.Split(value, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, ok := parseDay(first)
+		if !ok {
+			return days, false
+		}
+		to := from
+		if isRange {
+			if to, ok = parseDay(last); !ok {
+				return days, false
+			}
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return days, true
+}
+
+// parseDay reads an English day name, abbreviated to at least three letters
+func parseDay(value string) (time.Weekday, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) < 3 {
+		return 0, false
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.HasPrefix(strings.ToLower(day.String()), value) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// String returns the schedule in the form Parse reads, e.g. "09:00-18:00 Mon-Fri"
+func (s *Schedule) String() string {
+	text := fmt.Sprintf("%02d:%02d-%02d:%02d %s", s.start/60, s.start%60, s.end/60, s.end%60, s.daysString())
+	if s.location != nil {
+		text += " " + s.location.String()
+	}
+	return text
+}
+
+// daysString names the schedule's days, joining runs of days into ranges
+func (s *Schedule) daysString() string {
+	if s.days == everyDay {
+		return "daily"
+	}
+	// Start at the beginning of a run of days so one like Fri-Mon is not split at Sunday
+	first := -1
+	for day := 0; day < 7; day++ {
+		if s.days[day] && !s.days[(day+6)%7] {
+			first = day
+			break
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	var parts []string
+	for i := 0; i < 7; i++ {
+		day := (first + i) % 7
+		if !s.days[day] {
+			continue
+		}
+		last := day
+		for i+1 < 7 && s.days[(first+i+1)%7] {
+			i++
+			last = (first + i) % 7
+		}
+		switch {
+		case last == day:
+			parts = append(parts, dayNames[day])
+		case (day+1)%7 == last:
+			parts = append(parts, dayNames[day], dayNames[last])
+		default:
+			parts = append(parts, dayNames[day]+"-"+dayNames[last])
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Location returns the schedule's timezone, or fallback if it has none
+func (s *Schedule) Location(fallback *time.Location) *time.Location {
+	if s.location != nil {
+		return s.location
+	}
+	return fallback
+}
+
+// window returns the opening window that starts on the day of t
+func (s *Schedule) window(t time.Time) (time.Time, time.Time) {
+	year, month, day := t.Date()
+	opens := time.Date(year, month, day, s.start/60, s.start%60, 0, 0, t.Location())
+	endDay := day
+	if s.end <= s.start {
+		endDay++
+	}
+	closes := time.Date(year, month, endDay, s.end/60, s.end%60, 0, 0, t.Location())
+	return opens, closes
+}
+
+// IsOpen reports whether t is within office hours, using fallback as the
+// timezone if the schedule has none
+func (s *Schedule) IsOpen(t time.Time, fallback *time.Location) bool {
+	t = t.In(s.Location(fallback))
+	// A window that started yesterday may still be open
+	for _, day := range []time.Time{t, t.AddDate(0, 0, -1)} {
+		if !s.days[day.Weekday()] {
+			continue
+		}
+		opens, closes := s.window(day)
+		if !t.Before(opens) && t.Before(closes) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpening returns when office hours next begin after t, or t itself if
+// they are open at t
+func (s *Schedule) NextOpening(t time.Time, fallback *time.Location) time.Time {
+	if s.IsOpen(t, fallback) {
+		return t
+	}
+	t = t.In(s.Location(fallback))
+	for i := 0; i <= 7; i++ {
+		day := t.AddDate(0, 0, i)
+		if !s.days[day.Weekday()] {
+			continue
+		}
+		if opens, _ := s.window(day); opens.After(t) {
+			return opens
+		}
+	}
+	return t
+}
@@ -0,0 +1,136 @@
+package officehours
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"09:00-18:00", "09:00-18:00 daily"},
+		{"9:00-18:00 Mon-Fri", "09:00-18:00 Mon-Fri"},
+		{"09:00-18:00 mon-fri, Asia/Shanghai", "09:00-18:00 Mon-Fri Asia/Shanghai"},
+		{"09:00-18:00 UTC", "09:00-18:00 daily UTC"},
+		{"10:00-14:00 Sat,Sun", "10:00-14:00 Sat,Sun"},
+		{"10:00-14:00 Fri-Mon", "10:00-14:00 Fri-Mon"},
+		{"10:00-14:00 Mon,Wed,Fri", "10:00-14:00 Mon,Wed,Fri"},
+		{"10:00-14:00 Monday-Thursday,Sat", "10:00-14:00 Mon-Thu,Sat"},
+		{"22:00-06:00 daily", "22:00-06:00 daily"},
+		{"00:00-24:00 Mon-Sun", "00:00-24:00 daily"},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+		// String must be readable by Parse again
+		again, err := Parse(schedule.String())
+		if err != nil || again.String() != tt.want {
+			t.Errorf("Parse(%q) does not round-trip: %v, %v", schedule.String(), again, err)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"09:00",
+		"9-18",
+		"09:00-09:00",
+		"24:00-18:00",
+		"09:00-24:30",
+		"09:60-18:00",
+		"09:00-18:00 Someday",
+		"09:00-18:00 Mon-Fri Mars/Olympus",
+		"09:00-18:00 Mon-Fri UTC extra",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) accepted an invalid schedule", spec)
+		}
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	schedule, err := Parse("09:00-18:00 Mon-Fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2024-01-01 is a Monday
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2024, 1, 1, 8, 59, 0, 0, time.UTC), false},
+		{time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 1, 1, 17, 59, 0, 0, time.UTC), true},
+		{time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC), false},
+		{time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC), false}, // Saturday
+	}
+	for _, tt := range tests {
+		if got := schedule.IsOpen(tt.at, time.UTC); got != tt.want {
+			t.Errorf("IsOpen(%s) = %v, want %v", tt.at.Format(time.RFC1123), got, tt.want)
+		}
+	}
+
+	// The fallback timezone only applies when the schedule has none
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	if !schedule.IsOpen(time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC), berlin) {
+		t.Error("08:30 UTC is 09:30 in Berlin and should be open")
+	}
+	withZone, _ := Parse("09:00-18:00 Mon-Fri UTC")
+	if withZone.IsOpen(time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC), berlin) {
+		t.Error("the schedule's own timezone should win over the fallback")
+	}
+}
+
+func TestIsOpenOvernight(t *testing.T) {
+	schedule, err := Parse("22:00-06:00 Fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2024-01-05 is a Friday
+	if !schedule.IsOpen(time.Date(2024, 1, 5, 23, 0, 0, 0, time.UTC), time.UTC) {
+		t.Error("Friday 23:00 should be open")
+	}
+	if !schedule.IsOpen(time.Date(2024, 1, 6, 5, 0, 0, 0, time.UTC), time.UTC) {
+		t.Error("Saturday 05:00 belongs to Friday's window and should be open")
+	}
+	if schedule.IsOpen(time.Date(2024, 1, 5, 5, 0, 0, 0, time.UTC), time.UTC) {
+		t.Error("Friday 05:00 belongs to Thursday's window and should be closed")
+	}
+}
+
+func TestNextOpening(t *testing.T) {
+	schedule, err := Parse("09:00-18:00 Mon-Fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		at   time.Time
+		want time.Time
+	}{
+		// Open now
+		{time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+		// Before opening on a weekday
+		{time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+		// After closing on a weekday
+		{time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+		// Friday evening waits for Monday
+		{time.Date(2024, 1, 5, 19, 0, 0, 0, time.UTC), time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := schedule.NextOpening(tt.at, time.UTC); !got.Equal(tt.want) {
+			t.Errorf("NextOpening(%s) = %s, want %s", tt.at.Format(time.RFC1123), got.Format(time.RFC1123), tt.want.Format(time.RFC1123))
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// quotaWarningPercent is the usage at which the manager gets a soft warning
+const quotaWarningPercent = 80
+
+// QuotaEnforcer tracks monthly message usage per bot and enforces
+// ForwarderBot.MonthlyQuota. Usage is tracked for every bot; only bots with a
+// quota are limited.
+type QuotaEnforcer struct {
+	botRepo         repository.BotRepository
+	usageRepo       repository.BotUsageRepository
+	managerNotifier *ManagerNotifier
+	logger          *zap.Logger
+}
+
+func NewQuotaEnforcer(
+	botRepo repository.BotRepository,
+	usageRepo repository.BotUsageRepository,
+	managerNotifier *ManagerNotifier,
+	logger *zap.Logger,
+) *QuotaEnforcer {
+	return &QuotaEnforcer{
+		botRepo:         botRepo,
+		usageRepo:       usageRepo,
+		managerNotifier: managerNotifier,
+		logger:          logger,
+	}
+}
+
+// CurrentMonth returns the BotUsage.Month of now
+func CurrentMonth() string {
+	return time.Now().Format(models.BotUsageMonthFormat)
+}
+
+// Consume counts one forwarded message for the bot. It returns false when the
+// monthly quota is used up and the message must not be forwarded.
+func (q *QuotaEnforcer) Consume(ctx context.Context, botID uuid.UUID) (bool, error) {
+	bot, err := q.botRepo.GetByID(botID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bot: %w", err)
+	}
+
+	month := CurrentMonth()
+	counted, err := q.usageRepo.TryIncrement(botID, month, bot.MonthlyQuota)
+	if err != nil {
+		return false, fmt.Errorf("failed to record usage: %w", err)
+	}
+	if bot.MonthlyQuota <= 0 {
+		return true, nil
+	}
+
+	if !counted {
+		q.notifyExceeded(ctx, bot, month)
+		return false, nil
+	}
+
+	usage, err := q.usageRepo.GetByBotIDAndMonth(botID, month)
+	if err != nil {
+		q.logger.Warn("Failed to read bot usage",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return true, nil
+	}
+	if usage.MessageCount >= bot.MonthlyQuota {
+		// This was the last allowed message
+		q.notifyExceeded(ctx, bot, month)
+	} else if usage.MessageCount*100 >= bot.MonthlyQuota*quotaWarningPercent {
+		q.notifyWarning(ctx, bot, month, usage.MessageCount)
+	}
+	return true, nil
+}
+
+// GetUsage returns the bot's usage in the current month
+func (q *QuotaEnforcer) GetUsage(botID uuid.UUID) (*models.BotUsage, error) {
+	return q.usageRepo.GetByBotIDAndMonth(botID, CurrentMonth())
+}
+
+// QuotaChanged resets the warning state after a superuser changed a bot's quota,
+// so the new limit is warned about and enforced with fresh notifications
+func (q *QuotaEnforcer) QuotaChanged(botID uuid.UUID) error {
+	return q.usageRepo.ClearFlags(botID, CurrentMonth())
+}
+
+func (q *QuotaEnforcer) notifyWarning(ctx context.Context, bot *models.ForwarderBot, month string, used int64) {
+	first, err := q.usageRepo.MarkWarned(bot.ID, month)
+	if err != nil || !first {
+		return
+	}
+	q.logger.Info("Bot reached quota warning threshold",
+		zap.String("bot_id", bot.ID.String()),
+		zap.Int64("used", used),
+		zap.Int64("quota", bot.MonthlyQuota))
+	q.notify(ctx, bot.ID, fmt.Sprintf(
+		"*Quota Warning*\n\n"+
+			"Your bot has used %d of its %d monthly messages (%d%%).\n"+
+			"Forwarding pauses when the quota is reached.",
+		used, bot.MonthlyQuota, used*100/bot.MonthlyQuota))
+}
+
+func (q *QuotaEnforcer) notifyExceeded(ctx context.Context, bot *models.ForwarderBot, month string) {
+	first, err := q.usageRepo.MarkExceeded(bot.ID, month)
+	if err != nil || !first {
+		return
+	}
+	q.logger.Warn("Bot reached monthly quota, forwarding paused",
+		zap.String("bot_id", bot.ID.String()),
+		zap.Int64("quota", bot.MonthlyQuota))
+	q.notify(ctx, bot.ID, fmt.Sprintf(
+		"*Quota Reached*\n\n"+
+			"Your bot has used all %d monthly messages. Forwarding is paused until next month "+
+			"or until a superuser raises the quota.",
+		bot.MonthlyQuota))
+}
+
+func (q *QuotaEnforcer) notify(ctx context.Context, botID uuid.UUID, message string) {
+	if q.managerNotifier == nil {
+		return
+	}
+	if err := q.managerNotifier.NotifyManager(ctx, botID, message); err != nil {
+		q.logger.Warn("Failed to notify manager about quota",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+	}
+}
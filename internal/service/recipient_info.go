@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// RecipientInfoRefresher keeps the chat title and username stored on recipients up to date
+type RecipientInfoRefresher struct {
+	recipientRepo repository.RecipientRepository
+	logger        *zap.Logger
+}
+
+func NewRecipientInfoRefresher(
+	recipientRepo repository.RecipientRepository,
+	logger *zap.Logger,
+) *RecipientInfoRefresher {
+	return &RecipientInfoRefresher{
+		recipientRepo: recipientRepo,
+		logger:        logger,
+	}
+}
+
+// Refresh fetches the recipient's chat from Telegram and stores its title, username
+// and whether it has topics enabled
+func (r *RecipientInfoRefresher) Refresh(ctx context.Context, bot *gotgbot.Bot, recipient *models.Recipient) error {
+	chat, err := bot.GetChat(recipient.ChatID, nil)
+	if err != nil {
+		return err
+	}
+
+	title := chat.Title
+	if title == "" {
+		title = strings.TrimSpace(chat.FirstName + " " + chat.LastName)
+	}
+	now := time.Now()
+	recipient.Title = optionalString(title)
+	recipient.Username = optionalString(chat.Username)
+	recipient.IsForum = chat.IsForum
+	recipient.InfoUpdatedAt = &now
+
+	return r.recipientRepo.Update(recipient)
+}
+
+// RefreshAll refreshes every recipient of a bot. Failures are logged and skipped;
+// unreachable chats are handled by the group monitor.
+func (r *RecipientInfoRefresher) RefreshAll(ctx context.Context, bot *gotgbot.Bot, botID uuid.UUID) {
+	recipients, err := r.recipientRepo.GetByBotID(botID)
+	if err != nil {
+		r.logger.Warn("Failed to get recipients for info refresh",
+			zap.String("bot_id", botID.String()),
+			zap.Error(err))
+		return
+	}
+
+	for _, recipient := range recipients {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := r.Refresh(ctx, bot, recipient); err != nil {
+			r.logger.Debug("Failed to refresh recipient info",
+				zap.String("bot_id", botID.String()),
+				zap.Int64("chat_id", recipient.ChatID),
+				zap.Error(err))
+		}
+	}
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// InvalidRecipientError explains why a chat cannot be added as a recipient
+type InvalidRecipientError struct {
+	Reason string
+}
+
+func (e *InvalidRecipientError) Error() string {
+	return e.Reason
+}
+
+// RecipientValidator checks chats before they are added as recipients, so a
+// typo or a guest's ID does not make the bot send messages back where they
+// came from
+type RecipientValidator struct {
+	guestRepo    repository.GuestRepository
+	userRepo     repository.UserRepository
+	botAdminRepo repository.BotAdminRepository
+	logger       *zap.Logger
+}
+
+func NewRecipientValidator(
+	guestRepo repository.GuestRepository,
+	userRepo repository.UserRepository,
+	botAdminRepo repository.BotAdminRepository,
+	logger *zap.Logger,
+) *RecipientValidator {
+	return &RecipientValidator{
+		guestRepo:    guestRepo,
+		userRepo:     userRepo,
+		botAdminRepo: botAdminRepo,
+		logger:       logger,
+	}
+}
+
+// Validate checks that chatID can be a recipient of the bot and returns its
+// type. It rejects the bot itself, chats Telegram does not know or the bot
+// cannot reach, and guests of the bot other than its manager and admins. b is
+// the running bot and may be nil, in which case the chat is not looked up and
+// its type is guessed from the sign of the ID. Errors other than
+// *InvalidRecipientError are lookup failures.
+func (v *RecipientValidator) Validate(b *gotgbot.Bot, forwarderBot *models.ForwarderBot, chatID int64) (models.RecipientType, error) {
+	if chatID == 0 {
+		return "", &InvalidRecipientError{Reason: "0 is not a chat ID"}
+	}
+	if (b != nil && chatID == b.Id) || (forwarderBot.TelegramBotID != 0 && chatID == forwarderBot.TelegramBotID) {
+		return "", &InvalidRecipientError{Reason: "this is the bot itself, which cannot send messages to itself"}
+	}
+
+	recipientType := models.RecipientTypeUser
+	if chatID < 0 {
+		recipientType = models.RecipientTypeGroup
+	}
+	if b != nil {
+		chat, err := b.GetChat(chatID, nil)
+		if err != nil {
+			return "", &InvalidRecipientError{Reason: fmt.Sprintf(
+				"the bot cannot reach this chat (%v); users must start the bot first, and groups and channels must add it", err)}
+		}
+		if chat.Type != "private" {
+			recipientType = models.RecipientTypeGroup
+		} else {
+			recipientType = models.RecipientTypeUser
+		}
+	}
+
+	if recipientType == models.RecipientTypeUser {
+		if err := v.checkNotGuest(forwarderBot, chatID); err != nil {
+			return "", err
+		}
+	}
+	return recipientType, nil
+}
+
+// checkNotGuest rejects users who write to the bot as guests, since their own
+// messages would be forwarded back to them. The manager and admins may write
+// to the bot themselves, e.g. to try it out, and still be recipients.
+func (v *RecipientValidator) checkNotGuest(forwarderBot *models.ForwarderBot, telegramUserID int64) error {
+	if _, err := v.guestRepo.GetByBotIDAndUserID(forwarderBot.ID, telegramUserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get guest: %w", err)
+	}
+
+	if forwarderBot.Manager.TelegramUserID == telegramUserID {
+		return nil
+	}
+	user, err := v.userRepo.GetByTelegramUserID(telegramUserID)
+	if err == nil && user != nil {
+		isAdmin, err := v.botAdminRepo.IsAdmin(forwarderBot.ID, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check admin: %w", err)
+		}
+		if isAdmin {
+			return nil
+		}
+	}
+
+	v.logger.Debug("Rejected guest as recipient",
+		zap.String("bot_id", forwarderBot.ID.String()),
+		zap.Int64("chat_id", telegramUserID))
+	return &InvalidRecipientError{Reason: "this user is a guest of the bot, who would get their own messages back; add their admin rights first if they are staff"}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/database"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// redisCheckInterval is how often the Redis connection is checked
+const redisCheckInterval = 30 * time.Second
+
+// RedisConnection owns the Redis client. It checks the connection
+// periodically, reconnects when it is lost and hands the current client to
+// every dependent registered with OnChange. While Redis is unreachable the
+// client is nil and dependents fall back to their non-Redis behavior.
+type RedisConnection struct {
+	cfg           config.RedisConfig
+	errorNotifier *ErrorNotifier
+	logger        *zap.Logger
+
+	mu        sync.RWMutex
+	client    redis.UniversalClient
+	listeners []func(redis.UniversalClient)
+}
+
+func NewRedisConnection(client redis.UniversalClient, cfg config.RedisConfig, logger *zap.Logger) *RedisConnection {
+	return &RedisConnection{
+		cfg:    cfg,
+		client: client,
+		logger: logger,
+	}
+}
+
+// SetErrorNotifier enables superuser alerts when reconnecting fails
+func (c *RedisConnection) SetErrorNotifier(errorNotifier *ErrorNotifier) {
+	c.errorNotifier = errorNotifier
+}
+
+// Client returns the current Redis client, or nil while Redis is unavailable
+func (c *RedisConnection) Client() redis.UniversalClient {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client
+}
+
+// OnChange registers fn to receive the client whenever it is replaced after a
+// reconnect, or nil when Redis is lost
+func (c *RedisConnection) OnChange(fn func(redis.UniversalClient)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// Monitor checks the connection until ctx is done. A lost connection is
+// retried three times before superusers are alerted; after that a new
+// connection is attempted on every check until Redis is back.
+func (c *RedisConnection) Monitor(ctx context.Context) {
+	ticker := time.NewTicker(redisCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.check(ctx)
+		}
+	}
+}
+
+func (c *RedisConnection) check(ctx context.Context) {
+	client := c.Client()
+	if client == nil {
+		// Degraded mode: try to get Redis back
+		newClient, err := database.ConnectRedis(c.cfg)
+		if err != nil {
+			c.logger.Debug("Redis still unreachable", zap.Error(err))
+			return
+		}
+		c.logger.Info("Redis connection restored")
+		c.setClient(newClient)
+		return
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	err := client.Ping(pingCtx).Err()
+	cancel()
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+
+	c.logger.Warn("Redis connection lost, attempting to reconnect",
+		zap.Error(err))
+
+	newClient, retryErr := database.RetryRedisConnection(c.cfg, 3, 10*time.Second)
+	if retryErr != nil {
+		c.logger.Error("Failed to reconnect to Redis after retries",
+			zap.Error(retryErr))
+		if c.errorNotifier != nil {
+			c.errorNotifier.NotifyCriticalError(ctx, ErrorTypeRedis, retryErr,
+				"Redis connection lost and reconnection failed after 3 retries")
+		}
+		// Continue without Redis (degraded mode)
+		c.setClient(nil)
+		return
+	}
+
+	c.logger.Info("Redis reconnected successfully")
+	c.setClient(newClient)
+}
+
+// setClient replaces the client, closes the old one and notifies dependents
+func (c *RedisConnection) setClient(client redis.UniversalClient) {
+	c.mu.Lock()
+	old := c.client
+	c.client = client
+	listeners := append([]func(redis.UniversalClient){}, c.listeners...)
+	c.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(client)
+	}
+
+	if old != nil {
+		if err := old.Close(); err != nil {
+			c.logger.Debug("Failed to close old Redis client", zap.Error(err))
+		}
+	}
+}
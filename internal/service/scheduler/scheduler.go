@@ -0,0 +1,143 @@
+// Package scheduler runs periodic background workers with configurable
+// intervals, a random initial delay and per-worker enable flags.
+package scheduler
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/service/lock"
+
+	"go.uber.org/zap"
+)
+
+// JobFunc is a single run of a periodic worker
+type JobFunc func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	jitter   time.Duration
+	run      JobFunc
+}
+
+// Scheduler runs registered jobs on their own intervals. Each run is guarded
+// by a distributed lock so only one instance executes a given pass.
+type Scheduler struct {
+	jobs   []job
+	locker lock.Locker
+	logger *zap.Logger
+	wg     sync.WaitGroup
+}
+
+func New(locker lock.Locker, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		locker: locker,
+		logger: logger,
+	}
+}
+
+// Register adds a job using its worker configuration. Disabled workers are skipped.
+// Must be called before Start.
+func (s *Scheduler) Register(name string, cfg config.WorkerConfig, run JobFunc) {
+	if !cfg.Enabled {
+		s.logger.Info("Worker disabled by configuration", zap.String("worker", name))
+		return
+	}
+	s.jobs = append(s.jobs, job{
+		name:     name,
+		interval: time.Duration(cfg.IntervalSeconds) * time.Second,
+		jitter:   time.Duration(cfg.JitterSeconds) * time.Second,
+		run:      run,
+	})
+}
+
+// Start launches all registered jobs. Jobs stop when ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func(j job) {
+			defer s.wg.Done()
+			s.runLoop(ctx, j)
+		}(j)
+	}
+}
+
+// Wait blocks until all jobs have stopped
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) runLoop(ctx context.Context, j job) {
+	delay := initialDelay(j.jitter)
+	s.logger.Info("Worker scheduled",
+		zap.String("worker", j.name),
+		zap.Duration("interval", j.interval),
+		zap.Duration("initial_delay", delay))
+
+	timer := time.NewTimer(delay)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return
+	case <-timer.C:
+	}
+
+	s.runOnce(ctx, j)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, j)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, j job) {
+	// The lock is held for most of the interval so other instances skip this pass
+	acquired, err := lock.AcquireForRun(ctx, s.locker, "worker:"+j.name, lockTTL(j.interval))
+	if err != nil {
+		s.logger.Warn("Failed to acquire worker lock, skipping run",
+			zap.String("worker", j.name),
+			zap.Error(err))
+		return
+	}
+	if !acquired {
+		s.logger.Debug("Worker lock held by another instance, skipping run",
+			zap.String("worker", j.name))
+		return
+	}
+
+	start := time.Now()
+	if err := j.run(ctx); err != nil {
+		s.logger.Error("Worker run failed",
+			zap.String("worker", j.name),
+			zap.Error(err))
+		return
+	}
+	s.logger.Debug("Worker run completed",
+		zap.String("worker", j.name),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// initialDelay returns a random delay in [0, jitter)
+func initialDelay(jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(jitter)))
+}
+
+// lockTTL keeps the lock slightly shorter than the interval so the next
+// pass on any instance can acquire it
+func lockTTL(interval time.Duration) time.Duration {
+	return interval - interval/10
+}
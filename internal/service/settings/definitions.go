@@ -0,0 +1,537 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/service/officehours"
+	"go-telegram-forwarder-bot/internal/utils"
+)
+
+// Limits shared by the settings registry and the ForwarderBot commands
+const (
+	MaxGuestRateLimit = 30
+	MaxGuestRateBurst = 100
+	MaxAPIRateLimit   = 30    // Telegram's limit for one bot sending to many chats
+	MaxPaywallPrice   = 10000 // Telegram Stars
+	MaxPaywallCredits = 1000
+
+	MaxPrivacyHeaderLength = 100
+
+	MaxMappingRetentionDays = 3650
+)
+
+// Values of KeyMappingRetention besides a number of days
+const (
+	RetentionDefault = "default" // The instance's retention.message_mapping_days
+	RetentionForever = "forever"
+)
+
+// Kind is how a setting's value is shown and changed in the settings menu
+type Kind string
+
+const (
+	// KindBool is "true" or "false" and is toggled with a button
+	KindBool Kind = "bool"
+	// KindChoice is one of the definition's Choices
+	KindChoice Kind = "choice"
+	// KindText is free text; it is shown in the menu and changed with a ForwarderBot command
+	KindText Kind = "text"
+)
+
+// Setting keys. Keys are grouped by category with a "category." prefix.
+const (
+	KeyGroupGuests      = "guests.group_guests"
+	KeyAutoLeave        = "guests.auto_leave"
+	KeyRateLimit        = "guests.rate_limit"
+	KeyRateBurst        = "guests.rate_burst"
+	KeyQueueNotice      = "guests.queue_notice"
+	KeyOfficeHours      = "guests.office_hours"
+	KeyAwayMessage      = "guests.away_message"
+	KeyTranscribe       = "guests.transcribe_voice"
+	KeyPrivacyMode      = "guests.privacy_mode"
+	KeyPrivacyHeader    = "guests.privacy_header"
+	KeyGuestTopics      = "guests.topics"
+	KeyProfileCard      = "guests.profile_card"
+	KeyReactionSync     = "guests.sync_reactions"
+	KeyWelcomeText      = "welcome.text"
+	KeyWelcomeTextB     = "welcome.text_b"
+	KeyTermsText        = "welcome.terms"
+	KeyGuestHelp        = "welcome.guest_help"
+	KeyAlertKeywords    = "filters.alert_keywords"
+	KeyReadImages       = "filters.read_images"
+	KeyScanFiles        = "filters.scan_files"
+	KeyPaywallMode      = "paywall.mode"
+	KeyPaywallPrice     = "paywall.price"
+	KeyPaywallCredits   = "paywall.credits"
+	KeyTimezone         = "general.timezone"
+	KeyDryRun           = "general.dry_run"
+	KeyMappingRetention = "general.mapping_retention"
+	KeyAPIRateLimit     = "general.api_rate_limit"
+	KeyDigest           = "notify.digest"
+)
+
+// Category groups related settings into one page of the settings menu
+type Category struct {
+	ID    string
+	Title string
+}
+
+// Categories in the order they are shown in the settings menu
+var Categories = []Category{
+	{ID: "general", Title: "General"},
+	{ID: "guests", Title: "Guests"},
+	{ID: "welcome", Title: "Welcome & Terms"},
+	{ID: "filters", Title: "Filters & Alerts"},
+	{ID: "paywall", Title: "Paywall"},
+	{ID: "notify", Title: "Notifications"},
+}
+
+// Choice is one allowed value of a KindChoice setting
+type Choice struct {
+	Value string
+	Label string
+}
+
+// Definition describes one per-bot setting
+type Definition struct {
+	Key         string
+	Category    string
+	Label       string
+	Description string
+	Kind        Kind
+	Default     string
+	Choices     []Choice
+	// Command is the ForwarderBot command that changes a KindText setting
+	Command string
+
+	// validate checks values beyond what Kind allows, e.g. on import
+	validate func(value string) error
+
+	// get and set access settings that predate BotSetting and are still
+	// stored on ForwarderBot; settings without them live in BotSetting
+	get func(bot *models.ForwarderBot) string
+	set func(bot *models.ForwarderBot, value string)
+}
+
+// StoredOnBot reports whether the setting is a ForwarderBot column rather than a BotSetting
+func (d *Definition) StoredOnBot() bool {
+	return d.get != nil
+}
+
+// ChoiceLabel returns the label of a value of a KindChoice setting
+func (d *Definition) ChoiceLabel(value string) string {
+	for _, choice := range d.Choices {
+		if choice.Value == value {
+			return choice.Label
+		}
+	}
+	return value
+}
+
+func formatBool(b bool) string {
+	return strconv.FormatBool(b)
+}
+
+func parseBool(value string) bool {
+	b, _ := strconv.ParseBool(value)
+	return b
+}
+
+func parseInt(value string) int {
+	n, _ := strconv.Atoi(value)
+	return n
+}
+
+// intBetween returns a validator for whole numbers from lo to hi
+func intBetween(lo, hi int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < lo || n > hi {
+			return fmt.Errorf("must be a number between %d and %d", lo, hi)
+		}
+		return nil
+	}
+}
+
+// retentionDays validates KeyMappingRetention: RetentionDefault,
+// RetentionForever or a number of days
+func retentionDays(value string) error {
+	if value == RetentionDefault || value == RetentionForever {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < config.MinMessageMappingRetentionDays || n > MaxMappingRetentionDays {
+		return fmt.Errorf("must be %s, %s or a number of days between %d and %d",
+			RetentionDefault, RetentionForever, config.MinMessageMappingRetentionDays, MaxMappingRetentionDays)
+	}
+	return nil
+}
+
+// definitions is the registry of all per-bot settings
+var definitions = []*Definition{
+	{
+		Key:         KeyTimezone,
+		Category:    "general",
+		Label:       "Timezone",
+		Description: "Timezone times are shown in; empty uses the manager's timezone",
+		Kind:        KindText,
+		Command:     "/settimezone",
+		validate: func(value string) error {
+			_, err := utils.LoadTimezone(value)
+			return err
+		},
+		get: func(bot *models.ForwarderBot) string { return bot.Timezone },
+		set: func(bot *models.ForwarderBot, value string) { bot.Timezone = value },
+	},
+	{
+		Key:         KeyDryRun,
+		Category:    "general",
+		Label:       "Dry run",
+		Description: "Process and log guest messages without delivering them to recipients, e.g. to try filters and routing rules",
+		Kind:        KindBool,
+		Default:     "false",
+		get:         func(bot *models.ForwarderBot) string { return formatBool(bot.DryRun) },
+		set:         func(bot *models.ForwarderBot, value string) { bot.DryRun = parseBool(value) },
+	},
+	{
+		Key:         KeyMappingRetention,
+		Category:    "general",
+		Label:       "Reply window",
+		Description: "How long message links are kept; replies to older forwarded messages no longer reach the guest",
+		Kind:        KindChoice,
+		Default:     RetentionDefault,
+		Choices: []Choice{
+			{Value: RetentionDefault, Label: "Default"},
+			{Value: "30", Label: "30 days"},
+			{Value: "90", Label: "90 days"},
+			{Value: "180", Label: "180 days"},
+			{Value: "365", Label: "1 year"},
+			{Value: RetentionForever, Label: "Forever"},
+		},
+		validate: retentionDays,
+	},
+	{
+		Key:         KeyAPIRateLimit,
+		Category:    "general",
+		Label:       "API rate limit",
+		Description: "Telegram API requests per second of this bot; the default shares the global limit with other bots",
+		Kind:        KindChoice,
+		Default:     "0",
+		Choices: []Choice{
+			{Value: "0", Label: "Default"},
+			{Value: "5", Label: "5/s"},
+			{Value: "10", Label: "10/s"},
+			{Value: "20", Label: "20/s"},
+			{Value: "30", Label: "30/s"},
+		},
+		validate: intBetween(0, MaxAPIRateLimit),
+		get:      func(bot *models.ForwarderBot) string { return strconv.Itoa(bot.APIRateLimit) },
+		set:      func(bot *models.ForwarderBot, value string) { bot.APIRateLimit = parseInt(value) },
+	},
+	{
+		Key:         KeyGroupGuests,
+		Category:    "guests",
+		Label:       "Group guests",
+		Description: "Forward messages from groups that are not recipients",
+		Kind:        KindBool,
+		Default:     "false",
+		get:         func(bot *models.ForwarderBot) string { return formatBool(bot.AllowGroupGuests) },
+		set:         func(bot *models.ForwarderBot, value string) { bot.AllowGroupGuests = parseBool(value) },
+	},
+	{
+		Key:         KeyAutoLeave,
+		Category:    "guests",
+		Label:       "Leave unknown groups",
+		Description: "Leave groups the bot is added to by anyone but the manager or admins",
+		Kind:        KindBool,
+		Default:     "true",
+		get:         func(bot *models.ForwarderBot) string { return formatBool(bot.AutoLeaveGroups) },
+		set:         func(bot *models.ForwarderBot, value string) { bot.AutoLeaveGroups = parseBool(value) },
+	},
+	{
+		Key:         KeyRateLimit,
+		Category:    "guests",
+		Label:       "Rate limit",
+		Description: "Guest messages per second",
+		Kind:        KindChoice,
+		Default:     "0",
+		Choices: []Choice{
+			{Value: "0", Label: "Default"},
+			{Value: "1", Label: "1/s"},
+			{Value: "2", Label: "2/s"},
+			{Value: "5", Label: "5/s"},
+			{Value: "10", Label: "10/s"},
+		},
+		validate: intBetween(0, MaxGuestRateLimit),
+		get:      func(bot *models.ForwarderBot) string { return strconv.Itoa(bot.GuestRateLimit) },
+		set:      func(bot *models.ForwarderBot, value string) { bot.GuestRateLimit = parseInt(value) },
+	},
+	{
+		Key:         KeyRateBurst,
+		Category:    "guests",
+		Label:       "Burst",
+		Description: "Messages a guest may send at once",
+		Kind:        KindChoice,
+		Default:     "0",
+		Choices: []Choice{
+			{Value: "0", Label: "Same as rate"},
+			{Value: "3", Label: "3"},
+			{Value: "5", Label: "5"},
+			{Value: "10", Label: "10"},
+			{Value: "20", Label: "20"},
+		},
+		validate: intBetween(0, MaxGuestRateBurst),
+		get:      func(bot *models.ForwarderBot) string { return strconv.Itoa(bot.GuestRateBurst) },
+		set:      func(bot *models.ForwarderBot, value string) { bot.GuestRateBurst = parseInt(value) },
+	},
+	{
+		Key:         KeyQueueNotice,
+		Category:    "guests",
+		Label:       "Queue notice",
+		Description: "Tell guests how many conversations are ahead of theirs and the usual response time",
+		Kind:        KindBool,
+		Default:     "false",
+	},
+	{
+		Key:         KeyOfficeHours,
+		Category:    "guests",
+		Label:       "Office hours",
+		Description: "When the team replies; guests writing outside them get the away message. Empty is always open",
+		Kind:        KindText,
+		Command:     "/sethours",
+		validate: func(value string) error {
+			if value == "" {
+				return nil
+			}
+			_, err := officehours.Parse(value)
+			return err
+		},
+		get: func(bot *models.ForwarderBot) string { return bot.OfficeHours },
+		set: func(bot *models.ForwarderBot, value string) { bot.OfficeHours = value },
+	},
+	{
+		Key:         KeyAwayMessage,
+		Category:    "guests",
+		Label:       "Away message",
+		Description: "Sent to guests writing outside office hours; {hours} and {opens_at} are filled in. Empty uses the built-in message",
+		Kind:        KindText,
+		Command:     "/setaway",
+		get:         func(bot *models.ForwarderBot) string { return bot.AwayMessage },
+		set:         func(bot *models.ForwarderBot, value string) { bot.AwayMessage = value },
+	},
+	{
+		Key:         KeyTranscribe,
+		Category:    "guests",
+		Label:       "Transcribe voice messages",
+		Description: "Add a text transcription below guest voice messages (needs transcription enabled by the instance operator)",
+		Kind:        KindBool,
+		Default:     "false",
+	},
+	{
+		Key:         KeyPrivacyMode,
+		Category:    "guests",
+		Label:       "Hide guest names",
+		Description: "Copy guest messages under a header instead of forwarding them, so recipients do not see who sent them",
+		Kind:        KindBool,
+		Default:     "false",
+		get:         func(bot *models.ForwarderBot) string { return formatBool(bot.PrivacyMode) },
+		set:         func(bot *models.ForwarderBot, value string) { bot.PrivacyMode = parseBool(value) },
+	},
+	{
+		Key:         KeyPrivacyHeader,
+		Category:    "guests",
+		Label:       "Guest header",
+		Description: "Shown above guest messages when guest names are hidden; {guest} is the guest's number",
+		Kind:        KindText,
+		Command:     "/setprivacyheader",
+		validate: func(value string) error {
+			if len([]rune(value)) > MaxPrivacyHeaderLength {
+				return fmt.Errorf("must be at most %d characters", MaxPrivacyHeaderLength)
+			}
+			return nil
+		},
+		get: func(bot *models.ForwarderBot) string { return bot.PrivacyHeader },
+		set: func(bot *models.ForwarderBot, value string) { bot.PrivacyHeader = value },
+	},
+	{
+		Key:         KeyGuestTopics,
+		Category:    "guests",
+		Label:       "One topic per guest",
+		Description: "In recipient groups with topics enabled, put each guest's messages in their own topic (the bot needs the right to manage topics)",
+		Kind:        KindBool,
+		Default:     "false",
+		get:         func(bot *models.ForwarderBot) string { return formatBool(bot.GuestTopics) },
+		set:         func(bot *models.ForwarderBot, value string) { bot.GuestTopics = parseBool(value) },
+	},
+	{
+		Key:         KeyProfileCard,
+		Category:    "guests",
+		Label:       "Guest profile card",
+		Description: "Before the first message of each conversation, show recipients the guest's username, ID, language, earlier messages and blacklist history",
+		Kind:        KindBool,
+		Default:     "false",
+	},
+	{
+		Key:         KeyReactionSync,
+		Category:    "guests",
+		Label:       "Sync reactions",
+		Description: "Mirror reactions between guest messages and their copies: a recipient's reaction appears on the guest's message and the other way round",
+		Kind:        KindBool,
+		Default:     "false",
+	},
+	{
+		Key:         KeyWelcomeText,
+		Category:    "welcome",
+		Label:       "Welcome message",
+		Description: "Sent to guests on /start and with their first message",
+		Kind:        KindText,
+		Command:     "/setwelcome a",
+		get:         func(bot *models.ForwarderBot) string { return bot.WelcomeText },
+		set:         func(bot *models.ForwarderBot, value string) { bot.WelcomeText = value },
+	},
+	{
+		Key:         KeyWelcomeTextB,
+		Category:    "welcome",
+		Label:       "Welcome message B",
+		Description: "Second welcome variant for A/B testing",
+		Kind:        KindText,
+		Command:     "/setwelcome b",
+		get:         func(bot *models.ForwarderBot) string { return bot.WelcomeTextB },
+		set:         func(bot *models.ForwarderBot, value string) { bot.WelcomeTextB = value },
+	},
+	{
+		Key:         KeyTermsText,
+		Category:    "welcome",
+		Label:       "Terms",
+		Description: "Notice guests must accept before their messages are forwarded",
+		Kind:        KindText,
+		Command:     "/setterms",
+		get:         func(bot *models.ForwarderBot) string { return bot.TermsText },
+		set: func(bot *models.ForwarderBot, value string) {
+			if value != bot.TermsText {
+				bot.TermsText = value
+				bot.TermsVersion++
+			}
+		},
+	},
+	{
+		Key:         KeyGuestHelp,
+		Category:    "welcome",
+		Label:       "Guest help",
+		Description: "Shown to guests on /help instead of the built-in explanation",
+		Kind:        KindText,
+		Command:     "/sethelp",
+	},
+	{
+		Key:         KeyAlertKeywords,
+		Category:    "filters",
+		Label:       "Alert keywords",
+		Description: "Guest messages containing these words ping the manager",
+		Kind:        KindText,
+		Command:     "/setalerts",
+		get:         func(bot *models.ForwarderBot) string { return bot.AlertKeywords },
+		set:         func(bot *models.ForwarderBot, value string) { bot.AlertKeywords = value },
+	},
+	{
+		Key:         KeyReadImages,
+		Category:    "filters",
+		Label:       "Read text in images",
+		Description: "Check text in screenshots and photos against the filters and alerts and show it to recipients (needs OCR enabled by the instance operator)",
+		Kind:        KindBool,
+		Default:     "false",
+	},
+	{
+		Key:         KeyScanFiles,
+		Category:    "filters",
+		Label:       "Scan files for viruses",
+		Description: "Withhold guest files the virus scanner flags (needs file scanning enabled by the instance operator)",
+		Kind:        KindBool,
+		Default:     "false",
+	},
+	{
+		Key:         KeyPaywallMode,
+		Category:    "paywall",
+		Label:       "Paywall",
+		Description: "Charge guests Telegram Stars to forward messages",
+		Kind:        KindText,
+		Command:     "/paywall",
+		validate: func(value string) error {
+			switch models.PaywallMode(value) {
+			case models.PaywallModeOff, models.PaywallModeRequired, models.PaywallModePriority:
+				return nil
+			}
+			return fmt.Errorf("must be empty, %q or %q", models.PaywallModeRequired, models.PaywallModePriority)
+		},
+		get: func(bot *models.ForwarderBot) string { return string(bot.PaywallMode) },
+		set: func(bot *models.ForwarderBot, value string) { bot.PaywallMode = models.PaywallMode(value) },
+	},
+	{
+		Key:         KeyPaywallPrice,
+		Category:    "paywall",
+		Label:       "Price",
+		Description: "Telegram Stars per purchase",
+		Kind:        KindText,
+		Command:     "/paywall",
+		validate:    intBetween(0, MaxPaywallPrice),
+		get:         func(bot *models.ForwarderBot) string { return strconv.Itoa(bot.PaywallPrice) },
+		set:         func(bot *models.ForwarderBot, value string) { bot.PaywallPrice = parseInt(value) },
+	},
+	{
+		Key:         KeyPaywallCredits,
+		Category:    "paywall",
+		Label:       "Messages per purchase",
+		Description: "How many messages one purchase pays for",
+		Kind:        KindText,
+		Command:     "/paywall",
+		validate:    intBetween(0, MaxPaywallCredits),
+		get:         func(bot *models.ForwarderBot) string { return strconv.Itoa(bot.PaywallCredits) },
+		set:         func(bot *models.ForwarderBot, value string) { bot.PaywallCredits = parseInt(value) },
+	},
+	{
+		Key:         KeyDigest,
+		Category:    "notify",
+		Label:       "Digests",
+		Description: "Batch notifications sent within a minute into one digest",
+		Kind:        KindBool,
+		Default:     "true",
+	},
+}
+
+// Definitions returns all per-bot settings in menu order
+func Definitions() []*Definition {
+	return definitions
+}
+
+// Lookup returns the definition of a setting key
+func Lookup(key string) (*Definition, bool) {
+	for _, def := range definitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return nil, false
+}
+
+// ByCategory returns the settings shown on one page of the settings menu
+func ByCategory(category string) []*Definition {
+	var defs []*Definition
+	for _, def := range definitions {
+		if def.Category == category {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// LookupCategory returns a category by ID
+func LookupCategory(id string) (Category, bool) {
+	for _, category := range Categories {
+		if category.ID == id {
+			return category, true
+		}
+	}
+	return Category{}, false
+}
@@ -0,0 +1,158 @@
+package settings
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.yaml.in/yaml/v3"
+)
+
+// DocumentVersion is the version of the settings document format
+const DocumentVersion = 1
+
+// Document is a bot's settings as exported to YAML. It only holds the
+// settings in the registry; the bot token and other secrets are never part
+// of it, so a document can be shared or kept under version control.
+type Document struct {
+	Version    int                    `yaml:"version"`
+	Bot        string                 `yaml:"bot,omitempty"` // Username of the exported bot, informational only
+	ExportedAt time.Time              `yaml:"exported_at"`
+	Settings   map[string]interface{} `yaml:"settings"`
+}
+
+// Export returns the bot's settings as a YAML document
+func (s *Service) Export(botID uuid.UUID) ([]byte, error) {
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bot: %w", err)
+	}
+	values, err := s.Values(botID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		Version:    DocumentVersion,
+		Bot:        bot.Name,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Settings:   make(map[string]interface{}, len(values)),
+	}
+	for _, def := range definitions {
+		value := values[def.Key]
+		if def.Kind == KindBool {
+			doc.Settings[def.Key] = parseBool(value)
+		} else {
+			doc.Settings[def.Key] = value
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Forwarder bot settings. Import with /importsettings in the ManagerBot.\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode settings: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode settings: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseDocument reads a settings document and checks every setting in it. It
+// returns the settings as strings keyed by setting key.
+func ParseDocument(data []byte) (map[string]string, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if doc.Version != DocumentVersion {
+		return nil, fmt.Errorf("unsupported settings document version %d", doc.Version)
+	}
+	if len(doc.Settings) == 0 {
+		return nil, fmt.Errorf("the document has no settings")
+	}
+
+	values := make(map[string]string, len(doc.Settings))
+	for key, raw := range doc.Settings {
+		def, ok := Lookup(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown setting %q", key)
+		}
+		var value string
+		switch v := raw.(type) {
+		case nil:
+			value = ""
+		case string:
+			value = v
+		case bool, int, int64, uint64, float64:
+			value = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("%s must be a single value", key)
+		}
+		if err := Validate(def, value); err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// Import applies parsed settings to the bot and returns the keys that changed,
+// sorted. Settings missing from values are left as they are.
+func (s *Service) Import(botID uuid.UUID, values map[string]string) ([]string, error) {
+	for key, value := range values {
+		def, ok := Lookup(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown setting %q", key)
+		}
+		if err := Validate(def, value); err != nil {
+			return nil, err
+		}
+	}
+
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bot: %w", err)
+	}
+	stored, err := s.botSettingRepo.GetByBotID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bot settings: %w", err)
+	}
+
+	var changed []string
+	botChanged := false
+	for _, def := range definitions {
+		value, ok := values[def.Key]
+		if !ok || value == valueOf(def, bot, stored) {
+			continue
+		}
+		changed = append(changed, def.Key)
+		if def.StoredOnBot() {
+			def.set(bot, value)
+			botChanged = true
+		}
+	}
+
+	if botChanged {
+		if err := s.botRepo.Update(bot); err != nil {
+			return nil, fmt.Errorf("failed to update bot: %w", err)
+		}
+	}
+	for _, key := range changed {
+		def, _ := Lookup(key)
+		if def.StoredOnBot() {
+			s.Changed(botID, key)
+			continue
+		}
+		if err := s.Set(botID, key, values[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(changed)
+	return changed, nil
+}
@@ -0,0 +1,174 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Service reads and changes per-bot settings. Settings stored on ForwarderBot
+// and in BotSetting are accessed the same way, so callers only deal with keys.
+type Service struct {
+	botRepo        repository.BotRepository
+	botSettingRepo repository.BotSettingRepository
+	logger         *zap.Logger
+	onChange       ChangeListener
+}
+
+// ChangeListener is called after a setting of a bot was changed
+type ChangeListener func(botID uuid.UUID, key string)
+
+func NewService(
+	botRepo repository.BotRepository,
+	botSettingRepo repository.BotSettingRepository,
+	logger *zap.Logger,
+) *Service {
+	return &Service{
+		botRepo:        botRepo,
+		botSettingRepo: botSettingRepo,
+		logger:         logger,
+	}
+}
+
+// SetChangeListener sets the function called after each setting change. It
+// must be called before the settings are used.
+func (s *Service) SetChangeListener(listener ChangeListener) {
+	s.onChange = listener
+}
+
+// Changed tells the change listener that a setting was changed without Set,
+// e.g. by a command writing several ForwarderBot columns at once
+func (s *Service) Changed(botID uuid.UUID, key string) {
+	if s.onChange != nil {
+		s.onChange(botID, key)
+	}
+}
+
+// Values returns the current value of every setting of the bot, defaults included
+func (s *Service) Values(botID uuid.UUID) (map[string]string, error) {
+	bot, err := s.botRepo.GetByID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bot: %w", err)
+	}
+	stored, err := s.botSettingRepo.GetByBotID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bot settings: %w", err)
+	}
+
+	values := make(map[string]string, len(definitions))
+	for _, def := range definitions {
+		values[def.Key] = valueOf(def, bot, stored)
+	}
+	return values, nil
+}
+
+func valueOf(def *Definition, bot *models.ForwarderBot, stored map[string]string) string {
+	if def.StoredOnBot() {
+		return def.get(bot)
+	}
+	if value, ok := stored[def.Key]; ok {
+		return value
+	}
+	return def.Default
+}
+
+// Get returns the current value of one setting
+func (s *Service) Get(botID uuid.UUID, key string) (string, error) {
+	def, ok := Lookup(key)
+	if !ok {
+		return "", fmt.Errorf("unknown setting %q", key)
+	}
+	if def.StoredOnBot() {
+		bot, err := s.botRepo.GetByID(botID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get bot: %w", err)
+		}
+		return def.get(bot), nil
+	}
+	stored, err := s.botSettingRepo.GetByBotID(botID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bot settings: %w", err)
+	}
+	return valueOf(def, nil, stored), nil
+}
+
+// GetBool returns a KindBool setting, falling back to its default if it cannot be read
+func (s *Service) GetBool(botID uuid.UUID, key string) bool {
+	value, err := s.Get(botID, key)
+	if err != nil {
+		s.logger.Warn("Failed to read bot setting, using default",
+			zap.String("bot_id", botID.String()),
+			zap.String("key", key),
+			zap.Error(err))
+		if def, ok := Lookup(key); ok {
+			return parseBool(def.Default)
+		}
+		return false
+	}
+	return parseBool(value)
+}
+
+// Validate checks that value is allowed for the setting
+func Validate(def *Definition, value string) error {
+	if def.validate != nil {
+		if err := def.validate(value); err != nil {
+			return fmt.Errorf("%s: %w", def.Key, err)
+		}
+		return nil
+	}
+	switch def.Kind {
+	case KindBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", def.Key)
+		}
+	case KindChoice:
+		for _, choice := range def.Choices {
+			if choice.Value == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not a valid value for %s", value, def.Key)
+	}
+	return nil
+}
+
+// Set validates and stores a new value for one setting. BotSetting rows are
+// removed when a setting is set back to its default.
+func (s *Service) Set(botID uuid.UUID, key, value string) error {
+	def, ok := Lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	if err := Validate(def, value); err != nil {
+		return err
+	}
+
+	if def.StoredOnBot() {
+		bot, err := s.botRepo.GetByID(botID)
+		if err != nil {
+			return fmt.Errorf("failed to get bot: %w", err)
+		}
+		def.set(bot, value)
+		if err := s.botRepo.Update(bot); err != nil {
+			return fmt.Errorf("failed to update bot: %w", err)
+		}
+		s.Changed(botID, key)
+		return nil
+	}
+
+	if value == def.Default {
+		if err := s.botSettingRepo.Delete(botID, key); err != nil {
+			return fmt.Errorf("failed to reset bot setting: %w", err)
+		}
+		s.Changed(botID, key)
+		return nil
+	}
+	if err := s.botSettingRepo.Set(botID, key, value); err != nil {
+		return fmt.Errorf("failed to update bot setting: %w", err)
+	}
+	s.Changed(botID, key)
+	return nil
+}
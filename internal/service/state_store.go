@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// stateKeyPrefix is the prefix of the Redis keys state is saved under
+	stateKeyPrefix = "state:"
+
+	// stateTTL is how long saved state is kept. Cooldowns and pauses are
+	// shorter than this, so state older than it is not worth restoring.
+	stateTTL = time.Hour
+)
+
+// Snapshotter is in-memory state that is kept across restarts
+type Snapshotter interface {
+	// Snapshot encodes the current state
+	Snapshot() ([]byte, error)
+	// Restore merges previously saved state into the current state
+	Restore(data []byte) error
+}
+
+type namedSnapshotter struct {
+	name        string
+	snapshotter Snapshotter
+}
+
+// StateStore saves the in-memory state of the registered components to Redis
+// on a clean shutdown and restores it at startup, so cooldowns, pauses and
+// notification windows are not reset by a restart. State is saved per
+// deployment rather than per instance: the last instance to shut down wins.
+type StateStore struct {
+	connection *RedisConnection
+	logger     *zap.Logger
+	entries    []namedSnapshotter
+}
+
+func NewStateStore(connection *RedisConnection, logger *zap.Logger) *StateStore {
+	return &StateStore{
+		connection: connection,
+		logger:     logger,
+	}
+}
+
+// Register adds a component whose state is saved under the given name
+func (s *StateStore) Register(name string, snapshotter Snapshotter) {
+	s.entries = append(s.entries, namedSnapshotter{name: name, snapshotter: snapshotter})
+}
+
+// Restore loads the saved state of every registered component. Missing or
+// expired state is skipped; failures are logged, as starting with empty
+// state is always possible.
+func (s *StateStore) Restore(ctx context.Context) {
+	client := s.connection.Client()
+	if client == nil {
+		s.logger.Warn("Redis is unavailable, starting without saved state")
+		return
+	}
+
+	for _, entry := range s.entries {
+		data, err := client.Get(ctx, stateKeyPrefix+entry.name).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err == nil {
+			err = entry.snapshotter.Restore(data)
+		}
+		if err != nil {
+			s.logger.Warn("Failed to restore saved state",
+				zap.String("component", entry.name),
+				zap.Error(err))
+			continue
+		}
+		s.logger.Info("Restored saved state", zap.String("component", entry.name))
+	}
+}
+
+// Save writes the state of every registered component to Redis. Called on
+// shutdown, after the bots have stopped.
+func (s *StateStore) Save(ctx context.Context) error {
+	client := s.connection.Client()
+	if client == nil {
+		return fmt.Errorf("redis is unavailable")
+	}
+
+	var errs []error
+	for _, entry := range s.entries {
+		data, err := entry.snapshotter.Snapshot()
+		if err == nil {
+			err = client.Set(ctx, stateKeyPrefix+entry.name, data, stateTTL).Err()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
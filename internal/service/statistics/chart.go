@@ -0,0 +1,186 @@
+package statistics
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wcharczuk/go-chart/v2"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/utils"
+)
+
+// chartDays is how many complete days the /stats charts cover by default
+const chartDays = 14
+
+// chartTicks is about how many days are labeled on the x axis
+const chartTicks = 7
+
+const (
+	chartWidth  = 800
+	chartHeight = 400
+)
+
+// DailySeries is one value per day for the /stats charts, oldest first
+type DailySeries struct {
+	Days        []time.Time
+	Inbound     []float64
+	Outbound    []float64
+	NewGuests   []float64
+	TotalGuests []float64 // Guest count at the end of each day
+	HasData     bool      // False when no daily rollups exist yet
+}
+
+// GetBotDailySeries returns the chart data of one bot for the last chartDays complete days
+func (s *Service) GetBotDailySeries(botID uuid.UUID) (*DailySeries, error) {
+	from, to := dayRange(chartDays)
+	stats, err := s.statsDailyRepo.GetByBotIDAndDayRange(botID,
+		from.Format(models.StatsDailyDayFormat), to.Format(models.StatsDailyDayFormat))
+	if err != nil {
+		return nil, err
+	}
+	guestCount, err := s.guestRepo.CountByBotID(botID)
+	if err != nil {
+		return nil, err
+	}
+	return buildDailySeries(from, chartDays, stats, guestCount), nil
+}
+
+// GetGlobalDailySeries returns the chart data summed over all bots
+func (s *Service) GetGlobalDailySeries() (*DailySeries, error) {
+	from, to := dayRange(chartDays)
+	stats, err := s.statsDailyRepo.GetByDayRange(
+		from.Format(models.StatsDailyDayFormat), to.Format(models.StatsDailyDayFormat))
+	if err != nil {
+		return nil, err
+	}
+	global, err := s.GetGlobalStatistics()
+	if err != nil {
+		return nil, err
+	}
+	return buildDailySeries(from, chartDays, stats, global.TotalGuestCount), nil
+}
+
+// dayRange returns the first and last of the given number of complete days before today
+func dayRange(days int) (from time.Time, to time.Time) {
+	today := startOfDay(time.Now())
+	return today.AddDate(0, 0, -days), today.AddDate(0, 0, -1)
+}
+
+// buildDailySeries sums rollups per day, fills missing days with zero and
+// derives the guest total of each day backwards from the current guest count
+func buildDailySeries(from time.Time, days int, stats []*models.StatsDaily, currentGuests int64) *DailySeries {
+	series := &DailySeries{
+		Days:        make([]time.Time, days),
+		Inbound:     make([]float64, days),
+		Outbound:    make([]float64, days),
+		NewGuests:   make([]float64, days),
+		TotalGuests: make([]float64, days),
+		HasData:     len(stats) > 0,
+	}
+
+	index := make(map[string]int, days)
+	for i := 0; i < days; i++ {
+		day := from.AddDate(0, 0, i)
+		series.Days[i] = day
+		index[day.Format(models.StatsDailyDayFormat)] = i
+	}
+
+	for _, stat := range stats {
+		i, ok := index[stat.Day]
+		if !ok {
+			continue
+		}
+		series.Inbound[i] += float64(stat.InboundCount)
+		series.Outbound[i] += float64(stat.OutboundCount)
+		series.NewGuests[i] += float64(stat.NewGuestCount)
+	}
+
+	// Guests created today are not in any rollup yet, so the current count is an upper bound
+	guests := currentGuests
+	for i := days - 1; i >= 0; i-- {
+		series.TotalGuests[i] = float64(guests)
+		guests -= int64(series.NewGuests[i])
+		if guests < 0 {
+			guests = 0
+		}
+	}
+	return series
+}
+
+// RenderMessagesChart draws inbound and outbound messages per day as a PNG
+func RenderMessagesChart(series *DailySeries) ([]byte, error) {
+	return renderChart("Messages per day", series.Days, []chart.TimeSeries{
+		{Name: "Inbound", XValues: series.Days, YValues: series.Inbound},
+		{Name: "Outbound", XValues: series.Days, YValues: series.Outbound},
+	})
+}
+
+// RenderGuestsChart draws the total guest count per day as a PNG
+func RenderGuestsChart(series *DailySeries) ([]byte, error) {
+	return renderChart("Guests", series.Days, []chart.TimeSeries{
+		{Name: "Total guests", XValues: series.Days, YValues: series.TotalGuests},
+	})
+}
+
+// RenderNewGuestsChart draws the new guests per day as a PNG
+func RenderNewGuestsChart(series *DailySeries) ([]byte, error) {
+	return renderChart("New guests per day", series.Days, []chart.TimeSeries{
+		{Name: "New guests", XValues: series.Days, YValues: series.NewGuests},
+	})
+}
+
+func renderChart(title string, days []time.Time, lines []chart.TimeSeries) ([]byte, error) {
+	// go-chart refuses a zero-height range, so always start at 0 and leave headroom
+	maxValue := 1.0
+	for _, line := range lines {
+		for _, value := range line.YValues {
+			if value > maxValue {
+				maxValue = value
+			}
+		}
+	}
+
+	step := max(1, len(days)/chartTicks)
+	ticks := make([]chart.Tick, 0, len(days))
+	for i, day := range days {
+		label := ""
+		if i%step == 0 {
+			label = utils.FormatShortDate(day)
+		}
+		ticks = append(ticks, chart.Tick{Value: chart.TimeToFloat64(day), Label: label})
+	}
+
+	seriesList := make([]chart.Series, 0, len(lines))
+	for _, line := range lines {
+		seriesList = append(seriesList, line)
+	}
+
+	graph := chart.Chart{
+		Title:  title,
+		Width:  chartWidth,
+		Height: chartHeight,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20},
+		},
+		XAxis: chart.XAxis{Ticks: ticks},
+		YAxis: chart.YAxis{
+			Range: &chart.ContinuousRange{Min: 0, Max: maxValue * 1.1},
+			ValueFormatter: func(v interface{}) string {
+				if value, ok := v.(float64); ok {
+					return utils.FormatDecimal(value, 0)
+				}
+				return ""
+			},
+		},
+		Series: seriesList,
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+
+	var buf bytes.Buffer
+	if err := graph.Render(chart.PNG, &buf); err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,142 @@
+package statistics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"go.uber.org/zap"
+)
+
+// trendDays is the length of the periods compared by GetBotTrend
+const trendDays = 7
+
+// rollupDays is how many complete days each rollup run recomputes. Covering
+// both trend periods means a missed run is filled in by the next one.
+const rollupDays = 2 * trendDays
+
+// MetricTrend compares one metric across the last two periods
+type MetricTrend struct {
+	Current  int64
+	Previous int64
+}
+
+// BotTrend compares the last trendDays complete days with the period before
+type BotTrend struct {
+	Inbound   MetricTrend
+	Outbound  MetricTrend
+	NewGuests MetricTrend
+	Failures  MetricTrend
+}
+
+// RollupDaily writes StatsDaily rows for the last rollupDays complete days of every bot.
+// It is run by the stats_daily worker.
+func (s *Service) RollupDaily(ctx context.Context) error {
+	bots, err := s.botRepo.GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to get bots: %w", err)
+	}
+
+	today := startOfDay(time.Now())
+	for _, bot := range bots {
+		for i := rollupDays; i >= 1; i-- {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			day := today.AddDate(0, 0, -i)
+			if err := s.rollupBotDay(bot.ID, day); err != nil {
+				s.logger.Warn("Failed to roll up daily statistics",
+					zap.String("bot_id", bot.ID.String()),
+					zap.String("day", day.Format(models.StatsDailyDayFormat)),
+					zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) rollupBotDay(botID uuid.UUID, day time.Time) error {
+	end := day.AddDate(0, 0, 1)
+
+	inbound, err := s.inboundMessageRepo.CountByBotIDBetween(botID, day, end)
+	if err != nil {
+		return err
+	}
+	outbound, err := s.messageMappingRepo.CountByBotIDAndDirectionBetween(
+		botID, models.MessageDirectionOutbound, day, end)
+	if err != nil {
+		return err
+	}
+	newGuests, err := s.guestRepo.CountCreatedByBotIDBetween(botID, day, end)
+	if err != nil {
+		return err
+	}
+
+	return s.statsDailyRepo.Upsert(&models.StatsDaily{
+		BotID:         botID,
+		Day:           day.Format(models.StatsDailyDayFormat),
+		InboundCount:  inbound.Messages,
+		OutboundCount: outbound,
+		NewGuestCount: newGuests,
+		CopyCount:     inbound.Copies,
+		FailureCount:  inbound.Failures,
+	})
+}
+
+// GetBotTrend sums the daily rollups of the last trendDays complete days and the period before
+func (s *Service) GetBotTrend(botID uuid.UUID) (*BotTrend, error) {
+	today := startOfDay(time.Now())
+	currentStart := today.AddDate(0, 0, -trendDays)
+	previousStart := currentStart.AddDate(0, 0, -trendDays)
+
+	stats, err := s.statsDailyRepo.GetByBotIDAndDayRange(botID,
+		previousStart.Format(models.StatsDailyDayFormat),
+		today.AddDate(0, 0, -1).Format(models.StatsDailyDayFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	trend := &BotTrend{}
+	currentFrom := currentStart.Format(models.StatsDailyDayFormat)
+	for _, stat := range stats {
+		metrics := []*MetricTrend{&trend.Inbound, &trend.Outbound, &trend.NewGuests, &trend.Failures}
+		values := []int64{stat.InboundCount, stat.OutboundCount, stat.NewGuestCount, stat.FailureCount}
+		for i, metric := range metrics {
+			if stat.Day >= currentFrom {
+				metric.Current += values[i]
+			} else {
+				metric.Previous += values[i]
+			}
+		}
+	}
+	return trend, nil
+}
+
+// Summary renders the change against the previous period, e.g. "▲ 12% vs last week"
+func (m MetricTrend) Summary() string {
+	switch {
+	case m.Previous == 0 && m.Current == 0:
+		return "no change vs last week"
+	case m.Previous == 0:
+		return "▲ new vs last week"
+	}
+
+	change := float64(m.Current-m.Previous) / float64(m.Previous) * 100
+	percent := int64(math.Round(math.Abs(change)))
+	switch {
+	case percent == 0:
+		return "no change vs last week"
+	case change > 0:
+		return fmt.Sprintf("▲ %d%% vs last week", percent)
+	default:
+		return fmt.Sprintf("▼ %d%% vs last week", percent)
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
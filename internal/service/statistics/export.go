@@ -0,0 +1,49 @@
+package statistics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+)
+
+// dailyCSVHeader are the columns of ExportBotDailyCSV
+var dailyCSVHeader = []string{"date", "inbound", "outbound", "new_guests", "copies", "failed_copies"}
+
+// ExportBotDailyCSV returns one CSV row per complete day of the last days
+// days, oldest first. Days without a rollup are written as zeros.
+func (s *Service) ExportBotDailyCSV(botID uuid.UUID, days int) ([]byte, error) {
+	from, to := dayRange(days)
+	stats, err := s.statsDailyRepo.GetByBotIDAndDayRange(botID,
+		from.Format(models.StatsDailyDayFormat), to.Format(models.StatsDailyDayFormat))
+	if err != nil {
+		return nil, err
+	}
+	byDay := make(map[string]*models.StatsDaily, len(stats))
+	for _, stat := range stats {
+		byDay[stat.Day] = stat
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(dailyCSVHeader)
+	for i := 0; i < days; i++ {
+		day := from.AddDate(0, 0, i).Format(models.StatsDailyDayFormat)
+		stat, ok := byDay[day]
+		if !ok {
+			stat = &models.StatsDaily{}
+		}
+		w.Write([]string{
+			day,
+			strconv.FormatInt(stat.InboundCount, 10),
+			strconv.FormatInt(stat.OutboundCount, 10),
+			strconv.FormatInt(stat.NewGuestCount, 10),
+			strconv.FormatInt(stat.CopyCount, 10),
+			strconv.FormatInt(stat.FailureCount, 10),
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
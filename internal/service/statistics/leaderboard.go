@@ -0,0 +1,194 @@
+package statistics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+)
+
+// leaderboardDays is the window ranked by GetBotLeaderboard
+const leaderboardDays = 7
+
+// LeaderboardSort selects the ranking of GetBotLeaderboard
+type LeaderboardSort string
+
+const (
+	LeaderboardSortVolume   LeaderboardSort = "volume"
+	LeaderboardSortFailures LeaderboardSort = "failures"
+)
+
+// BotActivity is one ForwarderBot's activity over the leaderboard window
+type BotActivity struct {
+	BotID         uuid.UUID
+	BotName       string
+	InboundCount  int64
+	OutboundCount int64
+	CopyCount     int64
+	FailureCount  int64
+}
+
+// MessageCount is the bot's total message volume
+func (a BotActivity) MessageCount() int64 {
+	return a.InboundCount + a.OutboundCount
+}
+
+// FailureRate is the share of recipient copies that were not delivered, from 0 to 1
+func (a BotActivity) FailureRate() float64 {
+	if a.CopyCount == 0 {
+		return 0
+	}
+	return float64(a.FailureCount) / float64(a.CopyCount)
+}
+
+// GetBotLeaderboard ranks all ForwarderBots by their activity over the last
+// leaderboardDays complete days, using the daily rollups
+func (s *Service) GetBotLeaderboard(sortBy LeaderboardSort) ([]BotActivity, error) {
+	_, activities, err := s.botActivities()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(activities, func(i, j int) bool {
+		a, b := activities[i], activities[j]
+		if sortBy == LeaderboardSortFailures && a.FailureRate() != b.FailureRate() {
+			return a.FailureRate() > b.FailureRate()
+		}
+		if a.MessageCount() != b.MessageCount() {
+			return a.MessageCount() > b.MessageCount()
+		}
+		return a.BotName < b.BotName
+	})
+	return activities, nil
+}
+
+// GetFailureHotspots returns the ForwarderBots with failed recipient copies
+// over the leaderboard window, most failures first
+func (s *Service) GetFailureHotspots() ([]BotActivity, error) {
+	_, activities, err := s.botActivities()
+	if err != nil {
+		return nil, err
+	}
+
+	hotspots := make([]BotActivity, 0, len(activities))
+	for _, activity := range activities {
+		if activity.FailureCount > 0 {
+			hotspots = append(hotspots, activity)
+		}
+	}
+	sort.SliceStable(hotspots, func(i, j int) bool {
+		a, b := hotspots[i], hotspots[j]
+		if a.FailureCount != b.FailureCount {
+			return a.FailureCount > b.FailureCount
+		}
+		if a.FailureRate() != b.FailureRate() {
+			return a.FailureRate() > b.FailureRate()
+		}
+		return a.BotName < b.BotName
+	})
+	return hotspots, nil
+}
+
+// ManagerActivity sums the activity of all bots of one manager over the
+// leaderboard window
+type ManagerActivity struct {
+	ManagerID      uuid.UUID
+	TelegramUserID int64
+	Username       string
+	BotCount       int
+	InboundCount   int64
+	OutboundCount  int64
+	CopyCount      int64
+	FailureCount   int64
+}
+
+// MessageCount is the manager's total message volume
+func (a ManagerActivity) MessageCount() int64 {
+	return a.InboundCount + a.OutboundCount
+}
+
+// FailureRate is the share of recipient copies that were not delivered, from 0 to 1
+func (a ManagerActivity) FailureRate() float64 {
+	if a.CopyCount == 0 {
+		return 0
+	}
+	return float64(a.FailureCount) / float64(a.CopyCount)
+}
+
+// GetManagerLeaderboard ranks the managers owning ForwarderBots by the message
+// volume of their bots over the last leaderboardDays complete days
+func (s *Service) GetManagerLeaderboard() ([]ManagerActivity, error) {
+	bots, activities, err := s.botActivities()
+	if err != nil {
+		return nil, err
+	}
+
+	var managers []ManagerActivity
+	index := make(map[uuid.UUID]int)
+	for i, bot := range bots {
+		j, ok := index[bot.ManagerID]
+		if !ok {
+			j = len(managers)
+			index[bot.ManagerID] = j
+			manager := ManagerActivity{
+				ManagerID:      bot.ManagerID,
+				TelegramUserID: bot.Manager.TelegramUserID,
+			}
+			if bot.Manager.Username != nil {
+				manager.Username = *bot.Manager.Username
+			}
+			managers = append(managers, manager)
+		}
+		managers[j].BotCount++
+		managers[j].InboundCount += activities[i].InboundCount
+		managers[j].OutboundCount += activities[i].OutboundCount
+		managers[j].CopyCount += activities[i].CopyCount
+		managers[j].FailureCount += activities[i].FailureCount
+	}
+
+	sort.SliceStable(managers, func(i, j int) bool {
+		a, b := managers[i], managers[j]
+		if a.MessageCount() != b.MessageCount() {
+			return a.MessageCount() > b.MessageCount()
+		}
+		return a.TelegramUserID < b.TelegramUserID
+	})
+	return managers, nil
+}
+
+// botActivities returns all ForwarderBots and their activity over the
+// leaderboard window, in the same order
+func (s *Service) botActivities() ([]*models.ForwarderBot, []BotActivity, error) {
+	bots, err := s.botRepo.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	today := startOfDay(time.Now())
+	stats, err := s.statsDailyRepo.GetByDayRange(
+		today.AddDate(0, 0, -leaderboardDays).Format(models.StatsDailyDayFormat),
+		today.AddDate(0, 0, -1).Format(models.StatsDailyDayFormat))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	activities := make([]BotActivity, 0, len(bots))
+	index := make(map[uuid.UUID]int, len(bots))
+	for _, bot := range bots {
+		index[bot.ID] = len(activities)
+		activities = append(activities, BotActivity{BotID: bot.ID, BotName: bot.Name})
+	}
+	for _, stat := range stats {
+		i, ok := index[stat.BotID]
+		if !ok {
+			// Rollups of deleted bots
+			continue
+		}
+		activities[i].InboundCount += stat.InboundCount
+		activities[i].OutboundCount += stat.OutboundCount
+		activities[i].CopyCount += stat.CopyCount
+		activities[i].FailureCount += stat.FailureCount
+	}
+	return bots, activities, nil
+}
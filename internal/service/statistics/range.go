@@ -0,0 +1,57 @@
+package statistics
+
+import (
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+)
+
+// MaxRangeDays is the longest period GetBotRangeStatistics covers
+const MaxRangeDays = 365
+
+// topGuestCount is how many of the most active guests a range summary lists
+const topGuestCount = 5
+
+// RangeStatistics summarizes one bot over the last Days complete days
+type RangeStatistics struct {
+	Days      int
+	Inbound   int64
+	Outbound  int64
+	NewGuests int64
+	Failures  int64
+	Series    *DailySeries
+	TopGuests []repository.GuestMessageCount // Most active guest chats, most active first
+}
+
+// GetBotRangeStatistics sums the daily rollups of the last days complete days
+// and finds the guests that wrote the most in them. Days without a rollup,
+// e.g. before the stats_daily worker first ran, count as zero.
+func (s *Service) GetBotRangeStatistics(botID uuid.UUID, days int) (*RangeStatistics, error) {
+	from, to := dayRange(days)
+	stats, err := s.statsDailyRepo.GetByBotIDAndDayRange(botID,
+		from.Format(models.StatsDailyDayFormat), to.Format(models.StatsDailyDayFormat))
+	if err != nil {
+		return nil, err
+	}
+	guestCount, err := s.guestRepo.CountByBotID(botID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RangeStatistics{
+		Days:   days,
+		Series: buildDailySeries(from, days, stats, guestCount),
+	}
+	for _, stat := range stats {
+		result.Inbound += stat.InboundCount
+		result.Outbound += stat.OutboundCount
+		result.NewGuests += stat.NewGuestCount
+		result.Failures += stat.FailureCount
+	}
+
+	result.TopGuests, err = s.inboundMessageRepo.TopGuestsByBotIDBetween(botID, from, to.AddDate(0, 0, 1), topGuestCount)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
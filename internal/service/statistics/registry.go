@@ -0,0 +1,143 @@
+package statistics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+)
+
+// BotRegistry lists all registered ForwarderBots for reporting and capacity
+// planning. It never contains bot tokens.
+type BotRegistry struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Bots        []RegistryBot `json:"bots"`
+}
+
+// RegistryBot is one ForwarderBot of the registry. Message counts cover the
+// messages still stored, like GetGlobalStatistics.
+type RegistryBot struct {
+	BotID                 uuid.UUID  `json:"bot_id"`
+	Name                  string     `json:"name"`
+	TelegramBotID         int64      `json:"telegram_bot_id"`
+	ManagerID             uuid.UUID  `json:"manager_id"`
+	ManagerTelegramUserID int64      `json:"manager_telegram_user_id"`
+	ManagerUsername       string     `json:"manager_username,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+	LastActivityAt        *time.Time `json:"last_activity_at"` // Last guest message or reply; nil if the bot was never used
+	InboundCount          int64      `json:"inbound_messages"`
+	OutboundCount         int64      `json:"outbound_messages"`
+	GuestCount            int64      `json:"guests"`
+}
+
+// GetBotRegistry lists all ForwarderBots, oldest first
+func (s *Service) GetBotRegistry() (*BotRegistry, error) {
+	bots, err := s.botRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(bots, func(i, j int) bool {
+		return bots[i].CreatedAt.Before(bots[j].CreatedAt)
+	})
+
+	registry := &BotRegistry{
+		GeneratedAt: time.Now(),
+		Bots:        make([]RegistryBot, 0, len(bots)),
+	}
+	for _, bot := range bots {
+		entry := RegistryBot{
+			BotID:                 bot.ID,
+			Name:                  bot.Name,
+			TelegramBotID:         bot.TelegramBotID,
+			ManagerID:             bot.ManagerID,
+			ManagerTelegramUserID: bot.Manager.TelegramUserID,
+			CreatedAt:             bot.CreatedAt,
+		}
+		if bot.Manager.Username != nil {
+			entry.ManagerUsername = *bot.Manager.Username
+		}
+
+		if entry.InboundCount, err = s.inboundMessageRepo.CountByBotID(bot.ID); err != nil {
+			return nil, fmt.Errorf("failed to count inbound messages of bot %s: %w", bot.ID, err)
+		}
+		if entry.OutboundCount, err = s.messageMappingRepo.CountByBotIDAndDirection(bot.ID, models.MessageDirectionOutbound); err != nil {
+			return nil, fmt.Errorf("failed to count outbound messages of bot %s: %w", bot.ID, err)
+		}
+		if entry.GuestCount, err = s.guestRepo.CountByBotID(bot.ID); err != nil {
+			return nil, fmt.Errorf("failed to count guests of bot %s: %w", bot.ID, err)
+		}
+
+		lastInbound, err := s.inboundMessageRepo.LastReceivedAt(bot.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last inbound message of bot %s: %w", bot.ID, err)
+		}
+		lastOutbound, err := s.messageMappingRepo.LastCreatedAt(bot.ID, models.MessageDirectionOutbound)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last outbound message of bot %s: %w", bot.ID, err)
+		}
+		if last := maxTime(lastInbound, lastOutbound); !last.IsZero() {
+			entry.LastActivityAt = &last
+		}
+
+		registry.Bots = append(registry.Bots, entry)
+	}
+	return registry, nil
+}
+
+// WriteCSV writes the registry as CSV with a header row. Times are RFC 3339
+// in UTC; a bot that was never used has an empty last_activity_at.
+func (r *BotRegistry) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{
+		"bot_id", "name", "telegram_bot_id", "manager_id", "manager_telegram_user_id", "manager_username",
+		"created_at", "last_activity_at", "inbound_messages", "outbound_messages", "guests",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, bot := range r.Bots {
+		lastActivity := ""
+		if bot.LastActivityAt != nil {
+			lastActivity = bot.LastActivityAt.UTC().Format(time.RFC3339)
+		}
+		record := []string{
+			bot.BotID.String(),
+			bot.Name,
+			strconv.FormatInt(bot.TelegramBotID, 10),
+			bot.ManagerID.String(),
+			strconv.FormatInt(bot.ManagerTelegramUserID, 10),
+			bot.ManagerUsername,
+			bot.CreatedAt.UTC().Format(time.RFC3339),
+			lastActivity,
+			strconv.FormatInt(bot.InboundCount, 10),
+			strconv.FormatInt(bot.OutboundCount, 10),
+			strconv.FormatInt(bot.GuestCount, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the registry as indented JSON
+func (r *BotRegistry) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// maxTime returns the later of two times
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
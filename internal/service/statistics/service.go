@@ -8,10 +8,13 @@ import (
 )
 
 type Service struct {
-	botRepo            repository.BotRepository
-	guestRepo          repository.GuestRepository
-	messageMappingRepo repository.MessageMappingRepository
-	logger             *zap.Logger
+	botRepo               repository.BotRepository
+	guestRepo             repository.GuestRepository
+	messageMappingRepo    repository.MessageMappingRepository
+	inboundMessageRepo    repository.InboundMessageRepository
+	statsDailyRepo        repository.StatsDailyRepository
+	welcomeAssignmentRepo repository.WelcomeAssignmentRepository
+	logger                *zap.Logger
 }
 
 type GlobalStatistics struct {
@@ -38,13 +41,19 @@ func NewService(
 	botRepo repository.BotRepository,
 	guestRepo repository.GuestRepository,
 	messageMappingRepo repository.MessageMappingRepository,
+	inboundMessageRepo repository.InboundMessageRepository,
+	statsDailyRepo repository.StatsDailyRepository,
+	welcomeAssignmentRepo repository.WelcomeAssignmentRepository,
 	logger *zap.Logger,
 ) *Service {
 	return &Service{
-		botRepo:            botRepo,
-		guestRepo:          guestRepo,
-		messageMappingRepo: messageMappingRepo,
-		logger:             logger,
+		botRepo:               botRepo,
+		guestRepo:             guestRepo,
+		messageMappingRepo:    messageMappingRepo,
+		inboundMessageRepo:    inboundMessageRepo,
+		statsDailyRepo:        statsDailyRepo,
+		welcomeAssignmentRepo: welcomeAssignmentRepo,
+		logger:                logger,
 	}
 }
 
@@ -60,8 +69,7 @@ func (s *Service) GetGlobalStatistics() (*GlobalStatistics, error) {
 	for _, bot := range bots {
 		managerMap[bot.ManagerID] = true
 
-		inbound, err := s.messageMappingRepo.CountByBotIDAndDirection(
-			bot.ID, models.MessageDirectionInbound)
+		inbound, err := s.inboundMessageRepo.CountByBotID(bot.ID)
 		if err != nil {
 			s.logger.Warn("Failed to count inbound messages",
 				zap.String("bot_id", bot.ID.String()),
@@ -107,8 +115,7 @@ func (s *Service) GetManagerStatistics(managerID uuid.UUID) (*ManagerStatistics,
 
 	botStats := make([]BotStatistics, 0, len(bots))
 	for _, bot := range bots {
-		inbound, err := s.messageMappingRepo.CountByBotIDAndDirection(
-			bot.ID, models.MessageDirectionInbound)
+		inbound, err := s.inboundMessageRepo.CountByBotID(bot.ID)
 		if err != nil {
 			s.logger.Warn("Failed to count inbound messages",
 				zap.String("bot_id", bot.ID.String()),
@@ -153,8 +160,7 @@ func (s *Service) GetBotStatistics(botID uuid.UUID) (*BotStatistics, error) {
 		return nil, err
 	}
 
-	inbound, err := s.messageMappingRepo.CountByBotIDAndDirection(
-		botID, models.MessageDirectionInbound)
+	inbound, err := s.inboundMessageRepo.CountByBotID(botID)
 	if err != nil {
 		return nil, err
 	}
@@ -178,3 +184,8 @@ func (s *Service) GetBotStatistics(botID uuid.UUID) (*BotStatistics, error) {
 		GuestCount:    guestCount,
 	}, nil
 }
+
+// GetGuestMessageCount returns how many messages the bot received from a guest chat
+func (s *Service) GetGuestMessageCount(botID uuid.UUID, guestChatID int64) (int64, error) {
+	return s.inboundMessageRepo.CountByBotIDAndGuestChatID(botID, guestChatID)
+}
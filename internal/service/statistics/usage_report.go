@@ -0,0 +1,144 @@
+package statistics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go-telegram-forwarder-bot/internal/models"
+)
+
+// UsageReport is the per-manager usage of one calendar month, for chargeback
+type UsageReport struct {
+	Month    string         `json:"month"` // models.BotUsageMonthFormat
+	Managers []ManagerUsage `json:"managers"`
+}
+
+// ManagerUsage sums the usage of all bots of one manager
+type ManagerUsage struct {
+	ManagerID       uuid.UUID `json:"manager_id"`
+	TelegramUserID  int64     `json:"telegram_user_id"`
+	Username        string    `json:"username,omitempty"`
+	BotCount        int       `json:"bot_count"`
+	InboundCount    int64     `json:"inbound_messages"`
+	OutboundCount   int64     `json:"outbound_messages"`
+	NewGuestCount   int64     `json:"new_guests"`
+	TotalGuestCount int64     `json:"total_guests"`
+	StoredMessages  int64     `json:"stored_message_mappings"` // Storage footprint: message mapping rows kept for replies
+}
+
+// MessageCount is the manager's total message volume in the month
+func (u ManagerUsage) MessageCount() int64 {
+	return u.InboundCount + u.OutboundCount
+}
+
+// GetUsageReport builds the usage report of the month containing month.
+// Message and new guest counts come from the daily rollups, so the current
+// month only includes complete days; guest totals and storage are current values.
+func (s *Service) GetUsageReport(month time.Time) (*UsageReport, error) {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	bots, err := s.botRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make(map[uuid.UUID]*ManagerUsage)
+	for _, bot := range bots {
+		usage, ok := usages[bot.ManagerID]
+		if !ok {
+			usage = &ManagerUsage{
+				ManagerID:      bot.ManagerID,
+				TelegramUserID: bot.Manager.TelegramUserID,
+			}
+			if bot.Manager.Username != nil {
+				usage.Username = *bot.Manager.Username
+			}
+			usages[bot.ManagerID] = usage
+		}
+		usage.BotCount++
+
+		stats, err := s.statsDailyRepo.GetByBotIDAndDayRange(bot.ID,
+			monthStart.Format(models.StatsDailyDayFormat), monthEnd.Format(models.StatsDailyDayFormat))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get daily statistics of bot %s: %w", bot.ID, err)
+		}
+		for _, stat := range stats {
+			usage.InboundCount += stat.InboundCount
+			usage.OutboundCount += stat.OutboundCount
+			usage.NewGuestCount += stat.NewGuestCount
+		}
+
+		guests, err := s.guestRepo.CountByBotID(bot.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count guests of bot %s: %w", bot.ID, err)
+		}
+		usage.TotalGuestCount += guests
+
+		stored, err := s.messageMappingRepo.CountByBotID(bot.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count message mappings of bot %s: %w", bot.ID, err)
+		}
+		usage.StoredMessages += stored
+	}
+
+	report := &UsageReport{
+		Month:    monthStart.Format(models.BotUsageMonthFormat),
+		Managers: make([]ManagerUsage, 0, len(usages)),
+	}
+	for _, usage := range usages {
+		report.Managers = append(report.Managers, *usage)
+	}
+	sort.Slice(report.Managers, func(i, j int) bool {
+		a, b := report.Managers[i], report.Managers[j]
+		if a.MessageCount() != b.MessageCount() {
+			return a.MessageCount() > b.MessageCount()
+		}
+		return a.TelegramUserID < b.TelegramUserID
+	})
+	return report, nil
+}
+
+// WriteCSV writes the report as CSV with a header row
+func (r *UsageReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{
+		"month", "manager_id", "telegram_user_id", "username", "bot_count",
+		"inbound_messages", "outbound_messages", "new_guests", "total_guests", "stored_message_mappings",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, usage := range r.Managers {
+		record := []string{
+			r.Month,
+			usage.ManagerID.String(),
+			strconv.FormatInt(usage.TelegramUserID, 10),
+			usage.Username,
+			strconv.Itoa(usage.BotCount),
+			strconv.FormatInt(usage.InboundCount, 10),
+			strconv.FormatInt(usage.OutboundCount, 10),
+			strconv.FormatInt(usage.NewGuestCount, 10),
+			strconv.FormatInt(usage.TotalGuestCount, 10),
+			strconv.FormatInt(usage.StoredMessages, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *UsageReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,37 @@
+package statistics
+
+import (
+	"github.com/google/uuid"
+)
+
+// WelcomeVariantStats is the A/B test result of one welcome message variant
+type WelcomeVariantStats struct {
+	Variant   string
+	Assigned  int64 // New guests shown this variant
+	Converted int64 // Of those, guests who went on to send a message
+}
+
+// ConversionRate returns the share of assigned guests who converted, in percent
+func (w WelcomeVariantStats) ConversionRate() float64 {
+	if w.Assigned == 0 {
+		return 0
+	}
+	return float64(w.Converted) * 100 / float64(w.Assigned)
+}
+
+// GetWelcomeStats returns the welcome A/B test results of a bot, one entry per variant shown
+func (s *Service) GetWelcomeStats(botID uuid.UUID) ([]WelcomeVariantStats, error) {
+	counts, err := s.welcomeAssignmentRepo.CountByBotID(botID)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]WelcomeVariantStats, 0, len(counts))
+	for _, c := range counts {
+		stats = append(stats, WelcomeVariantStats{
+			Variant:   c.Variant,
+			Assigned:  c.Assigned,
+			Converted: c.Converted,
+		})
+	}
+	return stats, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/models"
+	"go-telegram-forwarder-bot/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// superuserCacheTTL is how long the superuser list is used before it is read
+// again, so superusers added on another instance are picked up
+const superuserCacheTTL = time.Minute
+
+// ErrConfiguredSuperuser is returned when removing a superuser listed in the
+// config, who would be added again on the next start
+var ErrConfiguredSuperuser = errors.New("superuser is listed in the config")
+
+// Superusers is the list of superusers, stored in the superusers table and
+// cached in memory. The superusers from manager_bot.superusers are added on
+// start, so the config can always restore access.
+type Superusers struct {
+	repo       repository.SuperuserRepository
+	configured []int64
+	logger     *zap.Logger
+
+	mu       sync.RWMutex
+	ids      []int64
+	loadedAt time.Time
+}
+
+// NewSuperusers adds the configured superusers to the table, removes those
+// that were taken out of the config since, and loads the list
+func NewSuperusers(repo repository.SuperuserRepository, cfg *config.Config, logger *zap.Logger) (*Superusers, error) {
+	s := &Superusers{
+		repo:       repo,
+		configured: cfg.ManagerBot.Superusers,
+		logger:     logger,
+	}
+	for _, telegramUserID := range s.configured {
+		if _, err := repo.Add(&models.Superuser{TelegramUserID: telegramUserID}); err != nil {
+			return nil, fmt.Errorf("failed to add configured superuser %d: %w", telegramUserID, err)
+		}
+	}
+	removed, err := repo.DeleteConfiguredExcept(s.configured)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove superusers no longer in the config: %w", err)
+	}
+	if removed > 0 {
+		logger.Info("Removed superusers no longer in the config", zap.Int64("count", removed))
+	}
+	if err := s.reload(); err != nil {
+		return nil, fmt.Errorf("failed to load superusers: %w", err)
+	}
+	return s, nil
+}
+
+// IsSuperuser reports whether the Telegram user is a superuser
+func (s *Superusers) IsSuperuser(telegramUserID int64) bool {
+	return slices.Contains(s.List(), telegramUserID)
+}
+
+// IsConfigured reports whether the Telegram user is a superuser from the config
+func (s *Superusers) IsConfigured(telegramUserID int64) bool {
+	return slices.Contains(s.configured, telegramUserID)
+}
+
+// List returns the Telegram user IDs of all superusers, oldest first
+func (s *Superusers) List() []int64 {
+	s.mu.RLock()
+	ids, fresh := s.ids, time.Since(s.loadedAt) < superuserCacheTTL
+	s.mu.RUnlock()
+	if fresh {
+		return ids
+	}
+
+	if err := s.reload(); err != nil {
+		// Keep using the last list rather than locking everyone out
+		s.logger.Warn("Failed to reload superusers", zap.Error(err))
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ids
+}
+
+// Add makes the Telegram user a superuser and reports whether they were not one already
+func (s *Superusers) Add(telegramUserID int64, addedBy uuid.UUID) (bool, error) {
+	added, err := s.repo.Add(&models.Superuser{TelegramUserID: telegramUserID, AddedByID: &addedBy})
+	if err != nil {
+		return false, err
+	}
+	s.changed(func(ids []int64) []int64 {
+		if slices.Contains(ids, telegramUserID) {
+			return ids
+		}
+		return append(slices.Clone(ids), telegramUserID)
+	})
+	return added, nil
+}
+
+// Remove takes superuser rights from the Telegram user and reports whether they had them
+func (s *Superusers) Remove(telegramUserID int64) (bool, error) {
+	if s.IsConfigured(telegramUserID) {
+		return false, ErrConfiguredSuperuser
+	}
+	removed, err := s.repo.Delete(telegramUserID)
+	if err != nil {
+		return false, err
+	}
+	s.changed(func(ids []int64) []int64 {
+		return slices.DeleteFunc(slices.Clone(ids), func(id int64) bool { return id == telegramUserID })
+	})
+	return removed, nil
+}
+
+// changed reloads the list after a change. If that fails, the change is
+// applied to the cached list so it takes effect on this instance regardless.
+func (s *Superusers) changed(apply func(ids []int64) []int64) {
+	err := s.reload()
+	if err == nil {
+		return
+	}
+	s.logger.Warn("Failed to reload superusers", zap.Error(err))
+	s.mu.Lock()
+	s.ids = apply(s.ids)
+	s.mu.Unlock()
+}
+
+func (s *Superusers) reload() error {
+	superusers, err := s.repo.GetAll()
+	if err != nil {
+		return err
+	}
+	ids := make([]int64, 0, len(superusers))
+	for _, superuser := range superusers {
+		ids = append(ids, superuser.TelegramUserID)
+	}
+
+	s.mu.Lock()
+	s.ids = ids
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,119 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/utils"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"go.uber.org/zap"
+)
+
+// maxFileSize is the largest file bots may download from Telegram
+const maxFileSize = 20 << 20
+
+// ErrTooLong is returned for voice messages longer than transcription.max_duration_seconds
+var ErrTooLong = errors.New("voice message is too long to transcribe")
+
+// Service downloads voice messages from Telegram and transcribes them
+type Service struct {
+	provider    Provider
+	httpClient  *http.Client // Downloads files from Telegram
+	maxDuration int64
+	timeout     time.Duration
+	logger      *zap.Logger
+}
+
+// NewService creates the service with the provider selected by transcription.provider
+func NewService(cfg *config.TranscriptionConfig, proxy *config.ProxyConfig, logger *zap.Logger) (*Service, error) {
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	httpClient, err := utils.CreateHTTPClientWithProxy(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	httpClient.Timeout = timeout
+
+	var provider Provider
+	switch cfg.Provider {
+	case "", "openai":
+		provider = &openAIProvider{
+			endpoint:   cfg.Endpoint,
+			apiKey:     cfg.APIKey,
+			model:      cfg.Model,
+			language:   cfg.Language,
+			httpClient: httpClient,
+		}
+	default:
+		return nil, fmt.Errorf("unknown transcription provider: %s", cfg.Provider)
+	}
+
+	return &Service{
+		provider:    provider,
+		httpClient:  httpClient,
+		maxDuration: int64(cfg.MaxDurationSeconds),
+		timeout:     timeout,
+		logger:      logger,
+	}, nil
+}
+
+// TranscribeVoice returns the text spoken in a voice message
+func (s *Service) TranscribeVoice(ctx context.Context, bot *gotgbot.Bot, voice *gotgbot.Voice) (string, error) {
+	if s.maxDuration > 0 && voice.Duration > s.maxDuration {
+		return "", ErrTooLong
+	}
+	if voice.FileSize > maxFileSize {
+		return "", ErrTooLong
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	audio, err := s.download(ctx, bot, voice.FileId)
+	if err != nil {
+		return "", err
+	}
+
+	text, err := s.provider.Transcribe(ctx, audio, "voice.ogg")
+	if err != nil {
+		return "", fmt.Errorf("failed to transcribe: %w", err)
+	}
+	s.logger.Debug("Voice message transcribed",
+		zap.Int64("duration_seconds", voice.Duration),
+		zap.Int("audio_bytes", len(audio)),
+		zap.Duration("took", time.Since(start)))
+	return text, nil
+}
+
+func (s *Service) download(ctx context.Context, bot *gotgbot.Bot, fileID string) ([]byte, error) {
+	file, err := bot.GetFileWithContext(ctx, fileID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.URL(bot, nil), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		// The error contains the URL, which contains the bot token
+		return nil, errors.New("failed to download file")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize))
+	if err != nil {
+		return nil, errors.New("failed to read file")
+	}
+	return audio, nil
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// WithBotAPIURL makes the client of opts send requests to the Bot API server
+// at apiURL instead of Telegram's. An empty apiURL leaves opts unchanged.
+func WithBotAPIURL(opts *gotgbot.BotOpts, apiURL string) *gotgbot.BotOpts {
+	if apiURL == "" {
+		return opts
+	}
+	if opts == nil {
+		opts = &gotgbot.BotOpts{}
+	}
+	if opts.BotClient == nil {
+		opts.BotClient = &gotgbot.BaseBotClient{}
+	}
+	client, ok := opts.BotClient.(*gotgbot.BaseBotClient)
+	if !ok {
+		return opts
+	}
+	requestOpts := gotgbot.RequestOpts{}
+	if client.DefaultRequestOpts != nil {
+		requestOpts = *client.DefaultRequestOpts
+	}
+	requestOpts.APIURL = apiURL
+	client.DefaultRequestOpts = &requestOpts
+	return opts
+}
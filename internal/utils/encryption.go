@@ -10,6 +10,21 @@ import (
 )
 
 func EncryptToken(token string, key []byte) (string, error) {
+	return seal(key, []byte(token), nil)
+}
+
+func DecryptToken(encryptedToken string, key []byte) (string, error) {
+	plaintext, err := open(encryptedToken, key, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// seal encrypts plaintext with AES-GCM and returns base64(nonce || ciphertext).
+// additionalData is authenticated but not encrypted; the same value must be
+// given to open.
+func seal(key, plaintext, additionalData []byte) (string, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
@@ -25,38 +40,34 @@ func EncryptToken(token string, key []byte) (string, error) {
 		return "", err
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, []byte(token), nil)
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, additionalData)
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-func DecryptToken(encryptedToken string, key []byte) (string, error) {
-	data, err := base64.StdEncoding.DecodeString(encryptedToken)
+// open reverses seal
+func open(encoded string, key, additionalData []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	nonceSize := gcm.NonceSize()
 	if len(data) < nonceSize {
-		return "", errors.New("ciphertext too short")
+		return nil, errors.New("ciphertext too short")
 	}
 
 	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return "", err
-	}
-
-	return string(plaintext), nil
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
 }
 
 func GenerateEncryptionKey() ([]byte, error) {
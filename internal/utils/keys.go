@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// Key derivation purposes. Each purpose yields an unrelated key, so the master
+// encryption key is never used directly for more than one kind of data.
+const (
+	// KeyPurposeDataKeyWrap derives the key that wraps per-bot data keys
+	KeyPurposeDataKeyWrap = "data-key-wrap"
+)
+
+// DeriveKey derives a 32-byte key for one purpose from the master encryption key with HKDF-SHA256
+func DeriveKey(master []byte, purpose string) ([]byte, error) {
+	if len(master) != 32 {
+		return nil, errors.New("master key must be 32 bytes")
+	}
+	if purpose == "" {
+		return nil, errors.New("key purpose is required")
+	}
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, master, nil, []byte(purpose)), key); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// NewDataKey generates a random data key for one owner (e.g. a bot) and returns
+// it along with its wrapped form for storage. The data key itself must not be stored.
+func NewDataKey(wrappingKey []byte, owner string) ([]byte, string, error) {
+	dataKey, err := GenerateEncryptionKey()
+	if err != nil {
+		return nil, "", err
+	}
+	wrapped, err := WrapDataKey(dataKey, wrappingKey, owner)
+	if err != nil {
+		return nil, "", err
+	}
+	return dataKey, wrapped, nil
+}
+
+// WrapDataKey encrypts a data key with the wrapping key. The owner is bound to
+// the result, so a wrapped key copied to another owner cannot be unwrapped.
+func WrapDataKey(dataKey, wrappingKey []byte, owner string) (string, error) {
+	if len(dataKey) != 32 {
+		return "", errors.New("data key must be 32 bytes")
+	}
+	return seal(wrappingKey, dataKey, []byte(owner))
+}
+
+// UnwrapDataKey decrypts a data key wrapped by WrapDataKey for the same owner
+func UnwrapDataKey(wrapped string, wrappingKey []byte, owner string) ([]byte, error) {
+	dataKey, err := open(wrapped, wrappingKey, []byte(owner))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	if len(dataKey) != 32 {
+		return nil, errors.New("unwrapped data key has the wrong size")
+	}
+	return dataKey, nil
+}
+
+// EncryptContent encrypts stored content such as message text with a data key.
+// The record reference is bound to the ciphertext, so it cannot be moved to
+// another record.
+func EncryptContent(plaintext string, dataKey []byte, record string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	return seal(dataKey, []byte(plaintext), []byte(record))
+}
+
+// DecryptContent decrypts content encrypted by EncryptContent for the same record
+func DecryptContent(ciphertext string, dataKey []byte, record string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+	plaintext, err := open(ciphertext, dataKey, []byte(record))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt content: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	master, _ := GenerateEncryptionKey()
+
+	first, err := DeriveKey(master, KeyPurposeDataKeyWrap)
+	if err != nil {
+		t.Fatalf("Failed to derive key: %v", err)
+	}
+	if len(first) != 32 {
+		t.Fatalf("Expected key length 32, got %d", len(first))
+	}
+
+	again, _ := DeriveKey(master, KeyPurposeDataKeyWrap)
+	if !bytes.Equal(first, again) {
+		t.Fatal("Expected the same purpose to derive the same key")
+	}
+
+	other, _ := DeriveKey(master, "other")
+	if bytes.Equal(first, other) {
+		t.Fatal("Expected different purposes to derive different keys")
+	}
+	if bytes.Equal(first, master) {
+		t.Fatal("Derived key should not equal the master key")
+	}
+
+	if _, err := DeriveKey(master[:16], KeyPurposeDataKeyWrap); err == nil {
+		t.Fatal("Expected error for short master key")
+	}
+}
+
+func TestWrapUnwrapDataKey(t *testing.T) {
+	master, _ := GenerateEncryptionKey()
+	wrappingKey, _ := DeriveKey(master, KeyPurposeDataKeyWrap)
+
+	dataKey, wrapped, err := NewDataKey(wrappingKey, "bot-a")
+	if err != nil {
+		t.Fatalf("Failed to create data key: %v", err)
+	}
+
+	unwrapped, err := UnwrapDataKey(wrapped, wrappingKey, "bot-a")
+	if err != nil {
+		t.Fatalf("Failed to unwrap data key: %v", err)
+	}
+	if !bytes.Equal(dataKey, unwrapped) {
+		t.Fatal("Unwrapped data key does not match")
+	}
+
+	if _, err := UnwrapDataKey(wrapped, wrappingKey, "bot-b"); err == nil {
+		t.Fatal("Expected error when unwrapping for another owner")
+	}
+
+	otherKey, _ := DeriveKey(master, "other")
+	if _, err := UnwrapDataKey(wrapped, otherKey, "bot-a"); err == nil {
+		t.Fatal("Expected error when unwrapping with the wrong key")
+	}
+}
+
+func TestEncryptDecryptContent(t *testing.T) {
+	dataKey, _ := GenerateEncryptionKey()
+	text := "Hello, this is an archived message"
+
+	encrypted, err := EncryptContent(text, dataKey, "message-1")
+	if err != nil {
+		t.Fatalf("Failed to encrypt content: %v", err)
+	}
+	if encrypted == text {
+		t.Fatal("Encrypted content should not equal the original")
+	}
+
+	decrypted, err := DecryptContent(encrypted, dataKey, "message-1")
+	if err != nil {
+		t.Fatalf("Failed to decrypt content: %v", err)
+	}
+	if decrypted != text {
+		t.Fatalf("Expected %q, got %q", text, decrypted)
+	}
+
+	if _, err := DecryptContent(encrypted, dataKey, "message-2"); err == nil {
+		t.Fatal("Expected error when decrypting for another record")
+	}
+
+	if empty, err := EncryptContent("", dataKey, "message-1"); err != nil || empty != "" {
+		t.Fatalf("Expected empty content to stay empty, got %q, %v", empty, err)
+	}
+}
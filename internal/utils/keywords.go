@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"strings"
+)
+
+// ParseKeywordList splits a comma-separated keyword list, trimming and
+// lowercasing each keyword and dropping empty entries and duplicates
+func ParseKeywordList(list string) []string {
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, keyword := range strings.Split(list, ",") {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+		keywords = append(keywords, keyword)
+	}
+	return keywords
+}
+
+// MatchKeywords returns the keywords contained in text, ignoring case.
+// Keywords are matched as substrings so they work for languages without spaces.
+func MatchKeywords(text string, keywords []string) []string {
+	if text == "" {
+		return nil
+	}
+	lower := strings.ToLower(text)
+	var matched []string
+	for _, keyword := range keywords {
+		if keyword != "" && strings.Contains(lower, strings.ToLower(keyword)) {
+			matched = append(matched, keyword)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeywordList(t *testing.T) {
+	got := ParseKeywordList(" Refund, lawyer,,URGENT , refund ")
+	want := []string{"refund", "lawyer", "urgent"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	if got := ParseKeywordList(""); len(got) != 0 {
+		t.Fatalf("Expected no keywords, got %v", got)
+	}
+}
+
+func TestMatchKeywords(t *testing.T) {
+	keywords := []string{"refund", "lawyer", "退款"}
+
+	got := MatchKeywords("I want a REFUND or I'll call my lawyer", keywords)
+	want := []string{"refund", "lawyer"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	if got := MatchKeywords("请帮我退款", keywords); !reflect.DeepEqual(got, []string{"退款"}) {
+		t.Fatalf("Expected match without spaces, got %v", got)
+	}
+
+	if got := MatchKeywords("Thanks for the help", keywords); len(got) != 0 {
+		t.Fatalf("Expected no matches, got %v", got)
+	}
+
+	if got := MatchKeywords("", keywords); len(got) != 0 {
+		t.Fatalf("Expected no matches for empty text, got %v", got)
+	}
+}
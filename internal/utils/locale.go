@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Locale controls how dates and numbers are shown to users
+type Locale struct {
+	Name             string
+	TimestampLayout  string // e.g. 2006-01-02 15:04:05
+	ShortDateLayout  string // Day and month only, used for chart labels
+	DecimalSeparator string
+	GroupSeparator   string // Thousands separator
+}
+
+// DefaultLocale is used when no locale is configured
+const DefaultLocale = "en"
+
+var locales = map[string]*Locale{
+	"en":    {TimestampLayout: TimestampLayout, ShortDateLayout: "01-02", DecimalSeparator: ".", GroupSeparator: ","},
+	"en-us": {TimestampLayout: "01/02/2006 15:04:05", ShortDateLayout: "01/02", DecimalSeparator: ".", GroupSeparator: ","},
+	"en-gb": {TimestampLayout: "02/01/2006 15:04:05", ShortDateLayout: "02/01", DecimalSeparator: ".", GroupSeparator: ","},
+	"de":    {TimestampLayout: "02.01.2006 15:04:05", ShortDateLayout: "02.01.", DecimalSeparator: ",", GroupSeparator: "."},
+	"es":    {TimestampLayout: "02/01/2006 15:04:05", ShortDateLayout: "02/01", DecimalSeparator: ",", GroupSeparator: "."},
+	"fr":    {TimestampLayout: "02/01/2006 15:04:05", ShortDateLayout: "02/01", DecimalSeparator: ",", GroupSeparator: "\u202f"},
+	"ru":    {TimestampLayout: "02.01.2006 15:04:05", ShortDateLayout: "02.01", DecimalSeparator: ",", GroupSeparator: "\u00a0"},
+	"ja":    {TimestampLayout: "2006/01/02 15:04:05", ShortDateLayout: "01/02", DecimalSeparator: ".", GroupSeparator: ","},
+	"zh":    {TimestampLayout: TimestampLayout, ShortDateLayout: "01-02", DecimalSeparator: ".", GroupSeparator: ","},
+}
+
+func init() {
+	for name, locale := range locales {
+		locale.Name = name
+	}
+	currentLocale.Store(locales[DefaultLocale])
+}
+
+var currentLocale atomic.Pointer[Locale]
+
+// LocaleNames returns the supported locale names, sorted
+func LocaleNames() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseLocale looks up a locale such as "de" or "en_US". An empty name is DefaultLocale.
+func ParseLocale(name string) (*Locale, error) {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", "-"))
+	if key == "" {
+		key = DefaultLocale
+	}
+	locale, ok := locales[key]
+	if !ok {
+		return nil, fmt.Errorf("unsupported locale %q (supported: %s)", name, strings.Join(LocaleNames(), ", "))
+	}
+	return locale, nil
+}
+
+// SetLocale sets the locale used by the package-level formatting helpers.
+// It is called once at startup from the configuration.
+func SetLocale(locale *Locale) {
+	currentLocale.Store(locale)
+}
+
+// CurrentLocale returns the locale set with SetLocale
+func CurrentLocale() *Locale {
+	return currentLocale.Load()
+}
+
+// FormatInt formats n with the locale's thousands separator
+func (l *Locale) FormatInt(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	return sign + l.group(digits)
+}
+
+// FormatFloat formats f with the given number of decimals and the locale's separators
+func (l *Locale) FormatFloat(f float64, decimals int) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	formatted := strconv.FormatFloat(math.Abs(f), 'f', decimals, 64)
+	intPart, fracPart, _ := strings.Cut(formatted, ".")
+	sign := ""
+	if f < 0 && strings.Trim(formatted, "0.") != "" {
+		sign = "-"
+	}
+	result := sign + l.group(intPart)
+	if fracPart != "" {
+		result += l.DecimalSeparator + fracPart
+	}
+	return result
+}
+
+func (l *Locale) group(digits string) string {
+	if len(digits) <= 3 || l.GroupSeparator == "" {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(l.GroupSeparator)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// FormatTimestamp formats t in loc using the locale's timestamp layout
+func (l *Locale) FormatTimestamp(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(l.TimestampLayout)
+}
+
+// FormatNumber formats n in the current locale
+func FormatNumber(n int64) string {
+	return CurrentLocale().FormatInt(n)
+}
+
+// FormatDecimal formats f with the given number of decimals in the current locale
+func FormatDecimal(f float64, decimals int) string {
+	return CurrentLocale().FormatFloat(f, decimals)
+}
+
+// FormatShortDate formats the day and month of t in the current locale
+func FormatShortDate(t time.Time) string {
+	return t.Format(CurrentLocale().ShortDateLayout)
+}
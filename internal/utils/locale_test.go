@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLocale(t *testing.T) {
+	for _, name := range []string{"", "en", "EN", "de", "en_US", "en-gb"} {
+		if _, err := ParseLocale(name); err != nil {
+			t.Fatalf("Expected %q to be supported, got %v", name, err)
+		}
+	}
+	if locale, _ := ParseLocale(""); locale.Name != DefaultLocale {
+		t.Fatalf("Expected empty name to be %s, got %s", DefaultLocale, locale.Name)
+	}
+	if locale, _ := ParseLocale("en_US"); locale.Name != "en-us" {
+		t.Fatalf("Expected en_US to be en-us, got %s", locale.Name)
+	}
+	if _, err := ParseLocale("xx"); err == nil {
+		t.Fatal("Expected error for unsupported locale")
+	}
+}
+
+func TestLocaleFormatInt(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int64
+		want   string
+	}{
+		{"en", 0, "0"},
+		{"en", 999, "999"},
+		{"en", 1000, "1,000"},
+		{"en", 1234567, "1,234,567"},
+		{"en", -1234567, "-1,234,567"},
+		{"de", 1234567, "1.234.567"},
+		{"ru", 12345, "12\u00a0345"},
+	}
+
+	for _, tt := range tests {
+		locale, _ := ParseLocale(tt.locale)
+		if got := locale.FormatInt(tt.n); got != tt.want {
+			t.Fatalf("%s FormatInt(%d): expected %q, got %q", tt.locale, tt.n, tt.want, got)
+		}
+	}
+}
+
+func TestLocaleFormatFloat(t *testing.T) {
+	tests := []struct {
+		locale   string
+		f        float64
+		decimals int
+		want     string
+	}{
+		{"en", 12.345, 1, "12.3"},
+		{"en", 1234.5, 1, "1,234.5"},
+		{"en", 42, 0, "42"},
+		{"de", 1234.56, 2, "1.234,56"},
+		{"fr", 1234.5, 1, "1\u202f234,5"},
+		{"en", -0.01, 1, "0.0"},
+		{"en", -1.25, 1, "-1.2"},
+	}
+
+	for _, tt := range tests {
+		locale, _ := ParseLocale(tt.locale)
+		if got := locale.FormatFloat(tt.f, tt.decimals); got != tt.want {
+			t.Fatalf("%s FormatFloat(%v, %d): expected %q, got %q", tt.locale, tt.f, tt.decimals, tt.want, got)
+		}
+	}
+}
+
+func TestSetLocale(t *testing.T) {
+	defer SetLocale(CurrentLocale())
+
+	de, _ := ParseLocale("de")
+	SetLocale(de)
+
+	ts := time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC)
+	if got := FormatTimestamp(ts, time.UTC); got != "01.03.2024 23:30:00" {
+		t.Fatalf("Expected 01.03.2024 23:30:00, got %s", got)
+	}
+	if got := FormatShortDate(ts); got != "01.03." {
+		t.Fatalf("Expected 01.03., got %s", got)
+	}
+	if got := FormatNumber(25000); got != "25.000" {
+		t.Fatalf("Expected 25.000, got %s", got)
+	}
+	if got := FormatDecimal(12.5, 1); got != "12,5" {
+		t.Fatalf("Expected 12,5, got %s", got)
+	}
+}
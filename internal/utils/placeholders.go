@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"strings"
+)
+
+// FillPlaceholders replaces each {name} in text with values[name]. Placeholders
+// without a value are left as they are, and values are not expanded again.
+func FillPlaceholders(text string, values map[string]string) string {
+	if len(values) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	pairs := make([]string, 0, len(values)*2)
+	for name, value := range values {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestFillPlaceholders(t *testing.T) {
+	values := map[string]string{
+		"first_name": "Ada",
+		"username":   "{first_name}",
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hi {first_name}!", "Hi Ada!"},
+		{"{first_name} {first_name}", "Ada Ada"},
+		{"Hi {last_name}", "Hi {last_name}"},
+		{"You are {username}", "You are {first_name}"},
+		{"No placeholders", "No placeholders"},
+	}
+	for _, tt := range tests {
+		if got := FillPlaceholders(tt.text, values); got != tt.want {
+			t.Errorf("FillPlaceholders(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	if got := FillPlaceholders("Hi {first_name}", nil); got != "Hi {first_name}" {
+		t.Errorf("FillPlaceholders without values = %q", got)
+	}
+}
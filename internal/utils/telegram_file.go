@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// DownloadFile downloads a file sent to the bot. Files larger than maxBytes are
+// rejected. The bot's own HTTP client is used, so a configured proxy applies.
+func DownloadFile(ctx context.Context, b *gotgbot.Bot, fileID string, maxBytes int64) ([]byte, error) {
+	file, err := b.GetFile(fileID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	if file.FileSize > maxBytes {
+		return nil, fmt.Errorf("file is too large (%d bytes, limit %d)", file.FileSize, maxBytes)
+	}
+
+	client := http.DefaultClient
+	if base, ok := b.BotClient.(*gotgbot.BaseBotClient); ok {
+		client = &base.Client
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.URL(b, nil), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// The error contains the URL, which includes the bot token
+		return nil, fmt.Errorf("failed to download file")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("file is too large (limit %d bytes)", maxBytes)
+	}
+	return data, nil
+}
+
+// Document is a file sent to a chat by SendDocuments
+type Document struct {
+	Name    string
+	Data    []byte
+	Caption string
+}
+
+// SendDocuments uploads files to a chat, as one album when there are several.
+// Telegram allows at most 10 files per album.
+func SendDocuments(b *gotgbot.Bot, chatID int64, docs []Document) error {
+	switch {
+	case len(docs) == 0:
+		return nil
+	case len(docs) == 1:
+		_, err := b.SendDocument(chatID, gotgbot.InputFileByReader(docs[0].Name, bytes.NewReader(docs[0].Data)),
+			&gotgbot.SendDocumentOpts{Caption: docs[0].Caption})
+		return err
+	case len(docs) > 10:
+		return fmt.Errorf("too many documents (%d, limit 10)", len(docs))
+	}
+
+	media := make([]gotgbot.InputMedia, 0, len(docs))
+	for _, doc := range docs {
+		media = append(media, gotgbot.InputMediaDocument{
+			Media:   gotgbot.InputFileByReader(doc.Name, bytes.NewReader(doc.Data)),
+			Caption: doc.Caption,
+		})
+	}
+	_, err := b.SendMediaGroup(chatID, media, nil)
+	return err
+}
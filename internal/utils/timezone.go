@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimestampLayout is the layout of timestamps shown to users in the default locale
+const TimestampLayout = "2006-01-02 15:04:05"
+
+// LoadTimezone parses an IANA timezone name such as "Europe/Berlin" or "UTC".
+// An empty name is the server's local timezone.
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// LocationOrLocal returns the named timezone, or the server's local timezone
+// if the name is empty or unknown
+func LocationOrLocal(name string) *time.Location {
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// FormatTimestamp formats t in loc using the current locale's timestamp layout
+func FormatTimestamp(t time.Time, loc *time.Location) string {
+	return CurrentLocale().FormatTimestamp(t, loc)
+}
+
+// TimezoneLabel returns the timezone name for display, e.g. "Asia/Shanghai (UTC+08:00)"
+func TimezoneLabel(loc *time.Location) string {
+	_, offset := time.Now().In(loc).Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s (UTC%s%02d:%02d)", loc.String(), sign, offset/3600, offset%3600/60)
+}
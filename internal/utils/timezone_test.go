@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	loc, err := LoadTimezone(" Asia/Shanghai ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loc.String() != "Asia/Shanghai" {
+		t.Fatalf("Expected Asia/Shanghai, got %s", loc)
+	}
+
+	if loc, err := LoadTimezone(""); err != nil || loc != time.Local {
+		t.Fatalf("Expected local timezone for empty name, got %v, %v", loc, err)
+	}
+
+	if _, err := LoadTimezone("Mars/Olympus"); err == nil {
+		t.Fatal("Expected error for unknown timezone")
+	}
+	if loc := LocationOrLocal("Mars/Olympus"); loc != time.Local {
+		t.Fatalf("Expected local timezone fallback, got %s", loc)
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC)
+
+	if got := FormatTimestamp(ts, time.UTC); got != "2024-03-01 23:30:00" {
+		t.Fatalf("Expected 2024-03-01 23:30:00, got %s", got)
+	}
+	if got := FormatTimestamp(ts, LocationOrLocal("Asia/Shanghai")); got != "2024-03-02 07:30:00" {
+		t.Fatalf("Expected 2024-03-02 07:30:00, got %s", got)
+	}
+	if got := FormatTimestamp(ts, LocationOrLocal("America/New_York")); got != "2024-03-01 18:30:00" {
+		t.Fatalf("Expected 2024-03-01 18:30:00, got %s", got)
+	}
+}
+
+func TestTimezoneLabel(t *testing.T) {
+	if got := TimezoneLabel(LocationOrLocal("Asia/Kolkata")); got != "Asia/Kolkata (UTC+05:30)" {
+		t.Fatalf("Expected Asia/Kolkata (UTC+05:30), got %s", got)
+	}
+	if got := TimezoneLabel(time.UTC); got != "UTC (UTC+00:00)" {
+		t.Fatalf("Expected UTC (UTC+00:00), got %s", got)
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// botTokenPattern is the shape of tokens issued by BotFather: the bot ID, a colon
+// and a secret of at least 30 characters
+var botTokenPattern = regexp.MustCompile(`^(\d+):[A-Za-z0-9_-]{30,}$`)
+
+// botTokenInURLPattern finds a token inside a pasted Bot API URL such as
+// https://api.telegram.org/bot123:ABC.../getMe
+var botTokenInURLPattern = regexp.MustCompile(`/bot(\d+:[A-Za-z0-9_-]+)`)
+
+// ParseBotToken checks that s looks like a bot token without contacting Telegram
+// and returns the bot ID it contains. The error describes the most likely paste
+// mistake so it can be shown to the user as is.
+func ParseBotToken(s string) (int64, error) {
+	token := strings.TrimSpace(s)
+	if token == "" {
+		return 0, errors.New("the token is empty")
+	}
+
+	if match := botTokenPattern.FindStringSubmatch(token); match != nil {
+		botID, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || botID <= 0 {
+			return 0, errors.New("the number before the colon is not a valid bot ID")
+		}
+		return botID, nil
+	}
+
+	switch {
+	case strings.ContainsAny(token, " \t\n"):
+		return 0, errors.New("the token contains spaces or line breaks; copy it again as a single piece")
+	case strings.Contains(token, "api.telegram.org") || botTokenInURLPattern.MatchString(token):
+		return 0, errors.New("this looks like a Bot API URL; send only the token, the part after \"/bot\" up to the next \"/\"")
+	case strings.ContainsAny(token[:1], "\"'`<[(") || strings.ContainsAny(token[len(token)-1:], "\"'`>])"):
+		return 0, errors.New("remove the quotes or brackets around the token")
+	case strings.HasPrefix(strings.ToLower(token), "bot") && botTokenPattern.MatchString(token[3:]):
+		return 0, errors.New("remove the \"bot\" prefix; it belongs to API URLs, not to the token")
+	case !strings.Contains(token, ":"):
+		return 0, errors.New("a token has the form 123456789:AAE...; the colon is missing")
+	}
+
+	id, secret, _ := strings.Cut(token, ":")
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return 0, errors.New("the part before the colon must be the numeric bot ID")
+	}
+	if len(secret) < 30 {
+		return 0, errors.New("the part after the colon is too short; the token was probably cut off when copying")
+	}
+	return 0, errors.New("the part after the colon may only contain letters, digits, \"_\" and \"-\"")
+}
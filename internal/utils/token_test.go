@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+const testToken = "123456789:AAEhBOweik6ad9r_QXMENQjcrGbqCr4K-ms"
+
+func TestParseBotToken(t *testing.T) {
+	botID, err := ParseBotToken(testToken)
+	if err != nil {
+		t.Fatalf("Expected valid token, got %v", err)
+	}
+	if botID != 123456789 {
+		t.Fatalf("Expected bot ID 123456789, got %d", botID)
+	}
+
+	if _, err := ParseBotToken("  " + testToken + "\n"); err != nil {
+		t.Fatalf("Expected surrounding whitespace to be ignored, got %v", err)
+	}
+}
+
+func TestParseBotTokenGuidance(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		hint  string
+	}{
+		{"empty", "", "empty"},
+		{"space", "123456789:AAEhBOweik6ad9r_QXMENQ jcrGbqCr4K-ms", "spaces"},
+		{"url", "https://api.telegram.org/bot" + testToken + "/getMe", "URL"},
+		{"quotes", "\"" + testToken + "\"", "quotes"},
+		{"backticks", "`" + testToken + "`", "quotes"},
+		{"brackets", "<" + testToken + ">", "brackets"},
+		{"bot prefix", "bot" + testToken, "\"bot\" prefix"},
+		{"no colon", "123456789AAEhBOweik6ad9r_QXMENQjcrGbqCr4K-ms", "colon is missing"},
+		{"bad id", "abc:AAEhBOweik6ad9r_QXMENQjcrGbqCr4K-ms", "numeric bot ID"},
+		{"truncated", "123456789:AAEhBOweik6ad9r", "cut off"},
+		{"bad chars", "123456789:AAEhBOweik6ad9r_QXMENQjcrGbqCr4K.ms", "may only contain"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseBotToken(tt.token)
+		if err == nil {
+			t.Fatalf("%s: expected error", tt.name)
+		}
+		if !strings.Contains(err.Error(), tt.hint) {
+			t.Fatalf("%s: expected error mentioning %q, got %q", tt.name, tt.hint, err.Error())
+		}
+	}
+}
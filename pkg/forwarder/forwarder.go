@@ -0,0 +1,173 @@
+// Package forwarder embeds the Telegram forwarder bot in other Go programs.
+//
+// The simplest use runs the same engine as the binary until ctx is done:
+//
+//	cfg, err := forwarder.LoadConfig("configs/config.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	return forwarder.Run(ctx, cfg, forwarder.WithHooks(forwarder.Hooks{
+//		OnGuestMessage: func(ctx context.Context, m forwarder.GuestMessage) {
+//			log.Printf("bot %s: message from %d", m.BotID, m.GuestChatID)
+//		},
+//	}))
+//
+// Use New and Engine.Run instead to register bots from code with
+// Engine.RegisterBot.
+package forwarder
+
+import (
+	"context"
+	"fmt"
+
+	"go-telegram-forwarder-bot/internal/app"
+	"go-telegram-forwarder-bot/internal/config"
+	"go-telegram-forwarder-bot/internal/logger"
+	"go-telegram-forwarder-bot/internal/service/llm"
+	"go-telegram-forwarder-bot/internal/service/manager_bot"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Config is the bot configuration, with the same fields as config.yaml
+type Config = config.Config
+
+// LLMProvider completes a conversation with a draft staff reply for /suggest.
+// Messages start with the system prompt, followed by the conversation with
+// guest messages as role "user" and staff replies as role "assistant".
+type LLMProvider = llm.Provider
+
+// LLMMessage is one message passed to an LLMProvider
+type LLMMessage = llm.Message
+
+// Errors returned by Engine.RegisterBot
+var (
+	ErrManagerBotToken      = manager_bot.ErrManagerBotToken
+	ErrBotAlreadyRegistered = manager_bot.ErrBotAlreadyRegistered
+	ErrManagerSuspended     = manager_bot.ErrManagerSuspended
+)
+
+// LoadConfig reads and validates a config file. Unset values get the same
+// defaults as in the binary.
+func LoadConfig(path string) (*Config, error) {
+	return config.LoadFromFile(path)
+}
+
+// LoadDefaultConfig reads config.yaml from the locations the binary searches:
+// the working directory, ./configs and $HOME/.go-telegram-forwarder-bot
+func LoadDefaultConfig() (*Config, error) {
+	return config.Load()
+}
+
+// Option customizes the engine
+type Option func(*engineOptions)
+
+type engineOptions struct {
+	logger *zap.Logger
+	hooks  *Hooks
+	app    []app.Option
+}
+
+// WithLogger logs through the given logger instead of one built from the log
+// section of the config
+func WithLogger(log *zap.Logger) Option {
+	return func(o *engineOptions) {
+		o.logger = log
+	}
+}
+
+// WithDB uses an existing database connection instead of the database section
+// of the config. Migrations still run on it.
+func WithDB(db *gorm.DB) Option {
+	return func(o *engineOptions) {
+		o.app = append(o.app, app.WithDB(db))
+	}
+}
+
+// WithRedisClient uses an existing Redis client when Redis is enabled in the config
+func WithRedisClient(client redis.UniversalClient) Option {
+	return func(o *engineOptions) {
+		o.app = append(o.app, app.WithRedisClient(client))
+	}
+}
+
+// WithLLMProvider drafts /suggest replies with the given provider instead of
+// the one configured in the llm section. llm.enabled must still be true.
+func WithLLMProvider(provider LLMProvider) Option {
+	return func(o *engineOptions) {
+		o.app = append(o.app, app.WithLLMProvider(provider))
+	}
+}
+
+// WithHooks registers callbacks for forwarded messages
+func WithHooks(hooks Hooks) Option {
+	return func(o *engineOptions) {
+		o.hooks = &hooks
+	}
+}
+
+// Engine is a configured forwarder, ready to run
+type Engine struct {
+	app    *app.App
+	logger *zap.Logger
+}
+
+// New connects to the database and Redis and prepares the ManagerBot and all
+// registered ForwarderBots. Nothing is started until Run is called. Bots stop
+// when ctx is done.
+func New(ctx context.Context, cfg *Config, opts ...Option) (*Engine, error) {
+	o := &engineOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	log := o.logger
+	if log == nil {
+		var err error
+		log, err = logger.New(cfg.Log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize logger: %w", err)
+		}
+	}
+
+	appOpts := o.app
+	if o.hooks != nil {
+		appOpts = append(appOpts, app.WithHooks(hooksAdapter{hooks: *o.hooks}))
+	}
+
+	a, err := app.New(ctx, cfg, log, appOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{app: a, logger: log}, nil
+}
+
+// Run starts all bots and blocks until the context passed to New is done. It
+// returns once every bot and worker has stopped.
+func (e *Engine) Run() error {
+	defer e.logger.Sync()
+	return e.app.Run()
+}
+
+// RegisterBot registers the bot with the given token for the manager with the
+// given Telegram user ID, like /addbot, and starts it if the engine is running.
+// The manager becomes the bot's first recipient.
+func (e *Engine) RegisterBot(ctx context.Context, token string, managerTelegramUserID int64) (uuid.UUID, error) {
+	bot, err := e.app.RegisterBot(ctx, token, managerTelegramUserID)
+	if bot == nil {
+		return uuid.Nil, err
+	}
+	return bot.ID, err
+}
+
+// Run creates an engine and runs it until ctx is done
+func Run(ctx context.Context, cfg *Config, opts ...Option) error {
+	e, err := New(ctx, cfg, opts...)
+	if err != nil {
+		return err
+	}
+	return e.Run()
+}
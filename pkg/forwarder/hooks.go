@@ -0,0 +1,66 @@
+package forwarder
+
+import (
+	"context"
+
+	"go-telegram-forwarder-bot/internal/service/message"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+)
+
+// GuestMessage is a guest message that was forwarded to the bot's recipients
+type GuestMessage struct {
+	BotID       uuid.UUID
+	GuestChatID int64
+	Message     *gotgbot.Message
+	Delivered   int // Recipients the message reached
+	Failed      int // Recipients it could not be delivered to
+}
+
+// Reply is a recipient's reply that was delivered to a guest
+type Reply struct {
+	BotID           uuid.UUID
+	GuestChatID     int64
+	RecipientChatID int64
+	Message         *gotgbot.Message
+}
+
+// Hooks are called after messages are delivered. Nil hooks are skipped. Hooks
+// run while the update is being handled, so slow work belongs in a goroutine.
+type Hooks struct {
+	OnGuestMessage func(ctx context.Context, m GuestMessage)
+	OnReply        func(ctx context.Context, r Reply)
+}
+
+// hooksAdapter implements message.HooksInterface with the public Hooks
+type hooksAdapter struct {
+	hooks Hooks
+}
+
+var _ message.HooksInterface = hooksAdapter{}
+
+func (h hooksAdapter) GuestMessageForwarded(ctx context.Context, botID uuid.UUID, guestChatID int64, msg *gotgbot.Message, result *message.ForwardResult) {
+	if h.hooks.OnGuestMessage == nil {
+		return
+	}
+	h.hooks.OnGuestMessage(ctx, GuestMessage{
+		BotID:       botID,
+		GuestChatID: guestChatID,
+		Message:     msg,
+		Delivered:   result.SuccessCount,
+		Failed:      result.FailureCount,
+	})
+}
+
+func (h hooksAdapter) ReplyForwarded(ctx context.Context, botID uuid.UUID, guestChatID int64, recipientChatID int64, reply *gotgbot.Message) {
+	if h.hooks.OnReply == nil {
+		return
+	}
+	h.hooks.OnReply(ctx, Reply{
+		BotID:           botID,
+		GuestChatID:     guestChatID,
+		RecipientChatID: recipientChatID,
+		Message:         reply,
+	})
+}